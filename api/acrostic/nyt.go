@@ -2,16 +2,13 @@ package acrostic
 
 import (
 	"bytes"
-	"encoding/json"
-	"errors"
 	"fmt"
-	"github.com/PuerkitoBio/goquery"
+	"github.com/bbeck/puzzles-with-chat/api/model"
 	"github.com/bbeck/puzzles-with-chat/api/web"
 	lzstring "github.com/daku10/go-lz-string"
 	"html"
 	"io"
 	"regexp"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -22,6 +19,12 @@ var XWordInfoHeaders = map[string]string{
 	"Referer": "https://www.xwordinfo.com/Acrostic",
 }
 
+// SourceURL returns the URL of the page on xwordinfo.com for the New York Times
+// acrostic published on a particular date.
+func SourceURL(published time.Time) string {
+	return fmt.Sprintf("https://www.xwordinfo.com/Acrostic?date=%s", published.Format("1/2/2006"))
+}
+
 // LoadFromNewYorkTimes loads an acrostic puzzle from the New York Times for a
 // particular date.
 //
@@ -98,7 +101,7 @@ func ParseXWordInfoWrappedPuzzleResponse(in io.Reader) (*Puzzle, error) {
 	// XWordInfo has started to serve up a compressed version of the acrostic
 	// JSON.  We will need to unwrap and decompress it before using it.
 	var wrapped XWordInfoPuzzleWrapper
-	if err := json.NewDecoder(in).Decode(&wrapped); err != nil {
+	if err := model.DecodeJSON(in, &wrapped); err != nil {
 		return nil, fmt.Errorf("unable to parse JSON response: %v", err)
 	}
 
@@ -131,7 +134,7 @@ type XWordInfoPuzzle struct {
 // a puzzle object.
 func ParseXWordInfoPuzzleResponse(in io.Reader) (*Puzzle, error) {
 	var raw XWordInfoPuzzle
-	if err := json.NewDecoder(in).Decode(&raw); err != nil {
+	if err := model.DecodeJSON(in, &raw); err != nil {
 		return nil, fmt.Errorf("unable to parse JSON response: %v", err)
 	}
 
@@ -221,7 +224,7 @@ func ParseXWordInfoPuzzleResponse(in io.Reader) (*Puzzle, error) {
 			return nil, err
 		}
 
-		clues[letter] = raw.Clues[index]
+		clues[letter] = model.UnescapeClue(raw.Clues[index])
 		clueNumbers[letter] = nums
 	}
 
@@ -243,6 +246,7 @@ func ParseXWordInfoPuzzleResponse(in io.Reader) (*Puzzle, error) {
 	puzzle.Author = author
 	puzzle.Title = title
 	puzzle.Quote = quote
+	puzzle.Copyright = raw.Copyright
 	puzzle.Cells = cells
 	puzzle.Givens = givens
 	puzzle.CellBlocks = blocks
@@ -368,36 +372,5 @@ func ParseInts(s string) ([]int, error) {
 // ParseXWordInfoAvailableDatesResponse converts an HTML response from the
 // select acrostic page on xwordinfo.com into a list of available dates.
 func ParseXWordInfoAvailableDatesResponse(in io.Reader) ([]time.Time, error) {
-	doc, err := goquery.NewDocumentFromReader(in)
-	if err != nil {
-		return nil, err
-	}
-
-	var dates []time.Time
-	doc.Find("a[data-acrdate]").Each(func(i int, s *goquery.Selection) {
-		if err != nil {
-			return
-		}
-
-		d, ok := s.Attr("data-acrdate")
-		if !ok {
-			err = fmt.Errorf("unable to determine data-acrdate for selection: %v", s)
-			return
-		}
-
-		var date time.Time
-		if date, err = time.Parse("1/2/2006", d); err == nil {
-			dates = append(dates, date)
-		}
-	})
-
-	sort.Slice(dates, func(i, j int) bool {
-		return dates[i].Before(dates[j])
-	})
-
-	if len(dates) == 0 && err == nil {
-		err = errors.New("no dates found")
-	}
-
-	return dates, err
+	return model.ParseXWordInfoAvailableDates(in, "acr")
 }
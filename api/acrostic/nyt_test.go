@@ -4,6 +4,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"io"
+	"io/ioutil"
 	"sort"
 	"strings"
 	"testing"
@@ -506,28 +507,28 @@ func TestParseXWordInfoPuzzleResponse(t *testing.T) {
 			input: load(t, "xwordinfo-nyt-20200524.json"),
 			verify: func(t *testing.T, puzzle *Puzzle) {
 				expected := map[string]string{
-					"A": "Sources of nonhuman &quot;songs&quot; on a 1970s album with more than 10 million copies",
-					"B": "Rock band called the &quot;Bad Boys from Boston&quot;",
-					"C": "Hit Broadway musical with the songs &quot;Let Me Entertain You&quot; and &quot;You Gotta Get a Gimmick&quot;",
+					"A": "Sources of nonhuman \"songs\" on a 1970s album with more than 10 million copies",
+					"B": "Rock band called the \"Bad Boys from Boston\"",
+					"C": "Hit Broadway musical with the songs \"Let Me Entertain You\" and \"You Gotta Get a Gimmick\"",
 					"D": "Country Music Hall of Fame site",
 					"E": "Turn in square dancing",
-					"F": "Prop whose name comes from a French word meaning &quot;squinting&quot;",
+					"F": "Prop whose name comes from a French word meaning \"squinting\"",
 					"G": "Recurrent theme",
 					"H": "Taken from a B to a C, say",
 					"I": "Fertile area for 1990s grunge music",
-					"J": "Capital hosting the Fajr Music Festival ",
+					"J": "Capital hosting the Fajr Music Festival",
 					"K": "Equipment for a busker, maybe",
-					"L": "What &quot;da capo&quot; tells you to do",
+					"L": "What \"da capo\" tells you to do",
 					"M": "Work for a pit crew?",
-					"N": "City that hosted jazz&#39;s storied Dreamland Ballroom",
+					"N": "City that hosted jazz's storied Dreamland Ballroom",
 					"O": "Brought off without a single fluff",
-					"P": "Nation whose country music is &quot;luk thung&quot;",
+					"P": "Nation whose country music is \"luk thung\"",
 					"Q": "Interval from ti to do (2 wds.)",
 					"R": "Pause for a change of scenery, perhaps",
 					"S": "Jumps a prima donna may make",
-					"T": "&quot;Peter and the Wolf&quot; composer",
+					"T": "\"Peter and the Wolf\" composer",
 					"U": "Catcher of some waves",
-					"V": "Affected by &quot;Ode to Joy,&quot; perhaps",
+					"V": "Affected by \"Ode to Joy,\" perhaps",
 					"W": "Killer musical by Stephen Sondheim",
 				}
 				assert.Equal(t, expected, puzzle.Clues)
@@ -703,6 +704,23 @@ func TestParseXWordInfoPuzzleResponse_Error(t *testing.T) {
 	}
 }
 
+func TestParseXWordInfoPuzzleResponse_MalformedField(t *testing.T) {
+	reader := load(t, "xwordinfo-nyt-20200524.json")
+	defer reader.Close()
+	bs, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+
+	input := string(bs)
+	require.Contains(t, input, `"cols": 27`)
+	input = strings.Replace(input, `"cols": 27`, `"cols": [27]`, 1)
+
+	_, err = ParseXWordInfoPuzzleResponse(strings.NewReader(input))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "field cols")
+	assert.Contains(t, err.Error(), "[27]")
+	assert.Less(t, len(err.Error()), 500)
+}
+
 func TestParseXWordInfoAvailableDatesResponse(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -37,6 +37,9 @@ type Puzzle struct {
 	// The quote that the acrostic is from.
 	Quote string `json:"quote"`
 
+	// The copyright notice of the acrostic, if its source provided one.
+	Copyright string `json:"copyright,omitempty"`
+
 	// The cells of the acrostic as a 2D list, entries are the letter that belongs
 	// in the cell.  If a cell cannot be inputted into then it will contain the
 	// empty string.  The lists are first indexed by the row coordinate of the
@@ -89,6 +92,7 @@ func (p *Puzzle) WithoutSolution() *Puzzle {
 	puzzle.Author = "" // The author is the first letter of some clue answers
 	puzzle.Title = ""  // The title is the first letter of some clue answers
 	puzzle.Quote = ""  // The quote is the first letter of some clue answers
+	puzzle.Copyright = p.Copyright
 	puzzle.Cells = nil
 	puzzle.Givens = p.Givens
 	puzzle.CellBlocks = p.CellBlocks
@@ -0,0 +1,207 @@
+package acrostic
+
+import (
+	"context"
+	"github.com/bbeck/puzzles-with-chat/api/db"
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"github.com/bbeck/puzzles-with-chat/api/pubsub"
+	"github.com/gomodule/redigo/redis"
+	"log"
+	"time"
+)
+
+// CompletionRevealInterval is how frequently channels are checked for
+// completed solves whose reveal time has passed.  This catches reveals whose
+// timers were lost, for example because the server restarted.
+var CompletionRevealInterval = time.Minute
+
+// PendingRevealsKey is the key of the sorted set in redis that tracks the
+// channels with a completed solve waiting to be revealed.  Members of the set
+// are channel names and are scored by the reveal time in milliseconds since the
+// epoch.
+const PendingRevealsKey = "acrostic:pending-reveals"
+
+// AddPendingReveal records that the provided channel's completed solve should
+// be revealed at the provided deadline.
+func AddPendingReveal(conn db.Connection, channel string, deadline time.Time) error {
+	_, err := conn.Do("ZADD", PendingRevealsKey, toMillis(deadline), channel)
+	return err
+}
+
+// RemovePendingReveal removes the provided channel from the set of channels
+// waiting to have their completed solve revealed.
+func RemovePendingReveal(conn db.Connection, channel string) error {
+	_, err := conn.Do("ZREM", PendingRevealsKey, channel)
+	return err
+}
+
+// RevealCompletion reveals the quote of a channel's completed solve once its
+// delayed reveal time has arrived.  The reveal only happens if the channel's
+// state still has a pending reveal at the provided deadline, if the channel has
+// since moved on to another puzzle or the solve was already revealed then
+// nothing happens.  The state is watched until it's saved so that a change made
+// in the meantime isn't overwritten.  Whether or not the solve was revealed is
+// returned.
+func RevealCompletion(conn redis.Conn, registry *pubsub.Registry, channel string, deadline time.Time) (bool, error) {
+	if _, err := conn.Do("WATCH", StateKey(channel)); err != nil {
+		return false, err
+	}
+	defer func() { _, _ = conn.Do("UNWATCH") }()
+
+	state, err := GetState(conn, channel)
+	if err != nil {
+		return false, err
+	}
+
+	if state.Status != model.StatusComplete || state.Puzzle == nil {
+		return false, nil
+	}
+	if state.RevealTime == nil || !state.RevealTime.Equal(deadline) {
+		return false, nil
+	}
+
+	state.RevealTime = nil
+
+	if err := conn.Send("MULTI"); err != nil {
+		return false, err
+	}
+	if err := SetState(conn, channel, state); err != nil {
+		_, _ = conn.Do("DISCARD")
+		return false, err
+	}
+
+	// If the state changed before it was saved then the channel did something
+	// else with it and the reveal no longer applies.  An aborted transaction has
+	// no replies.
+	replies, err := redis.Values(conn.Do("EXEC"))
+	if err == redis.ErrNil || (err == nil && len(replies) == 0) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := RemovePendingReveal(conn, channel); err != nil {
+		log.Printf("unable to remove pending reveal for channel %s: %+v", channel, err)
+	}
+
+	publishCompletion(conn, registry, channel, state)
+	return true, nil
+}
+
+// RevealPendingCompletions reveals the quote of every channel in the set of
+// pending reveals whose reveal time is at or before the provided time.  A
+// channel that can't be revealed is logged and skipped so that it doesn't hold
+// up the others, it remains pending and is tried again the next time.
+// Channels that no longer have a pending reveal are removed from the set.  The
+// names of the channels whose solves were revealed are returned.
+func RevealPendingCompletions(conn redis.Conn, registry *pubsub.Registry, now time.Time) ([]string, error) {
+	channels, err := redis.Strings(conn.Do("ZRANGEBYSCORE", PendingRevealsKey, "-inf", toMillis(now)))
+	if err != nil {
+		return nil, err
+	}
+
+	var revealed []string
+	for _, channel := range channels {
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			continue
+		}
+
+		// The channel may have moved on since the reveal was scheduled, and if it
+		// has a new reveal then that one was added to the set in its place.
+		if state.RevealTime == nil {
+			if err := RemovePendingReveal(conn, channel); err != nil {
+				log.Printf("unable to remove pending reveal for channel %s: %+v", channel, err)
+			}
+			continue
+		}
+		if state.RevealTime.After(now) {
+			continue
+		}
+
+		done, err := RevealCompletion(conn, registry, channel, *state.RevealTime)
+		if err != nil {
+			log.Printf("unable to reveal completion for channel %s: %+v", channel, err)
+			continue
+		}
+		if done {
+			revealed = append(revealed, channel)
+		}
+	}
+
+	return revealed, nil
+}
+
+// StartCompletionRevealer periodically reveals the quotes of completed solves
+// whose reveal time has passed until the provided context is cancelled.
+func StartCompletionRevealer(ctx context.Context, pool *redis.Pool, registry *pubsub.Registry) {
+	go func() {
+		ticker := time.NewTicker(CompletionRevealInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			conn := pool.Get()
+			channels, err := RevealPendingCompletions(conn, registry, time.Now())
+			_ = conn.Close()
+
+			if err != nil {
+				log.Printf("unable to reveal pending completions: %+v", err)
+			}
+			if len(channels) > 0 {
+				log.Printf("revealed pending completions for channels: %v", channels)
+			}
+		}
+	}()
+}
+
+// scheduleReveal arranges for a channel's completed solve to be revealed at
+// the provided deadline.  The reveal is recorded in redis as well as being put
+// on a timer because the timer doesn't survive a restart of the server,
+// StartCompletionRevealer picks up any reveals that are missed because of that.
+func scheduleReveal(conn redis.Conn, pool *redis.Pool, registry *pubsub.Registry, channel string, deadline time.Time) {
+	if err := AddPendingReveal(conn, channel, deadline); err != nil {
+		log.Printf("unable to record pending reveal for channel %s: %+v", channel, err)
+	}
+
+	time.AfterFunc(time.Until(deadline), func() {
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		if _, err := RevealCompletion(conn, registry, channel, deadline); err != nil {
+			log.Printf("unable to reveal completion for channel %s: %+v", channel, err)
+		}
+	})
+}
+
+// publishCompletion announces a channel's completed solve.  Now that the title
+// is no longer a secret the solve is added to the recent completions across
+// all channels, and the channel's clients are sent the quote.
+func publishCompletion(conn db.Connection, registry *pubsub.Registry, channel string, state State) {
+	completion := model.Completion{
+		Type:      "acrostic",
+		Channel:   channel,
+		Title:     state.Puzzle.Title,
+		Publisher: state.Puzzle.Publisher,
+		Duration:  state.TotalSolveDuration,
+		Time:      time.Now(),
+	}
+	if err := model.RecordCompletion(conn, completion); err != nil {
+		log.Printf("unable to record completion for channel %s: %+v", channel, err)
+	}
+	registry.Publish(model.CompletionsChannel, model.CompletionEvent(completion))
+
+	registry.Publish(ChannelID(channel), CompleteEvent(state.Puzzle))
+}
+
+// toMillis converts a time into the number of milliseconds since the epoch.
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,93 @@
+package acrostic
+
+import (
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRevealPendingCompletions(t *testing.T) {
+	_, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, "pending")
+
+	now := time.Now()
+	for _, channel := range []string{"pending", "later", "revealed"} {
+		state := NewState(t, "xwordinfo-nyt-20200524.json")
+		state.Status = model.StatusComplete
+		if channel != "revealed" {
+			reveal := now.Add(10 * time.Millisecond)
+			if channel == "later" {
+				reveal = now.Add(time.Hour)
+			}
+			state.RevealTime = &reveal
+			require.NoError(t, AddPendingReveal(conn, channel, reveal))
+		}
+		require.NoError(t, SetState(conn, channel, state))
+	}
+
+	// A channel that was pending but has since moved on to another puzzle.
+	require.NoError(t, AddPendingReveal(conn, "abandoned", now))
+
+	// Only the solve whose reveal time has passed is revealed.
+	channels, err := RevealPendingCompletions(conn, registry, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pending"}, channels)
+
+	pending, err := GetState(conn, "pending")
+	require.NoError(t, err)
+	assert.Nil(t, pending.RevealTime)
+
+	later, err := GetState(conn, "later")
+	require.NoError(t, err)
+	assert.NotNil(t, later.RevealTime)
+
+	// The solve is recorded and its quote sent to clients.
+	completions, err := model.GetRecentCompletions(conn, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(completions))
+	assert.Equal(t, "pending", completions[0].Channel)
+
+	found := Events(events, "complete")
+	require.Equal(t, 1, len(found))
+	payload := found[0].Payload.(map[string]string)
+	assert.Equal(t, "STARS OF THE OPERA", payload["title"])
+
+	// Only the channel that's still waiting remains pending.
+	pendings, err := redis.Strings(conn.Do("ZRANGE", PendingRevealsKey, 0, -1))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"later"}, pendings)
+
+	// A reveal is only ever done once.
+	channels, err = RevealPendingCompletions(conn, registry, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Empty(t, channels)
+}
+
+func TestRevealCompletion_NewPuzzleSelected(t *testing.T) {
+	_, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	reveal := time.Now()
+	state := NewState(t, "xwordinfo-nyt-20200524.json")
+	state.Status = model.StatusComplete
+	state.RevealTime = &reveal
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// The channel moves on to another puzzle before the reveal happens.
+	state.resetEphemeralState(state.Puzzle)
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	revealed, err := RevealCompletion(conn, registry, Channel.name, reveal)
+	require.NoError(t, err)
+	assert.False(t, revealed)
+	assert.Empty(t, Events(events, "complete"))
+
+	completions, err := model.GetRecentCompletions(conn, 10)
+	require.NoError(t, err)
+	assert.Empty(t, completions)
+}
@@ -2,6 +2,8 @@ package acrostic
 
 import (
 	"compress/flate"
+	"context"
+	"errors"
 	"fmt"
 	"github.com/bbeck/puzzles-with-chat/api/model"
 	"github.com/bbeck/puzzles-with-chat/api/pubsub"
@@ -22,8 +24,10 @@ func RegisterRoutes(r chi.Router, pool *redis.Pool, registry *pubsub.Registry) {
 	r.Route("/acrostic/{channel}", func(r chi.Router) {
 		r.Put("/", UpdatePuzzle(pool, registry))
 		r.Get("/events", GetEvents(pool, registry))
+		r.Get("/settings", ReadSettings(pool))
 		r.Put("/setting/{setting}", UpdateSetting(pool, registry))
 		r.Get("/show/{clue}", ShowClue(registry))
+		r.Get("/numbering", GetNumbering(pool))
 		r.Put("/status", ToggleStatus(pool, registry))
 		r.Put("/answer/{clue}", UpdateAnswer(pool, registry))
 	})
@@ -70,24 +74,9 @@ func UpdatePuzzle(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 		conn := pool.Get()
 		defer func() { _ = conn.Close() }()
 
-		// Initialize the cell values.  Most cells will be empty with the exception
-		// of cells containing givens.
-		cells := make([][]string, puzzle.Rows)
-		for row := 0; row < puzzle.Rows; row++ {
-			cells[row] = make([]string, puzzle.Cols)
-			for col := 0; col < puzzle.Cols; col++ {
-				if puzzle.Givens[row][col] != "" {
-					cells[row][col] = puzzle.Givens[row][col]
-				}
-			}
-		}
-
-		state := State{
-			Status:      model.StatusSelected,
-			Puzzle:      puzzle,
-			Cells:       cells,
-			CluesFilled: make(map[string]bool),
-		}
+		// Save the puzzle to this channel's state
+		var state State
+		state.resetEphemeralState(puzzle)
 		if err := SetState(conn, channel, state); err != nil {
 			log.Printf("unable to save state for channel %s: %+v", channel, err)
 			w.WriteHeader(http.StatusInternalServerError)
@@ -106,6 +95,45 @@ func UpdatePuzzle(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 	}
 }
 
+// Numbering describes the layout of a puzzle's grid, which cells are blocks
+// and what number each cell has, without any of the puzzle's solution.  It's
+// enough for a client to render an empty grid.
+type Numbering struct {
+	Rows            int      `json:"rows"`
+	Cols            int      `json:"cols"`
+	CellBlocks      [][]bool `json:"cell_blocks"`
+	CellClueNumbers [][]int  `json:"cell_clue_numbers"`
+}
+
+// GetNumbering returns the numbering of the grid of the channel's acrostic.
+func GetNumbering(pool *redis.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if state.Puzzle == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		render.JSON(w, r, Numbering{
+			Rows:            state.Puzzle.Rows,
+			Cols:            state.Puzzle.Cols,
+			CellBlocks:      state.Puzzle.CellBlocks,
+			CellClueNumbers: state.Puzzle.CellNumbers,
+		})
+	}
+}
+
 // GetEvents establishes an event stream with a client.  An event stream is
 // server side event stream (SSE) with a client's browser that allows one way
 // communication from the server to the client.  Clients that call into this
@@ -121,44 +149,60 @@ func GetEvents(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
 		stream := make(chan pubsub.Event, 10)
 		defer close(stream)
 
-		// Setup a connection to redis so that we can read settings and the current
-		// state of the solve.
-		conn := pool.Get()
-		defer func() { _ = conn.Close() }()
+		// Load the settings and the current state of the solve (if there is one,
+		// but make sure to mask the solution to the puzzle).  These are always the
+		// first events sent to the client.
+		load := func() ([]pubsub.Event, error) {
+			conn := pool.Get()
+			defer func() { _ = conn.Close() }()
 
-		// Always send the settings if there are any.
-		settings, err := GetSettings(conn, channel)
-		if err != nil {
-			log.Printf("unable to read settings for channel %s: %+v", channel, err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-		stream <- SettingsEvent(settings)
+			settings, err := GetSettings(conn, channel)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read settings for channel %s: %w", channel, err)
+			}
+			events := []pubsub.Event{SettingsEvent(settings)}
 
-		// Send the current state of the solve if there is one, but make sure to
-		// mask the solution to the puzzle.
-		state, err := GetState(conn, channel)
-		if err != nil {
-			log.Printf("unable to read state for channel %s: %+v", channel, err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-		if state.Puzzle != nil {
-			state.Puzzle = state.Puzzle.WithoutSolution()
-			stream <- StateEvent(state)
+			state, err := GetState(conn, channel)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read state for channel %s: %w", channel, err)
+			}
+			if state.Puzzle != nil {
+				state.Puzzle = state.Puzzle.WithoutSolution()
+				events = append(events, StateEvent(state))
+			}
+
+			return events, nil
 		}
 
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		// If the initial events can't be loaded the client is sent an error event
+		// instead, but the stream stays open while loading is retried in the
+		// background.
+		done := pubsub.SendInitialEvents(ctx, stream, load)
+		defer func() {
+			cancel()
+			<-done
+		}()
+
 		// Now that we've seeded the stream with the initialization events,
 		// subscribe it to receive all future events for the channel.
 		id, err := registry.Subscribe(ChannelID(channel), stream)
 		defer registry.Unsubscribe(id)
+		if errors.Is(err, pubsub.ErrTooManySubscribers) {
+			log.Printf("too many clients subscribed to channel %s", channel)
+			w.Header().Set("Retry-After", pubsub.SubscriberRetryAfter)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
 		if err != nil {
 			log.Printf("unable to subscribe client to channel %s: %+v", channel, err)
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
-		pubsub.EmitEvents(r.Context(), w, stream)
+		pubsub.EmitEvents(ctx, w, stream)
 	}
 }
 
@@ -197,11 +241,45 @@ func UpdateSetting(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 			var value model.FontSize
 			if err := render.DecodeJSON(r.Body, &value); err != nil {
 				log.Printf("unable to parse acrostic clue font size setting json %s: %+v", value, err)
-				w.WriteHeader(http.StatusBadRequest)
+
+				var allowed []string
+				for _, v := range model.FontSizes {
+					allowed = append(allowed, v.String())
+				}
+				model.InvalidSettingValue(w, r, setting, allowed)
 				return
 			}
 			settings.ClueFontSize = value
 
+		case "answer_aliases":
+			var value map[string]string
+			if err := render.DecodeJSON(r.Body, &value); err != nil {
+				log.Printf("unable to parse acrostic answer aliases setting json %v: %+v", value, err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			aliases, err := model.NormalizeAnswerAliases(value)
+			if err != nil {
+				log.Printf("invalid acrostic answer aliases setting: %+v", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			settings.AnswerAliases = aliases
+
+		case "completion_reveal_delay":
+			var value int
+			if err := render.DecodeJSON(r.Body, &value); err != nil {
+				log.Printf("unable to parse acrostic completion reveal delay setting json %v: %+v", value, err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if value < 0 || value > MaxCompletionRevealDelay {
+				log.Printf("invalid acrostic completion reveal delay setting %d", value)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			settings.CompletionRevealDelay = value
+
 		default:
 			log.Printf("unrecognized acrostic setting name %s", setting)
 			w.WriteHeader(http.StatusBadRequest)
@@ -282,6 +360,26 @@ func ShowClue(registry *pubsub.Registry) http.HandlerFunc {
 	}
 }
 
+// ReadSettings returns the acrostic settings of a channel.  Channels that have
+// never changed a setting receive the default settings.
+func ReadSettings(pool *redis.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		settings, err := GetSettings(conn, channel)
+		if err != nil {
+			log.Printf("unable to load settings for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		render.JSON(w, r, settings)
+	}
+}
+
 // ToggleStatus changes the status of the current acrostic solve to a new
 // status.  This effectively toggles between the solving and paused statuses as
 // long as the solve is in a state that can be paused or resumed.
@@ -299,14 +397,17 @@ func ToggleStatus(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 			return
 		}
 
-		now := time.Now()
-
-		switch state.Status {
-		case model.StatusCreated:
+		// A channel that has never selected a puzzle doesn't have a solve whose
+		// status can be toggled.
+		if state.Puzzle == nil || state.Status == model.StatusCreated {
 			log.Printf("unable to toggle status for channel %s, no puzzle selected", channel)
-			w.WriteHeader(http.StatusBadRequest)
+			http.Error(w, "no puzzle selected", http.StatusConflict)
 			return
+		}
+
+		now := time.Now()
 
+		switch state.Status {
 		case model.StatusSelected:
 			state.Status = model.StatusSolving
 			state.LastStartTime = &now
@@ -345,6 +446,10 @@ func ToggleStatus(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 	}
 }
 
+// CompletionRevealDelayUnit is the unit of time of a channel's completion
+// reveal delay setting.
+var CompletionRevealDelayUnit = time.Second
+
 // UpdateAnswer applies an answer to either a given clue or given set of cells
 // in the current acrostic solve.
 func UpdateAnswer(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
@@ -392,6 +497,7 @@ func UpdateAnswer(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 		}
 
 		// Determine if the user specified a clue letter or cell numbers.
+		state.AnswerAliases = settings.AnswerAliases
 		if start, err := strconv.Atoi(clue); err == nil {
 			if err := state.ApplyCellAnswer(start, answer, settings.OnlyAllowCorrectAnswers); err != nil {
 				log.Printf("unable to apply answer %s for cell %d for channel %s: %+v", answer, start, channel, err)
@@ -399,19 +505,33 @@ func UpdateAnswer(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 				return
 			}
 		} else {
-			if err := state.ApplyClueAnswer(clue, answer, settings.OnlyAllowCorrectAnswers); err != nil {
+			err := state.ApplyClueAnswer(clue, answer, settings.OnlyAllowCorrectAnswers)
+			if errors.Is(err, ErrUnknownClue) {
+				log.Printf("unable to apply answer %s for clue %s for channel %s: %+v", answer, clue, channel, err)
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			if err != nil {
 				log.Printf("unable to apply answer %s for clue %s for channel %s: %+v", answer, clue, channel, err)
 				w.WriteHeader(http.StatusBadRequest)
 				return
 			}
 		}
 
-		// If we just solved the puzzle then we should stop the timer.
+		// If we just solved the puzzle then we should stop the timer.  The channel
+		// may also want to hold off on revealing the quote for a while, in which
+		// case the time to reveal it is remembered so that it happens even if the
+		// server restarts in the meantime.
 		if state.Status == model.StatusComplete {
 			now := time.Now()
 			total := state.TotalSolveDuration.Nanoseconds() + now.Sub(*state.LastStartTime).Nanoseconds()
 			state.LastStartTime = nil
 			state.TotalSolveDuration = model.Duration{Duration: time.Duration(total)}
+
+			if settings.CompletionRevealDelay > 0 {
+				reveal := now.Add(time.Duration(settings.CompletionRevealDelay) * CompletionRevealDelayUnit)
+				state.RevealTime = &reveal
+			}
 		}
 
 		// Save the updated state.
@@ -421,11 +541,9 @@ func UpdateAnswer(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 			return
 		}
 
-		// Save these before hiding the solution because they'll be cleared because
-		// they're part of the solution.
-		author := state.Puzzle.Author
-		title := state.Puzzle.Title
-		quote := state.Puzzle.Quote
+		// Keep a copy of the state with the solution intact so that the solve can
+		// be announced once it's complete.
+		solved := state
 
 		// Broadcast to all of the clients that the puzzle has been selected, making
 		// sure to not include the answers.  It's okay to overwrite the puzzle
@@ -435,9 +553,14 @@ func UpdateAnswer(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 
 		registry.Publish(ChannelID(channel), StateEvent(state))
 
-		// If we've just finished the solve then send a complete event as well.
+		// If we've just finished the solve then announce it, unless the channel
+		// wants to hold off on revealing the quote.
 		if state.Status == model.StatusComplete {
-			registry.Publish(ChannelID(channel), CompleteEvent(author, title, quote))
+			if state.RevealTime != nil {
+				scheduleReveal(conn, pool, registry, channel, *state.RevealTime)
+			} else {
+				publishCompletion(conn, registry, channel, solved)
+			}
 		}
 
 		w.WriteHeader(http.StatusOK)
@@ -505,14 +628,25 @@ func StateEvent(state State) pubsub.Event {
 	}
 }
 
-func CompleteEvent(author, title, text string) pubsub.Event {
+// CompleteEvent returns the event that's sent when a channel completes its
+// acrostic.  Along with the quote it carries the attribution of the puzzle so
+// that clients sharing the solve can credit its source.  The copyright is only
+// included when it's known.
+func CompleteEvent(puzzle *Puzzle) pubsub.Event {
+	payload := map[string]string{
+		"author":     puzzle.Author,
+		"title":      puzzle.Title,
+		"text":       puzzle.Quote,
+		"publisher":  puzzle.Publisher,
+		"source_url": SourceURL(puzzle.PublishedDate),
+	}
+	if puzzle.Copyright != "" {
+		payload["copyright"] = puzzle.Copyright
+	}
+
 	return pubsub.Event{
-		Kind: "complete",
-		Payload: map[string]string{
-			"author": author,
-			"title":  title,
-			"text":   text,
-		},
+		Kind:    "complete",
+		Payload: payload,
 	}
 }
 
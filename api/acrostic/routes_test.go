@@ -3,6 +3,7 @@ package acrostic
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -172,6 +173,47 @@ func TestRoute_ShowClue(t *testing.T) {
 	})
 }
 
+func TestRoute_GetNumbering(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	// There's no numbering until a puzzle is selected.
+	response := Channel.GET("/numbering", router)
+	require.Equal(t, http.StatusNotFound, response.Code)
+
+	state := NewState(t, "xwordinfo-nyt-20200524.json")
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response = Channel.GET("/numbering", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var numbering Numbering
+	require.NoError(t, render.DecodeJSON(response.Result().Body, &numbering))
+	assert.Equal(t, 8, numbering.Rows)
+	assert.Equal(t, 27, numbering.Cols)
+	assert.Equal(t, state.Puzzle.CellBlocks, numbering.CellBlocks)
+
+	expected := [][]int{
+		{1, 2, 3, 4, 5, 6, 0, 7, 8, 9, 10, 11, 12, 0, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 0, 23, 24},
+		{25, 0, 26, 27, 28, 29, 0, 30, 31, 32, 33, 34, 35, 36, 37, 38, 0, 39, 40, 41, 42, 43, 0, 44, 45, 46, 47},
+		{48, 49, 50, 51, 52, 0, 53, 54, 55, 56, 57, 58, 59, 0, 60, 61, 62, 63, 64, 65, 66, 0, 67, 68, 69, 70, 0},
+		{71, 72, 0, 73, 74, 75, 76, 0, 77, 78, 79, 80, 0, 81, 82, 83, 84, 85, 86, 87, 0, 88, 89, 90, 91, 92, 93},
+		{94, 95, 96, 97, 98, 0, 99, 100, 101, 0, 102, 103, 104, 105, 0, 106, 107, 108, 0, 109, 110, 111, 112, 0, 113, 114, 0},
+		{115, 0, 116, 117, 118, 119, 120, 0, 121, 122, 123, 124, 0, 125, 0, 126, 127, 128, 129, 130, 0, 131, 132, 133, 134, 0, 135},
+		{136, 0, 137, 0, 138, 139, 140, 0, 141, 142, 143, 0, 144, 0, 145, 146, 147, 148, 149, 150, 151, 152, 153, 0, 154, 155, 0},
+		{156, 157, 158, 159, 160, 161, 0, 162, 163, 0, 164, 0, 165, 166, 167, 168, 0, 169, 170, 171, 172, 0, 173, 174, 175, 176, 177},
+	}
+	assert.Equal(t, expected, numbering.CellClueNumbers)
+
+	// The solution is never included.
+	assert.NotContains(t, response.Body.String(), `"cells"`)
+
+	// Errors loading the state should be reported.
+	ForceErrorDuringStateLoad(t, errors.New("forced error"))
+	response = Channel.GET("/numbering", router)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
 func TestRoute_ToggleStatus(t *testing.T) {
 	// This acts as a small integration test toggling the status of an acrostic
 	// being solved.
@@ -271,6 +313,15 @@ func TestRoute_ToggleStatus_Error(t *testing.T) {
 	}
 }
 
+func TestRoute_ToggleStatus_NoPuzzleSelected(t *testing.T) {
+	router, _, _ := NewTestRouter(t)
+
+	// The channel has never selected a puzzle so there's no state stored.
+	response := Channel.PUT("/status", "", router)
+	assert.Equal(t, http.StatusConflict, response.Code)
+	assert.Equal(t, "no puzzle selected", strings.TrimSpace(response.Body.String()))
+}
+
 func TestRoute_UpdateAnswer_AllowIncorrectAnswers(t *testing.T) {
 	// This acts as a small integration test of applying answers to an acrostic
 	// being solved.
@@ -440,6 +491,94 @@ func TestRoute_UpdateAnswer_SolvedPuzzleStopsTimer(t *testing.T) {
 	})
 }
 
+func TestRoute_UpdateAnswer_CompletionRevealDelay(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+	completes := NewEventSubscription(t, registry, Channel.name)
+
+	unit := CompletionRevealDelayUnit
+	CompletionRevealDelayUnit = 10 * time.Millisecond
+	defer func() { CompletionRevealDelayUnit = unit }()
+
+	settings := Settings{CompletionRevealDelay: 5}
+	require.NoError(t, SetSettings(conn, Channel.name, settings))
+
+	// Setup a state that has the entire puzzle solved except for the last answer.
+	state := NewState(t, "xwordinfo-nyt-20200524.json")
+	state.Status = model.StatusSolving
+	state.ApplyClueAnswer("A", "WHALES", false)
+	state.ApplyClueAnswer("B", "AEROSMITH", false)
+	state.ApplyClueAnswer("C", "GYPSY", false)
+	state.ApplyClueAnswer("D", "NASHVILLE", false)
+	state.ApplyClueAnswer("E", "ALLEMANDE", false)
+	state.ApplyClueAnswer("F", "LORGNETTE", false)
+	state.ApplyClueAnswer("G", "LEITMOTIF", false)
+	state.ApplyClueAnswer("H", "SHARPED", false)
+	state.ApplyClueAnswer("I", "SEATTLE", false)
+	state.ApplyClueAnswer("J", "TEHRAN", false)
+	state.ApplyClueAnswer("K", "ACCORDION", false)
+	state.ApplyClueAnswer("L", "REPEAT", false)
+	state.ApplyClueAnswer("M", "SYMPHONY", false)
+	state.ApplyClueAnswer("N", "OMAHA", false)
+	state.ApplyClueAnswer("O", "FLAWLESS", false)
+	state.ApplyClueAnswer("P", "THAILAND", false)
+	state.ApplyClueAnswer("Q", "HALFSTEP", false)
+	state.ApplyClueAnswer("R", "ENTRACTE", false)
+	state.ApplyClueAnswer("S", "OCTAVES", false)
+	state.ApplyClueAnswer("T", "PROKOFIEV", false)
+	state.ApplyClueAnswer("U", "EARDRUM", false)
+	state.ApplyClueAnswer("V", "RHAPSODIC", false)
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	start := time.Now()
+	response := Channel.PUT("/answer/W", `"ASSASSINS"`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	// The status changes right away, but the quote isn't revealed yet and the
+	// solve doesn't show up in the recent completions.
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, model.StatusComplete, state.Status)
+		assert.NotNil(t, state.RevealTime)
+	})
+	assert.Empty(t, Events(completes, "complete"))
+
+	completions, err := model.GetRecentCompletions(conn, 10)
+	require.NoError(t, err)
+	assert.Empty(t, completions)
+
+	pending, err := redis.Strings(conn.Do("ZRANGE", PendingRevealsKey, 0, -1))
+	require.NoError(t, err)
+	assert.Equal(t, []string{Channel.name}, pending)
+
+	// Eventually the complete event is sent, but not before the delay has passed.
+	var found []pubsub.Event
+	for deadline := time.Now().Add(time.Second); len(found) == 0 && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+		found = Events(completes, "complete")
+	}
+	require.Equal(t, 1, len(found))
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+
+	payload := found[0].Payload.(map[string]string)
+	assert.Equal(t, "MABEL WAGNALLS", payload["author"])
+	assert.Equal(t, "STARS OF THE OPERA", payload["title"])
+
+	// Once revealed the solve is recorded and the reveal is no longer pending.
+	completions, err = model.GetRecentCompletions(conn, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(completions))
+	assert.Equal(t, "STARS OF THE OPERA", completions[0].Title)
+
+	loaded, err := GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Nil(t, loaded.RevealTime)
+
+	pending, err = redis.Strings(conn.Do("ZRANGE", PendingRevealsKey, 0, -1))
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
 func TestRoute_UpdateAnswer_Error(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -478,6 +617,24 @@ func TestRoute_UpdateAnswer_Error(t *testing.T) {
 	}
 }
 
+func TestRoute_UpdateAnswer_UnknownClue(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	state := NewState(t, "xwordinfo-nyt-20200524.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response := Channel.PUT("/answer/zz", `"WHALES"`, router)
+	require.Equal(t, http.StatusNotFound, response.Code)
+	assert.Equal(t, "unknown clue: ZZ", strings.TrimSpace(response.Body.String()))
+
+	// The state shouldn't have been modified.
+	actual, err := GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, state.Cells, actual.Cells)
+}
+
 func TestRoute_UpdateAnswer_LoadSaveError(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -518,6 +675,38 @@ func TestRoute_UpdateAnswer_LoadSaveError(t *testing.T) {
 	}
 }
 
+func TestRoute_ReadSettings(t *testing.T) {
+	router, _, _ := NewTestRouter(t)
+
+	// A channel that has never changed a setting gets the defaults.
+	response := Channel.GET("/settings", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var settings Settings
+	require.NoError(t, render.DecodeJSON(response.Result().Body, &settings))
+	assert.False(t, settings.OnlyAllowCorrectAnswers)
+	assert.Equal(t, model.FontSizeNormal, settings.ClueFontSize)
+
+	// Once a setting is changed its new value is returned.
+	response = Channel.PUT("/setting/clue_font_size", `"xlarge"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	response = Channel.GET("/settings", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	settings = Settings{}
+	require.NoError(t, render.DecodeJSON(response.Result().Body, &settings))
+	assert.Equal(t, model.FontSizeXLarge, settings.ClueFontSize)
+}
+
+func TestRoute_ReadSettings_LoadError(t *testing.T) {
+	router, _, _ := NewTestRouter(t)
+	ForceErrorDuringSettingsLoad(t, errors.New("forced error"))
+
+	response := Channel.GET("/settings", router)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
 func TestRoute_UpdateSetting(t *testing.T) {
 	// This acts as a small integration test updating each setting in turn and
 	// making sure the proper value is written to the database and that clients
@@ -537,6 +726,18 @@ func TestRoute_UpdateSetting(t *testing.T) {
 	VerifySettings(t, pool, events, func(s Settings) {
 		assert.Equal(t, model.FontSizeXLarge, s.ClueFontSize)
 	})
+
+	response = Channel.PUT("/setting/answer_aliases", `{"+": "plus"}`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifySettings(t, pool, events, func(s Settings) {
+		assert.Equal(t, map[string]string{"+": "PLUS"}, s.AnswerAliases)
+	})
+
+	response = Channel.PUT("/setting/completion_reveal_delay", `30`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifySettings(t, pool, events, func(s Settings) {
+		assert.Equal(t, 30, s.CompletionRevealDelay)
+	})
 }
 
 func TestRoute_UpdateSetting_ClearsIncorrectCells(t *testing.T) {
@@ -591,6 +792,36 @@ func TestRoute_UpdateSetting_JSONError(t *testing.T) {
 			setting: "foo_bar_baz",
 			json:    `false`,
 		},
+		{
+			name:    "answer_aliases",
+			setting: "answer_aliases",
+			json:    `{`,
+		},
+		{
+			name:    "answer_aliases empty alias",
+			setting: "answer_aliases",
+			json:    `{"": "PLUS"}`,
+		},
+		{
+			name:    "answer_aliases empty value",
+			setting: "answer_aliases",
+			json:    `{"+": " "}`,
+		},
+		{
+			name:    "completion_reveal_delay",
+			setting: "completion_reveal_delay",
+			json:    `{`,
+		},
+		{
+			name:    "completion_reveal_delay negative",
+			setting: "completion_reveal_delay",
+			json:    `-1`,
+		},
+		{
+			name:    "completion_reveal_delay too large",
+			setting: "completion_reveal_delay",
+			json:    `3601`,
+		},
 	}
 
 	for _, test := range tests {
@@ -603,6 +834,43 @@ func TestRoute_UpdateSetting_JSONError(t *testing.T) {
 	}
 }
 
+func TestRoute_UpdateSetting_InvalidValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		setting  string
+		json     string
+		expected []string // the allowed values
+	}{
+		{
+			name:     "clue_font_size",
+			setting:  "clue_font_size",
+			json:     `"huge"`,
+			expected: []string{"normal", "large", "xlarge"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, _, _ := NewTestRouter(t)
+
+			response := Channel.PUT(fmt.Sprintf("/setting/%s", test.setting), test.json, router)
+			require.Equal(t, http.StatusBadRequest, response.Code)
+
+			var body struct {
+				Error   string   `json:"error"`
+				Setting string   `json:"setting"`
+				Allowed []string `json:"allowed"`
+			}
+			require.NoError(t, render.DecodeJSON(response.Body, &body))
+			assert.Equal(t, test.setting, body.Setting)
+			assert.Equal(t, test.expected, body.Allowed)
+			for _, value := range test.expected {
+				assert.Contains(t, body.Error, value)
+			}
+		})
+	}
+}
+
 func TestRoute_UpdateSettings_LoadSaveError(t *testing.T) {
 	tests := []struct {
 		name                   string
@@ -711,9 +979,12 @@ func TestRoute_GetEvents(t *testing.T) {
 	assert.Equal(t, "complete", events[1].Kind)
 
 	complete := map[string]interface{}{
-		"author": "MABEL WAGNALLS",
-		"title":  "STARS OF THE OPERA",
-		"text":   `<p>People seldom appreciate the vast knowledge of music and the remarkable ability in sight-reading which these orchestra players possess. Not one of them but has worked at his art from childhood; most of them play several different instruments; and they all hold as a creed that a false note is a sin, and a variation in rhythm is a fall from grace.</p>`,
+		"author":     "MABEL WAGNALLS",
+		"title":      "STARS OF THE OPERA",
+		"publisher":  "The New York Times",
+		"copyright":  "2020 The New York Times",
+		"source_url": "https://www.xwordinfo.com/Acrostic?date=5/24/2020",
+		"text":       `<p>People seldom appreciate the vast knowledge of music and the remarkable ability in sight-reading which these orchestra players possess. Not one of them but has worked at his art from childhood; most of them play several different instruments; and they all hold as a creed that a false note is a sin, and a variation in rhythm is a fall from grace.</p>`,
 	}
 	assert.Equal(t, complete, events[1].Payload)
 
@@ -749,10 +1020,12 @@ func TestRoute_GetEvents_LoadSaveError(t *testing.T) {
 			ForceErrorDuringSettingsLoad(t, test.forceSettingsLoadError)
 			ForceErrorDuringStateLoad(t, test.forceStateLoadError)
 
-			// This won't start a background goroutine to send events because the
-			// request will fail before reaching that part of the code.
-			response := Channel.GET("/events", router)
-			assert.NotEqual(t, http.StatusOK, response.Code)
+			// The stream stays open, but the client is told that the initial
+			// events couldn't be loaded.
+			_, stop := Channel.SSE("/events", router)
+			events := stop()
+			require.Equal(t, 1, len(events))
+			assert.Equal(t, "error", events[0].Kind)
 		})
 	}
 }
@@ -982,16 +1255,28 @@ func (c ChannelClient) SSE(url string, router chi.Router) (flush func() []pubsub
 		return events
 	}
 
+	// The request is cancelled when the stream is stopped, just like when a
+	// client disconnects.
+	ctx, cancel := context.WithCancel(context.Background())
+	finished := make(chan struct{})
+
 	stop = func() []pubsub.Event {
 		// Give the router a chance to write everything it needs to.
 		time.Sleep(10 * time.Millisecond)
 
 		recorder.Close()
-		return flush()
+		events := flush()
+
+		cancel()
+		<-finished
+		return events
 	}
 
-	request := httptest.NewRequest(http.MethodGet, url, nil)
-	go router.ServeHTTP(recorder, request)
+	request := httptest.NewRequest(http.MethodGet, url, nil).WithContext(ctx)
+	go func() {
+		defer close(finished)
+		router.ServeHTTP(recorder, request)
+	}()
 
 	return flush, stop
 }
@@ -15,8 +15,21 @@ type Settings struct {
 
 	// What font size should the clues be rendered with.
 	ClueFontSize model.FontSize `json:"clue_font_size"`
+
+	// Additional ways of writing parts of answers that should be accepted on top
+	// of the default aliases (e.g. "1" for "ONE").
+	AnswerAliases map[string]string `json:"answer_aliases,omitempty"`
+
+	// The number of seconds to wait after the acrostic is completed before the
+	// quote, author and title are revealed.  This gives the streamer a chance to
+	// react before the answer is spoiled for viewers that are behind.
+	CompletionRevealDelay int `json:"completion_reveal_delay"`
 }
 
+// MaxCompletionRevealDelay is the largest completion reveal delay in seconds
+// that a channel can configure.
+const MaxCompletionRevealDelay = 3600
+
 // SettingsKey returns the key that should be used in redis to store a
 // particular channel's acrostic settings.
 func SettingsKey(name string) string {
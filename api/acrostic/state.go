@@ -1,6 +1,7 @@
 package acrostic
 
 import (
+	"errors"
 	"fmt"
 	"github.com/bbeck/puzzles-with-chat/api/db"
 	"github.com/bbeck/puzzles-with-chat/api/model"
@@ -9,6 +10,10 @@ import (
 	"time"
 )
 
+// ErrUnknownClue is returned when an answer is applied to a clue letter that
+// doesn't exist in the acrostic.
+var ErrUnknownClue = errors.New("unknown clue")
+
 // State represents the state of an active channel that is attempting to solve
 // an acrostic.
 type State struct {
@@ -32,6 +37,44 @@ type State struct {
 
 	// The total time spent on solving the puzzle up to the last start time.
 	TotalSolveDuration model.Duration `json:"total_solve_duration"`
+
+	// The time that the state was last saved.  This is used to avoid counting
+	// time that the server was down as solve time.
+	LastSaveTime *time.Time `json:"last_save_time,omitempty"`
+
+	// The time that the quote of a completed solve will be revealed when the
+	// channel delays revealing it.  Once the quote has been revealed, or if it
+	// was never delayed, this will be nil.
+	RevealTime *time.Time `json:"reveal_time,omitempty"`
+
+	// Additional answer aliases configured in the channel's settings.  These are
+	// populated before answers are applied and are never persisted.
+	AnswerAliases map[string]string `json:"-"`
+}
+
+// resetEphemeralState clears everything about the state that belongs to a
+// single solve and initializes it for solving the provided puzzle.  Anything
+// that should not survive the selection of a new puzzle must be reset here so
+// that it isn't accidentally carried over into the next solve.
+func (s *State) resetEphemeralState(puzzle *Puzzle) {
+	// Most cells will be empty with the exception of cells containing givens.
+	cells := make([][]string, puzzle.Rows)
+	for row := 0; row < puzzle.Rows; row++ {
+		cells[row] = make([]string, puzzle.Cols)
+		for col := 0; col < puzzle.Cols; col++ {
+			if puzzle.Givens[row][col] != "" {
+				cells[row][col] = puzzle.Givens[row][col]
+			}
+		}
+	}
+
+	s.Status = model.StatusSelected
+	s.Puzzle = puzzle
+	s.Cells = cells
+	s.CluesFilled = make(map[string]bool)
+	s.LastStartTime = nil
+	s.TotalSolveDuration = model.Duration{}
+	s.RevealTime = nil
 }
 
 // ApplyClueAnswer applies an answer for a clue to the state.  If the clue
@@ -43,82 +86,42 @@ func (s *State) ApplyClueAnswer(clue string, answer string, onlyCorrect bool) er
 	clue = strings.ToUpper(clue)
 	nums, ok := s.Puzzle.ClueNumbers[clue]
 	if !ok {
-		return fmt.Errorf("invalid clue identifier: %s", clue)
+		return fmt.Errorf("%w: %s", ErrUnknownClue, clue)
 	}
 
 	// Ignore spaces within the answer and ensure the answer is all uppercase.
 	answer = strings.ReplaceAll(answer, " ", "")
 	answer = strings.ToUpper(answer)
 
+	// Expand any abbreviated runs of unknown cells.
+	answer, err := model.ExpandWildcards(answer)
+	if err != nil {
+		return err
+	}
+
+	// Every cell holds a single letter, so aliases such as "&" are spelled out.
+	answer = model.ExpandAnswerAliases(answer, model.AnswerAliases(s.AnswerAliases))
+
 	// Ensure that we have a proper length answer
 	if len(nums) != len(answer) {
 		return fmt.Errorf("unable to apply answer %s to clue %s, incompatible sizes", answer, clue)
 	}
 
-	// Cache the coordinates of each cell of the answer.
-	xs := make(map[int]int)
-	ys := make(map[int]int)
-	for _, num := range nums {
+	// Compute the coordinates of each cell of the answer.
+	xs := make([]int, len(nums))
+	ys := make([]int, len(nums))
+	for i, num := range nums {
 		x, y, err := s.Puzzle.GetCellCoordinates(num)
 		if err != nil {
 			return err
 		}
 
-		xs[num] = x
-		ys[num] = y
-	}
-
-	// Check to see if the answer is correct when required.
-	if onlyCorrect {
-		for i, num := range nums {
-			x := xs[num]
-			y := ys[num]
-
-			existing := s.Cells[y][x]
-			expected := s.Puzzle.Cells[y][x]
-			desired := string(answer[i])
-
-			// We can't change a correct value to an incorrect or empty one.
-			if existing != "" && desired != existing {
-				return fmt.Errorf("unable to apply answer %s to clue %s, changes correct value", answer, clue)
-			}
-
-			// We can't write an incorrect value into a cell
-			if desired != "." && desired != expected {
-				return fmt.Errorf("unable to apply answer %s to clue %s, incorrect", answer, clue)
-			}
-		}
-	}
-
-	// Apply the answer to the cells.
-	filled := true
-	for i, num := range nums {
-		x := xs[num]
-		y := ys[num]
-
-		if answer[i] != '.' {
-			s.Cells[y][x] = string(answer[i])
-		} else {
-			s.Cells[y][x] = ""
-			filled = false
-		}
+		xs[i] = x
+		ys[i] = y
 	}
 
-	// Update whether or not the clue was filled.
-	s.CluesFilled[clue] = filled
-
-	// Also determine if the puzzle is finished with all correct answers and
-	// update the Status if so.
-	complete := true
-	for y := 0; y < s.Puzzle.Rows; y++ {
-		for x := 0; x < s.Puzzle.Cols; x++ {
-			if s.Cells[y][x] != s.Puzzle.Cells[y][x] {
-				complete = false
-			}
-		}
-	}
-	if complete {
-		s.Status = model.StatusComplete
+	if err := s.applyCells(xs, ys, answer, onlyCorrect); err != nil {
+		return fmt.Errorf("unable to apply answer %s to clue %s: %w", answer, clue, err)
 	}
 
 	return nil
@@ -139,6 +142,12 @@ func (s *State) ApplyCellAnswer(start int, answer string, onlyCorrect bool) erro
 	answer = strings.ReplaceAll(answer, " ", "")
 	answer = strings.ToUpper(answer)
 
+	// Expand any abbreviated runs of unknown cells.
+	answer, err := model.ExpandWildcards(answer)
+	if err != nil {
+		return err
+	}
+
 	// We also ignore any given characters in the puzzle (such as hyphens) as well
 	// that might be in the answer.  It's common that they're typed as part of the
 	// answer.
@@ -152,6 +161,9 @@ func (s *State) ApplyCellAnswer(start int, answer string, onlyCorrect bool) erro
 		}
 	}
 
+	// Every cell holds a single letter, so aliases such as "&" are spelled out.
+	answer = model.ExpandAnswerAliases(answer, model.AnswerAliases(s.AnswerAliases))
+
 	// Ensure that we have a non-empty answer.
 	if len(answer) == 0 {
 		return fmt.Errorf("empty answer")
@@ -172,6 +184,21 @@ func (s *State) ApplyCellAnswer(start int, answer string, onlyCorrect bool) erro
 		ys[i] = y
 	}
 
+	if err := s.applyCells(xs, ys, answer, onlyCorrect); err != nil {
+		return fmt.Errorf("unable to apply answer %s starting at index %d: %w", answer, start, err)
+	}
+
+	return nil
+}
+
+// applyCells writes the letters of an answer into the cells at the provided
+// coordinates, a "." clears a cell.  Clue answers and cell answers both go
+// through here so that they follow the same policy when they overlap: the most
+// recent answer wins, unless onlyCorrect is true in which case a correct cell
+// is locked and incorrect letters are rejected.  Afterwards which clues are
+// filled and whether or not the puzzle is complete are recomputed since a
+// single answer can touch the cells of many clues.
+func (s *State) applyCells(xs, ys []int, answer string, onlyCorrect bool) error {
 	// Check to see if the answer is correct when required.
 	if onlyCorrect {
 		for i := 0; i < len(answer); i++ {
@@ -184,12 +211,12 @@ func (s *State) ApplyCellAnswer(start int, answer string, onlyCorrect bool) erro
 
 			// We can't change a correct value to an incorrect or empty one.
 			if existing != "" && desired != existing {
-				return fmt.Errorf("unable to apply answer %s starting at index %d, changes correct value", answer, start)
+				return errors.New("changes correct value")
 			}
 
 			// We can't write an incorrect value into a cell
 			if desired != "." && desired != expected {
-				return fmt.Errorf("unable to apply answer %s starting at index %d, incorrect", answer, start)
+				return errors.New("incorrect")
 			}
 		}
 	}
@@ -341,7 +368,16 @@ func GetState(conn db.Connection, channel string) (State, error) {
 	}
 
 	err := db.Get(conn, StateKey(channel), &state)
-	return state, err
+	if err != nil {
+		return state, err
+	}
+
+	// If the server restarted while the channel was solving then make sure the
+	// downtime isn't counted towards the solve.
+	state.Status, state.LastStartTime, state.TotalSolveDuration =
+		model.ReconcileSolveTimer(state.Status, state.LastStartTime, state.LastSaveTime, state.TotalSolveDuration)
+
+	return state, nil
 }
 
 // SetState writes the state for a channel's crossword solve to redis.  If the
@@ -351,5 +387,12 @@ func SetState(conn db.Connection, channel string, state State) error {
 		return testStateSaveError
 	}
 
-	return db.SetWithTTL(conn, StateKey(channel), state, StateTTL)
+	now := time.Now()
+	state.LastSaveTime = &now
+
+	if err := db.SetWithTTL(conn, StateKey(channel), state, StateTTL); err != nil {
+		return err
+	}
+
+	return model.RecordActivity(conn, "acrostic", channel, now)
 }
@@ -1104,6 +1104,18 @@ func TestState_ApplyCellAnswer_Error(t *testing.T) {
 			start:    173,
 			answer:   "ABCDEF",
 		},
+		{
+			name:     "answer overruns short final row",
+			filename: "xwordinfo-nyt-20200607-partial-last-row.json",
+			start:    168,
+			answer:   "SEENIT",
+		},
+		{
+			name:     "start id past end of short final row",
+			filename: "xwordinfo-nyt-20200607-partial-last-row.json",
+			start:    172,
+			answer:   "S",
+		},
 		{
 			name:     "only spaces",
 			filename: "xwordinfo-nyt-20200524.json",
@@ -1136,6 +1148,228 @@ func TestState_ApplyCellAnswer_Error(t *testing.T) {
 	}
 }
 
+func TestState_ApplyCellAnswer_ShortFinalRow(t *testing.T) {
+	// The final row of this puzzle only has cells up through the 21st column,
+	// the remaining columns of the row don't exist.
+	state := NewState(t, "xwordinfo-nyt-20200607-partial-last-row.json")
+	last := state.Puzzle.Rows - 1
+
+	// An answer that wraps onto the final row.
+	require.NoError(t, state.ApplyCellAnswer(153, "CHANGES", false))
+	assert.Equal(t, "C", state.Cells[last-1][26])
+	assert.Equal(t, []string{"H", "A", "N", "G", "E", "S"}, state.Cells[last][:6])
+
+	// An answer that ends exactly at the end of the quote.
+	require.NoError(t, state.ApplyCellAnswer(168, "SEEN", false))
+	assert.Equal(t, []string{"S", "E", "E", "N"}, state.Cells[last][17:21])
+
+	// An answer that would overrun the end of the quote isn't applied at all.
+	require.Error(t, state.ApplyCellAnswer(168, "SEENIT", false))
+	assert.Equal(t, []string{"S", "E", "E", "N"}, state.Cells[last][17:21])
+
+	// None of the nonexistent trailing cells should ever be written to.
+	for x := 21; x < state.Puzzle.Cols; x++ {
+		assert.True(t, state.Puzzle.CellBlocks[last][x])
+		assert.Equal(t, "", state.Cells[last][x])
+	}
+}
+
+func TestState_ApplyAnswer_WildcardCount(t *testing.T) {
+	dots := NewState(t, "xwordinfo-nyt-20200524.json")
+	require.NoError(t, dots.ApplyClueAnswer("A", "WH???S", false))
+
+	count := NewState(t, "xwordinfo-nyt-20200524.json")
+	require.NoError(t, count.ApplyClueAnswer("A", "WH?3S", false))
+
+	assert.Equal(t, dots.Cells, count.Cells)
+	assert.Equal(t, "W", count.Cells[1][10])
+	assert.Equal(t, "H", count.Cells[5][9])
+	assert.Equal(t, "", count.Cells[2][4])
+	assert.Equal(t, "", count.Cells[7][14])
+	assert.Equal(t, "", count.Cells[0][18])
+	assert.Equal(t, "S", count.Cells[2][24])
+	assert.False(t, count.CluesFilled["A"])
+
+	// The same applies to answers starting at a cell.
+	dots = NewState(t, "xwordinfo-nyt-20200524.json")
+	require.NoError(t, dots.ApplyCellAnswer(1, "A???E", false))
+
+	count = NewState(t, "xwordinfo-nyt-20200524.json")
+	require.NoError(t, count.ApplyCellAnswer(1, "A?3E", false))
+
+	assert.Equal(t, dots.Cells, count.Cells)
+	assert.Error(t, count.ApplyCellAnswer(1, "A??3E", false))
+}
+
+func TestState_ApplyAnswer_Aliases(t *testing.T) {
+	tests := []struct {
+		name    string
+		clue    string
+		aliases map[string]string // additional aliases from the settings
+		answer  string
+	}{
+		{
+			name:   "ampersand for and",
+			clue:   "P",
+			answer: "THAIL&",
+		},
+		{
+			name:   "ampersand within answer",
+			clue:   "E",
+			answer: "ALLEM&E",
+		},
+		{
+			name:    "additional alias",
+			clue:    "W",
+			aliases: map[string]string{"$": "SS"},
+			answer:  "A$A$INS",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := NewState(t, "xwordinfo-nyt-20200524.json")
+			state.AnswerAliases = test.aliases
+
+			require.NoError(t, state.ApplyClueAnswer(test.clue, test.answer, true))
+			assert.True(t, state.CluesFilled[test.clue])
+		})
+	}
+
+	// Aliases are also accepted in answers starting at a cell.
+	for alias, canonical := range map[string]string{"&": "AND", "1": "ONE", "10": "TEN"} {
+		state := NewState(t, "xwordinfo-nyt-20200524.json")
+		expected := NewState(t, "xwordinfo-nyt-20200524.json")
+		require.NoError(t, state.ApplyCellAnswer(1, alias, false))
+		require.NoError(t, expected.ApplyCellAnswer(1, canonical, false))
+		assert.Equal(t, expected.Cells, state.Cells)
+	}
+}
+
+func TestState_ApplyAnswer_ClueAndCellAnswersOverlap(t *testing.T) {
+	// Clue A is made up of cells 33, 122, 52, 167, 17 and 69.  Cells 68 and 70
+	// belong to clues N and I respectively.
+	tests := []struct {
+		name   string
+		apply  func(*testing.T, *State)
+		verify func(*testing.T, State)
+	}{
+		{
+			name: "cell answer completes clue",
+			apply: func(t *testing.T, state *State) {
+				require.NoError(t, state.ApplyClueAnswer("A", "WHALE.", false))
+				require.False(t, state.CluesFilled["A"])
+				require.NoError(t, state.ApplyCellAnswer(69, "S", false))
+			},
+			verify: func(t *testing.T, state State) {
+				assert.True(t, state.CluesFilled["A"])
+			},
+		},
+		{
+			name: "cell answer clears cell of filled clue",
+			apply: func(t *testing.T, state *State) {
+				require.NoError(t, state.ApplyClueAnswer("A", "WHALES", false))
+				require.True(t, state.CluesFilled["A"])
+				require.NoError(t, state.ApplyCellAnswer(69, ".", false))
+			},
+			verify: func(t *testing.T, state State) {
+				assert.False(t, state.CluesFilled["A"])
+			},
+		},
+		{
+			name: "cell answer spanning clues updates each clue",
+			apply: func(t *testing.T, state *State) {
+				require.NoError(t, state.ApplyClueAnswer("A", "WHALE.", false))
+				require.NoError(t, state.ApplyCellAnswer(68, "OST", false))
+			},
+			verify: func(t *testing.T, state State) {
+				assert.True(t, state.CluesFilled["A"])
+				assert.False(t, state.CluesFilled["N"])
+				assert.False(t, state.CluesFilled["I"])
+			},
+		},
+		{
+			name: "clue answer completes clue started by cell answers",
+			apply: func(t *testing.T, state *State) {
+				require.NoError(t, state.ApplyCellAnswer(33, "W", false))
+				require.NoError(t, state.ApplyCellAnswer(69, "S", false))
+				require.False(t, state.CluesFilled["A"])
+				require.NoError(t, state.ApplyClueAnswer("A", "WHALES", false))
+			},
+			verify: func(t *testing.T, state State) {
+				assert.True(t, state.CluesFilled["A"])
+			},
+		},
+		{
+			name: "clue answer clears cell filled by cell answer",
+			apply: func(t *testing.T, state *State) {
+				require.NoError(t, state.ApplyClueAnswer("A", "WHALE.", false))
+				require.NoError(t, state.ApplyCellAnswer(69, "S", false))
+				require.True(t, state.CluesFilled["A"])
+				require.NoError(t, state.ApplyClueAnswer("A", "WHALE.", false))
+			},
+			verify: func(t *testing.T, state State) {
+				assert.False(t, state.CluesFilled["A"])
+				assert.Equal(t, "", cell(t, state, 69))
+			},
+		},
+		{
+			name: "last write wins",
+			apply: func(t *testing.T, state *State) {
+				require.NoError(t, state.ApplyClueAnswer("A", "WHALES", false))
+				require.NoError(t, state.ApplyCellAnswer(33, "X", false))
+			},
+			verify: func(t *testing.T, state State) {
+				assert.Equal(t, "X", cell(t, state, 33))
+				assert.True(t, state.CluesFilled["A"])
+			},
+		},
+		{
+			name: "correct cell from clue answer is locked for cell answers",
+			apply: func(t *testing.T, state *State) {
+				require.NoError(t, state.ApplyClueAnswer("A", "WHALES", true))
+				require.Error(t, state.ApplyCellAnswer(33, "X", true))
+				require.Error(t, state.ApplyCellAnswer(33, ".", true))
+			},
+			verify: func(t *testing.T, state State) {
+				assert.Equal(t, "W", cell(t, state, 33))
+				assert.True(t, state.CluesFilled["A"])
+			},
+		},
+		{
+			name: "correct cell from cell answer is locked for clue answers",
+			apply: func(t *testing.T, state *State) {
+				require.NoError(t, state.ApplyCellAnswer(69, "S", true))
+				require.Error(t, state.ApplyClueAnswer("A", "WHALE.", true))
+				require.Error(t, state.ApplyClueAnswer("A", "WHALEX", true))
+			},
+			verify: func(t *testing.T, state State) {
+				assert.Equal(t, "S", cell(t, state, 69))
+				assert.Equal(t, "", cell(t, state, 33))
+				assert.False(t, state.CluesFilled["A"])
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := NewState(t, "xwordinfo-nyt-20200524.json")
+			test.apply(t, &state)
+			test.verify(t, state)
+		})
+	}
+}
+
+// cell returns the current value of the numbered cell of the state's puzzle.
+func cell(t *testing.T, state State, num int) string {
+	t.Helper()
+
+	x, y, err := state.Puzzle.GetCellCoordinates(num)
+	require.NoError(t, err)
+
+	return state.Cells[y][x]
+}
+
 func TestState_ClearIncorrectCells(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -1207,6 +1441,30 @@ func TestState_ClearIncorrectCells(t *testing.T) {
 	}
 }
 
+func TestState_ResetEphemeralState(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20200524.json")
+	require.NoError(t, state.ApplyClueAnswer("A", "WHALES", false))
+	state.Status = model.StatusSolving
+	state.TotalSolveDuration = model.Duration{Duration: 10 * time.Minute}
+
+	puzzle := LoadTestPuzzle(t, "xwordinfo-nyt-20011007-given-cell.json")
+	state.resetEphemeralState(puzzle)
+
+	assert.Equal(t, model.StatusSelected, state.Status)
+	assert.Equal(t, puzzle, state.Puzzle)
+	assert.Equal(t, puzzle.Rows, len(state.Cells))
+	for y, row := range state.Cells {
+		assert.Equal(t, puzzle.Cols, len(row))
+		for x, cell := range row {
+			// Only givens should be filled in.
+			assert.Equal(t, puzzle.Givens[y][x], cell)
+		}
+	}
+	assert.Empty(t, state.CluesFilled)
+	assert.Nil(t, state.LastStartTime)
+	assert.Equal(t, time.Duration(0), state.TotalSolveDuration.Duration)
+}
+
 func TestGetAllChannels(t *testing.T) {
 	type ChannelToCreate struct {
 		name     string
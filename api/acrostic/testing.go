@@ -205,6 +205,16 @@ func NewTestRouter(t *testing.T) (chi.Router, *redis.Pool, *pubsub.Registry) {
 	// Create the pubsub registry.
 	registry := new(pubsub.Registry)
 
+	// Don't make streams wait long when retrying their initial events.
+	retryDelay := pubsub.InitialEventsRetryDelay
+	recoveryInterval := pubsub.InitialEventsRecoveryInterval
+	pubsub.InitialEventsRetryDelay = time.Millisecond
+	pubsub.InitialEventsRecoveryInterval = 10 * time.Millisecond
+	t.Cleanup(func() {
+		pubsub.InitialEventsRetryDelay = retryDelay
+		pubsub.InitialEventsRecoveryInterval = recoveryInterval
+	})
+
 	// Setup the chi router and wire it up to the redis pool and pubsub registry.
 	router := chi.NewRouter()
 	RegisterRoutes(router, pool, registry)
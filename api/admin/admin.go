@@ -0,0 +1,31 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Token is the secret that must be provided to access administrative
+// endpoints.  When it's empty administrative endpoints are disabled.
+var Token string
+
+// Required is middleware that only allows a request through when it presents
+// the admin token as a bearer token in its Authorization header.  Requests are
+// always rejected when no admin token has been configured.
+func Required(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if Token == "" {
+			http.Error(w, "admin endpoints are disabled", http.StatusForbidden)
+			return
+		}
+
+		provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(Token)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequired(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		expected      int
+	}{
+		{
+			name:          "correct token",
+			token:         "secret",
+			authorization: "Bearer secret",
+			expected:      http.StatusOK,
+		},
+		{
+			name:          "incorrect token",
+			token:         "secret",
+			authorization: "Bearer guess",
+			expected:      http.StatusUnauthorized,
+		},
+		{
+			name:     "missing token",
+			token:    "secret",
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:          "admin endpoints disabled",
+			token:         "",
+			authorization: "Bearer ",
+			expected:      http.StatusForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			Token = test.token
+			defer func() { Token = "" }()
+
+			handler := Required(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.authorization != "" {
+				request.Header.Set("Authorization", test.authorization)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			assert.Equal(t, test.expected, recorder.Code)
+		})
+	}
+}
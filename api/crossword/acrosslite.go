@@ -0,0 +1,220 @@
+package crossword
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// LoadFromAcrossLiteText parses the pasted contents of an Across Lite text
+// file into a Puzzle object.
+func LoadFromAcrossLiteText(text string) (*Puzzle, error) {
+	if testPuzzle != nil {
+		return testPuzzle, nil
+	}
+
+	if testPuzzleLoadError != nil {
+		return nil, testPuzzleLoadError
+	}
+
+	return ParseAcrossLiteText(strings.NewReader(text))
+}
+
+// ParseAcrossLiteText parses an Across Lite text file into a Puzzle object.
+//
+// The file is a series of sections that each start with a tag such as <TITLE>
+// on a line of its own.  The grid is written one row per line with a . for each
+// block, and the clues are listed in the order of their numbers without the
+// numbers themselves.  Version 2 files (<ACROSS PUZZLE V2>) may also contain a
+// <REBUS> section.  Each of its entries maps a marker character used in the
+// grid to the full contents of a rebus cell (e.g. 1:HEART:H), and a MARK;
+// entry indicates that lowercase letters in the grid are circled.
+//
+// Details on the file format can be found at:
+//
+//	https://www.litsoft.com/across/docs/AcrossTextFormat.pdf
+func ParseAcrossLiteText(in io.Reader) (*Puzzle, error) {
+	sections := make(map[string][]string)
+
+	var version, section string
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if version == "" {
+			if line == "" {
+				continue
+			}
+
+			if line != "<ACROSS PUZZLE>" && line != "<ACROSS PUZZLE V2>" {
+				return nil, fmt.Errorf("unrecognized across lite header: %s", line)
+			}
+
+			version = line
+			continue
+		}
+
+		if strings.HasPrefix(line, "<") && strings.HasSuffix(line, ">") {
+			section = strings.ToUpper(line[1 : len(line)-1])
+			if _, ok := sections[section]; ok {
+				return nil, fmt.Errorf("duplicate across lite section %s", section)
+			}
+			sections[section] = []string{}
+			continue
+		}
+
+		// Blank lines separate nothing in the format, except in the notepad where
+		// they separate paragraphs.
+		if line == "" && section != "NOTEPAD" {
+			continue
+		}
+
+		if section == "" {
+			return nil, fmt.Errorf("across lite text outside of a section: %s", line)
+		}
+
+		sections[section] = append(sections[section], line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if version == "" {
+		return nil, errors.New("missing across lite header")
+	}
+
+	for _, name := range []string{"SIZE", "GRID", "ACROSS", "DOWN"} {
+		if _, ok := sections[name]; !ok {
+			return nil, fmt.Errorf("missing across lite section %s", name)
+		}
+	}
+
+	var puzzle Puzzle
+	puzzle.Description = "Crossword loaded from Across Lite text"
+	puzzle.Title = strings.Join(sections["TITLE"], " ")
+	puzzle.Copyright = strings.Join(sections["COPYRIGHT"], " ")
+	puzzle.Notes = strings.TrimSpace(strings.Join(sections["NOTEPAD"], "\n"))
+
+	puzzle.Author = strings.Join(sections["AUTHOR"], " ")
+	if strings.HasPrefix(puzzle.Author, "by ") || strings.HasPrefix(puzzle.Author, "By ") {
+		puzzle.Author = puzzle.Author[3:]
+	}
+
+	// The size is written as the number of columns by the number of rows.
+	if len(sections["SIZE"]) != 1 {
+		return nil, fmt.Errorf("malformed across lite size: %v", sections["SIZE"])
+	}
+	if _, err := fmt.Sscanf(strings.ToLower(sections["SIZE"][0]), "%dx%d", &puzzle.Cols, &puzzle.Rows); err != nil {
+		return nil, fmt.Errorf("malformed across lite size %s: %v", sections["SIZE"][0], err)
+	}
+	if puzzle.Rows <= 0 || puzzle.Cols <= 0 {
+		return nil, fmt.Errorf("invalid dimensions %dx%d", puzzle.Rows, puzzle.Cols)
+	}
+	puzzle.Variant = ClassifyVariant(puzzle.Rows, puzzle.Cols)
+
+	// Parse the rebus table if the file has one.
+	rebuses := make(map[rune]string)
+	var marked bool
+	for _, entry := range sections["REBUS"] {
+		if strings.ToUpper(entry) == "MARK;" {
+			marked = true
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 || len([]rune(parts[0])) != 1 || parts[1] == "" {
+			return nil, fmt.Errorf("malformed across lite rebus entry: %s", entry)
+		}
+		rebuses[[]rune(parts[0])[0]] = strings.ToUpper(parts[1])
+	}
+
+	// Determine the value of each cell and whether or not it's a block or has a
+	// circle.  The format has no way to shade a cell.
+	grid := sections["GRID"]
+	if len(grid) != puzzle.Rows {
+		return nil, fmt.Errorf("incorrect number of grid rows (%d) for a %dx%d grid", len(grid), puzzle.Rows, puzzle.Cols)
+	}
+
+	for y, line := range grid {
+		row := []rune(line)
+		if len(row) != puzzle.Cols {
+			return nil, fmt.Errorf("incorrect number of grid cells (%d) in row %d", len(row), y)
+		}
+
+		puzzle.Cells = append(puzzle.Cells, make([]string, puzzle.Cols))
+		puzzle.CellBlocks = append(puzzle.CellBlocks, make([]bool, puzzle.Cols))
+		puzzle.CellCircles = append(puzzle.CellCircles, make([]bool, puzzle.Cols))
+		puzzle.CellShades = append(puzzle.CellShades, make([]bool, puzzle.Cols))
+		for x, c := range row {
+			if c == '.' {
+				puzzle.CellBlocks[y][x] = true
+				continue
+			}
+
+			if rebus, ok := rebuses[c]; ok {
+				puzzle.Cells[y][x] = rebus
+				continue
+			}
+
+			if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+				return nil, fmt.Errorf("unrecognized grid cell %c at (%d, %d)", c, x, y)
+			}
+
+			puzzle.Cells[y][x] = strings.ToUpper(string(c))
+			if marked && unicode.IsLower(c) {
+				puzzle.CellCircles[y][x] = true
+			}
+		}
+	}
+
+	// The clues don't include their numbers, so they're assigned to the cells
+	// that start an answer in the order that the cells are numbered.
+	puzzle.CellClueNumbers = NumberCells(puzzle.CellBlocks)
+	puzzle.CluesAcross = make(map[int]string)
+	puzzle.CluesDown = make(map[int]string)
+
+	across, down := sections["ACROSS"], sections["DOWN"]
+	for y := 0; y < puzzle.Rows; y++ {
+		for x := 0; x < puzzle.Cols; x++ {
+			if puzzle.CellBlocks[y][x] {
+				continue
+			}
+
+			num := puzzle.CellClueNumbers[y][x]
+
+			isLeftABlock := x == 0 || puzzle.CellBlocks[y][x-1]
+			isRightABlock := x >= puzzle.Cols-1 || puzzle.CellBlocks[y][x+1]
+			if isLeftABlock && !isRightABlock {
+				if len(across) == 0 {
+					return nil, fmt.Errorf("missing across clue %d", num)
+				}
+				if err := AddClue(puzzle.CluesAcross, "across", num, model.UnescapeClue(across[0])); err != nil {
+					return nil, err
+				}
+				across = across[1:]
+			}
+
+			isUpABlock := y == 0 || puzzle.CellBlocks[y-1][x]
+			isDownABlock := y >= puzzle.Rows-1 || puzzle.CellBlocks[y+1][x]
+			if isUpABlock && !isDownABlock {
+				if len(down) == 0 {
+					return nil, fmt.Errorf("missing down clue %d", num)
+				}
+				if err := AddClue(puzzle.CluesDown, "down", num, model.UnescapeClue(down[0])); err != nil {
+					return nil, err
+				}
+				down = down[1:]
+			}
+		}
+	}
+
+	if len(across) != 0 || len(down) != 0 {
+		return nil, fmt.Errorf("%d across and %d down clues don't belong to the grid", len(across), len(down))
+	}
+
+	return &puzzle, nil
+}
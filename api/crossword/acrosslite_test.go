@@ -0,0 +1,172 @@
+package crossword
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestParseAcrossLiteText(t *testing.T) {
+	reader := load(t, path.Join("acrosslite", "rebus-and-circles.txt"))
+	defer reader.Close()
+
+	puzzle, err := ParseAcrossLiteText(reader)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Crossword loaded from Across Lite text", puzzle.Description)
+	assert.Equal(t, 4, puzzle.Rows)
+	assert.Equal(t, 4, puzzle.Cols)
+	assert.Equal(t, VariantMini, puzzle.Variant)
+	assert.Equal(t, "Card Tricks", puzzle.Title)
+	assert.Equal(t, "Test Author", puzzle.Author)
+	assert.Equal(t, "The first square holds more than one letter.", puzzle.Notes)
+
+	// The rebus marker is replaced by the full contents of its cell.
+	assert.Equal(t, [][]string{
+		{"CH", "A", "R", "D"},
+		{"A", "R", "E", "A"},
+		{"R", "E", "A", "R"},
+		{"D", "A", "R", "T"},
+	}, puzzle.Cells)
+
+	assert.Equal(t, [][]int{
+		{1, 2, 3, 4},
+		{5, 0, 0, 0},
+		{6, 0, 0, 0},
+		{7, 0, 0, 0},
+	}, puzzle.CellClueNumbers)
+
+	// Lowercase letters are circled.
+	assert.Equal(t, [][]bool{
+		{false, false, false, false},
+		{false, false, false, false},
+		{false, false, true, false},
+		{false, false, true, false},
+	}, puzzle.CellCircles)
+
+	assert.Equal(t, map[int]string{
+		1: "Swiss ___ (leafy green)",
+		5: "Region",
+		6: "Back",
+		7: "Pub missile",
+	}, puzzle.CluesAcross)
+
+	assert.Equal(t, map[int]string{
+		1: "Beet relative",
+		2: "Field",
+		3: "Behind",
+		4: "Quick run",
+	}, puzzle.CluesDown)
+
+	require.NoError(t, puzzle.Validate())
+	answer, err := puzzle.Answer(1, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "CHARD", answer)
+}
+
+func TestParseAcrossLiteText_Blocks(t *testing.T) {
+	// Version 1 files don't have a rebus section, so lowercase letters are just
+	// letters.
+	text := `
+		<ACROSS PUZZLE>
+		<TITLE>
+			Blocks
+		<SIZE>
+			3x3
+		<GRID>
+			ab.
+			CDE
+			.fg
+		<ACROSS>
+			AB
+			CDE
+			FG
+		<DOWN>
+			AC
+			BDF
+			EG
+	`
+
+	puzzle, err := ParseAcrossLiteText(strings.NewReader(text))
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{
+		{"A", "B", ""},
+		{"C", "D", "E"},
+		{"", "F", "G"},
+	}, puzzle.Cells)
+
+	assert.Equal(t, [][]bool{
+		{false, false, true},
+		{false, false, false},
+		{true, false, false},
+	}, puzzle.CellBlocks)
+
+	assert.Equal(t, map[int]string{1: "AB", 3: "CDE", 5: "FG"}, puzzle.CluesAcross)
+	assert.Equal(t, map[int]string{1: "AC", 2: "BDF", 4: "EG"}, puzzle.CluesDown)
+
+	for _, row := range puzzle.CellCircles {
+		assert.NotContains(t, row, true)
+	}
+}
+
+func TestParseAcrossLiteText_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{
+			name: "empty",
+			text: ``,
+		},
+		{
+			name: "unrecognized header",
+			text: "<ACROSS PUZZLE V9>\n<SIZE>\n1x1\n<GRID>\nA\n<ACROSS>\n<DOWN>",
+		},
+		{
+			name: "missing grid",
+			text: "<ACROSS PUZZLE>\n<SIZE>\n1x1\n<ACROSS>\n<DOWN>",
+		},
+		{
+			name: "malformed size",
+			text: "<ACROSS PUZZLE>\n<SIZE>\n1 by 1\n<GRID>\nA\n<ACROSS>\n<DOWN>",
+		},
+		{
+			name: "incorrect number of rows",
+			text: "<ACROSS PUZZLE>\n<SIZE>\n1x2\n<GRID>\nA\n<ACROSS>\n<DOWN>",
+		},
+		{
+			name: "incorrect number of columns",
+			text: "<ACROSS PUZZLE>\n<SIZE>\n2x1\n<GRID>\nABC\n<ACROSS>\nABC\n<DOWN>",
+		},
+		{
+			name: "unknown rebus marker",
+			text: "<ACROSS PUZZLE V2>\n<SIZE>\n2x1\n<GRID>\nA@\n<ACROSS>\nA@\n<DOWN>",
+		},
+		{
+			name: "malformed rebus entry",
+			text: "<ACROSS PUZZLE V2>\n<SIZE>\n2x1\n<GRID>\nA1\n<REBUS>\n1=ONE\n<ACROSS>\nA1\n<DOWN>",
+		},
+		{
+			name: "missing clue",
+			text: "<ACROSS PUZZLE>\n<SIZE>\n2x2\n<GRID>\nAB\nCD\n<ACROSS>\nAB\n<DOWN>\nAC\nBD",
+		},
+		{
+			name: "extra clue",
+			text: "<ACROSS PUZZLE>\n<SIZE>\n2x1\n<GRID>\nAB\n<ACROSS>\nAB\nCD\n<DOWN>",
+		},
+		{
+			name: "text outside of a section",
+			text: "<ACROSS PUZZLE>\nstray\n<SIZE>\n1x1\n<GRID>\nA\n<ACROSS>\n<DOWN>",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseAcrossLiteText(strings.NewReader(test.text))
+			assert.Error(t, err)
+		})
+	}
+}
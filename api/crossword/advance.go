@@ -0,0 +1,217 @@
+package crossword
+
+import (
+	"context"
+	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/db"
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"github.com/bbeck/puzzles-with-chat/api/pubsub"
+	"github.com/gomodule/redigo/redis"
+	"log"
+	"time"
+)
+
+// AutoAdvanceDelay is how long to wait after a channel completes a puzzle
+// before automatically selecting the next one.  This gives the channel a
+// chance to see the completed puzzle before it's replaced.
+var AutoAdvanceDelay = 20 * time.Second
+
+// AutoAdvanceInterval is how frequently channels are checked for completed
+// puzzles whose advance time has passed.  This catches advances whose timers
+// were lost, for example because the server restarted.
+var AutoAdvanceInterval = time.Minute
+
+// PendingAdvancesKey is the key of the sorted set in redis that tracks the
+// channels with a completed puzzle waiting to be advanced.  Members of the set
+// are channel names and are scored by the advance time in milliseconds since
+// the epoch.
+const PendingAdvancesKey = "crossword:pending-advances"
+
+// AddPendingAdvance records that the provided channel should advance from its
+// completed puzzle at the provided deadline.
+func AddPendingAdvance(conn db.Connection, channel string, deadline time.Time) error {
+	_, err := conn.Do("ZADD", PendingAdvancesKey, toMillis(deadline), channel)
+	return err
+}
+
+// RemovePendingAdvance removes the provided channel from the set of channels
+// waiting to be advanced.
+func RemovePendingAdvance(conn db.Connection, channel string) error {
+	_, err := conn.Do("ZREM", PendingAdvancesKey, channel)
+	return err
+}
+
+// AdvancePuzzle selects the puzzle that was published before a channel's
+// completed puzzle by the same source once its advance time has arrived.  The
+// advance only happens if the channel's state still has a pending advance at
+// the provided deadline for the provided puzzle, if the channel has since moved
+// on to another puzzle or already advanced then nothing happens.  The state is
+// watched while the next puzzle is loaded so that a change made in the meantime
+// isn't overwritten.  Whether or not the channel advanced is returned.
+func AdvancePuzzle(conn redis.Conn, registry *pubsub.Registry, channel string, deadline time.Time, completed *Puzzle) (bool, error) {
+	if _, err := conn.Do("WATCH", StateKey(channel)); err != nil {
+		return false, err
+	}
+	defer func() { _, _ = conn.Do("UNWATCH") }()
+
+	state, err := GetState(conn, channel)
+	if err != nil {
+		return false, err
+	}
+
+	if state.Status != model.StatusComplete || state.Puzzle == nil || state.Source == "" {
+		return false, nil
+	}
+	if state.AdvanceTime == nil || !state.AdvanceTime.Equal(deadline) {
+		return false, nil
+	}
+
+	// The completed puzzle may have had its solution removed before it was sent
+	// to clients, so only what's left after removing it is compared.
+	if !state.Puzzle.WithoutSolution().IsSamePuzzle(completed.WithoutSolution()) {
+		return false, nil
+	}
+
+	date, ok := PreviousAvailableDate(state.Source, state.Puzzle.PublishedDate)
+	if !ok {
+		return false, fmt.Errorf("no %s puzzle before %s to advance to", state.Source, state.Puzzle.PublishedDate.Format("2006-01-02"))
+	}
+
+	puzzle, err := LoadCachedPuzzle(state.Source, date.Format("2006-01-02"))
+	if err != nil {
+		return false, err
+	}
+
+	next := State{Source: state.Source, Practice: state.Practice}
+	next.resetEphemeralState(puzzle)
+
+	if err := conn.Send("MULTI"); err != nil {
+		return false, err
+	}
+	if err := SetState(conn, channel, next); err != nil {
+		_, _ = conn.Do("DISCARD")
+		return false, err
+	}
+
+	// If the state changed while the next puzzle was loading then the channel
+	// did something else with it and the advance no longer applies.  An aborted
+	// transaction has no replies.
+	replies, err := redis.Values(conn.Do("EXEC"))
+	if err == redis.ErrNil || (err == nil && len(replies) == 0) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := RemovePendingAdvance(conn, channel); err != nil {
+		log.Printf("unable to remove pending advance for channel %s: %+v", channel, err)
+	}
+
+	// Proposals for the previous puzzle don't apply to this one.
+	if err := SetProposals(conn, channel, nil); err != nil {
+		log.Printf("unable to remove proposals for channel %s: %+v", channel, err)
+	}
+
+	next.Puzzle = next.Puzzle.WithoutSolution()
+	registry.Publish(ChannelID(channel), StateEvent(next))
+	return true, nil
+}
+
+// AdvancePendingPuzzles advances every channel in the set of pending advances
+// whose advance time is at or before the provided time.  A channel that can't be
+// advanced is logged and skipped so that it doesn't hold up the others, it
+// remains pending and is tried again the next time.  Channels that no longer
+// have a pending advance are removed from the set.  The names of the channels
+// that advanced are returned.
+func AdvancePendingPuzzles(conn redis.Conn, registry *pubsub.Registry, now time.Time) ([]string, error) {
+	channels, err := redis.Strings(conn.Do("ZRANGEBYSCORE", PendingAdvancesKey, "-inf", toMillis(now)))
+	if err != nil {
+		return nil, err
+	}
+
+	var advanced []string
+	for _, channel := range channels {
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			continue
+		}
+
+		// The channel may have moved on since the advance was scheduled, and if
+		// it has a new advance then that one was added to the set in its place.
+		if state.AdvanceTime == nil || state.Puzzle == nil {
+			if err := RemovePendingAdvance(conn, channel); err != nil {
+				log.Printf("unable to remove pending advance for channel %s: %+v", channel, err)
+			}
+			continue
+		}
+		if state.AdvanceTime.After(now) {
+			continue
+		}
+
+		done, err := AdvancePuzzle(conn, registry, channel, *state.AdvanceTime, state.Puzzle)
+		if err != nil {
+			log.Printf("unable to advance puzzle for channel %s: %+v", channel, err)
+			continue
+		}
+		if done {
+			advanced = append(advanced, channel)
+		}
+	}
+
+	return advanced, nil
+}
+
+// StartPuzzleAdvancer periodically advances the channels whose completed
+// puzzles have an advance time that has passed until the provided context is
+// cancelled.
+func StartPuzzleAdvancer(ctx context.Context, pool *redis.Pool, registry *pubsub.Registry) {
+	go func() {
+		ticker := time.NewTicker(AutoAdvanceInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			conn := pool.Get()
+			channels, err := AdvancePendingPuzzles(conn, registry, time.Now())
+			_ = conn.Close()
+
+			if err != nil {
+				log.Printf("unable to advance pending puzzles: %+v", err)
+			}
+			if len(channels) > 0 {
+				log.Printf("advanced pending puzzles for channels: %v", channels)
+			}
+		}
+	}()
+}
+
+// scheduleAdvance arranges for a channel to advance from its completed puzzle
+// at the provided deadline.  The advance is recorded in redis as well as being
+// put on a timer because the timer doesn't survive a restart of the server,
+// StartPuzzleAdvancer picks up any advances that are missed because of that.
+func scheduleAdvance(conn redis.Conn, pool *redis.Pool, registry *pubsub.Registry, channel string, deadline time.Time, completed *Puzzle) {
+	if err := AddPendingAdvance(conn, channel, deadline); err != nil {
+		log.Printf("unable to record pending advance for channel %s: %+v", channel, err)
+	}
+
+	time.AfterFunc(time.Until(deadline), func() {
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		if _, err := AdvancePuzzle(conn, registry, channel, deadline, completed); err != nil {
+			log.Printf("unable to advance puzzle for channel %s: %+v", channel, err)
+		}
+	})
+}
+
+// toMillis converts a time into the number of milliseconds since the epoch.
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
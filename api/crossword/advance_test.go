@@ -0,0 +1,182 @@
+package crossword
+
+import (
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// ForcePreviousPuzzles sets up the New York Times loader to return the test
+// puzzle with the requested date as its published date.
+func ForcePreviousPuzzles(t *testing.T) {
+	t.Helper()
+
+	ForcePuzzleLoader(t, "new_york_times", func(date string) (*Puzzle, error) {
+		published, err := time.Parse("2006-01-02", date)
+		require.NoError(t, err)
+
+		puzzle := LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json")
+		puzzle.PublishedDate = published
+		return puzzle, nil
+	})
+}
+
+func TestAdvancePendingPuzzles(t *testing.T) {
+	_, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, "pending")
+	ForcePreviousPuzzles(t)
+
+	now := time.Now()
+	for _, channel := range []string{"pending", "later", "advanced"} {
+		state := NewState(t, "xwordinfo-nyt-20181231.json")
+		state.Status = model.StatusComplete
+		state.Source = "new_york_times"
+		if channel != "advanced" {
+			advance := now.Add(10 * time.Millisecond)
+			if channel == "later" {
+				advance = now.Add(time.Hour)
+			}
+			state.AdvanceTime = &advance
+			require.NoError(t, AddPendingAdvance(conn, channel, advance))
+		}
+		require.NoError(t, SetState(conn, channel, state))
+	}
+
+	// A channel that was pending but has since moved on to another puzzle.
+	require.NoError(t, AddPendingAdvance(conn, "abandoned", now))
+
+	// Only the channel whose advance time has passed advances.
+	channels, err := AdvancePendingPuzzles(conn, registry, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pending"}, channels)
+
+	pending, err := GetState(conn, "pending")
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusSelected, pending.Status)
+	assert.Equal(t, "2018-12-30", pending.Puzzle.PublishedDate.Format("2006-01-02"))
+	assert.Nil(t, pending.AdvanceTime)
+
+	later, err := GetState(conn, "later")
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusComplete, later.Status)
+	assert.NotNil(t, later.AdvanceTime)
+
+	found := Events(events, "state")
+	require.Equal(t, 1, len(found))
+	assert.Equal(t, model.StatusSelected, found[0].Payload.(State).Status)
+
+	// Only the channel that's still waiting remains pending.
+	pendings, err := redis.Strings(conn.Do("ZRANGE", PendingAdvancesKey, 0, -1))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"later"}, pendings)
+
+	// An advance is only ever done once.
+	channels, err = AdvancePendingPuzzles(conn, registry, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Empty(t, channels)
+}
+
+func TestAdvancePuzzle_StateChangedDuringLoad(t *testing.T) {
+	_, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	advance := time.Now()
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusComplete
+	state.Source = "new_york_times"
+	state.AdvanceTime = &advance
+	require.NoError(t, SetState(conn, Channel.name, state))
+	require.NoError(t, AddPendingAdvance(conn, Channel.name, advance))
+	completed := state.Puzzle
+
+	// The channel selects a different puzzle while the next puzzle is being
+	// downloaded.
+	other := LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json")
+	other.PublishedDate = other.PublishedDate.AddDate(0, 0, -7)
+	ForcePuzzleLoader(t, "new_york_times", func(date string) (*Puzzle, error) {
+		selected := state
+		selected.resetEphemeralState(other)
+		require.NoError(t, SetState(NewRedisConnection(t, pool), Channel.name, selected))
+
+		return LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json"), nil
+	})
+
+	advanced, err := AdvancePuzzle(conn, registry, Channel.name, advance, completed)
+	require.NoError(t, err)
+	assert.False(t, advanced)
+	assert.Empty(t, Events(events, "state"))
+
+	state, err = GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusSelected, state.Status)
+	assert.True(t, state.Puzzle.IsSamePuzzle(other))
+}
+
+func TestAdvancePuzzle_NewPuzzleSelected(t *testing.T) {
+	_, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+	ForcePreviousPuzzles(t)
+
+	advance := time.Now()
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusComplete
+	state.Source = "new_york_times"
+	state.AdvanceTime = &advance
+	require.NoError(t, SetState(conn, Channel.name, state))
+	completed := state.Puzzle
+
+	// The channel selects and completes a different puzzle with its own pending
+	// advance at the same time before the original advance happens.
+	other := LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json")
+	other.PublishedDate = other.PublishedDate.AddDate(0, 0, -7)
+	state.resetEphemeralState(other)
+	state.Status = model.StatusComplete
+	state.AdvanceTime = &advance
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	advanced, err := AdvancePuzzle(conn, registry, Channel.name, advance, completed)
+	require.NoError(t, err)
+	assert.False(t, advanced)
+	assert.Empty(t, Events(events, "state"))
+
+	state, err = GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusComplete, state.Status)
+	assert.True(t, state.Puzzle.IsSamePuzzle(other))
+}
+
+func TestAdvancePuzzle_AlreadyAdvanced(t *testing.T) {
+	_, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	ForcePreviousPuzzles(t)
+
+	advance := time.Now()
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusComplete
+	state.Source = "new_york_times"
+	state.AdvanceTime = &advance
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// The timer and the background advancer may both try to advance, but only
+	// the first one does.  The solution is removed from the puzzle the same way
+	// it is when the timer is scheduled.
+	completed := state.Puzzle.WithoutSolution()
+
+	advanced, err := AdvancePuzzle(conn, registry, Channel.name, advance, completed)
+	require.NoError(t, err)
+	assert.True(t, advanced)
+
+	advanced, err = AdvancePuzzle(conn, registry, Channel.name, advance, completed)
+	require.NoError(t, err)
+	assert.False(t, advanced)
+
+	state, err = GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, "2018-12-30", state.Puzzle.PublishedDate.Format("2006-01-02"))
+}
@@ -0,0 +1,87 @@
+package crossword
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/web"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// ArchiveIDRegexp matches a well formed identifier of a puzzle within the
+// community archive.  Identifiers are short strings of letters, digits, dashes
+// and underscores.
+var ArchiveIDRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// ErrInvalidArchiveID is returned when an archive id isn't properly formed.
+var ErrInvalidArchiveID = errors.New("invalid archive id")
+
+// ErrUnknownArchiveID is returned when an archive id is properly formed but
+// doesn't correspond to a puzzle in the community archive.
+var ErrUnknownArchiveID = errors.New("unknown archive id")
+
+// ArchiveURLTemplate is the template used to build the download URL of a puzzle
+// in the community archive from its id.
+var ArchiveURLTemplate = "https://crosswordnexus.com/downloads/%s.puz"
+
+// ArchiveResolver resolves a community archive id into the URL of a
+// downloadable puzzle file.  If the id is unknown then ErrUnknownArchiveID
+// should be returned.  This is a variable so that tests can substitute their
+// own resolver.
+var ArchiveResolver = func(id string) (string, error) {
+	return fmt.Sprintf(ArchiveURLTemplate, id), nil
+}
+
+// LoadFromArchive loads a crossword puzzle from the community archive using its
+// short id.
+//
+// The id is first resolved into the URL of a downloadable .puz file which is
+// then retrieved and parsed.  If the id isn't properly formed then
+// ErrInvalidArchiveID is returned, and if the archive doesn't know about the id
+// then ErrUnknownArchiveID is returned.  If the puzzle cannot be loaded or
+// parsed for any other reason then an error is returned.
+func LoadFromArchive(id string) (*Puzzle, error) {
+	if !ArchiveIDRegexp.MatchString(id) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArchiveID, id)
+	}
+
+	if testPuzzle != nil {
+		return testPuzzle, nil
+	}
+
+	if testPuzzleLoadError != nil {
+		return nil, testPuzzleLoadError
+	}
+
+	url, err := ArchiveResolver(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the .puz format is currently supported, other formats such as .jpz
+	// can't be parsed yet.
+	if !strings.HasSuffix(strings.ToLower(url), ".puz") {
+		return nil, fmt.Errorf("unsupported puzzle format for archive id %s: %s", id, url)
+	}
+
+	response, err := web.Get(url)
+	if response != nil {
+		defer func() { _ = response.Body.Close() }()
+	}
+	if response != nil && response.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownArchiveID, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	puzzle, err := LoadPuzFile(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse archive puzzle %s: %v", id, err)
+	}
+
+	puzzle.Description = fmt.Sprintf("Community archive puzzle %s", id)
+
+	return puzzle, nil
+}
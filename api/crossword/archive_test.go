@@ -0,0 +1,86 @@
+package crossword
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+)
+
+func TestLoadFromArchive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		if r.URL.Path != "/wp-20051206.puz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		reader := load(t, path.Join("puz", "puzpy-washpost-20051206.puz"))
+		_, err := io.Copy(w, reader)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	ForceArchiveResolver(t, func(id string) (string, error) {
+		if id == "unresolvable" {
+			return "", ErrUnknownArchiveID
+		}
+
+		return server.URL + "/" + id + ".puz", nil
+	})
+
+	puzzle, err := LoadFromArchive("wp-20051206")
+	require.NoError(t, err)
+
+	expected := loadJson(t, "puzpy-washpost-20051206.json")
+	assert.Equal(t, "Community archive puzzle wp-20051206", puzzle.Description)
+	assert.Equal(t, expected.Title, puzzle.Title)
+	assert.Equal(t, expected.Cells, puzzle.Cells)
+	assert.Equal(t, expected.CluesAcross, puzzle.CluesAcross)
+	assert.Equal(t, expected.CluesDown, puzzle.CluesDown)
+
+	// An id that the archive doesn't have should be reported as unknown.
+	_, err = LoadFromArchive("missing")
+	assert.True(t, errors.Is(err, ErrUnknownArchiveID))
+
+	// As should an id that the resolver doesn't know about.
+	_, err = LoadFromArchive("unresolvable")
+	assert.True(t, errors.Is(err, ErrUnknownArchiveID))
+}
+
+func TestLoadFromArchive_InvalidID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{
+			name: "empty",
+			id:   "",
+		},
+		{
+			name: "path traversal",
+			id:   "../etc/passwd",
+		},
+		{
+			name: "whitespace",
+			id:   "abc def",
+		},
+		{
+			name: "too long",
+			id:   RandomString(65),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := LoadFromArchive(test.id)
+			assert.True(t, errors.Is(err, ErrInvalidArchiveID))
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package crossword
+
+import (
+	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"github.com/bbeck/puzzles-with-chat/api/web"
+	"io"
+	"strings"
+	"time"
+)
+
+// LoadFromAtlantic loads The Atlantic's crossword puzzle for a particular date.
+//
+// The Atlantic publishes its free daily crossword through the PuzzleMe
+// platform which can provide the puzzle as JSON.
+//
+// If the puzzle cannot be loaded or parsed then an error is returned.
+func LoadFromAtlantic(date string) (*Puzzle, error) {
+	if testPuzzle != nil {
+		return testPuzzle, nil
+	}
+
+	if testPuzzleLoadError != nil {
+		return nil, testPuzzleLoadError
+	}
+
+	published, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		err = fmt.Errorf("unable to parse date %s: %+v", date, err)
+		return nil, err
+	}
+
+	response, err := web.Get(AtlanticURL(published))
+	if response != nil {
+		defer func() { _ = response.Body.Close() }()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	puzzle, err := ParseAtlanticResponse(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse atlantic response for date %s: %v", date, err)
+	}
+
+	return puzzle, nil
+}
+
+// AtlanticURL returns the URL that The Atlantic's crossword published on a
+// particular date is downloaded from.
+func AtlanticURL(published time.Time) string {
+	return fmt.Sprintf("https://cdn3.amuselabs.com/atlantic/crossword?id=atlantic_%s&set=atlantic&format=json", published.Format("20060102"))
+}
+
+// AtlanticPuzzle is a representation of the JSON response from PuzzleMe when
+// querying for one of The Atlantic's crossword puzzles.  Grids in the response
+// are stored by column, so the cell at (x, y) is found at Box[x][y].
+type AtlanticPuzzle struct {
+	Title       string     `json:"title"`
+	Author      string     `json:"author"`
+	PublishTime int64      `json:"publishTime"`
+	Width       int        `json:"w"`
+	Height      int        `json:"h"`
+	Box         [][]string `json:"box"`
+	ClueNums    [][]int    `json:"clueNums"`
+	CellInfos   []struct {
+		X         int    `json:"x"`
+		Y         int    `json:"y"`
+		IsCircled bool   `json:"isCircled"`
+		BgColor   string `json:"bgColor"`
+	} `json:"cellInfos"`
+	PlacedWords []struct {
+		Clue struct {
+			Clue string `json:"clue"`
+		} `json:"clue"`
+		ClueNum       int  `json:"clueNum"`
+		AcrossNotDown bool `json:"acrossNotDown"`
+	} `json:"placedWords"`
+}
+
+// ParseAtlanticResponse converts a JSON response from PuzzleMe for one of The
+// Atlantic's puzzles into a puzzle object.
+func ParseAtlanticResponse(in io.Reader) (*Puzzle, error) {
+	var raw AtlanticPuzzle
+	if err := model.DecodeJSON(in, &raw); err != nil {
+		return nil, fmt.Errorf("unable to parse JSON response: %v", err)
+	}
+
+	rows := raw.Height
+	cols := raw.Width
+	if rows == 0 || cols == 0 {
+		return nil, fmt.Errorf("empty JSON response")
+	}
+
+	if len(raw.Box) != cols || len(raw.ClueNums) != cols {
+		return nil, fmt.Errorf("incorrect number of columns for a %dx%d grid", rows, cols)
+	}
+	for x := 0; x < cols; x++ {
+		if len(raw.Box[x]) != rows || len(raw.ClueNums[x]) != rows {
+			return nil, fmt.Errorf("incorrect number of cells in column %d for a %dx%d grid", x, rows, cols)
+		}
+	}
+
+	if raw.PublishTime == 0 {
+		return nil, fmt.Errorf("missing publish time in JSON response")
+	}
+	published := time.Unix(raw.PublishTime/1000, 0).UTC().Truncate(24 * time.Hour)
+
+	var cells [][]string
+	var blocks [][]bool
+	var numbers [][]int
+	var circles [][]bool
+	var shades [][]bool
+	for y := 0; y < rows; y++ {
+		cells = append(cells, make([]string, cols))
+		blocks = append(blocks, make([]bool, cols))
+		numbers = append(numbers, make([]int, cols))
+		circles = append(circles, make([]bool, cols))
+		shades = append(shades, make([]bool, cols))
+
+		for x := 0; x < cols; x++ {
+			// Blocks are represented by a cell containing only a NUL character.
+			value := strings.Trim(raw.Box[x][y], "\x00 ")
+			if value == "" {
+				blocks[y][x] = true
+				continue
+			}
+
+			cells[y][x] = strings.ToUpper(value)
+			numbers[y][x] = raw.ClueNums[x][y]
+		}
+	}
+
+	// Circled and shaded cells are called out separately from the grid.
+	for _, info := range raw.CellInfos {
+		if info.X < 0 || info.X >= cols || info.Y < 0 || info.Y >= rows {
+			return nil, fmt.Errorf("cell info for (%d, %d) is outside of the grid", info.X, info.Y)
+		}
+
+		circles[info.Y][info.X] = info.IsCircled
+		shades[info.Y][info.X] = info.BgColor != ""
+	}
+
+	across := make(map[int]string)
+	down := make(map[int]string)
+	for _, word := range raw.PlacedWords {
+		clues, direction := down, "down"
+		if word.AcrossNotDown {
+			clues, direction = across, "across"
+		}
+
+		if err := AddClue(clues, direction, word.ClueNum, model.UnescapeClue(word.Clue.Clue)); err != nil {
+			return nil, err
+		}
+	}
+
+	var puzzle Puzzle
+	puzzle.Description = fmt.Sprintf("Atlantic puzzle from %s", published.Format("2006-01-02"))
+	puzzle.Rows = rows
+	puzzle.Cols = cols
+	puzzle.Variant = ClassifyVariant(puzzle.Rows, puzzle.Cols)
+	puzzle.Title = raw.Title
+	puzzle.Publisher = "The Atlantic"
+	puzzle.PublishedDate = published
+	puzzle.Author = raw.Author
+	puzzle.Cells = cells
+	puzzle.CellBlocks = blocks
+	puzzle.CellClueNumbers = numbers
+	puzzle.CellCircles = circles
+	puzzle.CellShades = shades
+	puzzle.CluesAcross = across
+	puzzle.CluesDown = down
+
+	return &puzzle, nil
+}
+
+// AtlanticFirstPuzzleDate is the date of the first crossword that The Atlantic
+// published after it relaunched its crossword.
+var AtlanticFirstPuzzleDate = time.Date(2018, time.April, 16, 0, 0, 0, 0, time.UTC)
+
+// LoadAvailableAtlanticDates calculates the set of available dates for
+// crossword puzzles from The Atlantic.
+func LoadAvailableAtlanticDates() []time.Time {
+	now := time.Now().UTC()
+
+	var dates []time.Time
+	for date := AtlanticFirstPuzzleDate; date.Before(now) || date.Equal(now); date = date.AddDate(0, 0, 1) {
+		dates = append(dates, date)
+	}
+
+	return dates
+}
@@ -0,0 +1,310 @@
+package crossword
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAtlanticResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  io.ReadCloser
+		verify func(t *testing.T, puzzle *Puzzle)
+	}{
+		{
+			name:  "description",
+			input: load(t, "atlantic-20240311.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				expected := "Atlantic puzzle from 2024-03-11"
+				assert.Equal(t, expected, puzzle.Description)
+			},
+		},
+		{
+			name:  "size",
+			input: load(t, "atlantic-20240311.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				assert.Equal(t, 5, puzzle.Cols)
+				assert.Equal(t, 5, puzzle.Rows)
+				assert.Equal(t, VariantMini, puzzle.Variant)
+			},
+		},
+		{
+			name:  "title",
+			input: load(t, "atlantic-20240311.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				assert.Equal(t, "Pocket Change", puzzle.Title)
+			},
+		},
+		{
+			name:  "publisher",
+			input: load(t, "atlantic-20240311.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				assert.Equal(t, "The Atlantic", puzzle.Publisher)
+			},
+		},
+		{
+			name:  "published date",
+			input: load(t, "atlantic-20240311.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				expected := time.Date(2024, time.March, 11, 0, 0, 0, 0, time.UTC)
+				assert.Equal(t, expected, puzzle.PublishedDate)
+			},
+		},
+		{
+			name:  "author",
+			input: load(t, "atlantic-20240311.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				assert.Equal(t, "Alex Rowe", puzzle.Author)
+			},
+		},
+		{
+			name:  "cells",
+			input: load(t, "atlantic-20240311.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				expected := [][]string{
+					{"", "C", "A", "M", "P"},
+					{"C", "O", "L", "O", "R"},
+					{"A", "L", "O", "N", "E"},
+					{"M", "O", "N", "E", "Y"},
+					{"P", "R", "E", "Y", ""},
+				}
+				assert.Equal(t, expected, puzzle.Cells)
+			},
+		},
+		{
+			name:  "cell blocks",
+			input: load(t, "atlantic-20240311.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				expected := [][]bool{
+					{true, false, false, false, false},
+					{false, false, false, false, false},
+					{false, false, false, false, false},
+					{false, false, false, false, false},
+					{false, false, false, false, true},
+				}
+				assert.Equal(t, expected, puzzle.CellBlocks)
+			},
+		},
+		{
+			name:  "cell clue numbers",
+			input: load(t, "atlantic-20240311.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				expected := [][]int{
+					{0, 1, 2, 3, 4},
+					{5, 0, 0, 0, 0},
+					{6, 0, 0, 0, 0},
+					{7, 0, 0, 0, 0},
+					{8, 0, 0, 0, 0},
+				}
+				assert.Equal(t, expected, puzzle.CellClueNumbers)
+			},
+		},
+		{
+			name:  "cell circles",
+			input: load(t, "atlantic-20240311.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				expected := [][]bool{
+					{false, false, false, false, false},
+					{false, false, false, false, false},
+					{false, false, true, false, false},
+					{false, false, false, false, false},
+					{false, false, false, false, false},
+				}
+				assert.Equal(t, expected, puzzle.CellCircles)
+			},
+		},
+		{
+			name:  "cell shades",
+			input: load(t, "atlantic-20240311.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				expected := [][]bool{
+					{false, true, false, false, false},
+					{false, false, false, false, false},
+					{false, false, false, false, false},
+					{false, false, false, false, false},
+					{false, false, false, false, false},
+				}
+				assert.Equal(t, expected, puzzle.CellShades)
+			},
+		},
+		{
+			name:  "across clues",
+			input: load(t, "atlantic-20240311.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				expected := map[int]string{
+					1: "Summer getaway with cabins",
+					5: "Hue",
+					6: "Solo",
+					7: "What a wallet holds",
+					8: "Hawk's quarry",
+				}
+				assert.Equal(t, expected, puzzle.CluesAcross)
+			},
+		},
+		{
+			name:  "down clues",
+			input: load(t, "atlantic-20240311.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				expected := map[int]string{
+					1: "Crayon choice",
+					2: "Without company",
+					3: "Cash",
+					4: "Target of a hunt",
+					5: "Pitch a tent, say",
+				}
+				assert.Equal(t, expected, puzzle.CluesDown)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer test.input.Close()
+
+			puzzle, err := ParseAtlanticResponse(test.input)
+			require.NoError(t, err)
+			test.verify(t, puzzle)
+		})
+	}
+}
+
+func TestParseAtlanticResponse_ColumnMajorGrid(t *testing.T) {
+	// A grid that isn't square makes sure the columns of the response are read
+	// as columns and not rows.
+	input := `{
+							"publishTime": 1710158400000,
+							"w": 3,
+							"h": 2,
+							"box": [["A", "D"], ["B", "\u0000"], ["C", "F"]],
+							"clueNums": [[1, 3], [2, 0], [0, 0]],
+							"cellInfos": [{"x": 2, "y": 1, "isCircled": true}]
+						}`
+
+	puzzle, err := ParseAtlanticResponse(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"A", "B", "C"}, {"D", "", "F"}}, puzzle.Cells)
+	assert.Equal(t, [][]bool{{false, false, false}, {false, true, false}}, puzzle.CellBlocks)
+	assert.Equal(t, [][]int{{1, 2, 0}, {3, 0, 0}}, puzzle.CellClueNumbers)
+	assert.Equal(t, [][]bool{{false, false, false}, {false, false, true}}, puzzle.CellCircles)
+}
+
+func TestParseAtlanticResponse_Error(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "malformed response",
+			input: `{true}`,
+		},
+		{
+			name:  "empty response",
+			input: ``,
+		},
+		{
+			name:  "empty puzzle",
+			input: `{}`,
+		},
+		{
+			name: "incorrect number of columns",
+			input: `{
+								"publishTime": 1710158400000,
+								"w": 2,
+								"h": 1,
+								"box": [["A"]],
+								"clueNums": [[1]]
+							}`,
+		},
+		{
+			name: "incorrect number of cells in column",
+			input: `{
+								"publishTime": 1710158400000,
+								"w": 1,
+								"h": 2,
+								"box": [["A"]],
+								"clueNums": [[1]]
+							}`,
+		},
+		{
+			name: "missing publish time",
+			input: `{
+								"w": 1,
+								"h": 1,
+								"box": [["A"]],
+								"clueNums": [[1]]
+							}`,
+		},
+		{
+			name: "cell info outside of grid",
+			input: `{
+								"publishTime": 1710158400000,
+								"w": 1,
+								"h": 1,
+								"box": [["A"]],
+								"clueNums": [[1]],
+								"cellInfos": [{"x": 1, "y": 0, "isCircled": true}]
+							}`,
+		},
+		{
+			name: "duplicate clue number",
+			input: `{
+								"publishTime": 1710158400000,
+								"w": 1,
+								"h": 1,
+								"box": [["A"]],
+								"clueNums": [[1]],
+								"placedWords": [
+									{"clue": {"clue": "First"}, "clueNum": 1, "acrossNotDown": true},
+									{"clue": {"clue": "Second"}, "clueNum": 1, "acrossNotDown": true}
+								]
+							}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseAtlanticResponse(strings.NewReader(test.input))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestLoadAvailableAtlanticDates(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected time.Time
+	}{
+		{
+			name:     "first puzzle date",
+			expected: AtlanticFirstPuzzleDate,
+		},
+		{
+			name:     "2020-01-01",
+			expected: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "today",
+			expected: time.Now().UTC().Truncate(24 * time.Hour),
+		},
+	}
+
+	dates := LoadAvailableAtlanticDates()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.True(t, sort.SliceIsSorted(dates, func(i, j int) bool {
+				return dates[i].Before(dates[j])
+			}))
+
+			index := sort.Search(len(dates), func(i int) bool {
+				return dates[i].Equal(test.expected) || dates[i].After(test.expected)
+			})
+			assert.Equal(t, test.expected, dates[index])
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package crossword
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/db"
+	"github.com/gomodule/redigo/redis"
+	"time"
+)
+
+// AuditEntry is a record of a single answer that was submitted for a clue of a
+// channel's crossword.  It can be marshalled to/from JSON.
+type AuditEntry struct {
+	// The name of the user that submitted the answer, if known.
+	User string `json:"user,omitempty"`
+
+	// The Twitch id of the user that submitted the answer, if known.
+	UserID string `json:"user_id,omitempty"`
+
+	// The number of bits that were cheered along with the answer.
+	Bits int `json:"bits,omitempty"`
+
+	// The clue (e.g. "1a") the answer was submitted for.
+	Clue string `json:"clue"`
+
+	// The answer exactly as it was submitted.
+	Answer string `json:"answer"`
+
+	// Whether or not the clue was correctly answered by the submission.
+	Correct bool `json:"correct"`
+
+	// When the answer was submitted.
+	Time time.Time `json:"time"`
+}
+
+// AuditLogMaxEntries is the maximum number of entries that are retained in a
+// channel's audit log.  Once the log is full the oldest entries are discarded.
+var AuditLogMaxEntries = 1000
+
+// AuditLogKey returns the key that should be used in redis to store a
+// particular channel's answer audit log.
+func AuditLogKey(name string) string {
+	return fmt.Sprintf("%s:crossword:audit", name)
+}
+
+// RecordAuditEntry adds an entry to the front of a channel's audit log.  The
+// log is trimmed to AuditLogMaxEntries entries that are no older than
+// AuditLogMaxAge and expires along with the channel's state when the channel
+// stops solving.
+func RecordAuditEntry(conn db.Connection, channel string, entry AuditEntry) error {
+	bs, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	key := AuditLogKey(channel)
+	if _, err := conn.Do("LPUSH", key, bs); err != nil {
+		return err
+	}
+
+	if err := TrimList(conn, key, AuditLogMaxEntries, AuditLogMaxAge, time.Now()); err != nil {
+		return err
+	}
+
+	_, err = conn.Do("PEXPIRE", key, StateTTL.Milliseconds())
+	return err
+}
+
+// GetAuditEntries returns up to limit of the most recent entries from a
+// channel's audit log ordered from newest to oldest.
+func GetAuditEntries(conn db.Connection, channel string, limit int) ([]AuditEntry, error) {
+	values, err := redis.ByteSlices(conn.Do("LRANGE", AuditLogKey(channel), 0, limit-1))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]AuditEntry, 0, len(values))
+	for _, value := range values {
+		var entry AuditEntry
+		if err := json.Unmarshal(value, &entry); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,77 @@
+package crossword
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAuditEntry(t *testing.T) {
+	_, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	now := time.Date(2024, time.March, 11, 12, 0, 0, 0, time.UTC)
+	first := AuditEntry{User: "alice", Clue: "1a", Answer: "QANDA", Correct: true, Time: now}
+	second := AuditEntry{User: "bob", Clue: "6a", Answer: "WRONG", Time: now.Add(time.Minute)}
+	require.NoError(t, RecordAuditEntry(conn, Channel.name, first))
+	require.NoError(t, RecordAuditEntry(conn, Channel.name, second))
+
+	// Entries are returned newest first.
+	entries, err := GetAuditEntries(conn, Channel.name, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []AuditEntry{second, first}, entries)
+
+	// The limit controls how many entries are returned.
+	entries, err = GetAuditEntries(conn, Channel.name, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []AuditEntry{second}, entries)
+
+	// A channel that has never recorded an entry has an empty log.
+	entries, err = GetAuditEntries(conn, "other", 10)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRecordAuditEntry_MaxEntries(t *testing.T) {
+	_, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	AuditLogMaxEntries = 3
+	defer func() { AuditLogMaxEntries = 1000 }()
+
+	for _, clue := range []string{"1a", "6a", "10a", "14a"} {
+		require.NoError(t, RecordAuditEntry(conn, Channel.name, AuditEntry{Clue: clue}))
+	}
+
+	entries, err := GetAuditEntries(conn, Channel.name, 10)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(entries))
+	assert.Equal(t, "14a", entries[0].Clue)
+	assert.Equal(t, "6a", entries[2].Clue)
+}
+
+func TestRecordAuditEntry_MaxAge(t *testing.T) {
+	_, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	AuditLogMaxAge = time.Hour
+	defer func() { AuditLogMaxAge = 0 }()
+
+	now := time.Now()
+	for _, entry := range []AuditEntry{
+		{Clue: "1a", Time: now.Add(-3 * time.Hour)},
+		{Clue: "6a", Time: now.Add(-2 * time.Hour)},
+		{Clue: "10a", Time: now.Add(-time.Minute)},
+		{Clue: "14a", Time: now},
+	} {
+		require.NoError(t, RecordAuditEntry(conn, Channel.name, entry))
+	}
+
+	entries, err := GetAuditEntries(conn, Channel.name, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(entries))
+	assert.Equal(t, "14a", entries[0].Clue)
+	assert.Equal(t, "10a", entries[1].Clue)
+}
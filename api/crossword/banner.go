@@ -0,0 +1,128 @@
+package crossword
+
+import (
+	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/db"
+	"github.com/bbeck/puzzles-with-chat/api/pubsub"
+	"sync"
+	"time"
+)
+
+// BannerMaxLength is the maximum number of characters that a banner message
+// may contain.
+var BannerMaxLength = 100
+
+// BannerMaxTTL is the longest amount of time that a banner may be configured to
+// be shown for before it automatically expires.
+var BannerMaxTTL = time.Hour
+
+// Banner is a short message that a streamer has pinned to their channel's solve
+// overlay.  It is independent of the state of the solve.
+type Banner struct {
+	// The message to display.
+	Message string `json:"message"`
+
+	// When the banner will automatically be cleared, nil if it is shown until it
+	// is manually cleared.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// BannerKey returns the key that should be used in redis to store a particular
+// channel's banner.
+func BannerKey(name string) string {
+	return fmt.Sprintf("%s:crossword:banner", name)
+}
+
+// GetBanner loads the banner for a channel from redis.  If the channel doesn't
+// have a banner, or its banner has expired, then nil is returned.
+func GetBanner(conn db.Connection, channel string) (*Banner, error) {
+	var banner *Banner
+	if err := db.Get(conn, BannerKey(channel), &banner); err != nil {
+		return nil, err
+	}
+
+	if banner != nil && banner.ExpiresAt != nil && !banner.ExpiresAt.After(time.Now()) {
+		return nil, nil
+	}
+
+	return banner, nil
+}
+
+// SetBanner writes the banner for a channel to redis.  Banners are transient
+// and expire from redis along with the rest of an idle channel's data.
+func SetBanner(conn db.Connection, channel string, banner Banner) error {
+	return db.SetWithTTL(conn, BannerKey(channel), banner, StateTTL)
+}
+
+// DeleteBanner removes the banner for a channel from redis.
+func DeleteBanner(conn db.Connection, channel string) error {
+	_, err := conn.Do("DEL", BannerKey(channel))
+	return err
+}
+
+// The timers that will clear each channel's banner when it expires, indexed by
+// channel name.  Banners are written to redis without holding the lock, so each
+// change to a channel's banner is also given a version.  A change is only
+// published if no other change to the channel's banner has started since.
+var bannerTimers = struct {
+	sync.Mutex
+	timers   map[string]*time.Timer
+	versions map[string]int
+	next     int
+}{
+	timers:   make(map[string]*time.Timer),
+	versions: make(map[string]int),
+}
+
+// claimBanner starts a change to a channel's banner.  Any pending expiration of
+// the channel's current banner is cancelled and the version of the change is
+// returned.
+func claimBanner(channel string) int {
+	bannerTimers.Lock()
+	defer bannerTimers.Unlock()
+
+	if timer := bannerTimers.timers[channel]; timer != nil {
+		timer.Stop()
+		delete(bannerTimers.timers, channel)
+	}
+
+	bannerTimers.next++
+	bannerTimers.versions[channel] = bannerTimers.next
+	return bannerTimers.next
+}
+
+// finishBanner completes a change to a channel's banner.  If no other change
+// has started since the provided version then the banner is published, and
+// when it has a ttl it's scheduled to be cleared once the ttl elapses.
+// Expired banners are never returned from redis, so clearing one is only a
+// matter of telling the clients.
+func finishBanner(registry *pubsub.Registry, channel string, version int, banner *Banner, ttl time.Duration) {
+	bannerTimers.Lock()
+	defer bannerTimers.Unlock()
+
+	if bannerTimers.versions[channel] != version {
+		return
+	}
+
+	registry.Publish(ChannelID(channel), BannerEvent(banner))
+
+	if ttl <= 0 {
+		delete(bannerTimers.versions, channel)
+		return
+	}
+
+	bannerTimers.timers[channel] = time.AfterFunc(ttl, func() {
+		bannerTimers.Lock()
+		defer bannerTimers.Unlock()
+
+		// The timer may have fired just as another banner was being set, in
+		// which case this banner has already been replaced.
+		if bannerTimers.versions[channel] != version {
+			return
+		}
+		delete(bannerTimers.timers, channel)
+		delete(bannerTimers.versions, channel)
+
+		registry.Publish(ChannelID(channel), BannerEvent(nil))
+	})
+}
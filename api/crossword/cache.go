@@ -0,0 +1,193 @@
+package crossword
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// PuzzleLoaders contains the function to use to load a puzzle for a date from
+// each of the sources that publish a puzzle on a schedule.  The keys of the map
+// match the source names used by the dates endpoint.
+var PuzzleLoaders = map[string]func(date string) (*Puzzle, error){
+	"atlantic":               LoadFromAtlantic,
+	"jonesin":                LoadFromJonesin,
+	"new_york_times":         LoadFromNewYorkTimes,
+	"new_york_times_mini":    LoadFromNYTMini,
+	"new_york_times_cryptic": LoadFromNYTCryptic,
+	"wall_street_journal":    LoadFromWallStreetJournal,
+	"washington_post":        LoadFromWashingtonPost,
+}
+
+// AvailableDateLoaders contains the function to use to determine which dates
+// each of the sources in PuzzleLoaders published a puzzle on.
+var AvailableDateLoaders = map[string]func() []time.Time{
+	"atlantic":               LoadAvailableAtlanticDates,
+	"jonesin":                LoadAvailableJonesinDates,
+	"new_york_times":         LoadAvailableNYTDates,
+	"new_york_times_mini":    LoadAvailableNYTMiniDates,
+	"new_york_times_cryptic": LoadAvailableNYTCrypticDates,
+	"wall_street_journal":    LoadAvailableWSJDates,
+	"washington_post":        LoadAvailableWPDates,
+}
+
+// ErrNoPuzzleOnDate is returned when a source didn't publish a puzzle on the
+// requested date.
+var ErrNoPuzzleOnDate = errors.New("no puzzle published on date")
+
+// CheckPuzzleDate verifies that a source published a puzzle on a date before
+// any attempt is made to download it.  If the date is well formed, but isn't
+// one of the source's available dates then ErrNoPuzzleOnDate is returned.
+// Malformed dates are left for the source's loader to report.
+func CheckPuzzleDate(source, date string) error {
+	available, ok := AvailableDateLoaders[source]
+	if !ok {
+		return fmt.Errorf("unrecognized puzzle source: %s", source)
+	}
+
+	d, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil
+	}
+
+	for _, a := range available() {
+		if a.Equal(d) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s %s", ErrNoPuzzleOnDate, source, date)
+}
+
+// PreviousAvailableDate returns the closest date before the provided one that
+// a source published a puzzle on.  If the source is unrecognized or didn't
+// publish a puzzle before the date then false is returned.
+func PreviousAvailableDate(source string, before time.Time) (time.Time, bool) {
+	available, ok := AvailableDateLoaders[source]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	var previous time.Time
+	var found bool
+	for _, date := range available() {
+		if date.Before(before) && (!found || date.After(previous)) {
+			previous = date
+			found = true
+		}
+	}
+
+	return previous, found
+}
+
+// PuzzleCacheTTL is how long a puzzle remains in the cache after it's loaded.
+var PuzzleCacheTTL = 48 * time.Hour
+
+// PuzzleCacheWarmSpacing is the amount of time to wait between fetches when
+// warming the cache so that we don't overwhelm the sites we download puzzles
+// from.
+var PuzzleCacheWarmSpacing = 5 * time.Second
+
+// PuzzleCacheWarmInterval is how frequently the cache is warmed when it's
+// warmed on a schedule.
+var PuzzleCacheWarmInterval = 6 * time.Hour
+
+// puzzleCache is an in-memory cache of puzzles that have been loaded, indexed
+// by source and date.
+var puzzleCache = struct {
+	sync.Mutex
+	entries map[string]puzzleCacheEntry
+}{}
+
+type puzzleCacheEntry struct {
+	puzzle  *Puzzle
+	expires time.Time
+}
+
+// LoadCachedPuzzle loads the puzzle published by a source on a date, using a
+// cached copy of it when one is available.  Puzzles that aren't in the cache
+// are loaded and then added to the cache.
+func LoadCachedPuzzle(source, date string) (*Puzzle, error) {
+	key := fmt.Sprintf("%s:%s", source, date)
+
+	puzzleCache.Lock()
+	entry, ok := puzzleCache.entries[key]
+	puzzleCache.Unlock()
+
+	if ok && time.Now().Before(entry.expires) {
+		return entry.puzzle, nil
+	}
+
+	loader, ok := PuzzleLoaders[source]
+	if !ok {
+		return nil, fmt.Errorf("unrecognized puzzle source: %s", source)
+	}
+
+	puzzle, err := loader(date)
+	if err != nil {
+		return nil, err
+	}
+
+	puzzleCache.Lock()
+	defer puzzleCache.Unlock()
+	if puzzleCache.entries == nil {
+		puzzleCache.entries = make(map[string]puzzleCacheEntry)
+	}
+	puzzleCache.entries[key] = puzzleCacheEntry{
+		puzzle:  puzzle,
+		expires: time.Now().Add(PuzzleCacheTTL),
+	}
+
+	return puzzle, nil
+}
+
+// ClearPuzzleCache removes every puzzle from the cache.
+func ClearPuzzleCache() {
+	puzzleCache.Lock()
+	defer puzzleCache.Unlock()
+
+	puzzleCache.entries = nil
+}
+
+// WarmPuzzleCache loads the puzzles published by each of the provided sources
+// on a date into the cache.  Fetches are spaced PuzzleCacheWarmSpacing apart
+// and puzzles that can't be loaded are logged and skipped.  Warming stops
+// early if the context is done.
+func WarmPuzzleCache(ctx context.Context, sources []string, date time.Time) {
+	for i, source := range sources {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(PuzzleCacheWarmSpacing):
+			}
+		}
+
+		formatted := date.Format("2006-01-02")
+		if _, err := LoadCachedPuzzle(source, formatted); err != nil {
+			log.Printf("unable to warm cache with %s puzzle for date %s: %+v", source, formatted, err)
+		}
+	}
+}
+
+// StartPuzzleCacheWarmer periodically warms the cache with the next day's
+// puzzles from each of the provided sources until the context is done.
+func StartPuzzleCacheWarmer(ctx context.Context, sources []string) {
+	go func() {
+		ticker := time.NewTicker(PuzzleCacheWarmInterval)
+		defer ticker.Stop()
+
+		for {
+			WarmPuzzleCache(ctx, sources, time.Now().UTC().AddDate(0, 0, 1))
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
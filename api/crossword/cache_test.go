@@ -0,0 +1,208 @@
+package crossword
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCachedPuzzle(t *testing.T) {
+	var calls int
+	ForcePuzzleLoader(t, "new_york_times", func(date string) (*Puzzle, error) {
+		calls++
+		return LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json"), nil
+	})
+
+	first, err := LoadCachedPuzzle("new_york_times", "2018-12-31")
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	second, err := LoadCachedPuzzle("new_york_times", "2018-12-31")
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Same(t, first, second)
+
+	// A different date isn't in the cache.
+	_, err = LoadCachedPuzzle("new_york_times", "2019-01-01")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestLoadCachedPuzzle_Error(t *testing.T) {
+	var calls int
+	ForcePuzzleLoader(t, "new_york_times", func(date string) (*Puzzle, error) {
+		calls++
+		return nil, errors.New("forced error")
+	})
+
+	_, err := LoadCachedPuzzle("new_york_times", "2018-12-31")
+	assert.Error(t, err)
+
+	// Errors aren't cached.
+	_, err = LoadCachedPuzzle("new_york_times", "2018-12-31")
+	assert.Error(t, err)
+	assert.Equal(t, 2, calls)
+
+	// Unknown sources can't be loaded.
+	_, err = LoadCachedPuzzle("unknown", "2018-12-31")
+	assert.Error(t, err)
+}
+
+func TestCheckPuzzleDate(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		date   string
+		err    error
+	}{
+		{
+			name:   "wsj available date",
+			source: "wall_street_journal",
+			date:   "2019-01-02",
+		},
+		{
+			name:   "wsj unavailable date",
+			source: "wall_street_journal",
+			date:   "2019-01-01",
+			err:    ErrNoPuzzleOnDate,
+		},
+		{
+			name:   "wp sunday",
+			source: "washington_post",
+			date:   "2005-12-04",
+		},
+		{
+			name:   "wp weekday",
+			source: "washington_post",
+			date:   "2005-12-06",
+		},
+		{
+			name:   "wp before first puzzle",
+			source: "washington_post",
+			date:   "2004-12-31",
+			err:    ErrNoPuzzleOnDate,
+		},
+		{
+			name:   "malformed date",
+			source: "wall_street_journal",
+			date:   "unused",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckPuzzleDate(test.source, test.date)
+			if test.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, test.err))
+			}
+		})
+	}
+
+	// Unknown sources can't be checked.
+	assert.Error(t, CheckPuzzleDate("unknown", "2018-12-31"))
+}
+
+func TestPreviousAvailableDate(t *testing.T) {
+	ForceAvailableCrypticDates(t, []time.Time{
+		time.Date(2024, time.January, 7, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, time.January, 21, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, time.January, 14, 0, 0, 0, 0, time.UTC),
+	})
+
+	tests := []struct {
+		name     string
+		source   string
+		before   time.Time
+		expected time.Time
+		ok       bool
+	}{
+		{
+			name:     "previous day",
+			source:   "new_york_times",
+			before:   time.Date(2018, time.December, 31, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2018, time.December, 30, 0, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "skips unavailable dates",
+			source:   "new_york_times_cryptic",
+			before:   time.Date(2024, time.January, 21, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2024, time.January, 14, 0, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:   "before first date",
+			source: "new_york_times_cryptic",
+			before: time.Date(2024, time.January, 7, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "unrecognized source",
+			source: "unknown",
+			before: time.Date(2024, time.January, 7, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			date, ok := PreviousAvailableDate(test.source, test.before)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, date)
+		})
+	}
+}
+
+func TestLoadCachedPuzzle_Expired(t *testing.T) {
+	var calls int
+	ForcePuzzleLoader(t, "new_york_times", func(date string) (*Puzzle, error) {
+		calls++
+		return LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json"), nil
+	})
+
+	original := PuzzleCacheTTL
+	PuzzleCacheTTL = 0
+	t.Cleanup(func() { PuzzleCacheTTL = original })
+
+	_, err := LoadCachedPuzzle("new_york_times", "2018-12-31")
+	require.NoError(t, err)
+	_, err = LoadCachedPuzzle("new_york_times", "2018-12-31")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestWarmPuzzleCache(t *testing.T) {
+	original := PuzzleCacheWarmSpacing
+	PuzzleCacheWarmSpacing = 10 * time.Millisecond
+	t.Cleanup(func() { PuzzleCacheWarmSpacing = original })
+
+	var nyt, wsj []time.Time
+	ForcePuzzleLoader(t, "new_york_times", func(date string) (*Puzzle, error) {
+		nyt = append(nyt, time.Now())
+		return LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json"), nil
+	})
+	ForcePuzzleLoader(t, "wall_street_journal", func(date string) (*Puzzle, error) {
+		wsj = append(wsj, time.Now())
+		return LoadTestPuzzle(t, "puzzle-wsj-20190102.json"), nil
+	})
+
+	date := time.Date(2019, time.January, 2, 0, 0, 0, 0, time.UTC)
+	WarmPuzzleCache(context.Background(), []string{"new_york_times", "wall_street_journal"}, date)
+	require.Equal(t, 1, len(nyt))
+	require.Equal(t, 1, len(wsj))
+
+	// The fetches should have been spaced out.
+	assert.True(t, wsj[0].Sub(nyt[0]) >= 10*time.Millisecond)
+
+	// Loading the puzzles now should hit the cache.
+	_, err := LoadCachedPuzzle("new_york_times", "2019-01-02")
+	require.NoError(t, err)
+	_, err = LoadCachedPuzzle("wall_street_journal", "2019-01-02")
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(nyt))
+	assert.Equal(t, 1, len(wsj))
+}
@@ -0,0 +1,143 @@
+package crossword
+
+import (
+	"context"
+	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/db"
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"github.com/bbeck/puzzles-with-chat/api/pubsub"
+	"github.com/gomodule/redigo/redis"
+	"log"
+	"strings"
+	"time"
+)
+
+// IdleAbandonInterval is how frequently channels are checked for puzzles that
+// have sat idle for too long when idle puzzles are being abandoned.
+var IdleAbandonInterval = time.Minute
+
+// AbandonIdlePuzzles abandons the puzzle of every channel that selected a
+// puzzle but hasn't done anything with it for at least the provided threshold.
+// Only puzzles that were never started are considered, channels that are in
+// the middle of a solve are left alone.  A channel whose puzzle can't be
+// abandoned is logged and skipped so that it doesn't hold up the others.  The
+// names of the channels whose puzzles were abandoned are returned.
+func AbandonIdlePuzzles(conn db.Connection, registry *pubsub.Registry, threshold time.Duration, now time.Time) ([]string, error) {
+	keys, err := db.ScanKeys(conn, StateKey("*"))
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := db.GetAll(conn, keys, State{})
+	if err != nil {
+		return nil, err
+	}
+
+	var abandoned []string
+	for key, value := range values {
+		channel := strings.Replace(key, StateKey(""), "", 1)
+
+		state, ok := value.(State)
+		if !ok {
+			return nil, fmt.Errorf("unable to convert value to State: %v", value)
+		}
+
+		if !isIdle(state, threshold, now) {
+			continue
+		}
+
+		// The channel may have started solving its puzzle since the states were
+		// read, so check again with its latest state before giving up on it.
+		state, err = GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			continue
+		}
+		if !isIdle(state, threshold, now) {
+			continue
+		}
+
+		if err := abandonSolve(conn, registry, channel, state, now); err != nil {
+			log.Printf("unable to abandon idle puzzle for channel %s: %+v", channel, err)
+			continue
+		}
+		abandoned = append(abandoned, channel)
+	}
+
+	return abandoned, nil
+}
+
+// isIdle determines whether a channel's state is for a puzzle that was
+// selected but never started and hasn't been touched for at least the provided
+// threshold.
+func isIdle(state State, threshold time.Duration, now time.Time) bool {
+	if state.Status != model.StatusSelected || state.Puzzle == nil {
+		return false
+	}
+
+	return state.LastSaveTime != nil && now.Sub(*state.LastSaveTime) >= threshold
+}
+
+// StartIdlePuzzleAbandoner periodically abandons the puzzles of channels that
+// have been idle for at least the provided threshold until the context is
+// done.
+func StartIdlePuzzleAbandoner(ctx context.Context, pool *redis.Pool, registry *pubsub.Registry, threshold time.Duration) {
+	go func() {
+		ticker := time.NewTicker(IdleAbandonInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			conn := pool.Get()
+			channels, err := AbandonIdlePuzzles(conn, registry, threshold, time.Now())
+			_ = conn.Close()
+
+			if err != nil {
+				log.Printf("unable to abandon idle puzzles: %+v", err)
+			}
+			if len(channels) > 0 {
+				log.Printf("abandoned idle puzzles for channels: %v", channels)
+			}
+		}
+	}()
+}
+
+// abandonSolve gives up on a channel's solve.  The solve's timer is stopped,
+// it's recorded in the channel's stats as abandoned and the channel is no
+// longer considered active.  Clients are then sent the abandoned state.
+func abandonSolve(conn db.Connection, registry *pubsub.Registry, channel string, state State, now time.Time) error {
+	record := NewSolveRecord(state, now)
+
+	state.Status = model.StatusAbandoned
+	state.LastStartTime = nil
+	state.TotalSolveDuration = record.Duration
+	record.Status = state.Status
+
+	if err := SetState(conn, channel, state); err != nil {
+		return err
+	}
+
+	if !state.Practice {
+		if err := RecordSolve(conn, channel, record); err != nil {
+			log.Printf("unable to record solve for channel %s: %+v", channel, err)
+		}
+	}
+
+	// Saving the state marked the channel as active, but it isn't anymore.
+	if err := model.RemoveActivity(conn, "crossword", channel); err != nil {
+		log.Printf("unable to remove activity for channel %s: %+v", channel, err)
+	}
+
+	// Broadcast to all of the clients that the puzzle has been abandoned,
+	// making sure to not include the answers.
+	state.Puzzle = state.Puzzle.WithoutSolution()
+
+	registry.Publish(ChannelID(channel), StateEvent(state))
+
+	return nil
+}
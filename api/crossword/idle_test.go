@@ -0,0 +1,89 @@
+package crossword
+
+import (
+	"errors"
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestAbandonIdlePuzzles(t *testing.T) {
+	_, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, "idle")
+
+	now := time.Now()
+	for _, channel := range []string{"idle", "solving"} {
+		state := NewState(t, "xwordinfo-nyt-20181231.json")
+		if channel == "solving" {
+			state.Status = model.StatusSolving
+			state.LastStartTime = &now
+		}
+		require.NoError(t, SetState(conn, channel, state))
+	}
+
+	// Nothing has been idle long enough yet.
+	threshold := 50 * time.Millisecond
+	channels, err := AbandonIdlePuzzles(conn, registry, threshold, now.Add(10*time.Millisecond))
+	require.NoError(t, err)
+	assert.Empty(t, channels)
+
+	// Only the puzzle that was never started is abandoned.
+	channels, err = AbandonIdlePuzzles(conn, registry, threshold, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"idle"}, channels)
+
+	idle, err := GetState(conn, "idle")
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusAbandoned, idle.Status)
+
+	solving, err := GetState(conn, "solving")
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusSolving, solving.Status)
+
+	// The abandoned solve is recorded in the stats.
+	records, err := GetSolveRecords(conn, "idle", 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(records))
+	assert.Equal(t, model.StatusAbandoned, records[0].Status)
+
+	// And clients are told that the puzzle was abandoned without its answers.
+	found := Events(events, "state")
+	require.Equal(t, 1, len(found))
+	state := found[0].Payload.(State)
+	assert.Equal(t, model.StatusAbandoned, state.Status)
+	assert.Nil(t, state.Puzzle.Cells)
+
+	// The channel is no longer listed as active.
+	all, err := GetAllChannels(conn)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(all))
+	assert.Equal(t, "solving", all[0].Name)
+
+	activities, err := model.GetRecentActivity(conn)
+	require.NoError(t, err)
+	for _, activity := range activities {
+		assert.NotEqual(t, "idle", activity.Name)
+	}
+}
+
+func TestAbandonIdlePuzzles_SaveError(t *testing.T) {
+	_, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	now := time.Now()
+	for _, channel := range []string{"a", "b"} {
+		state := NewState(t, "xwordinfo-nyt-20181231.json")
+		require.NoError(t, SetState(conn, channel, state))
+	}
+
+	// A channel that can't be abandoned doesn't stop the others from being
+	// considered or fail the whole pass.
+	ForceErrorDuringStateSave(t, errors.New("forced error"))
+
+	channels, err := AbandonIdlePuzzles(conn, registry, time.Millisecond, now.Add(time.Second))
+	require.NoError(t, err)
+	assert.Empty(t, channels)
+}
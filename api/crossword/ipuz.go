@@ -0,0 +1,355 @@
+package crossword
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IPuzFile represents the JSON file format of a .ipuz file that contains a
+// crossword puzzle.
+//
+// The format is an open standard that's used by many constructors.  It's quite
+// flexible and allows the same information to be represented in several
+// different ways.  For example a cell of the puzzle grid can be a clue number,
+// a string, null (for a cell that's omitted from the grid) or an object that
+// contains the cell along with a style to render it with.
+//
+// Details on the file format can be found at:
+//
+//	http://www.ipuz.org
+type IPuzFile struct {
+	Version    string   `json:"version"`
+	Kind       []string `json:"kind"`
+	Dimensions struct {
+		Width  int `json:"width"`
+		Height int `json:"height"`
+	} `json:"dimensions"`
+	Title     string                   `json:"title"`
+	Author    string                   `json:"author"`
+	Publisher string                   `json:"publisher"`
+	Copyright string                   `json:"copyright"`
+	Date      string                   `json:"date"`
+	Notes     string                   `json:"notes"`
+	Block     *string                  `json:"block"`
+	Empty     interface{}              `json:"empty"`
+	Styles    map[string]interface{}   `json:"styles"`
+	Puzzle    [][]interface{}          `json:"puzzle"`
+	Solution  [][]interface{}          `json:"solution"`
+	Clues     map[string][]interface{} `json:"clues"`
+}
+
+// IPuzCrosswordKind is the prefix of the kind that identifies a .ipuz file as
+// containing a crossword.  Kinds may have a version number appended to them.
+const IPuzCrosswordKind = "http://ipuz.org/crossword"
+
+// LoadFromEncodedIPuzFile will base64 decode the input and then attempt to
+// load the resulting bytes as a .ipuz file into a Puzzle object.
+func LoadFromEncodedIPuzFile(encoded string) (*Puzzle, error) {
+	if testPuzzle != nil {
+		return testPuzzle, nil
+	}
+
+	if testPuzzleLoadError != nil {
+		return nil, testPuzzleLoadError
+	}
+
+	bs, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		err = fmt.Errorf("unable to base64 decode .ipuz bytes: %+v", err)
+		return nil, err
+	}
+
+	return LoadFromIPuzBytes(bs)
+}
+
+// LoadFromIPuzBytes parses the contents of a .ipuz file into a Puzzle object.
+func LoadFromIPuzBytes(bs []byte) (*Puzzle, error) {
+	var f IPuzFile
+	if err := model.UnmarshalJSON(bs, &f); err != nil {
+		return nil, fmt.Errorf("unable to parse .ipuz JSON: %v", err)
+	}
+
+	var isCrossword bool
+	for _, kind := range f.Kind {
+		isCrossword = isCrossword || strings.HasPrefix(kind, IPuzCrosswordKind)
+	}
+	if !isCrossword {
+		return nil, fmt.Errorf("unsupported .ipuz kind: %v", f.Kind)
+	}
+
+	return f.Convert()
+}
+
+// Convert converts the .ipuz file into a Puzzle object.
+func (f *IPuzFile) Convert() (*Puzzle, error) {
+	rows := f.Dimensions.Height
+	cols := f.Dimensions.Width
+	if rows <= 0 || cols <= 0 {
+		return nil, fmt.Errorf("invalid dimensions %dx%d", rows, cols)
+	}
+
+	if len(f.Puzzle) != rows {
+		return nil, fmt.Errorf("incorrect number of puzzle rows (%d) for a %dx%d grid", len(f.Puzzle), rows, cols)
+	}
+
+	if len(f.Solution) != rows {
+		return nil, fmt.Errorf("incorrect number of solution rows (%d) for a %dx%d grid", len(f.Solution), rows, cols)
+	}
+
+	// Both the character used for blocks and the value used for empty cells can
+	// be overridden by the file.
+	block := "#"
+	if f.Block != nil {
+		block = *f.Block
+	}
+
+	empty := "0"
+	if f.Empty != nil {
+		empty = fmt.Sprint(f.Empty)
+	}
+
+	var puzzle Puzzle
+	puzzle.Description = "Crossword loaded from .ipuz file"
+	puzzle.Rows = rows
+	puzzle.Cols = cols
+	puzzle.Variant = ClassifyVariant(rows, cols)
+	puzzle.Title = strings.TrimSpace(f.Title)
+	puzzle.Publisher = strings.TrimSpace(f.Publisher)
+	puzzle.Copyright = strings.TrimSpace(f.Copyright)
+	puzzle.Notes = strings.TrimSpace(f.Notes)
+
+	puzzle.Author = strings.TrimSpace(f.Author)
+	if strings.HasPrefix(puzzle.Author, "by ") || strings.HasPrefix(puzzle.Author, "By ") {
+		puzzle.Author = puzzle.Author[3:]
+	}
+
+	// The date isn't required and isn't always in the format that the spec
+	// describes, so if it can't be parsed it's left unset.
+	if published, err := time.Parse("01/02/2006", f.Date); err == nil {
+		puzzle.PublishedDate = published
+	}
+
+	// Determine the block, clue number and style of each cell from the puzzle
+	// grid.
+	var numbered bool
+	for y := 0; y < rows; y++ {
+		if len(f.Puzzle[y]) != cols {
+			return nil, fmt.Errorf("incorrect number of puzzle cells (%d) in row %d", len(f.Puzzle[y]), y)
+		}
+
+		puzzle.CellBlocks = append(puzzle.CellBlocks, make([]bool, cols))
+		puzzle.CellClueNumbers = append(puzzle.CellClueNumbers, make([]int, cols))
+		puzzle.CellCircles = append(puzzle.CellCircles, make([]bool, cols))
+		puzzle.CellShades = append(puzzle.CellShades, make([]bool, cols))
+
+		for x := 0; x < cols; x++ {
+			cell, style := f.Puzzle[y][x], interface{}(nil)
+			if m, ok := cell.(map[string]interface{}); ok {
+				cell, style = m["cell"], m["style"]
+				if cell == nil {
+					cell = empty
+				}
+			}
+
+			// Omitted cells aren't a part of the grid, since they can't be inputted
+			// into they're treated the same as a block.
+			if cell == nil {
+				puzzle.CellBlocks[y][x] = true
+				continue
+			}
+
+			label := fmt.Sprint(cell)
+			if label == block {
+				puzzle.CellBlocks[y][x] = true
+				continue
+			}
+
+			// Labels don't have to be numbers, anything that isn't a number is
+			// displayed without a clue number.
+			if label != empty {
+				if number, err := strconv.Atoi(label); err == nil && number > 0 {
+					puzzle.CellClueNumbers[y][x] = number
+					numbered = true
+				}
+			}
+
+			circle, shade, err := f.ParseStyle(style)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse style of cell (%d, %d): %v", x, y, err)
+			}
+			puzzle.CellCircles[y][x] = circle
+			puzzle.CellShades[y][x] = shade
+		}
+	}
+
+	// Determine the answer for each cell from the solution grid.
+	for y := 0; y < rows; y++ {
+		if len(f.Solution[y]) != cols {
+			return nil, fmt.Errorf("incorrect number of solution cells (%d) in row %d", len(f.Solution[y]), y)
+		}
+
+		puzzle.Cells = append(puzzle.Cells, make([]string, cols))
+		for x := 0; x < cols; x++ {
+			if puzzle.CellBlocks[y][x] {
+				continue
+			}
+
+			value := f.Solution[y][x]
+			if m, ok := value.(map[string]interface{}); ok {
+				value = m["value"]
+			}
+
+			s, ok := value.(string)
+
+			// A cell of the puzzle whose solution is a block is void.  It's shown
+			// in the grid, possibly with a number, but nothing is ever written in
+			// it so answers treat it as a block.
+			if ok && s == block {
+				if puzzle.CellVoids == nil {
+					puzzle.CellVoids = make([][]bool, rows)
+					for row := 0; row < rows; row++ {
+						puzzle.CellVoids[row] = make([]bool, cols)
+					}
+				}
+
+				puzzle.CellBlocks[y][x] = true
+				puzzle.CellVoids[y][x] = true
+				continue
+			}
+
+			if !ok || s == "" {
+				return nil, fmt.Errorf("missing solution for cell (%d, %d)", x, y)
+			}
+
+			puzzle.Cells[y][x] = strings.ToUpper(s)
+		}
+	}
+
+	// Files aren't required to label the cells of the grid with clue numbers.
+	// When they don't we number the grid ourselves.
+	if !numbered {
+		puzzle.CellClueNumbers = NumberCells(puzzle.CellBlocks)
+	}
+
+	// Finally, parse the clues.
+	puzzle.CluesAcross = make(map[int]string)
+	puzzle.CluesDown = make(map[int]string)
+	for direction, clues := range f.Clues {
+		// Directions can include a label to display, e.g. "Across:Horizontal".
+		if index := strings.Index(direction, ":"); index != -1 {
+			direction = direction[:index]
+		}
+
+		var target map[int]string
+		switch direction {
+		case "Across":
+			target = puzzle.CluesAcross
+		case "Down":
+			target = puzzle.CluesDown
+		default:
+			return nil, fmt.Errorf("unsupported clue direction %s", direction)
+		}
+
+		for _, clue := range clues {
+			number, text, err := ParseIPuzClue(clue)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := AddClue(target, direction, number, text); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &puzzle, nil
+}
+
+// ParseStyle determines whether a cell's style renders a circle in it and
+// whether it shades the cell.  A style is either an object or the name of one
+// of the styles defined in the file.
+func (f *IPuzFile) ParseStyle(style interface{}) (bool, bool, error) {
+	if name, ok := style.(string); ok {
+		if style, ok = f.Styles[name]; !ok {
+			return false, false, fmt.Errorf("unknown style %s", name)
+		}
+	}
+
+	if style == nil {
+		return false, false, nil
+	}
+
+	m, ok := style.(map[string]interface{})
+	if !ok {
+		return false, false, fmt.Errorf("unrecognized style %v", style)
+	}
+
+	circle := m["shapebg"] == "circle"
+	shade := m["highlight"] == true || m["color"] != nil
+	return circle, shade, nil
+}
+
+// ParseIPuzClue parses a clue from a .ipuz file into its number and text.  A
+// clue is either a list containing the number and text or an object with
+// number and clue fields.  Numbers may themselves be numbers or strings.
+func ParseIPuzClue(clue interface{}) (int, string, error) {
+	var number, text interface{}
+	switch c := clue.(type) {
+	case []interface{}:
+		if len(c) != 2 {
+			return 0, "", fmt.Errorf("unrecognized clue %v", clue)
+		}
+		number, text = c[0], c[1]
+
+	case map[string]interface{}:
+		number, text = c["number"], c["clue"]
+
+	default:
+		return 0, "", fmt.Errorf("unrecognized clue %v", clue)
+	}
+
+	n, err := strconv.Atoi(fmt.Sprint(number))
+	if err != nil {
+		return 0, "", fmt.Errorf("unable to parse clue number %v: %v", number, err)
+	}
+
+	s, ok := text.(string)
+	if !ok {
+		return 0, "", errors.New("missing clue text")
+	}
+
+	return n, model.UnescapeClue(s), nil
+}
+
+// NumberCells determines the clue numbers for each cell of a grid from the
+// placement of its blocks.  Cells that start an across or down answer are
+// numbered sequentially in row major order, all other cells are assigned 0.
+func NumberCells(blocks [][]bool) [][]int {
+	var numbers [][]int
+	var next = 1
+	for y := 0; y < len(blocks); y++ {
+		numbers = append(numbers, make([]int, len(blocks[y])))
+
+		for x := 0; x < len(blocks[y]); x++ {
+			if blocks[y][x] {
+				continue
+			}
+
+			isLeftABlock := x == 0 || blocks[y][x-1]
+			isRightABlock := x >= len(blocks[y])-1 || blocks[y][x+1]
+			isUpABlock := y == 0 || blocks[y-1][x]
+			isDownABlock := y >= len(blocks)-1 || blocks[y+1][x]
+			if (isLeftABlock && !isRightABlock) || (isUpABlock && !isDownABlock) {
+				numbers[y][x] = next
+				next++
+			}
+		}
+	}
+
+	return numbers
+}
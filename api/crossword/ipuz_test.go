@@ -0,0 +1,205 @@
+package crossword
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestLoadFromIPuzBytes(t *testing.T) {
+	puzzle := loadIPuz(t, "blocks-and-circles.ipuz")
+
+	assert.Equal(t, "Crossword loaded from .ipuz file", puzzle.Description)
+	assert.Equal(t, 4, puzzle.Rows)
+	assert.Equal(t, 4, puzzle.Cols)
+	assert.Equal(t, VariantMini, puzzle.Variant)
+	assert.Equal(t, "Blocks and Circles", puzzle.Title)
+	assert.Equal(t, "Jane Doe", puzzle.Author)
+	assert.Equal(t, "Puzzles With Chat", puzzle.Publisher)
+	assert.Equal(t, time.Date(2020, time.April, 8, 0, 0, 0, 0, time.UTC), puzzle.PublishedDate)
+	assert.Equal(t, "The circled letters spell a verb.", puzzle.Notes)
+
+	assert.Equal(t, [][]string{
+		{"C", "A", "B", ""},
+		{"A", "R", "E", "A"},
+		{"R", "E", "A", "D"},
+		{"", "A", "R", "E"},
+	}, puzzle.Cells)
+
+	// Both blocks and omitted cells are blocks.
+	assert.Equal(t, [][]bool{
+		{false, false, false, true},
+		{false, false, false, false},
+		{false, false, false, false},
+		{true, false, false, false},
+	}, puzzle.CellBlocks)
+
+	assert.Equal(t, [][]int{
+		{1, 2, 3, 0},
+		{4, 0, 0, 5},
+		{6, 0, 0, 0},
+		{0, 7, 0, 0},
+	}, puzzle.CellClueNumbers)
+
+	// Circles can be specified inline or by referencing a named style.
+	assert.Equal(t, [][]bool{
+		{false, false, false, false},
+		{false, true, false, false},
+		{false, false, true, false},
+		{false, false, false, false},
+	}, puzzle.CellCircles)
+
+	assert.Equal(t, map[int]string{
+		1: "Taxi",
+		4: "Region",
+		6: "Peruse",
+		7: "Exist",
+	}, puzzle.CluesAcross)
+
+	assert.Equal(t, map[int]string{
+		1: "Sedan, e.g.",
+		2: "Length × width",
+		3: "Grizzly & co.",
+		5: "Bon ___",
+	}, puzzle.CluesDown)
+}
+
+func TestLoadFromIPuzBytes_UnlabeledCells(t *testing.T) {
+	ipuz := `{
+		"kind": ["http://ipuz.org/crossword#1"],
+		"dimensions": {"width": 3, "height": 3},
+		"block": ".",
+		"empty": "-",
+		"puzzle": [["-", "-", "."], ["-", "-", "-"], [".", "-", "-"]],
+		"solution": [["A", "B", "."], ["C", "D", "E"], [".", "F", "G"]],
+		"clues": {"Across": [[1, "AB"], [3, "CDE"], [5, "FG"]], "Down": [[1, "AC"], [2, "BDF"], [4, "EG"]]}
+	}`
+
+	puzzle, err := LoadFromIPuzBytes([]byte(ipuz))
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]bool{
+		{false, false, true},
+		{false, false, false},
+		{true, false, false},
+	}, puzzle.CellBlocks)
+
+	assert.Equal(t, [][]int{
+		{1, 2, 0},
+		{3, 0, 4},
+		{0, 5, 0},
+	}, puzzle.CellClueNumbers)
+}
+
+func TestLoadFromIPuzBytes_VoidCells(t *testing.T) {
+	puzzle := loadIPuz(t, "void-cells.ipuz")
+
+	assert.Equal(t, [][]string{
+		{"C", "A", "T", "S"},
+		{"A", "R", "", "O"},
+		{"B", "E", "E", "S"},
+	}, puzzle.Cells)
+
+	// Void cells keep their number but are otherwise treated as blocks.
+	assert.Equal(t, [][]bool{
+		{false, false, false, false},
+		{false, false, true, false},
+		{false, false, false, false},
+	}, puzzle.CellBlocks)
+
+	assert.Equal(t, [][]bool{
+		{false, false, false, false},
+		{false, false, true, false},
+		{false, false, false, false},
+	}, puzzle.CellVoids)
+
+	assert.Equal(t, [][]int{
+		{1, 2, 0, 3},
+		{4, 0, 5, 0},
+		{6, 0, 0, 0},
+	}, puzzle.CellClueNumbers)
+
+	assert.Equal(t, CellKindLetter, puzzle.CellKind(0, 1))
+	assert.Equal(t, CellKindVoid, puzzle.CellKind(2, 1))
+	require.NoError(t, puzzle.Validate())
+
+	// Answers end at a void cell.
+	minX, minY, maxX, maxY, err := puzzle.GetAnswerCoordinates(4, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 1, 1}, []int{minX, minY, maxX, maxY})
+
+	var state State
+	state.resetEphemeralState(puzzle)
+	assert.Error(t, state.ApplyAnswer("4a", "ARX", false))
+	require.NoError(t, state.ApplyAnswer("4a", "AR", true))
+	assert.Equal(t, []string{"A", "R", "", ""}, state.Cells[1])
+
+	// The void cell isn't sent to clients as part of the solution, but is still
+	// identified so that it can be drawn.
+	assert.Equal(t, puzzle.CellVoids, puzzle.WithoutSolution().CellVoids)
+}
+
+func TestLoadFromIPuzBytes_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		ipuz string
+	}{
+		{
+			name: "invalid json",
+			ipuz: `{`,
+		},
+		{
+			name: "not a crossword",
+			ipuz: `{"kind": ["http://ipuz.org/sudoku#1"]}`,
+		},
+		{
+			name: "invalid dimensions",
+			ipuz: `{"kind": ["http://ipuz.org/crossword#1"], "dimensions": {"width": 0, "height": 0}}`,
+		},
+		{
+			name: "incorrect number of rows",
+			ipuz: `{"kind": ["http://ipuz.org/crossword#1"], "dimensions": {"width": 1, "height": 2}, "puzzle": [[1]], "solution": [["A"]]}`,
+		},
+		{
+			name: "missing solution",
+			ipuz: `{"kind": ["http://ipuz.org/crossword#1"], "dimensions": {"width": 1, "height": 1}, "puzzle": [[1]], "solution": [[null]]}`,
+		},
+		{
+			name: "unknown style",
+			ipuz: `{"kind": ["http://ipuz.org/crossword#1"], "dimensions": {"width": 1, "height": 1}, "puzzle": [[{"cell": 1, "style": "missing"}]], "solution": [["A"]]}`,
+		},
+		{
+			name: "unsupported clue direction",
+			ipuz: `{"kind": ["http://ipuz.org/crossword#1"], "dimensions": {"width": 1, "height": 1}, "puzzle": [[1]], "solution": [["A"]], "clues": {"Diagonal": [[1, "A"]]}}`,
+		},
+		{
+			name: "invalid clue number",
+			ipuz: `{"kind": ["http://ipuz.org/crossword#1"], "dimensions": {"width": 1, "height": 1}, "puzzle": [[1]], "solution": [["A"]], "clues": {"Across": [["one", "A"]]}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := LoadFromIPuzBytes([]byte(test.ipuz))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func loadIPuz(t *testing.T, filename string) *Puzzle {
+	t.Helper()
+
+	reader := load(t, path.Join("ipuz", filename))
+	defer reader.Close()
+
+	bs, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+
+	puzzle, err := LoadFromIPuzBytes(bs)
+	require.NoError(t, err)
+
+	return puzzle
+}
@@ -0,0 +1,144 @@
+package crossword
+
+import (
+	"fmt"
+	"time"
+)
+
+// JonesinURLTemplate is the template used to build the download URL of a
+// Jonesin' crossword from the two digit year, month and day that it was
+// published on.
+var JonesinURLTemplate = "http://herbach.dnsalias.com/Jonesin/jz%02d%02d%02d.puz"
+
+// LoadFromJonesin loads Matt Jones' weekly Jonesin' crossword puzzle for a
+// particular date.
+//
+// Like the Wall Street Journal this method downloads a .puz file from the
+// herbach.dnsalias.com site and loads it into a Puzzle object.
+//
+// If the puzzle cannot be loaded or parsed then an error is returned.
+func LoadFromJonesin(date string) (*Puzzle, error) {
+	published, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		err = fmt.Errorf("unable to parse date %s: %+v", date, err)
+		return nil, err
+	}
+
+	// Download the .puz file from the herbach.dnsalias.com site.
+	puzzle, err := LoadFromPuzFileURLWithHeaders(JonesinURL(published), HerbachHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	puzzle.Description = fmt.Sprintf("Jonesin' puzzle from %s", published.Format("2006-01-02"))
+
+	// Normally .puz files don't have puzzle dates recorded in them, but we
+	// happen to know the date for this puzzle, so fill it in.
+	puzzle.PublishedDate = published
+	puzzle.Publisher = "Jonesin'"
+
+	return puzzle, nil
+}
+
+// JonesinURL returns the URL of the .puz file for the Jonesin' crossword
+// published on a particular date.
+func JonesinURL(published time.Time) string {
+	return fmt.Sprintf(JonesinURLTemplate, published.Year()%100, published.Month(), published.Day())
+}
+
+// LoadAvailableJonesinDates calculates the set of available dates for
+// Jonesin' crossword puzzles.
+func LoadAvailableJonesinDates() []time.Time {
+	now := time.Now().UTC()
+
+	var dates []time.Time
+	for _, s := range AvailableJonesinDates {
+		date, err := time.Parse("2006-01-02", s)
+		if err != nil || date.After(now) {
+			continue
+		}
+
+		dates = append(dates, date)
+	}
+
+	return dates
+}
+
+// AvailableJonesinDates contains the list of dates that there was or will be a
+// Jonesin' crossword.  The puzzle is published weekly and is dated on
+// Thursdays on herbach.dnsalias.com.  The following command was used to
+// generate these dates.
+//
+//	d=2020-01-02
+//	while [[ "${d}" < "2026-01-01" ]]; do
+//	  echo "\"${d}\","
+//	  d=$(date -d "${d} + 7 days" +%Y-%m-%d)
+//	done                                     |
+//	paste - - - - -
+//
+// The results of this command are pasted below.
+var AvailableJonesinDates = []string{
+	"2020-01-02", "2020-01-09", "2020-01-16", "2020-01-23", "2020-01-30",
+	"2020-02-06", "2020-02-13", "2020-02-20", "2020-02-27", "2020-03-05",
+	"2020-03-12", "2020-03-19", "2020-03-26", "2020-04-02", "2020-04-09",
+	"2020-04-16", "2020-04-23", "2020-04-30", "2020-05-07", "2020-05-14",
+	"2020-05-21", "2020-05-28", "2020-06-04", "2020-06-11", "2020-06-18",
+	"2020-06-25", "2020-07-02", "2020-07-09", "2020-07-16", "2020-07-23",
+	"2020-07-30", "2020-08-06", "2020-08-13", "2020-08-20", "2020-08-27",
+	"2020-09-03", "2020-09-10", "2020-09-17", "2020-09-24", "2020-10-01",
+	"2020-10-08", "2020-10-15", "2020-10-22", "2020-10-29", "2020-11-05",
+	"2020-11-12", "2020-11-19", "2020-11-26", "2020-12-03", "2020-12-10",
+	"2020-12-17", "2020-12-24", "2020-12-31", "2021-01-07", "2021-01-14",
+	"2021-01-21", "2021-01-28", "2021-02-04", "2021-02-11", "2021-02-18",
+	"2021-02-25", "2021-03-04", "2021-03-11", "2021-03-18", "2021-03-25",
+	"2021-04-01", "2021-04-08", "2021-04-15", "2021-04-22", "2021-04-29",
+	"2021-05-06", "2021-05-13", "2021-05-20", "2021-05-27", "2021-06-03",
+	"2021-06-10", "2021-06-17", "2021-06-24", "2021-07-01", "2021-07-08",
+	"2021-07-15", "2021-07-22", "2021-07-29", "2021-08-05", "2021-08-12",
+	"2021-08-19", "2021-08-26", "2021-09-02", "2021-09-09", "2021-09-16",
+	"2021-09-23", "2021-09-30", "2021-10-07", "2021-10-14", "2021-10-21",
+	"2021-10-28", "2021-11-04", "2021-11-11", "2021-11-18", "2021-11-25",
+	"2021-12-02", "2021-12-09", "2021-12-16", "2021-12-23", "2021-12-30",
+	"2022-01-06", "2022-01-13", "2022-01-20", "2022-01-27", "2022-02-03",
+	"2022-02-10", "2022-02-17", "2022-02-24", "2022-03-03", "2022-03-10",
+	"2022-03-17", "2022-03-24", "2022-03-31", "2022-04-07", "2022-04-14",
+	"2022-04-21", "2022-04-28", "2022-05-05", "2022-05-12", "2022-05-19",
+	"2022-05-26", "2022-06-02", "2022-06-09", "2022-06-16", "2022-06-23",
+	"2022-06-30", "2022-07-07", "2022-07-14", "2022-07-21", "2022-07-28",
+	"2022-08-04", "2022-08-11", "2022-08-18", "2022-08-25", "2022-09-01",
+	"2022-09-08", "2022-09-15", "2022-09-22", "2022-09-29", "2022-10-06",
+	"2022-10-13", "2022-10-20", "2022-10-27", "2022-11-03", "2022-11-10",
+	"2022-11-17", "2022-11-24", "2022-12-01", "2022-12-08", "2022-12-15",
+	"2022-12-22", "2022-12-29", "2023-01-05", "2023-01-12", "2023-01-19",
+	"2023-01-26", "2023-02-02", "2023-02-09", "2023-02-16", "2023-02-23",
+	"2023-03-02", "2023-03-09", "2023-03-16", "2023-03-23", "2023-03-30",
+	"2023-04-06", "2023-04-13", "2023-04-20", "2023-04-27", "2023-05-04",
+	"2023-05-11", "2023-05-18", "2023-05-25", "2023-06-01", "2023-06-08",
+	"2023-06-15", "2023-06-22", "2023-06-29", "2023-07-06", "2023-07-13",
+	"2023-07-20", "2023-07-27", "2023-08-03", "2023-08-10", "2023-08-17",
+	"2023-08-24", "2023-08-31", "2023-09-07", "2023-09-14", "2023-09-21",
+	"2023-09-28", "2023-10-05", "2023-10-12", "2023-10-19", "2023-10-26",
+	"2023-11-02", "2023-11-09", "2023-11-16", "2023-11-23", "2023-11-30",
+	"2023-12-07", "2023-12-14", "2023-12-21", "2023-12-28", "2024-01-04",
+	"2024-01-11", "2024-01-18", "2024-01-25", "2024-02-01", "2024-02-08",
+	"2024-02-15", "2024-02-22", "2024-02-29", "2024-03-07", "2024-03-14",
+	"2024-03-21", "2024-03-28", "2024-04-04", "2024-04-11", "2024-04-18",
+	"2024-04-25", "2024-05-02", "2024-05-09", "2024-05-16", "2024-05-23",
+	"2024-05-30", "2024-06-06", "2024-06-13", "2024-06-20", "2024-06-27",
+	"2024-07-04", "2024-07-11", "2024-07-18", "2024-07-25", "2024-08-01",
+	"2024-08-08", "2024-08-15", "2024-08-22", "2024-08-29", "2024-09-05",
+	"2024-09-12", "2024-09-19", "2024-09-26", "2024-10-03", "2024-10-10",
+	"2024-10-17", "2024-10-24", "2024-10-31", "2024-11-07", "2024-11-14",
+	"2024-11-21", "2024-11-28", "2024-12-05", "2024-12-12", "2024-12-19",
+	"2024-12-26", "2025-01-02", "2025-01-09", "2025-01-16", "2025-01-23",
+	"2025-01-30", "2025-02-06", "2025-02-13", "2025-02-20", "2025-02-27",
+	"2025-03-06", "2025-03-13", "2025-03-20", "2025-03-27", "2025-04-03",
+	"2025-04-10", "2025-04-17", "2025-04-24", "2025-05-01", "2025-05-08",
+	"2025-05-15", "2025-05-22", "2025-05-29", "2025-06-05", "2025-06-12",
+	"2025-06-19", "2025-06-26", "2025-07-03", "2025-07-10", "2025-07-17",
+	"2025-07-24", "2025-07-31", "2025-08-07", "2025-08-14", "2025-08-21",
+	"2025-08-28", "2025-09-04", "2025-09-11", "2025-09-18", "2025-09-25",
+	"2025-10-02", "2025-10-09", "2025-10-16", "2025-10-23", "2025-10-30",
+	"2025-11-06", "2025-11-13", "2025-11-20", "2025-11-27", "2025-12-04",
+	"2025-12-11", "2025-12-18", "2025-12-25",
+}
@@ -0,0 +1,103 @@
+package crossword
+
+import (
+	"github.com/bbeck/puzzles-with-chat/api/web"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestLoadFromJonesin(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		headers = r.Header
+
+		if r.URL.Path != "/jz200102.puz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		reader := load(t, path.Join("puz", "puzpy-avclub-20110622.puz"))
+		_, err := io.Copy(w, reader)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	template := JonesinURLTemplate
+	JonesinURLTemplate = server.URL + "/jz%02d%02d%02d.puz"
+	defer func() { JonesinURLTemplate = template }()
+
+	puzzle, err := LoadFromJonesin("2020-01-02")
+	require.NoError(t, err)
+
+	expected := loadJson(t, "puzpy-avclub-20110622.json")
+	assert.Equal(t, "Jonesin' puzzle from 2020-01-02", puzzle.Description)
+	assert.Equal(t, "Jonesin'", puzzle.Publisher)
+	assert.Equal(t, time.Date(2020, time.January, 2, 0, 0, 0, 0, time.UTC), puzzle.PublishedDate)
+	assert.Equal(t, expected.Title, puzzle.Title)
+	assert.Equal(t, expected.Cells, puzzle.Cells)
+	assert.Equal(t, expected.CluesAcross, puzzle.CluesAcross)
+	assert.Equal(t, expected.CluesDown, puzzle.CluesDown)
+
+	// The request identifies the application and carries the site's headers.
+	assert.Equal(t, web.UserAgent, headers.Get("User-Agent"))
+	assert.Equal(t, HerbachHeaders["Referer"], headers.Get("Referer"))
+
+	// A date that the site doesn't have a puzzle for should fail to load.
+	_, err = LoadFromJonesin("2020-01-09")
+	assert.Error(t, err)
+
+	// As should a malformed date.
+	_, err = LoadFromJonesin("not a date")
+	assert.Error(t, err)
+}
+
+func TestLoadAvailableJonesinDates(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected time.Time
+	}{
+		{
+			name:     "2020-01-02",
+			expected: time.Date(2020, time.January, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "2021-01-07",
+			expected: time.Date(2021, time.January, 7, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "2022-01-06",
+			expected: time.Date(2022, time.January, 6, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "2023-01-05",
+			expected: time.Date(2023, time.January, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "2024-01-04",
+			expected: time.Date(2024, time.January, 4, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	dates := LoadAvailableJonesinDates()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.True(t, sort.SliceIsSorted(dates, func(i, j int) bool {
+				return dates[i].Before(dates[j])
+			}))
+
+			index := sort.Search(len(dates), func(i int) bool {
+				return dates[i].Equal(test.expected) || dates[i].After(test.expected)
+			})
+			assert.Equal(t, test.expected, dates[index])
+		})
+	}
+}
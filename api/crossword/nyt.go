@@ -1,10 +1,9 @@
 package crossword
 
 import (
-	"encoding/json"
 	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/model"
 	"github.com/bbeck/puzzles-with-chat/api/web"
-	"html"
 	"io"
 	"strconv"
 	"strings"
@@ -56,6 +55,7 @@ type XWordInfoPuzzle struct {
 	Title     string `json:"title"`
 	Author    string `json:"author"`
 	Publisher string `json:"publisher"`
+	Copyright string `json:"copyright"`
 	Date      string `json:"date"`
 	Notepad   string `json:"notepad"`
 	JNotes    string `json:"jnotes"`
@@ -81,7 +81,7 @@ type XWordInfoPuzzle struct {
 // puzzle object.
 func ParseXWordInfoResponse(in io.Reader) (*Puzzle, error) {
 	var raw XWordInfoPuzzle
-	if err := json.NewDecoder(in).Decode(&raw); err != nil {
+	if err := model.DecodeJSON(in, &raw); err != nil {
 		return nil, fmt.Errorf("unable to parse JSON response: %v", err)
 	}
 
@@ -159,7 +159,9 @@ func ParseXWordInfoResponse(in io.Reader) (*Puzzle, error) {
 			return nil, fmt.Errorf("unable to parse clue text %s: %v", c, err)
 		}
 
-		across[num] = clue
+		if err := AddClue(across, "across", num, clue); err != nil {
+			return nil, err
+		}
 	}
 
 	down := make(map[int]string)
@@ -169,17 +171,21 @@ func ParseXWordInfoResponse(in io.Reader) (*Puzzle, error) {
 			return nil, fmt.Errorf("unable to parse clue text %s: %v", c, err)
 		}
 
-		down[num] = clue
+		if err := AddClue(down, "down", num, clue); err != nil {
+			return nil, err
+		}
 	}
 
 	var puzzle Puzzle
 	puzzle.Description = fmt.Sprintf("New York Times puzzle from %s", published.Format("2006-01-02"))
 	puzzle.Rows = raw.Size.Rows
 	puzzle.Cols = raw.Size.Cols
+	puzzle.Variant = ClassifyVariant(puzzle.Rows, puzzle.Cols)
 	puzzle.Title = raw.Title
 	puzzle.Publisher = raw.Publisher
 	puzzle.PublishedDate = published
 	puzzle.Author = raw.Author
+	puzzle.Copyright = raw.Copyright
 	puzzle.Cells = cells
 	puzzle.CellBlocks = blocks
 	puzzle.CellClueNumbers = numbers
@@ -220,8 +226,7 @@ func ParseXWordInfoClue(s string) (int, string, error) {
 		return 0, "", fmt.Errorf("unable to parse clue number %s: %v", parts[0], err)
 	}
 
-	clue := html.UnescapeString(parts[1])
-	clue = strings.Trim(clue, " \t\n")
+	clue := model.UnescapeClue(parts[1])
 	return num, clue, nil
 }
 
@@ -230,13 +235,24 @@ var NYTFirstPuzzleDate = time.Date(1942, time.February, 15, 0, 0, 0, 0, time.UTC
 // Prior to 1950-09-11 puzzles were only on Sundays.
 var NYTSwitchToDailyDate = time.Date(1950, time.September, 11, 0, 0, 0, 0, time.UTC)
 
+// NYTMinDate is the earliest date that a New York Times puzzle can be selected
+// from.  Deployments can set this to limit how much of the puzzle history is
+// selectable, when it's the zero time (the default) every puzzle back to
+// NYTFirstPuzzleDate is available.
+var NYTMinDate time.Time
+
 // LoadAvailableNYTDates calculates the set of available dates for crossword
-// puzzles from The New York Times.
+// puzzles from The New York Times.  Dates before NYTMinDate are excluded.
 func LoadAvailableNYTDates() []time.Time {
 	now := time.Now().UTC()
 
+	start := NYTFirstPuzzleDate
+	if NYTMinDate.After(start) {
+		start = NYTMinDate
+	}
+
 	var dates []time.Time
-	for date := NYTFirstPuzzleDate; date.Before(now) || date.Equal(now); date = date.AddDate(0, 0, 1) {
+	for date := start; date.Before(now) || date.Equal(now); date = date.AddDate(0, 0, 1) {
 		if date.Before(NYTSwitchToDailyDate) && date.Weekday() != time.Sunday {
 			continue
 		}
@@ -76,6 +76,16 @@ func TestParseXWordInfoClue(t *testing.T) {
 			input:  "12. \t\n ", // only whitespace
 			number: 12,
 		},
+		{
+			input:  "13. Q&amp;A &mdash; Caf&eacute; &#8220;menu&#x201D;",
+			number: 13,
+			clue:   "Q&A — Café “menu”",
+		},
+		{
+			input:  "14. <i>Hamlet</i> setting",
+			number: 14,
+			clue:   "<i>Hamlet</i> setting",
+		},
 	}
 
 	for _, test := range tests {
@@ -589,6 +599,77 @@ func TestParseXWordInfoResponse_Error(t *testing.T) {
 	}
 }
 
+func TestParseXWordInfoResponse_DuplicateClueNumbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		replaced string
+		expected string
+	}{
+		{
+			name:     "across",
+			original: `"6. Room just under the roof"`,
+			replaced: `"1. Room just under the roof"`,
+			expected: "duplicate across clue number 1",
+		},
+		{
+			name:     "down",
+			original: `"2. Man&#39;s name`,
+			replaced: `"1. Man&#39;s name`,
+			expected: "duplicate down clue number 1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			input := toString(t, load(t, "xwordinfo-nyt-20181231.json"))
+			require.Contains(t, input, test.original)
+			input = strings.Replace(input, test.original, test.replaced, 1)
+
+			_, err := ParseXWordInfoResponse(strings.NewReader(input))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.expected)
+		})
+	}
+}
+
+func TestParseXWordInfoResponse_MalformedField(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		replaced string
+		expected string
+	}{
+		{
+			name:     "unexpected type",
+			original: `"rows": 15`,
+			replaced: `"rows": "fifteen"`,
+			expected: "field size.rows",
+		},
+		{
+			name:     "malformed json",
+			original: `"date": "12/31/2018",`,
+			replaced: `"date": "12/31/2018"`,
+			expected: "12/31/2018",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			input := toString(t, load(t, "xwordinfo-nyt-20181231.json"))
+			require.Contains(t, input, test.original)
+			input = strings.Replace(input, test.original, test.replaced, 1)
+
+			_, err := ParseXWordInfoResponse(strings.NewReader(input))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.expected)
+
+			// Only a snippet of the input is included in the error.
+			assert.Less(t, len(err.Error()), 500)
+		})
+	}
+}
+
 func TestLoadAvailableNYTDates(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -935,6 +1016,27 @@ func TestLoadAvailableNYTDates(t *testing.T) {
 	}
 }
 
+func TestLoadAvailableNYTDates_MinDate(t *testing.T) {
+	all := LoadAvailableNYTDates()
+
+	NYTMinDate = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	defer func() { NYTMinDate = time.Time{} }()
+
+	dates := LoadAvailableNYTDates()
+	require.NotEmpty(t, dates)
+	assert.Equal(t, NYTMinDate, dates[0])
+	assert.True(t, sort.SliceIsSorted(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	}))
+
+	// The most recent dates are unaffected by the bound.
+	assert.Equal(t, all[len(all)-len(dates):], dates)
+
+	// A bound before the first puzzle doesn't add any dates.
+	NYTMinDate = time.Date(1900, time.January, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, all, LoadAvailableNYTDates())
+}
+
 func toString(t *testing.T, r io.ReadCloser) string {
 	t.Helper()
 	defer r.Close()
@@ -0,0 +1,137 @@
+package crossword
+
+import (
+	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"github.com/bbeck/puzzles-with-chat/api/web"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+var XWordInfoCrypticHeaders = map[string]string{
+	"Referer": "https://www.xwordinfo.com/Cryptic",
+}
+
+// LoadFromNYTCryptic loads a New York Times "Variety: Cryptic" crossword puzzle
+// for a particular date.
+//
+// This method uses the xwordinfo.com JSON API to load the puzzle.  Cryptics are
+// published about once a month and are returned in the same format as the
+// regular New York Times crossword, with each clue's enumeration (e.g. "(3,4)")
+// included at the end of its text.
+//
+// If the puzzle cannot be loaded or parsed then an error is returned.
+func LoadFromNYTCryptic(date string) (*Puzzle, error) {
+	if testPuzzle != nil {
+		return testPuzzle, nil
+	}
+
+	if testPuzzleLoadError != nil {
+		return nil, testPuzzleLoadError
+	}
+
+	response, err := web.GetWithHeaders(NYTCrypticURL(date), XWordInfoCrypticHeaders)
+	if response != nil {
+		defer func() { _ = response.Body.Close() }()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	puzzle, err := ParseXWordInfoCrypticResponse(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse xwordinfo.com cryptic response for date %s: %v", date, err)
+	}
+
+	return puzzle, nil
+}
+
+// NYTCrypticURL returns the URL that the New York Times cryptic published on a
+// particular date (e.g. "2020-05-24") is downloaded from.
+func NYTCrypticURL(date string) string {
+	return fmt.Sprintf("https://www.xwordinfo.com/JSON/CrypticData.ashx?date=%s", date)
+}
+
+// ParseXWordInfoCrypticResponse converts a JSON response from xwordinfo.com for
+// a cryptic into a puzzle object.
+func ParseXWordInfoCrypticResponse(in io.Reader) (*Puzzle, error) {
+	puzzle, err := ParseXWordInfoResponse(in)
+	if err != nil {
+		return nil, err
+	}
+
+	puzzle.Description = fmt.Sprintf("New York Times cryptic puzzle from %s", puzzle.PublishedDate.Format("2006-01-02"))
+	return puzzle, nil
+}
+
+// NYTCrypticDatesTTL is how long the dates that cryptics are available for are
+// cached before they're loaded from xwordinfo.com again.
+var NYTCrypticDatesTTL = 4 * time.Hour
+
+// The most recently loaded dates that cryptics are available for.
+var nytCrypticDates struct {
+	sync.Mutex
+	dates      []time.Time
+	expiration time.Time
+}
+
+// LoadAvailableNYTCrypticDates determines all of the historical dates that
+// have New York Times cryptic puzzles.
+//
+// In order to not call into the xwordinfo.com site too often the dates are
+// cached for NYTCrypticDatesTTL.  If the dates can't be loaded then the error
+// is logged and the previously loaded dates (if any) are returned.
+func LoadAvailableNYTCrypticDates() []time.Time {
+	if testAvailableCrypticDates != nil {
+		return testAvailableCrypticDates
+	}
+
+	nytCrypticDates.Lock()
+	defer nytCrypticDates.Unlock()
+
+	if nytCrypticDates.dates == nil || nytCrypticDates.expiration.Before(time.Now()) {
+		dates, err := LoadNYTCrypticDatesFromXWordInfo()
+		if err != nil {
+			log.Printf("unable to load available new york times cryptic dates: %+v", err)
+			return nytCrypticDates.dates
+		}
+
+		nytCrypticDates.dates = dates
+		nytCrypticDates.expiration = time.Now().Add(NYTCrypticDatesTTL)
+	}
+
+	return nytCrypticDates.dates
+}
+
+// LoadNYTCrypticDatesFromXWordInfo loads the dates that have New York Times
+// cryptic puzzles.
+//
+// This method uses the https://www.xwordinfo.com/SelectCryptic page and parses
+// the HTML on the page to determine the available puzzle dates.
+//
+// If the dates cannot be determined then an error is returned.
+func LoadNYTCrypticDatesFromXWordInfo() ([]time.Time, error) {
+	url := "https://www.xwordinfo.com/SelectCryptic"
+	response, err := web.Get(url)
+	if response != nil {
+		defer func() { _ = response.Body.Close() }()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dates, err := ParseXWordInfoCrypticDatesResponse(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse xwordinfo.com response for available cryptic dates: %v", err)
+	}
+
+	return dates, nil
+}
+
+// ParseXWordInfoCrypticDatesResponse converts an HTML response from the select
+// cryptic page on xwordinfo.com into a list of available dates.
+func ParseXWordInfoCrypticDatesResponse(in io.Reader) ([]time.Time, error) {
+	return model.ParseXWordInfoAvailableDates(in, "cryptic")
+}
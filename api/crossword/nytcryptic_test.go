@@ -0,0 +1,145 @@
+package crossword
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseXWordInfoCrypticResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  io.ReadCloser
+		verify func(t *testing.T, puzzle *Puzzle)
+	}{
+		{
+			name:  "description",
+			input: load(t, "xwordinfo-cryptic-20240107.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				expected := "New York Times cryptic puzzle from 2024-01-07"
+				assert.Equal(t, expected, puzzle.Description)
+			},
+		},
+		{
+			name:  "title",
+			input: load(t, "xwordinfo-cryptic-20240107.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				assert.Equal(t, "Variety: Cryptic Crossword", puzzle.Title)
+			},
+		},
+		{
+			name:  "published date",
+			input: load(t, "xwordinfo-cryptic-20240107.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				expected := time.Date(2024, time.January, 7, 0, 0, 0, 0, time.UTC)
+				assert.Equal(t, expected, puzzle.PublishedDate)
+			},
+		},
+		{
+			name:  "cells",
+			input: load(t, "xwordinfo-cryptic-20240107.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				expected := [][]string{
+					{"S", "T", "O", "R", "E"},
+					{"T", "", "V", "", "A"},
+					{"A", "L", "E", "R", "T"},
+					{"R", "", "R", "", "E"},
+					{"E", "A", "T", "E", "N"},
+				}
+				assert.Equal(t, expected, puzzle.Cells)
+			},
+		},
+		{
+			name:  "clue numbers",
+			input: load(t, "xwordinfo-cryptic-20240107.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				assert.Equal(t, NumberCells(puzzle.CellBlocks), puzzle.CellClueNumbers)
+			},
+		},
+		{
+			name:  "enumerations are preserved",
+			input: load(t, "xwordinfo-cryptic-20240107.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				assert.Equal(t, "Shop's reserve (5)", puzzle.CluesAcross[1])
+				assert.Equal(t, `Took in "tea" and ten, oddly (5)`, puzzle.CluesAcross[5])
+				assert.Equal(t, "Consumed, eventually, at a neat lunch (2,3)", puzzle.CluesDown[3])
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer test.input.Close()
+			puzzle, err := ParseXWordInfoCrypticResponse(test.input)
+			require.NoError(t, err)
+			test.verify(t, puzzle)
+		})
+	}
+}
+
+func TestParseXWordInfoCrypticResponse_Error(t *testing.T) {
+	_, err := ParseXWordInfoCrypticResponse(strings.NewReader(`{"grid": []}`))
+	assert.Error(t, err)
+}
+
+func TestParseXWordInfoCrypticDatesResponse(t *testing.T) {
+	input := load(t, "xwordinfo-select-cryptic-20240201.html")
+	defer input.Close()
+
+	dates, err := ParseXWordInfoCrypticDatesResponse(input)
+	require.NoError(t, err)
+
+	expected := []time.Time{
+		time.Date(2023, time.October, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, time.November, 5, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, time.December, 3, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, time.January, 7, 0, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, expected, dates)
+}
+
+func TestParseXWordInfoCrypticDatesResponse_Error(t *testing.T) {
+	tests := []struct {
+		name  string
+		input io.Reader
+	}{
+		{
+			name:  "reader returning error",
+			input: iotest.TimeoutReader(strings.NewReader("random input")),
+		},
+		{
+			name:  "malformed date",
+			input: strings.NewReader(`<a data-crypticdate="2024-01-07"/>`),
+		},
+		{
+			name:  "acrostic dates",
+			input: strings.NewReader(`<a data-acrdate="1/7/2024"/>`),
+		},
+		{
+			name:  "no dates",
+			input: strings.NewReader(`<html><body></body></html>`),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseXWordInfoCrypticDatesResponse(test.input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadAvailableNYTCrypticDates(t *testing.T) {
+	dates := []time.Time{time.Date(2024, time.January, 7, 0, 0, 0, 0, time.UTC)}
+	ForceAvailableCrypticDates(t, dates)
+
+	assert.Equal(t, dates, LoadAvailableNYTCrypticDates())
+	assert.NoError(t, CheckPuzzleDate("new_york_times_cryptic", "2024-01-07"))
+	assert.True(t, errors.Is(CheckPuzzleDate("new_york_times_cryptic", "2024-01-08"), ErrNoPuzzleOnDate))
+}
@@ -0,0 +1,193 @@
+package crossword
+
+import (
+	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"github.com/bbeck/puzzles-with-chat/api/web"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadFromNYTMini loads a New York Times Mini crossword puzzle for a particular
+// date.
+//
+// This method uses the same JSON API that the nytimes.com website uses to
+// render the Mini.  The Mini is a small (usually 5x5) puzzle that is published
+// daily alongside the regular New York Times crossword.
+//
+// If the puzzle cannot be loaded or parsed then an error is returned.
+func LoadFromNYTMini(date string) (*Puzzle, error) {
+	if testPuzzle != nil {
+		return testPuzzle, nil
+	}
+
+	if testPuzzleLoadError != nil {
+		return nil, testPuzzleLoadError
+	}
+
+	url := fmt.Sprintf("https://www.nytimes.com/svc/crosswords/v6/puzzle/mini/%s.json", date)
+	response, err := web.Get(url)
+	if response != nil {
+		defer func() { _ = response.Body.Close() }()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	puzzle, err := ParseNYTMiniResponse(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse nytimes.com mini response for date %s: %v", date, err)
+	}
+
+	return puzzle, nil
+}
+
+// NYTMiniPuzzle is a representation of the response from the nytimes.com JSON
+// API when querying for a Mini puzzle.
+type NYTMiniPuzzle struct {
+	Body []struct {
+		Cells []struct {
+			Answer string `json:"answer"`
+			Label  string `json:"label"`
+			Type   int    `json:"type"`
+		} `json:"cells"`
+		Clues []struct {
+			Cells     []int  `json:"cells"`
+			Direction string `json:"direction"`
+			Label     string `json:"label"`
+			Text      []struct {
+				Plain string `json:"plain"`
+			} `json:"text"`
+		} `json:"clues"`
+		Dimensions struct {
+			Height int `json:"height"`
+			Width  int `json:"width"`
+		} `json:"dimensions"`
+	} `json:"body"`
+	Constructors    []string `json:"constructors"`
+	PublicationDate string   `json:"publicationDate"`
+	Title           string   `json:"title"`
+}
+
+// ParseNYTMiniResponse converts a JSON response from nytimes.com for a Mini
+// puzzle into a puzzle object.
+func ParseNYTMiniResponse(in io.Reader) (*Puzzle, error) {
+	var raw NYTMiniPuzzle
+	if err := model.DecodeJSON(in, &raw); err != nil {
+		return nil, fmt.Errorf("unable to parse JSON response: %v", err)
+	}
+
+	if len(raw.Body) == 0 || len(raw.Body[0].Cells) == 0 {
+		return nil, fmt.Errorf("empty JSON response")
+	}
+	body := raw.Body[0]
+
+	rows := body.Dimensions.Height
+	cols := body.Dimensions.Width
+	if len(body.Cells) != rows*cols {
+		return nil, fmt.Errorf("incorrect number of cells (%d) for a %dx%d grid", len(body.Cells), rows, cols)
+	}
+
+	published, err := time.Parse("2006-01-02", raw.PublicationDate)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse date (%s) from JSON response: %v", raw.PublicationDate, err)
+	}
+
+	var cells [][]string
+	var blocks [][]bool
+	var numbers [][]int
+	var circles [][]bool
+	var shades [][]bool
+	for row := 0; row < rows; row++ {
+		cells = append(cells, make([]string, cols))
+		blocks = append(blocks, make([]bool, cols))
+		numbers = append(numbers, make([]int, cols))
+		circles = append(circles, make([]bool, cols))
+		shades = append(shades, make([]bool, cols))
+
+		for col := 0; col < cols; col++ {
+			cell := body.Cells[row*cols+col]
+
+			// Blocks are represented by an empty object without an answer.
+			if cell.Answer == "" {
+				blocks[row][col] = true
+				continue
+			}
+			cells[row][col] = cell.Answer
+
+			if cell.Label != "" {
+				num, err := strconv.Atoi(cell.Label)
+				if err != nil {
+					return nil, fmt.Errorf("unable to parse cell label %s: %v", cell.Label, err)
+				}
+				numbers[row][col] = num
+			}
+		}
+	}
+
+	across := make(map[int]string)
+	down := make(map[int]string)
+	for _, c := range body.Clues {
+		num, err := strconv.Atoi(c.Label)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse clue label %s: %v", c.Label, err)
+		}
+
+		var parts []string
+		for _, text := range c.Text {
+			parts = append(parts, text.Plain)
+		}
+		clue := model.UnescapeClue(strings.Join(parts, " "))
+
+		var clues map[int]string
+		switch c.Direction {
+		case "Across":
+			clues = across
+		case "Down":
+			clues = down
+		default:
+			return nil, fmt.Errorf("unrecognized clue direction %s", c.Direction)
+		}
+
+		if err := AddClue(clues, c.Direction, num, clue); err != nil {
+			return nil, err
+		}
+	}
+
+	var puzzle Puzzle
+	puzzle.Description = fmt.Sprintf("New York Times Mini puzzle from %s", published.Format("2006-01-02"))
+	puzzle.Rows = rows
+	puzzle.Cols = cols
+	puzzle.Variant = ClassifyVariant(puzzle.Rows, puzzle.Cols)
+	puzzle.Title = raw.Title
+	puzzle.Publisher = "The New York Times"
+	puzzle.PublishedDate = published
+	puzzle.Author = strings.Join(raw.Constructors, " and ")
+	puzzle.Cells = cells
+	puzzle.CellBlocks = blocks
+	puzzle.CellClueNumbers = numbers
+	puzzle.CellCircles = circles
+	puzzle.CellShades = shades
+	puzzle.CluesAcross = across
+	puzzle.CluesDown = down
+
+	return &puzzle, nil
+}
+
+// NYTMiniFirstPuzzleDate is the date of the first New York Times Mini puzzle.
+var NYTMiniFirstPuzzleDate = time.Date(2014, time.August, 21, 0, 0, 0, 0, time.UTC)
+
+// LoadAvailableNYTMiniDates calculates the set of available dates for Mini
+// crossword puzzles from The New York Times.
+func LoadAvailableNYTMiniDates() []time.Time {
+	now := time.Now().UTC()
+
+	var dates []time.Time
+	for date := NYTMiniFirstPuzzleDate; date.Before(now) || date.Equal(now); date = date.AddDate(0, 0, 1) {
+		dates = append(dates, date)
+	}
+
+	return dates
+}
@@ -0,0 +1,261 @@
+package crossword
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNYTMiniResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  io.ReadCloser
+		verify func(t *testing.T, puzzle *Puzzle)
+	}{
+		{
+			name:  "description",
+			input: load(t, "nyt-mini-20240105.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				expected := "New York Times Mini puzzle from 2024-01-05"
+				assert.Equal(t, expected, puzzle.Description)
+			},
+		},
+		{
+			name:  "size",
+			input: load(t, "nyt-mini-20240105.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				assert.Equal(t, 5, puzzle.Cols)
+				assert.Equal(t, 5, puzzle.Rows)
+			},
+		},
+		{
+			name:  "publisher",
+			input: load(t, "nyt-mini-20240105.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				assert.Equal(t, "The New York Times", puzzle.Publisher)
+			},
+		},
+		{
+			name:  "published date",
+			input: load(t, "nyt-mini-20240105.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				expected := time.Date(2024, time.January, 5, 0, 0, 0, 0, time.UTC)
+				assert.Equal(t, expected, puzzle.PublishedDate)
+			},
+		},
+		{
+			name:  "author",
+			input: load(t, "nyt-mini-20240105.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				assert.Equal(t, "Joel Fagliano", puzzle.Author)
+			},
+		},
+		{
+			name:  "cells",
+			input: load(t, "nyt-mini-20240105.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				expected := [][]string{
+					{"", "S", "P", "A", "T"},
+					{"S", "T", "A", "R", "E"},
+					{"C", "A", "N", "O", "E"},
+					{"A", "R", "T", "S", "Y"},
+					{"B", "E", "S", "T", ""},
+				}
+				assert.Equal(t, expected, puzzle.Cells)
+			},
+		},
+		{
+			name:  "cell blocks",
+			input: load(t, "nyt-mini-20240105.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				expected := [][]bool{
+					{true, false, false, false, false},
+					{false, false, false, false, false},
+					{false, false, false, false, false},
+					{false, false, false, false, false},
+					{false, false, false, false, true},
+				}
+				assert.Equal(t, expected, puzzle.CellBlocks)
+			},
+		},
+		{
+			name:  "cell clue numbers",
+			input: load(t, "nyt-mini-20240105.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				expected := [][]int{
+					{0, 1, 2, 3, 4},
+					{5, 0, 0, 0, 0},
+					{6, 0, 0, 0, 0},
+					{7, 0, 0, 0, 0},
+					{8, 0, 0, 0, 0},
+				}
+				assert.Equal(t, expected, puzzle.CellClueNumbers)
+			},
+		},
+		{
+			name:  "across clues",
+			input: load(t, "nyt-mini-20240105.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				expected := map[int]string{
+					1: "Minor quarrel",
+					5: "Look long and hard",
+					6: "Paddled craft",
+					7: "A little too bohemian",
+					8: "Top-notch",
+				}
+				assert.Equal(t, expected, puzzle.CluesAcross)
+			},
+		},
+		{
+			name:  "down clues",
+			input: load(t, "nyt-mini-20240105.json"),
+			verify: func(t *testing.T, puzzle *Puzzle) {
+				expected := map[int]string{
+					1: "Gaze fixedly",
+					2: "Trousers",
+					3: "Got up, old-style",
+					4: "Like a tiny golf peg",
+					5: "Healing crust",
+				}
+				assert.Equal(t, expected, puzzle.CluesDown)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer test.input.Close()
+
+			puzzle, err := ParseNYTMiniResponse(test.input)
+			require.NoError(t, err)
+			test.verify(t, puzzle)
+		})
+	}
+}
+
+func TestParseNYTMiniResponse_Error(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "malformed response",
+			input: `{true}`,
+		},
+		{
+			name:  "empty response",
+			input: ``,
+		},
+		{
+			name:  "empty puzzle",
+			input: `{}`,
+		},
+		{
+			name: "incorrect number of cells",
+			input: `{
+								"publicationDate": "2024-01-05",
+								"body": [{
+									"cells": [{"answer": "A"}],
+									"dimensions": {"height": 2, "width": 2}
+								}]
+							}`,
+		},
+		{
+			name: "malformed published date",
+			input: `{
+								"publicationDate": "hello world",
+								"body": [{
+									"cells": [{"answer": "A"}],
+									"dimensions": {"height": 1, "width": 1}
+								}]
+							}`,
+		},
+		{
+			name: "malformed clue label",
+			input: `{
+								"publicationDate": "2024-01-05",
+								"body": [{
+									"cells": [{"answer": "A", "label": "1"}],
+									"clues": [{"direction": "Across", "label": "A"}],
+									"dimensions": {"height": 1, "width": 1}
+								}]
+							}`,
+		},
+		{
+			name: "unrecognized clue direction",
+			input: `{
+								"publicationDate": "2024-01-05",
+								"body": [{
+									"cells": [{"answer": "A", "label": "1"}],
+									"clues": [{"direction": "Diagonal", "label": "1"}],
+									"dimensions": {"height": 1, "width": 1}
+								}]
+							}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseNYTMiniResponse(strings.NewReader(test.input))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestParseNYTMiniResponse_DuplicateClueNumbers(t *testing.T) {
+	// Renumber the second across clue so that it has the same number as the
+	// first.
+	var raw map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(toString(t, load(t, "nyt-mini-20240105.json"))), &raw))
+
+	clues := raw["body"].([]interface{})[0].(map[string]interface{})["clues"].([]interface{})
+	clues[1].(map[string]interface{})["label"] = "1"
+
+	bs, err := json.Marshal(raw)
+	require.NoError(t, err)
+
+	_, err = ParseNYTMiniResponse(bytes.NewReader(bs))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate across clue number 1")
+}
+
+func TestLoadAvailableNYTMiniDates(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected time.Time
+	}{
+		{
+			name:     "first puzzle date",
+			expected: NYTMiniFirstPuzzleDate,
+		},
+		{
+			name:     "2020-01-01",
+			expected: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "today",
+			expected: time.Now().UTC().Truncate(24 * time.Hour),
+		},
+	}
+
+	dates := LoadAvailableNYTMiniDates()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.True(t, sort.SliceIsSorted(dates, func(i, j int) bool {
+				return dates[i].Before(dates[j])
+			}))
+
+			index := sort.Search(len(dates), func(i int) bool {
+				return dates[i].Equal(test.expected) || dates[i].After(test.expected)
+			})
+			assert.Equal(t, test.expected, dates[index])
+		})
+	}
+}
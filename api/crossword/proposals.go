@@ -0,0 +1,216 @@
+package crossword
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/db"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxProposalsPerClue is the maximum number of distinct answers that can be
+// proposed for a single clue at the same time.
+var MaxProposalsPerClue = 5
+
+// ProposalTimeout is how long a proposed answer remains open for voting.
+// Proposals that haven't been applied by then are discarded.
+var ProposalTimeout = 5 * time.Minute
+
+// ErrTooManyProposals is returned when a clue already has the maximum number of
+// proposals and a new answer is proposed for it.
+var ErrTooManyProposals = errors.New("too many proposals")
+
+// ErrUnknownProposal is returned when a vote is cast for an answer that hasn't
+// been proposed.
+var ErrUnknownProposal = errors.New("unknown proposal")
+
+// Proposal is an answer for a clue that has been proposed by chat, but not yet
+// applied to the puzzle.  Users in chat can vote for or against the proposal to
+// indicate their confidence in it.
+type Proposal struct {
+	// The proposed answer, normalized to uppercase without any whitespace.
+	Answer string `json:"answer"`
+
+	// The sum of all of the votes that have been cast for the proposal.
+	Score int `json:"score"`
+
+	// The vote each user cast for the proposal indexed by user name.  A vote is
+	// either +1 or -1.  Votes are never sent to clients, only the score is.
+	Votes map[string]int `json:"votes,omitempty"`
+
+	// The time that the answer was proposed.
+	ProposedTime time.Time `json:"proposed_time"`
+}
+
+// ProposalsKey returns the key that should be used in redis to store the
+// proposals of a particular channel's solve.  Proposals are kept separate from
+// the state because they're short lived.
+func ProposalsKey(name string) string {
+	return fmt.Sprintf("%s:crossword:proposals", name)
+}
+
+// GetProposals loads the proposals of the channel's solve from redis indexed by
+// clue.  If there are no proposals then nil is returned.
+func GetProposals(conn db.Connection, channel string) (map[string][]Proposal, error) {
+	var proposals map[string][]Proposal
+	if err := db.Get(conn, ProposalsKey(channel), &proposals); err != nil {
+		return nil, err
+	}
+
+	return proposals, nil
+}
+
+// SetProposals writes the proposals of the channel's solve to redis.  They
+// expire once the newest of them would have timed out.  If there are no
+// proposals then any previously stored proposals are removed.
+func SetProposals(conn db.Connection, channel string, proposals map[string][]Proposal) error {
+	if len(proposals) == 0 {
+		_, err := conn.Do("DEL", ProposalsKey(channel))
+		return err
+	}
+
+	return db.SetWithTTL(conn, ProposalsKey(channel), proposals, ProposalTimeout)
+}
+
+// PruneProposals discards the proposals that have been open for longer than
+// ProposalTimeout as well as the proposals for clues that have since been
+// filled in.
+func (s *State) PruneProposals(now time.Time) {
+	for key, proposals := range s.Proposals {
+		num, direction, err := ParseClue(key)
+		if err != nil {
+			delete(s.Proposals, key)
+			continue
+		}
+
+		filled := s.AcrossCluesFilled[num]
+		if direction == "d" {
+			filled = s.DownCluesFilled[num]
+		}
+		if filled {
+			delete(s.Proposals, key)
+			continue
+		}
+
+		var open []Proposal
+		for _, proposal := range proposals {
+			if now.Sub(proposal.ProposedTime) < ProposalTimeout {
+				open = append(open, proposal)
+			}
+		}
+
+		if len(open) == 0 {
+			delete(s.Proposals, key)
+		} else {
+			s.Proposals[key] = open
+		}
+	}
+}
+
+// Propose adds an answer for a clue to the state's proposals.  If the answer
+// has already been proposed for the clue then nothing changes.  If the clue
+// cannot be identified or the answer doesn't fit properly then an error will be
+// returned.  If the clue already has the maximum number of proposals then
+// ErrTooManyProposals is returned.
+func (s *State) Propose(clue string, answer string) error {
+	key, answer, err := s.normalizeProposal(clue, answer)
+	if err != nil {
+		return err
+	}
+
+	for _, proposal := range s.Proposals[key] {
+		if proposal.Answer == answer {
+			return nil
+		}
+	}
+
+	if len(s.Proposals[key]) >= MaxProposalsPerClue {
+		return fmt.Errorf("%w: clue %s", ErrTooManyProposals, key)
+	}
+
+	if s.Proposals == nil {
+		s.Proposals = make(map[string][]Proposal)
+	}
+	s.Proposals[key] = append(s.Proposals[key], Proposal{Answer: answer, ProposedTime: time.Now()})
+
+	return nil
+}
+
+// Vote records a user's vote for or against a proposed answer for a clue.  Each
+// user has a single vote per proposal, voting again replaces their previous
+// vote.  The proposal with its updated score is returned.  If the answer hasn't
+// been proposed for the clue then ErrUnknownProposal is returned.
+func (s *State) Vote(clue string, answer string, user string, up bool) (Proposal, error) {
+	key, answer, err := s.normalizeProposal(clue, answer)
+	if err != nil {
+		return Proposal{}, err
+	}
+
+	proposals := s.Proposals[key]
+	for i := range proposals {
+		if proposals[i].Answer != answer {
+			continue
+		}
+
+		if proposals[i].Votes == nil {
+			proposals[i].Votes = make(map[string]int)
+		}
+
+		vote := -1
+		if up {
+			vote = 1
+		}
+		proposals[i].Score += vote - proposals[i].Votes[user]
+		proposals[i].Votes[user] = vote
+
+		// Keep the proposals ordered with the most popular first.
+		sort.SliceStable(proposals, func(i, j int) bool {
+			return proposals[i].Score > proposals[j].Score
+		})
+
+		for _, proposal := range proposals {
+			if proposal.Answer == answer {
+				return proposal, nil
+			}
+		}
+	}
+
+	return Proposal{}, fmt.Errorf("%w: %s for clue %s", ErrUnknownProposal, answer, key)
+}
+
+// ClearProposals removes all of the proposals for a clue.
+func (s *State) ClearProposals(clue string) {
+	num, direction, err := ParseClue(clue)
+	if err != nil {
+		return
+	}
+
+	delete(s.Proposals, fmt.Sprintf("%d%s", num, direction))
+}
+
+// normalizeProposal verifies that an answer fits a clue and returns the
+// normalized forms of the clue and the answer.
+func (s *State) normalizeProposal(clue string, answer string) (string, string, error) {
+	num, direction, err := ParseClue(clue)
+	if err != nil {
+		return "", "", err
+	}
+
+	cells, err := ParseAnswer(answer)
+	if err != nil {
+		return "", "", err
+	}
+
+	minX, minY, maxX, maxY, err := s.Puzzle.GetAnswerCoordinates(num, direction)
+	if err != nil {
+		return "", "", err
+	}
+
+	if len(cells) != (maxX-minX)+(maxY-minY)+1 {
+		return "", "", &AnswerLengthError{Expected: (maxX - minX) + (maxY - minY) + 1, Actual: len(cells)}
+	}
+
+	answer = strings.ToUpper(strings.Join(strings.Fields(answer), ""))
+	return fmt.Sprintf("%d%s", num, direction), answer, nil
+}
@@ -0,0 +1,185 @@
+package crossword
+
+import (
+	"errors"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestState_Propose(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+
+	require.NoError(t, state.Propose("1a", "q and a"))
+	require.NoError(t, state.Propose("1A", "QANDA")) // duplicate
+	require.NoError(t, state.Propose("1a", "QANDO"))
+	require.NoError(t, state.Propose("6a", "ATTIC"))
+
+	assert.Equal(t, []string{"QANDA", "QANDO"}, ProposedAnswers(state.Proposals["1a"]))
+	assert.Equal(t, []string{"ATTIC"}, ProposedAnswers(state.Proposals["6a"]))
+}
+
+func TestState_Propose_Error(t *testing.T) {
+	tests := []struct {
+		name   string
+		clue   string
+		answer string
+	}{
+		{
+			name:   "invalid clue",
+			clue:   "1x",
+			answer: "QANDA",
+		},
+		{
+			name:   "unknown clue",
+			clue:   "999a",
+			answer: "QANDA",
+		},
+		{
+			name:   "answer too short",
+			clue:   "1a",
+			answer: "QAND",
+		},
+		{
+			name:   "answer too long",
+			clue:   "1a",
+			answer: "QANDAS",
+		},
+		{
+			name:   "empty answer",
+			clue:   "1a",
+			answer: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			assert.Error(t, state.Propose(test.clue, test.answer))
+			assert.Empty(t, state.Proposals)
+		})
+	}
+}
+
+func TestState_Propose_TooMany(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+
+	for _, answer := range []string{"AAAAA", "BBBBB", "CCCCC", "DDDDD", "EEEEE"} {
+		require.NoError(t, state.Propose("1a", answer))
+	}
+
+	err := state.Propose("1a", "FFFFF")
+	assert.True(t, errors.Is(err, ErrTooManyProposals))
+	assert.Equal(t, MaxProposalsPerClue, len(state.Proposals["1a"]))
+
+	// Proposing an existing answer is still fine.
+	assert.NoError(t, state.Propose("1a", "AAAAA"))
+}
+
+func TestState_Vote(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, state.Propose("1a", "QANDO"))
+	require.NoError(t, state.Propose("1a", "QANDA"))
+
+	proposal, err := state.Vote("1a", "QANDA", "alice", true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, proposal.Score)
+
+	proposal, err = state.Vote("1a", "q and a", "bob", true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, proposal.Score)
+
+	// Changing a vote replaces the previous one.
+	proposal, err = state.Vote("1a", "QANDA", "bob", false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, proposal.Score)
+
+	// Voting the same way twice only counts once.
+	proposal, err = state.Vote("1a", "QANDO", "carol", true)
+	require.NoError(t, err)
+	proposal, err = state.Vote("1a", "QANDO", "carol", true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, proposal.Score)
+
+	// The most popular proposal should be first.
+	assert.Equal(t, "QANDO", state.Proposals["1a"][0].Answer)
+	assert.Equal(t, "QANDA", state.Proposals["1a"][1].Answer)
+
+	// Votes for an answer that wasn't proposed fail.
+	_, err = state.Vote("1a", "QANDY", "alice", true)
+	assert.True(t, errors.Is(err, ErrUnknownProposal))
+}
+
+func TestState_ClearProposals(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, state.Propose("1a", "QANDA"))
+	require.NoError(t, state.Propose("6a", "ATTIC"))
+
+	state.ClearProposals("1A")
+	assert.Nil(t, state.Proposals["1a"])
+	assert.NotNil(t, state.Proposals["6a"])
+}
+
+func TestState_PruneProposals(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, state.Propose("1a", "QANDA"))
+	require.NoError(t, state.Propose("6a", "ATTIC"))
+	require.NoError(t, state.Propose("1d", "QUAD"))
+
+	// An old proposal for a clue expires while a newer one stays open.
+	state.Proposals["1d"][0].ProposedTime = time.Now().Add(-2 * ProposalTimeout)
+	require.NoError(t, state.Propose("1d", "QUIT"))
+
+	// Filled in clues no longer need proposals.
+	require.NoError(t, state.ApplyAnswer("6a", "ATTIC", false))
+
+	state.PruneProposals(time.Now())
+	assert.Equal(t, []string{"QANDA"}, ProposedAnswers(state.Proposals["1a"]))
+	assert.Equal(t, []string{"QUIT"}, ProposedAnswers(state.Proposals["1d"]))
+	assert.NotContains(t, state.Proposals, "6a")
+
+	// Once every proposal for a clue has expired the clue is removed.
+	state.PruneProposals(time.Now().Add(ProposalTimeout))
+	assert.Empty(t, state.Proposals)
+}
+
+func TestGetProposals(t *testing.T) {
+	_, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	proposals, err := GetProposals(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Nil(t, proposals)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, state.Propose("1a", "QANDA"))
+	require.NoError(t, SetProposals(conn, Channel.name, state.Proposals))
+
+	proposals, err = GetProposals(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"QANDA"}, ProposedAnswers(proposals["1a"]))
+
+	// Proposals are short lived.
+	ttl, err := redis.Int(conn.Do("PTTL", ProposalsKey(Channel.name)))
+	require.NoError(t, err)
+	assert.True(t, ttl > 0)
+	assert.True(t, time.Duration(ttl)*time.Millisecond <= ProposalTimeout)
+
+	// Saving no proposals removes them.
+	require.NoError(t, SetProposals(conn, Channel.name, nil))
+	proposals, err = GetProposals(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Nil(t, proposals)
+}
+
+// ProposedAnswers returns the answers of a list of proposals in order.
+func ProposedAnswers(proposals []Proposal) []string {
+	var answers []string
+	for _, proposal := range proposals {
+		answers = append(answers, proposal.Answer)
+	}
+
+	return answers
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/model"
 	"github.com/bbeck/puzzles-with-chat/api/web"
 	"golang.org/x/text/encoding/charmap"
 	"io"
@@ -89,6 +90,16 @@ func LoadFromEncodedPuzFile(encoded string) (*Puzzle, error) {
 // If the URL cannot be retrieved or the puzzle parsed then an error is
 // returned.
 func LoadFromPuzFileURL(url string) (*Puzzle, error) {
+	return LoadFromPuzFileURLWithHeaders(url, nil)
+}
+
+// LoadFromPuzFileURLWithHeaders will take a URL and retrieve it using the
+// supplied headers and load it into a Puzzle object.  This allows sources whose
+// sites expect particular headers to be sent to provide them.
+//
+// If the URL cannot be retrieved or the puzzle parsed then an error is
+// returned.
+func LoadFromPuzFileURLWithHeaders(url string, headers map[string]string) (*Puzzle, error) {
 	if testPuzzle != nil {
 		return testPuzzle, nil
 	}
@@ -98,7 +109,7 @@ func LoadFromPuzFileURL(url string) (*Puzzle, error) {
 	}
 
 	// First, download the .puz file from the URL.
-	response, err := web.Get(url)
+	response, err := web.GetWithHeaders(url, headers)
 	if response != nil {
 		defer func() { _ = response.Body.Close() }()
 	}
@@ -597,6 +608,7 @@ func (f *PuzFile) Convert() (*Puzzle, error) {
 	puzzle.Description = "Crossword loaded from .puz file"
 	puzzle.Rows = int(f.Header.Height)
 	puzzle.Cols = int(f.Header.Width)
+	puzzle.Variant = ClassifyVariant(puzzle.Rows, puzzle.Cols)
 	puzzle.Title = decode(f.Title)
 
 	puzzle.Author = strings.TrimSpace(decode(f.Author))
@@ -604,6 +616,7 @@ func (f *PuzFile) Convert() (*Puzzle, error) {
 		puzzle.Author = puzzle.Author[3:]
 	}
 
+	puzzle.Copyright = strings.TrimSpace(decode(f.Copyright))
 	puzzle.Notes = strings.TrimSpace(decode(f.Notes))
 
 	// Parse the entries of the rebus table if one exists.
@@ -681,7 +694,10 @@ func (f *PuzFile) Convert() (*Puzzle, error) {
 					nextClueNumber++
 				}
 
-				puzzle.CluesAcross[puzzle.CellClueNumbers[y][x]] = decode(f.Clues[nextClueIndex])
+				clue := model.UnescapeClue(decode(f.Clues[nextClueIndex]))
+				if err := AddClue(puzzle.CluesAcross, "across", puzzle.CellClueNumbers[y][x], clue); err != nil {
+					return nil, err
+				}
 				nextClueIndex++
 			}
 
@@ -694,7 +710,10 @@ func (f *PuzFile) Convert() (*Puzzle, error) {
 					nextClueNumber++
 				}
 
-				puzzle.CluesDown[puzzle.CellClueNumbers[y][x]] = decode(f.Clues[nextClueIndex])
+				clue := model.UnescapeClue(decode(f.Clues[nextClueIndex]))
+				if err := AddClue(puzzle.CluesDown, "down", puzzle.CellClueNumbers[y][x], clue); err != nil {
+					return nil, err
+				}
 				nextClueIndex++
 			}
 		}
@@ -711,12 +730,45 @@ func (f *PuzFile) Convert() (*Puzzle, error) {
 		}
 	}
 
+	// Determine if any cells are givens.  These are cells whose contents are
+	// provided as part of the puzzle.  Only include the givens in the puzzle when
+	// at least one cell is a given.
+	if extension := f.Extensions["GEXT"]; extension != nil {
+		var givens [][]bool
+		var found bool
+		for y := 0; y < puzzle.Rows; y++ {
+			givens = append(givens, make([]bool, puzzle.Cols))
+
+			for x := 0; x < puzzle.Cols; x++ {
+				givens[y][x] = !puzzle.CellBlocks[y][x] && extension.Data[y*puzzle.Cols+x]&0x40 != 0
+				found = found || givens[y][x]
+			}
+		}
+
+		if found {
+			puzzle.CellGivens = givens
+		}
+	}
+
 	// Shaded cells aren't supported by .puz files so initialize the 2D array to
 	// all false values.
 	for y := 0; y < puzzle.Rows; y++ {
 		puzzle.CellShades = append(puzzle.CellShades, make([]bool, puzzle.Cols))
 	}
 
+	// Some .puz files are distributed with the player's grid already completely
+	// filled in with the correct answers.  Detect this so that the puzzle can be
+	// reviewed instead of solved.  Rebus cells only have their first letter
+	// present in the player's grid so we compare against the first letter of the
+	// solution.
+	puzzle.Prefilled = true
+	for i := 0; i < len(f.Solution) && i < len(f.Cells); i++ {
+		if f.Solution[i] != '.' && f.Cells[i] != f.Solution[i] {
+			puzzle.Prefilled = false
+			break
+		}
+	}
+
 	// Check if an error occurred anywhere.
 	if errs != nil {
 		var err = errors.New("an error occurred while converting")
@@ -111,6 +111,99 @@ func TestLoadFromPuzFileURL_EquivalenceWithKnownGood(t *testing.T) {
 	}
 }
 
+func TestLoadPuzFile_Prefilled(t *testing.T) {
+	tests := []struct {
+		name        string
+		puzFilename string // relative to the testdata/puz directory
+		expected    bool
+	}{
+		{
+			name:        "empty grid",
+			puzFilename: "nyt-20081006-nonsquare.puz",
+			expected:    false,
+		},
+		{
+			name:        "partly filled grid",
+			puzFilename: "puzpy-nyt-20080310-partly-filled.puz",
+			expected:    false,
+		},
+		{
+			name:        "completely filled grid",
+			puzFilename: "nyt-20081006-nonsquare-filled.puz",
+			expected:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			puzzle := loadPuz(t, test.puzFilename)
+			assert.Equal(t, test.expected, puzzle.Prefilled)
+		})
+	}
+}
+
+func TestLoadPuzFile_Variant(t *testing.T) {
+	tests := []struct {
+		name        string
+		puzFilename string // relative to the testdata/puz directory
+		rows        int
+		cols        int
+		expected    Variant
+	}{
+		{
+			name:        "washington post daily",
+			puzFilename: "puzpy-washpost-20051206.puz",
+			rows:        15,
+			cols:        15,
+			expected:    VariantDaily,
+		},
+		{
+			name:        "wall street journal sunday sized",
+			puzFilename: "puzpy-wsj-20110624.puz",
+			rows:        21,
+			cols:        21,
+			expected:    VariantSunday,
+		},
+		{
+			name:        "oversized nonsquare",
+			puzFilename: "nyt-20081006-nonsquare.puz",
+			rows:        9,
+			cols:        24,
+			expected:    VariantOversized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			puzzle := loadPuz(t, test.puzFilename)
+			assert.Equal(t, test.rows, puzzle.Rows)
+			assert.Equal(t, test.cols, puzzle.Cols)
+			assert.Equal(t, test.expected, puzzle.Variant)
+		})
+	}
+}
+
+func TestLoadPuzFile_Givens(t *testing.T) {
+	puzzle := loadPuz(t, "nyt-20081006-nonsquare.puz")
+	assert.Nil(t, puzzle.CellGivens)
+
+	puzzle = loadPuz(t, "nyt-20081006-nonsquare-givens.puz")
+	require.NotNil(t, puzzle.CellGivens)
+
+	var givens [][2]int
+	for y := 0; y < puzzle.Rows; y++ {
+		for x := 0; x < puzzle.Cols; x++ {
+			if puzzle.CellGivens[y][x] {
+				givens = append(givens, [2]int{x, y})
+			}
+		}
+	}
+	assert.ElementsMatch(t, [][2]int{{0, 0}, {2, 0}, {1, 1}}, givens)
+
+	// The givens should be sent to clients.
+	assert.Equal(t, puzzle.CellGivens, puzzle.WithoutSolution().CellGivens)
+}
+
 func loadPuz(t *testing.T, filename string) *Puzzle {
 	t.Helper()
 
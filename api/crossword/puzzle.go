@@ -1,10 +1,28 @@
 package crossword
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
+	"unicode"
 )
 
+// ErrAmbiguousClueText is returned when searching for a clue by its text and
+// more than one clue matches.
+var ErrAmbiguousClueText = errors.New("ambiguous clue text")
+
+// ErrNoSolution is returned when the solution to a puzzle is needed but the
+// puzzle doesn't have one, for example because it was imported from a snapshot
+// that omitted the solution.
+var ErrNoSolution = errors.New("puzzle has no solution")
+
+// ErrUnknownClue is returned when a clue is referenced that doesn't exist in
+// the puzzle.
+var ErrUnknownClue = errors.New("unknown clue")
+
 // Puzzle represents a crossword puzzle.  The puzzle is comprised of a
 // grid which has dimensions (rows x cols) and demonstrates which cells of the
 // crossword are available for placing letters into and which are not.
@@ -22,6 +40,9 @@ type Puzzle struct {
 	// The number of columns in the crossword grid.
 	Cols int `json:"cols"`
 
+	// The size classification of the crossword grid (e.g. daily or sunday).
+	Variant Variant `json:"variant"`
+
 	// The title of the crossword.
 	Title string `json:"title"`
 
@@ -34,6 +55,9 @@ type Puzzle struct {
 	// The name of the author(s) of the crossword.
 	Author string `json:"author"`
 
+	// The copyright notice of the crossword, if its source provided one.
+	Copyright string `json:"copyright,omitempty"`
+
 	// The cells of the crossword as a 2D list, entries are the letter (or letters
 	// in the case of a rebus) that belong in the cell.  If a cell cannot be
 	// inputted into then it will contain the empty string.  The lists are first
@@ -68,6 +92,23 @@ type Puzzle struct {
 	// column coordinate.
 	CellShades [][]bool `json:"cell_shades"`
 
+	// Whether or not a cell is a given for all of the cells in the crossword as
+	// a 2D list.  A given is a cell whose value is provided to the solver as
+	// part of the puzzle and therefore starts out filled in and can't be
+	// changed.  Givens appear as true and all other cells appear as false.  Like
+	// cells the 2D list is first indexed by the row coordinate of the cell and
+	// then by the column coordinate.  Puzzles without any givens omit this list.
+	CellGivens [][]bool `json:"cell_givens,omitempty"`
+
+	// Whether or not a cell is void for all of the cells in the crossword as a 2D
+	// list.  A void cell is drawn as part of the grid and may hold a number, but
+	// never holds a letter.  Void cells are also blocks so that answers end at
+	// them.  Void cells appear as true and all other cells appear as false.  Like
+	// cells the 2D list is first indexed by the row coordinate of the cell and
+	// then by the column coordinate.  Puzzles without any void cells omit this
+	// list.
+	CellVoids [][]bool `json:"cell_voids,omitempty"`
+
 	// The clues for the across answers indexed by the clue number.
 	CluesAcross map[int]string `json:"clues_across"`
 
@@ -79,6 +120,134 @@ type Puzzle struct {
 	// be done online.  These notes describe the visual change so that the
 	// crossword can be solved online.
 	Notes string `json:"notes"`
+
+	// Whether or not the puzzle was distributed with all of its answers already
+	// filled in.  Some archives distribute puzzles this way, and instead of
+	// being solved they're offered for review.
+	Prefilled bool `json:"prefilled,omitempty"`
+
+	// Whether or not the across answers of the puzzle read from right to left, as
+	// they do in puzzles written in languages such as Hebrew or Arabic.  The
+	// number of an across clue is in the rightmost cell of its answer.
+	RightToLeft bool `json:"right_to_left,omitempty"`
+}
+
+// Variant is a classification of a crossword by the size of its grid.  Puzzles
+// of the same variant are displayed the same way regardless of their source.
+type Variant string
+
+const (
+	// Small puzzles, such as the New York Times Mini.
+	VariantMini Variant = "mini"
+
+	// Standard sized puzzles, typically 15x15.
+	VariantDaily Variant = "daily"
+
+	// Larger puzzles that are typically published on Sundays, such as 21x21.
+	VariantSunday Variant = "sunday"
+
+	// Puzzles that are even larger than a Sunday puzzle.
+	VariantOversized Variant = "oversized"
+)
+
+// ClassifyVariant determines the variant of a crossword from the dimensions of
+// its grid.  The larger dimension determines the variant so that non-square
+// puzzles are classified by their longest side.
+func ClassifyVariant(rows, cols int) Variant {
+	size := rows
+	if cols > size {
+		size = cols
+	}
+
+	switch {
+	case size <= 8:
+		return VariantMini
+	case size <= 17:
+		return VariantDaily
+	case size <= 23:
+		return VariantSunday
+	default:
+		return VariantOversized
+	}
+}
+
+// Validate checks that the puzzle is internally consistent: each of its grids
+// has the puzzle's dimensions, every cell that isn't a block has a solution,
+// and every clue starts at a numbered cell.  Puzzles that are loaded from a
+// source are always valid, this is for puzzles that come from elsewhere.  A
+// puzzle without any solution at all is valid as long as the rest of it is.
+func (p *Puzzle) Validate() error {
+	if p.Rows <= 0 || p.Cols <= 0 {
+		return fmt.Errorf("invalid dimensions %dx%d", p.Rows, p.Cols)
+	}
+
+	// Ensure a grid has the puzzle's dimensions.  Optional grids may be missing
+	// entirely.
+	check := func(name string, rows int, cols func(row int) int, optional bool) error {
+		if optional && rows == 0 {
+			return nil
+		}
+
+		if rows != p.Rows {
+			return fmt.Errorf("%s has %d rows, expected %d", name, rows, p.Rows)
+		}
+
+		for row := 0; row < rows; row++ {
+			if n := cols(row); n != p.Cols {
+				return fmt.Errorf("row %d of %s has %d columns, expected %d", row, name, n, p.Cols)
+			}
+		}
+
+		return nil
+	}
+
+	grids := []error{
+		check("cells", len(p.Cells), func(row int) int { return len(p.Cells[row]) }, !p.HasSolution()),
+		check("cell blocks", len(p.CellBlocks), func(row int) int { return len(p.CellBlocks[row]) }, false),
+		check("cell clue numbers", len(p.CellClueNumbers), func(row int) int { return len(p.CellClueNumbers[row]) }, false),
+		check("cell circles", len(p.CellCircles), func(row int) int { return len(p.CellCircles[row]) }, true),
+		check("cell shades", len(p.CellShades), func(row int) int { return len(p.CellShades[row]) }, true),
+		check("cell givens", len(p.CellGivens), func(row int) int { return len(p.CellGivens[row]) }, true),
+		check("cell voids", len(p.CellVoids), func(row int) int { return len(p.CellVoids[row]) }, true),
+	}
+	for _, err := range grids {
+		if err != nil {
+			return err
+		}
+	}
+
+	for y := 0; y < p.Rows && p.HasSolution(); y++ {
+		for x := 0; x < p.Cols; x++ {
+			if p.CellBlocks[y][x] != (p.Cells[y][x] == "") {
+				return fmt.Errorf("cell (%d, %d) is inconsistent with its block", x, y)
+			}
+		}
+	}
+
+	for num := range p.CluesAcross {
+		if _, err := p.ClueStartingAt(num, "a"); err != nil {
+			return err
+		}
+	}
+	for num := range p.CluesDown {
+		if _, err := p.ClueStartingAt(num, "d"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddClue records the text of a clue in the provided clues of a direction.
+// Every loader adds its clues this way so that a source that repeats a clue
+// number is rejected instead of one of the clues silently replacing the other.
+func AddClue(clues map[int]string, direction string, num int, clue string) error {
+	if _, ok := clues[num]; ok {
+		return fmt.Errorf("duplicate %s clue number %d: %s", strings.ToLower(direction), num, clue)
+	}
+
+	clues[num] = clue
+	return nil
 }
 
 // WithoutSolution returns a copy of the puzzle that has the solution cells
@@ -89,22 +258,402 @@ func (p *Puzzle) WithoutSolution() *Puzzle {
 	puzzle.Description = p.Description
 	puzzle.Rows = p.Rows
 	puzzle.Cols = p.Cols
+	puzzle.Variant = p.Variant
 	puzzle.Title = p.Title
 	puzzle.Publisher = p.Publisher
 	puzzle.PublishedDate = p.PublishedDate
 	puzzle.Author = p.Author
+	puzzle.Copyright = p.Copyright
 	puzzle.Cells = nil
 	puzzle.CellBlocks = p.CellBlocks
 	puzzle.CellClueNumbers = p.CellClueNumbers
 	puzzle.CellCircles = p.CellCircles
 	puzzle.CellShades = p.CellShades
+	puzzle.CellGivens = p.CellGivens
+	puzzle.CellVoids = p.CellVoids
 	puzzle.CluesAcross = p.CluesAcross
 	puzzle.CluesDown = p.CluesDown
 	puzzle.Notes = p.Notes
+	puzzle.Prefilled = p.Prefilled
+	puzzle.RightToLeft = p.RightToLeft
 
 	return &puzzle
 }
 
+// HasSolution returns whether or not the puzzle includes its solution.  Puzzles
+// sent to clients and those imported from a solution-free snapshot don't.
+func (p *Puzzle) HasSolution() bool {
+	return p.Cells != nil
+}
+
+// HasSameShape returns whether or not another puzzle's grid has the same shape
+// as this one's.  Grids have the same shape if they have the same dimensions and
+// their blocks are in the same places, which means that the cells filled in for
+// one puzzle can be used for the other.
+func (p *Puzzle) HasSameShape(other *Puzzle) bool {
+	if p == nil || other == nil {
+		return false
+	}
+
+	return p.Rows == other.Rows &&
+		p.Cols == other.Cols &&
+		reflect.DeepEqual(p.CellBlocks, other.CellBlocks)
+}
+
+// IsSamePuzzle returns whether or not another puzzle is the same puzzle as this
+// one.  Puzzles are the same if they're from the same publisher on the same
+// date and have the same solution.
+func (p *Puzzle) IsSamePuzzle(other *Puzzle) bool {
+	if p == nil || other == nil {
+		return false
+	}
+
+	return p.Publisher == other.Publisher &&
+		p.PublishedDate.Equal(other.PublishedDate) &&
+		p.Title == other.Title &&
+		reflect.DeepEqual(p.Cells, other.Cells)
+}
+
+// IsCellGiven returns whether or not the cell at the provided coordinates is a
+// given.
+func (p *Puzzle) IsCellGiven(x, y int) bool {
+	return p.CellGivens != nil && p.CellGivens[y][x]
+}
+
+// IsCellCircled returns whether or not the cell at the provided coordinates is
+// circled.
+func (p *Puzzle) IsCellCircled(x, y int) bool {
+	return p.CellCircles != nil && p.CellCircles[y][x]
+}
+
+// IsCellShaded returns whether or not the cell at the provided coordinates is
+// shaded.
+func (p *Puzzle) IsCellShaded(x, y int) bool {
+	return p.CellShades != nil && p.CellShades[y][x]
+}
+
+// CellKind is a classification of a cell of the grid by what it can hold.
+type CellKind string
+
+const (
+	// CellKindLetter is a cell that holds a letter (or several for a rebus).
+	CellKindLetter CellKind = "letter"
+
+	// CellKindBlock is a cell that isn't filled in.
+	CellKindBlock CellKind = "block"
+
+	// CellKindVoid is a cell that may hold a number but never holds a letter.
+	// It separates answers the same way as a block.
+	CellKindVoid CellKind = "void"
+)
+
+// CellKind returns the classification of the cell at the provided coordinates.
+func (p *Puzzle) CellKind(x, y int) CellKind {
+	switch {
+	case p.CellVoids != nil && p.CellVoids[y][x]:
+		return CellKindVoid
+	case p.CellBlocks[y][x]:
+		return CellKindBlock
+	default:
+		return CellKindLetter
+	}
+}
+
+// UncheckedCells returns whether or not each cell of the crossword is unchecked
+// as a 2D list.  An unchecked cell belongs to the answer of only one clue (or of
+// no clue at all) so its value isn't confirmed by a crossing answer.  Variety
+// grids often have unchecked cells, but blocks are never unchecked.  Like cells
+// the 2D list is first indexed by the row coordinate of the cell and then by
+// the column coordinate.
+func (p *Puzzle) UncheckedCells() [][]bool {
+	counts := p.cellClueCounts()
+
+	unchecked := make([][]bool, p.Rows)
+	for y := 0; y < p.Rows; y++ {
+		unchecked[y] = make([]bool, p.Cols)
+		for x := 0; x < p.Cols; x++ {
+			unchecked[y][x] = !p.CellBlocks[y][x] && counts[y][x] < 2
+		}
+	}
+
+	return unchecked
+}
+
+// cellClueCounts returns the number of clues whose answers include each cell of
+// the crossword as a 2D list indexed by row and then column.
+func (p *Puzzle) cellClueCounts() [][]int {
+	counts := make([][]int, p.Rows)
+	for y := 0; y < p.Rows; y++ {
+		counts[y] = make([]int, p.Cols)
+	}
+
+	count := func(clues map[int]string, direction string) {
+		for num := range clues {
+			minX, minY, maxX, maxY, err := p.GetAnswerCoordinates(num, direction)
+			if err != nil {
+				continue
+			}
+
+			for y := minY; y <= maxY; y++ {
+				for x := minX; x <= maxX; x++ {
+					counts[y][x]++
+				}
+			}
+		}
+	}
+	count(p.CluesAcross, "a")
+	count(p.CluesDown, "d")
+
+	return counts
+}
+
+// CluesContaining returns the across and down clues (e.g. "1a" and "1d") whose
+// answers include the cell at the provided coordinates.  If no clue in a
+// direction includes the cell, for example because the cell is a block, then
+// the empty string is returned for that direction.
+func (p *Puzzle) CluesContaining(x, y int) (string, string) {
+	if p.CellBlocks[y][x] {
+		return "", ""
+	}
+
+	find := func(x, y, dx, dy int, clues map[int]string, direction string) string {
+		// Walk backwards to the cell that the answer starts in.
+		for x-dx >= 0 && x-dx < p.Cols && y-dy >= 0 && !p.CellBlocks[y-dy][x-dx] {
+			x, y = x-dx, y-dy
+		}
+
+		num := p.CellClueNumbers[y][x]
+		if _, ok := clues[num]; !ok {
+			return ""
+		}
+
+		return fmt.Sprintf("%d%s", num, direction)
+	}
+
+	dx := 1
+	if p.readsRightToLeft("a") {
+		dx = -1
+	}
+
+	return find(x, y, dx, 0, p.CluesAcross, "a"), find(x, y, 0, 1, p.CluesDown, "d")
+}
+
+// ClueStartingAt returns the clue (e.g. "17a") whose answer starts in the cell
+// with the provided number and goes in the provided direction ("a" or "d").  If
+// no cell has the number or no clue in that direction starts there then an
+// error is returned.
+func (p *Puzzle) ClueStartingAt(num int, direction string) (string, error) {
+	var found bool
+	for y := 0; y < p.Rows && !found; y++ {
+		for x := 0; x < p.Cols && !found; x++ {
+			found = p.CellClueNumbers[y][x] == num
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("no cell numbered %d", num)
+	}
+
+	clues := p.CluesAcross
+	if direction == "d" {
+		clues = p.CluesDown
+	}
+
+	if _, ok := clues[num]; !ok || (direction != "a" && direction != "d") {
+		return "", fmt.Errorf("no clue %d%s starts at cell %d", num, direction, num)
+	}
+
+	return fmt.Sprintf("%d%s", num, direction), nil
+}
+
+// CheckClue verifies that the puzzle has a clue with the provided identifier
+// (e.g. "17a").  If the identifier can't be parsed or the puzzle has no such
+// clue then an error wrapping ErrUnknownClue is returned.
+func (p *Puzzle) CheckClue(clue string) error {
+	num, direction, err := ParseClue(clue)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUnknownClue, clue)
+	}
+
+	clues := p.CluesAcross
+	if direction == "d" {
+		clues = p.CluesDown
+	}
+
+	if _, ok := clues[num]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownClue, clue)
+	}
+
+	return nil
+}
+
+// Answer returns the correct answer to a clue, read from the solution in the
+// puzzle's cells.  Cells containing more than one letter (rebus cells) have
+// all of their letters included in the answer.  If the puzzle doesn't have a
+// solution then ErrNoSolution is returned.
+func (p *Puzzle) Answer(num int, direction string) (string, error) {
+	if !p.HasSolution() {
+		return "", ErrNoSolution
+	}
+
+	minX, minY, maxX, maxY, err := p.GetAnswerCoordinates(num, direction)
+	if err != nil {
+		return "", err
+	}
+
+	var cells []string
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			cells = append(cells, p.Cells[y][x])
+		}
+	}
+
+	if p.readsRightToLeft(direction) {
+		reverse(cells)
+	}
+
+	return strings.Join(cells, ""), nil
+}
+
+// FindClueByText returns the identifier (e.g. "1a") of the clue whose text
+// contains the provided text.  Matching ignores case and surrounding
+// whitespace.  If no clue matches then an error is returned, and if more than
+// one clue matches then ErrAmbiguousClueText is returned.
+func (p *Puzzle) FindClueByText(text string) (string, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return "", errors.New("empty clue text")
+	}
+
+	var matches []string
+	for num, clue := range p.CluesAcross {
+		if strings.Contains(strings.ToLower(clue), text) {
+			matches = append(matches, fmt.Sprintf("%da", num))
+		}
+	}
+	for num, clue := range p.CluesDown {
+		if strings.Contains(strings.ToLower(clue), text) {
+			matches = append(matches, fmt.Sprintf("%dd", num))
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no clue contains the text %s", text)
+	case 1:
+		return matches[0], nil
+	default:
+		sort.Strings(matches)
+		return "", fmt.Errorf("%w: %s matches clues %s", ErrAmbiguousClueText, text, strings.Join(matches, ", "))
+	}
+}
+
+// ClueMatchThreshold is the minimum confidence that a clue must be matched with
+// by MatchClueText for it to be considered a match.
+var ClueMatchThreshold = 0.75
+
+// MatchClueText returns the identifier (e.g. "1a") of the clue whose text best
+// matches the provided text along with how confident the match is, between 0
+// and 1.  This is intended for text that came from speech recognition, so the
+// text doesn't need to match a clue exactly.  A clue is scored by the fewest
+// edits needed to turn the text into any part of the clue's text, ignoring
+// case and punctuation.  If no clue scores at least ClueMatchThreshold then the
+// empty string is returned along with the best confidence that was found.
+// Ties are broken in favor of across clues and then lower numbers.
+func (p *Puzzle) MatchClueText(text string) (string, float64) {
+	needle := []rune(normalizeClueText(text))
+	if len(needle) == 0 {
+		return "", 0
+	}
+
+	var best string
+	var confidence float64
+	match := func(clues map[int]string, direction string) {
+		nums := make([]int, 0, len(clues))
+		for num := range clues {
+			nums = append(nums, num)
+		}
+		sort.Ints(nums)
+
+		for _, num := range nums {
+			haystack := []rune(normalizeClueText(clues[num]))
+			score := 1 - float64(substringEditDistance(needle, haystack))/float64(len(needle))
+			if score > confidence {
+				best = fmt.Sprintf("%d%s", num, direction)
+				confidence = score
+			}
+		}
+	}
+	match(p.CluesAcross, "a")
+	match(p.CluesDown, "d")
+
+	if confidence < ClueMatchThreshold {
+		return "", confidence
+	}
+
+	return best, confidence
+}
+
+// normalizeClueText lowercases text and removes everything from it except for
+// letters, digits and single spaces between words.
+func normalizeClueText(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return strings.Join(words, " ")
+}
+
+// substringEditDistance returns the minimum number of single character
+// insertions, deletions and substitutions needed to turn the needle into any
+// contiguous part of the haystack.
+func substringEditDistance(needle, haystack []rune) int {
+	// Since the match can start anywhere in the haystack the first row is all
+	// zeros, and since it can end anywhere the answer is the smallest value in
+	// the last row.
+	prev := make([]int, len(haystack)+1)
+	curr := make([]int, len(haystack)+1)
+	for i := 1; i <= len(needle); i++ {
+		curr[0] = i
+		for j := 1; j <= len(haystack); j++ {
+			cost := 1
+			if needle[i-1] == haystack[j-1] {
+				cost = 0
+			}
+
+			curr[j] = prev[j-1] + cost
+			if prev[j]+1 < curr[j] {
+				curr[j] = prev[j] + 1
+			}
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+		}
+		prev, curr = curr, prev
+	}
+
+	distance := len(needle)
+	for _, d := range prev {
+		if d < distance {
+			distance = d
+		}
+	}
+
+	return distance
+}
+
+// readsRightToLeft returns whether or not answers in the provided direction
+// are written into the grid from right to left.
+func (p *Puzzle) readsRightToLeft(direction string) bool {
+	return p.RightToLeft && direction == "a"
+}
+
+// reverse reverses the order of a list of cell values in place.
+func reverse(cells []string) {
+	for i, j := 0, len(cells)-1; i < j; i, j = i+1, j-1 {
+		cells[i], cells[j] = cells[j], cells[i]
+	}
+}
+
 // GetAnswerCoordinates returns the min/max x/y coordinates for a clue.  If the
 // clue doesn't exist then an error is returned.
 func (p *Puzzle) GetAnswerCoordinates(num int, direction string) (int, int, int, int, error) {
@@ -122,35 +671,41 @@ func (p *Puzzle) GetAnswerCoordinates(num int, direction string) (int, int, int,
 	}
 
 	// Find the x, y coordinate where the answer begins.
-	var minX, minY int
+	var startX, startY int
 	for y := 0; y < p.Rows; y++ {
 		for x := 0; x < p.Cols; x++ {
 			if p.CellClueNumbers[y][x] == num {
-				minX = x
-				minY = y
+				startX = x
+				startY = y
 			}
 		}
 	}
 
 	// Determine the direction to step.
 	var dx, dy int
-	if direction == "a" {
+	switch {
+	case p.readsRightToLeft(direction):
+		dx = -1
+	case direction == "a":
 		dx = 1
-	} else {
+	default:
 		dy = 1
 	}
 
 	// Now that we know the starting cell, let's traverse in the correct direction
 	// until we run into a black cell or the edge of the puzzle.
-	var maxX, maxY int
-	for x, y := minX, minY; x < p.Cols && y < p.Rows; x, y = x+dx, y+dy {
+	var endX, endY int
+	for x, y := startX, startY; x >= 0 && x < p.Cols && y < p.Rows; x, y = x+dx, y+dy {
 		if p.CellBlocks[y][x] {
 			break
 		}
 
-		maxX = x
-		maxY = y
+		endX = x
+		endY = y
 	}
 
-	return minX, minY, maxX, maxY, nil
+	if dx < 0 {
+		return endX, startY, startX, endY, nil
+	}
+	return startX, startY, endX, endY, nil
 }
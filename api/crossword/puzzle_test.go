@@ -2,6 +2,7 @@ package crossword
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/stretchr/testify/require"
 	"io"
 	"testing"
@@ -347,6 +348,90 @@ func TestPuzzle_ConvertedJSON(t *testing.T) {
 	}
 }
 
+func TestPuzzle_Validate(t *testing.T) {
+	filenames := []string{
+		"atlantic-20240311.json",
+		"nyt-mini-20240105.json",
+		"puzzle-nyt-20080914-rebus.json",
+		"xwordinfo-nyt-20180621-nonsquare.json",
+		"xwordinfo-nyt-20181216-shades.json",
+		"xwordinfo-nyt-20181231.json",
+		"puz/nyt-20081006-nonsquare-givens.puz",
+		"puz/puzpy-nyt-20080224-diagramless.puz",
+	}
+
+	for _, filename := range filenames {
+		t.Run(filename, func(t *testing.T) {
+			puzzle := LoadTestPuzzle(t, filename)
+			assert.NoError(t, puzzle.Validate())
+		})
+	}
+}
+
+func TestPuzzle_Validate_Error(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Puzzle)
+	}{
+		{
+			name:   "no rows",
+			modify: func(p *Puzzle) { p.Rows = 0 },
+		},
+		{
+			name:   "wrong number of rows of cells",
+			modify: func(p *Puzzle) { p.Cells = p.Cells[1:] },
+		},
+		{
+			name:   "wrong number of columns of blocks",
+			modify: func(p *Puzzle) { p.CellBlocks[3] = p.CellBlocks[3][1:] },
+		},
+		{
+			name:   "wrong number of rows of clue numbers",
+			modify: func(p *Puzzle) { p.CellClueNumbers = nil },
+		},
+		{
+			name:   "wrong number of rows of circles",
+			modify: func(p *Puzzle) { p.CellCircles = p.CellCircles[1:] },
+		},
+		{
+			name:   "missing solution",
+			modify: func(p *Puzzle) { p.Cells[0][0] = "" },
+		},
+		{
+			name:   "solution in block",
+			modify: func(p *Puzzle) { p.Cells[0][5] = "A" },
+		},
+		{
+			name:   "across clue without a cell",
+			modify: func(p *Puzzle) { p.CluesAcross[99] = "Clue" },
+		},
+		{
+			name:   "down clue without a cell",
+			modify: func(p *Puzzle) { p.CluesDown[99] = "Clue" },
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			puzzle := LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json")
+			test.modify(puzzle)
+			assert.Error(t, puzzle.Validate())
+		})
+	}
+}
+
+func TestAddClue(t *testing.T) {
+	clues := make(map[int]string)
+	require.NoError(t, AddClue(clues, "across", 1, "First clue"))
+	require.NoError(t, AddClue(clues, "across", 2, "Second clue"))
+	assert.Equal(t, map[int]string{1: "First clue", 2: "Second clue"}, clues)
+
+	err := AddClue(clues, "Across", 1, "Repeated clue")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate across clue number 1")
+	assert.Equal(t, "First clue", clues[1])
+}
+
 func TestPuzzle_WithoutSolution(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -377,6 +462,315 @@ func TestPuzzle_WithoutSolution(t *testing.T) {
 	}
 }
 
+func TestClassifyVariant(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     int
+		cols     int
+		expected Variant
+	}{
+		{name: "5x5", rows: 5, cols: 5, expected: VariantMini},
+		{name: "8x8", rows: 8, cols: 8, expected: VariantMini},
+		{name: "15x15", rows: 15, cols: 15, expected: VariantDaily},
+		{name: "16x15", rows: 16, cols: 15, expected: VariantDaily},
+		{name: "21x21", rows: 21, cols: 21, expected: VariantSunday},
+		{name: "23x23", rows: 23, cols: 23, expected: VariantSunday},
+		{name: "9x24", rows: 9, cols: 24, expected: VariantOversized},
+		{name: "25x25", rows: 25, cols: 25, expected: VariantOversized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, ClassifyVariant(test.rows, test.cols))
+		})
+	}
+}
+
+func TestPuzzle_IsSamePuzzle(t *testing.T) {
+	puzzle := LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json")
+
+	assert.True(t, puzzle.IsSamePuzzle(LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json")))
+	assert.False(t, puzzle.IsSamePuzzle(LoadTestPuzzle(t, "xwordinfo-nyt-20181227-rebus.json")))
+	assert.False(t, puzzle.IsSamePuzzle(nil))
+
+	// A puzzle with a different solution isn't the same puzzle.
+	other := LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json")
+	other.Cells[0][0] = "X"
+	assert.False(t, puzzle.IsSamePuzzle(other))
+}
+
+func TestPuzzle_HasSameShape(t *testing.T) {
+	puzzle := LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json")
+
+	// A puzzle with different clues still has the same shape.
+	other := LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json")
+	other.CluesAcross[1] = "Changed"
+	assert.True(t, puzzle.HasSameShape(other))
+
+	other.CellBlocks[0][0] = true
+	assert.False(t, puzzle.HasSameShape(other))
+
+	assert.False(t, puzzle.HasSameShape(LoadTestPuzzle(t, "xwordinfo-nyt-20180621-nonsquare.json")))
+	assert.False(t, puzzle.HasSameShape(nil))
+}
+
+func TestPuzzle_ClueStartingAt(t *testing.T) {
+	puzzle := LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json")
+
+	tests := []struct {
+		num       int
+		direction string
+		expected  string
+	}{
+		{num: 1, direction: "a", expected: "1a"},
+		{num: 1, direction: "d", expected: "1d"},
+		{num: 2, direction: "d", expected: "2d"},
+		{num: 14, direction: "a", expected: "14a"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			clue, err := puzzle.ClueStartingAt(test.num, test.direction)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, clue)
+		})
+	}
+
+	// Cells that don't start a clue in the direction or don't exist are errors.
+	_, err := puzzle.ClueStartingAt(2, "a")
+	assert.Error(t, err)
+	_, err = puzzle.ClueStartingAt(14, "d")
+	assert.Error(t, err)
+	_, err = puzzle.ClueStartingAt(0, "a")
+	assert.Error(t, err)
+	_, err = puzzle.ClueStartingAt(1, "x")
+	assert.Error(t, err)
+}
+
+func TestPuzzle_CheckClue(t *testing.T) {
+	puzzle := LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json")
+
+	assert.NoError(t, puzzle.CheckClue("1a"))
+	assert.NoError(t, puzzle.CheckClue("2d"))
+	assert.NoError(t, puzzle.CheckClue("65A"))
+
+	for _, clue := range []string{"2a", "14d", "999a", "999d", "1x", "a", ""} {
+		t.Run(clue, func(t *testing.T) {
+			err := puzzle.CheckClue(clue)
+			assert.True(t, errors.Is(err, ErrUnknownClue))
+		})
+	}
+}
+
+func TestPuzzle_CluesContaining(t *testing.T) {
+	tests := []struct {
+		name           string
+		filename       string
+		x, y           int
+		expectedAcross string
+		expectedDown   string
+	}{
+		{name: "numbered cell", filename: "xwordinfo-nyt-20181231.json", x: 0, y: 0, expectedAcross: "1a", expectedDown: "1d"},
+		{name: "unnumbered cell", filename: "xwordinfo-nyt-20181231.json", x: 2, y: 1, expectedAcross: "14a", expectedDown: "3d"},
+		{name: "block", filename: "xwordinfo-nyt-20181231.json", x: 5, y: 0},
+		{name: "last cell", filename: "xwordinfo-nyt-20181231.json", x: 14, y: 14, expectedAcross: "65a", expectedDown: "57d"},
+		{name: "right to left", filename: "puzzle-rtl.json", x: 0, y: 0, expectedAcross: "1a", expectedDown: "3d"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			puzzle := LoadTestPuzzle(t, test.filename)
+
+			across, down := puzzle.CluesContaining(test.x, test.y)
+			assert.Equal(t, test.expectedAcross, across)
+			assert.Equal(t, test.expectedDown, down)
+		})
+	}
+}
+
+func TestPuzzle_Answer(t *testing.T) {
+	puzzle := LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json")
+
+	tests := []struct {
+		num       int
+		direction string
+		expected  string
+	}{
+		{num: 1, direction: "a", expected: "QANDA"},
+		{num: 6, direction: "a", expected: "ATTIC"},
+		{num: 1, direction: "d", expected: "QTIP"},
+		{num: 2, direction: "d", expected: "AHMED"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			answer, err := puzzle.Answer(test.num, test.direction)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, answer)
+		})
+	}
+
+	// Clues that don't exist are errors.
+	_, err := puzzle.Answer(2, "a")
+	assert.Error(t, err)
+	_, err = puzzle.Answer(999, "d")
+	assert.Error(t, err)
+
+	// Across answers of a right-to-left puzzle are read starting from their
+	// rightmost cell.
+	puzzle = LoadTestPuzzle(t, "puzzle-rtl.json")
+	answer, err := puzzle.Answer(1, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "CAT", answer)
+	answer, err = puzzle.Answer(1, "d")
+	require.NoError(t, err)
+	assert.Equal(t, "CAE", answer)
+}
+
+func TestPuzzle_UncheckedCells(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		expected [][]bool
+	}{
+		{
+			name:     "no unchecked cells",
+			filename: "xwordinfo-nyt-20181231.json",
+		},
+		{
+			name:     "unchecked cells",
+			filename: "xwordinfo-cryptic-20240107.json",
+			expected: [][]bool{
+				{false, true, false, true, false},
+				{true, false, true, false, true},
+				{false, true, false, true, false},
+				{true, false, true, false, true},
+				{false, true, false, true, false},
+			},
+		},
+		{
+			name:     "cell without a clue",
+			filename: "xwordinfo-variety-20240114-unchecked.json",
+			expected: [][]bool{
+				{false, true, true, false, true},
+				{true, false, false, false, true},
+				{true, false, true, false, true},
+				{true, false, false, false, true},
+				{false, true, true, true, false},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			puzzle := LoadTestPuzzle(t, test.filename)
+			unchecked := puzzle.UncheckedCells()
+			require.Equal(t, puzzle.Rows, len(unchecked))
+
+			if test.expected == nil {
+				for _, row := range unchecked {
+					assert.NotContains(t, row, true)
+				}
+				return
+			}
+
+			assert.Equal(t, test.expected, unchecked)
+		})
+	}
+}
+
+func TestPuzzle_FindClueByText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{name: "across clue", text: "under the roof", expected: "6a"},
+		{name: "down clue", text: "swabs", expected: "1d"},
+		{name: "ignores case", text: "PROLONGED DRY", expected: "4d"},
+		{name: "ignores surrounding whitespace", text: "  lecture  ", expected: "1a"},
+	}
+
+	puzzle := LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json")
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clue, err := puzzle.FindClueByText(test.text)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, clue)
+		})
+	}
+}
+
+func TestPuzzle_FindClueByText_Error(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		ambiguous bool
+	}{
+		{name: "no match", text: "zebra"},
+		{name: "empty text", text: " "},
+		{name: "ambiguous match", text: "room", ambiguous: true},
+	}
+
+	puzzle := LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json")
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := puzzle.FindClueByText(test.text)
+			require.Error(t, err)
+			assert.Equal(t, test.ambiguous, errors.Is(err, ErrAmbiguousClueText))
+		})
+	}
+}
+
+func TestPuzzle_MatchClueText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		exact    bool
+	}{
+		{name: "exact clue", text: "Room just under the roof", expected: "6a", exact: true},
+		{name: "part of a clue", text: "under the roof", expected: "6a", exact: true},
+		{name: "ignores case and punctuation", text: "DON'T LEAVE THIS SPOT!", expected: "39a", exact: true},
+		{name: "misheard word", text: "country between equator and bolivia", expected: "19a"},
+		{name: "misheard words", text: "plant eating dyno with spikes on its bag", expected: "61a"},
+		{name: "down clue", text: "prolong dry spell", expected: "4d"},
+	}
+
+	puzzle := LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json")
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clue, confidence := puzzle.MatchClueText(test.text)
+			assert.Equal(t, test.expected, clue)
+			if test.exact {
+				assert.Equal(t, 1.0, confidence)
+			} else {
+				assert.True(t, confidence >= ClueMatchThreshold && confidence < 1, "confidence: %f", confidence)
+			}
+		})
+	}
+}
+
+func TestPuzzle_MatchClueText_NoMatch(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{name: "too dissimilar", text: "purple elephants dancing"},
+		{name: "empty text", text: " "},
+		{name: "only punctuation", text: "?!"},
+	}
+
+	puzzle := LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json")
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clue, confidence := puzzle.MatchClueText(test.text)
+			assert.Equal(t, "", clue)
+			assert.True(t, confidence < ClueMatchThreshold, "confidence: %f", confidence)
+		})
+	}
+}
+
 func TestPuzzle_GetAnswerCoordinates(t *testing.T) {
 	tests := []struct {
 		name                       string
@@ -465,6 +859,34 @@ func TestPuzzle_GetAnswerCoordinates(t *testing.T) {
 	}
 }
 
+func TestPuzzle_GetAnswerCoordinates_RightToLeft(t *testing.T) {
+	puzzle := LoadTestPuzzle(t, "puzzle-rtl.json")
+
+	tests := []struct {
+		clue                       string
+		expectedMinX, expectedMinY int
+		expectedMaxX, expectedMaxY int
+	}{
+		{clue: "1a", expectedMinX: 0, expectedMinY: 0, expectedMaxX: 2, expectedMaxY: 0},
+		{clue: "5a", expectedMinX: 1, expectedMinY: 2, expectedMaxX: 2, expectedMaxY: 2},
+		{clue: "3d", expectedMinX: 0, expectedMinY: 0, expectedMaxX: 0, expectedMaxY: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.clue, func(t *testing.T) {
+			num, direction, err := ParseClue(test.clue)
+			require.NoError(t, err)
+
+			minX, minY, maxX, maxY, err := puzzle.GetAnswerCoordinates(num, direction)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedMinX, minX)
+			assert.Equal(t, test.expectedMinY, minY)
+			assert.Equal(t, test.expectedMaxX, maxX)
+			assert.Equal(t, test.expectedMaxY, maxY)
+		})
+	}
+}
+
 func TestPuzzle_GetAnswerCoordinates_Error(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -0,0 +1,71 @@
+package crossword
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxQueuedAnswers is the maximum number of answers that can be queued while a
+// solve is paused.
+var MaxQueuedAnswers = 50
+
+// ErrAnswerQueueFull is returned when an answer is queued but the queue already
+// holds the maximum number of answers.
+var ErrAnswerQueueFull = errors.New("answer queue is full")
+
+// QueuedAnswer is an answer that was submitted while the solve was paused.  It
+// is applied to the puzzle once the solve is resumed.
+type QueuedAnswer struct {
+	// The clue (e.g. "1a") that the answer was submitted for.
+	Clue string `json:"clue"`
+
+	// The answer exactly as it was submitted.
+	Answer string `json:"answer"`
+
+	// The name of the user that submitted the answer.
+	User string `json:"user,omitempty"`
+
+	// The Twitch id of the user that submitted the answer.
+	UserID string `json:"user_id,omitempty"`
+
+	// The number of bits that the user cheered in the message with the answer.
+	Bits int `json:"bits,omitempty"`
+}
+
+// Metadata returns the metadata of the chat message that the answer was
+// submitted in.
+func (q QueuedAnswer) Metadata() AnswerMetadata {
+	return AnswerMetadata{
+		User:   q.User,
+		UserID: q.UserID,
+		Bits:   q.Bits,
+	}
+}
+
+// QueueAnswer adds an answer for a clue to the end of the state's queue of
+// answers.  If the queue already holds MaxQueuedAnswers answers then
+// ErrAnswerQueueFull is returned.
+func (s *State) QueueAnswer(clue string, answer string, metadata AnswerMetadata) error {
+	if len(s.QueuedAnswers) >= MaxQueuedAnswers {
+		return fmt.Errorf("%w: %d answers queued", ErrAnswerQueueFull, len(s.QueuedAnswers))
+	}
+
+	s.QueuedAnswers = append(s.QueuedAnswers, QueuedAnswer{
+		Clue:   clue,
+		Answer: answer,
+		User:   metadata.User,
+		UserID: metadata.UserID,
+		Bits:   metadata.Bits,
+	})
+
+	return nil
+}
+
+// TakeQueuedAnswers removes all of the answers from the state's queue and
+// returns them in the order they were queued.
+func (s *State) TakeQueuedAnswers() []QueuedAnswer {
+	answers := s.QueuedAnswers
+	s.QueuedAnswers = nil
+
+	return answers
+}
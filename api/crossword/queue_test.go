@@ -0,0 +1,41 @@
+package crossword
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestState_QueueAnswer(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+
+	require.NoError(t, state.QueueAnswer("1a", "QANDA", AnswerMetadata{User: "alice", UserID: "123", Bits: 100}))
+	require.NoError(t, state.QueueAnswer("6a", "ATTIC", AnswerMetadata{}))
+
+	expected := []QueuedAnswer{
+		{Clue: "1a", Answer: "QANDA", User: "alice", UserID: "123", Bits: 100},
+		{Clue: "6a", Answer: "ATTIC"},
+	}
+	assert.Equal(t, expected, state.QueuedAnswers)
+	assert.Equal(t, AnswerMetadata{User: "alice", UserID: "123", Bits: 100}, state.QueuedAnswers[0].Metadata())
+
+	// Taking the answers empties the queue.
+	assert.Equal(t, expected, state.TakeQueuedAnswers())
+	assert.Empty(t, state.QueuedAnswers)
+	assert.Empty(t, state.TakeQueuedAnswers())
+}
+
+func TestState_QueueAnswer_Full(t *testing.T) {
+	max := MaxQueuedAnswers
+	MaxQueuedAnswers = 2
+	t.Cleanup(func() { MaxQueuedAnswers = max })
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, state.QueueAnswer("1a", "QANDA", AnswerMetadata{}))
+	require.NoError(t, state.QueueAnswer("6a", "ATTIC", AnswerMetadata{}))
+
+	err := state.QueueAnswer("11a", "HON", AnswerMetadata{})
+	require.True(t, errors.Is(err, ErrAnswerQueueFull))
+	assert.Len(t, state.QueuedAnswers, 2)
+}
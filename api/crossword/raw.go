@@ -0,0 +1,64 @@
+package crossword
+
+import (
+	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/db"
+	"time"
+)
+
+// RawSource is the raw contents that a channel's puzzle was parsed from.  It's
+// kept alongside the parsed puzzle so that the puzzle can be parsed again once
+// a parser has been improved, without the channel having to select it again.
+type RawSource struct {
+	// The name of the source that parsed the contents (e.g. "puz_file_bytes").
+	Source string `json:"source"`
+
+	// The raw contents exactly as they were provided when the puzzle was
+	// selected.
+	Value string `json:"value"`
+}
+
+// RawSourceTTL determines how long the raw source of a channel's puzzle should
+// remain in redis after the puzzle was selected.
+var RawSourceTTL = 7 * 24 * time.Hour
+
+// RawSourceKey returns the key that should be used in redis to store the raw
+// source of a particular channel's puzzle.
+func RawSourceKey(name string) string {
+	return fmt.Sprintf("%s:crossword:raw_source", name)
+}
+
+// GetRawSource loads the raw source of the channel's puzzle from redis.  If no
+// raw source was stored for the puzzle then nil is returned.
+func GetRawSource(conn db.Connection, channel string) (*RawSource, error) {
+	var raw *RawSource
+	if err := db.Get(conn, RawSourceKey(channel), &raw); err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+// SetRawSource writes the raw source of the channel's puzzle to redis.  If the
+// raw source is nil then any previously stored raw source is removed so that it
+// can't be mistaken for the source of a different puzzle.
+func SetRawSource(conn db.Connection, channel string, raw *RawSource) error {
+	if raw == nil {
+		_, err := conn.Do("DEL", RawSourceKey(channel))
+		return err
+	}
+
+	return db.SetWithTTL(conn, RawSourceKey(channel), raw, RawSourceTTL)
+}
+
+// FindSource returns the source with the provided name.  If there is no source
+// with the name then false is returned.
+func FindSource(name string) (Source, bool) {
+	for _, source := range Sources {
+		if source.Name == name {
+			return source, true
+		}
+	}
+
+	return Source{}, false
+}
@@ -0,0 +1,91 @@
+package crossword
+
+import (
+	"encoding/json"
+	"github.com/bbeck/puzzles-with-chat/api/db"
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"github.com/go-chi/render"
+	"github.com/gomodule/redigo/redis"
+	"net/http"
+	"time"
+)
+
+// StatsMaxAge is the maximum age of the solve records that are retained for a
+// channel.  Older records are discarded the next time a solve is recorded.  An
+// age of zero retains records regardless of their age.
+var StatsMaxAge time.Duration
+
+// AuditLogMaxAge is the maximum age of the entries that are retained in a
+// channel's audit log.  Older entries are discarded the next time an entry is
+// recorded.  An age of zero retains entries regardless of their age.
+var AuditLogMaxAge time.Duration
+
+// Retention describes how much of a kind of channel data is kept.  It can be
+// marshalled to JSON so that operators can see the effective limits.
+type Retention struct {
+	// The maximum number of entries that are retained.
+	MaxEntries int `json:"max_entries"`
+
+	// The maximum age of the entries that are retained, zero if entries are
+	// retained regardless of their age.
+	MaxAge model.Duration `json:"max_age"`
+}
+
+// GetRetention returns the effective limits on how much of each kind of
+// channel data is retained, indexed by the kind of data.
+func GetRetention() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, map[string]Retention{
+			"stats": {
+				MaxEntries: StatsMaxEntries,
+				MaxAge:     model.Duration{Duration: StatsMaxAge},
+			},
+			"audit": {
+				MaxEntries: AuditLogMaxEntries,
+				MaxAge:     model.Duration{Duration: AuditLogMaxAge},
+			},
+		})
+	}
+}
+
+// TrimList trims a list whose newest entries are at the front so that it has
+// no more than max entries and no entries older than maxAge as of now.  Each
+// entry of the list must be a JSON object with a time field that records when
+// the entry was added.
+func TrimList(conn db.Connection, key string, max int, maxAge time.Duration, now time.Time) error {
+	if _, err := conn.Do("LTRIM", key, 0, max-1); err != nil {
+		return err
+	}
+
+	if maxAge <= 0 {
+		return nil
+	}
+
+	// Entries are ordered from newest to oldest, so the expired entries are all
+	// at the end of the list.
+	cutoff := now.Add(-maxAge)
+	for {
+		bs, err := redis.Bytes(conn.Do("LINDEX", key, -1))
+		if err == redis.ErrNil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var entry struct {
+			Time time.Time `json:"time"`
+		}
+		if err := json.Unmarshal(bs, &entry); err != nil {
+			return err
+		}
+
+		if !entry.Time.Before(cutoff) {
+			return nil
+		}
+
+		if _, err := conn.Do("RPOP", key); err != nil {
+			return err
+		}
+	}
+}
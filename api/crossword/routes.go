@@ -2,35 +2,93 @@ package crossword
 
 import (
 	"compress/flate"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/admin"
 	"github.com/bbeck/puzzles-with-chat/api/model"
 	"github.com/bbeck/puzzles-with-chat/api/pubsub"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/render"
 	"github.com/gomodule/redigo/redis"
+	"io"
 	"log"
 	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 func RegisterRoutes(r chi.Router, pool *redis.Pool, registry *pubsub.Registry) {
 	r.Route("/crossword/{channel}", func(r chi.Router) {
 		r.Put("/", UpdatePuzzle(pool, registry))
+		r.Get("/settings", ReadSettings(pool))
+		r.Get("/streak", ReadStreak(pool))
+		r.Get("/summary", GetSummary(pool))
 		r.Put("/setting/{setting}", UpdateSetting(pool, registry))
 		r.Put("/status", ToggleStatus(pool, registry))
+		r.Put("/abandon", AbandonPuzzle(pool, registry))
+		r.Put("/lock", LockCells(pool, registry))
+		r.Put("/unlock", UnlockCells(pool, registry))
 		r.Put("/answer/{clue}", UpdateAnswer(pool, registry))
+		r.Put("/number/{number}/{direction}", UpdateAnswerByNumber(pool, registry))
+		r.Put("/answer-by-clue", UpdateAnswerByClueText(pool, registry))
+		r.Put("/promote/{clue}", PromotePencilAnswer(pool, registry))
+		r.Post("/propose/{clue}", ProposeAnswer(pool, registry))
+		r.Put("/vote/{clue}", VoteOnProposal(pool, registry))
 		r.Get("/show/{clue}", ShowClue(registry))
+		r.Put("/message", UpdateBanner(pool, registry))
+		r.Delete("/message", ClearBanner(pool, registry))
+		r.Put("/focus/{clue}", UpdateFocusedClue(pool, registry))
+		r.Get("/clue/next", FocusNextClue(pool, registry))
+		r.Get("/clue/prev", FocusPreviousClue(pool, registry))
+		r.Get("/clue/match", MatchClue(pool))
+		r.Get("/clue/{clue}/age", GetClueAge(pool))
+		r.Get("/peek/{row}/{col}", PeekCell(pool, registry))
+		r.Get("/progress", GetProgress(pool))
+		r.Get("/leaderboard", GetLeaderboard(pool))
+		r.Get("/clues", GetClues(pool))
+		r.Get("/answer-key", GetAnswerKey(pool))
+		r.Get("/text", GetText(pool))
+		r.Get("/numbering", GetNumbering(pool))
+		r.Get("/cell-info/{row}/{col}", GetCellInfo(pool))
 		r.Get("/events", GetEvents(pool, registry))
+		r.Get("/resync", Resync(pool, registry))
+		r.Get("/poll", PollEvents(registry))
+		r.With(admin.Required).Get("/snapshot", GetSnapshot(pool))
+		r.With(admin.Required).Put("/snapshot", UpdateSnapshot(pool, registry))
+		r.With(admin.Required).Get("/audit", GetAuditLog(pool))
+		r.With(admin.Required).Get("/debug", GetDebug(pool))
+		r.With(admin.Required).Get("/completion-webhook", ReadCompletionWebhook(pool))
+		r.With(admin.Required).Put("/completion-webhook", UpdateCompletionWebhook(pool))
+		r.With(admin.Required).Post("/reparse", ReparsePuzzle(pool, registry))
 	})
 
 	// When possible compress the dates response since it's so large.
 	compressor := middleware.NewCompressor(flate.BestCompression, "application/json")
 	r.With(compressor.Handler()).Get("/crossword/dates", GetAvailableDates())
+	r.With(admin.Required).Post("/crossword/cache", WarmCache())
+	r.Post("/crossword/preview", PreviewPuzzle())
+	r.Get("/crossword/compare", CompareChannels(pool))
+	r.Get("/crossword/capabilities", GetCapabilities())
+	r.Get("/crossword/retention", GetRetention())
+	r.Get("/crossword/sources/{source}/weekdays", GetWeekdayHistogram())
 }
 
 // UpdatePuzzle changes the crossword puzzle that's currently being solved for a
-// channel.
+// channel.  If the selected puzzle is the same one that the channel is already
+// solving then its progress is left alone unless the force query parameter is
+// true, this keeps an accidental re-selection from wiping out a solve.  When the
+// practice query parameter is true the solve is a practice solve that doesn't
+// count towards the channel's stats.  Switching the same puzzle into or out of
+// practice is a different selection, so the solve starts over.
 func UpdatePuzzle(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		channel := chi.URLParam(r, "channel")
@@ -44,79 +102,79 @@ func UpdatePuzzle(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 			return
 		}
 
-		var puzzle *Puzzle
+		puzzle, source, ok := loadPuzzleFromPayload(w, payload)
+		if !ok {
+			return
+		}
 
-		// New York Times date
-		if date := payload["new_york_times_date"]; date != "" {
-			p, err := LoadFromNewYorkTimes(date)
-			if err != nil {
-				log.Printf("unable to load NYT puzzle for date %s: %+v", date, err)
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
+		// Only puzzles selected by date can be found again by their source.
+		var name string
+		if source.Input == SourceInputDate {
+			name = source.Name
+		}
 
-			puzzle = p
+		// Uploaded files and pasted text are the puzzle's raw contents, so they can
+		// be parsed again later.  Puzzles from every other source, for example
+		// those selected by date, have nothing to keep since they can always be
+		// loaded from their source again.
+		var raw *RawSource
+		if source.Input == SourceInputFile || source.Input == SourceInputText {
+			raw = &RawSource{Source: source.Name, Value: payload[source.Key]}
 		}
 
-		// Wall Street Journal date
-		if date := payload["wall_street_journal_date"]; date != "" {
-			p, err := LoadFromWallStreetJournal(date)
-			if err != nil {
-				log.Printf("unable to load WSJ puzzle for date %s: %+v", date, err)
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
 
-			puzzle = p
-		}
+		practice, _ := strconv.ParseBool(r.URL.Query().Get("practice"))
 
-		if url := payload["puz_file_url"]; url != "" {
-			p, err := LoadFromPuzFileURL(url)
+		force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+		if !force {
+			existing, err := GetState(conn, channel)
 			if err != nil {
-				log.Printf("unable to load puzzle from url %s: %+v", url, err)
+				log.Printf("unable to load state for channel %s: %+v", channel, err)
 				w.WriteHeader(http.StatusInternalServerError)
 				return
 			}
 
-			puzzle = p
-		}
-
-		// .puz file upload
-		if encoded := payload["puz_file_bytes"]; encoded != "" {
-			p, err := LoadFromEncodedPuzFile(encoded)
-			if err != nil {
-				log.Printf("unable to load puzzle from bytes: %+v", err)
-				w.WriteHeader(http.StatusInternalServerError)
+			if existing.Puzzle.IsSamePuzzle(puzzle) && existing.Practice == practice {
+				log.Printf("puzzle already selected for channel %s, keeping progress", channel)
+				w.WriteHeader(http.StatusOK)
 				return
 			}
-
-			puzzle = p
 		}
 
-		if puzzle == nil {
-			log.Printf("unable to determine puzzle from payload: %+v", payload)
-			w.WriteHeader(http.StatusBadRequest)
+		// Save the puzzle to this channel's state
+		var state State
+		state.resetEphemeralState(puzzle)
+		state.Source = name
+		state.Practice = practice
+		if err := SetState(conn, channel, state); err != nil {
+			log.Printf("unable to save state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
-		conn := pool.Get()
-		defer func() { _ = conn.Close() }()
-
-		// Save the puzzle to this channel's state
-		cells := make([][]string, puzzle.Rows)
-		for row := 0; row < puzzle.Rows; row++ {
-			cells[row] = make([]string, puzzle.Cols)
+		// Keep the raw source of the puzzle when the channel wants it, otherwise
+		// make sure the raw source of a previous puzzle doesn't linger.
+		settings, err := GetSettings(conn, channel)
+		if err != nil {
+			log.Printf("unable to load settings for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
 		}
 
-		state := State{
-			Status:            model.StatusSelected,
-			Puzzle:            puzzle,
-			Cells:             cells,
-			AcrossCluesFilled: make(map[int]bool),
-			DownCluesFilled:   make(map[int]bool),
+		if !settings.StoreRawSource {
+			raw = nil
 		}
-		if err := SetState(conn, channel, state); err != nil {
-			log.Printf("unable to save state for channel %s: %+v", channel, err)
+		if err := SetRawSource(conn, channel, raw); err != nil {
+			log.Printf("unable to save raw source for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		// Proposals for the previous puzzle don't apply to this one.
+		if err := SetProposals(conn, channel, nil); err != nil {
+			log.Printf("unable to remove proposals for channel %s: %+v", channel, err)
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
@@ -133,6 +191,87 @@ func UpdatePuzzle(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 	}
 }
 
+// PreviewPuzzle loads a crossword puzzle using any of the ways that a puzzle
+// can be selected for a channel and returns the state that a channel would have
+// if it selected the puzzle.  Nothing is stored and no events are sent, so the
+// puzzle can be looked over before committing a channel to it.  The solution
+// of the puzzle isn't included in the response.
+func PreviewPuzzle() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		if err := render.DecodeJSON(r.Body, &payload); err != nil {
+			log.Printf("unable to read request body: %+v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		puzzle, _, ok := loadPuzzleFromPayload(w, payload)
+		if !ok {
+			return
+		}
+
+		var state State
+		state.resetEphemeralState(puzzle)
+		state.Puzzle = state.Puzzle.WithoutSolution()
+
+		render.JSON(w, r, state)
+	}
+}
+
+// loadPuzzleFromPayload loads the puzzle described by a payload that maps the
+// key of a source to the value that the source should load the puzzle from.
+// The puzzle is returned along with the source that loaded it.  If the puzzle
+// can't be loaded then an appropriate response is written and false is
+// returned.
+func loadPuzzleFromPayload(w http.ResponseWriter, payload map[string]string) (*Puzzle, Source, bool) {
+	var puzzle *Puzzle
+	var selected Source
+	for _, source := range EnabledSources() {
+		value := payload[source.Key]
+		if value == "" {
+			continue
+		}
+
+		// Uploaded files and pasted text are too large to be worth logging.
+		if source.Input == SourceInputFile || source.Input == SourceInputText {
+			value = "upload"
+		}
+
+		p, err := source.Load(payload[source.Key])
+		if errors.Is(err, ErrNoPuzzleOnDate) {
+			log.Printf("unable to load %s puzzle from %s: %+v", source.Label, value, err)
+			http.Error(w, fmt.Sprintf("no %s puzzle on that date", source.Label), http.StatusNotFound)
+			return nil, Source{}, false
+		}
+		if errors.Is(err, ErrInvalidArchiveID) {
+			log.Printf("invalid %s id %s: %+v", source.Label, value, err)
+			w.WriteHeader(http.StatusBadRequest)
+			return nil, Source{}, false
+		}
+		if errors.Is(err, ErrUnknownArchiveID) {
+			log.Printf("unknown %s id %s: %+v", source.Label, value, err)
+			w.WriteHeader(http.StatusNotFound)
+			return nil, Source{}, false
+		}
+		if err != nil {
+			log.Printf("unable to load %s puzzle from %s: %+v", source.Label, value, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return nil, Source{}, false
+		}
+
+		puzzle = p
+		selected = source
+	}
+
+	if puzzle == nil {
+		log.Printf("unable to determine puzzle from payload: %+v", payload)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, Source{}, false
+	}
+
+	return puzzle, selected, true
+}
+
 // UpdateSetting changes a specified crossword setting to a new value.
 func UpdateSetting(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -151,6 +290,15 @@ func UpdateSetting(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 			return
 		}
 
+		// Remember the value of the setting before the update so that clients can
+		// be told what it changed from.
+		old, err := settings.Value(setting)
+		if err != nil {
+			log.Printf("unable to determine crossword setting %s for channel %s: %+v", setting, channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
 		// Apply the update to the settings in memory.
 		var shouldClearIncorrectCells bool
 		switch setting {
@@ -168,7 +316,12 @@ func UpdateSetting(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 			var value ClueVisibility
 			if err := render.DecodeJSON(r.Body, &value); err != nil {
 				log.Printf("unable to parse crossword clue visibility setting json %s: %+v", value, err)
-				w.WriteHeader(http.StatusBadRequest)
+
+				var allowed []string
+				for _, v := range ClueVisibilities {
+					allowed = append(allowed, v.String())
+				}
+				model.InvalidSettingValue(w, r, setting, allowed)
 				return
 			}
 			settings.CluesToShow = value
@@ -177,7 +330,12 @@ func UpdateSetting(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 			var value model.FontSize
 			if err := render.DecodeJSON(r.Body, &value); err != nil {
 				log.Printf("unable to parse crossword clue font size setting json %s: %+v", value, err)
-				w.WriteHeader(http.StatusBadRequest)
+
+				var allowed []string
+				for _, v := range model.FontSizes {
+					allowed = append(allowed, v.String())
+				}
+				model.InvalidSettingValue(w, r, setting, allowed)
 				return
 			}
 			settings.ClueFontSize = value
@@ -191,6 +349,128 @@ func UpdateSetting(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 			}
 			settings.ShowNotes = value
 
+		case "complete_threshold":
+			var value int
+			if err := render.DecodeJSON(r.Body, &value); err != nil {
+				log.Printf("unable to parse crossword complete threshold setting json %v: %+v", value, err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if value < 0 || value > 100 {
+				log.Printf("invalid crossword complete threshold setting %d", value)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			settings.CompleteThreshold = value
+
+		case "min_toggle_interval_ms":
+			var value int
+			if err := render.DecodeJSON(r.Body, &value); err != nil {
+				log.Printf("unable to parse crossword min toggle interval setting json %v: %+v", value, err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if value < 0 {
+				log.Printf("invalid crossword min toggle interval setting %d", value)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			settings.MinToggleIntervalMillis = value
+
+		case "auto_apply_proposal_score":
+			var value int
+			if err := render.DecodeJSON(r.Body, &value); err != nil {
+				log.Printf("unable to parse crossword auto apply proposal score setting json %v: %+v", value, err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if value < 0 {
+				log.Printf("invalid crossword auto apply proposal score setting %d", value)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			settings.AutoApplyProposalScore = value
+
+		case "show_correct_count":
+			var value bool
+			if err := render.DecodeJSON(r.Body, &value); err != nil {
+				log.Printf("unable to parse crossword show correct count setting json %v: %+v", value, err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			settings.ShowCorrectCount = value
+
+		case "answer_aliases":
+			var value map[string]string
+			if err := render.DecodeJSON(r.Body, &value); err != nil {
+				log.Printf("unable to parse crossword answer aliases setting json %v: %+v", value, err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			aliases, err := model.NormalizeAnswerAliases(value)
+			if err != nil {
+				log.Printf("invalid crossword answer aliases setting: %+v", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			settings.AnswerAliases = aliases
+
+		case "emote_letters":
+			var value map[string]string
+			if err := render.DecodeJSON(r.Body, &value); err != nil {
+				log.Printf("unable to parse crossword emote letters setting json %v: %+v", value, err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			emotes, err := model.NormalizeEmoteLetters(value)
+			if err != nil {
+				log.Printf("invalid crossword emote letters setting: %+v", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			settings.EmoteLetters = emotes
+
+		case "audit_answers":
+			var value bool
+			if err := render.DecodeJSON(r.Body, &value); err != nil {
+				log.Printf("unable to parse crossword audit answers setting json %v: %+v", value, err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			settings.AuditAnswers = value
+
+		case "auto_advance_on_complete":
+			var value bool
+			if err := render.DecodeJSON(r.Body, &value); err != nil {
+				log.Printf("unable to parse crossword auto advance on complete setting json %v: %+v", value, err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			settings.AutoAdvanceOnComplete = value
+
+		case "store_raw_source":
+			var value bool
+			if err := render.DecodeJSON(r.Body, &value); err != nil {
+				log.Printf("unable to parse crossword store raw source setting json %v: %+v", value, err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			settings.StoreRawSource = value
+
+		case "paused_answer_behavior":
+			var value PausedAnswerBehavior
+			if err := render.DecodeJSON(r.Body, &value); err != nil {
+				log.Printf("unable to parse crossword paused answer behavior setting json %s: %+v", value, err)
+
+				var allowed []string
+				for _, v := range PausedAnswerBehaviors {
+					allowed = append(allowed, v.String())
+				}
+				model.InvalidSettingValue(w, r, setting, allowed)
+				return
+			}
+			settings.PausedAnswerBehavior = value
+
 		default:
 			log.Printf("unrecognized crossword setting name %s", setting)
 			w.WriteHeader(http.StatusBadRequest)
@@ -219,7 +499,7 @@ func UpdateSetting(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 			// There's no need to update cells if the puzzle hasn't been selected or
 			// started or is already complete.
 			status := state.Status
-			if status != model.StatusCreated && status != model.StatusSelected && status != model.StatusComplete {
+			if status != model.StatusCreated && status != model.StatusSelected && status != model.StatusComplete && status != model.StatusReview && status != model.StatusAbandoned {
 				if err := state.ClearIncorrectCells(); err != nil {
 					log.Printf("unable to clear incorrect cells for channel: %s: %+v", channel, err)
 					w.WriteHeader(http.StatusInternalServerError)
@@ -239,6 +519,14 @@ func UpdateSetting(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 		// Now broadcast the new settings to all of the clients in the channel.
 		registry.Publish(ChannelID(channel), SettingsEvent(settings))
 
+		// Along with which setting changed, for clients that want to describe the
+		// change.
+		if value, err := settings.Value(setting); err != nil {
+			log.Printf("unable to determine crossword setting %s for channel %s: %+v", setting, channel, err)
+		} else if !reflect.DeepEqual(old, value) {
+			registry.Publish(ChannelID(channel), SettingChangedEvent(setting, old, value))
+		}
+
 		if updatedState != nil {
 			// Broadcast the updated state to all of the clients, making sure to not
 			// include the answers.
@@ -251,93 +539,132 @@ func UpdateSetting(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 	}
 }
 
-// ToggleStatus changes the status of the current crossword solve to a new
-// status.  This effectively toggles between the solving and paused statuses as
-// long as the solve is in a state that can be paused or resumed.
-func ToggleStatus(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+// ReadSettings returns the crossword settings of a channel.  Channels that have
+// never changed a setting receive the default settings.
+func ReadSettings(pool *redis.Pool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		channel := chi.URLParam(r, "channel")
 
 		conn := pool.Get()
 		defer func() { _ = conn.Close() }()
 
-		state, err := GetState(conn, channel)
+		settings, err := GetSettings(conn, channel)
 		if err != nil {
-			log.Printf("unable to load state for channel %s: %+v", channel, err)
-			w.WriteHeader(http.StatusNotFound)
+			log.Printf("unable to load settings for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
-		now := time.Now()
+		render.JSON(w, r, settings)
+	}
+}
 
-		switch state.Status {
-		case model.StatusCreated:
-			log.Printf("unable to toggle status for channel %s, no puzzle selected", channel)
-			w.WriteHeader(http.StatusBadRequest)
-			return
+// ReadCompletionWebhook returns the URL that's called when the channel
+// completes a crossword.  The URL is private to the channel so this is only
+// available to administrators.
+func ReadCompletionWebhook(pool *redis.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
 
-		case model.StatusSelected:
-			state.Status = model.StatusSolving
-			state.LastStartTime = &now
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
 
-		case model.StatusPaused:
-			state.Status = model.StatusSolving
-			state.LastStartTime = &now
+		webhook, err := GetCompletionWebhook(conn, channel)
+		if err != nil {
+			log.Printf("unable to load completion webhook for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
 
-		case model.StatusSolving:
-			state.Status = model.StatusPaused
-			total := state.TotalSolveDuration.Nanoseconds() + now.Sub(*state.LastStartTime).Nanoseconds()
-			state.LastStartTime = nil
-			state.TotalSolveDuration = model.Duration{Duration: time.Duration(total)}
+		render.JSON(w, r, webhook)
+	}
+}
 
-		case model.StatusComplete:
-			log.Printf("unable to toggle status for channel %s, puzzle is already solved", channel)
+// UpdateCompletionWebhook changes the URL that's called when the channel
+// completes a crossword.  An empty URL removes the webhook.  The URL is never
+// broadcast along with the channel's settings.
+func UpdateCompletionWebhook(pool *redis.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		var webhook string
+		if err := render.DecodeJSON(r.Body, &webhook); err != nil {
+			log.Printf("unable to parse crossword completion webhook json %v: %+v", webhook, err)
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
+		if webhook != "" {
+			if err := ValidateWebhookURL(webhook); err != nil {
+				log.Printf("invalid crossword completion webhook %s: %+v", webhook, err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
 
-		if err := SetState(conn, channel, state); err != nil {
-			log.Printf("unable to save state for channel %s: %+v", channel, err)
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		if err := SetCompletionWebhook(conn, channel, webhook); err != nil {
+			log.Printf("unable to save completion webhook for channel %s: %+v", channel, err)
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
-		// Broadcast to all of the clients that the puzzle status has been changed,
-		// making sure to not include the answers.  It's okay to overwrite the
-		// puzzle attribute because we just wrote this state instance to the
-		// database and will be discarding it immediately publishing.
-		state.Puzzle = state.Puzzle.WithoutSolution()
-
-		registry.Publish(ChannelID(channel), StateEvent(state))
-
 		w.WriteHeader(http.StatusOK)
 	}
 }
 
-// UpdateAnswer applies an answer to a given clue in the current crossword
-// solve.
-func UpdateAnswer(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+// ReadStreak returns the number of consecutive days that a channel has
+// completed a crossword on.  Channels that have never completed a crossword, or
+// that have missed a day since they last did, have a streak of zero.
+func ReadStreak(pool *redis.Pool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		channel := chi.URLParam(r, "channel")
-		clue := chi.URLParam(r, "clue")
 
-		if r.ContentLength > 1024 {
-			w.WriteHeader(http.StatusRequestEntityTooLarge)
-			return
-		}
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
 
-		var answer string
-		if err := render.DecodeJSON(r.Body, &answer); err != nil {
-			log.Printf("unable to read request body: %+v", err)
-			w.WriteHeader(http.StatusBadRequest)
+		streak, err := GetStreak(conn, channel)
+		if err != nil {
+			log.Printf("unable to load solve streak for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
-		if len(answer) == 0 {
-			w.WriteHeader(http.StatusBadRequest)
+		render.JSON(w, r, streak.Current(time.Now()))
+	}
+}
+
+// GetSummary returns an aggregation of the crosswords that a channel has
+// completed, such as the average solve duration and how many of the solved
+// puzzles were published on each day of the week.
+func GetSummary(pool *redis.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		records, err := GetSolveRecords(conn, channel, StatsMaxEntries)
+		if err != nil {
+			log.Printf("unable to load solve records for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
+		render.JSON(w, r, Summarize(records))
+	}
+}
+
+// ToggleStatus changes the status of the current crossword solve to a new
+// status.  This effectively toggles between the solving and paused statuses as
+// long as the solve is in a state that can be paused or resumed.  A toggle that
+// comes within the channel's minimum toggle interval of the previous one is
+// ignored and leaves the solve unchanged.
+func ToggleStatus(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
 		conn := pool.Get()
 		defer func() { _ = conn.Close() }()
 
@@ -348,8 +675,311 @@ func UpdateAnswer(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 			return
 		}
 
-		if state.Status != model.StatusSolving {
-			w.WriteHeader(http.StatusConflict)
+		// A channel that has never selected a puzzle doesn't have a solve whose
+		// status can be toggled.
+		if state.Puzzle == nil || state.Status == model.StatusCreated {
+			log.Printf("unable to toggle status for channel %s, no puzzle selected", channel)
+			http.Error(w, "no puzzle selected", http.StatusConflict)
+			return
+		}
+
+		settings, err := GetSettings(conn, channel)
+		if err != nil {
+			log.Printf("unable to load settings for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		now := time.Now()
+
+		// A toggle that comes too soon after the previous one is most likely an
+		// accidental double click, ignore it so that the first toggle stands.
+		toggleable := state.Status == model.StatusSolving || state.Status == model.StatusPaused
+		interval := time.Duration(settings.MinToggleIntervalMillis) * time.Millisecond
+		if toggleable && interval > 0 && state.LastToggleTime != nil && now.Sub(*state.LastToggleTime) < interval {
+			log.Printf("ignoring status toggle for channel %s, previous toggle was too recent", channel)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// Practice solves don't track how long they take, so their timer is never
+		// started.
+		var start *time.Time
+		if !state.Practice {
+			start = &now
+		}
+
+		switch state.Status {
+		case model.StatusSelected:
+			state.Status = model.StatusSolving
+			state.LastStartTime = start
+
+		case model.StatusPaused:
+			state.Status = model.StatusSolving
+			state.LastStartTime = start
+
+		case model.StatusSolving:
+			state.Status = model.StatusPaused
+			if state.LastStartTime != nil {
+				total := state.TotalSolveDuration.Nanoseconds() + now.Sub(*state.LastStartTime).Nanoseconds()
+				state.TotalSolveDuration = model.Duration{Duration: time.Duration(total)}
+			}
+			state.LastStartTime = nil
+
+		case model.StatusComplete:
+			log.Printf("unable to toggle status for channel %s, puzzle is already solved", channel)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+
+		case model.StatusReview:
+			log.Printf("unable to toggle status for channel %s, puzzle is being reviewed", channel)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+
+		case model.StatusAbandoned:
+			log.Printf("unable to toggle status for channel %s, puzzle was abandoned", channel)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		state.LastToggleTime = &now
+
+		// Answers that were queued while the solve was paused are applied now that
+		// it's been resumed.
+		if state.Status == model.StatusSolving && len(state.QueuedAnswers) > 0 {
+			applyQueuedAnswers(conn, channel, &state, settings)
+		}
+
+		if err := SetState(conn, channel, state); err != nil {
+			log.Printf("unable to save state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		// Broadcast to all of the clients that the puzzle status has been changed,
+		// making sure to not include the answers.  It's okay to overwrite the
+		// puzzle attribute because we just wrote this state instance to the
+		// database and will be discarding it immediately publishing.
+		state.Puzzle = state.Puzzle.WithoutSolution()
+
+		registry.Publish(ChannelID(channel), StateEvent(state))
+
+		// The queued answers may have finished the solve.
+		if state.Status == model.StatusComplete {
+			recordSolve(conn, registry, channel, settings, state)
+			registry.Publish(ChannelID(channel), CompleteEvent(state))
+
+			if state.AdvanceTime != nil {
+				scheduleAdvance(conn, pool, registry, channel, *state.AdvanceTime, state.Puzzle)
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// AbandonPuzzle gives up on the current crossword solve.  The solve's timer is
+// stopped, it's recorded in the channel's stats as abandoned along with the
+// time that was spent on it, and the channel is no longer considered active.
+func AbandonPuzzle(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if state.Puzzle == nil || state.Status == model.StatusCreated {
+			log.Printf("unable to abandon puzzle for channel %s, no puzzle selected", channel)
+			http.Error(w, "no puzzle selected", http.StatusConflict)
+			return
+		}
+
+		// Only a solve that hasn't ended yet can be abandoned.
+		switch state.Status {
+		case model.StatusComplete, model.StatusReview, model.StatusAbandoned:
+			log.Printf("unable to abandon puzzle for channel %s, solve is %s", channel, state.Status)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if err := abandonSolve(conn, registry, channel, state, time.Now()); err != nil {
+			log.Printf("unable to abandon puzzle for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// LockCells locks cells of the current crossword solve so that answers can't
+// change their values.  This is intended to be used by moderators to protect
+// a correctly solved region of the puzzle.  The request body identifies the
+// cells to lock, either by a clue whose cells should all be locked, by a list
+// of individual cells, or both.
+func LockCells(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return updateLockedCells(pool, registry, true)
+}
+
+// UnlockCells unlocks cells of the current crossword solve so that answers can
+// change their values again.  The request body identifies the cells the same
+// way as it does for LockCells.
+func UnlockCells(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return updateLockedCells(pool, registry, false)
+}
+
+// updateLockedCells returns a handler that locks or unlocks the cells in the
+// request body.
+func updateLockedCells(pool *redis.Pool, registry *pubsub.Registry, locked bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		var payload struct {
+			Clue  string `json:"clue"`
+			Cells []Cell `json:"cells"`
+		}
+		if err := render.DecodeJSON(r.Body, &payload); err != nil {
+			log.Printf("unable to read request body: %+v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if state.Puzzle == nil {
+			log.Printf("unable to lock cells for channel %s, no puzzle selected", channel)
+			http.Error(w, "no puzzle selected", http.StatusConflict)
+			return
+		}
+
+		cells := payload.Cells
+		if payload.Clue != "" {
+			clueCells, err := state.ClueCells(payload.Clue)
+			if err != nil {
+				log.Printf("unable to determine cells of clue %s for channel %s: %+v", payload.Clue, channel, err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			cells = append(cells, clueCells...)
+		}
+
+		if len(cells) == 0 {
+			log.Printf("unable to lock cells for channel %s, no cells specified", channel)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if err := state.LockCells(cells, locked); err != nil {
+			log.Printf("unable to lock cells for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if err := SetState(conn, channel, state); err != nil {
+			log.Printf("unable to save state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		// Broadcast the updated state so that clients can render the locked cells,
+		// making sure to not include the answers.
+		state.Puzzle = state.Puzzle.WithoutSolution()
+
+		registry.Publish(ChannelID(channel), StateEvent(state))
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// UpdateAnswer applies an answer to a given clue in the current crossword
+// solve.
+func UpdateAnswer(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return updateAnswer(pool, registry, func(r *http.Request, puzzle *Puzzle) (string, error) {
+		clue := chi.URLParam(r, "clue")
+		return clue, puzzle.CheckClue(clue)
+	})
+}
+
+// UpdateAnswerByNumber applies an answer for the clue that starts at a numbered
+// cell of the crossword and goes in the requested direction (across or down).
+func UpdateAnswerByNumber(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return updateAnswer(pool, registry, func(r *http.Request, puzzle *Puzzle) (string, error) {
+		num, err := strconv.Atoi(chi.URLParam(r, "number"))
+		if err != nil {
+			return "", err
+		}
+
+		var direction string
+		switch strings.ToLower(chi.URLParam(r, "direction")) {
+		case "across", "a":
+			direction = "a"
+		case "down", "d":
+			direction = "d"
+		default:
+			return "", fmt.Errorf("invalid direction %s", chi.URLParam(r, "direction"))
+		}
+
+		return puzzle.ClueStartingAt(num, direction)
+	})
+}
+
+// UpdateAnswerByClueText applies an answer for the clue whose text contains the
+// text provided in the request's text query parameter.  The text must match
+// exactly one clue.
+func UpdateAnswerByClueText(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return updateAnswer(pool, registry, func(r *http.Request, puzzle *Puzzle) (string, error) {
+		return puzzle.FindClueByText(r.URL.Query().Get("text"))
+	})
+}
+
+// updateAnswer returns a handler that applies an answer to a clue of the
+// channel's crossword.  The clue is determined from the request and puzzle by
+// the provided function, and if it can't be determined a 404 is returned (or a
+// 409 if the request could refer to more than one clue).
+func updateAnswer(pool *redis.Pool, registry *pubsub.Registry, resolve func(*http.Request, *Puzzle) (string, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		if r.ContentLength > 1024 {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		var answer string
+		if err := render.DecodeJSON(r.Body, &answer); err != nil {
+			log.Printf("unable to read request body: %+v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if len(answer) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusNotFound)
 			return
 		}
 
@@ -360,62 +990,1622 @@ func UpdateAnswer(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 			return
 		}
 
-		if err := state.ApplyAnswer(clue, answer, settings.OnlyAllowCorrectAnswers); err != nil {
+		// Answers can only be submitted while solving, unless the channel queues
+		// up the answers that are submitted while paused.
+		queue := state.Status == model.StatusPaused && settings.PausedAnswerBehavior == QueuePausedAnswers
+		if state.Status != model.StatusSolving && !queue {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		clue, err := resolve(r, state.Puzzle)
+		if errors.Is(err, ErrAmbiguousClueText) {
+			log.Printf("unable to determine clue for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if errors.Is(err, ErrUnknownClue) {
+			log.Printf("unable to determine clue for channel %s: %+v", channel, err)
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("unable to determine clue for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		metadata, err := ParseAnswerMetadata(r)
+		if err != nil {
+			log.Printf("malformed answer metadata for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// Answers written entirely as letter emotes are translated into letters.
+		answer = model.TranslateEmoteLetters(answer, settings.EmoteLetters)
+
+		// Tentative answers are penciled in instead of being committed to the grid.
+		pencil, _ := strconv.ParseBool(r.URL.Query().Get("pencil"))
+
+		// Answers submitted while paused are held until the solve is resumed.
+		if queue {
+			if pencil {
+				log.Printf("unable to queue pencil answer %s for clue %s for channel %s", answer, clue, channel)
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+
+			if err := state.QueueAnswer(clue, answer, metadata); err != nil {
+				log.Printf("unable to queue answer %s for clue %s for channel %s: %+v", answer, clue, channel, err)
+				http.Error(w, ErrAnswerQueueFull.Error(), http.StatusConflict)
+				return
+			}
+
+			if err := SetState(conn, channel, state); err != nil {
+				log.Printf("unable to save state for channel %s: %+v", channel, err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		if pencil {
+			err = state.ApplyPencilAnswer(clue, answer)
+		} else {
+			err = applyAnswer(&state, settings, clue, answer, metadata)
+
+			// Record the attempt whether or not it could be applied.
+			if settings.AuditAnswers {
+				auditAnswer(conn, channel, clue, answer, metadata, err == nil && state.IsClueCorrect(clue))
+			}
+		}
+		if err != nil {
 			log.Printf("unable to apply answer %s for clue %s for channel %s: %+v", answer, clue, channel, err)
+
+			// When the answer doesn't fit the clue let the user know what went wrong.
+			var lengthErr *AnswerLengthError
+			if errors.As(err, &lengthErr) {
+				http.Error(w, lengthErr.Error(), http.StatusBadRequest)
+				return
+			}
+
+			// Incorrect answers can optionally be told how close they were.
+			var incorrectErr *IncorrectAnswerError
+			if errors.As(err, &incorrectErr) && settings.ShowCorrectCount {
+				message := fmt.Sprintf("%d of %d letters correct", incorrectErr.Correct, incorrectErr.Total)
+				http.Error(w, message, http.StatusBadRequest)
+				return
+			}
+
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
 
-		// If we just solved the puzzle then we should stop the timer.
-		if state.Status == model.StatusComplete {
-			now := time.Now()
-			total := state.TotalSolveDuration.Nanoseconds() + now.Sub(*state.LastStartTime).Nanoseconds()
-			state.LastStartTime = nil
-			state.TotalSolveDuration = model.Duration{Duration: time.Duration(total)}
+		// Save the updated state.
+		if err := SetState(conn, channel, state); err != nil {
+			log.Printf("unable to save state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		// Broadcast to all of the clients that the puzzle has been selected, making
+		// sure to not include the answers.  It's okay to overwrite the puzzle
+		// attribute because we just wrote this state instance to the database
+		// and will be discarding it immediately publishing.
+		state.Puzzle = state.Puzzle.WithoutSolution()
+
+		registry.Publish(ChannelID(channel), StateEvent(state))
+
+		// If we've just finished the solve then send a complete event as well.
+		if state.Status == model.StatusComplete {
+			recordSolve(conn, registry, channel, settings, state)
+			registry.Publish(ChannelID(channel), CompleteEvent(state))
+
+			if state.AdvanceTime != nil {
+				scheduleAdvance(conn, pool, registry, channel, *state.AdvanceTime, state.Puzzle)
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// PromotePencilAnswer commits the penciled in cells of a clue to the crossword
+// as if they had been submitted as the clue's answer.
+func PromotePencilAnswer(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+		clue := chi.URLParam(r, "clue")
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if state.Status != model.StatusSolving {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		settings, err := GetSettings(conn, channel)
+		if err != nil {
+			log.Printf("unable to load settings for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		answer, err := state.PencilAnswer(clue)
+		if err != nil {
+			log.Printf("unable to determine pencil answer for clue %s for channel %s: %+v", clue, channel, err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		metadata, err := ParseAnswerMetadata(r)
+		if err != nil {
+			log.Printf("malformed answer metadata for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if err := applyAnswer(&state, settings, clue, answer, metadata); err != nil {
+			log.Printf("unable to apply answer %s for clue %s for channel %s: %+v", answer, clue, channel, err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// Save the updated state.
+		if err := SetState(conn, channel, state); err != nil {
+			log.Printf("unable to save state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		// Broadcast to all of the clients the updated state, making sure to not
+		// include the answers.
+		state.Puzzle = state.Puzzle.WithoutSolution()
+
+		registry.Publish(ChannelID(channel), StateEvent(state))
+
+		if state.Status == model.StatusComplete {
+			recordSolve(conn, registry, channel, settings, state)
+			registry.Publish(ChannelID(channel), CompleteEvent(state))
+
+			if state.AdvanceTime != nil {
+				scheduleAdvance(conn, pool, registry, channel, *state.AdvanceTime, state.Puzzle)
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// AnswerMetadata describes the chat message that an answer was submitted in.
+// Everything is optional since answers don't always come from chat.
+type AnswerMetadata struct {
+	// The name of the user that submitted the answer.
+	User string
+
+	// The Twitch id of the user that submitted the answer.
+	UserID string
+
+	// The number of bits that the user cheered in the message with the answer.
+	Bits int
+}
+
+// UserRegexp matches the name of a user that an answer can be credited to.
+// Twitch display names are made up of letters (which may be localized), digits
+// and underscores.
+var UserRegexp = regexp.MustCompile(`^[\p{L}\p{N}_]{1,25}$`)
+
+// UserIDRegexp matches the Twitch id of a user.
+var UserIDRegexp = regexp.MustCompile(`^[0-9]{1,20}$`)
+
+// ParseAnswerMetadata reads the metadata of an answer from the user, user_id
+// and bits query parameters of a request.  Users are credited for the answers
+// that they submit, so anything that doesn't look like a Twitch user is
+// rejected.
+func ParseAnswerMetadata(r *http.Request) (AnswerMetadata, error) {
+	query := r.URL.Query()
+	metadata := AnswerMetadata{
+		User:   query.Get("user"),
+		UserID: query.Get("user_id"),
+	}
+
+	if metadata.User != "" && !UserRegexp.MatchString(metadata.User) {
+		return metadata, fmt.Errorf("invalid user %s", metadata.User)
+	}
+
+	if metadata.UserID != "" && !UserIDRegexp.MatchString(metadata.UserID) {
+		return metadata, fmt.Errorf("invalid user id %s", metadata.UserID)
+	}
+
+	if bits := query.Get("bits"); bits != "" {
+		n, err := strconv.Atoi(bits)
+		if err != nil || n < 0 {
+			return metadata, fmt.Errorf("invalid bits %s", bits)
+		}
+		metadata.Bits = n
+	}
+
+	return metadata, nil
+}
+
+// auditAnswer records an answer that was submitted for a clue in the channel's
+// audit log.  A failure to record it is logged, but shouldn't prevent the answer
+// from being used.
+func auditAnswer(conn redis.Conn, channel, clue, answer string, metadata AnswerMetadata, correct bool) {
+	entry := AuditEntry{
+		User:    metadata.User,
+		UserID:  metadata.UserID,
+		Bits:    metadata.Bits,
+		Clue:    clue,
+		Answer:  answer,
+		Correct: correct,
+		Time:    time.Now(),
+	}
+	if err := RecordAuditEntry(conn, channel, entry); err != nil {
+		log.Printf("unable to record audit entry for channel %s: %+v", channel, err)
+	}
+}
+
+// applyQueuedAnswers applies the answers that were queued while the solve was
+// paused in the order that they were submitted.  Answers that can't be applied
+// are skipped, and once the puzzle is complete any remaining answers are
+// discarded.
+func applyQueuedAnswers(conn redis.Conn, channel string, state *State, settings Settings) {
+	for _, queued := range state.TakeQueuedAnswers() {
+		if state.Status != model.StatusSolving {
+			break
+		}
+
+		err := applyAnswer(state, settings, queued.Clue, queued.Answer, queued.Metadata())
+		if err != nil {
+			log.Printf("unable to apply queued answer %s for clue %s for channel %s: %+v", queued.Answer, queued.Clue, channel, err)
+		}
+
+		if settings.AuditAnswers {
+			auditAnswer(conn, channel, queued.Clue, queued.Answer, queued.Metadata(), err == nil && state.IsClueCorrect(queued.Clue))
+		}
+	}
+}
+
+// applyAnswer applies an answer submitted by a user for a clue to the state
+// according to the channel's settings.  The user is credited with solving the
+// clue if they were the first to correctly answer it, any proposals for the
+// clue are discarded, and if the puzzle is now complete the timer is stopped.
+func applyAnswer(state *State, settings Settings, clue, answer string, metadata AnswerMetadata) error {
+	// Determine if the clue was correctly answered before this answer so that
+	// only the first user to correctly answer it is credited.
+	alreadyCorrect := state.IsClueCorrect(clue)
+
+	state.AnswerAliases = settings.AnswerAliases
+	if err := state.ApplyAnswer(clue, answer, settings.OnlyAllowCorrectAnswers); err != nil {
+		return err
+	}
+
+	if !alreadyCorrect && state.IsClueCorrect(clue) && !state.Practice {
+		if metadata.User != "" {
+			state.CreditSolver(clue, metadata.User)
+		}
+
+		if metadata.Bits > 0 {
+			state.CreditCheer(clue, metadata.Bits)
+		}
+	}
+
+	// Now that the clue has been answered there's no need to keep voting on
+	// proposed answers for it.
+	state.ClearProposals(clue)
+
+	// A completely filled in puzzle may be considered complete even with some
+	// incorrect cells depending on the channel's complete threshold.
+	if state.IsComplete(settings.CompleteThreshold) {
+		state.Status = model.StatusComplete
+	}
+
+	// If we just solved the puzzle then we should stop the timer.
+	if state.Status == model.StatusComplete && state.LastStartTime != nil {
+		now := time.Now()
+		total := state.TotalSolveDuration.Nanoseconds() + now.Sub(*state.LastStartTime).Nanoseconds()
+		state.LastStartTime = nil
+		state.TotalSolveDuration = model.Duration{Duration: time.Duration(total)}
+	}
+
+	// When the channel advances through a source's puzzles the previous puzzle
+	// is selected a little while after this one is completed.
+	if state.Status == model.StatusComplete && state.AdvanceTime == nil && settings.AutoAdvanceOnComplete && state.Source != "" {
+		advance := time.Now().Add(AutoAdvanceDelay)
+		state.AdvanceTime = &advance
+	}
+
+	return nil
+}
+
+// ProposeAnswer adds a proposed answer for a clue to the current crossword
+// solve so that chat can vote on it before it's applied.
+func ProposeAnswer(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+		clue := chi.URLParam(r, "clue")
+
+		if r.ContentLength > 1024 {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		var answer string
+		if err := render.DecodeJSON(r.Body, &answer); err != nil {
+			log.Printf("unable to read request body: %+v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if state.Status != model.StatusSolving {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		state.Proposals, err = GetProposals(conn, channel)
+		if err != nil {
+			log.Printf("unable to load proposals for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		state.PruneProposals(time.Now())
+
+		if err := state.Propose(clue, answer); err != nil {
+			log.Printf("unable to propose answer %s for clue %s for channel %s: %+v", answer, clue, channel, err)
+
+			var lengthErr *AnswerLengthError
+			if errors.Is(err, ErrTooManyProposals) {
+				w.WriteHeader(http.StatusConflict)
+			} else if errors.As(err, &lengthErr) {
+				http.Error(w, lengthErr.Error(), http.StatusBadRequest)
+			} else {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+			return
+		}
+
+		if err := SetProposals(conn, channel, state.Proposals); err != nil {
+			log.Printf("unable to save proposals for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		registry.Publish(ChannelID(channel), ProposalsEvent(state.Proposals))
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// VoteOnProposal records a user's vote for or against a proposed answer for a
+// clue.  If the channel has enabled automatically applying proposals and the
+// proposal's score reaches the configured score then the proposed answer is
+// applied to the puzzle.
+func VoteOnProposal(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+		clue := chi.URLParam(r, "clue")
+
+		if r.ContentLength > 1024 {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		var vote struct {
+			Answer string `json:"answer"`
+			Up     bool   `json:"up"`
+		}
+		if err := render.DecodeJSON(r.Body, &vote); err != nil {
+			log.Printf("unable to read request body: %+v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		user := r.URL.Query().Get("user")
+		if user == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if state.Status != model.StatusSolving {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		settings, err := GetSettings(conn, channel)
+		if err != nil {
+			log.Printf("unable to load settings for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		state.Proposals, err = GetProposals(conn, channel)
+		if err != nil {
+			log.Printf("unable to load proposals for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		state.PruneProposals(time.Now())
+
+		proposal, err := state.Vote(clue, vote.Answer, user, vote.Up)
+		if err != nil {
+			log.Printf("unable to vote on answer %s for clue %s for channel %s: %+v", vote.Answer, clue, channel, err)
+			if errors.Is(err, ErrUnknownProposal) {
+				w.WriteHeader(http.StatusNotFound)
+			} else {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+			return
+		}
+
+		// Apply the proposal if it has become popular enough.  If the answer can't
+		// be applied (for example because it's incorrect and only correct answers
+		// are allowed) then the proposal remains so that voting can continue.
+		var applied bool
+		if threshold := settings.AutoApplyProposalScore; threshold > 0 && proposal.Score >= threshold {
+			if err := applyAnswer(&state, settings, clue, proposal.Answer, AnswerMetadata{}); err != nil {
+				log.Printf("unable to apply proposed answer %s for clue %s for channel %s: %+v", proposal.Answer, clue, channel, err)
+			} else {
+				applied = true
+			}
+		}
+
+		if applied {
+			if err := SetState(conn, channel, state); err != nil {
+				log.Printf("unable to save state for channel %s: %+v", channel, err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if err := SetProposals(conn, channel, state.Proposals); err != nil {
+			log.Printf("unable to save proposals for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		registry.Publish(ChannelID(channel), ProposalsEvent(state.Proposals))
+
+		if applied {
+			state.Puzzle = state.Puzzle.WithoutSolution()
+			registry.Publish(ChannelID(channel), StateEvent(state))
+
+			if state.Status == model.StatusComplete {
+				recordSolve(conn, registry, channel, settings, state)
+				registry.Publish(ChannelID(channel), CompleteEvent(state))
+
+				if state.AdvanceTime != nil {
+					scheduleAdvance(conn, pool, registry, channel, *state.AdvanceTime, state.Puzzle)
+				}
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// recordSolve adds a solve that was just completed to the channel's stats and
+// to the recent completions across all channels, announces the completion,
+// advances its solve streak and calls the channel's completion webhook.
+// Practice solves aren't recorded and don't call the webhook.  The solve's
+// state has already been saved, so a failure to record it is only logged.
+func recordSolve(conn redis.Conn, registry *pubsub.Registry, channel string, settings Settings, state State) {
+	now := time.Now()
+	if state.Practice {
+		return
+	}
+
+	if err := RecordSolve(conn, channel, NewSolveRecord(state, now)); err != nil {
+		log.Printf("unable to record solve for channel %s: %+v", channel, err)
+	}
+
+	if _, err := UpdateStreak(conn, channel, now); err != nil {
+		log.Printf("unable to update solve streak for channel %s: %+v", channel, err)
+	}
+
+	completion := model.Completion{
+		Type:     "crossword",
+		Channel:  channel,
+		Duration: model.Duration{Duration: state.SolveDuration(now)},
+		Time:     now,
+	}
+	if state.Puzzle != nil {
+		completion.Title = state.Puzzle.Title
+		completion.Publisher = state.Puzzle.Publisher
+	}
+	if err := model.RecordCompletion(conn, completion); err != nil {
+		log.Printf("unable to record completion for channel %s: %+v", channel, err)
+	}
+	registry.Publish(model.CompletionsChannel, model.CompletionEvent(completion))
+
+	// The webhook is called in the background so that a slow or failing webhook
+	// doesn't affect the solve.
+	if url := settings.CompletionWebhook; url != "" {
+		payload := CompletionPayload{
+			Channel:  channel,
+			Duration: model.Duration{Duration: state.SolveDuration(now)},
+		}
+		if state.Puzzle != nil {
+			payload.Title = state.Puzzle.Title
+		}
+
+		go func() {
+			if err := SendCompletionWebhook(url, payload); err != nil {
+				log.Printf("unable to call completion webhook for channel %s: %+v", channel, err)
+			}
+		}()
+	}
+}
+
+// ShowClue sends an event to all clients of a channel requesting that they
+// update their view to make the specified clue visible.  If the specified clue
+// isn't structured as a proper clue number and direction than an error will be
+// returned.
+//
+// When a duration is provided the clue is automatically hidden again once it
+// elapses by sending a follow-up event.  Showing another clue in the channel
+// cancels any pending hide of a previously shown clue.
+func ShowClue(registry *pubsub.Registry) http.HandlerFunc {
+	// The timers that will hide each channel's shown clue, indexed by channel.
+	var lock sync.Mutex
+	timers := make(map[string]*time.Timer)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+		clue := chi.URLParam(r, "clue")
+
+		_, _, err := ParseClue(clue)
+		if err != nil {
+			log.Printf("malformed clue (%s): %+v", clue, err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var duration time.Duration
+		if s := r.URL.Query().Get("duration"); s != "" {
+			duration, err = time.ParseDuration(s)
+			if err != nil || duration <= 0 {
+				log.Printf("malformed duration (%s): %+v", s, err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		if timer := timers[channel]; timer != nil {
+			timer.Stop()
+			delete(timers, channel)
+		}
+
+		registry.Publish(ChannelID(channel), ShowClueEvent(clue))
+
+		if duration > 0 {
+			var timer *time.Timer
+			timer = time.AfterFunc(duration, func() {
+				lock.Lock()
+				defer lock.Unlock()
+
+				// The timer may have fired just as another clue was being shown, in
+				// which case this clue has already been replaced.
+				if timers[channel] != timer {
+					return
+				}
+				delete(timers, channel)
+
+				registry.Publish(ChannelID(channel), HideClueEvent(clue))
+			})
+			timers[channel] = timer
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// UpdateBanner pins a short message to a channel's solve overlay.  The message
+// is provided in the request body along with an optional ttl (e.g. "5m") after
+// which the banner is automatically cleared.  Setting a new banner replaces the
+// existing one and cancels its pending expiration.
+func UpdateBanner(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		var body struct {
+			Message string          `json:"message"`
+			TTL     *model.Duration `json:"ttl"`
+		}
+		if err := render.DecodeJSON(r.Body, &body); err != nil {
+			log.Printf("unable to parse banner json body: %+v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		message := strings.TrimSpace(body.Message)
+		if message == "" || utf8.RuneCountInString(message) > BannerMaxLength {
+			log.Printf("invalid banner message for channel %s: %q", channel, message)
+			http.Error(w, fmt.Sprintf("banner messages must be between 1 and %d characters", BannerMaxLength), http.StatusBadRequest)
+			return
+		}
+
+		var ttl time.Duration
+		if body.TTL != nil {
+			ttl = body.TTL.Duration
+			if ttl <= 0 || ttl > BannerMaxTTL {
+				log.Printf("invalid banner ttl for channel %s: %s", channel, ttl)
+				http.Error(w, fmt.Sprintf("banner ttls must be positive and at most %s", BannerMaxTTL), http.StatusBadRequest)
+				return
+			}
+		}
+
+		banner := Banner{Message: message}
+		if ttl > 0 {
+			expiration := time.Now().Add(ttl)
+			banner.ExpiresAt = &expiration
+		}
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		version := claimBanner(channel)
+		if err := SetBanner(conn, channel, banner); err != nil {
+			log.Printf("unable to save banner for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		finishBanner(registry, channel, version, &banner, ttl)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// ClearBanner removes the banner pinned to a channel's solve overlay, if there
+// is one, and cancels its pending expiration.
+func ClearBanner(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		version := claimBanner(channel)
+		if err := DeleteBanner(conn, channel); err != nil {
+			log.Printf("unable to delete banner for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		finishBanner(registry, channel, version, nil, 0)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// UpdateFocusedClue changes the clue that the channel is focused on.  The clue
+// is saved as part of the state so that clients which connect later start out
+// focused on the same clue as everyone else.
+func UpdateFocusedClue(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+		clue := chi.URLParam(r, "clue")
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if state.Puzzle == nil {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		if err := state.FocusClue(clue); err != nil {
+			log.Printf("unable to focus clue %s for channel %s: %+v", clue, channel, err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if err := SetState(conn, channel, state); err != nil {
+			log.Printf("unable to save state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		registry.Publish(ChannelID(channel), FocusEvent(state.FocusedClue))
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// FocusNextClue changes the clue that the channel is focused on to the next
+// clue after it that hasn't been filled in yet.  Once the across clues are
+// exhausted the down clues are searched, and after the down clues the search
+// wraps back around to the across clues.
+func FocusNextClue(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return focusAdjacentClue(pool, registry, true)
+}
+
+// FocusPreviousClue changes the clue that the channel is focused on to the
+// closest clue before it that hasn't been filled in yet.  It searches in the
+// opposite order of FocusNextClue.
+func FocusPreviousClue(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return focusAdjacentClue(pool, registry, false)
+}
+
+// focusAdjacentClue returns a handler that moves the channel's focus to an
+// adjacent unfilled clue in the provided direction.
+func focusAdjacentClue(pool *redis.Pool, registry *pubsub.Registry, forward bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if state.Puzzle == nil {
+			http.Error(w, "no puzzle selected", http.StatusConflict)
+			return
+		}
+
+		clue, err := state.AdjacentUnfilledClue(forward)
+		if errors.Is(err, ErrNoUnfilledClues) {
+			http.Error(w, "no unfilled clues", http.StatusConflict)
+			return
+		}
+		if err != nil {
+			log.Printf("unable to find adjacent clue for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if err := state.FocusClue(clue); err != nil {
+			log.Printf("unable to focus clue %s for channel %s: %+v", clue, channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if err := SetState(conn, channel, state); err != nil {
+			log.Printf("unable to save state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		registry.Publish(ChannelID(channel), FocusEvent(state.FocusedClue))
+		render.JSON(w, r, state.FocusedClue)
+	}
+}
+
+// PeekDuration is how long a peeked at cell remains visible before it's hidden
+// again.
+var PeekDuration = 5 * time.Second
+
+// PeekCell temporarily reveals the solution to a single cell of the crossword.
+// Clients are sent the solution and then told to hide it again after
+// PeekDuration.  The cell isn't filled in, but the reveal is counted in the
+// solve's state.
+func PeekCell(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		row, err := strconv.Atoi(chi.URLParam(r, "row"))
+		if err != nil {
+			log.Printf("malformed row (%s): %+v", chi.URLParam(r, "row"), err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		col, err := strconv.Atoi(chi.URLParam(r, "col"))
+		if err != nil {
+			log.Printf("malformed col (%s): %+v", chi.URLParam(r, "col"), err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		// There's nothing to peek at if the puzzle was imported without its
+		// solution.
+		if state.Status != model.StatusSolving || !state.Puzzle.HasSolution() {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		puzzle := state.Puzzle
+		if row < 0 || row >= puzzle.Rows || col < 0 || col >= puzzle.Cols || puzzle.CellBlocks[row][col] {
+			log.Printf("invalid cell to peek at for channel %s: (%d, %d)", channel, row, col)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// Keep track of how much help the channel has needed.
+		state.Reveals++
+		if err := SetState(conn, channel, state); err != nil {
+			log.Printf("unable to save state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		registry.Publish(ChannelID(channel), PeekEvent(row, col, puzzle.Cells[row][col], PeekDuration))
+		time.AfterFunc(PeekDuration, func() {
+			registry.Publish(ChannelID(channel), PeekClearEvent(row, col))
+		})
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// GetProgress returns how far along the channel's crossword solve is.
+func GetProgress(pool *redis.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if state.Puzzle == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		render.JSON(w, r, state.Progress())
+	}
+}
+
+// GetClueAge returns how long a clue of the channel's crossword has been open,
+// measured in solve time so that time spent paused isn't included.  Clues that
+// already have an answer filled in are reported as filled with an age of zero.
+func GetClueAge(pool *redis.Pool) http.HandlerFunc {
+	type ClueAge struct {
+		Clue   string         `json:"clue"`
+		Filled bool           `json:"filled"`
+		Age    model.Duration `json:"age"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+		clue := strings.ToLower(chi.URLParam(r, "clue"))
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if state.Puzzle == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		age, filled, err := state.ClueAge(clue, time.Now())
+		if err != nil {
+			log.Printf("unable to determine age of clue %s for channel %s: %+v", clue, channel, err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		render.JSON(w, r, ClueAge{
+			Clue:   clue,
+			Filled: filled,
+			Age:    model.Duration{Duration: age},
+		})
+	}
+}
+
+// MatchClue returns the clue of the channel's crossword whose text best matches
+// the text provided in the request's text query parameter, along with how
+// confident the match is.  The text doesn't have to match a clue exactly, which
+// allows voice driven overlays to resolve misheard clues.  When no clue matches
+// well enough the clue is omitted from the response.
+func MatchClue(pool *redis.Pool) http.HandlerFunc {
+	type ClueMatch struct {
+		Clue       string  `json:"clue,omitempty"`
+		Confidence float64 `json:"confidence"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		text := r.URL.Query().Get("text")
+		if strings.TrimSpace(text) == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if state.Puzzle == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		clue, confidence := state.Puzzle.MatchClueText(text)
+		render.JSON(w, r, ClueMatch{
+			Clue:       clue,
+			Confidence: confidence,
+		})
+	}
+}
+
+// GetLeaderboard returns the users that have been credited with solving clues
+// in the channel's crossword ranked by the number of clues they solved.
+func GetLeaderboard(pool *redis.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if state.Puzzle == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		render.JSON(w, r, state.Leaderboard())
+	}
+}
+
+// GetClues returns the across and down clues of the channel's crossword ordered
+// by their clue number.  No part of the solution is included so this is safe
+// to call while the crossword is being solved.
+func GetClues(pool *redis.Pool) http.HandlerFunc {
+	// A single clue, identified by its number.
+	type Clue struct {
+		Number int    `json:"number"`
+		Text   string `json:"text"`
+	}
+
+	// Sort a set of clues by their number.
+	sorted := func(clues map[int]string) []Clue {
+		sorted := make([]Clue, 0, len(clues))
+		for number, text := range clues {
+			sorted = append(sorted, Clue{Number: number, Text: text})
+		}
+
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i].Number < sorted[j].Number
+		})
+
+		return sorted
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if state.Puzzle == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		render.JSON(w, r, map[string][]Clue{
+			"across": sorted(state.Puzzle.CluesAcross),
+			"down":   sorted(state.Puzzle.CluesDown),
+		})
+	}
+}
+
+// GetAnswerKey returns the across and down clues of the channel's crossword
+// along with their correct answers ordered by their clue number.  Since this
+// reveals the solution it's only available once the solve is over, either
+// because the crossword was completed or because it was abandoned.
+func GetAnswerKey(pool *redis.Pool) http.HandlerFunc {
+	// A single clue and its answer, identified by its number.
+	type Clue struct {
+		Number int    `json:"number"`
+		Text   string `json:"text"`
+		Answer string `json:"answer"`
+	}
+
+	// Sort a set of clues by their number, including the answer to each.
+	sorted := func(puzzle *Puzzle, clues map[int]string, direction string) ([]Clue, error) {
+		sorted := make([]Clue, 0, len(clues))
+		for number, text := range clues {
+			answer, err := puzzle.Answer(number, direction)
+			if err != nil {
+				return nil, err
+			}
+
+			sorted = append(sorted, Clue{Number: number, Text: text, Answer: answer})
+		}
+
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i].Number < sorted[j].Number
+		})
+
+		return sorted, nil
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if state.Puzzle == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if state.Status != model.StatusComplete && state.Status != model.StatusAbandoned {
+			http.Error(w, "answer key is only available once the puzzle is over", http.StatusForbidden)
+			return
+		}
+
+		if !state.Puzzle.HasSolution() {
+			http.Error(w, "puzzle was imported without its solution", http.StatusConflict)
+			return
+		}
+
+		across, err := sorted(state.Puzzle, state.Puzzle.CluesAcross, "a")
+		if err != nil {
+			log.Printf("unable to determine across answers for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		down, err := sorted(state.Puzzle, state.Puzzle.CluesDown, "d")
+		if err != nil {
+			log.Printf("unable to determine down answers for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		render.JSON(w, r, map[string][]Clue{
+			"across": across,
+			"down":   down,
+		})
+	}
+}
+
+// GetText returns a plain text rendering of the channel's crossword solve for
+// use by screen readers and other accessibility tools.  Only the cells that
+// have been filled in are included, never the solution.
+func GetText(pool *redis.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if state.Puzzle == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		render.PlainText(w, r, state.Text())
+	}
+}
+
+// DefaultAuditLogLimit is the number of audit log entries returned when the
+// request doesn't specify how many it wants.
+const DefaultAuditLogLimit = 100
+
+// GetAuditLog returns the most recent entries from the channel's answer audit
+// log.  The number of entries can be controlled with the limit query
+// parameter, but no more than AuditLogMaxEntries are ever returned.  Entries
+// identify the users that submitted answers, so this is only available to
+// administrators.
+func GetAuditLog(pool *redis.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		limit := DefaultAuditLogLimit
+		if s := r.URL.Query().Get("limit"); s != "" {
+			value, err := strconv.Atoi(s)
+			if err != nil || value <= 0 {
+				log.Printf("invalid audit log limit %s", s)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			limit = value
+		}
+		if limit > AuditLogMaxEntries {
+			limit = AuditLogMaxEntries
+		}
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		entries, err := GetAuditEntries(conn, channel, limit)
+		if err != nil {
+			log.Printf("unable to load audit log for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		render.JSON(w, r, entries)
+	}
+}
+
+// Numbering describes the layout of a puzzle's grid, which cells are blocks
+// and what number each cell has, without any of the puzzle's solution.  It's
+// enough for a client to render an empty grid.
+type Numbering struct {
+	Rows            int      `json:"rows"`
+	Cols            int      `json:"cols"`
+	CellBlocks      [][]bool `json:"cell_blocks"`
+	CellClueNumbers [][]int  `json:"cell_clue_numbers"`
+}
+
+// GetNumbering returns the numbering of the grid of the channel's crossword.
+func GetNumbering(pool *redis.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if state.Puzzle == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		render.JSON(w, r, Numbering{
+			Rows:            state.Puzzle.Rows,
+			Cols:            state.Puzzle.Cols,
+			CellBlocks:      state.Puzzle.CellBlocks,
+			CellClueNumbers: state.Puzzle.CellClueNumbers,
+		})
+	}
+}
+
+// CellInfo describes a single cell of a puzzle's grid along with the clues
+// whose answers include it, without any of the puzzle's solution.  It allows a
+// client to find out everything about a cell without having to consult each
+// of the puzzle's per-cell lists.
+type CellInfo struct {
+	Row        int    `json:"row"`
+	Col        int    `json:"col"`
+	Number     int    `json:"number,omitempty"`
+	AcrossClue string `json:"across_clue,omitempty"`
+	DownClue   string `json:"down_clue,omitempty"`
+	Block      bool   `json:"block"`
+	Circle     bool   `json:"circle"`
+	Shade      bool   `json:"shade"`
+	Given      bool   `json:"given"`
+}
+
+// GetCellInfo returns information about a single cell of the channel's
+// crossword, including the clues whose answers include it.
+func GetCellInfo(pool *redis.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		row, err := strconv.Atoi(chi.URLParam(r, "row"))
+		if err != nil {
+			log.Printf("malformed row (%s): %+v", chi.URLParam(r, "row"), err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		col, err := strconv.Atoi(chi.URLParam(r, "col"))
+		if err != nil {
+			log.Printf("malformed col (%s): %+v", chi.URLParam(r, "col"), err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if state.Puzzle == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		puzzle := state.Puzzle
+		if row < 0 || row >= puzzle.Rows || col < 0 || col >= puzzle.Cols {
+			log.Printf("invalid cell for channel %s: (%d, %d)", channel, row, col)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		across, down := puzzle.CluesContaining(col, row)
+		render.JSON(w, r, CellInfo{
+			Row:        row,
+			Col:        col,
+			Number:     puzzle.CellClueNumbers[row][col],
+			AcrossClue: across,
+			DownClue:   down,
+			Block:      puzzle.CellBlocks[row][col],
+			Circle:     puzzle.IsCellCircled(col, row),
+			Shade:      puzzle.IsCellShaded(col, row),
+			Given:      puzzle.IsCellGiven(col, row),
+		})
+	}
+}
+
+// GetSnapshot returns the complete state of the channel's crossword solve,
+// including the puzzle's solution, so that it can be backed up or imported
+// into another server.  If the solution query parameter is false then the
+// puzzle's solution is left out of the snapshot.
+func GetSnapshot(pool *redis.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		solution := true
+		if value := r.URL.Query().Get("solution"); value != "" {
+			var err error
+			if solution, err = strconv.ParseBool(value); err != nil {
+				log.Printf("invalid solution parameter for channel %s: %s", channel, value)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if state.Puzzle == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if !solution {
+			state.Puzzle = state.Puzzle.WithoutSolution()
+		}
+
+		render.JSON(w, r, state)
+	}
+}
+
+// UpdateSnapshot replaces the state of the channel's crossword solve with one
+// that was previously returned by GetSnapshot.  A solve that was in progress
+// when the snapshot was taken is imported as paused so that the time between
+// taking the snapshot and importing it isn't counted.
+func UpdateSnapshot(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		var state State
+		if err := render.DecodeJSON(r.Body, &state); err != nil {
+			log.Printf("unable to read request body: %+v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if err := state.Validate(); err != nil {
+			log.Printf("invalid snapshot for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// Make sure the derived parts of the state agree with its cells.
+		state.AcrossCluesFilled = make(map[int]bool)
+		state.DownCluesFilled = make(map[int]bool)
+		if err := state.UpdateFilledClues(); err != nil {
+			log.Printf("unable to update filled clues for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if state.Status == model.StatusSolving {
+			if state.LastStartTime != nil && state.LastSaveTime != nil && state.LastSaveTime.After(*state.LastStartTime) {
+				total := state.TotalSolveDuration.Duration + state.LastSaveTime.Sub(*state.LastStartTime)
+				state.TotalSolveDuration = model.Duration{Duration: total}
+			}
+			state.Status = model.StatusPaused
+			state.LastStartTime = nil
+		}
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		if err := SetState(conn, channel, state); err != nil {
+			log.Printf("unable to save state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		// The snapshot doesn't say where its puzzle came from, so a raw source
+		// stored for the previous puzzle can't be trusted to match it.
+		if err := SetRawSource(conn, channel, nil); err != nil {
+			log.Printf("unable to remove raw source for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		// Broadcast the imported state to all of the clients, making sure to not
+		// include the answers.
+		state.Puzzle = state.Puzzle.WithoutSolution()
+
+		registry.Publish(ChannelID(channel), StateEvent(state))
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// ReparsePuzzle parses the channel's puzzle again from the raw source that was
+// stored when it was selected so that improvements to a parser can be picked up
+// without selecting the puzzle again.  When the reparsed puzzle's grid has the
+// same shape the progress of the solve is kept, otherwise the solve starts
+// over.
+func ReparsePuzzle(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := GetState(conn, channel)
+		if err != nil {
+			log.Printf("unable to load state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if state.Puzzle == nil {
+			log.Printf("unable to reparse puzzle for channel %s, no puzzle selected", channel)
+			http.Error(w, "no puzzle selected", http.StatusNotFound)
+			return
+		}
+
+		raw, err := GetRawSource(conn, channel)
+		if err != nil {
+			log.Printf("unable to load raw source for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if raw == nil {
+			log.Printf("unable to reparse puzzle for channel %s, no raw source stored", channel)
+			http.Error(w, "no raw source stored", http.StatusConflict)
+			return
+		}
+
+		source, ok := FindSource(raw.Source)
+		if !ok {
+			log.Printf("unable to reparse puzzle for channel %s, unknown source %s", channel, raw.Source)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		puzzle, err := source.Load(raw.Value)
+		if err != nil {
+			log.Printf("unable to reparse %s puzzle for channel %s: %+v", source.Label, channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if state.Puzzle.HasSameShape(puzzle) {
+			state.Puzzle = puzzle
+
+			// The clues may have changed so determine again which are filled in.
+			state.AcrossCluesFilled = make(map[int]bool)
+			state.DownCluesFilled = make(map[int]bool)
+			if err := state.UpdateFilledClues(); err != nil {
+				log.Printf("unable to update filled clues for channel %s: %+v", channel, err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		} else {
+			log.Printf("reparsed puzzle for channel %s has a different grid, starting over", channel)
+			state.resetEphemeralState(puzzle)
+
+			if err := SetProposals(conn, channel, nil); err != nil {
+				log.Printf("unable to remove proposals for channel %s: %+v", channel, err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
 		}
 
-		// Save the updated state.
 		if err := SetState(conn, channel, state); err != nil {
 			log.Printf("unable to save state for channel %s: %+v", channel, err)
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
-		// Broadcast to all of the clients that the puzzle has been selected, making
-		// sure to not include the answers.  It's okay to overwrite the puzzle
-		// attribute because we just wrote this state instance to the database
-		// and will be discarding it immediately publishing.
+		// Broadcast the reparsed puzzle to all of the clients, making sure to not
+		// include the answers.
 		state.Puzzle = state.Puzzle.WithoutSolution()
 
 		registry.Publish(ChannelID(channel), StateEvent(state))
 
-		// If we've just finished the solve then send a complete event as well.
-		if state.Status == model.StatusComplete {
-			registry.Publish(ChannelID(channel), CompleteEvent())
-		}
-
 		w.WriteHeader(http.StatusOK)
 	}
 }
 
-// ShowClue sends an event to all clients of a channel requesting that they
-// update their view to make the specified clue visible.  If the specified clue
-// isn't structured as a proper clue number and direction than an error will be
-// returned.
-func ShowClue(registry *pubsub.Registry) http.HandlerFunc {
+// GetDebug returns exactly what is stored in redis for the channel's crossword
+// state and settings along with the time remaining before each key expires.
+// Unlike GetState it doesn't reconcile the stored state or extend its
+// expiration.  The puzzle's solution is removed from the state unless the
+// force query parameter is true.
+func GetDebug(pool *redis.Pool) http.HandlerFunc {
+	// The raw contents of a single key.  The value is nil when the key doesn't
+	// exist and the TTL is in seconds using the same conventions as redis: -1
+	// when the key doesn't expire and -2 when it doesn't exist.
+	type Key struct {
+		Key   string  `json:"key"`
+		Value *string `json:"value"`
+		TTL   int     `json:"ttl"`
+	}
+
+	read := func(conn redis.Conn, key string) (Key, error) {
+		value, err := redis.String(conn.Do("GET", key))
+		if err != nil && err != redis.ErrNil {
+			return Key{}, err
+		}
+
+		ttl, err := redis.Int(conn.Do("TTL", key))
+		if err != nil {
+			return Key{}, err
+		}
+
+		k := Key{Key: key, TTL: ttl}
+		if ttl != -2 {
+			k.Value = &value
+		}
+		return k, nil
+	}
+
+	// Remove the solution from the puzzle of a raw state.
+	redact := func(value string) (string, error) {
+		var state map[string]interface{}
+		if err := json.Unmarshal([]byte(value), &state); err != nil {
+			return "", err
+		}
+
+		if puzzle, ok := state["puzzle"].(map[string]interface{}); ok {
+			delete(puzzle, "cells")
+		}
+
+		bs, err := json.Marshal(state)
+		return string(bs), err
+	}
+
 	return func(w http.ResponseWriter, r *http.Request) {
 		channel := chi.URLParam(r, "channel")
-		clue := chi.URLParam(r, "clue")
+		force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
 
-		_, _, err := ParseClue(clue)
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		state, err := read(conn, StateKey(channel))
 		if err != nil {
-			log.Printf("malformed clue (%s): %+v", clue, err)
-			w.WriteHeader(http.StatusBadRequest)
+			log.Printf("unable to read state for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
-		registry.Publish(ChannelID(channel), ShowClueEvent(clue))
-		w.WriteHeader(http.StatusOK)
+		settings, err := read(conn, SettingsKey(channel))
+		if err != nil {
+			log.Printf("unable to read settings for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if state.Value != nil && !force {
+			redacted, err := redact(*state.Value)
+			if err != nil {
+				log.Printf("unable to redact state for channel %s: %+v", channel, err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			state.Value = &redacted
+		}
+
+		render.JSON(w, r, map[string]Key{
+			"state":    state,
+			"settings": settings,
+		})
 	}
 }
 
@@ -434,44 +2624,151 @@ func GetEvents(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
 		stream := make(chan pubsub.Event, 10)
 		defer close(stream)
 
-		// Setup a connection to redis so that we can read settings and the current
-		// state of the solve.
-		conn := pool.Get()
-		defer func() { _ = conn.Close() }()
+		// The crossword settings and the current state of the solve are always the
+		// first events sent to the client.
+		load := func() ([]pubsub.Event, error) {
+			conn := pool.Get()
+			defer func() { _ = conn.Close() }()
 
-		// Always send the crossword settings if there are any.
-		settings, err := GetSettings(conn, channel)
-		if err != nil {
-			log.Printf("unable to read settings for channel %s: %+v", channel, err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
+			return InitialEvents(conn, channel)
 		}
-		stream <- SettingsEvent(settings)
 
-		// Send the current state of the solve if there is one, but make sure to
-		// mask the solution to the puzzle.
-		state, err := GetState(conn, channel)
-		if err != nil {
-			log.Printf("unable to read state for channel %s: %+v", channel, err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-		if state.Puzzle != nil {
-			state.Puzzle = state.Puzzle.WithoutSolution()
-			stream <- StateEvent(state)
-		}
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		// If the initial events can't be loaded the client is sent an error event
+		// instead, but the stream stays open while loading is retried in the
+		// background.
+		done := pubsub.SendInitialEvents(ctx, stream, load)
+		defer func() {
+			cancel()
+			<-done
+		}()
 
 		// Now that we've seeded the stream with the initialization events,
 		// subscribe it to receive all future events for the channel.
 		id, err := registry.Subscribe(ChannelID(channel), stream)
 		defer registry.Unsubscribe(id)
+		if errors.Is(err, pubsub.ErrTooManySubscribers) {
+			log.Printf("too many clients subscribed to channel %s", channel)
+			w.Header().Set("Retry-After", pubsub.SubscriberRetryAfter)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
 		if err != nil {
 			log.Printf("unable to subscribe client to channel %s: %+v", channel, err)
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
-		pubsub.EmitEvents(r.Context(), w, stream)
+		pubsub.EmitEvents(ctx, w, stream)
+	}
+}
+
+// Resync publishes the events that describe the current settings and state of
+// the channel's solve to all of its clients, the same events a client receives
+// when it first connects.  This allows a client that got into a bad state to
+// recover without reconnecting.  The events are also returned in the response.
+func Resync(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		events, err := InitialEvents(conn, channel)
+		if err != nil {
+			log.Printf("unable to load events for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		for _, event := range events {
+			registry.Publish(ChannelID(channel), event)
+		}
+
+		render.JSON(w, r, events)
+	}
+}
+
+// InitialEvents loads the events that describe the channel's crossword settings
+// and the current state of its solve (if there is one, but with the solution to
+// the puzzle masked), followed by the banner pinned to the channel (if any).
+func InitialEvents(conn redis.Conn, channel string) ([]pubsub.Event, error) {
+	settings, err := GetSettings(conn, channel)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read settings for channel %s: %w", channel, err)
+	}
+	events := []pubsub.Event{SettingsEvent(settings)}
+
+	state, err := GetState(conn, channel)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read state for channel %s: %w", channel, err)
+	}
+	if state.Puzzle != nil {
+		state.Puzzle = state.Puzzle.WithoutSolution()
+		events = append(events, StateEvent(state))
+
+		if state.FocusedClue != "" {
+			events = append(events, FocusEvent(state.FocusedClue))
+		}
+	}
+
+	banner, err := GetBanner(conn, channel)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read banner for channel %s: %w", channel, err)
+	}
+	if banner != nil {
+		events = append(events, BannerEvent(banner))
+	}
+
+	return events, nil
+}
+
+// PollEvents is a fallback for clients that can't hold an event stream open.
+// It returns the events published to the channel since the cursor in the since
+// query parameter as newline delimited JSON.  If there aren't any newer events
+// then it waits up to pubsub.PollTimeout for one to be published.  The cursor
+// to use for the next poll is returned in the X-Cursor header.
+func PollEvents(registry *pubsub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		var since uint64
+		if s := r.URL.Query().Get("since"); s != "" {
+			value, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				log.Printf("invalid poll cursor %s", s)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			since = value
+		}
+
+		events, cursor := registry.Poll(r.Context(), ChannelID(channel), since, pubsub.PollTimeout)
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("X-Cursor", strconv.FormatUint(cursor, 10))
+		w.WriteHeader(http.StatusOK)
+
+		encoder := json.NewEncoder(w)
+		for _, event := range events {
+			if err := encoder.Encode(event); err != nil {
+				log.Printf("unable to write poll event for channel %s: %+v", channel, err)
+				return
+			}
+		}
+	}
+}
+
+// GetCapabilities returns the sources that puzzles can currently be selected
+// from along with the payload key and kind of value that each one expects.
+func GetCapabilities() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, map[string][]Source{
+			"sources": EnabledSources(),
+		})
 	}
 }
 
@@ -490,12 +2787,100 @@ func GetAvailableDates() http.HandlerFunc {
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		render.JSON(w, r, map[string][]string{
-			"new_york_times":      format(LoadAvailableNYTDates()),
-			"wall_street_journal": format(LoadAvailableWSJDates()),
+			"atlantic":               format(LoadAvailableAtlanticDates()),
+			"jonesin":                format(LoadAvailableJonesinDates()),
+			"new_york_times":         format(LoadAvailableNYTDates()),
+			"new_york_times_mini":    format(LoadAvailableNYTMiniDates()),
+			"new_york_times_cryptic": format(LoadAvailableNYTCrypticDates()),
+			"wall_street_journal":    format(LoadAvailableWSJDates()),
+			"washington_post":        format(LoadAvailableWPDates()),
 		})
 	}
 }
 
+// GetWeekdayHistogram returns how many of a source's available dates fall on
+// each day of the week, indexed by the name of the weekday.
+func GetWeekdayHistogram() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		source := chi.URLParam(r, "source")
+
+		available, ok := AvailableDateLoaders[source]
+		if !ok {
+			log.Printf("unrecognized puzzle source: %s", source)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		render.JSON(w, r, WeekdayHistogram(available()))
+	}
+}
+
+// WeekdayHistogram counts how many of the dates fall on each day of the week.
+// Every weekday is included in the result, even when no dates fall on it.
+func WeekdayHistogram(dates []time.Time) map[string]int {
+	histogram := make(map[string]int)
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		histogram[weekday.String()] = 0
+	}
+
+	for _, date := range dates {
+		histogram[date.Weekday().String()]++
+	}
+
+	return histogram
+}
+
+// WarmCache starts loading puzzles into the puzzle cache so that selecting them
+// later doesn't require waiting for them to download.  The request body may
+// specify the sources to load puzzles from and the date of the puzzles, by
+// default every source is warmed with the next day's puzzles.
+func WarmCache() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Sources []string `json:"sources"`
+			Date    string   `json:"date"`
+		}
+		if err := render.DecodeJSON(r.Body, &payload); err != nil && err != io.EOF {
+			log.Printf("unable to read request body: %+v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		sources := payload.Sources
+		if len(sources) == 0 {
+			for source := range PuzzleLoaders {
+				sources = append(sources, source)
+			}
+			sort.Strings(sources)
+		}
+
+		for _, source := range sources {
+			if _, ok := PuzzleLoaders[source]; !ok {
+				log.Printf("unrecognized puzzle source: %s", source)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+
+		date := time.Now().UTC().AddDate(0, 0, 1)
+		if payload.Date != "" {
+			d, err := time.Parse("2006-01-02", payload.Date)
+			if err != nil {
+				log.Printf("unable to parse date %s: %+v", payload.Date, err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			date = d
+		}
+
+		// Warming respects the spacing between fetches so it can take a while,
+		// don't make the client wait for it.
+		go WarmPuzzleCache(context.Background(), sources, date)
+
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
 func ChannelID(channel string) pubsub.Channel {
 	channel = fmt.Sprintf("%s:crossword", channel)
 	return pubsub.Channel(channel)
@@ -508,16 +2893,70 @@ func SettingsEvent(settings Settings) pubsub.Event {
 	}
 }
 
+func SettingChangedEvent(setting string, old, value interface{}) pubsub.Event {
+	return pubsub.Event{
+		Kind: "setting_changed",
+		Payload: map[string]interface{}{
+			"setting": setting,
+			"old":     old,
+			"new":     value,
+		},
+	}
+}
+
+// StateEvent returns the event that's sent when a channel's state changes.
+// Answers queued while the solve is paused are left out since they would reveal
+// the pending answers and who submitted them before they're applied.
 func StateEvent(state State) pubsub.Event {
+	state.Elapsed = state.SolveDuration(time.Now()).Seconds()
+	state.QueuedAnswers = nil
+
 	return pubsub.Event{
 		Kind:    "state",
 		Payload: state,
 	}
 }
 
-func CompleteEvent() pubsub.Event {
+// CompleteEvent returns the event that's sent when a channel completes its
+// crossword.  Along with how the puzzle was solved it carries the attribution
+// of the puzzle so that clients sharing the solve can credit its source.  The
+// copyright and source URL are only included when they're known.
+func CompleteEvent(state State) pubsub.Event {
+	payload := map[string]interface{}{
+		"clue_solvers": state.ClueSolvers,
+		"reveals":      state.Reveals,
+	}
+
+	if state.Puzzle != nil {
+		payload["publisher"] = state.Puzzle.Publisher
+		if state.Puzzle.Copyright != "" {
+			payload["copyright"] = state.Puzzle.Copyright
+		}
+		if url := SourceURL(state.Source, state.Puzzle.PublishedDate); url != "" {
+			payload["source_url"] = url
+		}
+	}
+
+	return pubsub.Event{
+		Kind:    "complete",
+		Payload: payload,
+	}
+}
+
+// ProposalsEvent returns the event that's sent when chat's proposals change.
+// Only the score of each proposal is included, never who voted for it.
+func ProposalsEvent(proposals map[string][]Proposal) pubsub.Event {
+	payload := make(map[string][]Proposal)
+	for clue, ps := range proposals {
+		for _, proposal := range ps {
+			proposal.Votes = nil
+			payload[clue] = append(payload[clue], proposal)
+		}
+	}
+
 	return pubsub.Event{
-		Kind: "complete",
+		Kind:    "proposals",
+		Payload: payload,
 	}
 }
 
@@ -527,3 +2966,124 @@ func ShowClueEvent(clue string) pubsub.Event {
 		Payload: clue,
 	}
 }
+
+func HideClueEvent(clue string) pubsub.Event {
+	return pubsub.Event{
+		Kind:    "hide_clue",
+		Payload: clue,
+	}
+}
+
+// BannerEvent is sent when a channel's banner changes.  A nil banner means that
+// the banner has been cleared.
+func BannerEvent(banner *Banner) pubsub.Event {
+	if banner == nil {
+		return pubsub.Event{Kind: "banner"}
+	}
+
+	return pubsub.Event{
+		Kind:    "banner",
+		Payload: banner,
+	}
+}
+
+func FocusEvent(clue string) pubsub.Event {
+	return pubsub.Event{
+		Kind:    "focus",
+		Payload: clue,
+	}
+}
+
+// Peek describes a cell of the crossword that is being peeked at.  The value is
+// only present while the cell is visible.
+type Peek struct {
+	Row   int            `json:"row"`
+	Col   int            `json:"col"`
+	Value string         `json:"value,omitempty"`
+	TTL   model.Duration `json:"ttl"`
+}
+
+func PeekEvent(row, col int, value string, ttl time.Duration) pubsub.Event {
+	return pubsub.Event{
+		Kind:    "peek",
+		Payload: Peek{Row: row, Col: col, Value: value, TTL: model.Duration{Duration: ttl}},
+	}
+}
+
+func PeekClearEvent(row, col int) pubsub.Event {
+	return pubsub.Event{
+		Kind:    "peek_clear",
+		Payload: Peek{Row: row, Col: col},
+	}
+}
+
+// ChannelComparison describes how far along a channel's crossword solve is so
+// that it can be compared against other channels solving the same puzzle.
+type ChannelComparison struct {
+	Progress
+
+	// The name of the channel.
+	Channel string `json:"channel"`
+
+	// The status of the channel's solve.
+	Status model.Status `json:"status"`
+
+	// The total amount of time the channel has spent solving the puzzle.
+	Elapsed model.Duration `json:"elapsed"`
+}
+
+// CompareChannels returns the progress of two channels that are solving the
+// same crossword so that they can be compared head to head.
+func CompareChannels(pool *redis.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		a := r.URL.Query().Get("a")
+		b := r.URL.Query().Get("b")
+		if a == "" || b == "" || a == b {
+			log.Printf("invalid channels to compare, a: %s, b: %s", a, b)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		var states []State
+		for _, channel := range []string{a, b} {
+			state, err := GetState(conn, channel)
+			if err != nil {
+				log.Printf("unable to load state for channel %s: %+v", channel, err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			if state.Puzzle == nil {
+				log.Printf("channel %s doesn't have a puzzle selected", channel)
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			states = append(states, state)
+		}
+
+		if !states[0].Puzzle.IsSamePuzzle(states[1].Puzzle) {
+			log.Printf("channels %s and %s are solving different puzzles", a, b)
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		now := time.Now()
+		compare := func(channel string, state State) ChannelComparison {
+			return ChannelComparison{
+				Progress: state.Progress(),
+				Channel:  channel,
+				Status:   state.Status,
+				Elapsed:  model.Duration{Duration: state.SolveDuration(now)},
+			}
+		}
+
+		render.JSON(w, r, map[string]ChannelComparison{
+			"a": compare(a, states[0]),
+			"b": compare(b, states[1]),
+		})
+	}
+}
@@ -3,9 +3,12 @@ package crossword
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/admin"
 	"github.com/bbeck/puzzles-with-chat/api/model"
 	"github.com/bbeck/puzzles-with-chat/api/pubsub"
 	"github.com/go-chi/chi"
@@ -17,6 +20,7 @@ import (
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"path"
 	"sort"
 	"strings"
@@ -27,6 +31,43 @@ import (
 
 var Channel = ChannelClient{name: "channel"}
 
+func TestRoute_ReadSettings(t *testing.T) {
+	router, _, _ := NewTestRouter(t)
+
+	// A channel that has never changed a setting gets the defaults.
+	response := Channel.GET("/settings", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var settings Settings
+	require.NoError(t, render.DecodeJSON(response.Result().Body, &settings))
+	assert.False(t, settings.OnlyAllowCorrectAnswers)
+	assert.Equal(t, AllCluesVisible, settings.CluesToShow)
+	assert.Equal(t, model.FontSizeNormal, settings.ClueFontSize)
+	assert.Equal(t, DefaultCompleteThreshold, settings.CompleteThreshold)
+
+	// Once a setting is changed its new value is returned.
+	response = Channel.PUT("/setting/clue_font_size", `"xlarge"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	response = Channel.PUT("/setting/complete_threshold", `80`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	response = Channel.GET("/settings", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	settings = Settings{}
+	require.NoError(t, render.DecodeJSON(response.Result().Body, &settings))
+	assert.Equal(t, model.FontSizeXLarge, settings.ClueFontSize)
+	assert.Equal(t, 80, settings.CompleteThreshold)
+}
+
+func TestRoute_ReadSettings_LoadError(t *testing.T) {
+	router, _, _ := NewTestRouter(t)
+	ForceErrorDuringSettingsLoad(t, errors.New("forced error"))
+
+	response := Channel.GET("/settings", router)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
 func TestRoute_UpdateSetting(t *testing.T) {
 	// This acts as a small integration test updating each setting in turn and
 	// making sure the proper value is written to the database and that clients
@@ -58,6 +99,92 @@ func TestRoute_UpdateSetting(t *testing.T) {
 	VerifySettings(t, pool, events, func(s Settings) {
 		assert.True(t, s.ShowNotes)
 	})
+
+	response = Channel.PUT("/setting/complete_threshold", `80`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifySettings(t, pool, events, func(s Settings) {
+		assert.Equal(t, 80, s.CompleteThreshold)
+	})
+
+	response = Channel.PUT("/setting/show_correct_count", `true`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifySettings(t, pool, events, func(s Settings) {
+		assert.True(t, s.ShowCorrectCount)
+	})
+
+	response = Channel.PUT("/setting/answer_aliases", `{"+": "plus"}`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifySettings(t, pool, events, func(s Settings) {
+		assert.Equal(t, map[string]string{"+": "PLUS"}, s.AnswerAliases)
+	})
+
+	response = Channel.PUT("/setting/emote_letters", `{"myChannelA": "a"}`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifySettings(t, pool, events, func(s Settings) {
+		assert.Equal(t, map[string]string{"myChannelA": "A"}, s.EmoteLetters)
+	})
+
+	response = Channel.PUT("/setting/audit_answers", `true`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifySettings(t, pool, events, func(s Settings) {
+		assert.True(t, s.AuditAnswers)
+	})
+
+	response = Channel.PUT("/setting/auto_advance_on_complete", `true`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifySettings(t, pool, events, func(s Settings) {
+		assert.True(t, s.AutoAdvanceOnComplete)
+	})
+
+	response = Channel.PUT("/setting/store_raw_source", `true`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifySettings(t, pool, events, func(s Settings) {
+		assert.True(t, s.StoreRawSource)
+	})
+
+	response = Channel.PUT("/setting/paused_answer_behavior", `"queue"`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifySettings(t, pool, events, func(s Settings) {
+		assert.Equal(t, QueuePausedAnswers, s.PausedAnswerBehavior)
+	})
+
+	response = Channel.PUT("/setting/min_toggle_interval_ms", `500`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifySettings(t, pool, events, func(s Settings) {
+		assert.Equal(t, 500, s.MinToggleIntervalMillis)
+	})
+
+}
+
+func TestRoute_UpdateSetting_SettingChangedEvent(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	require.NoError(t, SetSettings(conn, Channel.name, Settings{ClueFontSize: model.FontSizeLarge}))
+
+	response := Channel.PUT("/setting/clue_font_size", `"xlarge"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	found := Events(events, "setting_changed")
+	require.Equal(t, 1, len(found))
+	assert.Equal(t, map[string]interface{}{
+		"setting": "clue_font_size",
+		"old":     "large",
+		"new":     "xlarge",
+	}, found[0].Payload)
+
+	// Setting a value to what it already is isn't a change.
+	response = Channel.PUT("/setting/clue_font_size", `"xlarge"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Empty(t, Events(events, "setting_changed"))
+
+	// The full settings are still sent for every update.
+	response = Channel.PUT("/setting/complete_threshold", `80`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifySettings(t, pool, events, func(s Settings) {
+		assert.Equal(t, 80, s.CompleteThreshold)
+	})
 }
 
 func TestRoute_UpdateSetting_ClearsIncorrectCells(t *testing.T) {
@@ -116,11 +243,86 @@ func TestRoute_UpdateSetting_JSONError(t *testing.T) {
 			setting: "foo_bar_baz",
 			json:    `false`,
 		},
+		{
+			name:    "show_correct_count",
+			setting: "show_correct_count",
+			json:    `{`,
+		},
+		{
+			name:    "answer_aliases",
+			setting: "answer_aliases",
+			json:    `{`,
+		},
+		{
+			name:    "answer_aliases empty alias",
+			setting: "answer_aliases",
+			json:    `{"": "PLUS"}`,
+		},
+		{
+			name:    "answer_aliases empty value",
+			setting: "answer_aliases",
+			json:    `{"+": " "}`,
+		},
+		{
+			name:    "emote_letters",
+			setting: "emote_letters",
+			json:    `{`,
+		},
+		{
+			name:    "emote_letters non-letter value",
+			setting: "emote_letters",
+			json:    `{"myChannelA": "1"}`,
+		},
 		{
 			name:    "show_notes",
 			setting: "show_notes",
 			json:    `{`,
 		},
+		{
+			name:    "complete_threshold",
+			setting: "complete_threshold",
+			json:    `{`,
+		},
+		{
+			name:    "complete_threshold too small",
+			setting: "complete_threshold",
+			json:    `-1`,
+		},
+		{
+			name:    "complete_threshold too large",
+			setting: "complete_threshold",
+			json:    `101`,
+		},
+		{
+			name:    "auto_advance_on_complete",
+			setting: "auto_advance_on_complete",
+			json:    `{`,
+		},
+		{
+			name:    "store_raw_source",
+			setting: "store_raw_source",
+			json:    `{`,
+		},
+		{
+			name:    "paused_answer_behavior",
+			setting: "paused_answer_behavior",
+			json:    `{`,
+		},
+		{
+			name:    "min_toggle_interval_ms",
+			setting: "min_toggle_interval_ms",
+			json:    `{`,
+		},
+		{
+			name:    "min_toggle_interval_ms negative",
+			setting: "min_toggle_interval_ms",
+			json:    `-1`,
+		},
+		{
+			name:    "completion_webhook isn't a setting",
+			setting: "completion_webhook",
+			json:    `"https://example.com/solved"`,
+		},
 	}
 
 	for _, test := range tests {
@@ -133,6 +335,55 @@ func TestRoute_UpdateSetting_JSONError(t *testing.T) {
 	}
 }
 
+func TestRoute_UpdateSetting_InvalidValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		setting  string
+		json     string
+		expected []string // the allowed values
+	}{
+		{
+			name:     "clues_to_show",
+			setting:  "clues_to_show",
+			json:     `"some"`,
+			expected: []string{"all", "none", "down", "across"},
+		},
+		{
+			name:     "clue_font_size",
+			setting:  "clue_font_size",
+			json:     `"huge"`,
+			expected: []string{"normal", "large", "xlarge"},
+		},
+		{
+			name:     "paused_answer_behavior",
+			setting:  "paused_answer_behavior",
+			json:     `"hold"`,
+			expected: []string{"reject", "queue"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, _, _ := NewTestRouter(t)
+
+			response := Channel.PUT(fmt.Sprintf("/setting/%s", test.setting), test.json, router)
+			require.Equal(t, http.StatusBadRequest, response.Code)
+
+			var body struct {
+				Error   string   `json:"error"`
+				Setting string   `json:"setting"`
+				Allowed []string `json:"allowed"`
+			}
+			require.NoError(t, render.DecodeJSON(response.Body, &body))
+			assert.Equal(t, test.setting, body.Setting)
+			assert.Equal(t, test.expected, body.Allowed)
+			for _, value := range test.expected {
+				assert.Contains(t, body.Error, value)
+			}
+		})
+	}
+}
+
 func TestRoute_UpdateSettings_LoadSaveError(t *testing.T) {
 	tests := []struct {
 		name                   string
@@ -201,61 +452,106 @@ func TestRoute_UpdatePuzzle_NewYorkTimes(t *testing.T) {
 	})
 }
 
-func TestRoute_UpdatePuzzle_WallStreetJournal(t *testing.T) {
-	// This acts as a small integration test updating the date of the Wall Street
-	// Journal crossword we're working on and ensuring the proper values are
+func TestRoute_UpdatePuzzle_NewYorkTimesMini(t *testing.T) {
+	// This acts as a small integration test updating the date of the New York
+	// Times Mini crossword we're working on and ensuring the proper values are
 	// written to the database.
 	router, pool, registry := NewTestRouter(t)
 	events := NewEventSubscription(t, registry, Channel.name)
 
 	// Force a specific puzzle to be loaded so we don't make a network call.
-	ForcePuzzleToBeLoaded(t, "puzzle-wsj-20190102.json")
+	ForcePuzzleToBeLoaded(t, "nyt-mini-20240105.json")
 
-	response := Channel.PUT("/", `{"wall_street_journal_date": "2019-01-02"}`, router)
+	response := Channel.PUT("/", `{"new_york_times_mini_date": "2024-01-05"}`, router)
 	assert.Equal(t, http.StatusOK, response.Code)
 	VerifyState(t, pool, events, func(state State) {
 		assert.Equal(t, model.StatusSelected, state.Status)
 		assert.NotNil(t, state.Puzzle)
-		assert.Equal(t, 0, len(state.AcrossCluesFilled))
-		assert.Equal(t, 0, len(state.DownCluesFilled))
+		assert.Equal(t, 5, state.Puzzle.Rows)
+		assert.Equal(t, 5, state.Puzzle.Cols)
 		assert.Nil(t, state.LastStartTime)
-		assert.Equal(t, 0., state.TotalSolveDuration.Seconds())
 	})
 }
 
-func TestRoute_UpdatePuzzle_PuzFile(t *testing.T) {
-	// This acts as a small integration test uploading a .puz file of the
+func TestRoute_UpdatePuzzle_NewYorkTimesCryptic(t *testing.T) {
+	// This acts as a small integration test updating the date of the New York
+	// Times cryptic crossword we're working on and ensuring the proper values
+	// are written to the database.
+	router, pool, registry := NewTestRouter(t)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	// Force a specific puzzle and its date to be available so we don't make a
+	// network call.
+	ForcePuzzleToBeLoaded(t, "xwordinfo-cryptic-20240107.json")
+	ForceAvailableCrypticDates(t, []time.Time{time.Date(2024, time.January, 7, 0, 0, 0, 0, time.UTC)})
+
+	response := Channel.PUT("/", `{"new_york_times_cryptic_date": "2024-01-07"}`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, model.StatusSelected, state.Status)
+		assert.NotNil(t, state.Puzzle)
+		assert.Equal(t, "Shop's reserve (5)", state.Puzzle.CluesAcross[1])
+	})
+
+	// Dates without a cryptic are rejected.
+	response = Channel.PUT("/", `{"new_york_times_cryptic_date": "2024-01-08"}`, router)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestRoute_UpdatePuzzle_Atlantic(t *testing.T) {
+	// This acts as a small integration test updating the date of the Atlantic
 	// crossword we're working on and ensuring the proper values are written to
 	// the database.
 	router, pool, registry := NewTestRouter(t)
 	events := NewEventSubscription(t, registry, Channel.name)
 
 	// Force a specific puzzle to be loaded so we don't make a network call.
-	ForcePuzzleToBeLoaded(t, "puzzle-wp-20051206.json")
+	ForcePuzzleToBeLoaded(t, "atlantic-20240311.json")
 
-	response := Channel.PUT("/", `{"puz_file_bytes": "unused"}`, router)
+	response := Channel.PUT("/", `{"atlantic_date": "2024-03-11"}`, router)
 	assert.Equal(t, http.StatusOK, response.Code)
 	VerifyState(t, pool, events, func(state State) {
 		assert.Equal(t, model.StatusSelected, state.Status)
 		assert.NotNil(t, state.Puzzle)
-		assert.Equal(t, 0, len(state.AcrossCluesFilled))
-		assert.Equal(t, 0, len(state.DownCluesFilled))
+		assert.Equal(t, "The Atlantic", state.Puzzle.Publisher)
+		assert.Equal(t, 5, state.Puzzle.Rows)
+		assert.Equal(t, 5, state.Puzzle.Cols)
 		assert.Nil(t, state.LastStartTime)
-		assert.Equal(t, 0., state.TotalSolveDuration.Seconds())
 	})
 }
 
-func TestRoute_UpdatePuzzle_PuzURL(t *testing.T) {
-	// This acts as a small integration test retrieving a .puz file from a URL of
-	// the crossword we're working on and ensuring the proper values are written
-	// to the database.
+func TestRoute_UpdatePuzzle_Jonesin(t *testing.T) {
+	// This acts as a small integration test updating the date of the Jonesin'
+	// crossword we're working on and ensuring the proper values are written to
+	// the database.
 	router, pool, registry := NewTestRouter(t)
 	events := NewEventSubscription(t, registry, Channel.name)
 
 	// Force a specific puzzle to be loaded so we don't make a network call.
-	ForcePuzzleToBeLoaded(t, "puzzle-wp-20051206.json")
+	ForcePuzzleToBeLoaded(t, "puz/puzpy-avclub-20110622.puz")
 
-	response := Channel.PUT("/", `{"puz_file_url": "unused"}`, router)
+	response := Channel.PUT("/", `{"jonesin_date": "2020-01-02"}`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, model.StatusSelected, state.Status)
+		assert.NotNil(t, state.Puzzle)
+		assert.Equal(t, 15, state.Puzzle.Rows)
+		assert.Equal(t, 15, state.Puzzle.Cols)
+		assert.Nil(t, state.LastStartTime)
+	})
+}
+
+func TestRoute_UpdatePuzzle_WallStreetJournal(t *testing.T) {
+	// This acts as a small integration test updating the date of the Wall Street
+	// Journal crossword we're working on and ensuring the proper values are
+	// written to the database.
+	router, pool, registry := NewTestRouter(t)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	// Force a specific puzzle to be loaded so we don't make a network call.
+	ForcePuzzleToBeLoaded(t, "puzzle-wsj-20190102.json")
+
+	response := Channel.PUT("/", `{"wall_street_journal_date": "2019-01-02"}`, router)
 	assert.Equal(t, http.StatusOK, response.Code)
 	VerifyState(t, pool, events, func(state State) {
 		assert.Equal(t, model.StatusSelected, state.Status)
@@ -267,416 +563,4499 @@ func TestRoute_UpdatePuzzle_PuzURL(t *testing.T) {
 	})
 }
 
-func TestRoute_UpdatePuzzle_JSONError(t *testing.T) {
+func TestRoute_UpdatePuzzle_NoPuzzleOnDate(t *testing.T) {
 	tests := []struct {
-		name     string
-		json     string
-		expected int
+		name    string
+		json    string
+		message string
 	}{
 		{
-			name:     "bad json",
-			json:     `{"new_york_times_date": }`,
-			expected: http.StatusBadRequest,
-		},
-		{
-			name:     "invalid json",
-			json:     `{}`,
-			expected: http.StatusBadRequest,
+			name:    "wsj date not in list",
+			json:    `{"wall_street_journal_date": "2019-01-01"}`,
+			message: "no WSJ puzzle on that date",
 		},
-	}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			router, _, _ := NewTestRouter(t)
-			ForcePuzzleToBeLoaded(t, "xwordinfo-nyt-20181231.json")
-
-			response := Channel.PUT("/", test.json, router)
-			assert.Equal(t, test.expected, response.Code)
-		})
-	}
-}
-
-func TestRoute_UpdatePuzzle_LoadSaveError(t *testing.T) {
-	tests := []struct {
-		name                 string
-		json                 string
-		forcePuzzleLoadError error
-		forceStateSaveError  error
-		expected             int
-	}{
 		{
-			name:                 "nyt error loading puzzle",
-			json:                 `{"new_york_times_date": "unused"}`,
-			forcePuzzleLoadError: errors.New("forced error"),
-			expected:             http.StatusInternalServerError,
+			name:    "wp before first puzzle",
+			json:    `{"washington_post_date": "2004-12-31"}`,
+			message: "no WP puzzle on that date",
 		},
 		{
-			name:                 "wsj error loading puzzle",
-			json:                 `{"wall_street_journal_date": "unused"}`,
-			forcePuzzleLoadError: errors.New("forced error"),
-			expected:             http.StatusInternalServerError,
+			name:    "nyt weekday before daily puzzles",
+			json:    `{"new_york_times_date": "1945-06-05"}`,
+			message: "no NYT puzzle on that date",
 		},
 		{
-			name:                 "puz bytes error loading puzzle",
-			json:                 `{"puz_file_bytes": "unused"}`,
-			forcePuzzleLoadError: errors.New("forced error"),
-			expected:             http.StatusInternalServerError,
+			name:    "nyt mini before first puzzle",
+			json:    `{"new_york_times_mini_date": "2014-08-20"}`,
+			message: "no NYT Mini puzzle on that date",
 		},
 		{
-			name:                 "puz url error loading puzzle",
-			json:                 `{"puz_file_url": "unused"}`,
-			forcePuzzleLoadError: errors.New("forced error"),
-			expected:             http.StatusInternalServerError,
+			name:    "atlantic future date",
+			json:    `{"atlantic_date": "2999-01-01"}`,
+			message: "no Atlantic puzzle on that date",
 		},
 		{
-			name:                "error saving state",
-			json:                `{"new_york_times_date": "unused"}`,
-			forceStateSaveError: errors.New("forced error"),
-			expected:            http.StatusInternalServerError,
+			name:    "jonesin non-thursday",
+			json:    `{"jonesin_date": "2020-01-03"}`,
+			message: "no Jonesin' puzzle on that date",
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			router, _, _ := NewTestRouter(t)
-
-			if test.forcePuzzleLoadError != nil {
-				ForceErrorDuringPuzzleLoad(t, test.forcePuzzleLoadError)
-			} else {
-				ForcePuzzleToBeLoaded(t, "xwordinfo-nyt-20181231.json")
-			}
+			router, pool, registry := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+			events := NewEventSubscription(t, registry, Channel.name)
 
-			ForceErrorDuringStateSave(t, test.forceStateSaveError)
+			// The puzzle would load if it were requested, so a failure means the
+			// date was rejected before attempting to download it.
+			ForcePuzzleToBeLoaded(t, "puzzle-wsj-20190102.json")
 
 			response := Channel.PUT("/", test.json, router)
-			assert.Equal(t, test.expected, response.Code)
+			assert.Equal(t, http.StatusNotFound, response.Code)
+			assert.Equal(t, test.message, strings.TrimSpace(response.Body.String()))
+			assert.Empty(t, Events(events, "state"))
+
+			state, err := GetState(conn, Channel.name)
+			require.NoError(t, err)
+			assert.Nil(t, state.Puzzle)
 		})
 	}
 }
 
-func TestRoute_ToggleStatus(t *testing.T) {
-	// This acts as a small integration test toggling the status of a crossword
-	// being solved.
+func TestRoute_UpdatePuzzle_WashingtonPost(t *testing.T) {
+	// This acts as a small integration test updating the date of the Washington
+	// Post crossword we're working on and ensuring the proper values are written
+	// to the database.
 	router, pool, registry := NewTestRouter(t)
-	conn := NewRedisConnection(t, pool)
 	events := NewEventSubscription(t, registry, Channel.name)
 
-	// Set a state that has a puzzle selected but not yet started.
-	state := NewState(t, "xwordinfo-nyt-20181231.json")
-	require.NoError(t, SetState(conn, Channel.name, state))
+	// Force a specific puzzle to be loaded so we don't make a network call.
+	ForcePuzzleToBeLoaded(t, "puzzle-wp-20051206.json")
 
-	// Toggle the status, it should transition to solving.
-	response := Channel.PUT("/status", ``, router)
+	response := Channel.PUT("/", `{"washington_post_date": "2005-12-06"}`, router)
 	assert.Equal(t, http.StatusOK, response.Code)
 	VerifyState(t, pool, events, func(state State) {
-		assert.Equal(t, model.StatusSolving, state.Status)
-		assert.NotNil(t, state.LastStartTime)
+		assert.Equal(t, model.StatusSelected, state.Status)
+		assert.NotNil(t, state.Puzzle)
+		assert.Equal(t, "The Washington Post", state.Puzzle.Publisher)
+		assert.Nil(t, state.LastStartTime)
 	})
+}
 
-	// Toggle the status again, it should transition to paused. Make sure we
-	// sleep for at least a nanosecond first so that the solve was unpaused for
-	// a non-zero duration.
-	time.Sleep(1 * time.Nanosecond)
-	response = Channel.PUT("/status", ``, router)
+func TestRoute_UpdatePuzzle_PuzFile(t *testing.T) {
+	// This acts as a small integration test uploading a .puz file of the
+	// crossword we're working on and ensuring the proper values are written to
+	// the database.
+	router, pool, registry := NewTestRouter(t)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	// Force a specific puzzle to be loaded so we don't make a network call.
+	ForcePuzzleToBeLoaded(t, "puzzle-wp-20051206.json")
+
+	response := Channel.PUT("/", `{"puz_file_bytes": "unused"}`, router)
 	assert.Equal(t, http.StatusOK, response.Code)
 	VerifyState(t, pool, events, func(state State) {
-		assert.Equal(t, model.StatusPaused, state.Status)
+		assert.Equal(t, model.StatusSelected, state.Status)
+		assert.NotNil(t, state.Puzzle)
+		assert.Equal(t, 0, len(state.AcrossCluesFilled))
+		assert.Equal(t, 0, len(state.DownCluesFilled))
 		assert.Nil(t, state.LastStartTime)
-		assert.True(t, state.TotalSolveDuration.Seconds() > 0.)
+		assert.Equal(t, 0., state.TotalSolveDuration.Seconds())
 	})
+}
 
-	// Toggle the status again, it should transition back to solving.
+func TestRoute_UpdatePuzzle_PuzFile_Prefilled(t *testing.T) {
+	// This acts as a small integration test uploading a .puz file that already
+	// has all of its answers filled in and ensuring that it's offered for review
+	// instead of being solved.
+	router, pool, registry := NewTestRouter(t)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	reader := load(t, path.Join("puz", "nyt-20081006-nonsquare-filled.puz"))
+	defer reader.Close()
+	bs, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+
+	body := fmt.Sprintf(`{"puz_file_bytes": "%s"}`, base64.StdEncoding.EncodeToString(bs))
+	response := Channel.PUT("/", body, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, model.StatusReview, state.Status)
+		assert.Equal(t, "O", state.Cells[0][0])
+		assert.True(t, state.AcrossCluesFilled[1])
+		assert.True(t, state.DownCluesFilled[1])
+	})
+
+	// A puzzle being reviewed can't be started.
 	response = Channel.PUT("/status", ``, router)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestRoute_UpdatePuzzle_IPuzFile(t *testing.T) {
+	// This acts as a small integration test uploading a .ipuz file and ensuring
+	// the proper values are written to the database.
+	router, pool, registry := NewTestRouter(t)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	reader := load(t, path.Join("ipuz", "blocks-and-circles.ipuz"))
+	defer reader.Close()
+	bs, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+
+	body := fmt.Sprintf(`{"ipuz_file_bytes": "%s"}`, base64.StdEncoding.EncodeToString(bs))
+	response := Channel.PUT("/", body, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, model.StatusSelected, state.Status)
+		assert.Equal(t, "Blocks and Circles", state.Puzzle.Title)
+		assert.Equal(t, "Taxi", state.Puzzle.CluesAcross[1])
+		assert.True(t, state.Puzzle.CellCircles[1][1])
+	})
+}
+
+func TestRoute_UpdatePuzzle_AcrossLiteText(t *testing.T) {
+	// This acts as a small integration test pasting an Across Lite text puzzle
+	// and ensuring the proper values are written to the database.
+	router, pool, registry := NewTestRouter(t)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	reader := load(t, path.Join("acrosslite", "rebus-and-circles.txt"))
+	defer reader.Close()
+	bs, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+
+	body, err := json.Marshal(map[string]string{"text_puzzle": string(bs)})
+	require.NoError(t, err)
+
+	response := Channel.PUT("/", string(body), router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, model.StatusSelected, state.Status)
+		assert.Equal(t, "Card Tricks", state.Puzzle.Title)
+		assert.Equal(t, "Region", state.Puzzle.CluesAcross[5])
+		assert.True(t, state.Puzzle.CellCircles[2][2])
+	})
+
+	// Text that isn't a puzzle can't be loaded.
+	response = Channel.PUT("/?force=true", `{"text_puzzle": "not a puzzle"}`, router)
+	require.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
+func TestRoute_UpdatePuzzle_PuzURL(t *testing.T) {
+	// This acts as a small integration test retrieving a .puz file from a URL of
+	// the crossword we're working on and ensuring the proper values are written
+	// to the database.
+	router, pool, registry := NewTestRouter(t)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	// Force a specific puzzle to be loaded so we don't make a network call.
+	ForcePuzzleToBeLoaded(t, "puzzle-wp-20051206.json")
+
+	response := Channel.PUT("/", `{"puz_file_url": "unused"}`, router)
 	assert.Equal(t, http.StatusOK, response.Code)
 	VerifyState(t, pool, events, func(state State) {
-		assert.Equal(t, model.StatusSolving, state.Status)
-		assert.NotNil(t, state.LastStartTime)
-		assert.True(t, state.TotalSolveDuration.Seconds() > 0.)
+		assert.Equal(t, model.StatusSelected, state.Status)
+		assert.NotNil(t, state.Puzzle)
+		assert.Equal(t, 0, len(state.AcrossCluesFilled))
+		assert.Equal(t, 0, len(state.DownCluesFilled))
+		assert.Nil(t, state.LastStartTime)
+		assert.Equal(t, 0., state.TotalSolveDuration.Seconds())
 	})
+}
 
-	// Force the puzzle to be complete.
-	state.Status = model.StatusComplete
+func TestRoute_UpdatePuzzle_Archive(t *testing.T) {
+	// This acts as a small integration test loading a puzzle from the community
+	// archive and ensuring the proper values are written to the database.
+	router, pool, registry := NewTestRouter(t)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	// Force a specific puzzle to be loaded so we don't make a network call.
+	ForcePuzzleToBeLoaded(t, "puzzle-wp-20051206.json")
+
+	response := Channel.PUT("/", `{"archive_id": "wp-20051206"}`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, model.StatusSelected, state.Status)
+		assert.NotNil(t, state.Puzzle)
+	})
+}
+
+func TestRoute_UpdatePuzzle_ResetsEphemeralState(t *testing.T) {
+	// This acts as a small integration test ensuring that selecting a new puzzle
+	// clears out all of the progress from the previous solve while leaving the
+	// channel's settings untouched.
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	settings := Settings{OnlyAllowCorrectAnswers: true, ClueFontSize: model.FontSizeLarge}
+	require.NoError(t, SetSettings(conn, Channel.name, settings))
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, state.ApplyAnswer("1a", "QANDA", false))
+	require.NoError(t, state.FocusClue("1a"))
+	state.TotalSolveDuration = model.Duration{Duration: 10 * time.Minute}
 	require.NoError(t, SetState(conn, Channel.name, state))
 
-	// Try to toggle the status one more time.  Now that the puzzle is complete
-	// it should return an HTTP error.
-	response = Channel.PUT("/status", ``, router)
-	assert.Equal(t, http.StatusBadRequest, response.Code)
+	ForcePuzzleToBeLoaded(t, "xwordinfo-nyt-20181231.json")
+
+	response := Channel.PUT("/?force=true", `{"new_york_times_date": "2018-12-31"}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, model.StatusSelected, state.Status)
+		assert.Equal(t, "", state.Cells[0][0])
+		assert.Equal(t, 0, len(state.AcrossCluesFilled))
+		assert.Equal(t, 0, len(state.DownCluesFilled))
+		assert.Nil(t, state.LastStartTime)
+		assert.Equal(t, 0., state.TotalSolveDuration.Seconds())
+		assert.Equal(t, "", state.FocusedClue)
+	})
+
+	actual, err := GetSettings(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, settings, actual)
+}
+
+func TestRoute_UpdatePuzzle_SamePuzzleKeepsProgress(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	ForcePuzzleToBeLoaded(t, "xwordinfo-nyt-20181231.json")
+
+	// Select the puzzle and make some progress on it.
+	response := Channel.PUT("/", `{"new_york_times_date": "2018-12-31"}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	Events(events, "state")
+
 	state, err := GetState(conn, Channel.name)
 	require.NoError(t, err)
-	assert.Equal(t, model.StatusComplete, state.Status)
+	state.Status = model.StatusSolving
+	require.NoError(t, state.ApplyAnswer("1a", "QANDA", false))
+	state.TotalSolveDuration = model.Duration{Duration: 10 * time.Minute}
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// Selecting the same puzzle again leaves the progress alone.
+	response = Channel.PUT("/", `{"new_york_times_date": "2018-12-31"}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Empty(t, Events(events, "state"))
+
+	state, err = GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusSolving, state.Status)
+	assert.Equal(t, "Q", state.Cells[0][0])
+	assert.Equal(t, 10*time.Minute, state.TotalSolveDuration.Duration)
+
+	// Selecting the same puzzle as a practice solve starts it over.
+	response = Channel.PUT("/?practice=true", `{"new_york_times_date": "2018-12-31"}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, model.StatusSelected, state.Status)
+		assert.True(t, state.Practice)
+		assert.Equal(t, "", state.Cells[0][0])
+	})
+
+	// Selecting a different puzzle resets the progress.
+	ForcePuzzleToBeLoaded(t, "xwordinfo-nyt-20181227-rebus.json")
+
+	response = Channel.PUT("/", `{"new_york_times_date": "2018-12-27"}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, model.StatusSelected, state.Status)
+		assert.Equal(t, "", state.Cells[0][0])
+	})
 }
 
-func TestRoute_ToggleStatus_Error(t *testing.T) {
+func TestRoute_UpdatePuzzle_JSONError(t *testing.T) {
 	tests := []struct {
-		name           string
-		initialStatus  model.Status
-		loadStateError error
-		saveStateError error
+		name     string
+		json     string
+		expected int
+	}{
+		{
+			name:     "bad json",
+			json:     `{"new_york_times_date": }`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid json",
+			json:     `{}`,
+			expected: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, _, _ := NewTestRouter(t)
+			ForcePuzzleToBeLoaded(t, "xwordinfo-nyt-20181231.json")
+
+			response := Channel.PUT("/", test.json, router)
+			assert.Equal(t, test.expected, response.Code)
+		})
+	}
+}
+
+func TestRoute_UpdatePuzzle_LoadSaveError(t *testing.T) {
+	tests := []struct {
+		name                 string
+		json                 string
+		forcePuzzleLoadError error
+		forceStateLoadError  error
+		forceStateSaveError  error
+		expected             int
 	}{
 		{
-			name:          "status created",
-			initialStatus: model.StatusCreated,
+			name:                 "nyt error loading puzzle",
+			json:                 `{"new_york_times_date": "unused"}`,
+			forcePuzzleLoadError: errors.New("forced error"),
+			expected:             http.StatusInternalServerError,
+		},
+		{
+			name:                 "nyt mini error loading puzzle",
+			json:                 `{"new_york_times_mini_date": "unused"}`,
+			forcePuzzleLoadError: errors.New("forced error"),
+			expected:             http.StatusInternalServerError,
+		},
+		{
+			name:                 "atlantic error loading puzzle",
+			json:                 `{"atlantic_date": "unused"}`,
+			forcePuzzleLoadError: errors.New("forced error"),
+			expected:             http.StatusInternalServerError,
+		},
+		{
+			name:                 "jonesin error loading puzzle",
+			json:                 `{"jonesin_date": "unused"}`,
+			forcePuzzleLoadError: errors.New("forced error"),
+			expected:             http.StatusInternalServerError,
+		},
+		{
+			name:                 "wsj error loading puzzle",
+			json:                 `{"wall_street_journal_date": "unused"}`,
+			forcePuzzleLoadError: errors.New("forced error"),
+			expected:             http.StatusInternalServerError,
+		},
+		{
+			name:                 "wp error loading puzzle",
+			json:                 `{"washington_post_date": "unused"}`,
+			forcePuzzleLoadError: errors.New("forced error"),
+			expected:             http.StatusInternalServerError,
+		},
+		{
+			name:                 "archive error loading puzzle",
+			json:                 `{"archive_id": "unused"}`,
+			forcePuzzleLoadError: errors.New("forced error"),
+			expected:             http.StatusInternalServerError,
+		},
+		{
+			name:                 "archive unknown id",
+			json:                 `{"archive_id": "unused"}`,
+			forcePuzzleLoadError: ErrUnknownArchiveID,
+			expected:             http.StatusNotFound,
+		},
+		{
+			name:     "archive invalid id",
+			json:     `{"archive_id": "not a valid id"}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:                 "puz bytes error loading puzzle",
+			json:                 `{"puz_file_bytes": "unused"}`,
+			forcePuzzleLoadError: errors.New("forced error"),
+			expected:             http.StatusInternalServerError,
+		},
+		{
+			name:                 "puz url error loading puzzle",
+			json:                 `{"puz_file_url": "unused"}`,
+			forcePuzzleLoadError: errors.New("forced error"),
+			expected:             http.StatusInternalServerError,
+		},
+		{
+			name:                "error loading state",
+			json:                `{"new_york_times_date": "unused"}`,
+			forceStateLoadError: errors.New("forced error"),
+			expected:            http.StatusInternalServerError,
+		},
+		{
+			name:                "error saving state",
+			json:                `{"new_york_times_date": "unused"}`,
+			forceStateSaveError: errors.New("forced error"),
+			expected:            http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, _, _ := NewTestRouter(t)
+
+			if test.forcePuzzleLoadError != nil {
+				ForceErrorDuringPuzzleLoad(t, test.forcePuzzleLoadError)
+			} else {
+				ForcePuzzleToBeLoaded(t, "xwordinfo-nyt-20181231.json")
+			}
+
+			ForceErrorDuringStateLoad(t, test.forceStateLoadError)
+			ForceErrorDuringStateSave(t, test.forceStateSaveError)
+
+			response := Channel.PUT("/", test.json, router)
+			assert.Equal(t, test.expected, response.Code)
+		})
+	}
+}
+
+func TestRoute_PreviewPuzzle_NewYorkTimes(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	// Force a specific puzzle to be loaded so we don't make a network call.
+	ForcePuzzleToBeLoaded(t, "xwordinfo-nyt-20181231.json")
+
+	response := POST("/crossword/preview", `{"new_york_times_date": "2018-12-31"}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var state State
+	require.NoError(t, render.DecodeJSON(response.Body, &state))
+	assert.Equal(t, model.StatusSelected, state.Status)
+	require.NotNil(t, state.Puzzle)
+	assert.Equal(t, "New York Times puzzle from 2018-12-31", state.Puzzle.Description)
+	assert.Equal(t, "The New York Times", state.Puzzle.Publisher)
+	assert.Equal(t, 15, state.Puzzle.Rows)
+	assert.Equal(t, 15, state.Puzzle.Cols)
+	assert.Len(t, state.Cells, 15)
+	assert.Len(t, state.Cells[0], 15)
+	assert.Equal(t, "Exchange after a lecture, informally", state.Puzzle.CluesAcross[1])
+
+	// The solution isn't revealed.
+	assert.Nil(t, state.Puzzle.Cells)
+
+	// Nothing was stored or broadcast.
+	assert.Empty(t, Events(events, "state"))
+	keys, err := redis.Strings(conn.Do("KEYS", "*"))
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestRoute_PreviewPuzzle_IPuzFile(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	reader := load(t, path.Join("ipuz", "blocks-and-circles.ipuz"))
+	defer reader.Close()
+	bs, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+
+	body := fmt.Sprintf(`{"ipuz_file_bytes": "%s"}`, base64.StdEncoding.EncodeToString(bs))
+	response := POST("/crossword/preview", body, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var state State
+	require.NoError(t, render.DecodeJSON(response.Body, &state))
+	require.NotNil(t, state.Puzzle)
+	assert.Equal(t, "Blocks and Circles", state.Puzzle.Title)
+	assert.Equal(t, "Taxi", state.Puzzle.CluesAcross[1])
+	assert.True(t, state.Puzzle.CellCircles[1][1])
+	assert.Len(t, state.Cells, state.Puzzle.Rows)
+	assert.Len(t, state.Cells[0], state.Puzzle.Cols)
+	assert.Nil(t, state.Puzzle.Cells)
+
+	keys, err := redis.Strings(conn.Do("KEYS", "*"))
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestRoute_PreviewPuzzle_Error(t *testing.T) {
+	tests := []struct {
+		name           string
+		json           string
+		forceLoadError error
+		expected       int
+	}{
+		{
+			name:     "bad json",
+			json:     `{"new_york_times_date": }`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "no source",
+			json:     `{}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "no puzzle on date",
+			json:     `{"wall_street_journal_date": "2019-01-01"}`,
+			expected: http.StatusNotFound,
+		},
+		{
+			name:           "error loading puzzle",
+			json:           `{"new_york_times_date": "2018-12-31"}`,
+			forceLoadError: errors.New("forced error"),
+			expected:       http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, _, _ := NewTestRouter(t)
+			if test.forceLoadError != nil {
+				ForceErrorDuringPuzzleLoad(t, test.forceLoadError)
+			} else {
+				ForcePuzzleToBeLoaded(t, "xwordinfo-nyt-20181231.json")
+			}
+
+			response := POST("/crossword/preview", test.json, router)
+			assert.Equal(t, test.expected, response.Code)
+		})
+	}
+}
+
+func TestRoute_ReadStreak(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	// A channel that has never completed a crossword has no streak.
+	response := Channel.GET("/streak", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var streak Streak
+	require.NoError(t, render.DecodeJSON(response.Body, &streak))
+	assert.Equal(t, 0, streak.Count)
+
+	// Completions yesterday and today make a streak of two.
+	now := time.Now()
+	_, err := UpdateStreak(conn, Channel.name, now.AddDate(0, 0, -1))
+	require.NoError(t, err)
+	_, err = UpdateStreak(conn, Channel.name, now)
+	require.NoError(t, err)
+
+	response = Channel.GET("/streak", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, render.DecodeJSON(response.Body, &streak))
+	assert.Equal(t, 2, streak.Count)
+	assert.Equal(t, 2, streak.Best)
+
+	// A streak whose last completion was days ago has been broken.
+	require.NoError(t, SetStreak(conn, Channel.name, Streak{
+		Count:         5,
+		Best:          5,
+		LastSolveDate: now.AddDate(0, 0, -3),
+	}))
+
+	response = Channel.GET("/streak", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, render.DecodeJSON(response.Body, &streak))
+	assert.Equal(t, 0, streak.Count)
+	assert.Equal(t, 5, streak.Best)
+}
+
+func TestRoute_GetSummary(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	// A channel that has never completed a crossword has an empty summary.
+	response := Channel.GET("/summary", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var summary Summary
+	require.NoError(t, render.DecodeJSON(response.Body, &summary))
+	assert.Equal(t, 0, summary.Count)
+	assert.Equal(t, time.Duration(0), summary.MeanDuration.Duration)
+	assert.Empty(t, summary.ByPublisher)
+	assert.Equal(t, 0, summary.ByWeekday["Monday"])
+
+	monday := time.Date(2018, time.December, 31, 0, 0, 0, 0, time.UTC)
+	saturday := time.Date(2018, time.December, 29, 0, 0, 0, 0, time.UTC)
+	for _, record := range []SolveRecord{
+		{
+			Status:        model.StatusComplete,
+			Publisher:     "The New York Times",
+			PublishedDate: monday,
+			Duration:      model.Duration{Duration: 10 * time.Minute},
+		},
+		{
+			Status:        model.StatusComplete,
+			Publisher:     "The New York Times",
+			PublishedDate: saturday,
+			Duration:      model.Duration{Duration: 50 * time.Minute},
+		},
+		{
+			Status:        model.StatusComplete,
+			Publisher:     "The Wall Street Journal",
+			PublishedDate: monday,
+			Duration:      model.Duration{Duration: 15 * time.Minute},
+		},
+		{
+			// Abandoned solves aren't included.
+			Status:        model.StatusAbandoned,
+			Publisher:     "The New York Times",
+			PublishedDate: saturday,
+			Duration:      model.Duration{Duration: 2 * time.Hour},
+		},
+		{
+			// Neither are the publisher or weekday of puzzles that don't have one.
+			Status:   model.StatusComplete,
+			Duration: model.Duration{Duration: 5 * time.Minute},
+		},
+	} {
+		require.NoError(t, RecordSolve(conn, Channel.name, record))
+	}
+
+	response = Channel.GET("/summary", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, render.DecodeJSON(response.Body, &summary))
+	assert.Equal(t, 4, summary.Count)
+	assert.Equal(t, 20*time.Minute, summary.MeanDuration.Duration)
+	assert.Equal(t, 5*time.Minute, summary.FastestDuration.Duration)
+	assert.Equal(t, 50*time.Minute, summary.SlowestDuration.Duration)
+	assert.Equal(t, map[string]int{
+		"The New York Times":      2,
+		"The Wall Street Journal": 1,
+	}, summary.ByPublisher)
+	assert.Equal(t, map[string]int{
+		"Sunday":    0,
+		"Monday":    2,
+		"Tuesday":   0,
+		"Wednesday": 0,
+		"Thursday":  0,
+		"Friday":    0,
+		"Saturday":  1,
+	}, summary.ByWeekday)
+}
+
+func TestRoute_ToggleStatus(t *testing.T) {
+	// This acts as a small integration test toggling the status of a crossword
+	// being solved.
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	// Set a state that has a puzzle selected but not yet started.
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// Toggle the status, it should transition to solving.
+	response := Channel.PUT("/status", ``, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, model.StatusSolving, state.Status)
+		assert.NotNil(t, state.LastStartTime)
+	})
+
+	// Toggle the status again, it should transition to paused. Make sure we
+	// sleep for at least a nanosecond first so that the solve was unpaused for
+	// a non-zero duration.
+	time.Sleep(1 * time.Nanosecond)
+	response = Channel.PUT("/status", ``, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, model.StatusPaused, state.Status)
+		assert.Nil(t, state.LastStartTime)
+		assert.True(t, state.TotalSolveDuration.Seconds() > 0.)
+	})
+
+	// Toggle the status again, it should transition back to solving.
+	response = Channel.PUT("/status", ``, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, model.StatusSolving, state.Status)
+		assert.NotNil(t, state.LastStartTime)
+		assert.True(t, state.TotalSolveDuration.Seconds() > 0.)
+	})
+
+	// Force the puzzle to be complete.
+	state.Status = model.StatusComplete
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// Try to toggle the status one more time.  Now that the puzzle is complete
+	// it should return an HTTP error.
+	response = Channel.PUT("/status", ``, router)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	state, err := GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusComplete, state.Status)
+}
+
+func TestRoute_ToggleStatus_Error(t *testing.T) {
+	tests := []struct {
+		name              string
+		initialStatus     model.Status
+		loadStateError    error
+		saveStateError    error
+		loadSettingsError error
+	}{
+		{
+			name:          "status created",
+			initialStatus: model.StatusCreated,
+		},
+		{
+			name:           "error loading state",
+			initialStatus:  model.StatusSelected,
+			loadStateError: errors.New("forced error"),
+		},
+		{
+			name:           "error saving state",
+			initialStatus:  model.StatusSelected,
+			saveStateError: errors.New("forced error"),
+		},
+		{
+			name:              "error loading settings",
+			initialStatus:     model.StatusSelected,
+			loadSettingsError: errors.New("forced error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, _ := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.Status = test.initialStatus
+			require.NoError(t, SetState(conn, Channel.name, state))
+
+			if test.loadStateError != nil {
+				ForceErrorDuringStateLoad(t, test.loadStateError)
+			}
+
+			if test.loadSettingsError != nil {
+				ForceErrorDuringSettingsLoad(t, test.loadSettingsError)
+			}
+
+			if test.saveStateError != nil {
+				ForceErrorDuringStateSave(t, test.saveStateError)
+			}
+
+			response := Channel.PUT("/status", "", router)
+			assert.NotEqual(t, http.StatusOK, response.Code)
+		})
+	}
+}
+
+func TestRoute_ToggleStatus_MinToggleInterval(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	settings := Settings{MinToggleIntervalMillis: 60 * 1000}
+	require.NoError(t, SetSettings(conn, Channel.name, settings))
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// The first toggle starts the solve.
+	response := Channel.PUT("/status", ``, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, model.StatusSolving, state.Status)
+		assert.NotNil(t, state.LastToggleTime)
+	})
+
+	// The second toggle comes right after the first, so it's ignored and the
+	// solve keeps going.
+	response = Channel.PUT("/status", ``, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Empty(t, Events(events, "state"))
+
+	state, err := GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusSolving, state.Status)
+	assert.NotNil(t, state.LastStartTime)
+
+	// Once the interval has passed the status can be toggled again.
+	last := time.Now().Add(-2 * time.Minute)
+	state.LastToggleTime = &last
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response = Channel.PUT("/status", ``, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, model.StatusPaused, state.Status)
+	})
+}
+
+func TestRoute_ToggleStatus_NoPuzzleSelected(t *testing.T) {
+	router, _, _ := NewTestRouter(t)
+
+	// The channel has never selected a puzzle so there's no state stored.
+	response := Channel.PUT("/status", "", router)
+	assert.Equal(t, http.StatusConflict, response.Code)
+	assert.Equal(t, "no puzzle selected", strings.TrimSpace(response.Body.String()))
+}
+
+func TestRoute_AbandonPuzzle(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	// Setup a solve that has been going for 15 minutes, 10 of which were since
+	// it was last resumed.
+	start := time.Now().Add(-10 * time.Minute)
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	state.LastStartTime = &start
+	state.TotalSolveDuration = model.Duration{Duration: 5 * time.Minute}
+	state.Reveals = 2
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response := Channel.PUT("/abandon", "", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, model.StatusAbandoned, state.Status)
+		assert.Nil(t, state.LastStartTime)
+		assert.True(t, state.TotalSolveDuration.Duration >= 15*time.Minute)
+	})
+
+	// The solve is recorded in the stats as abandoned.
+	records, err := GetSolveRecords(conn, Channel.name, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(records))
+	assert.Equal(t, model.StatusAbandoned, records[0].Status)
+	assert.Equal(t, "New York Times puzzle from 2018-12-31", records[0].Description)
+	assert.True(t, records[0].Duration.Duration >= 15*time.Minute)
+	assert.Equal(t, 2, records[0].Reveals)
+
+	// Abandoning a solve doesn't count towards the solve streak.
+	streak, err := GetStreak(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, 0, streak.Count)
+
+	// The channel is no longer active.
+	activities, err := model.GetRecentActivity(conn)
+	require.NoError(t, err)
+	assert.Empty(t, activities)
+
+	channels, err := GetAllChannels(conn)
+	require.NoError(t, err)
+	assert.Empty(t, channels)
+
+	// An abandoned solve can't be resumed.
+	response = Channel.PUT("/status", "", router)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestRoute_AbandonPuzzle_Error(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         model.Status
+		loadStateError error
+		saveStateError error
+		expected       int
+	}{
+		{
+			name:     "status created",
+			status:   model.StatusCreated,
+			expected: http.StatusConflict,
+		},
+		{
+			name:     "status complete",
+			status:   model.StatusComplete,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "status review",
+			status:   model.StatusReview,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "status abandoned",
+			status:   model.StatusAbandoned,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:           "error loading state",
+			status:         model.StatusSolving,
+			loadStateError: errors.New("forced error"),
+			expected:       http.StatusInternalServerError,
+		},
+		{
+			name:           "error saving state",
+			status:         model.StatusSolving,
+			saveStateError: errors.New("forced error"),
+			expected:       http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, registry := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+			events := NewEventSubscription(t, registry, Channel.name)
+
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.Status = test.status
+			require.NoError(t, SetState(conn, Channel.name, state))
+
+			ForceErrorDuringStateLoad(t, test.loadStateError)
+			ForceErrorDuringStateSave(t, test.saveStateError)
+
+			response := Channel.PUT("/abandon", "", router)
+			assert.Equal(t, test.expected, response.Code)
+			assert.Empty(t, Events(events, "state"))
+
+			// Nothing is recorded in the stats.
+			records, err := GetSolveRecords(conn, Channel.name, 10)
+			require.NoError(t, err)
+			assert.Empty(t, records)
+		})
+	}
+
+	// A channel that has never selected a puzzle can't abandon it.
+	router, _, _ := NewTestRouter(t)
+	response := Channel.PUT("/abandon", "", router)
+	assert.Equal(t, http.StatusConflict, response.Code)
+	assert.Equal(t, "no puzzle selected", strings.TrimSpace(response.Body.String()))
+}
+
+func TestRoute_UpdateAnswer_AllowIncorrectAnswers(t *testing.T) {
+	// This acts as a small integration test of applying answers to a crossword
+	// being solved.
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// Apply a correct across answer.
+	response := Channel.PUT("/answer/1a", `"QANDA"`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.True(t, state.AcrossCluesFilled[1])
+		assert.Equal(t, "Q", state.Cells[0][0])
+		assert.Equal(t, "A", state.Cells[0][1])
+		assert.Equal(t, "N", state.Cells[0][2])
+		assert.Equal(t, "D", state.Cells[0][3])
+		assert.Equal(t, "A", state.Cells[0][4])
+	})
+
+	// Apply a correct down answer.
+	response = Channel.PUT("/answer/1d", `"QTIP"`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.True(t, state.DownCluesFilled[1])
+		assert.Equal(t, "Q", state.Cells[0][0])
+		assert.Equal(t, "T", state.Cells[1][0])
+		assert.Equal(t, "I", state.Cells[2][0])
+		assert.Equal(t, "P", state.Cells[3][0])
+	})
+
+	// Apply an incorrect across answer.
+	response = Channel.PUT("/answer/6a", `"FLOOR"`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.True(t, state.AcrossCluesFilled[6])
+		assert.Equal(t, "F", state.Cells[0][6])
+		assert.Equal(t, "L", state.Cells[0][7])
+		assert.Equal(t, "O", state.Cells[0][8])
+		assert.Equal(t, "O", state.Cells[0][9])
+		assert.Equal(t, "R", state.Cells[0][10])
+	})
+
+	// Apply an incorrect down answer.
+	response = Channel.PUT("/answer/11d", `"HEYA"`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.True(t, state.DownCluesFilled[11])
+		assert.Equal(t, "H", state.Cells[0][12])
+		assert.Equal(t, "E", state.Cells[1][12])
+		assert.Equal(t, "Y", state.Cells[2][12])
+		assert.Equal(t, "A", state.Cells[3][12])
+	})
+
+	// Pause the solve.
+	response = Channel.PUT("/status", ``, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	// Try to apply an answer.
+	response = Channel.PUT("/answer/6a", `"ATTIC"`, router)
+	assert.Equal(t, http.StatusConflict, response.Code)
+}
+
+func TestRoute_UpdateAnswer_EmoteLetters(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	settings := Settings{
+		OnlyAllowCorrectAnswers: true,
+		EmoteLetters: map[string]string{
+			"myChannelA": "A",
+			"myChannelD": "D",
+			"myChannelN": "N",
+			"myChannelQ": "Q",
+		},
+	}
+	require.NoError(t, SetSettings(conn, Channel.name, settings))
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// An answer written entirely as emotes is translated into letters.
+	response := Channel.PUT("/answer/1a", `"myChannelQ myChannelA myChannelN myChannelD myChannelA"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.True(t, state.AcrossCluesFilled[1])
+		assert.Equal(t, []string{"Q", "A", "N", "D", "A"}, state.Cells[0][0:5])
+	})
+
+	// Normal text is left untouched.
+	response = Channel.PUT("/answer/1d", `"QTIP"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.True(t, state.DownCluesFilled[1])
+		assert.Equal(t, "T", state.Cells[1][0])
+	})
+
+	// An answer that's only partially emotes isn't translated, so it doesn't fit.
+	response = Channel.PUT("/answer/6a", `"myChannelA TTIC"`, router)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestRoute_UpdateAnswer_AuditAnswers(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		expected []AuditEntry
+	}{
+		{
+			name:    "enabled",
+			enabled: true,
+			expected: []AuditEntry{
+				{User: "bob", Clue: "6a", Answer: "XYZ", Correct: false},
+				{User: "alice", Clue: "1a", Answer: "QANDA", Correct: true},
+			},
+		},
+		{
+			name:     "disabled",
+			enabled:  false,
+			expected: []AuditEntry{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, _ := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+			ForceAdminToken(t, "secret")
+
+			settings := Settings{AuditAnswers: test.enabled}
+			require.NoError(t, SetSettings(conn, Channel.name, settings))
+
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.Status = model.StatusSolving
+			require.NoError(t, SetState(conn, Channel.name, state))
+
+			response := Channel.PUT("/answer/1a?user=alice", `"QANDA"`, router)
+			require.Equal(t, http.StatusOK, response.Code)
+
+			// Answers that can't be applied are still recorded.
+			response = Channel.PUT("/answer/6a?user=bob", `"XYZ"`, router)
+			require.Equal(t, http.StatusBadRequest, response.Code)
+
+			response = Channel.ADMIN(http.MethodGet, "/audit", ``, router)
+			require.Equal(t, http.StatusOK, response.Code)
+
+			var entries []AuditEntry
+			require.NoError(t, render.DecodeJSON(response.Result().Body, &entries))
+			for i := range entries {
+				assert.False(t, entries[i].Time.IsZero())
+				entries[i].Time = time.Time{}
+			}
+			assert.Equal(t, test.expected, entries)
+		})
+	}
+}
+
+func TestRoute_UpdateAnswer_Metadata(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	settings := Settings{AuditAnswers: true}
+	require.NoError(t, SetSettings(conn, Channel.name, settings))
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response := Channel.PUT("/answer/1a?user=alice&user_id=123&bits=100", `"QANDA"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	// An answer without a cheer doesn't have any bits to attribute.
+	response = Channel.PUT("/answer/6a?user=bob&user_id=456", `"ATTIC"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	// A later cheer for an already solved clue doesn't replace the original.
+	response = Channel.PUT("/answer/1a?user=carol&user_id=789&bits=5", `"QANDA"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	state, err := GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"1a": 100}, state.ClueCheers)
+	assert.Equal(t, "alice", state.ClueSolvers["1a"])
+
+	entries, err := GetAuditEntries(conn, Channel.name, 10)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(entries))
+	assert.Equal(t, "789", entries[0].UserID)
+	assert.Equal(t, 5, entries[0].Bits)
+	assert.Equal(t, "456", entries[1].UserID)
+	assert.Equal(t, 0, entries[1].Bits)
+	assert.Equal(t, "123", entries[2].UserID)
+	assert.Equal(t, 100, entries[2].Bits)
+
+	// Bits must be a non-negative number.
+	for _, bits := range []string{"abc", "-1"} {
+		response = Channel.PUT("/answer/1d?bits="+bits, `"QTIP"`, router)
+		assert.Equal(t, http.StatusBadRequest, response.Code, bits)
+	}
+
+	// Users must look like Twitch users in order to be credited.
+	for _, query := range []string{
+		"user=" + url.QueryEscape("<script>"),
+		"user=" + url.QueryEscape("alice bob"),
+		"user=" + strings.Repeat("a", 26),
+		"user=alice&user_id=abc",
+	} {
+		response = Channel.PUT("/answer/1d?"+query, `"QTIP"`, router)
+		assert.Equal(t, http.StatusBadRequest, response.Code, query)
+	}
+
+	// Localized display names are allowed.
+	response = Channel.PUT("/answer/1d?user="+url.QueryEscape("ゆき_01"), `"QTIP"`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+}
+
+func TestRoute_GetAuditLog(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	ForceAdminToken(t, "secret")
+
+	for _, clue := range []string{"1a", "6a", "10a"} {
+		require.NoError(t, RecordAuditEntry(conn, Channel.name, AuditEntry{Clue: clue}))
+	}
+
+	// Only administrators can read the audit log.
+	response := Channel.GET("/audit?limit=2", router)
+	require.Equal(t, http.StatusUnauthorized, response.Code)
+
+	response = Channel.ADMIN(http.MethodGet, "/audit?limit=2", ``, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var entries []AuditEntry
+	require.NoError(t, render.DecodeJSON(response.Result().Body, &entries))
+	require.Equal(t, 2, len(entries))
+	assert.Equal(t, "10a", entries[0].Clue)
+	assert.Equal(t, "6a", entries[1].Clue)
+
+	response = Channel.ADMIN(http.MethodGet, "/audit?limit=0", ``, router)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	response = Channel.ADMIN(http.MethodGet, "/audit?limit=abc", ``, router)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestRoute_UpdateAnswerByNumber(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// Apply an across answer.
+	response := Channel.PUT("/number/1/across", `"QANDA"`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.True(t, state.AcrossCluesFilled[1])
+		assert.Equal(t, "Q", state.Cells[0][0])
+		assert.Equal(t, "A", state.Cells[0][4])
+	})
+
+	// Apply a down answer starting at the same cell.
+	response = Channel.PUT("/number/1/down", `"QTIP"`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.True(t, state.DownCluesFilled[1])
+		assert.Equal(t, "T", state.Cells[1][0])
+		assert.Equal(t, "P", state.Cells[3][0])
+	})
+}
+
+func TestRoute_UpdateAnswerByNumber_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected int
+	}{
+		{
+			name:     "no across clue starts at cell",
+			url:      "/number/2/across",
+			expected: http.StatusNotFound,
+		},
+		{
+			name:     "no down clue starts at cell",
+			url:      "/number/14/down",
+			expected: http.StatusNotFound,
+		},
+		{
+			name:     "no cell with number",
+			url:      "/number/1000/across",
+			expected: http.StatusNotFound,
+		},
+		{
+			name:     "invalid number",
+			url:      "/number/one/across",
+			expected: http.StatusNotFound,
+		},
+		{
+			name:     "invalid direction",
+			url:      "/number/1/sideways",
+			expected: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, _ := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.Status = model.StatusSolving
+			require.NoError(t, SetState(conn, Channel.name, state))
+
+			response := Channel.PUT(test.url, `"QANDA"`, router)
+			assert.Equal(t, test.expected, response.Code)
+		})
+	}
+}
+
+func TestRoute_UpdateAnswerByClueText(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response := Channel.PUT("/answer-by-clue?text=under+the+roof", `"ATTIC"`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.True(t, state.AcrossCluesFilled[6])
+		assert.Equal(t, "A", state.Cells[0][6])
+		assert.Equal(t, "C", state.Cells[0][10])
+	})
+}
+
+func TestRoute_UpdateAnswerByClueText_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected int
+	}{
+		{
+			name:     "ambiguous clue text",
+			url:      "/answer-by-clue?text=room",
+			expected: http.StatusConflict,
+		},
+		{
+			name:     "no matching clue",
+			url:      "/answer-by-clue?text=zebra",
+			expected: http.StatusNotFound,
+		},
+		{
+			name:     "missing clue text",
+			url:      "/answer-by-clue",
+			expected: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, _ := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.Status = model.StatusSolving
+			require.NoError(t, SetState(conn, Channel.name, state))
+
+			response := Channel.PUT(test.url, `"ATTIC"`, router)
+			assert.Equal(t, test.expected, response.Code)
+		})
+	}
+}
+
+func TestRoute_UpdateAnswer_OnlyAllowCorrectAnswers(t *testing.T) {
+	// This acts as a small integration test toggling the status of a crossword
+	// being solved.
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	// Change the settings to only allow correct answers.
+	settings := Settings{OnlyAllowCorrectAnswers: true}
+	require.NoError(t, SetSettings(conn, Channel.name, settings))
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// Apply a correct across answer.
+	response := Channel.PUT("/answer/1a", `"QANDA"`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.True(t, state.AcrossCluesFilled[1])
+		assert.Equal(t, "Q", state.Cells[0][0])
+		assert.Equal(t, "A", state.Cells[0][1])
+		assert.Equal(t, "N", state.Cells[0][2])
+		assert.Equal(t, "D", state.Cells[0][3])
+		assert.Equal(t, "A", state.Cells[0][4])
+	})
+
+	// Apply a correct down answer.
+	response = Channel.PUT("/answer/1d", `"QTIP"`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.True(t, state.DownCluesFilled[1])
+		assert.Equal(t, "Q", state.Cells[0][0])
+		assert.Equal(t, "T", state.Cells[1][0])
+		assert.Equal(t, "I", state.Cells[2][0])
+		assert.Equal(t, "P", state.Cells[3][0])
+	})
+
+	// Apply an incorrect across answer.
+	response = Channel.PUT("/answer/6a", `"FLOOR"`, router)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	// Apply an incorrect down answer.
+	response = Channel.PUT("/answer/11d", `"HEYA"`, router)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestRoute_UpdateAnswer_ShowCorrectCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		clue     string
+		answer   string
+		expected string
+	}{
+		{
+			name:     "one letter wrong",
+			enabled:  true,
+			clue:     "6a",
+			answer:   "ATTIX",
+			expected: "4 of 5 letters correct",
+		},
+		{
+			name:     "swapped letters",
+			enabled:  true,
+			clue:     "6a",
+			answer:   "ATTCI",
+			expected: "3 of 5 letters correct",
+		},
+		{
+			name:     "no letters correct",
+			enabled:  true,
+			clue:     "6a",
+			answer:   "FLOOR",
+			expected: "0 of 5 letters correct",
+		},
+		{
+			name:     "changes correct value",
+			enabled:  true,
+			clue:     "1d",
+			answer:   "XTIP",
+			expected: "3 of 4 letters correct",
+		},
+		{
+			name:    "disabled",
+			enabled: false,
+			clue:    "6a",
+			answer:  "ATTIX",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, _ := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+
+			settings := Settings{OnlyAllowCorrectAnswers: true, ShowCorrectCount: test.enabled}
+			require.NoError(t, SetSettings(conn, Channel.name, settings))
+
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.Status = model.StatusSolving
+			require.NoError(t, state.ApplyAnswer("1a", "QANDA", true))
+			require.NoError(t, SetState(conn, Channel.name, state))
+
+			response := Channel.PUT("/answer/"+test.clue, `"`+test.answer+`"`, router)
+			require.Equal(t, http.StatusBadRequest, response.Code)
+			assert.Equal(t, test.expected, strings.TrimSpace(response.Body.String()))
+		})
+	}
+}
+
+func TestRoute_UpdateAnswer_SolvedPuzzleStopsTimer(t *testing.T) {
+	// This acts as a small integration test ensuring that the timer stops
+	// counting once the crossword has been solved.
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	// Setup a state that has the entire puzzle solved except for the last answer.
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	state.ApplyAnswer("1a", "Q AND A", false)
+	state.ApplyAnswer("6a", "ATTIC", false)
+	state.ApplyAnswer("11a", "HON", false)
+	state.ApplyAnswer("14a", "THIRD", false)
+	state.ApplyAnswer("15a", "LAID ASIDE", false)
+	state.ApplyAnswer("17a", "IM TOO OLD FOR THIS", false)
+	state.ApplyAnswer("19a", "PERU", false)
+	state.ApplyAnswer("20a", "LEAF", false)
+	state.ApplyAnswer("21a", "PEONS", false)
+	state.ApplyAnswer("22a", "DOG TAG", false)
+	state.ApplyAnswer("24a", "LOL", false)
+	state.ApplyAnswer("25a", "HAVE NO OOMPH", false)
+	state.ApplyAnswer("30a", "MATTE", false)
+	state.ApplyAnswer("33a", "IMPLORED", false)
+	state.ApplyAnswer("35a", "ERR", false)
+	state.ApplyAnswer("36a", "RANGE", false)
+	state.ApplyAnswer("38a", "EMO", false)
+	state.ApplyAnswer("39a", "WAIT HERE", false)
+	state.ApplyAnswer("42a", "EGYPT", false)
+	state.ApplyAnswer("44a", "BOO OFF STAGE", false)
+	state.ApplyAnswer("47a", "ERS", false)
+	state.ApplyAnswer("48a", "EUGENE", false)
+	state.ApplyAnswer("51a", "SHARI", false)
+	state.ApplyAnswer("54a", "SINN", false)
+	state.ApplyAnswer("56a", "WING", false)
+	state.ApplyAnswer("58a", "ITS A ZOO OUT THERE", false)
+	state.ApplyAnswer("61a", "STEGOSAUR", false)
+	state.ApplyAnswer("62a", "HIT ON", false)
+	state.ApplyAnswer("63a", "IPA", false)
+	state.ApplyAnswer("64a", "NURSE", false)
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// Apply the last answer, but wait a bit first to ensure that a non-zero
+	// amount of time has passed in the solve.
+	time.Sleep(10 * time.Millisecond)
+
+	response := Channel.PUT("/answer/65a", `"OZONE"`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		require.Equal(t, model.StatusComplete, state.Status)
+		assert.Nil(t, state.LastStartTime)
+		assert.True(t, state.TotalSolveDuration.Seconds() > 0)
+	})
+}
+
+func TestRoute_UpdateAnswer_FirstCorrectSolverCredited(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// Alice answers first and should be credited.
+	response := Channel.PUT("/answer/1a?user=alice", `"Q AND A"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, map[string]string{"1a": "alice"}, state.ClueSolvers)
+	})
+
+	// Bob resubmits the same correct answer, but Alice keeps the credit.
+	response = Channel.PUT("/answer/1a?user=bob", `"Q AND A"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, map[string]string{"1a": "alice"}, state.ClueSolvers)
+	})
+
+	// Bob submits an incorrect answer for a clue, nobody is credited.
+	response = Channel.PUT("/answer/6a?user=bob", `"ATTIX"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, map[string]string{"1a": "alice"}, state.ClueSolvers)
+	})
+
+	// Carol fixes the incorrect answer and is credited.
+	response = Channel.PUT("/answer/6a?user=carol", `"ATTIC"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, map[string]string{"1a": "alice", "6a": "carol"}, state.ClueSolvers)
+	})
+
+	// An answer without a user doesn't credit anybody.
+	response = Channel.PUT("/answer/11a", `"HON"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, map[string]string{"1a": "alice", "6a": "carol"}, state.ClueSolvers)
+	})
+}
+
+func TestRoute_UpdateAnswer_AutoAdvanceOnComplete(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		expected string
+	}{
+		{
+			name:     "enabled",
+			enabled:  true,
+			expected: "2018-12-30",
+		},
+		{
+			name:     "disabled",
+			enabled:  false,
+			expected: "2018-12-31",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, registry := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+			events := NewEventSubscription(t, registry, Channel.name)
+
+			delay := AutoAdvanceDelay
+			AutoAdvanceDelay = 0
+			t.Cleanup(func() { AutoAdvanceDelay = delay })
+
+			ForcePuzzleLoader(t, "new_york_times", func(date string) (*Puzzle, error) {
+				published, err := time.Parse("2006-01-02", date)
+				require.NoError(t, err)
+
+				puzzle := LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json")
+				puzzle.PublishedDate = published
+				return puzzle, nil
+			})
+			require.NoError(t, SetSettings(conn, Channel.name, Settings{AutoAdvanceOnComplete: test.enabled}))
+
+			response := Channel.PUT("/", `{"new_york_times_date": "2018-12-31"}`, router)
+			require.Equal(t, http.StatusOK, response.Code)
+
+			// Solve the entire puzzle except for the last answer.
+			state, err := GetState(conn, Channel.name)
+			require.NoError(t, err)
+			require.Equal(t, "new_york_times", state.Source)
+			now := time.Now()
+			state.Status = model.StatusSolving
+			state.LastStartTime = &now
+			for _, answer := range []struct{ clue, answer string }{
+				{"1a", "Q AND A"}, {"6a", "ATTIC"}, {"11a", "HON"}, {"14a", "THIRD"},
+				{"15a", "LAID ASIDE"}, {"17a", "IM TOO OLD FOR THIS"}, {"19a", "PERU"},
+				{"20a", "LEAF"}, {"21a", "PEONS"}, {"22a", "DOG TAG"}, {"24a", "LOL"},
+				{"25a", "HAVE NO OOMPH"}, {"30a", "MATTE"}, {"33a", "IMPLORED"},
+				{"35a", "ERR"}, {"36a", "RANGE"}, {"38a", "EMO"}, {"39a", "WAIT HERE"},
+				{"42a", "EGYPT"}, {"44a", "BOO OFF STAGE"}, {"47a", "ERS"},
+				{"48a", "EUGENE"}, {"51a", "SHARI"}, {"54a", "SINN"}, {"56a", "WING"},
+				{"58a", "ITS A ZOO OUT THERE"}, {"61a", "STEGOSAUR"}, {"62a", "HIT ON"},
+				{"63a", "IPA"}, {"64a", "NURSE"},
+			} {
+				require.NoError(t, state.ApplyAnswer(answer.clue, answer.answer, false))
+			}
+			require.NoError(t, SetState(conn, Channel.name, state))
+			Events(events, "state")
+
+			response = Channel.PUT("/answer/65a", `"OZONE"`, router)
+			require.Equal(t, http.StatusOK, response.Code)
+
+			// The state event of the completed puzzle is sent right away.  When
+			// enabled the previous day's puzzle is then selected in the background
+			// and clients are sent its state as well.
+			var found []pubsub.Event
+			for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+				time.Sleep(5 * time.Millisecond)
+
+				found = append(found, Events(events, "state")...)
+				if len(found) == 2 || (len(found) == 1 && !test.enabled) {
+					break
+				}
+			}
+
+			if test.enabled {
+				require.Equal(t, 2, len(found))
+				selected := found[1].Payload.(State)
+				assert.Equal(t, model.StatusSelected, selected.Status)
+				assert.Equal(t, test.expected, selected.Puzzle.PublishedDate.Format("2006-01-02"))
+				assert.Nil(t, selected.Puzzle.Cells)
+			} else {
+				require.Equal(t, 1, len(found))
+			}
+			assert.Equal(t, model.StatusComplete, found[0].Payload.(State).Status)
+			assert.Equal(t, test.enabled, found[0].Payload.(State).AdvanceTime != nil)
+
+			state, err = GetState(conn, Channel.name)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, state.Puzzle.PublishedDate.Format("2006-01-02"))
+			assert.Equal(t, "new_york_times", state.Source)
+
+			// Nothing is left waiting to be advanced.
+			pending, err := redis.Strings(conn.Do("ZRANGE", PendingAdvancesKey, 0, -1))
+			require.NoError(t, err)
+			assert.Empty(t, pending)
+		})
+	}
+}
+
+func TestRoute_UpdateAnswer_PracticeSolve(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+	ForcePuzzleToBeLoaded(t, "xwordinfo-nyt-20181231.json")
+
+	response := Channel.PUT("/?practice=true", `{"new_york_times_date": "2018-12-31"}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.True(t, state.Practice)
+	})
+
+	// Starting a practice solve doesn't start its timer.
+	response = Channel.PUT("/status", ``, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, model.StatusSolving, state.Status)
+		assert.True(t, state.Practice)
+		assert.Nil(t, state.LastStartTime)
+	})
+
+	// Solve the entire puzzle except for the last answer.
+	state, err := GetState(conn, Channel.name)
+	require.NoError(t, err)
+	for _, answer := range []struct{ clue, answer string }{
+		{"1a", "Q AND A"}, {"6a", "ATTIC"}, {"11a", "HON"}, {"14a", "THIRD"},
+		{"15a", "LAID ASIDE"}, {"17a", "IM TOO OLD FOR THIS"}, {"19a", "PERU"},
+		{"20a", "LEAF"}, {"21a", "PEONS"}, {"22a", "DOG TAG"}, {"24a", "LOL"},
+		{"25a", "HAVE NO OOMPH"}, {"30a", "MATTE"}, {"33a", "IMPLORED"},
+		{"35a", "ERR"}, {"36a", "RANGE"}, {"38a", "EMO"}, {"39a", "WAIT HERE"},
+		{"42a", "EGYPT"}, {"44a", "BOO OFF STAGE"}, {"47a", "ERS"},
+		{"48a", "EUGENE"}, {"51a", "SHARI"}, {"54a", "SINN"}, {"56a", "WING"},
+		{"58a", "ITS A ZOO OUT THERE"}, {"61a", "STEGOSAUR"}, {"62a", "HIT ON"},
+		{"63a", "IPA"}, {"64a", "NURSE"},
+	} {
+		require.NoError(t, state.ApplyAnswer(answer.clue, answer.answer, false))
+	}
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response = Channel.PUT("/answer/65a?user=bob", `"OZONE"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.Equal(t, 1, len(Events(events, "complete")))
+
+	// The solve completes normally without any time being tracked.
+	loaded, err := GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusComplete, loaded.Status)
+	assert.Equal(t, time.Duration(0), loaded.TotalSolveDuration.Duration)
+
+	// But no one is credited with solving clues.
+	response = Channel.GET("/leaderboard", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	var leaderboard []LeaderboardEntry
+	require.NoError(t, render.DecodeJSON(response.Body, &leaderboard))
+	assert.Empty(t, leaderboard)
+
+	// And the channel's stats and streak are left untouched.
+	records, err := GetSolveRecords(conn, Channel.name, 10)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	streak, err := GetStreak(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, 0, streak.Count)
+
+	completions, err := model.GetRecentCompletions(conn, 10)
+	require.NoError(t, err)
+	assert.Empty(t, completions)
+}
+
+func TestRoute_UpdateAnswer_PausedAnswerBehavior(t *testing.T) {
+	// This acts as a small integration test submitting answers while the solve
+	// is paused and ensuring they're handled according to the setting once the
+	// solve is resumed.
+	t.Run("reject", func(t *testing.T) {
+		router, pool, registry := NewTestRouter(t)
+		conn := NewRedisConnection(t, pool)
+		events := NewEventSubscription(t, registry, Channel.name)
+
+		state := NewState(t, "xwordinfo-nyt-20181231.json")
+		state.Status = model.StatusSolving
+		require.NoError(t, SetState(conn, Channel.name, state))
+
+		response := Channel.PUT("/status", ``, router)
+		require.Equal(t, http.StatusOK, response.Code)
+		VerifyState(t, pool, events, func(state State) {
+			assert.Equal(t, model.StatusPaused, state.Status)
+		})
+
+		response = Channel.PUT("/answer/1a", `"QANDA"`, router)
+		assert.Equal(t, http.StatusConflict, response.Code)
+
+		response = Channel.PUT("/status", ``, router)
+		require.Equal(t, http.StatusOK, response.Code)
+		VerifyState(t, pool, events, func(state State) {
+			assert.Equal(t, model.StatusSolving, state.Status)
+			assert.False(t, state.AcrossCluesFilled[1])
+			assert.Empty(t, state.QueuedAnswers)
+		})
+	})
+
+	t.Run("queue", func(t *testing.T) {
+		router, pool, registry := NewTestRouter(t)
+		conn := NewRedisConnection(t, pool)
+		events := NewEventSubscription(t, registry, Channel.name)
+
+		settings := Settings{
+			OnlyAllowCorrectAnswers: true,
+			PausedAnswerBehavior:    QueuePausedAnswers,
+		}
+		require.NoError(t, SetSettings(conn, Channel.name, settings))
+
+		state := NewState(t, "xwordinfo-nyt-20181231.json")
+		state.Status = model.StatusSolving
+		require.NoError(t, SetState(conn, Channel.name, state))
+
+		response := Channel.PUT("/status", ``, router)
+		require.Equal(t, http.StatusOK, response.Code)
+		VerifyState(t, pool, events, func(state State) {
+			assert.Equal(t, model.StatusPaused, state.Status)
+		})
+
+		// Queued answers are accepted, but not applied or broadcast until the
+		// solve is resumed.
+		response = Channel.PUT("/answer/1a?user=alice", `"QANDA"`, router)
+		assert.Equal(t, http.StatusAccepted, response.Code)
+		response = Channel.PUT("/answer/6a?user=bob", `"WRONG"`, router)
+		assert.Equal(t, http.StatusAccepted, response.Code)
+		response = Channel.PUT("/answer/11a?user=carol", `"HON"`, router)
+		assert.Equal(t, http.StatusAccepted, response.Code)
+		assert.Empty(t, Events(events, "state"))
+
+		// Pencil answers can't be queued.
+		response = Channel.PUT("/answer/14a?pencil=true", `"THIRD"`, router)
+		assert.Equal(t, http.StatusConflict, response.Code)
+
+		state, err := GetState(conn, Channel.name)
+		require.NoError(t, err)
+		assert.False(t, state.AcrossCluesFilled[1])
+		assert.Len(t, state.QueuedAnswers, 3)
+
+		// Resuming applies the queued answers in order, skipping the incorrect
+		// one, and sends a single state event.
+		response = Channel.PUT("/status", ``, router)
+		require.Equal(t, http.StatusOK, response.Code)
+		VerifyState(t, pool, events, func(state State) {
+			assert.Equal(t, model.StatusSolving, state.Status)
+			assert.True(t, state.AcrossCluesFilled[1])
+			assert.False(t, state.AcrossCluesFilled[6])
+			assert.True(t, state.AcrossCluesFilled[11])
+			assert.Equal(t, map[string]string{"1a": "alice", "11a": "carol"}, state.ClueSolvers)
+			assert.Empty(t, state.QueuedAnswers)
+		})
+	})
+
+	t.Run("queue full", func(t *testing.T) {
+		router, pool, _ := NewTestRouter(t)
+		conn := NewRedisConnection(t, pool)
+
+		max := MaxQueuedAnswers
+		MaxQueuedAnswers = 1
+		t.Cleanup(func() { MaxQueuedAnswers = max })
+
+		settings := Settings{PausedAnswerBehavior: QueuePausedAnswers}
+		require.NoError(t, SetSettings(conn, Channel.name, settings))
+
+		state := NewState(t, "xwordinfo-nyt-20181231.json")
+		state.Status = model.StatusPaused
+		require.NoError(t, SetState(conn, Channel.name, state))
+
+		response := Channel.PUT("/answer/1a", `"QANDA"`, router)
+		assert.Equal(t, http.StatusAccepted, response.Code)
+
+		response = Channel.PUT("/answer/6a", `"ATTIC"`, router)
+		require.Equal(t, http.StatusConflict, response.Code)
+		assert.Equal(t, ErrAnswerQueueFull.Error(), strings.TrimSpace(response.Body.String()))
+	})
+}
+
+func TestRoute_ToggleStatus_QueuedAnswersCompletePuzzle(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	settings := Settings{
+		CompleteThreshold:    DefaultCompleteThreshold,
+		PausedAnswerBehavior: QueuePausedAnswers,
+	}
+	require.NoError(t, SetSettings(conn, Channel.name, settings))
+
+	// Setup a paused state that has the entire puzzle solved except for the last
+	// answer.
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	state.ApplyAnswer("1a", "Q AND A", false)
+	state.ApplyAnswer("6a", "ATTIC", false)
+	state.ApplyAnswer("11a", "HON", false)
+	state.ApplyAnswer("14a", "THIRD", false)
+	state.ApplyAnswer("15a", "LAID ASIDE", false)
+	state.ApplyAnswer("17a", "IM TOO OLD FOR THIS", false)
+	state.ApplyAnswer("19a", "PERU", false)
+	state.ApplyAnswer("20a", "LEAF", false)
+	state.ApplyAnswer("21a", "PEONS", false)
+	state.ApplyAnswer("22a", "DOG TAG", false)
+	state.ApplyAnswer("24a", "LOL", false)
+	state.ApplyAnswer("25a", "HAVE NO OOMPH", false)
+	state.ApplyAnswer("30a", "MATTE", false)
+	state.ApplyAnswer("33a", "IMPLORED", false)
+	state.ApplyAnswer("35a", "ERR", false)
+	state.ApplyAnswer("36a", "RANGE", false)
+	state.ApplyAnswer("38a", "EMO", false)
+	state.ApplyAnswer("39a", "WAIT HERE", false)
+	state.ApplyAnswer("42a", "EGYPT", false)
+	state.ApplyAnswer("44a", "BOO OFF STAGE", false)
+	state.ApplyAnswer("47a", "ERS", false)
+	state.ApplyAnswer("48a", "EUGENE", false)
+	state.ApplyAnswer("51a", "SHARI", false)
+	state.ApplyAnswer("54a", "SINN", false)
+	state.ApplyAnswer("56a", "WING", false)
+	state.ApplyAnswer("58a", "ITS A ZOO OUT THERE", false)
+	state.ApplyAnswer("61a", "STEGOSAUR", false)
+	state.ApplyAnswer("62a", "HIT ON", false)
+	state.ApplyAnswer("63a", "IPA", false)
+	state.ApplyAnswer("64a", "NURSE", false)
+	state.Status = model.StatusPaused
+	state.LastStartTime = nil
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response := Channel.PUT("/answer/65a", `"OZONE"`, router)
+	require.Equal(t, http.StatusAccepted, response.Code)
+
+	// Resuming applies the final answer, completing the puzzle.
+	response = Channel.PUT("/status", ``, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	assert.Len(t, Events(events, "complete"), 1)
+
+	state, err := GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusComplete, state.Status)
+	assert.Nil(t, state.LastStartTime)
+	assert.Empty(t, state.QueuedAnswers)
+}
+
+func TestRoute_UpdateAnswer_CompleteEventIncludesSolvers(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	// Setup a state that has the entire puzzle solved except for the last answer.
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	for _, answer := range []struct{ clue, answer string }{
+		{"1a", "Q AND A"}, {"6a", "ATTIC"}, {"11a", "HON"}, {"14a", "THIRD"},
+		{"15a", "LAID ASIDE"}, {"17a", "IM TOO OLD FOR THIS"}, {"19a", "PERU"},
+		{"20a", "LEAF"}, {"21a", "PEONS"}, {"22a", "DOG TAG"}, {"24a", "LOL"},
+		{"25a", "HAVE NO OOMPH"}, {"30a", "MATTE"}, {"33a", "IMPLORED"},
+		{"35a", "ERR"}, {"36a", "RANGE"}, {"38a", "EMO"}, {"39a", "WAIT HERE"},
+		{"42a", "EGYPT"}, {"44a", "BOO OFF STAGE"}, {"47a", "ERS"},
+		{"48a", "EUGENE"}, {"51a", "SHARI"}, {"54a", "SINN"}, {"56a", "WING"},
+		{"58a", "ITS A ZOO OUT THERE"}, {"61a", "STEGOSAUR"}, {"62a", "HIT ON"},
+		{"63a", "IPA"}, {"64a", "NURSE"},
+	} {
+		require.NoError(t, state.ApplyAnswer(answer.clue, answer.answer, false))
+	}
+	state.CreditSolver("1a", "alice")
+	state.Reveals = 3
+	state.Source = "new_york_times"
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response := Channel.PUT("/answer/65a?user=bob", `"OZONE"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	found := Events(events, "complete")
+	require.Equal(t, 1, len(found))
+
+	payload := found[0].Payload.(map[string]interface{})
+	assert.Equal(t, map[string]string{"1a": "alice", "65a": "bob"}, payload["clue_solvers"])
+	assert.Equal(t, 3, payload["reveals"])
+	assert.Equal(t, "The New York Times", payload["publisher"])
+	assert.Equal(t, "2018, The New York Times", payload["copyright"])
+	assert.Equal(t, "https://www.nytimes.com/crosswords/game/daily/2018/12/31", payload["source_url"])
+
+	// The number of reveals is retained in the completed state.
+	loaded, err := GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusComplete, loaded.Status)
+	assert.Equal(t, 3, loaded.Reveals)
+
+	// And the completed solve is recorded in the stats.
+	records, err := GetSolveRecords(conn, Channel.name, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(records))
+	assert.Equal(t, model.StatusComplete, records[0].Status)
+	assert.Equal(t, 3, records[0].Reveals)
+
+	// And the channel's solve streak is started.
+	streak, err := GetStreak(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, 1, streak.Count)
+}
+
+func TestCompleteEvent_Attribution(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		source   string
+		expected map[string]interface{}
+	}{
+		{
+			name:     "new york times",
+			filename: "xwordinfo-nyt-20181231.json",
+			source:   "new_york_times",
+			expected: map[string]interface{}{
+				"publisher":  "The New York Times",
+				"copyright":  "2018, The New York Times",
+				"source_url": "https://www.nytimes.com/crosswords/game/daily/2018/12/31",
+			},
+		},
+		{
+			name:     "wall street journal",
+			filename: "puzzle-wsj-20190102.json",
+			source:   "wall_street_journal",
+			expected: map[string]interface{}{
+				"publisher":  "The Wall Street Journal",
+				"source_url": "http://herbach.dnsalias.com/wsj/wsj190102.puz",
+			},
+		},
+		{
+			name:     "puzzle without a dated source",
+			filename: "puzzle-wsj-20190102.json",
+			source:   "puz_file_url",
+			expected: map[string]interface{}{
+				"publisher": "The Wall Street Journal",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := NewState(t, test.filename)
+			state.Source = test.source
+
+			event := CompleteEvent(state)
+			assert.Equal(t, "complete", event.Kind)
+
+			payload := event.Payload.(map[string]interface{})
+			for _, key := range []string{"publisher", "copyright", "source_url"} {
+				expected, ok := test.expected[key]
+				if !ok {
+					assert.NotContains(t, payload, key)
+					continue
+				}
+				assert.Equal(t, expected, payload[key])
+			}
+		})
+	}
+}
+func TestRoute_UpdateAnswer_CompletionWebhook(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	AllowInternalWebhookAddresses(t)
+
+	payloads := make(chan CompletionPayload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var payload CompletionPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		payloads <- payload
+	}))
+	defer server.Close()
+
+	require.NoError(t, SetCompletionWebhook(conn, Channel.name, server.URL))
+
+	// Setup a state that has the entire puzzle solved except for the last answer.
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	state.TotalSolveDuration = model.Duration{Duration: 10 * time.Minute}
+	for _, answer := range []struct{ clue, answer string }{
+		{"1a", "Q AND A"}, {"6a", "ATTIC"}, {"11a", "HON"}, {"14a", "THIRD"},
+		{"15a", "LAID ASIDE"}, {"17a", "IM TOO OLD FOR THIS"}, {"19a", "PERU"},
+		{"20a", "LEAF"}, {"21a", "PEONS"}, {"22a", "DOG TAG"}, {"24a", "LOL"},
+		{"25a", "HAVE NO OOMPH"}, {"30a", "MATTE"}, {"33a", "IMPLORED"},
+		{"35a", "ERR"}, {"36a", "RANGE"}, {"38a", "EMO"}, {"39a", "WAIT HERE"},
+		{"42a", "EGYPT"}, {"44a", "BOO OFF STAGE"}, {"47a", "ERS"},
+		{"48a", "EUGENE"}, {"51a", "SHARI"}, {"54a", "SINN"}, {"56a", "WING"},
+		{"58a", "ITS A ZOO OUT THERE"}, {"61a", "STEGOSAUR"}, {"62a", "HIT ON"},
+		{"63a", "IPA"}, {"64a", "NURSE"},
+	} {
+		require.NoError(t, state.ApplyAnswer(answer.clue, answer.answer, false))
+	}
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response := Channel.PUT("/answer/65a", `"OZONE"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	select {
+	case payload := <-payloads:
+		assert.Equal(t, Channel.name, payload.Channel)
+		assert.Equal(t, state.Puzzle.Title, payload.Title)
+		assert.True(t, payload.Duration.Duration >= 10*time.Minute)
+
+	case <-time.After(time.Second):
+		assert.Fail(t, "completion webhook was not called")
+	}
+}
+
+func TestRoute_CompletionWebhook(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	ForceAdminToken(t, "secret")
+
+	// Only administrators can change the webhook.
+	response := Channel.PUT("/completion-webhook", `"https://example.com/solved"`, router)
+	require.Equal(t, http.StatusUnauthorized, response.Code)
+
+	response = Channel.ADMIN(http.MethodPut, "/completion-webhook", `"https://example.com/solved"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	response = Channel.ADMIN(http.MethodGet, "/completion-webhook", ``, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, `"https://example.com/solved"`, strings.TrimSpace(response.Body.String()))
+
+	// The webhook is used when the channel completes a puzzle, but it's never
+	// sent to clients with the rest of the settings.
+	conn := NewRedisConnection(t, pool)
+	settings, err := GetSettings(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/solved", settings.CompletionWebhook)
+
+	response = Channel.GET("/settings", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.NotContains(t, response.Body.String(), "example.com")
+
+	// Webhooks that point into the server's network are rejected.
+	for _, webhook := range []string{`"http://127.0.0.1/solved"`, `"http://10.0.0.1/solved"`, `"ftp://example.com"`, `{`} {
+		response = Channel.ADMIN(http.MethodPut, "/completion-webhook", webhook, router)
+		assert.Equal(t, http.StatusBadRequest, response.Code, webhook)
+	}
+
+	// An empty webhook removes it.
+	response = Channel.ADMIN(http.MethodPut, "/completion-webhook", `""`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	settings, err = GetSettings(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Empty(t, settings.CompletionWebhook)
+}
+
+func TestRoute_UpdateAnswer_PracticeSolveSkipsCompletionWebhook(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	AllowInternalWebhookAddresses(t)
+
+	called := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called <- struct{}{}
+	}))
+	defer server.Close()
+	require.NoError(t, SetCompletionWebhook(conn, Channel.name, server.URL))
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	state.Practice = true
+	for _, answer := range []struct{ clue, answer string }{
+		{"1a", "Q AND A"}, {"6a", "ATTIC"}, {"11a", "HON"}, {"14a", "THIRD"},
+		{"15a", "LAID ASIDE"}, {"17a", "IM TOO OLD FOR THIS"}, {"19a", "PERU"},
+		{"20a", "LEAF"}, {"21a", "PEONS"}, {"22a", "DOG TAG"}, {"24a", "LOL"},
+		{"25a", "HAVE NO OOMPH"}, {"30a", "MATTE"}, {"33a", "IMPLORED"},
+		{"35a", "ERR"}, {"36a", "RANGE"}, {"38a", "EMO"}, {"39a", "WAIT HERE"},
+		{"42a", "EGYPT"}, {"44a", "BOO OFF STAGE"}, {"47a", "ERS"},
+		{"48a", "EUGENE"}, {"51a", "SHARI"}, {"54a", "SINN"}, {"56a", "WING"},
+		{"58a", "ITS A ZOO OUT THERE"}, {"61a", "STEGOSAUR"}, {"62a", "HIT ON"},
+		{"63a", "IPA"}, {"64a", "NURSE"},
+	} {
+		require.NoError(t, state.ApplyAnswer(answer.clue, answer.answer, false))
+	}
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response := Channel.PUT("/answer/65a", `"OZONE"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	select {
+	case <-called:
+		assert.Fail(t, "completion webhook was called for a practice solve")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestRoute_UpdateAnswer_CompleteThreshold(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold string // empty to use the default
+		complete  bool
+	}{
+		{
+			name:     "default threshold",
+			complete: false,
+		},
+		{
+			name:      "threshold 0",
+			threshold: "0",
+			complete:  true,
+		},
+		{
+			name:      "threshold 80",
+			threshold: "80",
+			complete:  true,
+		},
+		{
+			name:      "threshold 100",
+			threshold: "100",
+			complete:  false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, registry := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+
+			if test.threshold != "" {
+				response := Channel.PUT("/setting/complete_threshold", test.threshold, router)
+				require.Equal(t, http.StatusOK, response.Code)
+			}
+
+			// Fill in every cell of the puzzle except for the last one with the
+			// correct value.
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.Status = model.StatusSolving
+			for y := 0; y < state.Puzzle.Rows; y++ {
+				for x := 0; x < state.Puzzle.Cols; x++ {
+					state.Cells[y][x] = state.Puzzle.Cells[y][x]
+				}
+			}
+			state.Cells[state.Puzzle.Rows-1][state.Puzzle.Cols-1] = ""
+			require.NoError(t, SetState(conn, Channel.name, state))
+
+			events := NewEventSubscription(t, registry, Channel.name)
+
+			// Fill in the last answer with a mistake.
+			response := Channel.PUT("/answer/65a", `"OZONX"`, router)
+			require.Equal(t, http.StatusOK, response.Code)
+
+			found := Events(events, "complete")
+			if test.complete {
+				assert.Equal(t, 1, len(found))
+			} else {
+				assert.Equal(t, 0, len(found))
+			}
+
+			state, err := GetState(conn, Channel.name)
+			require.NoError(t, err)
+			assert.Equal(t, test.complete, state.Status == model.StatusComplete)
+		})
+	}
+}
+
+func TestRoute_UpdateAnswer_Pencil(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+	completes := NewEventSubscription(t, registry, Channel.name)
+
+	// Setup a state that has the entire puzzle solved except for the last answer.
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	for _, answer := range []struct{ clue, answer string }{
+		{"1a", "Q AND A"}, {"6a", "ATTIC"}, {"11a", "HON"}, {"14a", "THIRD"},
+		{"15a", "LAID ASIDE"}, {"17a", "IM TOO OLD FOR THIS"}, {"19a", "PERU"},
+		{"20a", "LEAF"}, {"21a", "PEONS"}, {"22a", "DOG TAG"}, {"24a", "LOL"},
+		{"25a", "HAVE NO OOMPH"}, {"30a", "MATTE"}, {"33a", "IMPLORED"},
+		{"35a", "ERR"}, {"36a", "RANGE"}, {"38a", "EMO"}, {"39a", "WAIT HERE"},
+		{"42a", "EGYPT"}, {"44a", "BOO OFF STAGE"}, {"47a", "ERS"},
+		{"48a", "EUGENE"}, {"51a", "SHARI"}, {"54a", "SINN"}, {"56a", "WING"},
+		{"58a", "ITS A ZOO OUT THERE"}, {"61a", "STEGOSAUR"}, {"62a", "HIT ON"},
+		{"63a", "IPA"}, {"64a", "NURSE"},
+	} {
+		require.NoError(t, state.ApplyAnswer(answer.clue, answer.answer, false))
+	}
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// Penciling in the last answer shouldn't complete the puzzle.
+	response := Channel.PUT("/answer/65a?pencil=true", `"OZONE"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, model.StatusSolving, state.Status)
+		assert.False(t, state.AcrossCluesFilled[65])
+		assert.Equal(t, []string{"O", "Z", "O", "N", "E"}, state.PencilCells[14][10:15])
+		assert.Equal(t, []string{"", "", "", "", ""}, state.Cells[14][10:15])
+	})
+	assert.Empty(t, Events(completes, "complete"))
+
+	// Promoting the penciled in answer commits it and completes the puzzle.
+	response = Channel.PUT("/promote/65a?user=bob", ``, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, model.StatusComplete, state.Status)
+		assert.True(t, state.AcrossCluesFilled[65])
+		assert.Equal(t, []string{"O", "Z", "O", "N", "E"}, state.Cells[14][10:15])
+		assert.Equal(t, []string{"", "", "", "", ""}, state.PencilCells[14][10:15])
+		assert.Equal(t, "bob", state.ClueSolvers["65a"])
+	})
+	assert.Equal(t, 1, len(Events(completes, "complete")))
+}
+
+func TestRoute_PromotePencilAnswer_Error(t *testing.T) {
+	tests := []struct {
+		name              string
+		status            model.Status
+		pencil            bool
+		onlyCorrect       bool
+		clue              string
+		loadSettingsError error
+		loadStateError    error
+		saveStateError    error
+		expected          int
+	}{
+		{
+			name:     "not solving",
+			status:   model.StatusPaused,
+			pencil:   true,
+			clue:     "1a",
+			expected: http.StatusConflict,
+		},
+		{
+			name:     "nothing penciled in",
+			status:   model.StatusSolving,
+			clue:     "1a",
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid clue",
+			status:   model.StatusSolving,
+			pencil:   true,
+			clue:     "1x",
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:        "incorrect answer when only correct answers allowed",
+			status:      model.StatusSolving,
+			pencil:      true,
+			onlyCorrect: true,
+			clue:        "1a",
+			expected:    http.StatusBadRequest,
+		},
+		{
+			name:              "error loading settings",
+			status:            model.StatusSolving,
+			pencil:            true,
+			clue:              "1a",
+			loadSettingsError: errors.New("forced error"),
+			expected:          http.StatusInternalServerError,
+		},
+		{
+			name:           "error loading state",
+			status:         model.StatusSolving,
+			pencil:         true,
+			clue:           "1a",
+			loadStateError: errors.New("forced error"),
+			expected:       http.StatusNotFound,
+		},
+		{
+			name:           "error saving state",
+			status:         model.StatusSolving,
+			pencil:         true,
+			clue:           "1a",
+			saveStateError: errors.New("forced error"),
+			expected:       http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, _ := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+
+			settings := Settings{OnlyAllowCorrectAnswers: test.onlyCorrect}
+			require.NoError(t, SetSettings(conn, Channel.name, settings))
+
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.Status = test.status
+			if test.pencil {
+				require.NoError(t, state.ApplyPencilAnswer("1a", "QANDY"))
+			}
+			require.NoError(t, SetState(conn, Channel.name, state))
+
+			ForceErrorDuringSettingsLoad(t, test.loadSettingsError)
+			ForceErrorDuringStateLoad(t, test.loadStateError)
+			ForceErrorDuringStateSave(t, test.saveStateError)
+
+			response := Channel.PUT("/promote/"+test.clue, ``, router)
+			assert.Equal(t, test.expected, response.Code)
+		})
+	}
+}
+
+func TestRoute_ProposeAndVote(t *testing.T) {
+	// This acts as a small integration test of chat proposing answers and then
+	// voting on them.
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// Propose an answer.
+	response := Channel.POST("/propose/1a", `"Q AND A"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyProposals(t, events, func(proposals map[string][]Proposal) {
+		assert.Equal(t, []string{"QANDA"}, ProposedAnswers(proposals["1a"]))
+	})
+
+	// Vote on it.  Only the score is sent to clients, not who voted.
+	response = Channel.PUT("/vote/1a?user=alice", `{"answer": "QANDA", "up": true}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyProposals(t, events, func(proposals map[string][]Proposal) {
+		assert.Equal(t, 1, proposals["1a"][0].Score)
+		assert.Nil(t, proposals["1a"][0].Votes)
+	})
+
+	// Proposals aren't part of the state and shouldn't have changed the cells.
+	state, err := GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, "", state.Cells[0][0])
+	assert.Empty(t, state.Proposals)
+
+	// Answering the clue discards the proposals.
+	response = Channel.PUT("/answer/1a", `"QANDA"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, "Q", state.Cells[0][0])
+	})
+
+	response = Channel.POST("/propose/6a", `"ATTIC"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyProposals(t, events, func(proposals map[string][]Proposal) {
+		assert.NotContains(t, proposals, "1a")
+		assert.Equal(t, []string{"ATTIC"}, ProposedAnswers(proposals["6a"]))
+	})
+}
+
+func TestRoute_ProposeAndVote_Expired(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	require.NoError(t, state.Propose("1a", "QANDA"))
+	state.Proposals["1a"][0].ProposedTime = time.Now().Add(-2 * ProposalTimeout)
+	require.NoError(t, SetProposals(conn, Channel.name, state.Proposals))
+
+	// Voting on a proposal that has timed out isn't possible.
+	response := Channel.PUT("/vote/1a?user=alice", `{"answer": "QANDA", "up": true}`, router)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	// And it's no longer sent to clients.
+	response = Channel.POST("/propose/6a", `"ATTIC"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyProposals(t, events, func(proposals map[string][]Proposal) {
+		assert.NotContains(t, proposals, "1a")
+	})
+}
+
+func TestRoute_UpdatePuzzle_DiscardsProposals(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, state.Propose("1a", "QANDA"))
+	require.NoError(t, SetProposals(conn, Channel.name, state.Proposals))
+
+	ForcePuzzleToBeLoaded(t, "xwordinfo-nyt-20181231.json")
+
+	response := Channel.PUT("/", `{"new_york_times_date": "2018-12-31"}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	proposals, err := GetProposals(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Nil(t, proposals)
+}
+
+func TestRoute_VoteOnProposal_AutoApply(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	response := Channel.PUT("/setting/auto_apply_proposal_score", `2`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response = Channel.POST("/propose/1a", `"QANDA"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	// One vote isn't enough to apply the proposal.
+	response = Channel.PUT("/vote/1a?user=alice", `{"answer": "QANDA", "up": true}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, 0, len(Events(events, "state")))
+
+	// But two is.
+	response = Channel.PUT("/vote/1a?user=bob", `{"answer": "QANDA", "up": true}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, "Q", state.Cells[0][0])
+		assert.True(t, state.AcrossCluesFilled[1])
+	})
+
+	proposals, err := GetProposals(conn, Channel.name)
+	require.NoError(t, err)
+	assert.NotContains(t, proposals, "1a")
+}
+
+func TestRoute_ProposeAndVote_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   model.Status
+		method   string
+		url      string
+		body     string
+		expected int
+	}{
+		{
+			name:     "propose, not solving",
+			status:   model.StatusPaused,
+			method:   http.MethodPost,
+			url:      "/propose/1a",
+			body:     `"QANDA"`,
+			expected: http.StatusConflict,
+		},
+		{
+			name:     "propose, malformed json",
+			status:   model.StatusSolving,
+			method:   http.MethodPost,
+			url:      "/propose/1a",
+			body:     `"`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "propose, answer doesn't fit",
+			status:   model.StatusSolving,
+			method:   http.MethodPost,
+			url:      "/propose/1a",
+			body:     `"QAND"`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "propose, too many proposals",
+			status:   model.StatusSolving,
+			method:   http.MethodPost,
+			url:      "/propose/6a",
+			body:     `"FFFFF"`,
+			expected: http.StatusConflict,
+		},
+		{
+			name:     "vote, not solving",
+			status:   model.StatusPaused,
+			method:   http.MethodPut,
+			url:      "/vote/1a?user=alice",
+			body:     `{"answer": "QANDA", "up": true}`,
+			expected: http.StatusConflict,
+		},
+		{
+			name:     "vote, no user",
+			status:   model.StatusSolving,
+			method:   http.MethodPut,
+			url:      "/vote/1a",
+			body:     `{"answer": "QANDA", "up": true}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "vote, unknown proposal",
+			status:   model.StatusSolving,
+			method:   http.MethodPut,
+			url:      "/vote/1a?user=alice",
+			body:     `{"answer": "QANDO", "up": true}`,
+			expected: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, _ := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.Status = test.status
+			require.NoError(t, state.Propose("1a", "QANDA"))
+			for _, answer := range []string{"AAAAA", "BBBBB", "CCCCC", "DDDDD", "EEEEE"} {
+				require.NoError(t, state.Propose("6a", answer))
+			}
+			require.NoError(t, SetState(conn, Channel.name, state))
+			require.NoError(t, SetProposals(conn, Channel.name, state.Proposals))
+
+			var response *httptest.ResponseRecorder
+			if test.method == http.MethodPost {
+				response = Channel.POST(test.url, test.body, router)
+			} else {
+				response = Channel.PUT(test.url, test.body, router)
+			}
+			assert.Equal(t, test.expected, response.Code)
+		})
+	}
+}
+
+func TestRoute_UpdateAnswer_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected int
+	}{
+		{
+			name:     "empty answer",
+			json:     `""`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "too long answer",
+			json:     `"` + RandomString(1023) + `"`,
+			expected: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "malformed json",
+			json:     `"`,
+			expected: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, _ := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.Status = model.StatusSolving
+			require.NoError(t, SetState(conn, Channel.name, state))
+
+			response := Channel.PUT("/answer/1a", test.json, router)
+			assert.Equal(t, test.expected, response.Code)
+		})
+	}
+}
+
+func TestRoute_UpdateAnswer_UnknownClue(t *testing.T) {
+	tests := []struct {
+		name string
+		clue string
+	}{
+		{
+			name: "across",
+			clue: "999a",
+		},
+		{
+			name: "down",
+			clue: "999d",
+		},
+		{
+			name: "across clue in down direction",
+			clue: "14d",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, _ := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.Status = model.StatusSolving
+			require.NoError(t, SetState(conn, Channel.name, state))
+
+			response := Channel.PUT("/answer/"+test.clue, `"QANDA"`, router)
+			require.Equal(t, http.StatusNotFound, response.Code)
+			assert.Equal(t, "unknown clue: "+test.clue, strings.TrimSpace(response.Body.String()))
+
+			// The state shouldn't have been modified.
+			actual, err := GetState(conn, Channel.name)
+			require.NoError(t, err)
+			assert.Equal(t, state.Cells, actual.Cells)
+		})
+	}
+}
+
+func TestRoute_UpdateAnswer_LengthError(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected string
+	}{
+		{
+			name:     "too short",
+			json:     `"QAND"`,
+			expected: "expected 5 letters, got 4",
+		},
+		{
+			name:     "too long",
+			json:     `"QANDAS"`,
+			expected: "expected 5 letters, got 6",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, _ := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.Status = model.StatusSolving
+			require.NoError(t, SetState(conn, Channel.name, state))
+
+			response := Channel.PUT("/answer/1a", test.json, router)
+			require.Equal(t, http.StatusBadRequest, response.Code)
+			assert.Equal(t, test.expected, strings.TrimSpace(response.Body.String()))
+		})
+	}
+}
+
+func TestRoute_UpdateAnswer_LoadSaveError(t *testing.T) {
+	tests := []struct {
+		name              string
+		loadSettingsError error
+		loadStateError    error
+		saveStateError    error
+	}{
+		{
+			name:              "error loading settings",
+			loadSettingsError: errors.New("forced error"),
+		},
+		{
+			name:           "error loading state",
+			loadStateError: errors.New("forced error"),
+		},
+		{
+			name:           "error saving state",
+			saveStateError: errors.New("forced error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, _ := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.Status = model.StatusSolving
+			require.NoError(t, SetState(conn, Channel.name, state))
+
+			ForceErrorDuringSettingsLoad(t, test.loadSettingsError)
+			ForceErrorDuringStateLoad(t, test.loadStateError)
+			ForceErrorDuringStateSave(t, test.saveStateError)
+
+			response := Channel.PUT("/answer/1a", `"QANDA"`, router)
+			assert.NotEqual(t, http.StatusOK, response.Code)
+		})
+	}
+}
+
+func TestRoute_ShowClue(t *testing.T) {
+	// This acts as a small integration test requesting clues to be shown and
+	// making sure events are properly emitted.
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	// Force a specific puzzle to be loaded so we don't make a network call.
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// Request showing an across clue.
+	response := Channel.GET("/show/1a", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyShowClue(t, events, func(clue string) {
+		assert.Equal(t, "1a", clue)
+	})
+
+	// Request showing a down clue.
+	response = Channel.GET("/show/16d", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyShowClue(t, events, func(clue string) {
+		assert.Equal(t, "16d", clue)
+	})
+
+	// Request showing a malformed clue.
+	response = Channel.GET("/show/1x", router)
+	require.Equal(t, http.StatusBadRequest, response.Code)
+
+	// Request showing a properly formed, but non-existent clue.  This doesn't
+	// fail because it doesn't mutate the state of the puzzle in any way.
+	response = Channel.GET("/show/999a", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyShowClue(t, events, func(clue string) {
+		assert.Equal(t, "999a", clue)
+	})
+}
+
+func TestRoute_ShowClue_AutoHide(t *testing.T) {
+	router, _, registry := NewTestRouter(t)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	response := Channel.GET("/show/1a?duration=20ms", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	// The clue is shown immediately, but not hidden until the duration elapses.
+	var hides []pubsub.Event
+	shows := Events(events, "show_clue")
+	require.Equal(t, 1, len(shows))
+	assert.Equal(t, "1a", shows[0].Payload)
+	assert.Empty(t, Events(events, "hide_clue"))
+
+	for deadline := time.Now().Add(time.Second); len(hides) == 0 && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+		hides = Events(events, "hide_clue")
+	}
+	require.Equal(t, 1, len(hides))
+	assert.Equal(t, "1a", hides[0].Payload)
+
+	// Only a single hide is ever sent.
+	time.Sleep(40 * time.Millisecond)
+	assert.Empty(t, Events(events, "hide_clue"))
+}
+
+func TestRoute_ShowClue_AutoHideCancelled(t *testing.T) {
+	router, _, registry := NewTestRouter(t)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	// Showing a clue without a duration cancels the pending hide.
+	response := Channel.GET("/show/1a?duration=20ms", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	response = Channel.GET("/show/16d", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Empty(t, Events(events, "hide_clue"))
+
+	// Showing a clue with a duration replaces the pending hide with its own.
+	response = Channel.GET("/show/1a?duration=20ms", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	response = Channel.GET("/show/16d?duration=40ms", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var hides []pubsub.Event
+	for deadline := time.Now().Add(time.Second); len(hides) == 0 && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+		hides = Events(events, "hide_clue")
+	}
+	require.Equal(t, 1, len(hides))
+	assert.Equal(t, "16d", hides[0].Payload)
+}
+
+func TestRoute_ShowClue_InvalidDuration(t *testing.T) {
+	router, _, registry := NewTestRouter(t)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	for _, duration := range []string{"abc", "10", "0s", "-5s"} {
+		response := Channel.GET("/show/1a?duration="+duration, router)
+		assert.Equal(t, http.StatusBadRequest, response.Code, duration)
+	}
+
+	assert.Empty(t, Events(events, "show_clue"))
+}
+
+func TestRoute_UpdateBanner(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	response := Channel.PUT("/message", `{"message": " BRB in 5 "}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	found := Events(events, "banner")
+	require.Equal(t, 1, len(found))
+	assert.Equal(t, &Banner{Message: "BRB in 5"}, found[0].Payload)
+
+	// The banner is saved so that clients which connect later see it as well.
+	banner, err := GetBanner(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, &Banner{Message: "BRB in 5"}, banner)
+
+	response = Channel.GET("/resync", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var body []pubsub.Event
+	require.NoError(t, render.DecodeJSON(response.Body, &body))
+	require.Equal(t, 2, len(body))
+	assert.Equal(t, "banner", body[1].Kind)
+
+	// Setting another banner replaces the existing one.
+	response = Channel.PUT("/message", `{"message": "Back!"}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	banner, err = GetBanner(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, "Back!", banner.Message)
+}
+
+func TestRoute_UpdateBanner_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{
+			name: "malformed json",
+			body: `{"message": "BRB"`,
+		},
+		{
+			name: "empty message",
+			body: `{"message": "  "}`,
+		},
+		{
+			name: "message too long",
+			body: fmt.Sprintf(`{"message": "%s"}`, strings.Repeat("x", BannerMaxLength+1)),
+		},
+		{
+			name: "malformed ttl",
+			body: `{"message": "BRB", "ttl": "abc"}`,
+		},
+		{
+			name: "non-positive ttl",
+			body: `{"message": "BRB", "ttl": "0s"}`,
+		},
+		{
+			name: "ttl too long",
+			body: `{"message": "BRB", "ttl": "2h"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, registry := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+			events := NewEventSubscription(t, registry, Channel.name)
+
+			response := Channel.PUT("/message", test.body, router)
+			assert.Equal(t, http.StatusBadRequest, response.Code)
+			assert.Empty(t, Events(events, "banner"))
+
+			banner, err := GetBanner(conn, Channel.name)
+			require.NoError(t, err)
+			assert.Nil(t, banner)
+		})
+	}
+}
+
+func TestRoute_UpdateBanner_Expires(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	response := Channel.PUT("/message", `{"message": "BRB in 5", "ttl": "20ms"}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	found := Events(events, "banner")
+	require.Equal(t, 1, len(found))
+	banner := found[0].Payload.(*Banner)
+	assert.Equal(t, "BRB in 5", banner.Message)
+	assert.NotNil(t, banner.ExpiresAt)
+
+	// Once the ttl elapses the banner is cleared.
+	for deadline := time.Now().Add(time.Second); len(found) <= 1 && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+		found = append(found, Events(events, "banner")...)
+	}
+	require.Equal(t, 2, len(found))
+	assert.Nil(t, found[1].Payload)
+
+	banner, err := GetBanner(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Nil(t, banner)
+
+	// Only a single clear is ever sent.
+	time.Sleep(40 * time.Millisecond)
+	assert.Empty(t, Events(events, "banner"))
+}
+
+func TestRoute_UpdateBanner_ExpirationCancelled(t *testing.T) {
+	router, _, registry := NewTestRouter(t)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	// Setting a banner without a ttl cancels the pending expiration.
+	response := Channel.PUT("/message", `{"message": "BRB", "ttl": "20ms"}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	response = Channel.PUT("/message", `{"message": "Back!"}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.Equal(t, 2, len(Events(events, "banner")))
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Empty(t, Events(events, "banner"))
+}
+
+func TestFinishBanner_Superseded(t *testing.T) {
+	_, _, registry := NewTestRouter(t)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	// A change that finishes after a newer change has started isn't published,
+	// and doesn't schedule its expiration.
+	first := claimBanner(Channel.name)
+	second := claimBanner(Channel.name)
+	finishBanner(registry, Channel.name, first, &Banner{Message: "BRB"}, 20*time.Millisecond)
+	assert.Empty(t, Events(events, "banner"))
+
+	finishBanner(registry, Channel.name, second, &Banner{Message: "Back!"}, 0)
+	found := Events(events, "banner")
+	require.Equal(t, 1, len(found))
+	assert.Equal(t, &Banner{Message: "Back!"}, found[0].Payload)
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Empty(t, Events(events, "banner"))
+}
+
+func TestRoute_ClearBanner(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	response := Channel.PUT("/message", `{"message": "BRB in 5", "ttl": "20ms"}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.Equal(t, 1, len(Events(events, "banner")))
+
+	response = Channel.DELETE("/message", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	found := Events(events, "banner")
+	require.Equal(t, 1, len(found))
+	assert.Nil(t, found[0].Payload)
+
+	banner, err := GetBanner(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Nil(t, banner)
+
+	// Clearing the banner cancels its pending expiration.
+	time.Sleep(60 * time.Millisecond)
+	assert.Empty(t, Events(events, "banner"))
+
+	// Clearing a channel without a banner is harmless.
+	response = Channel.DELETE("/message", router)
+	require.Equal(t, http.StatusOK, response.Code)
+}
+
+func TestRoute_LockCells(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, state.ApplyAnswer("1a", "QANDA", false))
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// Lock the cells of 1a along with an additional cell.
+	response := Channel.PUT("/lock", `{"clue": "1a", "cells": [{"row": 1, "col": 0}]}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		require.NotNil(t, state.LockedCells)
+		assert.Equal(t, []bool{true, true, true, true, true, false}, state.LockedCells[0][:6])
+		assert.True(t, state.LockedCells[1][0])
+	})
+
+	// An answer that attempts to write a locked cell can't change it.
+	response = Channel.PUT("/answer/1a", `"XXXXX"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	response = Channel.PUT("/answer/1d", `"XXXX"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	state, err := GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Q", "A", "N", "D", "A"}, state.Cells[0][:5])
+	assert.Equal(t, "", state.Cells[1][0])
+	assert.Equal(t, "X", state.Cells[2][0])
+
+	// Once unlocked the answer can be changed.
+	response = Channel.PUT("/unlock", `{"clue": "1a"}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	response = Channel.PUT("/answer/1a", `"XXXXX"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	state, err = GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"X", "X", "X", "X", "X"}, state.Cells[0][:5])
+	assert.True(t, state.IsCellLocked(0, 1))
+}
+
+func TestRoute_LockCells_Error(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		json           string
+		loadStateError error
+		saveStateError error
+		expected       int
+	}{
+		{
+			name:     "malformed body",
+			url:      "/lock",
+			json:     `{`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "no cells",
+			url:      "/lock",
+			json:     `{}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid clue",
+			url:      "/lock",
+			json:     `{"clue": "1x"}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown clue",
+			url:      "/unlock",
+			json:     `{"clue": "2a"}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "cell out of bounds",
+			url:      "/lock",
+			json:     `{"cells": [{"row": 15, "col": 0}]}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "block cell",
+			url:      "/lock",
+			json:     `{"cells": [{"row": 0, "col": 5}]}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:           "error loading state",
+			url:            "/lock",
+			json:           `{"clue": "1a"}`,
+			loadStateError: errors.New("forced error"),
+			expected:       http.StatusInternalServerError,
+		},
+		{
+			name:           "error saving state",
+			url:            "/lock",
+			json:           `{"clue": "1a"}`,
+			saveStateError: errors.New("forced error"),
+			expected:       http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, registry := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+			events := NewEventSubscription(t, registry, Channel.name)
+
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.Status = model.StatusSolving
+			require.NoError(t, SetState(conn, Channel.name, state))
+
+			ForceErrorDuringStateLoad(t, test.loadStateError)
+			ForceErrorDuringStateSave(t, test.saveStateError)
+
+			response := Channel.PUT(test.url, test.json, router)
+			assert.Equal(t, test.expected, response.Code)
+			assert.Empty(t, Events(events, "state"))
+		})
+	}
+
+	// A channel that hasn't selected a puzzle doesn't have cells to lock.
+	router, _, _ := NewTestRouter(t)
+	response := Channel.PUT("/lock", `{"clue": "1a"}`, router)
+	assert.Equal(t, http.StatusConflict, response.Code)
+}
+
+func TestRoute_UpdateFocusedClue(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response := Channel.PUT("/focus/16D", ``, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	focuses := Events(events, "focus")
+	require.Equal(t, 1, len(focuses))
+	assert.Equal(t, "16d", focuses[0].Payload)
+
+	loaded, err := GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, "16d", loaded.FocusedClue)
+
+	// A client that connects afterwards should start out focused on the same
+	// clue.
+	_, stop := Channel.SSE("/events", router)
+	initial := stop()
+	require.Equal(t, 3, len(initial))
+	assert.Equal(t, "settings", initial[0].Kind)
+	assert.Equal(t, "state", initial[1].Kind)
+	assert.Equal(t, "focus", initial[2].Kind)
+	assert.Equal(t, "16d", initial[2].Payload)
+}
+
+func TestRoute_FocusAdjacentClue(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, state.ApplyAnswer("6a", "ATTIC", false))
+	require.NoError(t, state.FocusClue("1a"))
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// Moving forward skips over the filled in 6a.
+	response := Channel.GET("/clue/next", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, `"11a"`, strings.TrimSpace(response.Body.String()))
+
+	focuses := Events(events, "focus")
+	require.Equal(t, 1, len(focuses))
+	assert.Equal(t, "11a", focuses[0].Payload)
+
+	loaded, err := GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, "11a", loaded.FocusedClue)
+
+	// And moving backwards does as well.
+	response = Channel.GET("/clue/prev", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, `"1a"`, strings.TrimSpace(response.Body.String()))
+
+	// Moving backwards from the first clue wraps around to the last down clue.
+	response = Channel.GET("/clue/prev", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, `"60d"`, strings.TrimSpace(response.Body.String()))
+
+	// And moving forward from the last down clue wraps back to the first clue.
+	response = Channel.GET("/clue/next", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, `"1a"`, strings.TrimSpace(response.Body.String()))
+}
+
+func TestRoute_FocusAdjacentClue_Error(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	// There's no puzzle selected yet.
+	response := Channel.GET("/clue/next", router)
+	require.Equal(t, http.StatusConflict, response.Code)
+
+	// Every clue is already filled in.
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	for num := range state.Puzzle.CluesAcross {
+		state.AcrossCluesFilled[num] = true
+	}
+	for num := range state.Puzzle.CluesDown {
+		state.DownCluesFilled[num] = true
+	}
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response = Channel.GET("/clue/next", router)
+	require.Equal(t, http.StatusConflict, response.Code)
+	assert.Equal(t, "no unfilled clues", strings.TrimSpace(response.Body.String()))
+
+	// Errors loading and saving the state should be reported.
+	ForceErrorDuringStateLoad(t, errors.New("forced error"))
+	response = Channel.GET("/clue/prev", router)
+	require.Equal(t, http.StatusInternalServerError, response.Code)
+	ForceErrorDuringStateLoad(t, nil)
+
+	state.DownCluesFilled[60] = false
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	ForceErrorDuringStateSave(t, errors.New("forced error"))
+	response = Channel.GET("/clue/prev", router)
+	require.Equal(t, http.StatusInternalServerError, response.Code)
+
+	assert.Empty(t, Events(events, "focus"))
+}
+
+func TestRoute_UpdateFocusedClue_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		puzzle   bool
+		url      string
+		expected int
+	}{
+		{
+			name:     "malformed clue",
+			puzzle:   true,
+			url:      "/focus/1x",
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "non-existent clue",
+			puzzle:   true,
+			url:      "/focus/999a",
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "no puzzle selected",
+			puzzle:   false,
+			url:      "/focus/1a",
+			expected: http.StatusConflict,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, registry := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+			events := NewEventSubscription(t, registry, Channel.name)
+
+			if test.puzzle {
+				state := NewState(t, "xwordinfo-nyt-20181231.json")
+				require.NoError(t, SetState(conn, Channel.name, state))
+			}
+
+			response := Channel.PUT(test.url, ``, router)
+			assert.Equal(t, test.expected, response.Code)
+			assert.Empty(t, Events(events, "focus"))
+		})
+	}
+
+	// Errors loading or saving the state should be reported.
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	require.NoError(t, SetState(conn, Channel.name, NewState(t, "xwordinfo-nyt-20181231.json")))
+
+	ForceErrorDuringStateSave(t, errors.New("forced error"))
+	response := Channel.PUT("/focus/1a", ``, router)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+
+	ForceErrorDuringStateLoad(t, errors.New("forced error"))
+	response = Channel.PUT("/focus/1a", ``, router)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
+func TestRoute_PeekCell(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	PeekDuration = 10 * time.Millisecond
+	defer func() { PeekDuration = 5 * time.Second }()
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response := Channel.GET("/peek/0/1", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	peeks := Events(events, "peek")
+	require.Equal(t, 1, len(peeks))
+	assert.Equal(t, Peek{Row: 0, Col: 1, Value: "A", TTL: model.Duration{Duration: PeekDuration}}, peeks[0].Payload)
+
+	// After the peek duration the cell is hidden again.
+	var clears []pubsub.Event
+	for deadline := time.Now().Add(time.Second); len(clears) == 0 && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+		clears = Events(events, "peek_clear")
+	}
+	require.Equal(t, 1, len(clears))
+	assert.Equal(t, Peek{Row: 0, Col: 1}, clears[0].Payload)
+
+	// Peeking never changes the cells, but it is counted as a reveal.
+	assert.Empty(t, Events(events, "state"))
+	loaded, err := GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, "", loaded.Cells[0][1])
+	assert.Equal(t, 1, loaded.Reveals)
+
+	// Each subsequent peek increments the count.
+	response = Channel.GET("/peek/0/2", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	loaded, err = GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, 2, loaded.Reveals)
+}
+
+func TestRoute_PeekCell_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   model.Status
+		url      string
+		expected int
+	}{
+		{
+			name:     "malformed row",
+			status:   model.StatusSolving,
+			url:      "/peek/a/1",
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "malformed col",
+			status:   model.StatusSolving,
+			url:      "/peek/1/a",
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "row out of bounds",
+			status:   model.StatusSolving,
+			url:      "/peek/15/1",
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "negative col",
+			status:   model.StatusSolving,
+			url:      "/peek/1/-1",
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "block cell",
+			status:   model.StatusSolving,
+			url:      "/peek/0/5",
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "not solving",
+			status:   model.StatusPaused,
+			url:      "/peek/0/1",
+			expected: http.StatusConflict,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, registry := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+			events := NewEventSubscription(t, registry, Channel.name)
+
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.Status = test.status
+			require.NoError(t, SetState(conn, Channel.name, state))
+
+			response := Channel.GET(test.url, router)
+			assert.Equal(t, test.expected, response.Code)
+			assert.Empty(t, Events(events, "peek"))
+
+			// Rejected peeks aren't counted as reveals.
+			loaded, err := GetState(conn, Channel.name)
+			require.NoError(t, err)
+			assert.Equal(t, 0, loaded.Reveals)
+		})
+	}
+
+	// Errors loading the state should be reported.
+	router, _, _ := NewTestRouter(t)
+	ForceErrorDuringStateLoad(t, errors.New("forced error"))
+	response := Channel.GET("/peek/0/1", router)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+
+	// Errors saving the state should be reported and nothing revealed.
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+	ForceErrorDuringStateLoad(t, nil)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	ForceErrorDuringStateSave(t, errors.New("forced error"))
+	response = Channel.GET("/peek/0/1", router)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+	assert.Empty(t, Events(events, "peek"))
+}
+
+func TestRoute_GetProgress(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	// There's no puzzle selected yet.
+	response := Channel.GET("/progress", router)
+	require.Equal(t, http.StatusNotFound, response.Code)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, state.ApplyAnswer("1a", "QANDA", false))
+	require.NoError(t, state.ApplyAnswer("6a", "ATTIC", false))
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response = Channel.GET("/progress", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var progress Progress
+	require.NoError(t, render.DecodeJSON(response.Body, &progress))
+	assert.Equal(t, 10, progress.CellsFilled)
+	assert.Equal(t, 187, progress.CellsTotal)
+	assert.Equal(t, 2, progress.CluesFilled)
+	assert.Equal(t, 74, progress.CluesTotal)
+	assert.Equal(t, 5, progress.Percent)
+
+	// Errors loading the state should be reported.
+	ForceErrorDuringStateLoad(t, errors.New("forced error"))
+	response = Channel.GET("/progress", router)
+	require.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
+func TestRoute_GetClueAge(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	// There's no puzzle selected yet.
+	response := Channel.GET("/clue/14a/age", router)
+	require.Equal(t, http.StatusNotFound, response.Code)
+
+	// The solve is paused, so none of the time since it was paused counts.
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusPaused
+	state.LastStartTime = nil
+	state.TotalSolveDuration = model.Duration{Duration: 4*time.Minute + 12*time.Second}
+	require.NoError(t, state.ApplyAnswer("1a", "QANDA", false))
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response = Channel.GET("/clue/14A/age", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var age map[string]interface{}
+	require.NoError(t, render.DecodeJSON(response.Body, &age))
+	assert.Equal(t, map[string]interface{}{"clue": "14a", "filled": false, "age": "4m12s"}, age)
+
+	// A clue that's already filled in isn't open.
+	response = Channel.GET("/clue/1a/age", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	require.NoError(t, render.DecodeJSON(response.Body, &age))
+	assert.Equal(t, map[string]interface{}{"clue": "1a", "filled": true, "age": "0s"}, age)
+
+	// Clues that don't exist in the puzzle can't have an age.
+	response = Channel.GET("/clue/1x/age", router)
+	require.Equal(t, http.StatusBadRequest, response.Code)
+	response = Channel.GET("/clue/2a/age", router)
+	require.Equal(t, http.StatusBadRequest, response.Code)
+
+	// Errors loading the state should be reported.
+	ForceErrorDuringStateLoad(t, errors.New("forced error"))
+	response = Channel.GET("/clue/14a/age", router)
+	require.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
+func TestRoute_MatchClue(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	// There's no puzzle selected yet.
+	response := Channel.GET("/clue/match?text=under+the+roof", router)
+	require.Equal(t, http.StatusNotFound, response.Code)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// A slightly misheard clue still matches.
+	response = Channel.GET("/clue/match?text=country+between+equator+and+bolivia", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var match map[string]interface{}
+	require.NoError(t, render.DecodeJSON(response.Body, &match))
+	assert.Equal(t, "19a", match["clue"])
+	assert.Greater(t, match["confidence"], 0.75)
+	assert.Less(t, match["confidence"], 1.0)
+
+	// Text that isn't anything like a clue doesn't match.
+	response = Channel.GET("/clue/match?text=purple+elephants+dancing", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	match = nil
+	require.NoError(t, render.DecodeJSON(response.Body, &match))
+	assert.NotContains(t, match, "clue")
+	assert.Less(t, match["confidence"], 0.75)
+
+	// Text is required.
+	response = Channel.GET("/clue/match?text=+", router)
+	require.Equal(t, http.StatusBadRequest, response.Code)
+
+	// Errors loading the state should be reported.
+	ForceErrorDuringStateLoad(t, errors.New("forced error"))
+	response = Channel.GET("/clue/match?text=under+the+roof", router)
+	require.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
+func TestRoute_GetLeaderboard(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	// There's no puzzle selected yet.
+	response := Channel.GET("/leaderboard", router)
+	require.Equal(t, http.StatusNotFound, response.Code)
+
+	// Nobody has solved any clues yet.
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response = Channel.GET("/leaderboard", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var leaderboard []LeaderboardEntry
+	require.NoError(t, render.DecodeJSON(response.Body, &leaderboard))
+	assert.Empty(t, leaderboard)
+
+	state.CreditSolver("1a", "alice")
+	state.CreditSolver("6a", "bob")
+	state.CreditSolver("1d", "bob")
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response = Channel.GET("/leaderboard", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, render.DecodeJSON(response.Body, &leaderboard))
+
+	expected := []LeaderboardEntry{
+		{User: "bob", Clues: 2, Rank: 1},
+		{User: "alice", Clues: 1, Rank: 2},
+	}
+	assert.Equal(t, expected, leaderboard)
+}
+
+func TestRoute_GetLeaderboard_Error(t *testing.T) {
+	router, _, _ := NewTestRouter(t)
+
+	ForceErrorDuringStateLoad(t, errors.New("forced error"))
+	response := Channel.GET("/leaderboard", router)
+	require.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
+func TestRoute_GetWeekdayHistogram(t *testing.T) {
+	for source, available := range AvailableDateLoaders {
+		source, available := source, available
+		t.Run(source, func(t *testing.T) {
+			router, _, _ := NewTestRouter(t)
+
+			response := GET(fmt.Sprintf("/crossword/sources/%s/weekdays", source), router)
+			require.Equal(t, http.StatusOK, response.Code)
+
+			var histogram map[string]int
+			require.NoError(t, render.DecodeJSON(response.Body, &histogram))
+			assert.Equal(t, 7, len(histogram))
+
+			var total int
+			for _, count := range histogram {
+				total += count
+			}
+			assert.Equal(t, len(available()), total)
+		})
+	}
+}
+
+func TestRoute_GetWeekdayHistogram_UnknownSource(t *testing.T) {
+	router, _, _ := NewTestRouter(t)
+
+	response := GET("/crossword/sources/unknown/weekdays", router)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestWeekdayHistogram(t *testing.T) {
+	dates := []time.Time{
+		time.Date(2020, time.January, 5, 0, 0, 0, 0, time.UTC),  // Sunday
+		time.Date(2020, time.January, 12, 0, 0, 0, 0, time.UTC), // Sunday
+		time.Date(2020, time.January, 6, 0, 0, 0, 0, time.UTC),  // Monday
+		time.Date(2020, time.January, 11, 0, 0, 0, 0, time.UTC), // Saturday
+	}
+
+	expected := map[string]int{
+		"Sunday":    2,
+		"Monday":    1,
+		"Tuesday":   0,
+		"Wednesday": 0,
+		"Thursday":  0,
+		"Friday":    0,
+		"Saturday":  1,
+	}
+	assert.Equal(t, expected, WeekdayHistogram(dates))
+}
+
+func TestRoute_GetRetention(t *testing.T) {
+	router, _, _ := NewTestRouter(t)
+
+	defer func(entries int, age time.Duration) { StatsMaxEntries, StatsMaxAge = entries, age }(StatsMaxEntries, StatsMaxAge)
+	StatsMaxEntries = 50
+	StatsMaxAge = 720 * time.Hour
+
+	response := GET("/crossword/retention", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var retention map[string]Retention
+	require.NoError(t, render.DecodeJSON(response.Body, &retention))
+	assert.Equal(t, Retention{MaxEntries: 50, MaxAge: model.Duration{Duration: 720 * time.Hour}}, retention["stats"])
+	assert.Equal(t, Retention{MaxEntries: AuditLogMaxEntries}, retention["audit"])
+}
+
+func TestRoute_GetCapabilities(t *testing.T) {
+	router, _, _ := NewTestRouter(t)
+
+	type Source struct {
+		Name  string `json:"name"`
+		Label string `json:"label"`
+		Key   string `json:"key"`
+		Input string `json:"input"`
+	}
+
+	response := GET("/crossword/capabilities", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var capabilities map[string][]Source
+	require.NoError(t, render.DecodeJSON(response.Body, &capabilities))
+	require.Equal(t, len(Sources), len(capabilities["sources"]))
+
+	inputs := make(map[string]string)
+	for _, source := range capabilities["sources"] {
+		inputs[source.Key] = source.Input
+	}
+	assert.Equal(t, "date", inputs["new_york_times_date"])
+	assert.Equal(t, "date", inputs["jonesin_date"])
+	assert.Equal(t, "id", inputs["archive_id"])
+	assert.Equal(t, "url", inputs["puz_file_url"])
+	assert.Equal(t, "file", inputs["puz_file_bytes"])
+
+	// Sources that are disabled aren't included.
+	original := PuzzleLoaders["atlantic"]
+	delete(PuzzleLoaders, "atlantic")
+	defer func() { PuzzleLoaders["atlantic"] = original }()
+
+	response = GET("/crossword/capabilities", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, render.DecodeJSON(response.Body, &capabilities))
+	assert.Equal(t, len(Sources)-1, len(capabilities["sources"]))
+	for _, source := range capabilities["sources"] {
+		assert.NotEqual(t, "atlantic", source.Name)
+	}
+}
+
+func TestRoute_CompareChannels(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	// The first channel has answered two clues and is currently solving.
+	start := time.Now()
+	a := NewState(t, "xwordinfo-nyt-20181231.json")
+	a.Status = model.StatusSolving
+	a.LastStartTime = &start
+	a.TotalSolveDuration = model.Duration{Duration: 3 * time.Minute}
+	require.NoError(t, a.ApplyAnswer("1a", "QANDA", false))
+	require.NoError(t, a.ApplyAnswer("6a", "ATTIC", false))
+	require.NoError(t, SetState(conn, "a", a))
+
+	// The second channel has answered one clue and is paused.
+	b := NewState(t, "xwordinfo-nyt-20181231.json")
+	b.Status = model.StatusPaused
+	b.LastStartTime = nil
+	b.TotalSolveDuration = model.Duration{Duration: 4 * time.Minute}
+	require.NoError(t, b.ApplyAnswer("1a", "QANDA", false))
+	require.NoError(t, SetState(conn, "b", b))
+
+	response := GET("/crossword/compare?a=a&b=b", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var comparison map[string]ChannelComparison
+	require.NoError(t, render.DecodeJSON(response.Body, &comparison))
+
+	assert.Equal(t, "a", comparison["a"].Channel)
+	assert.Equal(t, model.StatusSolving, comparison["a"].Status)
+	assert.Equal(t, 10, comparison["a"].CellsFilled)
+	assert.Equal(t, 2, comparison["a"].CluesFilled)
+	assert.Equal(t, 74, comparison["a"].CluesTotal)
+	assert.Equal(t, 5, comparison["a"].Percent)
+	assert.True(t, comparison["a"].Elapsed.Duration > 3*time.Minute)
+	assert.True(t, comparison["a"].Elapsed.Duration < 4*time.Minute)
+
+	assert.Equal(t, "b", comparison["b"].Channel)
+	assert.Equal(t, model.StatusPaused, comparison["b"].Status)
+	assert.Equal(t, 5, comparison["b"].CellsFilled)
+	assert.Equal(t, 1, comparison["b"].CluesFilled)
+	assert.Equal(t, 74, comparison["b"].CluesTotal)
+	assert.Equal(t, 2, comparison["b"].Percent)
+	assert.Equal(t, 4*time.Minute, comparison["b"].Elapsed.Duration)
+}
+
+func TestRoute_CompareChannels_Error(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	require.NoError(t, SetState(conn, "a", NewState(t, "xwordinfo-nyt-20181231.json")))
+	require.NoError(t, SetState(conn, "b", NewState(t, "xwordinfo-nyt-20181227-rebus.json")))
+	require.NoError(t, SetState(conn, "c", State{Status: model.StatusCreated}))
+
+	tests := []struct {
+		name     string
+		url      string
+		expected int
+	}{
+		{
+			name:     "missing channel",
+			url:      "/crossword/compare?a=a",
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "same channel",
+			url:      "/crossword/compare?a=a&b=a",
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "no puzzle selected",
+			url:      "/crossword/compare?a=a&b=c",
+			expected: http.StatusNotFound,
+		},
+		{
+			name:     "unknown channel",
+			url:      "/crossword/compare?a=a&b=unknown",
+			expected: http.StatusNotFound,
+		},
+		{
+			name:     "different puzzles",
+			url:      "/crossword/compare?a=a&b=b",
+			expected: http.StatusConflict,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := GET(test.url, router)
+			assert.Equal(t, test.expected, response.Code)
+		})
+	}
+
+	// Errors loading the state should be reported.
+	ForceErrorDuringStateLoad(t, errors.New("forced error"))
+	response := GET("/crossword/compare?a=a&b=b", router)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
+func TestRoute_GetClues(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	// There's no puzzle selected yet.
+	response := Channel.GET("/clues", router)
+	require.Equal(t, http.StatusNotFound, response.Code)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response = Channel.GET("/clues", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.NotContains(t, response.Body.String(), "OZONE")
+
+	type Clue struct {
+		Number int    `json:"number"`
+		Text   string `json:"text"`
+	}
+
+	var clues map[string][]Clue
+	require.NoError(t, render.DecodeJSON(response.Body, &clues))
+	require.Equal(t, len(state.Puzzle.CluesAcross), len(clues["across"]))
+	require.Equal(t, len(state.Puzzle.CluesDown), len(clues["down"]))
+
+	for direction, expected := range map[string]map[int]string{
+		"across": state.Puzzle.CluesAcross,
+		"down":   state.Puzzle.CluesDown,
+	} {
+		for i, clue := range clues[direction] {
+			assert.Equal(t, expected[clue.Number], clue.Text)
+			if i > 0 {
+				assert.Less(t, clues[direction][i-1].Number, clue.Number)
+			}
+		}
+	}
+
+	// Errors loading the state should be reported.
+	ForceErrorDuringStateLoad(t, errors.New("forced error"))
+	response = Channel.GET("/clues", router)
+	require.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
+func TestRoute_GetAnswerKey(t *testing.T) {
+	type Clue struct {
+		Number int    `json:"number"`
+		Text   string `json:"text"`
+		Answer string `json:"answer"`
+	}
+
+	for _, status := range []model.Status{model.StatusComplete, model.StatusAbandoned} {
+		t.Run(status.String(), func(t *testing.T) {
+			router, pool, _ := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.Status = status
+			require.NoError(t, SetState(conn, Channel.name, state))
+
+			response := Channel.GET("/answer-key", router)
+			require.Equal(t, http.StatusOK, response.Code)
+
+			var clues map[string][]Clue
+			require.NoError(t, render.DecodeJSON(response.Body, &clues))
+			require.Equal(t, len(state.Puzzle.CluesAcross), len(clues["across"]))
+			require.Equal(t, len(state.Puzzle.CluesDown), len(clues["down"]))
+
+			assert.Equal(t, Clue{Number: 1, Text: "Exchange after a lecture, informally", Answer: "QANDA"}, clues["across"][0])
+			assert.Equal(t, Clue{Number: 6, Text: "Room just under the roof", Answer: "ATTIC"}, clues["across"][1])
+			assert.Equal(t, Clue{Number: 1, Text: "Brand of swabs", Answer: "QTIP"}, clues["down"][0])
+
+			for _, direction := range []string{"across", "down"} {
+				for i := 1; i < len(clues[direction]); i++ {
+					assert.Less(t, clues[direction][i-1].Number, clues[direction][i].Number)
+				}
+			}
+		})
+	}
+}
+
+func TestRoute_GetAnswerKey_Forbidden(t *testing.T) {
+	for _, status := range []model.Status{model.StatusSelected, model.StatusSolving, model.StatusPaused} {
+		t.Run(status.String(), func(t *testing.T) {
+			router, pool, _ := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.Status = status
+			require.NoError(t, SetState(conn, Channel.name, state))
+
+			response := Channel.GET("/answer-key", router)
+			require.Equal(t, http.StatusForbidden, response.Code)
+			assert.NotContains(t, response.Body.String(), "QANDA")
+		})
+	}
+}
+
+func TestRoute_GetAnswerKey_Error(t *testing.T) {
+	router, _, _ := NewTestRouter(t)
+
+	// There's no puzzle selected yet.
+	response := Channel.GET("/answer-key", router)
+	require.Equal(t, http.StatusNotFound, response.Code)
+
+	// Errors loading the state should be reported.
+	ForceErrorDuringStateLoad(t, errors.New("forced error"))
+	response = Channel.GET("/answer-key", router)
+	require.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
+func TestRoute_GetText(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, state.ApplyAnswer("1a", "QANDA", false))
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response := Channel.GET("/text", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.True(t, strings.HasPrefix(response.Header().Get("Content-Type"), "text/plain"))
+
+	lines := strings.Split(response.Body.String(), "\n")
+	assert.Equal(t, "New York Times puzzle from 2018-12-31", lines[0])
+	assert.Equal(t, "Status: solving", lines[1])
+	assert.Contains(t, lines, "Row 1: Q A N D A # - - - - - # - - -")
+	assert.Contains(t, lines, "Row 5: # - - - - - - # # - - - # # #")
+	assert.Contains(t, lines, "1. Exchange after a lecture, informally (filled)")
+	assert.Contains(t, lines, "6. Room just under the roof (empty)")
+	assert.Contains(t, lines, "1. Brand of swabs (empty)")
+
+	// None of the unfilled parts of the solution are included.
+	assert.NotContains(t, response.Body.String(), "ATTIC")
+	assert.NotContains(t, response.Body.String(), "A T T I C")
+}
+
+func TestRoute_GetText_Error(t *testing.T) {
+	// No puzzle has been selected.
+	router, _, _ := NewTestRouter(t)
+	response := Channel.GET("/text", router)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	// Errors loading the state should be reported.
+	ForceErrorDuringStateLoad(t, errors.New("forced error"))
+	response = Channel.GET("/text", router)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
+func TestRoute_GetNumbering(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	// There's no numbering until a puzzle is selected.
+	response := Channel.GET("/numbering", router)
+	require.Equal(t, http.StatusNotFound, response.Code)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response = Channel.GET("/numbering", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var numbering Numbering
+	require.NoError(t, render.DecodeJSON(response.Result().Body, &numbering))
+	assert.Equal(t, 15, numbering.Rows)
+	assert.Equal(t, 15, numbering.Cols)
+	assert.Equal(t, state.Puzzle.CellBlocks, numbering.CellBlocks)
+
+	expected := [][]int{
+		{1, 2, 3, 4, 5, 0, 6, 7, 8, 9, 10, 0, 11, 12, 13},
+		{14, 0, 0, 0, 0, 0, 15, 0, 0, 0, 0, 16, 0, 0, 0},
+		{17, 0, 0, 0, 0, 18, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		{19, 0, 0, 0, 0, 20, 0, 0, 0, 0, 21, 0, 0, 0, 0},
+		{0, 22, 0, 0, 23, 0, 0, 0, 0, 24, 0, 0, 0, 0, 0},
+		{0, 0, 0, 25, 0, 0, 0, 26, 27, 0, 0, 0, 28, 29, 0},
+		{30, 31, 32, 0, 0, 0, 0, 33, 0, 0, 0, 0, 0, 0, 34},
+		{35, 0, 0, 0, 0, 36, 37, 0, 0, 0, 0, 0, 38, 0, 0},
+		{39, 0, 0, 40, 41, 0, 0, 0, 0, 0, 42, 43, 0, 0, 0},
+		{0, 44, 0, 0, 0, 0, 0, 0, 45, 46, 0, 0, 0, 0, 0},
+		{0, 0, 0, 47, 0, 0, 0, 0, 48, 0, 0, 0, 49, 50, 0},
+		{51, 52, 53, 0, 0, 0, 54, 55, 0, 0, 0, 56, 0, 0, 57},
+		{58, 0, 0, 0, 0, 59, 0, 0, 0, 0, 60, 0, 0, 0, 0},
+		{61, 0, 0, 0, 0, 0, 0, 0, 0, 0, 62, 0, 0, 0, 0},
+		{63, 0, 0, 0, 64, 0, 0, 0, 0, 0, 65, 0, 0, 0, 0},
+	}
+	assert.Equal(t, expected, numbering.CellClueNumbers)
+
+	// The solution is never included.
+	assert.NotContains(t, response.Body.String(), "QANDA")
+	assert.NotContains(t, response.Body.String(), `"cells"`)
+
+	// Errors loading the state should be reported.
+	ForceErrorDuringStateLoad(t, errors.New("forced error"))
+	response = Channel.GET("/numbering", router)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
+func TestRoute_GetCellInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		row, col int
+		expected CellInfo
+	}{
+		{
+			name: "numbered cell",
+			row:  0,
+			col:  0,
+			expected: CellInfo{
+				Number:     1,
+				AcrossClue: "1a",
+				DownClue:   "1d",
+			},
+		},
+		{
+			name: "unnumbered cell",
+			row:  1,
+			col:  2,
+			expected: CellInfo{
+				AcrossClue: "14a",
+				DownClue:   "3d",
+			},
+		},
+		{
+			name: "block",
+			row:  0,
+			col:  5,
+			expected: CellInfo{
+				Block: true,
+			},
+		},
+		{
+			name: "edge cell",
+			row:  14,
+			col:  14,
+			expected: CellInfo{
+				AcrossClue: "65a",
+				DownClue:   "57d",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, _ := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			require.NoError(t, SetState(conn, Channel.name, state))
+
+			response := Channel.GET(fmt.Sprintf("/cell-info/%d/%d", test.row, test.col), router)
+			require.Equal(t, http.StatusOK, response.Code)
+
+			var info CellInfo
+			require.NoError(t, render.DecodeJSON(response.Result().Body, &info))
+
+			test.expected.Row = test.row
+			test.expected.Col = test.col
+			assert.Equal(t, test.expected, info)
+		})
+	}
+}
+
+func TestRoute_GetCellInfo_WithoutCirclesOrShades(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	// Not every puzzle format has circled or shaded cells.
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Puzzle.CellCircles = nil
+	state.Puzzle.CellShades = nil
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response := Channel.GET("/cell-info/0/0", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var info CellInfo
+	require.NoError(t, render.DecodeJSON(response.Result().Body, &info))
+	assert.Equal(t, 1, info.Number)
+	assert.False(t, info.Circle)
+	assert.False(t, info.Shade)
+}
+
+func TestRoute_GetCellInfo_Error(t *testing.T) {
+	tests := []struct {
+		name           string
+		noPuzzle       bool
+		url            string
+		loadStateError error
+		expected       int
+	}{
+		{
+			name:     "malformed row",
+			url:      "/cell-info/a/0",
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "malformed col",
+			url:      "/cell-info/0/a",
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "row out of bounds",
+			url:      "/cell-info/15/0",
+			expected: http.StatusNotFound,
+		},
+		{
+			name:     "col out of bounds",
+			url:      "/cell-info/0/-1",
+			expected: http.StatusNotFound,
+		},
+		{
+			name:     "no puzzle selected",
+			noPuzzle: true,
+			url:      "/cell-info/0/0",
+			expected: http.StatusNotFound,
+		},
+		{
+			name:           "error loading state",
+			url:            "/cell-info/0/0",
+			loadStateError: errors.New("forced error"),
+			expected:       http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, _ := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+
+			if !test.noPuzzle {
+				state := NewState(t, "xwordinfo-nyt-20181231.json")
+				require.NoError(t, SetState(conn, Channel.name, state))
+			}
+			ForceErrorDuringStateLoad(t, test.loadStateError)
+
+			response := Channel.GET(test.url, router)
+			assert.Equal(t, test.expected, response.Code)
+		})
+	}
+}
+
+func TestRoute_Snapshot(t *testing.T) {
+	// This acts as a small integration test exporting a channel's solve,
+	// clearing it out and then importing it again.
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+	ForceAdminToken(t, "secret")
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusPaused
+	state.TotalSolveDuration = model.Duration{Duration: 10 * time.Minute}
+	require.NoError(t, state.ApplyAnswer("1a", "QANDA", false))
+	require.NoError(t, state.ApplyPencilAnswer("6a", "ATTIC"))
+	state.CreditSolver("1a", "alice")
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	expected, err := GetState(conn, Channel.name)
+	require.NoError(t, err)
+
+	response := Channel.ADMIN(http.MethodGet, "/snapshot", ``, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	snapshot := response.Body.String()
+
+	// The snapshot includes the solution.
+	assert.Contains(t, snapshot, `"cells":[["Q","A","N","D","A"`)
+
+	// Clear out the solve and then import the snapshot.  The raw source of the
+	// previous puzzle shouldn't survive the import.
+	_, err = conn.Do("DEL", StateKey(Channel.name))
+	require.NoError(t, err)
+	require.NoError(t, SetRawSource(conn, Channel.name, &RawSource{Source: "text_puzzle", Value: "text"}))
+
+	response = Channel.ADMIN(http.MethodPut, "/snapshot", snapshot, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(actual State) {
+		assert.Equal(t, model.StatusPaused, actual.Status)
+	})
+
+	actual, err := GetState(conn, Channel.name)
+	require.NoError(t, err)
+	actual.LastSaveTime = expected.LastSaveTime
+	assert.Equal(t, expected, actual)
+
+	raw, err := GetRawSource(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Nil(t, raw)
+}
+
+func TestRoute_Snapshot_ImportPausesSolve(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	ForceAdminToken(t, "secret")
+
+	start := time.Now().Add(-time.Hour)
+	save := start.Add(5 * time.Minute)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	state.LastStartTime = &start
+	state.LastSaveTime = &save
+	state.TotalSolveDuration = model.Duration{Duration: 10 * time.Minute}
+
+	bs, err := json.Marshal(state)
+	require.NoError(t, err)
+
+	response := Channel.ADMIN(http.MethodPut, "/snapshot", string(bs), router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	// Only the time up until the snapshot was saved is counted.
+	actual, err := GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusPaused, actual.Status)
+	assert.Nil(t, actual.LastStartTime)
+	assert.Equal(t, 15*time.Minute, actual.TotalSolveDuration.Duration)
+}
+
+func TestRoute_Snapshot_WithoutSolution(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+	ForceAdminToken(t, "secret")
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusPaused
+	require.NoError(t, state.ApplyAnswer("1a", "QANDA", false))
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response := Channel.ADMIN(http.MethodGet, "/snapshot?solution=false", ``, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	snapshot := response.Body.String()
+
+	// The snapshot keeps the structure of the puzzle and the progress of the
+	// solve, but not the solution.
+	var exported State
+	require.NoError(t, json.Unmarshal([]byte(snapshot), &exported))
+	assert.Nil(t, exported.Puzzle.Cells)
+	assert.Equal(t, state.Puzzle.CellBlocks, exported.Puzzle.CellBlocks)
+	assert.Equal(t, state.Puzzle.CluesAcross, exported.Puzzle.CluesAcross)
+	assert.Equal(t, state.Cells, exported.Cells)
+
+	// Clear out the solve and then import the snapshot.
+	_, err := conn.Do("DEL", StateKey(Channel.name))
+	require.NoError(t, err)
+
+	response = Channel.ADMIN(http.MethodPut, "/snapshot", snapshot, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, 1, len(Events(events, "state")))
+
+	// Without a solution answers can't be checked, so they're accepted as given
+	// and the puzzle is never considered complete.
+	actual, err := GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.False(t, actual.Puzzle.HasSolution())
+	assert.Equal(t, model.StatusPaused, actual.Status)
+	assert.True(t, actual.AcrossCluesFilled[1])
+	assert.NoError(t, actual.ApplyAnswer("1d", "QXYZ", true))
+	assert.False(t, actual.IsClueCorrect("1a"))
+	assert.False(t, actual.IsComplete(0))
+	assert.NoError(t, actual.ClearIncorrectCells())
+	assert.Equal(t, "Q", actual.Cells[0][0])
+	assert.Equal(t, "X", actual.Cells[1][0])
+
+	// The features that reveal the solution are unavailable.
+	actual.Status = model.StatusSolving
+	require.NoError(t, SetState(conn, Channel.name, actual))
+	response = Channel.GET("/peek/0/0", router)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	actual.Status = model.StatusComplete
+	require.NoError(t, SetState(conn, Channel.name, actual))
+	response = Channel.GET("/answer-key", router)
+	assert.Equal(t, http.StatusConflict, response.Code)
+}
+
+func TestRoute_Snapshot_Error(t *testing.T) {
+	valid := NewState(t, "xwordinfo-nyt-20181231.json")
+
+	invalid := NewState(t, "xwordinfo-nyt-20181231.json")
+	invalid.Puzzle.Cells[0][0] = ""
+
+	mismatched := NewState(t, "xwordinfo-nyt-20181231.json")
+	mismatched.Cells = mismatched.Cells[1:]
+
+	encode := func(state State) string {
+		bs, err := json.Marshal(state)
+		require.NoError(t, err)
+		return string(bs)
+	}
+
+	tests := []struct {
+		name           string
+		token          string
+		method         string
+		query          string
+		body           string
+		stateLoadError error
+		stateSaveError error
+		expected       int
+	}{
+		{
+			name:     "export with admin endpoints disabled",
+			method:   http.MethodGet,
+			expected: http.StatusForbidden,
+		},
+		{
+			name:     "import with admin endpoints disabled",
+			method:   http.MethodPut,
+			body:     encode(valid),
+			expected: http.StatusForbidden,
+		},
+		{
+			name:     "export without a puzzle selected",
+			token:    "secret",
+			method:   http.MethodGet,
+			expected: http.StatusNotFound,
+		},
+		{
+			name:           "export with error loading state",
+			token:          "secret",
+			method:         http.MethodGet,
+			stateLoadError: errors.New("forced error"),
+			expected:       http.StatusInternalServerError,
+		},
+		{
+			name:     "export with malformed solution flag",
+			token:    "secret",
+			method:   http.MethodGet,
+			query:    "?solution=maybe",
+			expected: http.StatusBadRequest,
 		},
 		{
-			name:           "error loading state",
-			initialStatus:  model.StatusSelected,
-			loadStateError: errors.New("forced error"),
+			name:     "import malformed snapshot",
+			token:    "secret",
+			method:   http.MethodPut,
+			body:     `{`,
+			expected: http.StatusBadRequest,
 		},
 		{
-			name:           "error saving state",
-			initialStatus:  model.StatusSelected,
-			saveStateError: errors.New("forced error"),
+			name:     "import snapshot without a puzzle",
+			token:    "secret",
+			method:   http.MethodPut,
+			body:     `{"status": "paused"}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "import snapshot with invalid puzzle",
+			token:    "secret",
+			method:   http.MethodPut,
+			body:     encode(invalid),
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "import snapshot with mismatched cells",
+			token:    "secret",
+			method:   http.MethodPut,
+			body:     encode(mismatched),
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:           "import with error saving state",
+			token:          "secret",
+			method:         http.MethodPut,
+			body:           encode(valid),
+			stateSaveError: errors.New("forced error"),
+			expected:       http.StatusInternalServerError,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			router, pool, _ := NewTestRouter(t)
-			conn := NewRedisConnection(t, pool)
-
-			state := NewState(t, "xwordinfo-nyt-20181231.json")
-			state.Status = test.initialStatus
-			require.NoError(t, SetState(conn, Channel.name, state))
-
-			if test.loadStateError != nil {
-				ForceErrorDuringStateLoad(t, test.loadStateError)
-			}
-
-			if test.saveStateError != nil {
-				ForceErrorDuringStateSave(t, test.saveStateError)
-			}
+			router, _, _ := NewTestRouter(t)
+			ForceAdminToken(t, test.token)
+			ForceErrorDuringStateLoad(t, test.stateLoadError)
+			ForceErrorDuringStateSave(t, test.stateSaveError)
 
-			response := Channel.PUT("/status", "", router)
-			assert.NotEqual(t, http.StatusOK, response.Code)
+			response := Channel.ADMIN(test.method, "/snapshot"+test.query, test.body, router)
+			assert.Equal(t, test.expected, response.Code)
 		})
 	}
 }
 
-func TestRoute_UpdateAnswer_AllowIncorrectAnswers(t *testing.T) {
-	// This acts as a small integration test of applying answers to a crossword
-	// being solved.
+func TestRoute_ReparsePuzzle(t *testing.T) {
+	// This acts as a small integration test pasting an Across Lite text puzzle,
+	// making progress on it and then reparsing it with an improved parser.
 	router, pool, registry := NewTestRouter(t)
 	conn := NewRedisConnection(t, pool)
 	events := NewEventSubscription(t, registry, Channel.name)
+	ForceAdminToken(t, "secret")
 
-	state := NewState(t, "xwordinfo-nyt-20181231.json")
-	state.Status = model.StatusSolving
-	require.NoError(t, SetState(conn, Channel.name, state))
+	require.NoError(t, SetSettings(conn, Channel.name, Settings{StoreRawSource: true}))
 
-	// Apply a correct across answer.
-	response := Channel.PUT("/answer/1a", `"QANDA"`, router)
-	assert.Equal(t, http.StatusOK, response.Code)
-	VerifyState(t, pool, events, func(state State) {
-		assert.True(t, state.AcrossCluesFilled[1])
-		assert.Equal(t, "Q", state.Cells[0][0])
-		assert.Equal(t, "A", state.Cells[0][1])
-		assert.Equal(t, "N", state.Cells[0][2])
-		assert.Equal(t, "D", state.Cells[0][3])
-		assert.Equal(t, "A", state.Cells[0][4])
-	})
+	reader := load(t, path.Join("acrosslite", "rebus-and-circles.txt"))
+	defer reader.Close()
+	bs, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
 
-	// Apply a correct down answer.
-	response = Channel.PUT("/answer/1d", `"QTIP"`, router)
-	assert.Equal(t, http.StatusOK, response.Code)
-	VerifyState(t, pool, events, func(state State) {
-		assert.True(t, state.DownCluesFilled[1])
-		assert.Equal(t, "Q", state.Cells[0][0])
-		assert.Equal(t, "T", state.Cells[1][0])
-		assert.Equal(t, "I", state.Cells[2][0])
-		assert.Equal(t, "P", state.Cells[3][0])
-	})
+	body, err := json.Marshal(map[string]string{"text_puzzle": string(bs)})
+	require.NoError(t, err)
 
-	// Apply an incorrect across answer.
-	response = Channel.PUT("/answer/6a", `"FLOOR"`, router)
-	assert.Equal(t, http.StatusOK, response.Code)
+	response := Channel.PUT("/", string(body), router)
+	require.Equal(t, http.StatusOK, response.Code)
 	VerifyState(t, pool, events, func(state State) {
-		assert.True(t, state.AcrossCluesFilled[6])
-		assert.Equal(t, "F", state.Cells[0][6])
-		assert.Equal(t, "L", state.Cells[0][7])
-		assert.Equal(t, "O", state.Cells[0][8])
-		assert.Equal(t, "O", state.Cells[0][9])
-		assert.Equal(t, "R", state.Cells[0][10])
+		assert.Equal(t, "Region", state.Puzzle.CluesAcross[5])
 	})
 
-	// Apply an incorrect down answer.
-	response = Channel.PUT("/answer/11d", `"HEYA"`, router)
-	assert.Equal(t, http.StatusOK, response.Code)
-	VerifyState(t, pool, events, func(state State) {
-		assert.True(t, state.DownCluesFilled[11])
-		assert.Equal(t, "H", state.Cells[0][12])
-		assert.Equal(t, "E", state.Cells[1][12])
-		assert.Equal(t, "Y", state.Cells[2][12])
-		assert.Equal(t, "A", state.Cells[3][12])
-	})
+	raw, err := GetRawSource(conn, Channel.name)
+	require.NoError(t, err)
+	require.NotNil(t, raw)
+	assert.Equal(t, "text_puzzle", raw.Source)
+	assert.Equal(t, string(bs), raw.Value)
 
-	// Pause the solve.
+	// Make some progress on the solve.
 	response = Channel.PUT("/status", ``, router)
 	require.Equal(t, http.StatusOK, response.Code)
+	response = Channel.PUT("/answer/1a", `"(CH)ARD"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	Events(events, "state")
 
-	// Try to apply an answer.
-	response = Channel.PUT("/answer/6a", `"ATTIC"`, router)
-	assert.Equal(t, http.StatusConflict, response.Code)
-}
-
-func TestRoute_UpdateAnswer_OnlyAllowCorrectAnswers(t *testing.T) {
-	// This acts as a small integration test toggling the status of a crossword
-	// being solved.
-	router, pool, registry := NewTestRouter(t)
-	conn := NewRedisConnection(t, pool)
-	events := NewEventSubscription(t, registry, Channel.name)
-
-	// Change the settings to only allow correct answers.
-	settings := Settings{OnlyAllowCorrectAnswers: true}
-	require.NoError(t, SetSettings(conn, Channel.name, settings))
-
-	state := NewState(t, "xwordinfo-nyt-20181231.json")
-	state.Status = model.StatusSolving
-	require.NoError(t, SetState(conn, Channel.name, state))
+	// Improve the parser so that it produces a different clue.
+	ForceSourceLoader(t, "text_puzzle", func(text string) (*Puzzle, error) {
+		puzzle, err := LoadFromAcrossLiteText(text)
+		if err != nil {
+			return nil, err
+		}
 
-	// Apply a correct across answer.
-	response := Channel.PUT("/answer/1a", `"QANDA"`, router)
-	assert.Equal(t, http.StatusOK, response.Code)
-	VerifyState(t, pool, events, func(state State) {
-		assert.True(t, state.AcrossCluesFilled[1])
-		assert.Equal(t, "Q", state.Cells[0][0])
-		assert.Equal(t, "A", state.Cells[0][1])
-		assert.Equal(t, "N", state.Cells[0][2])
-		assert.Equal(t, "D", state.Cells[0][3])
-		assert.Equal(t, "A", state.Cells[0][4])
+		puzzle.CluesAcross[5] = "Area"
+		return puzzle, nil
 	})
 
-	// Apply a correct down answer.
-	response = Channel.PUT("/answer/1d", `"QTIP"`, router)
-	assert.Equal(t, http.StatusOK, response.Code)
+	response = Channel.ADMIN(http.MethodPost, "/reparse", ``, router)
+	require.Equal(t, http.StatusOK, response.Code)
 	VerifyState(t, pool, events, func(state State) {
-		assert.True(t, state.DownCluesFilled[1])
-		assert.Equal(t, "Q", state.Cells[0][0])
-		assert.Equal(t, "T", state.Cells[1][0])
-		assert.Equal(t, "I", state.Cells[2][0])
-		assert.Equal(t, "P", state.Cells[3][0])
+		assert.Equal(t, model.StatusSolving, state.Status)
+		assert.Equal(t, "Area", state.Puzzle.CluesAcross[5])
+		assert.Equal(t, []string{"CH", "A", "R", "D"}, state.Cells[0])
+		assert.True(t, state.AcrossCluesFilled[1])
+		assert.False(t, state.AcrossCluesFilled[5])
 	})
-
-	// Apply an incorrect across answer.
-	response = Channel.PUT("/answer/6a", `"FLOOR"`, router)
-	assert.Equal(t, http.StatusBadRequest, response.Code)
-
-	// Apply an incorrect down answer.
-	response = Channel.PUT("/answer/11d", `"HEYA"`, router)
-	assert.Equal(t, http.StatusBadRequest, response.Code)
 }
 
-func TestRoute_UpdateAnswer_SolvedPuzzleStopsTimer(t *testing.T) {
-	// This acts as a small integration test ensuring that the timer stops
-	// counting once the crossword has been solved.
+func TestRoute_ReparsePuzzle_GridChanged(t *testing.T) {
 	router, pool, registry := NewTestRouter(t)
 	conn := NewRedisConnection(t, pool)
 	events := NewEventSubscription(t, registry, Channel.name)
+	ForceAdminToken(t, "secret")
 
-	// Setup a state that has the entire puzzle solved except for the last answer.
 	state := NewState(t, "xwordinfo-nyt-20181231.json")
-	state.Status = model.StatusSolving
-	state.ApplyAnswer("1a", "Q AND A", false)
-	state.ApplyAnswer("6a", "ATTIC", false)
-	state.ApplyAnswer("11a", "HON", false)
-	state.ApplyAnswer("14a", "THIRD", false)
-	state.ApplyAnswer("15a", "LAID ASIDE", false)
-	state.ApplyAnswer("17a", "IM TOO OLD FOR THIS", false)
-	state.ApplyAnswer("19a", "PERU", false)
-	state.ApplyAnswer("20a", "LEAF", false)
-	state.ApplyAnswer("21a", "PEONS", false)
-	state.ApplyAnswer("22a", "DOG TAG", false)
-	state.ApplyAnswer("24a", "LOL", false)
-	state.ApplyAnswer("25a", "HAVE NO OOMPH", false)
-	state.ApplyAnswer("30a", "MATTE", false)
-	state.ApplyAnswer("33a", "IMPLORED", false)
-	state.ApplyAnswer("35a", "ERR", false)
-	state.ApplyAnswer("36a", "RANGE", false)
-	state.ApplyAnswer("38a", "EMO", false)
-	state.ApplyAnswer("39a", "WAIT HERE", false)
-	state.ApplyAnswer("42a", "EGYPT", false)
-	state.ApplyAnswer("44a", "BOO OFF STAGE", false)
-	state.ApplyAnswer("47a", "ERS", false)
-	state.ApplyAnswer("48a", "EUGENE", false)
-	state.ApplyAnswer("51a", "SHARI", false)
-	state.ApplyAnswer("54a", "SINN", false)
-	state.ApplyAnswer("56a", "WING", false)
-	state.ApplyAnswer("58a", "ITS A ZOO OUT THERE", false)
-	state.ApplyAnswer("61a", "STEGOSAUR", false)
-	state.ApplyAnswer("62a", "HIT ON", false)
-	state.ApplyAnswer("63a", "IPA", false)
-	state.ApplyAnswer("64a", "NURSE", false)
+	state.Status = model.StatusPaused
+	require.NoError(t, state.ApplyAnswer("1a", "QANDA", false))
 	require.NoError(t, SetState(conn, Channel.name, state))
+	require.NoError(t, SetRawSource(conn, Channel.name, &RawSource{Source: "text_puzzle", Value: "text"}))
 
-	// Apply the last answer, but wait a bit first to ensure that a non-zero
-	// amount of time has passed in the solve.
-	time.Sleep(10 * time.Millisecond)
+	// The reparsed puzzle has a different grid so the progress can't be kept.
+	ForceSourceLoader(t, "text_puzzle", func(string) (*Puzzle, error) {
+		return LoadTestPuzzle(t, "xwordinfo-nyt-20180621-nonsquare.json"), nil
+	})
 
-	response := Channel.PUT("/answer/65a", `"OZONE"`, router)
-	assert.Equal(t, http.StatusOK, response.Code)
+	response := Channel.ADMIN(http.MethodPost, "/reparse", ``, router)
+	require.Equal(t, http.StatusOK, response.Code)
 	VerifyState(t, pool, events, func(state State) {
-		require.Equal(t, model.StatusComplete, state.Status)
-		assert.Nil(t, state.LastStartTime)
-		assert.True(t, state.TotalSolveDuration.Seconds() > 0)
+		assert.Equal(t, model.StatusSelected, state.Status)
+		assert.Equal(t, "", state.Cells[0][0])
+		assert.Empty(t, state.AcrossCluesFilled)
 	})
 }
 
-func TestRoute_UpdateAnswer_Error(t *testing.T) {
+func TestRoute_ReparsePuzzle_Error(t *testing.T) {
 	tests := []struct {
-		name     string
-		json     string
-		expected int
+		name           string
+		noPuzzle       bool
+		raw            *RawSource
+		loader         func(string) (*Puzzle, error)
+		loadStateError error
+		saveStateError error
+		expected       int
 	}{
 		{
-			name:     "empty answer",
-			json:     `""`,
-			expected: http.StatusBadRequest,
+			name:     "no puzzle selected",
+			noPuzzle: true,
+			raw:      &RawSource{Source: "text_puzzle", Value: "text"},
+			expected: http.StatusNotFound,
 		},
 		{
-			name:     "too long answer",
-			json:     `"` + RandomString(1023) + `"`,
-			expected: http.StatusRequestEntityTooLarge,
+			name:     "no raw source stored",
+			expected: http.StatusConflict,
 		},
 		{
-			name:     "malformed json",
-			json:     `"`,
-			expected: http.StatusBadRequest,
+			name:     "unknown source",
+			raw:      &RawSource{Source: "unknown", Value: "text"},
+			expected: http.StatusInternalServerError,
 		},
-	}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			router, pool, _ := NewTestRouter(t)
-			conn := NewRedisConnection(t, pool)
-
-			state := NewState(t, "xwordinfo-nyt-20181231.json")
-			state.Status = model.StatusSolving
-			require.NoError(t, SetState(conn, Channel.name, state))
-
-			response := Channel.PUT("/answer/1a", test.json, router)
-			assert.Equal(t, test.expected, response.Code)
-		})
-	}
-}
-
-func TestRoute_UpdateAnswer_LoadSaveError(t *testing.T) {
-	tests := []struct {
-		name              string
-		loadSettingsError error
-		loadStateError    error
-		saveStateError    error
-	}{
 		{
-			name:              "error loading settings",
-			loadSettingsError: errors.New("forced error"),
+			name: "error parsing raw source",
+			raw:  &RawSource{Source: "text_puzzle", Value: "text"},
+			loader: func(string) (*Puzzle, error) {
+				return nil, errors.New("forced error")
+			},
+			expected: http.StatusInternalServerError,
 		},
 		{
 			name:           "error loading state",
+			raw:            &RawSource{Source: "text_puzzle", Value: "text"},
 			loadStateError: errors.New("forced error"),
+			expected:       http.StatusInternalServerError,
 		},
 		{
 			name:           "error saving state",
+			raw:            &RawSource{Source: "text_puzzle", Value: "text"},
 			saveStateError: errors.New("forced error"),
+			expected:       http.StatusInternalServerError,
 		},
 	}
 
@@ -684,57 +5063,137 @@ func TestRoute_UpdateAnswer_LoadSaveError(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			router, pool, _ := NewTestRouter(t)
 			conn := NewRedisConnection(t, pool)
+			ForceAdminToken(t, "secret")
 
-			state := NewState(t, "xwordinfo-nyt-20181231.json")
-			state.Status = model.StatusSolving
-			require.NoError(t, SetState(conn, Channel.name, state))
+			if !test.noPuzzle {
+				state := NewState(t, "xwordinfo-nyt-20181231.json")
+				require.NoError(t, SetState(conn, Channel.name, state))
+			}
+			require.NoError(t, SetRawSource(conn, Channel.name, test.raw))
 
-			ForceErrorDuringSettingsLoad(t, test.loadSettingsError)
+			loader := test.loader
+			if loader == nil {
+				loader = func(string) (*Puzzle, error) {
+					return LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json"), nil
+				}
+			}
+			ForceSourceLoader(t, "text_puzzle", loader)
 			ForceErrorDuringStateLoad(t, test.loadStateError)
 			ForceErrorDuringStateSave(t, test.saveStateError)
 
-			response := Channel.PUT("/answer/1a", `"QANDA"`, router)
-			assert.NotEqual(t, http.StatusOK, response.Code)
+			response := Channel.ADMIN(http.MethodPost, "/reparse", ``, router)
+			assert.Equal(t, test.expected, response.Code)
 		})
 	}
 }
 
-func TestRoute_ShowClue(t *testing.T) {
-	// This acts as a small integration test requesting clues to be shown and
-	// making sure events are properly emitted.
-	router, pool, registry := NewTestRouter(t)
+func TestRoute_UpdatePuzzle_RawSourceNotStored(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	// A raw source left over from a previous puzzle is removed when a puzzle is
+	// selected without storing its raw source.
+	require.NoError(t, SetRawSource(conn, Channel.name, &RawSource{Source: "text_puzzle", Value: "text"}))
+	ForcePuzzleToBeLoaded(t, "xwordinfo-nyt-20181231.json")
+
+	response := Channel.PUT("/", `{"new_york_times_date": "2018-12-31"}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	raw, err := GetRawSource(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Nil(t, raw)
+}
+
+func TestRoute_UpdatePuzzle_RawSourceNotStoredForDate(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	// Puzzles selected by date can be loaded again from their source, so there's
+	// nothing to store even when the channel wants raw sources kept.
+	require.NoError(t, SetSettings(conn, Channel.name, Settings{StoreRawSource: true}))
+	ForcePuzzleToBeLoaded(t, "xwordinfo-nyt-20181231.json")
+
+	response := Channel.PUT("/", `{"new_york_times_date": "2018-12-31"}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	raw, err := GetRawSource(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Nil(t, raw)
+}
+
+func TestRoute_GetDebug(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
 	conn := NewRedisConnection(t, pool)
-	events := NewEventSubscription(t, registry, Channel.name)
+	ForceAdminToken(t, "secret")
 
-	// Force a specific puzzle to be loaded so we don't make a network call.
 	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusPaused
+	require.NoError(t, state.ApplyAnswer("1a", "QANDA", false))
 	require.NoError(t, SetState(conn, Channel.name, state))
 
-	// Request showing an across clue.
-	response := Channel.GET("/show/1a", router)
-	require.Equal(t, http.StatusOK, response.Code)
-	VerifyShowClue(t, events, func(clue string) {
-		assert.Equal(t, "1a", clue)
-	})
+	settings := Settings{OnlyAllowCorrectAnswers: true, CompleteThreshold: 90}
+	require.NoError(t, SetSettings(conn, Channel.name, settings))
 
-	// Request showing a down clue.
-	response = Channel.GET("/show/16d", router)
-	require.Equal(t, http.StatusOK, response.Code)
-	VerifyShowClue(t, events, func(clue string) {
-		assert.Equal(t, "16d", clue)
-	})
+	rawState, err := redis.String(conn.Do("GET", StateKey(Channel.name)))
+	require.NoError(t, err)
+	rawSettings, err := redis.String(conn.Do("GET", SettingsKey(Channel.name)))
+	require.NoError(t, err)
 
-	// Request showing a malformed clue.
-	response = Channel.GET("/show/1x", router)
-	require.Equal(t, http.StatusBadRequest, response.Code)
+	type Key struct {
+		Key   string  `json:"key"`
+		Value *string `json:"value"`
+		TTL   int     `json:"ttl"`
+	}
 
-	// Request showing a properly formed, but non-existent clue.  This doesn't
-	// fail because it doesn't mutate the state of the puzzle in any way.
-	response = Channel.GET("/show/999a", router)
-	require.Equal(t, http.StatusOK, response.Code)
-	VerifyShowClue(t, events, func(clue string) {
-		assert.Equal(t, "999a", clue)
-	})
+	debug := func(path string) map[string]Key {
+		response := Channel.ADMIN(http.MethodGet, path, ``, router)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var keys map[string]Key
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &keys))
+		return keys
+	}
+
+	// By default the solution is removed from the stored state.
+	keys := debug("/debug")
+	assert.Equal(t, StateKey(Channel.name), keys["state"].Key)
+	assert.True(t, keys["state"].TTL > 0)
+	assert.Equal(t, SettingsKey(Channel.name), keys["settings"].Key)
+	assert.Equal(t, -1, keys["settings"].TTL)
+	assert.Equal(t, rawSettings, *keys["settings"].Value)
+
+	var redacted State
+	require.NoError(t, json.Unmarshal([]byte(*keys["state"].Value), &redacted))
+	assert.Nil(t, redacted.Puzzle.Cells)
+	assert.Equal(t, state.Cells, redacted.Cells)
+	assert.Equal(t, state.Puzzle.CluesAcross, redacted.Puzzle.CluesAcross)
+
+	// Forcing it returns exactly what is stored.
+	keys = debug("/debug?force=true")
+	assert.Equal(t, rawState, *keys["state"].Value)
+	assert.Equal(t, rawSettings, *keys["settings"].Value)
+
+	// Keys that don't exist have no value.
+	_, err = conn.Do("DEL", StateKey(Channel.name), SettingsKey(Channel.name))
+	require.NoError(t, err)
+
+	keys = debug("/debug")
+	assert.Nil(t, keys["state"].Value)
+	assert.Equal(t, -2, keys["state"].TTL)
+	assert.Nil(t, keys["settings"].Value)
+	assert.Equal(t, -2, keys["settings"].TTL)
+}
+
+func TestRoute_GetDebug_Error(t *testing.T) {
+	router, _, _ := NewTestRouter(t)
+
+	// The endpoint requires admin access.
+	response := Channel.ADMIN(http.MethodGet, "/debug", ``, router)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	ForceAdminToken(t, "secret")
+	response = Channel.GET("/debug", router)
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
 }
 
 func TestRoute_GetEvents(t *testing.T) {
@@ -778,19 +5237,225 @@ func TestRoute_GetEvents(t *testing.T) {
 	assert.Equal(t, 1, len(events))
 	assert.Equal(t, "state", events[0].Kind)
 
-	// Now change a setting, this should cause the settings to be sent again.
+	// Now change a setting, this should cause the settings to be sent again
+	// along with a description of what changed.
 	response = Channel.PUT("/setting/clue_font_size", `"xlarge"`, router)
 	assert.Equal(t, http.StatusOK, response.Code)
 
 	events = flush()
-	assert.Equal(t, 1, len(events))
+	assert.Equal(t, 2, len(events))
 	assert.Equal(t, "settings", events[0].Kind)
+	assert.Equal(t, "setting_changed", events[1].Kind)
 
 	// Disconnect, there shouldn't be any events anymore.
 	events = stop()
 	assert.Equal(t, 0, len(events))
 }
 
+func TestRoute_Resync(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	settings := Settings{ClueFontSize: model.FontSizeLarge}
+	require.NoError(t, SetSettings(conn, Channel.name, settings))
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.FocusedClue = "1a"
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	response := Channel.GET("/resync", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	// The settings, state and focused clue are re-emitted to subscribers.
+	found := Events(events, "settings")
+	require.Equal(t, 1, len(found))
+	assert.Equal(t, settings, found[0].Payload)
+
+	response = Channel.GET("/resync", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	found = Events(events, "state")
+	require.Equal(t, 1, len(found))
+	payload := found[0].Payload.(State)
+	assert.Equal(t, state.Cells, payload.Cells)
+	assert.Nil(t, payload.Puzzle.Cells)
+
+	// The events are also returned in the response.
+	var body []pubsub.Event
+	require.NoError(t, render.DecodeJSON(response.Body, &body))
+	require.Equal(t, 3, len(body))
+	assert.Equal(t, "settings", body[0].Kind)
+	assert.Equal(t, "state", body[1].Kind)
+	assert.Equal(t, "focus", body[2].Kind)
+}
+
+func TestRoute_Resync_LoadError(t *testing.T) {
+	router, _, registry := NewTestRouter(t)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	ForceErrorDuringSettingsLoad(t, errors.New("forced error"))
+
+	response := Channel.GET("/resync", router)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+	assert.Empty(t, events)
+}
+
+func TestRoute_GetEvents_Elapsed(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	// The solve has accumulated 90 seconds from earlier runs and has been running
+	// for another 30 seconds since the server started.
+	start := time.Now().Add(-30 * time.Second)
+	defer func(original time.Time) { model.ServerStartTime = original }(model.ServerStartTime)
+	model.ServerStartTime = start.Add(-time.Second)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	state.LastStartTime = &start
+	state.TotalSolveDuration = model.Duration{Duration: 90 * time.Second}
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// A client that reconnects receives the elapsed time along with the raw
+	// timer fields.
+	_, stop := Channel.SSE("/events", router)
+	events := stop()
+	require.Equal(t, 2, len(events))
+	require.Equal(t, "state", events[1].Kind)
+
+	payload := events[1].Payload.(map[string]interface{})
+	assert.InDelta(t, 120, payload["elapsed"], 1)
+	assert.Equal(t, "1m30s", payload["total_solve_duration"])
+	assert.NotNil(t, payload["last_start_time"])
+}
+
+func TestStateEvent_QueuedAnswers(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusPaused
+	require.NoError(t, state.QueueAnswer("1a", "QANDA", AnswerMetadata{User: "alice"}))
+
+	// Queued answers aren't sent to clients, but remain in the state.
+	event := StateEvent(state)
+	assert.Empty(t, event.Payload.(State).QueuedAnswers)
+	assert.Equal(t, 1, len(state.QueuedAnswers))
+}
+
+func TestStateEvent_Elapsed(t *testing.T) {
+	start := time.Now().Add(-30 * time.Second)
+
+	tests := []struct {
+		name     string
+		status   model.Status
+		start    *time.Time
+		expected float64
+	}{
+		{
+			name:     "solving",
+			status:   model.StatusSolving,
+			start:    &start,
+			expected: 120,
+		},
+		{
+			name:     "paused",
+			status:   model.StatusPaused,
+			expected: 90,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := State{
+				Status:             test.status,
+				LastStartTime:      test.start,
+				TotalSolveDuration: model.Duration{Duration: 90 * time.Second},
+			}
+
+			event := StateEvent(state)
+			assert.InDelta(t, test.expected, event.Payload.(State).Elapsed, 1)
+		})
+	}
+}
+
+func TestRoute_GetEvents_MaxSubscribers(t *testing.T) {
+	router, _, registry := NewTestRouter(t)
+	registry.MaxSubscribersPerChannel = 1
+
+	// Wait for the number of clients subscribed to the channel to reach the
+	// expected value since streams subscribe in the background.
+	waitForSubscribers := func(expected int) {
+		t.Helper()
+
+		deadline := time.Now().Add(time.Second)
+		for registry.NumSubscribers(ChannelID(Channel.name)) != expected {
+			if time.Now().After(deadline) {
+				require.Fail(t, "timed out waiting for subscribers", "expected %d", expected)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	_, stop := Channel.SSE("/events", router)
+	waitForSubscribers(1)
+
+	// The channel is full so another client is turned away.
+	response := Channel.GET("/events", router)
+	assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	assert.Equal(t, pubsub.SubscriberRetryAfter, response.Header().Get("Retry-After"))
+
+	// Once the first client disconnects there's room for another.
+	stop()
+	waitForSubscribers(0)
+
+	_, stop = Channel.SSE("/events", router)
+	events := stop()
+	require.Equal(t, 1, len(events))
+	assert.Equal(t, "settings", events[0].Kind)
+}
+
+func TestRoute_GetEvents_TransientLoadError(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// Redis is unavailable when the client connects.
+	testStateLoadError = errors.New("forced error")
+	t.Cleanup(func() { testStateLoadError = nil })
+
+	flush, stop := Channel.SSE("/events", router)
+	defer stop()
+
+	// Flush the stream until at least n events have been received.
+	receive := func(n int) []pubsub.Event {
+		var events []pubsub.Event
+		for deadline := time.Now().Add(time.Second); len(events) < n && time.Now().Before(deadline); {
+			events = append(events, flush()...)
+		}
+		return events
+	}
+
+	events := receive(1)
+	require.Equal(t, 1, len(events))
+	assert.Equal(t, "error", events[0].Kind)
+
+	// Events published while redis is unavailable still reach the client.
+	registry.Publish(ChannelID(Channel.name), ShowClueEvent("1a"))
+	events = receive(1)
+	require.Equal(t, 1, len(events))
+	assert.Equal(t, "show_clue", events[0].Kind)
+
+	// Once redis is available again the client receives the initial events.
+	testStateLoadError = nil
+
+	events = receive(2)
+	require.Equal(t, 2, len(events))
+	assert.Equal(t, "settings", events[0].Kind)
+	assert.Equal(t, "state", events[1].Kind)
+}
+
 func TestRoute_GetEvents_LoadSaveError(t *testing.T) {
 	tests := []struct {
 		name                   string
@@ -818,14 +5483,67 @@ func TestRoute_GetEvents_LoadSaveError(t *testing.T) {
 			ForceErrorDuringSettingsLoad(t, test.forceSettingsLoadError)
 			ForceErrorDuringStateLoad(t, test.forceStateLoadError)
 
-			// This won't start a background goroutine to send events because the
-			// request will fail before reaching that part of the code.
-			response := Channel.GET("/events", router)
-			assert.NotEqual(t, http.StatusOK, response.Code)
+			// The stream stays open, but the client is told that the initial
+			// events couldn't be loaded.
+			_, stop := Channel.SSE("/events", router)
+			events := stop()
+			require.Equal(t, 1, len(events))
+			assert.Equal(t, "error", events[0].Kind)
 		})
 	}
 }
 
+func TestRoute_PollEvents(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	timeout := pubsub.PollTimeout
+	pubsub.PollTimeout = time.Second
+	defer func() { pubsub.PollTimeout = timeout }()
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	response := Channel.PUT("/focus/1a", "", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	// Events that have already been published are returned immediately.
+	response = Channel.GET("/poll?since=0", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "application/x-ndjson", response.Header().Get("Content-Type"))
+	assert.Equal(t, "1", response.Header().Get("X-Cursor"))
+	assert.Equal(t, `{"kind":"focus","payload":"1a"}`+"\n", response.Body.String())
+
+	// When there are no new events the poll waits for one to be published.
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		Channel.PUT("/focus/6a", "", router)
+	}()
+
+	response = Channel.GET("/poll?since=1", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "2", response.Header().Get("X-Cursor"))
+	assert.Equal(t, `{"kind":"focus","payload":"6a"}`+"\n", response.Body.String())
+
+	// If nothing is published before the timeout then no events are returned.
+	pubsub.PollTimeout = 10 * time.Millisecond
+	response = Channel.GET("/poll?since=2", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "2", response.Header().Get("X-Cursor"))
+	assert.Equal(t, "", response.Body.String())
+}
+
+func TestRoute_PollEvents_Error(t *testing.T) {
+	router, _, _ := NewTestRouter(t)
+
+	response := Channel.GET("/poll?since=abc", router)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	response = Channel.GET("/poll?since=-1", router)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
 func TestRoute_GetAvailableDates(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -919,6 +5637,41 @@ func TestRoute_GetAvailableDates(t *testing.T) {
 				time.Now().UTC().Format("2006-01-02"),
 			},
 		},
+		{
+			name:   "atlantic",
+			source: "atlantic",
+			expected: []string{
+				"2018-04-16",
+				"2020-01-01",
+				time.Now().UTC().Format("2006-01-02"),
+			},
+		},
+		{
+			name:   "jonesin",
+			source: "jonesin",
+			expected: []string{
+				"2020-01-02",
+				"2021-01-07",
+				"2024-01-04",
+			},
+		},
+		{
+			name:   "new york times cryptic",
+			source: "new_york_times_cryptic",
+			expected: []string{
+				"2024-01-07",
+			},
+		},
+		{
+			name:   "new york times mini",
+			source: "new_york_times_mini",
+			expected: []string{
+				"2014-08-21",
+				"2015-01-01",
+				"2020-01-01",
+				time.Now().UTC().Format("2006-01-02"),
+			},
+		},
 		{
 			name:   "wall street journal",
 			source: "wall_street_journal",
@@ -933,11 +5686,22 @@ func TestRoute_GetAvailableDates(t *testing.T) {
 				"2020-01-02",
 			},
 		},
+		{
+			name:   "washington post",
+			source: "washington_post",
+			expected: []string{
+				"2005-01-02",
+				"2005-12-06",
+				"2019-01-06",
+				"2020-01-05",
+			},
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			router, _, _ := NewTestRouter(t)
+			ForceAvailableCrypticDates(t, []time.Time{time.Date(2024, time.January, 7, 0, 0, 0, 0, time.UTC)})
 
 			response := GET("/crossword/dates", router)
 			assert.Equal(t, http.StatusOK, response.Code)
@@ -954,6 +5718,72 @@ func TestRoute_GetAvailableDates(t *testing.T) {
 	}
 }
 
+func TestRoute_WarmCache(t *testing.T) {
+	router, _, _ := NewTestRouter(t)
+	ForceAdminToken(t, "secret")
+
+	var mutex sync.Mutex
+	var calls int
+	ForcePuzzleLoader(t, "new_york_times", func(date string) (*Puzzle, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		calls++
+		return LoadTestPuzzle(t, "xwordinfo-nyt-20181231.json"), nil
+	})
+
+	// Only administrators can warm the cache.
+	response := POST("/crossword/cache", `{"sources": ["new_york_times"], "date": "2018-12-31"}`, router)
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+
+	response = ADMIN(http.MethodPost, "/crossword/cache", `{"sources": ["new_york_times"], "date": "2018-12-31"}`, router)
+	assert.Equal(t, http.StatusAccepted, response.Code)
+
+	// Wait for the cache to be warmed in the background.
+	require.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return calls == 1
+	}, time.Second, time.Millisecond)
+
+	// Selecting the puzzle now should be a cache hit.
+	response = Channel.PUT("/", `{"new_york_times_date": "2018-12-31"}`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, 1, calls)
+}
+
+func TestRoute_WarmCache_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{
+			name: "malformed body",
+			json: `{`,
+		},
+		{
+			name: "unknown source",
+			json: `{"sources": ["unknown"]}`,
+		},
+		{
+			name: "malformed date",
+			json: `{"date": "not a date"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, _, _ := NewTestRouter(t)
+			ForceAdminToken(t, "secret")
+
+			response := ADMIN(http.MethodPost, "/crossword/cache", test.json, router)
+			assert.Equal(t, http.StatusBadRequest, response.Code)
+		})
+	}
+}
+
 // VerifySettings performs test specific verifications on the settings objects
 // in both event and database forms.
 func VerifySettings(t *testing.T, pool *redis.Pool, events <-chan pubsub.Event, fn func(s Settings)) {
@@ -997,6 +5827,15 @@ func VerifyState(t *testing.T, pool *redis.Pool, events <-chan pubsub.Event, fn
 	fn(state)
 }
 
+// VerifyProposals performs common verifications for proposals events.
+func VerifyProposals(t *testing.T, events <-chan pubsub.Event, fn func(proposals map[string][]Proposal)) {
+	t.Helper()
+
+	found := Events(events, "proposals")
+	require.Equal(t, 1, len(found))
+	fn(found[0].Payload.(map[string][]Proposal))
+}
+
 // VerifyShowClue performs common verifications for show clue events.
 func VerifyShowClue(t *testing.T, events <-chan pubsub.Event, fn func(clue string)) {
 	t.Helper()
@@ -1035,6 +5874,22 @@ func GET(url string, router chi.Router) *httptest.ResponseRecorder {
 	return recorder
 }
 
+func POST(url, body string, router chi.Router) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// ADMIN performs a request that includes the admin token to the router.
+func ADMIN(method, url, body string, router chi.Router) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, url, strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer "+admin.Token)
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
 // ChannelClient is a client that makes requests against the URL of a particular
 // user's channel.
 type ChannelClient struct {
@@ -1046,6 +5901,11 @@ func (c ChannelClient) GET(url string, router chi.Router) *httptest.ResponseReco
 	return GET(url, router)
 }
 
+func (c ChannelClient) POST(url, body string, router chi.Router) *httptest.ResponseRecorder {
+	url = path.Join("/crossword", c.name, url)
+	return POST(url, body, router)
+}
+
 func (c ChannelClient) PUT(url, body string, router chi.Router) *httptest.ResponseRecorder {
 	url = path.Join("/crossword", c.name, url)
 	recorder := httptest.NewRecorder()
@@ -1054,6 +5914,25 @@ func (c ChannelClient) PUT(url, body string, router chi.Router) *httptest.Respon
 	return recorder
 }
 
+func (c ChannelClient) DELETE(url string, router chi.Router) *httptest.ResponseRecorder {
+	url = path.Join("/crossword", c.name, url)
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodDelete, url, nil)
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// ADMIN performs a request to an administrative endpoint of the router using
+// the configured admin token.
+func (c ChannelClient) ADMIN(method, url, body string, router chi.Router) *httptest.ResponseRecorder {
+	url = path.Join("/crossword", c.name, url)
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, url, strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer "+admin.Token)
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
 // SSE performs a streaming request to the provided router.  Because the router
 // won't immediately return, this request is done in a background goroutine.
 // When the main thread wishes to read events that have been received thus far
@@ -1092,16 +5971,28 @@ func (c ChannelClient) SSE(url string, router chi.Router) (flush func() []pubsub
 		return events
 	}
 
+	// The request is cancelled when the stream is stopped, just like when a
+	// client disconnects.
+	ctx, cancel := context.WithCancel(context.Background())
+	finished := make(chan struct{})
+
 	stop = func() []pubsub.Event {
 		// Give the router a chance to write everything it needs to.
 		time.Sleep(10 * time.Millisecond)
 
 		recorder.Close()
-		return flush()
+		events := flush()
+
+		cancel()
+		<-finished
+		return events
 	}
 
-	request := httptest.NewRequest(http.MethodGet, url, nil)
-	go router.ServeHTTP(recorder, request)
+	request := httptest.NewRequest(http.MethodGet, url, nil).WithContext(ctx)
+	go func() {
+		defer close(finished)
+		router.ServeHTTP(recorder, request)
+	}()
 
 	return flush, stop
 }
@@ -23,8 +23,87 @@ type Settings struct {
 
 	// Whether or not notes field should shown.
 	ShowNotes bool `json:"show_notes"`
+
+	// The percentage of cells that must be correct for a completely filled in
+	// puzzle to be considered complete.  At 100 every cell must be correct, at 0
+	// the puzzle is complete as soon as every cell is filled in.
+	CompleteThreshold int `json:"complete_threshold"`
+
+	// The score a proposed answer must reach from chat's votes before it's
+	// automatically applied to the puzzle.  When 0 proposals are never applied
+	// automatically.
+	AutoApplyProposalScore int `json:"auto_apply_proposal_score"`
+
+	// When enabled an answer that's rejected because only correct answers are
+	// allowed tells the user how many of its letters were correct, without
+	// revealing which ones.
+	ShowCorrectCount bool `json:"show_correct_count"`
+
+	// Additional ways of writing parts of answers that should be accepted on top
+	// of the default aliases (e.g. "1" for "ONE").
+	AnswerAliases map[string]string `json:"answer_aliases,omitempty"`
+
+	// Emote names mapped to the letters they stand for.  An answer written
+	// entirely as known emotes is translated into their letters before being
+	// applied, so that emote-heavy chats can answer with letter emotes.
+	EmoteLetters map[string]string `json:"emote_letters,omitempty"`
+
+	// When enabled every answer submitted for a clue is recorded in the channel's
+	// audit log so that moderators can review who submitted what.
+	AuditAnswers bool `json:"audit_answers"`
+
+	// A URL that's sent a POST request when the channel completes a crossword,
+	// for example to switch scenes.  When empty no request is made.  The URL is
+	// private to the channel, so it's stored separately and never sent to
+	// clients along with the rest of the settings.
+	CompletionWebhook string `json:"-"`
+
+	// When enabled a puzzle that was selected by its date is automatically
+	// followed by the puzzle its source published before it once it's
+	// completed.
+	AutoAdvanceOnComplete bool `json:"auto_advance_on_complete"`
+
+	// When enabled the raw contents of uploaded or pasted puzzles are stored
+	// alongside the parsed puzzle so that the puzzle can be reparsed later.
+	// Puzzles selected by date are never stored since they can be loaded from
+	// their source again.
+	StoreRawSource bool `json:"store_raw_source"`
+
+	// What happens to answers that are submitted while the solve is paused.
+	// They can either be rejected or queued up and applied when the solve is
+	// resumed.
+	PausedAnswerBehavior PausedAnswerBehavior `json:"paused_answer_behavior"`
+
+	// The minimum number of milliseconds that must pass between toggles of the
+	// solve's status.  A toggle that comes sooner than this after the previous
+	// one is ignored so that an accidental double click doesn't immediately undo
+	// itself.  When 0 toggles are never ignored.
+	MinToggleIntervalMillis int `json:"min_toggle_interval_ms"`
 }
 
+// Value returns the value of a single setting identified by its JSON name (e.g.
+// "clue_font_size").  The value is returned in the form it takes when the
+// settings are marshalled to JSON, so for example font sizes are returned as
+// their string names.  If the setting doesn't have a value then nil is
+// returned.
+func (s Settings) Value(name string) (interface{}, error) {
+	bs, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal(bs, &values); err != nil {
+		return nil, err
+	}
+
+	return values[name], nil
+}
+
+// DefaultCompleteThreshold is the complete threshold used by channels that
+// haven't changed it.  It requires every cell to be correct.
+const DefaultCompleteThreshold = 100
+
 // ClueVisibility is an enumeration representing which clues should be shown.
 type ClueVisibility int
 
@@ -35,6 +114,15 @@ const (
 	OnlyAcrossCluesVisible
 )
 
+// ClueVisibilities contains every supported clue visibility in the order
+// they're defined.
+var ClueVisibilities = []ClueVisibility{
+	AllCluesVisible,
+	NoCluesVisible,
+	OnlyDownCluesVisible,
+	OnlyAcrossCluesVisible,
+}
+
 func (v ClueVisibility) String() string {
 	switch v {
 	case AllCluesVisible:
@@ -92,6 +180,60 @@ func (v *ClueVisibility) UnmarshalJSON(bs []byte) error {
 	return nil
 }
 
+// PausedAnswerBehavior is an enumeration representing what happens to answers
+// that are submitted while the solve is paused.
+type PausedAnswerBehavior int
+
+const (
+	RejectPausedAnswers PausedAnswerBehavior = iota
+	QueuePausedAnswers
+)
+
+// PausedAnswerBehaviors contains every supported paused answer behavior in the
+// order they're defined.
+var PausedAnswerBehaviors = []PausedAnswerBehavior{
+	RejectPausedAnswers,
+	QueuePausedAnswers,
+}
+
+func (b PausedAnswerBehavior) String() string {
+	switch b {
+	case RejectPausedAnswers:
+		return "reject"
+	case QueuePausedAnswers:
+		return "queue"
+	default:
+		return "unknown"
+	}
+}
+
+func (b PausedAnswerBehavior) MarshalJSON() ([]byte, error) {
+	switch b {
+	case RejectPausedAnswers, QueuePausedAnswers:
+		return json.Marshal(b.String())
+	default:
+		return nil, fmt.Errorf("unable to marshal invalid paused answer behavior: %v", b)
+	}
+}
+
+func (b *PausedAnswerBehavior) UnmarshalJSON(bs []byte) error {
+	var str string
+	if err := json.Unmarshal(bs, &str); err != nil {
+		return err
+	}
+
+	switch str {
+	case "reject":
+		*b = RejectPausedAnswers
+	case "queue":
+		*b = QueuePausedAnswers
+	default:
+		return fmt.Errorf("unable to unmarshal invalid paused answer behavior: %s", str)
+	}
+
+	return nil
+}
+
 // SettingsKey returns the key that should be used in redis to store a
 // particular channel's settings.
 func SettingsKey(name string) string {
@@ -101,13 +243,20 @@ func SettingsKey(name string) string {
 // GetSettings will load settings for the provided channel name.  If the
 // settings can't be properly loaded then an error will be returned.
 func GetSettings(conn redis.Conn, channel string) (Settings, error) {
-	var settings Settings
+	settings := Settings{
+		CompleteThreshold: DefaultCompleteThreshold,
+	}
 
 	if testSettingsLoadError != nil {
 		return settings, testSettingsLoadError
 	}
 
-	err := db.Get(conn, SettingsKey(channel), &settings)
+	if err := db.Get(conn, SettingsKey(channel), &settings); err != nil {
+		return settings, err
+	}
+
+	webhook, err := GetCompletionWebhook(conn, channel)
+	settings.CompletionWebhook = webhook
 	return settings, err
 }
 
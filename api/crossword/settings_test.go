@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/bbeck/puzzles-with-chat/api/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -172,3 +173,68 @@ func TestClueVisibility_UnmarshalJSON_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestPausedAnswerBehavior_JSON(t *testing.T) {
+	tests := []struct {
+		behavior PausedAnswerBehavior
+		expected string
+	}{
+		{behavior: RejectPausedAnswers, expected: "reject"},
+		{behavior: QueuePausedAnswers, expected: "queue"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.behavior.String())
+
+			bs, err := json.Marshal(test.behavior)
+			require.NoError(t, err)
+			assert.Equal(t, `"`+test.expected+`"`, string(bs))
+
+			var actual PausedAnswerBehavior
+			require.NoError(t, json.Unmarshal(bs, &actual))
+			assert.Equal(t, test.behavior, actual)
+		})
+	}
+}
+
+func TestPausedAnswerBehavior_JSON_Error(t *testing.T) {
+	assert.Equal(t, "unknown", PausedAnswerBehavior(17).String())
+
+	_, err := json.Marshal(PausedAnswerBehavior(17))
+	assert.Error(t, err)
+
+	var actual PausedAnswerBehavior
+	assert.Error(t, json.Unmarshal([]byte(`false`), &actual))
+	assert.Error(t, json.Unmarshal([]byte(`""`), &actual))
+	assert.Error(t, json.Unmarshal([]byte(`"hold"`), &actual))
+}
+
+func TestSettings_Value(t *testing.T) {
+	settings := Settings{
+		OnlyAllowCorrectAnswers: true,
+		CluesToShow:             OnlyDownCluesVisible,
+		ClueFontSize:            model.FontSizeXLarge,
+		CompleteThreshold:       80,
+	}
+
+	tests := []struct {
+		name     string
+		expected interface{}
+	}{
+		{name: "only_allow_correct_answers", expected: true},
+		{name: "clues_to_show", expected: "down"},
+		{name: "clue_font_size", expected: "xlarge"},
+		{name: "complete_threshold", expected: float64(80)},
+		{name: "answer_aliases", expected: nil},
+		{name: "unknown", expected: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := settings.Value(test.name)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, value)
+		})
+	}
+}
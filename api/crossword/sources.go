@@ -0,0 +1,161 @@
+package crossword
+
+import (
+	"fmt"
+	"time"
+)
+
+// SourceInput describes the kind of value that's used to select a puzzle from a
+// source.
+type SourceInput string
+
+const (
+	// The puzzle is selected by the date that it was published on.
+	SourceInputDate SourceInput = "date"
+
+	// The puzzle is selected by an identifier.
+	SourceInputID SourceInput = "id"
+
+	// The puzzle is selected by the URL of a file to download.
+	SourceInputURL SourceInput = "url"
+
+	// The puzzle is selected by uploading the contents of a file.
+	SourceInputFile SourceInput = "file"
+
+	// The puzzle is selected by pasting its contents as text.
+	SourceInputText SourceInput = "text"
+)
+
+// Source describes a place that puzzles can be loaded from when a channel
+// selects a puzzle.  It can be marshalled to JSON so that clients can discover
+// which sources are available.
+type Source struct {
+	// The name of the source.  For sources that publish on a schedule this is
+	// the same name used by PuzzleLoaders and the dates endpoint.
+	Name string `json:"name"`
+
+	// A short human readable name for the source, used in messages.
+	Label string `json:"label"`
+
+	// The key in the puzzle selection payload whose value selects a puzzle
+	// from this source.
+	Key string `json:"key"`
+
+	// The kind of value that the key expects.
+	Input SourceInput `json:"input"`
+
+	// Load loads the puzzle that a value selects.
+	Load func(value string) (*Puzzle, error) `json:"-"`
+}
+
+// Sources contains every source that a puzzle can be selected from.  When a
+// selection includes values for more than one source they're loaded in this
+// order and the last one wins.
+var Sources = []Source{
+	DatedSource("new_york_times", "NYT"),
+	DatedSource("new_york_times_mini", "NYT Mini"),
+	DatedSource("new_york_times_cryptic", "NYT Cryptic"),
+	DatedSource("wall_street_journal", "WSJ"),
+	DatedSource("washington_post", "WP"),
+	DatedSource("atlantic", "Atlantic"),
+	DatedSource("jonesin", "Jonesin'"),
+	{
+		Name:  "archive",
+		Label: "archive",
+		Key:   "archive_id",
+		Input: SourceInputID,
+		Load:  LoadFromArchive,
+	},
+	{
+		Name:  "puz_file_url",
+		Label: ".puz file",
+		Key:   "puz_file_url",
+		Input: SourceInputURL,
+		Load:  LoadFromPuzFileURL,
+	},
+	{
+		Name:  "puz_file_bytes",
+		Label: ".puz file",
+		Key:   "puz_file_bytes",
+		Input: SourceInputFile,
+		Load:  LoadFromEncodedPuzFile,
+	},
+	{
+		Name:  "ipuz_file_bytes",
+		Label: ".ipuz file",
+		Key:   "ipuz_file_bytes",
+		Input: SourceInputFile,
+		Load:  LoadFromEncodedIPuzFile,
+	},
+	{
+		Name:  "text_puzzle",
+		Label: "Across Lite text",
+		Key:   "text_puzzle",
+		Input: SourceInputText,
+		Load:  LoadFromAcrossLiteText,
+	},
+}
+
+// DatedSource creates a source for one of the sources in PuzzleLoaders.  Its
+// puzzles are selected by date and loaded through the puzzle cache, and dates
+// the source didn't publish a puzzle on are rejected with ErrNoPuzzleOnDate
+// before any attempt is made to download them.
+func DatedSource(name, label string) Source {
+	return Source{
+		Name:  name,
+		Label: label,
+		Key:   name + "_date",
+		Input: SourceInputDate,
+		Load: func(date string) (*Puzzle, error) {
+			if err := CheckPuzzleDate(name, date); err != nil {
+				return nil, err
+			}
+
+			return LoadCachedPuzzle(name, date)
+		},
+	}
+}
+
+// EnabledSources returns the sources that puzzles can currently be selected
+// from.  Sources selected by date are only enabled while they have a loader in
+// PuzzleLoaders.
+func EnabledSources() []Source {
+	var sources []Source
+	for _, source := range Sources {
+		if source.Input == SourceInputDate {
+			if _, ok := PuzzleLoaders[source.Name]; !ok {
+				continue
+			}
+		}
+
+		sources = append(sources, source)
+	}
+
+	return sources
+}
+
+// SourceURL returns the URL of a puzzle that was selected from one of the
+// sources in PuzzleLoaders by the date it was published on.  When the publisher
+// has a page for each of its puzzles that page is returned, otherwise the URL
+// that the puzzle is downloaded from is returned.  If there's no URL for the
+// source then the empty string is returned.
+func SourceURL(source string, published time.Time) string {
+	switch source {
+	case "new_york_times":
+		return fmt.Sprintf("https://www.nytimes.com/crosswords/game/daily/%s", published.Format("2006/01/02"))
+	case "new_york_times_mini":
+		return fmt.Sprintf("https://www.nytimes.com/crosswords/game/mini/%s", published.Format("2006/01/02"))
+	case "wall_street_journal":
+		return WallStreetJournalURL(published)
+	case "washington_post":
+		return WashingtonPostURL(published)
+	case "jonesin":
+		return JonesinURL(published)
+	case "atlantic":
+		return AtlanticURL(published)
+	case "new_york_times_cryptic":
+		return NYTCrypticURL(published.Format("2006-01-02"))
+	default:
+		return ""
+	}
+}
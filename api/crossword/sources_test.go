@@ -0,0 +1,90 @@
+package crossword
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSources_MatchPuzzleLoaders(t *testing.T) {
+	// Every source that publishes on a schedule should be selectable by date.
+	var dated []string
+	for _, source := range Sources {
+		if source.Input == SourceInputDate {
+			dated = append(dated, source.Name)
+		}
+	}
+
+	var loaders []string
+	for name := range PuzzleLoaders {
+		loaders = append(loaders, name)
+	}
+
+	assert.ElementsMatch(t, loaders, dated)
+}
+
+func TestSourceURL(t *testing.T) {
+	// Every source that can be selected by date knows where its puzzles are.
+	published := time.Date(2020, time.May, 24, 0, 0, 0, 0, time.UTC)
+	for name := range PuzzleLoaders {
+		assert.NotEmpty(t, SourceURL(name, published), "no url for source %s", name)
+	}
+
+	assert.Equal(t, "https://cdn3.amuselabs.com/atlantic/crossword?id=atlantic_20200524&set=atlantic&format=json", SourceURL("atlantic", published))
+	assert.Equal(t, "https://www.xwordinfo.com/JSON/CrypticData.ashx?date=2020-05-24", SourceURL("new_york_times_cryptic", published))
+	assert.Empty(t, SourceURL("puz_file_url", published))
+}
+
+func TestSources_Keys(t *testing.T) {
+	keys := make(map[string]bool)
+	for _, source := range Sources {
+		assert.NotEmpty(t, source.Name)
+		assert.NotEmpty(t, source.Label)
+		assert.NotNil(t, source.Load)
+		assert.False(t, keys[source.Key], "duplicate key %s", source.Key)
+		keys[source.Key] = true
+	}
+}
+
+func TestEnabledSources(t *testing.T) {
+	names := func(sources []Source) []string {
+		var names []string
+		for _, source := range sources {
+			names = append(names, source.Name)
+		}
+		return names
+	}
+
+	assert.Equal(t, names(Sources), names(EnabledSources()))
+
+	// A source that publishes on a schedule is disabled when its loader is
+	// removed.
+	original := PuzzleLoaders["jonesin"]
+	delete(PuzzleLoaders, "jonesin")
+	defer func() { PuzzleLoaders["jonesin"] = original }()
+
+	enabled := names(EnabledSources())
+	assert.NotContains(t, enabled, "jonesin")
+	assert.Contains(t, enabled, "new_york_times")
+	assert.Contains(t, enabled, "archive")
+	assert.Equal(t, len(Sources)-1, len(enabled))
+}
+
+func TestDatedSource(t *testing.T) {
+	ForcePuzzleToBeLoaded(t, "xwordinfo-nyt-20181231.json")
+
+	source := DatedSource("new_york_times", "NYT")
+	assert.Equal(t, "new_york_times_date", source.Key)
+	assert.Equal(t, SourceInputDate, source.Input)
+
+	puzzle, err := source.Load("2018-12-31")
+	require.NoError(t, err)
+	assert.Equal(t, "The New York Times", puzzle.Publisher)
+
+	// Dates the source didn't publish on are rejected.
+	_, err = source.Load("1945-06-05")
+	assert.True(t, errors.Is(err, ErrNoPuzzleOnDate))
+}
@@ -1,6 +1,7 @@
 package crossword
 
 import (
+	"errors"
 	"fmt"
 	"github.com/bbeck/puzzles-with-chat/api/db"
 	"github.com/bbeck/puzzles-with-chat/api/model"
@@ -23,6 +24,11 @@ type State struct {
 	// The currently filled in cells of the crossword.
 	Cells [][]string `json:"cells"`
 
+	// The tentative (pencil) values of cells of the crossword.  These are kept
+	// separate from the committed cells and don't count towards completing the
+	// puzzle until they're promoted.
+	PencilCells [][]string `json:"pencil_cells,omitempty"`
+
 	// Whether or not an across clue with a given clue number has had an answer
 	// filled in.
 	AcrossCluesFilled map[int]bool `json:"across_clues_filled"`
@@ -38,13 +44,167 @@ type State struct {
 
 	// The total time spent on solving the puzzle up to the last start time.
 	TotalSolveDuration model.Duration `json:"total_solve_duration"`
+
+	// The time that the state was last saved.  This is used to avoid counting
+	// time that the server was down as solve time.
+	LastSaveTime *time.Time `json:"last_save_time,omitempty"`
+
+	// The time that the status of the solve was last toggled between solving
+	// and paused.  This is used to ignore toggles that come too quickly after
+	// one another, such as from an accidental double click.
+	LastToggleTime *time.Time `json:"last_toggle_time,omitempty"`
+
+	// The total time, in seconds, spent solving the puzzle as of when the state
+	// was sent to clients.  This allows clients to display a consistent time
+	// without having to compare the last start time against their own clock.
+	// It's only populated in state events.
+	Elapsed float64 `json:"elapsed,omitempty"`
+
+	// The name of the user that was first to correctly answer each clue indexed
+	// by the clue (e.g. "1a").  Clues that haven't been correctly answered by a
+	// known user won't have an entry.
+	ClueSolvers map[string]string `json:"clue_solvers,omitempty"`
+
+	// The number of bits that were cheered in the message that first correctly
+	// answered each clue indexed by the clue (e.g. "1a").  Clues that weren't
+	// answered in a message with a cheer won't have an entry.
+	ClueCheers map[string]int `json:"clue_cheers,omitempty"`
+
+	// The answers that chat has proposed, but not yet applied, indexed by the
+	// clue (e.g. "1a").  Proposals for a clue are discarded once it's answered.
+	// These are stored separately from the state and only populated when
+	// they're needed, they're never persisted or sent with the state.
+	Proposals map[string][]Proposal `json:"-"`
+
+	// Which cells have been locked by a moderator.  Like givens, the value of a
+	// locked cell can't be changed by answers.
+	LockedCells [][]bool `json:"locked_cells,omitempty"`
+
+	// The number of times that the solution to a cell was revealed during the
+	// solve.  This measures how much help the channel needed.
+	Reveals int `json:"reveals,omitempty"`
+
+	// The solve time at which each clue most recently went from having an answer
+	// filled in to not having one, indexed by the clue (e.g. "1a").  Clues that
+	// have never been filled in have been open since the start of the solve and
+	// won't have an entry.
+	ClueOpenedAt map[string]model.Duration `json:"clue_opened_at,omitempty"`
+
+	// The clue (e.g. "1a") that the channel is currently focused on.  Clients
+	// highlight this clue so that everyone is looking at the same place.
+	FocusedClue string `json:"focused_clue,omitempty"`
+
+	// The answers that were submitted while the solve was paused, in the order
+	// they were submitted.  They're applied when the solve is resumed.
+	QueuedAnswers []QueuedAnswer `json:"queued_answers,omitempty"`
+
+	// Whether or not the solve is for practice.  Practice solves work normally,
+	// but their time isn't tracked, users aren't credited with solving clues and
+	// they aren't included in the channel's stats or solve streak.
+	Practice bool `json:"practice,omitempty"`
+
+	// The name of the source that publishes the puzzle on a schedule (e.g.
+	// "new_york_times") when the puzzle was selected by its date.  This allows
+	// the puzzle published before it to be selected once it's completed.
+	Source string `json:"source,omitempty"`
+
+	// When the puzzle published before this one by the same source will be
+	// selected, nil if the channel isn't going to advance to it.  This is only
+	// set once the puzzle is completed.
+	AdvanceTime *time.Time `json:"advance_time,omitempty"`
+
+	// Additional answer aliases configured in the channel's settings.  These are
+	// populated before answers are applied and are never persisted.
+	AnswerAliases map[string]string `json:"-"`
+}
+
+// resetEphemeralState clears everything about the state that belongs to a
+// single solve and initializes it for solving the provided puzzle.  Anything
+// that should not survive the selection of a new puzzle must be reset here so
+// that it isn't accidentally carried over into the next solve.
+func (s *State) resetEphemeralState(puzzle *Puzzle) {
+	cells := make([][]string, puzzle.Rows)
+	for row := 0; row < puzzle.Rows; row++ {
+		cells[row] = make([]string, puzzle.Cols)
+	}
+
+	s.Status = model.StatusSelected
+	s.Puzzle = puzzle
+	s.Cells = cells
+	s.PencilCells = nil
+	s.LockedCells = nil
+	s.AcrossCluesFilled = make(map[int]bool)
+	s.DownCluesFilled = make(map[int]bool)
+	s.LastStartTime = nil
+	s.TotalSolveDuration = model.Duration{}
+	s.LastToggleTime = nil
+	s.ClueSolvers = make(map[string]string)
+	s.ClueCheers = nil
+	s.Proposals = make(map[string][]Proposal)
+	s.Reveals = 0
+	s.ClueOpenedAt = nil
+	s.FocusedClue = ""
+	s.QueuedAnswers = nil
+	s.AdvanceTime = nil
+
+	// Givens are provided as part of the puzzle so they start out filled in.
+	// Cells that aren't part of any clue's answer can't ever be filled in by an
+	// answer, so they're treated the same way.
+	counts := puzzle.cellClueCounts()
+	for row := 0; row < puzzle.Rows; row++ {
+		for col := 0; col < puzzle.Cols; col++ {
+			if puzzle.IsCellGiven(col, row) || (!puzzle.CellBlocks[row][col] && counts[row][col] == 0) {
+				cells[row][col] = puzzle.Cells[row][col]
+			}
+		}
+	}
+
+	// A puzzle that already has all of its answers filled in is offered for
+	// review instead of being solved.  Show the answers as filled in.
+	if puzzle.Prefilled {
+		for row := 0; row < puzzle.Rows; row++ {
+			copy(cells[row], puzzle.Cells[row])
+		}
+
+		s.Status = model.StatusReview
+		_ = s.UpdateFilledClues()
+	}
+}
+
+// AnswerLengthError is returned when an answer doesn't have the same number of
+// cells as the clue that it was provided for spans in the grid.  Its message is
+// suitable for showing to the user that provided the answer.
+type AnswerLengthError struct {
+	Expected int
+	Actual   int
+}
+
+func (e *AnswerLengthError) Error() string {
+	return fmt.Sprintf("expected %d letters, got %d", e.Expected, e.Actual)
+}
+
+// IncorrectAnswerError is returned when only correct answers are allowed and an
+// answer is incorrect or would remove a correct cell.  It records how many of
+// the answer's cells match the clue's solution so that the user can be nudged
+// without revealing which of them are correct.
+type IncorrectAnswerError struct {
+	Clue    string
+	Answer  string
+	Reason  string
+	Correct int
+	Total   int
+}
+
+func (e *IncorrectAnswerError) Error() string {
+	return fmt.Sprintf("unable to apply answer %s to %s, %s", e.Answer, e.Clue, e.Reason)
 }
 
 // ApplyAnswer applies an answer for a clue to the state.  If the clue cannot
-// be identified or the answer doesn't fit property (too short or too long) then
-// an error will be returned.  If the onlyCorrect parameter is true then only
-// correct cells will be permitted and an error is returned if any part of the
-// answer is incorrect or would remove a correct cell.
+// be identified then an error will be returned, if the answer doesn't fit
+// properly (too short or too long) then the error is an AnswerLengthError.  If
+// the onlyCorrect parameter is true then only correct cells will be permitted
+// and an IncorrectAnswerError is returned if any part of the answer is
+// incorrect or would remove a correct cell.
 func (s *State) ApplyAnswer(clue string, answer string, onlyCorrect bool) error {
 	num, direction, err := ParseClue(clue)
 	if err != nil {
@@ -61,9 +221,28 @@ func (s *State) ApplyAnswer(clue string, answer string, onlyCorrect bool) error
 		return err
 	}
 
+	// An alias can stand for more than one cell (e.g. "&" for "AND"), so when
+	// the answer doesn't fit as written its aliases are expanded across the whole
+	// answer before it's split into cells.  Wildcards are expanded first so that
+	// their counts aren't mistaken for aliases.
+	aliases := model.AnswerAliases(s.AnswerAliases)
+	if len(cells) != (maxX-minX)+(maxY-minY)+1 {
+		if expanded, err := model.ExpandWildcards(answer); err == nil {
+			if alternate, err := ParseAnswer(model.ExpandAnswerAliases(expanded, aliases)); err == nil {
+				cells = alternate
+			}
+		}
+	}
+
 	// Check to see if our cell values are compatible with the size of the answer.
 	if len(cells) != (maxX-minX)+(maxY-minY)+1 {
-		return fmt.Errorf("unable to apply answer %s to %s, incompatible sizes", answer, clue)
+		return &AnswerLengthError{Expected: (maxX - minX) + (maxY - minY) + 1, Actual: len(cells)}
+	}
+
+	// Answers that read from right to left start in their rightmost cell, so
+	// flip them to line up with the grid's columns.
+	if s.Puzzle.readsRightToLeft(direction) {
+		reverse(cells)
 	}
 
 	// Determine the way to iterate through the grid.
@@ -74,28 +253,73 @@ func (s *State) ApplyAnswer(clue string, answer string, onlyCorrect bool) error
 		dy = 1
 	}
 
+	// When the puzzle was imported without its solution there's nothing to
+	// check the answer against, so it's written as given.
+	hasSolution := s.Puzzle.HasSolution()
+
+	// Values that are equivalent to the solution once aliases are taken into
+	// account (e.g. "1" for "ONE") are written as the solution's value so that
+	// the cell is considered correct.
+	for x, y := minX, minY; x <= maxX && y <= maxY && hasSolution; x, y = x+dx, y+dy {
+		index := y - minY + x - minX
+		if cells[index] != "" && model.EquivalentAnswers(cells[index], s.Puzzle.Cells[y][x], aliases) {
+			cells[index] = s.Puzzle.Cells[y][x]
+		}
+	}
+
 	// Check to see if the answer is correct when required.
-	if onlyCorrect {
-		for x, y := minX, minY; x <= maxX && y <= maxY; x, y = x+dx, y+dy {
+	if onlyCorrect && hasSolution {
+		var reason string
+		for x, y := minX, minY; x <= maxX && y <= maxY && reason == ""; x, y = x+dx, y+dy {
+			// Givens and locked cells can't be changed so they're never checked.
+			if s.Puzzle.IsCellGiven(x, y) || s.IsCellLocked(x, y) {
+				continue
+			}
+
 			existing := s.Cells[y][x]
 			expected := s.Puzzle.Cells[y][x]
 			desired := cells[y-minY+x-minX]
 
-			// We can't change a correct value to an incorrect or empty one.
 			if existing != "" && desired != existing {
-				return fmt.Errorf("unable to apply answer %s to %s, changes correct value", answer, clue)
+				// We can't change a correct value to an incorrect or empty one.
+				reason = "changes correct value"
+			} else if desired != "" && desired != expected {
+				// We can't write an incorrect value into a cell.
+				reason = "incorrect"
 			}
+		}
 
-			// We can't write an incorrect value into a cell.
-			if desired != "" && desired != expected {
-				return fmt.Errorf("unable to apply answer %s to %s, incorrect", answer, clue)
+		if reason != "" {
+			var correct int
+			for x, y := minX, minY; x <= maxX && y <= maxY; x, y = x+dx, y+dy {
+				if cells[y-minY+x-minX] == s.Puzzle.Cells[y][x] {
+					correct++
+				}
+			}
+
+			return &IncorrectAnswerError{
+				Clue:    clue,
+				Answer:  answer,
+				Reason:  reason,
+				Correct: correct,
+				Total:   len(cells),
 			}
 		}
 	}
 
-	// Write the cells of our answer.
+	// Write the cells of our answer.  Givens and cells locked by a moderator
+	// keep their value regardless of what the answer contains.
 	for x, y := minX, minY; x <= maxX && y <= maxY; x, y = x+dx, y+dy {
+		if s.Puzzle.IsCellGiven(x, y) || s.IsCellLocked(x, y) {
+			continue
+		}
+
 		s.Cells[y][x] = cells[y-minY+x-minX]
+
+		// A cell written in pen replaces whatever was penciled into it.
+		if s.Cells[y][x] != "" && s.PencilCells != nil {
+			s.PencilCells[y][x] = ""
+		}
 	}
 
 	// Now that we've filled in an answer we may have completed one or more clues.
@@ -108,8 +332,8 @@ func (s *State) ApplyAnswer(clue string, answer string, onlyCorrect bool) error
 
 	// Also determine if the puzzle is finished with all correct answers and
 	// update the Status if so.
-	complete := true
-	for y := 0; y < s.Puzzle.Rows; y++ {
+	complete := hasSolution
+	for y := 0; y < s.Puzzle.Rows && hasSolution; y++ {
 		for x := 0; x < s.Puzzle.Cols; x++ {
 			if s.Cells[y][x] != s.Puzzle.Cells[y][x] {
 				complete = false
@@ -126,13 +350,459 @@ func (s *State) ApplyAnswer(clue string, answer string, onlyCorrect bool) error
 	return nil
 }
 
+// ApplyPencilAnswer applies a tentative answer for a clue to the pencil layer of
+// the state.  The committed cells aren't changed, so a pencil answer is never
+// checked for correctness and doesn't complete the puzzle.  If the clue cannot
+// be identified or the answer doesn't fit properly then an error will be
+// returned.
+func (s *State) ApplyPencilAnswer(clue string, answer string) error {
+	num, direction, err := ParseClue(clue)
+	if err != nil {
+		return err
+	}
+
+	cells, err := ParseAnswer(answer)
+	if err != nil {
+		return err
+	}
+
+	minX, minY, maxX, maxY, err := s.Puzzle.GetAnswerCoordinates(num, direction)
+	if err != nil {
+		return err
+	}
+
+	if len(cells) != (maxX-minX)+(maxY-minY)+1 {
+		return &AnswerLengthError{Expected: (maxX - minX) + (maxY - minY) + 1, Actual: len(cells)}
+	}
+
+	if s.Puzzle.readsRightToLeft(direction) {
+		reverse(cells)
+	}
+
+	if s.PencilCells == nil {
+		s.PencilCells = make([][]string, s.Puzzle.Rows)
+		for row := 0; row < s.Puzzle.Rows; row++ {
+			s.PencilCells[row] = make([]string, s.Puzzle.Cols)
+		}
+	}
+
+	for x := minX; x <= maxX; x++ {
+		for y := minY; y <= maxY; y++ {
+			// Givens and locked cells are already known so there's no reason to
+			// pencil them in.
+			if s.Puzzle.IsCellGiven(x, y) || s.IsCellLocked(x, y) {
+				continue
+			}
+
+			s.PencilCells[y][x] = cells[y-minY+x-minX]
+		}
+	}
+
+	return nil
+}
+
+// PencilAnswer returns an answer for a clue that commits the clue's penciled in
+// cells.  Cells of the clue without a pencil value keep their committed value.
+// The answer is in the same format accepted by ApplyAnswer.  If the clue cannot
+// be identified or none of its cells are penciled in then an error is returned.
+func (s *State) PencilAnswer(clue string) (string, error) {
+	num, direction, err := ParseClue(clue)
+	if err != nil {
+		return "", err
+	}
+
+	minX, minY, maxX, maxY, err := s.Puzzle.GetAnswerCoordinates(num, direction)
+	if err != nil {
+		return "", err
+	}
+
+	var answer []string
+	var penciled bool
+	for x := minX; x <= maxX; x++ {
+		for y := minY; y <= maxY; y++ {
+			value := s.Cells[y][x]
+			if s.PencilCells != nil && s.PencilCells[y][x] != "" {
+				value = s.PencilCells[y][x]
+				penciled = true
+			}
+
+			switch {
+			case value == "":
+				answer = append(answer, ".")
+			case len(value) > 1:
+				answer = append(answer, "("+value+")")
+			default:
+				answer = append(answer, value)
+			}
+		}
+	}
+
+	if !penciled {
+		return "", fmt.Errorf("clue %s has no penciled in cells", clue)
+	}
+
+	if s.Puzzle.readsRightToLeft(direction) {
+		reverse(answer)
+	}
+
+	return strings.Join(answer, ""), nil
+}
+
+// FocusClue makes the provided clue the one that the channel is focused on.  If
+// the clue can't be parsed or doesn't exist in the puzzle then an error is
+// returned and the focus is left unchanged.
+func (s *State) FocusClue(clue string) error {
+	num, dir, err := ParseClue(clue)
+	if err != nil {
+		return err
+	}
+
+	if s.Puzzle == nil {
+		return fmt.Errorf("no puzzle selected")
+	}
+
+	clues := s.Puzzle.CluesAcross
+	if dir == "d" {
+		clues = s.Puzzle.CluesDown
+	}
+	if _, ok := clues[num]; !ok {
+		return fmt.Errorf("no clue %d%s in puzzle", num, dir)
+	}
+
+	s.FocusedClue = fmt.Sprintf("%d%s", num, dir)
+	return nil
+}
+
+// ErrNoUnfilledClues is returned when looking for an unfilled clue to move to
+// in a crossword whose other clues are all filled in.
+var ErrNoUnfilledClues = errors.New("no unfilled clues")
+
+// AdjacentUnfilledClue returns the clue that comes after (or before when
+// forward is false) the focused clue and doesn't yet have an answer filled in.
+// Clues are ordered with all of the across clues by number followed by all of
+// the down clues by number, so moving past the last across clue continues with
+// the first down clue and moving past the last down clue wraps around to the
+// first across clue.  If no clue is focused then the search starts from the
+// beginning (or end) of the clues.
+func (s *State) AdjacentUnfilledClue(forward bool) (string, error) {
+	if s.Puzzle == nil {
+		return "", fmt.Errorf("no puzzle selected")
+	}
+
+	type clue struct {
+		num    int
+		dir    string
+		filled bool
+	}
+
+	var clues []clue
+	for _, dir := range []string{"a", "d"} {
+		numbers := s.Puzzle.CluesAcross
+		filled := s.AcrossCluesFilled
+		if dir == "d" {
+			numbers = s.Puzzle.CluesDown
+			filled = s.DownCluesFilled
+		}
+
+		var nums []int
+		for num := range numbers {
+			nums = append(nums, num)
+		}
+		sort.Ints(nums)
+
+		for _, num := range nums {
+			clues = append(clues, clue{num: num, dir: dir, filled: filled[num]})
+		}
+	}
+
+	if len(clues) == 0 {
+		return "", ErrNoUnfilledClues
+	}
+
+	// Find where the focused clue is in the ordering.  When nothing is focused
+	// start just outside of the clues so that the first step lands on an end.
+	current := -1
+	if !forward {
+		current = len(clues)
+	}
+	if num, dir, err := ParseClue(s.FocusedClue); err == nil {
+		for i, c := range clues {
+			if c.num == num && c.dir == dir {
+				current = i
+				break
+			}
+		}
+	}
+
+	step := 1
+	if !forward {
+		step = -1
+	}
+
+	for i := 1; i <= len(clues); i++ {
+		c := clues[((current+i*step)%len(clues)+len(clues))%len(clues)]
+		if !c.filled {
+			return fmt.Sprintf("%d%s", c.num, c.dir), nil
+		}
+	}
+
+	return "", ErrNoUnfilledClues
+}
+
+// Cell identifies a single cell of the crossword's grid.
+type Cell struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+// ClueCells returns the cells that the answer to a clue occupies.  If the clue
+// can't be identified then an error is returned.
+func (s *State) ClueCells(clue string) ([]Cell, error) {
+	num, direction, err := ParseClue(clue)
+	if err != nil {
+		return nil, err
+	}
+
+	minX, minY, maxX, maxY, err := s.Puzzle.GetAnswerCoordinates(num, direction)
+	if err != nil {
+		return nil, err
+	}
+
+	var cells []Cell
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			cells = append(cells, Cell{Row: y, Col: x})
+		}
+	}
+
+	return cells, nil
+}
+
+// IsCellLocked returns whether or not a moderator has locked a cell so that
+// its value can't be changed.
+func (s *State) IsCellLocked(x, y int) bool {
+	return s.LockedCells != nil && s.LockedCells[y][x]
+}
+
+// LockCells locks or unlocks a set of cells.  If any of the cells is outside
+// of the grid or is a block then an error is returned and no cells are
+// changed.
+func (s *State) LockCells(cells []Cell, locked bool) error {
+	if s.Puzzle == nil {
+		return fmt.Errorf("no puzzle selected")
+	}
+
+	for _, cell := range cells {
+		if cell.Row < 0 || cell.Row >= s.Puzzle.Rows || cell.Col < 0 || cell.Col >= s.Puzzle.Cols {
+			return fmt.Errorf("cell (%d, %d) is outside of the grid", cell.Row, cell.Col)
+		}
+
+		if s.Puzzle.CellBlocks[cell.Row][cell.Col] {
+			return fmt.Errorf("cell (%d, %d) is a block", cell.Row, cell.Col)
+		}
+	}
+
+	if s.LockedCells == nil {
+		s.LockedCells = make([][]bool, s.Puzzle.Rows)
+		for row := 0; row < s.Puzzle.Rows; row++ {
+			s.LockedCells[row] = make([]bool, s.Puzzle.Cols)
+		}
+	}
+
+	for _, cell := range cells {
+		s.LockedCells[cell.Row][cell.Col] = locked
+	}
+
+	return nil
+}
+
+// Validate checks that the state is consistent with its puzzle so that it can
+// be safely used for a solve.  States that aren't created by this server, for
+// example ones imported from a snapshot, should be validated before they're
+// used.
+func (s *State) Validate() error {
+	if s.Puzzle == nil {
+		return fmt.Errorf("missing puzzle")
+	}
+
+	if err := s.Puzzle.Validate(); err != nil {
+		return fmt.Errorf("invalid puzzle: %w", err)
+	}
+
+	grids := map[string][][]string{"cells": s.Cells}
+	if s.PencilCells != nil {
+		grids["pencil cells"] = s.PencilCells
+	}
+	for name, grid := range grids {
+		if len(grid) != s.Puzzle.Rows {
+			return fmt.Errorf("%s has %d rows, expected %d", name, len(grid), s.Puzzle.Rows)
+		}
+		for row := range grid {
+			if len(grid[row]) != s.Puzzle.Cols {
+				return fmt.Errorf("row %d of %s has %d columns, expected %d", row, name, len(grid[row]), s.Puzzle.Cols)
+			}
+		}
+	}
+
+	return nil
+}
+
+// IsComplete returns whether or not every cell of the puzzle has been filled in
+// and at least threshold percent of the cells are filled in correctly.  A
+// puzzle without a solution is never complete.
+func (s *State) IsComplete(threshold int) bool {
+	if !s.Puzzle.HasSolution() {
+		return false
+	}
+
+	var correct, total int
+	for y := 0; y < s.Puzzle.Rows; y++ {
+		for x := 0; x < s.Puzzle.Cols; x++ {
+			if s.Puzzle.CellBlocks[y][x] {
+				continue
+			}
+
+			if s.Cells[y][x] == "" {
+				return false
+			}
+
+			total++
+			if s.Cells[y][x] == s.Puzzle.Cells[y][x] {
+				correct++
+			}
+		}
+	}
+
+	return 100*correct >= threshold*total
+}
+
+// IsClueCorrect returns whether or not the clue currently has the correct answer
+// filled in.  If the clue cannot be identified or the puzzle doesn't have a
+// solution then false is returned.
+func (s *State) IsClueCorrect(clue string) bool {
+	if !s.Puzzle.HasSolution() {
+		return false
+	}
+
+	num, direction, err := ParseClue(clue)
+	if err != nil {
+		return false
+	}
+
+	minX, minY, maxX, maxY, err := s.Puzzle.GetAnswerCoordinates(num, direction)
+	if err != nil {
+		return false
+	}
+
+	for x := minX; x <= maxX; x++ {
+		for y := minY; y <= maxY; y++ {
+			if s.Cells[y][x] != s.Puzzle.Cells[y][x] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// CreditSolver records the user as the solver of a clue.  Only the first user
+// to be credited with solving a clue keeps the credit, later attempts to credit
+// a different user with the same clue are ignored.
+func (s *State) CreditSolver(clue string, user string) {
+	num, direction, err := ParseClue(clue)
+	if err != nil {
+		return
+	}
+
+	if s.ClueSolvers == nil {
+		s.ClueSolvers = make(map[string]string)
+	}
+
+	key := fmt.Sprintf("%d%s", num, direction)
+	if _, ok := s.ClueSolvers[key]; !ok {
+		s.ClueSolvers[key] = user
+	}
+}
+
+// CreditCheer records the number of bits that were cheered in the message that
+// solved a clue.  Like the credit for solving a clue only the first message to
+// be credited for a clue keeps the credit.
+func (s *State) CreditCheer(clue string, bits int) {
+	num, direction, err := ParseClue(clue)
+	if err != nil {
+		return
+	}
+
+	if s.ClueCheers == nil {
+		s.ClueCheers = make(map[string]int)
+	}
+
+	key := fmt.Sprintf("%d%s", num, direction)
+	if _, ok := s.ClueCheers[key]; !ok {
+		s.ClueCheers[key] = bits
+	}
+}
+
+// LeaderboardEntry describes how many clues a single user has been credited
+// with solving.
+type LeaderboardEntry struct {
+	// The user that solved the clues.
+	User string `json:"user"`
+
+	// The number of clues the user has been credited with solving.
+	Clues int `json:"clues"`
+
+	// The user's position on the leaderboard.  Users that have solved the same
+	// number of clues share the same rank, and the ranks that follow a tie are
+	// skipped (e.g. 1, 2, 2, 4).
+	Rank int `json:"rank"`
+}
+
+// Leaderboard ranks the users that have been credited with solving clues by the
+// number of clues they solved, most first.  Users that have solved the same
+// number of clues are ordered by name.
+func (s *State) Leaderboard() []LeaderboardEntry {
+	counts := make(map[string]int)
+	for _, user := range s.ClueSolvers {
+		counts[user]++
+	}
+
+	entries := make([]LeaderboardEntry, 0, len(counts))
+	for user, count := range counts {
+		entries = append(entries, LeaderboardEntry{User: user, Clues: count})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Clues != entries[j].Clues {
+			return entries[i].Clues > entries[j].Clues
+		}
+		return entries[i].User < entries[j].User
+	})
+
+	for i := range entries {
+		if i > 0 && entries[i].Clues == entries[i-1].Clues {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+
+	return entries
+}
+
 // ClearIncorrectCells will look at each filled in cell of the crossword and
-// clear it if it is filled in with an incorrect answer.  The AcrossCluesFilled
+// clear it if it is filled in with an incorrect answer.  Cells that have been
+// locked by a moderator are left alone.  The AcrossCluesFilled
 // and DownCluesFilled fields will also be updated to indicate any clues that
-// are now unanswered due to cleared cells.
+// are now unanswered due to cleared cells.  If the puzzle doesn't have a
+// solution then no cells are considered incorrect.
 func (s *State) ClearIncorrectCells() error {
-	for y := 0; y < s.Puzzle.Rows; y++ {
+	for y := 0; y < s.Puzzle.Rows && s.Puzzle.HasSolution(); y++ {
 		for x := 0; x < s.Puzzle.Cols; x++ {
+			if s.IsCellLocked(x, y) {
+				continue
+			}
+
 			if s.Cells[y][x] != "" && s.Cells[y][x] != s.Puzzle.Cells[y][x] {
 				s.Cells[y][x] = ""
 			}
@@ -149,7 +819,20 @@ func (s *State) ClearIncorrectCells() error {
 // entry in AcrossCluesFilled or DownCluesFilled will be set to true.  This
 // method doesn't check that the provided answer is correct, just that one is
 // present.
+//
+// Clues that no longer have a complete answer are recorded as having opened
+// again as of the current solve time so that their age can be determined.
 func (s *State) UpdateFilledClues() error {
+	now := s.SolveDuration(time.Now())
+	reopen := func(filled map[int]bool, num int, direction string, complete bool) {
+		if filled[num] && !complete {
+			if s.ClueOpenedAt == nil {
+				s.ClueOpenedAt = make(map[string]model.Duration)
+			}
+			s.ClueOpenedAt[fmt.Sprintf("%d%s", num, direction)] = model.Duration{Duration: now}
+		}
+	}
+
 	for num := range s.Puzzle.CluesAcross {
 		minX, y, maxX, _, err := s.Puzzle.GetAnswerCoordinates(num, "a")
 		if err != nil {
@@ -164,6 +847,7 @@ func (s *State) UpdateFilledClues() error {
 			}
 		}
 
+		reopen(s.AcrossCluesFilled, num, "a", complete)
 		s.AcrossCluesFilled[num] = complete
 	}
 
@@ -181,12 +865,170 @@ func (s *State) UpdateFilledClues() error {
 			}
 		}
 
+		reopen(s.DownCluesFilled, num, "d", complete)
 		s.DownCluesFilled[num] = complete
 	}
 
 	return nil
 }
 
+// Progress describes how far along a crossword solve is.
+type Progress struct {
+	// The number of cells that have a value filled in.
+	CellsFilled int `json:"cells_filled"`
+
+	// The total number of cells that can have a value filled in.
+	CellsTotal int `json:"cells_total"`
+
+	// The number of clues that have a complete answer filled in.
+	CluesFilled int `json:"clues_filled"`
+
+	// The total number of clues in the puzzle.
+	CluesTotal int `json:"clues_total"`
+
+	// The percentage of cells that have a value filled in, rounded down to the
+	// nearest whole percent.
+	Percent int `json:"percent"`
+}
+
+// Progress computes how far along the solve of the crossword is.  The values
+// filled in are not checked for correctness.
+func (s *State) Progress() Progress {
+	var progress Progress
+	if s.Puzzle == nil {
+		return progress
+	}
+
+	for y := 0; y < s.Puzzle.Rows; y++ {
+		for x := 0; x < s.Puzzle.Cols; x++ {
+			if s.Puzzle.CellBlocks[y][x] {
+				continue
+			}
+
+			progress.CellsTotal++
+			if s.Cells[y][x] != "" {
+				progress.CellsFilled++
+			}
+		}
+	}
+
+	for num := range s.Puzzle.CluesAcross {
+		progress.CluesTotal++
+		if s.AcrossCluesFilled[num] {
+			progress.CluesFilled++
+		}
+	}
+
+	for num := range s.Puzzle.CluesDown {
+		progress.CluesTotal++
+		if s.DownCluesFilled[num] {
+			progress.CluesFilled++
+		}
+	}
+
+	if progress.CellsTotal > 0 {
+		progress.Percent = 100 * progress.CellsFilled / progress.CellsTotal
+	}
+
+	return progress
+}
+
+// Text renders the solve as plain text that's suitable for screen readers and
+// other accessibility tools.  The grid is written one row per line with blocks
+// shown as # and empty cells as -, followed by the across and down clues along
+// with whether or not each has been filled in.  Only the values that have been
+// filled in are included, never the solution.
+func (s *State) Text() string {
+	var sb strings.Builder
+	if s.Puzzle == nil {
+		return ""
+	}
+
+	fmt.Fprintf(&sb, "%s\n", s.Puzzle.Description)
+	fmt.Fprintf(&sb, "Status: %s\n", s.Status)
+
+	progress := s.Progress()
+	fmt.Fprintf(&sb, "Filled: %d of %d cells\n", progress.CellsFilled, progress.CellsTotal)
+
+	sb.WriteString("\nGrid:\n")
+	for y := 0; y < s.Puzzle.Rows; y++ {
+		cells := make([]string, s.Puzzle.Cols)
+		for x := 0; x < s.Puzzle.Cols; x++ {
+			switch {
+			case s.Puzzle.CellBlocks[y][x]:
+				cells[x] = "#"
+			case s.Cells[y][x] == "":
+				cells[x] = "-"
+			default:
+				cells[x] = s.Cells[y][x]
+			}
+		}
+
+		fmt.Fprintf(&sb, "Row %d: %s\n", y+1, strings.Join(cells, " "))
+	}
+
+	clues := func(title string, clues map[int]string, filled map[int]bool) {
+		nums := make([]int, 0, len(clues))
+		for num := range clues {
+			nums = append(nums, num)
+		}
+		sort.Ints(nums)
+
+		fmt.Fprintf(&sb, "\n%s:\n", title)
+		for _, num := range nums {
+			status := "empty"
+			if filled[num] {
+				status = "filled"
+			}
+
+			fmt.Fprintf(&sb, "%d. %s (%s)\n", num, clues[num], status)
+		}
+	}
+	clues("Across", s.Puzzle.CluesAcross, s.AcrossCluesFilled)
+	clues("Down", s.Puzzle.CluesDown, s.DownCluesFilled)
+
+	return sb.String()
+}
+
+// SolveDuration returns the total amount of time spent solving the puzzle as of
+// the provided time.  This includes the time since the solve was last started
+// or resumed if it's currently being solved.
+func (s *State) SolveDuration(now time.Time) time.Duration {
+	duration := s.TotalSolveDuration.Duration
+	if s.Status == model.StatusSolving && s.LastStartTime != nil {
+		duration += now.Sub(*s.LastStartTime)
+	}
+
+	return duration
+}
+
+// ClueAge returns how much solve time has elapsed since the clue was last
+// opened, either at the start of the solve or when its answer was last removed.
+// Time that the solve spent paused isn't included.  Clues that currently have a
+// complete answer filled in aren't open and have an age of zero.  If the clue
+// cannot be identified then an error is returned.
+func (s *State) ClueAge(clue string, now time.Time) (time.Duration, bool, error) {
+	num, direction, err := ParseClue(clue)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if _, _, _, _, err := s.Puzzle.GetAnswerCoordinates(num, direction); err != nil {
+		return 0, false, err
+	}
+
+	filled := s.AcrossCluesFilled[num]
+	if direction == "d" {
+		filled = s.DownCluesFilled[num]
+	}
+	if filled {
+		return 0, true, nil
+	}
+
+	opened := s.ClueOpenedAt[fmt.Sprintf("%d%s", num, direction)]
+	return s.SolveDuration(now) - opened.Duration, false, nil
+}
+
 // ParseClue parses the identifier of a clue into its number and direction.
 // If the clue cannot be parsed for some reason then an error will be returned.
 func ParseClue(clue string) (int, string, error) {
@@ -215,18 +1057,25 @@ func ParseClue(clue string) (int, string, error) {
 // ["red", "v", "e", "l", "v", "e", "t"] and fit as the answer for a 7 cell
 // clue.
 //
-// Additionally if an answer contains a "." character anywhere that particular
-// cell will be left empty.  This allows strings like "....s" to be entered to
-// indicate that the answer is known to be plural, but the other letters aren't
-// known yet.  Within a rebus cell "." characters are kept as-is.
+// Additionally if an answer contains a "." or "?" character anywhere that
+// particular cell will be left empty.  This allows strings like "....s" to be
+// entered to indicate that the answer is known to be plural, but the other
+// letters aren't known yet.  A run of empty cells can also be written as a
+// single wildcard followed by a count, so "?4s" is the same as "....s".  Within
+// a rebus cell "." characters are kept as-is.
 //
 // Whitespace within answers is removed and ignored.  This makes it more natural
 // to specify answers like "red velvet cake".
 func ParseAnswer(answer string) ([]string, error) {
+	expanded, err := model.ExpandWildcards(answer)
+	if err != nil {
+		return nil, err
+	}
+
 	var cells []string
 	var inside bool
 
-	for _, c := range strings.ToUpper(answer) {
+	for _, c := range strings.ToUpper(expanded) {
 		switch {
 		case c == ' ':
 			continue
@@ -291,7 +1140,16 @@ func GetState(conn db.Connection, channel string) (State, error) {
 	}
 
 	err := db.Get(conn, StateKey(channel), &state)
-	return state, err
+	if err != nil {
+		return state, err
+	}
+
+	// If the server restarted while the channel was solving then make sure the
+	// downtime isn't counted towards the solve.
+	state.Status, state.LastStartTime, state.TotalSolveDuration =
+		model.ReconcileSolveTimer(state.Status, state.LastStartTime, state.LastSaveTime, state.TotalSolveDuration)
+
+	return state, nil
 }
 
 // SetState writes the state for a channel's crossword solve to redis.  If the
@@ -301,13 +1159,20 @@ func SetState(conn db.Connection, channel string, state State) error {
 		return testStateSaveError
 	}
 
-	return db.SetWithTTL(conn, StateKey(channel), state, StateTTL)
+	now := time.Now()
+	state.LastSaveTime = &now
+
+	if err := db.SetWithTTL(conn, StateKey(channel), state, StateTTL); err != nil {
+		return err
+	}
+
+	return model.RecordActivity(conn, "crossword", channel, now)
 }
 
 // GetAllChannels returns a slice of model.Channel instances for each crossword
-// that contains state in the database.  If there are no active channels then an
-// empty slice is returned.  This method does not update the expiration times
-// of any state instance.
+// that contains state in the database and hasn't been abandoned.  If there are
+// no active channels then an empty slice is returned.  This method does not
+// update the expiration times of any state instance.
 func GetAllChannels(conn db.Connection) ([]model.Channel, error) {
 	keys, err := db.ScanKeys(conn, StateKey("*"))
 	if err != nil {
@@ -328,6 +1193,11 @@ func GetAllChannels(conn db.Connection) ([]model.Channel, error) {
 			return nil, fmt.Errorf("unable to convert value to State: %v", value)
 		}
 
+		// Abandoned puzzles aren't being worked on anymore.
+		if state.Status == model.StatusAbandoned {
+			continue
+		}
+
 		var description string
 		var publisher string
 		var published time.Time
@@ -3,6 +3,7 @@ package crossword
 import (
 	"errors"
 	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/db"
 	"github.com/bbeck/puzzles-with-chat/api/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -253,12 +254,14 @@ func TestState_ApplyAnswer_Cells_CorrectOnly_Error(t *testing.T) {
 		setup    map[string]string // initial answers applied before the desired answer
 		clue     string
 		answer   string
+		correct  int
 	}{
 		{
 			name:     "cannot specify incorrect cell",
 			filename: "xwordinfo-nyt-20181231.json",
 			clue:     "1a",
 			answer:   "R AND A",
+			correct:  4,
 		},
 		{
 			name:     "cannot change correct cell",
@@ -266,8 +269,9 @@ func TestState_ApplyAnswer_Cells_CorrectOnly_Error(t *testing.T) {
 			setup: map[string]string{
 				"1a": "Q AND A",
 			},
-			clue:   "1a",
-			answer: "R AND A",
+			clue:    "1a",
+			answer:  "R AND A",
+			correct: 4,
 		},
 		{
 			name:     "cannot clear correct cell",
@@ -275,8 +279,9 @@ func TestState_ApplyAnswer_Cells_CorrectOnly_Error(t *testing.T) {
 			setup: map[string]string{
 				"1a": "Q AND A",
 			},
-			clue:   "1a",
-			answer: ". AND A",
+			clue:    "1a",
+			answer:  ". AND A",
+			correct: 4,
 		},
 		{
 			name:     "cannot incorrectly specify missing cell",
@@ -284,8 +289,9 @@ func TestState_ApplyAnswer_Cells_CorrectOnly_Error(t *testing.T) {
 			setup: map[string]string{
 				"1a": ". AND A",
 			},
-			clue:   "1a",
-			answer: "R AND A",
+			clue:    "1a",
+			answer:  "R AND A",
+			correct: 4,
 		},
 	}
 
@@ -297,9 +303,123 @@ func TestState_ApplyAnswer_Cells_CorrectOnly_Error(t *testing.T) {
 			}
 
 			err := state.ApplyAnswer(test.clue, test.answer, true)
-			assert.Error(t, err)
+
+			var incorrectErr *IncorrectAnswerError
+			require.True(t, errors.As(err, &incorrectErr))
+			assert.Equal(t, test.correct, incorrectErr.Correct)
+			assert.Equal(t, 5, incorrectErr.Total)
+		})
+	}
+}
+
+func TestState_ApplyAnswer_WildcardCount(t *testing.T) {
+	dots := NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, dots.ApplyAnswer("1a", "Q???A", false))
+
+	count := NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, count.ApplyAnswer("1a", "Q?3A", false))
+
+	assert.Equal(t, dots.Cells, count.Cells)
+	assert.Equal(t, []string{"Q", "", "", "", "A"}, count.Cells[0][:5])
+	assert.False(t, count.AcrossCluesFilled[1])
+}
+
+func TestState_ApplyAnswer_Aliases(t *testing.T) {
+	tests := []struct {
+		name     string
+		solution string            // the solution of the first cell of 1a
+		aliases  map[string]string // additional aliases from the settings
+		answer   string
+	}{
+		{
+			name:     "digit for number word",
+			solution: "ONE",
+			answer:   "(1)ANDA",
+		},
+		{
+			name:     "number word for digit",
+			solution: "1",
+			answer:   "(ONE)ANDA",
+		},
+		{
+			name:     "ampersand for and",
+			solution: "AND",
+			answer:   "(&)ANDA",
+		},
+		{
+			name:     "and for ampersand",
+			solution: "&",
+			answer:   "(AND)ANDA",
+		},
+		{
+			name:     "additional alias",
+			solution: "PLUS",
+			aliases:  map[string]string{"+": "PLUS"},
+			answer:   "(+)ANDA",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.Puzzle.Cells[0][0] = test.solution
+			state.AnswerAliases = test.aliases
+
+			require.NoError(t, state.ApplyAnswer("1a", test.answer, true))
+			assert.Equal(t, test.solution, state.Cells[0][0])
+			assert.True(t, state.IsClueCorrect("1a"))
+		})
+	}
+}
+
+func TestState_ApplyAnswer_Aliases_AcrossCells(t *testing.T) {
+	tests := []struct {
+		name     string
+		aliases  map[string]string // additional aliases from the settings
+		answer   string
+		expected []string // the cells of 1a after the answer is applied
+	}{
+		{
+			name:     "ampersand spans cells",
+			answer:   "Q&A",
+			expected: []string{"Q", "A", "N", "D", "A"},
+		},
+		{
+			name:     "ampersand with wildcard",
+			answer:   "Q&?",
+			expected: []string{"Q", "A", "N", "D", ""},
+		},
+		{
+			name:     "additional alias spans cells",
+			aliases:  map[string]string{"+": "AND"},
+			answer:   "Q+A",
+			expected: []string{"Q", "A", "N", "D", "A"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.AnswerAliases = test.aliases
+
+			require.NoError(t, state.ApplyAnswer("1a", test.answer, false))
+			assert.Equal(t, test.expected, state.Cells[0][:5])
 		})
 	}
+
+	// An answer that still doesn't fit once its aliases are expanded is
+	// rejected.
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	var lengthErr *AnswerLengthError
+	assert.True(t, errors.As(state.ApplyAnswer("1a", "Q&&A", false), &lengthErr))
+}
+
+func TestState_ApplyAnswer_Aliases_Error(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Puzzle.Cells[0][0] = "ONE"
+
+	assert.Error(t, state.ApplyAnswer("1a", "(2)ANDA", true))
+	assert.Error(t, state.ApplyAnswer("1a", "(+)ANDA", true))
 }
 
 func TestState_ApplyAnswer_Filled(t *testing.T) {
@@ -582,6 +702,162 @@ func TestState_ApplyAnswer_Error(t *testing.T) {
 	}
 }
 
+func TestState_ApplyAnswer_LengthError(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   string
+		expected string
+	}{
+		{
+			name:     "answer too short",
+			answer:   "QAND",
+			expected: "expected 5 letters, got 4",
+		},
+		{
+			name:     "answer too long",
+			answer:   "Q AND AS",
+			expected: "expected 5 letters, got 6",
+		},
+		{
+			name:     "answer too short (rebus)",
+			answer:   "(Q AND A)",
+			expected: "expected 5 letters, got 1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			err := state.ApplyAnswer("1a", test.answer, false)
+
+			var lengthErr *AnswerLengthError
+			require.True(t, errors.As(err, &lengthErr))
+			assert.Equal(t, 5, lengthErr.Expected)
+			assert.EqualError(t, err, test.expected)
+		})
+	}
+}
+
+func TestState_ApplyAnswer_UncheckedCells(t *testing.T) {
+	puzzle := LoadTestPuzzle(t, "xwordinfo-variety-20240114-unchecked.json")
+
+	var state State
+	state.resetEphemeralState(puzzle)
+
+	// The cell that doesn't belong to any clue starts out filled in since no
+	// answer could ever fill it.
+	assert.Equal(t, "X", state.Cells[2][2])
+
+	// An across answer only fills in its own cells and doesn't mark the down
+	// clue that shares its first cell as filled.
+	require.NoError(t, state.ApplyAnswer("1a", "CAT", true))
+	assert.Equal(t, []string{"C", "A", "T", "", ""}, state.Cells[0])
+	assert.Equal(t, map[int]bool{1: true, 3: false}, state.AcrossCluesFilled)
+	assert.Equal(t, map[int]bool{1: false, 2: false}, state.DownCluesFilled)
+
+	// A down answer through cells that are only part of the down clue doesn't
+	// mark any across clue as filled until all of its cells are filled.
+	require.NoError(t, state.ApplyAnswer("2d", "SODAS", true))
+	assert.Equal(t, map[int]bool{1: true, 3: false}, state.AcrossCluesFilled)
+	assert.Equal(t, map[int]bool{1: false, 2: true}, state.DownCluesFilled)
+	assert.Equal(t, "", state.Cells[4][1])
+
+	require.NoError(t, state.ApplyAnswer("1d", "CARS", true))
+	assert.Equal(t, model.StatusSelected, state.Status)
+
+	// Filling in every clue completes the puzzle.
+	require.NoError(t, state.ApplyAnswer("3a", "DOGS", true))
+	assert.Equal(t, map[int]bool{1: true, 3: true}, state.AcrossCluesFilled)
+	assert.Equal(t, map[int]bool{1: true, 2: true}, state.DownCluesFilled)
+	assert.Equal(t, model.StatusComplete, state.Status)
+	assert.True(t, state.IsComplete(100))
+}
+func TestState_ApplyAnswer_RightToLeft(t *testing.T) {
+	var state State
+	state.resetEphemeralState(LoadTestPuzzle(t, "puzzle-rtl.json"))
+
+	// Across answers are written starting from the rightmost cell.
+	require.NoError(t, state.ApplyAnswer("1a", "CAT", true))
+	assert.Equal(t, []string{"T", "A", "C"}, state.Cells[0])
+	assert.True(t, state.AcrossCluesFilled[1])
+
+	require.NoError(t, state.ApplyAnswer("5a", "EA", true))
+	assert.Equal(t, []string{"", "A", "E"}, state.Cells[2])
+
+	// Down answers are unaffected.
+	require.NoError(t, state.ApplyAnswer("3d", "TE", true))
+	assert.Equal(t, "E", state.Cells[1][0])
+
+	// Reading an across answer left to right places the letters incorrectly.
+	assert.Error(t, state.ApplyAnswer("4a", "ERA", true))
+
+	require.NoError(t, state.ApplyAnswer("4a", "ARE", true))
+	assert.Equal(t, []string{"E", "R", "A"}, state.Cells[1])
+	assert.Equal(t, model.StatusComplete, state.Status)
+}
+
+func TestState_PencilAnswer_RightToLeft(t *testing.T) {
+	var state State
+	state.resetEphemeralState(LoadTestPuzzle(t, "puzzle-rtl.json"))
+
+	require.NoError(t, state.ApplyPencilAnswer("1a", "CA."))
+	assert.Equal(t, []string{"", "A", "C"}, state.PencilCells[0])
+
+	answer, err := state.PencilAnswer("1a")
+	require.NoError(t, err)
+	assert.Equal(t, "CA.", answer)
+}
+
+func TestState_ApplyPencilAnswer(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+
+	// Penciling in an answer doesn't change the committed cells.
+	require.NoError(t, state.ApplyPencilAnswer("1a", "QANDA"))
+	assert.Equal(t, []string{"Q", "A", "N", "D", "A"}, state.PencilCells[0][0:5])
+	assert.Equal(t, []string{"", "", "", "", ""}, state.Cells[0][0:5])
+	assert.False(t, state.AcrossCluesFilled[1])
+
+	// Empty cells of a pencil answer erase what was penciled in.
+	require.NoError(t, state.ApplyPencilAnswer("1d", ".TIP"))
+	assert.Equal(t, "", state.PencilCells[0][0])
+	assert.Equal(t, "T", state.PencilCells[1][0])
+
+	// Writing a cell in pen clears its pencil value.
+	require.NoError(t, state.ApplyAnswer("1d", "QTIP", false))
+	assert.Equal(t, "", state.PencilCells[1][0])
+	assert.Equal(t, "A", state.PencilCells[0][1])
+}
+
+func TestState_ApplyPencilAnswer_Error(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+
+	assert.Error(t, state.ApplyPencilAnswer("1x", "QANDA"))
+	assert.Error(t, state.ApplyPencilAnswer("2a", "QANDA"))
+	assert.Error(t, state.ApplyPencilAnswer("1a", "QAND"))
+	assert.Error(t, state.ApplyPencilAnswer("1a", "(QANDA"))
+	assert.Nil(t, state.PencilCells)
+	assert.Equal(t, "", state.FocusedClue)
+}
+
+func TestState_PencilAnswer(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+
+	// A clue without anything penciled in can't be promoted.
+	_, err := state.PencilAnswer("1a")
+	assert.Error(t, err)
+
+	// Penciled cells are combined with the committed ones.
+	require.NoError(t, state.ApplyAnswer("1d", "QTIP", false))
+	require.NoError(t, state.ApplyPencilAnswer("1a", ".(AB).D."))
+
+	answer, err := state.PencilAnswer("1a")
+	require.NoError(t, err)
+	assert.Equal(t, "Q(AB).D.", answer)
+
+	_, err = state.PencilAnswer("1x")
+	assert.Error(t, err)
+}
+
 func TestState_ClearIncorrectCells(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -714,6 +990,507 @@ func TestState_ClearIncorrectCells(t *testing.T) {
 	}
 }
 
+func TestState_ResetEphemeralState(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, state.ApplyAnswer("1a", "QANDA", false))
+	require.NoError(t, state.ApplyPencilAnswer("6a", "ATTIC"))
+	require.NoError(t, state.FocusClue("6a"))
+	state.Status = model.StatusSolving
+	state.TotalSolveDuration = model.Duration{Duration: 10 * time.Minute}
+	state.Reveals = 3
+	require.NoError(t, state.LockCells([]Cell{{Row: 0, Col: 0}}, true))
+	now := time.Now()
+	state.LastToggleTime = &now
+
+	puzzle := LoadTestPuzzle(t, "xwordinfo-nyt-20180621-nonsquare.json")
+	state.resetEphemeralState(puzzle)
+
+	assert.Equal(t, model.StatusSelected, state.Status)
+	assert.Equal(t, puzzle, state.Puzzle)
+	assert.Equal(t, puzzle.Rows, len(state.Cells))
+	for _, row := range state.Cells {
+		assert.Equal(t, puzzle.Cols, len(row))
+		for _, cell := range row {
+			assert.Equal(t, "", cell)
+		}
+	}
+	assert.Empty(t, state.AcrossCluesFilled)
+	assert.Empty(t, state.DownCluesFilled)
+	assert.Nil(t, state.LastStartTime)
+	assert.Equal(t, time.Duration(0), state.TotalSolveDuration.Duration)
+	assert.Empty(t, state.ClueSolvers)
+	assert.Empty(t, state.Proposals)
+	assert.Nil(t, state.PencilCells)
+	assert.Nil(t, state.LockedCells)
+	assert.Equal(t, 0, state.Reveals)
+	assert.Nil(t, state.LastToggleTime)
+}
+
+func TestState_Givens(t *testing.T) {
+	puzzle := LoadTestPuzzle(t, "puz/nyt-20081006-nonsquare-givens.puz")
+
+	var state State
+	state.resetEphemeralState(puzzle)
+
+	// The givens should be pre-filled.
+	assert.Equal(t, "O", state.Cells[0][0])
+	assert.Equal(t, "", state.Cells[0][1])
+	assert.Equal(t, "E", state.Cells[0][2])
+	assert.Equal(t, "O", state.Cells[1][1])
+
+	// An answer shouldn't be able to change or clear a given.
+	require.NoError(t, state.ApplyAnswer("1a", "XXXX", false))
+	assert.Equal(t, "O", state.Cells[0][0])
+	assert.Equal(t, "X", state.Cells[0][1])
+	assert.Equal(t, "E", state.Cells[0][2])
+
+	require.NoError(t, state.ApplyAnswer("1a", "....", false))
+	assert.Equal(t, "O", state.Cells[0][0])
+	assert.Equal(t, "", state.Cells[0][1])
+	assert.Equal(t, "E", state.Cells[0][2])
+
+	// When only correct answers are allowed the givens shouldn't prevent an
+	// answer from being applied.
+	require.NoError(t, state.ApplyAnswer("1a", "..EG", true))
+	assert.Equal(t, "O", state.Cells[0][0])
+	assert.Equal(t, "", state.Cells[0][1])
+	assert.Equal(t, "E", state.Cells[0][2])
+	assert.Equal(t, "G", state.Cells[0][3])
+
+	// Clearing incorrect cells should leave the givens alone.
+	require.NoError(t, state.ClearIncorrectCells())
+	assert.Equal(t, "O", state.Cells[0][0])
+	assert.Equal(t, "E", state.Cells[0][2])
+	assert.Equal(t, "O", state.Cells[1][1])
+}
+
+func TestState_LockCells(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, state.ApplyAnswer("1a", "QANDA", false))
+
+	cells, err := state.ClueCells("1a")
+	require.NoError(t, err)
+	require.NoError(t, state.LockCells(cells, true))
+	for x := 0; x < 5; x++ {
+		assert.True(t, state.IsCellLocked(x, 0))
+	}
+	assert.False(t, state.IsCellLocked(0, 1))
+
+	// Answers can't change locked cells, but the rest of the answer is applied.
+	require.NoError(t, state.ApplyAnswer("1a", "XXXXX", false))
+	assert.Equal(t, []string{"Q", "A", "N", "D", "A"}, state.Cells[0][:5])
+
+	require.NoError(t, state.ApplyAnswer("1d", "XTIP", false))
+	assert.Equal(t, "Q", state.Cells[0][0])
+	assert.Equal(t, "T", state.Cells[1][0])
+
+	// Locked cells aren't penciled in.
+	require.NoError(t, state.ApplyPencilAnswer("2d", "ABCDE"))
+	assert.Equal(t, "", state.PencilCells[0][1])
+	assert.Equal(t, "B", state.PencilCells[1][1])
+
+	// Once unlocked the cells can be changed again.
+	require.NoError(t, state.LockCells([]Cell{{Row: 0, Col: 0}}, false))
+	assert.False(t, state.IsCellLocked(0, 0))
+	assert.True(t, state.IsCellLocked(1, 0))
+
+	require.NoError(t, state.ApplyAnswer("1a", "XXXXX", false))
+	assert.Equal(t, []string{"X", "A", "N", "D", "A"}, state.Cells[0][:5])
+}
+
+func TestState_LockCells_ClearIncorrectCells(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, state.ApplyAnswer("1a", "XXXXX", false))
+	require.NoError(t, state.LockCells([]Cell{{Row: 0, Col: 0}}, true))
+
+	// Locked cells are left alone even if they're incorrect.
+	require.NoError(t, state.ClearIncorrectCells())
+	assert.Equal(t, []string{"X", "", "", "", ""}, state.Cells[0][:5])
+}
+
+func TestState_LockCells_Error(t *testing.T) {
+	tests := []struct {
+		name  string
+		cells []Cell
+	}{
+		{
+			name:  "negative row",
+			cells: []Cell{{Row: -1, Col: 0}},
+		},
+		{
+			name:  "col out of bounds",
+			cells: []Cell{{Row: 0, Col: 15}},
+		},
+		{
+			name:  "block",
+			cells: []Cell{{Row: 0, Col: 0}, {Row: 0, Col: 5}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			assert.Error(t, state.LockCells(test.cells, true))
+
+			// No cells are locked when there's an error.
+			assert.False(t, state.IsCellLocked(0, 0))
+		})
+	}
+
+	// A state without a puzzle doesn't have any cells to lock.
+	var state State
+	assert.Error(t, state.LockCells([]Cell{{Row: 0, Col: 0}}, true))
+}
+
+func TestState_FocusClue(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+
+	require.NoError(t, state.FocusClue("1a"))
+	assert.Equal(t, "1a", state.FocusedClue)
+
+	require.NoError(t, state.FocusClue(" 16D "))
+	assert.Equal(t, "16d", state.FocusedClue)
+
+	// Invalid clues leave the focus unchanged.
+	assert.Error(t, state.FocusClue("1x"))
+	assert.Error(t, state.FocusClue("999a"))
+	assert.Equal(t, "16d", state.FocusedClue)
+
+	var empty State
+	assert.Error(t, empty.FocusClue("1a"))
+}
+
+func TestState_IsComplete(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		incorrect int // number of cells to fill in incorrectly
+		empty     int // number of cells to leave empty
+		expected  bool
+	}{
+		{
+			name:      "threshold 0, all correct",
+			threshold: 0,
+			expected:  true,
+		},
+		{
+			name:      "threshold 0, all incorrect",
+			threshold: 0,
+			incorrect: 187,
+			expected:  true,
+		},
+		{
+			name:      "threshold 0, not filled",
+			threshold: 0,
+			empty:     1,
+			expected:  false,
+		},
+		{
+			name:      "threshold 80, all correct",
+			threshold: 80,
+			expected:  true,
+		},
+		{
+			name:      "threshold 80, exactly enough correct",
+			threshold: 80,
+			incorrect: 37, // 150 of 187 correct is 80.2%
+			expected:  true,
+		},
+		{
+			name:      "threshold 80, too few correct",
+			threshold: 80,
+			incorrect: 38, // 149 of 187 correct is 79.7%
+			expected:  false,
+		},
+		{
+			name:      "threshold 80, not filled",
+			threshold: 80,
+			empty:     1,
+			expected:  false,
+		},
+		{
+			name:      "threshold 100, all correct",
+			threshold: 100,
+			expected:  true,
+		},
+		{
+			name:      "threshold 100, one incorrect",
+			threshold: 100,
+			incorrect: 1,
+			expected:  false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+
+			var incorrect, empty int
+			for y := 0; y < state.Puzzle.Rows; y++ {
+				for x := 0; x < state.Puzzle.Cols; x++ {
+					if state.Puzzle.CellBlocks[y][x] {
+						continue
+					}
+
+					switch {
+					case empty < test.empty:
+						empty++
+					case incorrect < test.incorrect:
+						state.Cells[y][x] = "#"
+						incorrect++
+					default:
+						state.Cells[y][x] = state.Puzzle.Cells[y][x]
+					}
+				}
+			}
+
+			assert.Equal(t, test.expected, state.IsComplete(test.threshold))
+		})
+	}
+}
+
+func TestState_CreditSolver(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+
+	state.CreditSolver("1a", "alice")
+	state.CreditSolver("1A", "bob")
+	state.CreditSolver("2d", "bob")
+	state.CreditSolver("invalid", "carol")
+
+	assert.Equal(t, map[string]string{"1a": "alice", "2d": "bob"}, state.ClueSolvers)
+}
+
+func TestState_CreditCheer(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+
+	state.CreditCheer("1a", 100)
+	state.CreditCheer("1A", 500)
+	state.CreditCheer("2d", 1)
+	state.CreditCheer("invalid", 10)
+
+	assert.Equal(t, map[string]int{"1a": 100, "2d": 1}, state.ClueCheers)
+}
+
+func TestState_Leaderboard(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	assert.Equal(t, []LeaderboardEntry{}, state.Leaderboard())
+
+	state.CreditSolver("1a", "carol")
+	state.CreditSolver("6a", "bob")
+	state.CreditSolver("1d", "alice")
+	state.CreditSolver("2d", "bob")
+	state.CreditSolver("3d", "alice")
+	state.CreditSolver("4d", "dave")
+
+	expected := []LeaderboardEntry{
+		{User: "alice", Clues: 2, Rank: 1},
+		{User: "bob", Clues: 2, Rank: 1},
+		{User: "carol", Clues: 1, Rank: 3},
+		{User: "dave", Clues: 1, Rank: 3},
+	}
+	assert.Equal(t, expected, state.Leaderboard())
+}
+
+func TestState_AdjacentUnfilledClue(t *testing.T) {
+	tests := []struct {
+		name     string
+		focused  string
+		filled   []string
+		forward  bool
+		expected string
+	}{
+		{
+			name:     "next with nothing focused",
+			forward:  true,
+			expected: "1a",
+		},
+		{
+			name:     "previous with nothing focused",
+			expected: "60d",
+		},
+		{
+			name:     "next across clue",
+			focused:  "1a",
+			forward:  true,
+			expected: "6a",
+		},
+		{
+			name:     "previous across clue",
+			focused:  "6a",
+			expected: "1a",
+		},
+		{
+			name:     "next skips filled clues",
+			focused:  "1a",
+			filled:   []string{"6a", "11a"},
+			forward:  true,
+			expected: "14a",
+		},
+		{
+			name:     "previous skips filled clues",
+			focused:  "14a",
+			filled:   []string{"6a", "11a"},
+			expected: "1a",
+		},
+		{
+			name:     "next switches from across to down",
+			focused:  "65a",
+			forward:  true,
+			expected: "1d",
+		},
+		{
+			name:     "previous switches from down to across",
+			focused:  "1d",
+			expected: "65a",
+		},
+		{
+			name:     "next wraps around from down to across",
+			focused:  "60d",
+			forward:  true,
+			expected: "1a",
+		},
+		{
+			name:     "previous wraps around from across to down",
+			focused:  "1a",
+			expected: "60d",
+		},
+		{
+			name:     "next wraps around skipping filled clues",
+			focused:  "57d",
+			filled:   []string{"59d", "60d", "1a"},
+			forward:  true,
+			expected: "6a",
+		},
+		{
+			name:     "focused clue filled",
+			focused:  "1a",
+			filled:   []string{"1a"},
+			forward:  true,
+			expected: "6a",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			state := NewState(t, "xwordinfo-nyt-20181231.json")
+			state.FocusedClue = test.focused
+			for _, clue := range test.filled {
+				num, dir, err := ParseClue(clue)
+				require.NoError(t, err)
+				if dir == "a" {
+					state.AcrossCluesFilled[num] = true
+				} else {
+					state.DownCluesFilled[num] = true
+				}
+			}
+
+			clue, err := state.AdjacentUnfilledClue(test.forward)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, clue)
+		})
+	}
+}
+
+func TestState_AdjacentUnfilledClue_Error(t *testing.T) {
+	// Without a puzzle there are no clues.
+	var state State
+	_, err := state.AdjacentUnfilledClue(true)
+	assert.Error(t, err)
+
+	// When every clue is filled there's nowhere to go.
+	state = NewState(t, "xwordinfo-nyt-20181231.json")
+	for num := range state.Puzzle.CluesAcross {
+		state.AcrossCluesFilled[num] = true
+	}
+	for num := range state.Puzzle.CluesDown {
+		state.DownCluesFilled[num] = true
+	}
+
+	_, err = state.AdjacentUnfilledClue(true)
+	assert.True(t, errors.Is(err, ErrNoUnfilledClues))
+	_, err = state.AdjacentUnfilledClue(false)
+	assert.True(t, errors.Is(err, ErrNoUnfilledClues))
+}
+
+func TestState_IsClueCorrect(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	assert.False(t, state.IsClueCorrect("1a"))
+
+	require.NoError(t, state.ApplyAnswer("1a", "QANDX", false))
+	assert.False(t, state.IsClueCorrect("1a"))
+
+	require.NoError(t, state.ApplyAnswer("1a", "QANDA", false))
+	assert.True(t, state.IsClueCorrect("1a"))
+	assert.False(t, state.IsClueCorrect("1d"))
+	assert.False(t, state.IsClueCorrect("999a"))
+	assert.False(t, state.IsClueCorrect("invalid"))
+}
+
+func TestState_ClueAge(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusPaused
+	state.LastStartTime = nil
+	state.TotalSolveDuration = model.Duration{Duration: 10 * time.Minute}
+	now := time.Now()
+
+	// While the solve is paused an open clue doesn't get any older.
+	age, filled, err := state.ClueAge("1a", now)
+	require.NoError(t, err)
+	assert.False(t, filled)
+	assert.Equal(t, 10*time.Minute, age)
+
+	age, _, err = state.ClueAge("1a", now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, age)
+
+	// Once the solve resumes the clue ages again.
+	start := now.Add(-2 * time.Minute)
+	state.Status = model.StatusSolving
+	state.LastStartTime = &start
+
+	age, _, err = state.ClueAge("1a", now)
+	require.NoError(t, err)
+	assert.Equal(t, 12*time.Minute, age)
+
+	// A clue with an answer filled in isn't open.
+	require.NoError(t, state.ApplyAnswer("1a", "QANDA", false))
+	age, filled, err = state.ClueAge("1a", now)
+	require.NoError(t, err)
+	assert.True(t, filled)
+	assert.Equal(t, time.Duration(0), age)
+
+	// Removing the answer opens the clue again as of the current solve time.
+	require.NoError(t, state.ApplyAnswer("1a", "Q....", false))
+	age, filled, err = state.ClueAge("1a", now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, filled)
+	assert.InDelta(t, float64(time.Minute), float64(age), float64(time.Second))
+
+	// Time spent paused after the clue opened again isn't included either.
+	state.TotalSolveDuration = model.Duration{Duration: state.SolveDuration(now.Add(time.Minute))}
+	state.Status = model.StatusPaused
+	state.LastStartTime = nil
+
+	age, _, err = state.ClueAge("1a", now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.InDelta(t, float64(time.Minute), float64(age), float64(time.Second))
+
+	// The crossing clues were never filled in so they've been open the whole
+	// solve.
+	age, _, err = state.ClueAge("1d", now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 13*time.Minute, age)
+}
+
+func TestState_ClueAge_Error(t *testing.T) {
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+
+	for _, clue := range []string{"1x", "2a", "999d"} {
+		_, _, err := state.ClueAge(clue, time.Now())
+		assert.Error(t, err, clue)
+	}
+}
+
 func TestParseClue(t *testing.T) {
 	tests := []struct {
 		clue        string
@@ -809,10 +1586,22 @@ func TestParseAnswer(t *testing.T) {
 			answer:   "....S",
 			expected: []string{"", "", "", "", "S"},
 		},
+		{
+			answer:   "????S",
+			expected: []string{"", "", "", "", "S"},
+		},
+		{
+			answer:   "?4S",
+			expected: []string{"", "", "", "", "S"},
+		},
 		{
 			answer:   "(RED) VELVET CAKE",
 			expected: []string{"RED", "V", "E", "L", "V", "E", "T", "C", "A", "K", "E"},
 		},
+		{
+			answer:   "(RED) ?6 CAKE",
+			expected: []string{"RED", "", "", "", "", "", "", "C", "A", "K", "E"},
+		},
 	}
 
 	for _, test := range tests {
@@ -832,6 +1621,8 @@ func TestParseAnswer_Error(t *testing.T) {
 		"((red) velvet cake",
 		"red velvet cake)",
 		")red velvet cake",
+		"??3S",
+		"?0S",
 	}
 
 	for _, answer := range tests {
@@ -842,6 +1633,31 @@ func TestParseAnswer_Error(t *testing.T) {
 	}
 }
 
+func TestGetState_StaleLastStartTime(t *testing.T) {
+	_, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	// Simulate a solve that was in progress long before the server started and
+	// was last saved 5 minutes after it was started.
+	start := model.ServerStartTime.Add(-24 * time.Hour)
+	save := start.Add(5 * time.Minute)
+
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	state.LastStartTime = &start
+	state.TotalSolveDuration = model.Duration{Duration: time.Minute}
+	state.LastSaveTime = &save
+
+	// Write the state directly so that the save time isn't changed.
+	require.NoError(t, db.Set(conn, StateKey("channel"), state))
+
+	loaded, err := GetState(conn, "channel")
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusPaused, loaded.Status)
+	assert.Nil(t, loaded.LastStartTime)
+	assert.Equal(t, 6*time.Minute, loaded.TotalSolveDuration.Duration)
+}
+
 func TestGetAllChannels(t *testing.T) {
 	type ChannelToCreate struct {
 		name     string
@@ -966,6 +1782,32 @@ func TestGetAllChannels(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "abandoned channel",
+			channels: []ChannelToCreate{
+				{
+					name:     "channel1",
+					filename: "xwordinfo-nyt-20181231.json",
+					status:   model.StatusSolving,
+				},
+				{
+					name:     "channel2",
+					filename: "puzzle-wsj-20190102.json",
+					status:   model.StatusAbandoned,
+				},
+			},
+			expected: []model.Channel{
+				{
+					Name:        "channel1",
+					Status:      model.StatusSolving,
+					Description: "New York Times puzzle from 2018-12-31",
+					Puzzle: model.PuzzleSource{
+						Publisher:     "The New York Times",
+						PublishedDate: time.Date(2018, time.December, 31, 0, 0, 0, 0, time.UTC),
+					},
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,177 @@
+package crossword
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/db"
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"github.com/gomodule/redigo/redis"
+	"time"
+)
+
+// SolveRecord is a record of a single crossword solve that a channel finished,
+// either by completing the puzzle or by abandoning it.  It can be marshalled
+// to/from JSON.
+type SolveRecord struct {
+	// How the solve ended, either complete or abandoned.
+	Status model.Status `json:"status"`
+
+	// The description of the puzzle that was solved.
+	Description string `json:"description"`
+
+	// The publisher of the puzzle that was solved, if known.
+	Publisher string `json:"publisher,omitempty"`
+
+	// The date the puzzle that was solved was published on, if known.
+	PublishedDate time.Time `json:"published_date"`
+
+	// How long was spent solving the puzzle.
+	Duration model.Duration `json:"duration"`
+
+	// The number of cells whose solution was revealed during the solve.
+	Reveals int `json:"reveals"`
+
+	// When the solve ended.
+	Time time.Time `json:"time"`
+}
+
+// NewSolveRecord creates a record of a solve from the state it ended in.  If the
+// solve's timer is still running then the time up until the provided time is
+// included in the record's duration.
+func NewSolveRecord(state State, now time.Time) SolveRecord {
+	duration := state.TotalSolveDuration.Duration
+	if state.LastStartTime != nil {
+		duration += now.Sub(*state.LastStartTime)
+	}
+
+	record := SolveRecord{
+		Status:   state.Status,
+		Duration: model.Duration{Duration: duration},
+		Reveals:  state.Reveals,
+		Time:     now,
+	}
+
+	if state.Puzzle != nil {
+		record.Description = state.Puzzle.Description
+		record.Publisher = state.Puzzle.Publisher
+		record.PublishedDate = state.Puzzle.PublishedDate
+	}
+
+	return record
+}
+
+// StatsMaxEntries is the maximum number of solve records that are retained for
+// a channel.  Once the limit is reached the oldest records are discarded.
+var StatsMaxEntries = 1000
+
+// StatsKey returns the key that should be used in redis to store a particular
+// channel's solve records.
+func StatsKey(name string) string {
+	return fmt.Sprintf("%s:crossword:stats", name)
+}
+
+// RecordSolve adds a record to the front of a channel's solve records.  Unlike
+// the channel's state the records don't expire, but only the most recent
+// StatsMaxEntries of them that are no older than StatsMaxAge are retained.
+func RecordSolve(conn db.Connection, channel string, record SolveRecord) error {
+	bs, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	key := StatsKey(channel)
+	if _, err := conn.Do("LPUSH", key, bs); err != nil {
+		return err
+	}
+
+	return TrimList(conn, key, StatsMaxEntries, StatsMaxAge, time.Now())
+}
+
+// GetSolveRecords returns up to limit of the most recent solve records for a
+// channel ordered from newest to oldest.
+func GetSolveRecords(conn db.Connection, channel string, limit int) ([]SolveRecord, error) {
+	values, err := redis.ByteSlices(conn.Do("LRANGE", StatsKey(channel), 0, limit-1))
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]SolveRecord, 0, len(values))
+	for _, value := range values {
+		var record SolveRecord
+		if err := json.Unmarshal(value, &record); err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// Summary is an aggregation of the completed solves in a channel's solve
+// records.  Abandoned solves aren't included.  It can be marshalled to/from
+// JSON.
+type Summary struct {
+	// The number of completed solves.
+	Count int `json:"count"`
+
+	// The average duration of the completed solves.
+	MeanDuration model.Duration `json:"mean_duration"`
+
+	// The duration of the quickest completed solve.
+	FastestDuration model.Duration `json:"fastest_duration"`
+
+	// The duration of the slowest completed solve.
+	SlowestDuration model.Duration `json:"slowest_duration"`
+
+	// The number of completed solves for each publisher.  Solves of puzzles
+	// without a publisher aren't counted.
+	ByPublisher map[string]int `json:"by_publisher"`
+
+	// The number of completed solves of puzzles published on each day of the
+	// week, indexed by the name of the weekday.  For most publishers the day of
+	// the week determines the difficulty of the puzzle.  Solves of puzzles
+	// without a published date aren't counted.
+	ByWeekday map[string]int `json:"by_weekday"`
+}
+
+// Summarize aggregates the completed solves in a set of solve records.
+func Summarize(records []SolveRecord) Summary {
+	summary := Summary{
+		ByPublisher: make(map[string]int),
+		ByWeekday:   make(map[string]int),
+	}
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		summary.ByWeekday[weekday.String()] = 0
+	}
+
+	var total time.Duration
+	for _, record := range records {
+		if record.Status != model.StatusComplete {
+			continue
+		}
+
+		duration := record.Duration.Duration
+		if summary.Count == 0 || duration < summary.FastestDuration.Duration {
+			summary.FastestDuration = record.Duration
+		}
+		if summary.Count == 0 || duration > summary.SlowestDuration.Duration {
+			summary.SlowestDuration = record.Duration
+		}
+		summary.Count++
+		total += duration
+
+		if record.Publisher != "" {
+			summary.ByPublisher[record.Publisher]++
+		}
+		if !record.PublishedDate.IsZero() {
+			summary.ByWeekday[record.PublishedDate.Weekday().String()]++
+		}
+	}
+
+	if summary.Count > 0 {
+		summary.MeanDuration = model.Duration{Duration: total / time.Duration(summary.Count)}
+	}
+
+	return summary
+}
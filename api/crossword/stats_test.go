@@ -0,0 +1,107 @@
+package crossword
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSolveRecord(t *testing.T) {
+	now := time.Date(2024, time.March, 11, 12, 0, 0, 0, time.UTC)
+
+	// A paused solve only includes the accumulated time.
+	state := NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusComplete
+	state.LastStartTime = nil
+	state.TotalSolveDuration = model.Duration{Duration: 5 * time.Minute}
+	state.Reveals = 4
+
+	record := NewSolveRecord(state, now)
+	assert.Equal(t, SolveRecord{
+		Status:        model.StatusComplete,
+		Description:   "New York Times puzzle from 2018-12-31",
+		Publisher:     "The New York Times",
+		PublishedDate: time.Date(2018, time.December, 31, 0, 0, 0, 0, time.UTC),
+		Duration:      model.Duration{Duration: 5 * time.Minute},
+		Reveals:       4,
+		Time:          now,
+	}, record)
+
+	// A running solve also includes the time since it was last started.
+	start := now.Add(-10 * time.Minute)
+	state.LastStartTime = &start
+
+	record = NewSolveRecord(state, now)
+	assert.Equal(t, 15*time.Minute, record.Duration.Duration)
+}
+
+func TestRecordSolve(t *testing.T) {
+	_, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	now := time.Date(2024, time.March, 11, 12, 0, 0, 0, time.UTC)
+	first := SolveRecord{Status: model.StatusComplete, Description: "first", Time: now}
+	second := SolveRecord{Status: model.StatusAbandoned, Description: "second", Time: now.Add(time.Hour)}
+	require.NoError(t, RecordSolve(conn, Channel.name, first))
+	require.NoError(t, RecordSolve(conn, Channel.name, second))
+
+	// Records are returned newest first.
+	records, err := GetSolveRecords(conn, Channel.name, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []SolveRecord{second, first}, records)
+
+	// The limit controls how many records are returned.
+	records, err = GetSolveRecords(conn, Channel.name, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []SolveRecord{second}, records)
+
+	// A channel that has never finished a solve doesn't have any records.
+	records, err = GetSolveRecords(conn, "other", 10)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestRecordSolve_MaxEntries(t *testing.T) {
+	_, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	StatsMaxEntries = 3
+	defer func() { StatsMaxEntries = 1000 }()
+
+	for _, description := range []string{"1", "2", "3", "4"} {
+		require.NoError(t, RecordSolve(conn, Channel.name, SolveRecord{Description: description}))
+	}
+
+	records, err := GetSolveRecords(conn, Channel.name, 10)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(records))
+	assert.Equal(t, "4", records[0].Description)
+	assert.Equal(t, "2", records[2].Description)
+}
+
+func TestRecordSolve_MaxAge(t *testing.T) {
+	_, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	StatsMaxAge = 24 * time.Hour
+	defer func() { StatsMaxAge = 0 }()
+
+	now := time.Now()
+	for _, record := range []SolveRecord{
+		{Description: "1", Time: now.Add(-72 * time.Hour)},
+		{Description: "2", Time: now.Add(-48 * time.Hour)},
+		{Description: "3", Time: now.Add(-time.Hour)},
+		{Description: "4", Time: now},
+	} {
+		require.NoError(t, RecordSolve(conn, Channel.name, record))
+	}
+
+	records, err := GetSolveRecords(conn, Channel.name, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(records))
+	assert.Equal(t, "4", records[0].Description)
+	assert.Equal(t, "3", records[1].Description)
+}
@@ -0,0 +1,96 @@
+package crossword
+
+import (
+	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/db"
+	"time"
+)
+
+// Streak tracks the number of consecutive days that a channel has completed a
+// crossword.  Days are calendar days in UTC and are determined by when the
+// crossword was completed.  It can be marshalled to/from JSON.
+type Streak struct {
+	// The number of consecutive days, ending with LastSolveDate, that the
+	// channel completed a crossword on.
+	Count int `json:"count"`
+
+	// The longest streak that the channel has ever had.
+	Best int `json:"best"`
+
+	// The most recent day that the channel completed a crossword on.
+	LastSolveDate time.Time `json:"last_solve_date"`
+}
+
+// Advance returns the streak that results from completing a crossword at the
+// provided time.  Completing more than one crossword on the same day only
+// counts once, and when a day has been missed the streak starts over.
+func (s Streak) Advance(now time.Time) Streak {
+	today := day(now)
+
+	switch {
+	case s.Count > 0 && s.LastSolveDate.Equal(today):
+		return s
+
+	case s.Count > 0 && s.LastSolveDate.Equal(today.AddDate(0, 0, -1)):
+		s.Count++
+
+	default:
+		s.Count = 1
+	}
+
+	s.LastSolveDate = today
+	if s.Count > s.Best {
+		s.Best = s.Count
+	}
+
+	return s
+}
+
+// Current returns the streak as of the provided time.  If a day has been missed
+// since the channel last completed a crossword then the streak is broken and
+// its count is reported as zero.
+func (s Streak) Current(now time.Time) Streak {
+	if s.LastSolveDate.Before(day(now).AddDate(0, 0, -1)) {
+		s.Count = 0
+	}
+
+	return s
+}
+
+// day truncates a time to the start of its calendar day in UTC.
+func day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// StreakKey returns the key that should be used in redis to store a particular
+// channel's solve streak.
+func StreakKey(name string) string {
+	return fmt.Sprintf("%s:crossword:streak", name)
+}
+
+// GetStreak loads the solve streak for a channel from redis.  If the channel
+// has never completed a crossword then the zero value is returned.  Unlike the
+// channel's state the streak doesn't expire.
+func GetStreak(conn db.Connection, channel string) (Streak, error) {
+	var streak Streak
+	err := db.Get(conn, StreakKey(channel), &streak)
+	return streak, err
+}
+
+// SetStreak writes the solve streak for a channel to redis.
+func SetStreak(conn db.Connection, channel string, streak Streak) error {
+	return db.Set(conn, StreakKey(channel), streak)
+}
+
+// UpdateStreak advances a channel's solve streak to account for a crossword
+// that was completed at the provided time and returns the updated streak.
+func UpdateStreak(conn db.Connection, channel string, now time.Time) (Streak, error) {
+	streak, err := GetStreak(conn, channel)
+	if err != nil {
+		return streak, err
+	}
+
+	streak = streak.Advance(now)
+	return streak, SetStreak(conn, channel, streak)
+}
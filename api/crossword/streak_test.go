@@ -0,0 +1,91 @@
+package crossword
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreak_Advance(t *testing.T) {
+	monday := time.Date(2024, time.March, 11, 18, 30, 0, 0, time.UTC)
+	tuesday := monday.AddDate(0, 0, 1)
+	wednesday := monday.AddDate(0, 0, 2)
+	friday := monday.AddDate(0, 0, 4)
+	date := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	// The first completion starts a streak.
+	var streak Streak
+	streak = streak.Advance(monday)
+	assert.Equal(t, Streak{Count: 1, Best: 1, LastSolveDate: date(monday)}, streak)
+
+	// Completing another puzzle the same day doesn't extend the streak.
+	streak = streak.Advance(monday.Add(2 * time.Hour))
+	assert.Equal(t, Streak{Count: 1, Best: 1, LastSolveDate: date(monday)}, streak)
+
+	// Completions on consecutive days extend the streak.
+	streak = streak.Advance(tuesday)
+	assert.Equal(t, Streak{Count: 2, Best: 2, LastSolveDate: date(tuesday)}, streak)
+
+	streak = streak.Advance(wednesday)
+	assert.Equal(t, Streak{Count: 3, Best: 3, LastSolveDate: date(wednesday)}, streak)
+
+	// Missing a day starts the streak over, but the best streak is kept.
+	streak = streak.Advance(friday)
+	assert.Equal(t, Streak{Count: 1, Best: 3, LastSolveDate: date(friday)}, streak)
+}
+
+func TestStreak_Advance_TimeZone(t *testing.T) {
+	// Days are determined in UTC regardless of the time zone of the completion.
+	est := time.FixedZone("EST", -5*60*60)
+	first := time.Date(2024, time.March, 11, 22, 0, 0, 0, est) // 2024-03-12 in UTC
+	second := time.Date(2024, time.March, 12, 8, 0, 0, 0, est) // 2024-03-12 in UTC
+
+	streak := Streak{}.Advance(first).Advance(second)
+	assert.Equal(t, 1, streak.Count)
+	assert.Equal(t, time.Date(2024, time.March, 12, 0, 0, 0, 0, time.UTC), streak.LastSolveDate)
+}
+
+func TestStreak_Current(t *testing.T) {
+	last := time.Date(2024, time.March, 11, 0, 0, 0, 0, time.UTC)
+	streak := Streak{Count: 4, Best: 6, LastSolveDate: last}
+
+	// The streak is intact on the day of the last completion and the day after.
+	assert.Equal(t, 4, streak.Current(last.Add(20*time.Hour)).Count)
+	assert.Equal(t, 4, streak.Current(last.AddDate(0, 0, 1).Add(23*time.Hour)).Count)
+
+	// Once a day has been missed the streak is broken.
+	current := streak.Current(last.AddDate(0, 0, 2))
+	assert.Equal(t, 0, current.Count)
+	assert.Equal(t, 6, current.Best)
+}
+
+func TestUpdateStreak(t *testing.T) {
+	_, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	// A channel that has never completed a crossword doesn't have a streak.
+	streak, err := GetStreak(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, Streak{}, streak)
+
+	monday := time.Date(2024, time.March, 11, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		_, err = UpdateStreak(conn, Channel.name, monday.AddDate(0, 0, i))
+		require.NoError(t, err)
+	}
+
+	streak, err = GetStreak(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, 3, streak.Count)
+	assert.Equal(t, 3, streak.Best)
+
+	// Skipping a day resets the streak.
+	streak, err = UpdateStreak(conn, Channel.name, monday.AddDate(0, 0, 5))
+	require.NoError(t, err)
+	assert.Equal(t, 1, streak.Count)
+	assert.Equal(t, 3, streak.Best)
+}
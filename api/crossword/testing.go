@@ -3,6 +3,7 @@ package crossword
 import (
 	"encoding/json"
 	"github.com/alicebob/miniredis"
+	"github.com/bbeck/puzzles-with-chat/api/admin"
 	"github.com/bbeck/puzzles-with-chat/api/model"
 	"github.com/bbeck/puzzles-with-chat/api/pubsub"
 	"github.com/go-chi/chi"
@@ -10,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -26,6 +28,11 @@ var testPuzzle *Puzzle = nil
 // error to be returned instead of a network call.
 var testPuzzleLoadError error = nil
 
+// The dates to use instead of fetching the dates that New York Times cryptics
+// are available for.  This is used by test cases to ensure that no network
+// calls are made when determining available dates.
+var testAvailableCrypticDates []time.Time = nil
+
 // A cached error to use instead of reading state from the database.
 var testSettingsLoadError error = nil
 
@@ -66,13 +73,25 @@ func LoadTestPuzzle(t *testing.T, filename string) *Puzzle {
 	var puzzle *Puzzle
 	var err error
 	switch {
+	case strings.HasPrefix(filename, "xwordinfo-cryptic-"):
+		puzzle, err = ParseXWordInfoCrypticResponse(in)
+
 	case strings.HasPrefix(filename, "xwordinfo-"):
 		puzzle, err = ParseXWordInfoResponse(in)
 
+	case strings.HasPrefix(filename, "nyt-mini-"):
+		puzzle, err = ParseNYTMiniResponse(in)
+
+	case strings.HasPrefix(filename, "atlantic-"):
+		puzzle, err = ParseAtlanticResponse(in)
+
 	case strings.HasPrefix(filename, "puzzle-"):
 		puzzle = new(Puzzle)
 		err = json.NewDecoder(in).Decode(puzzle)
 
+	case strings.HasSuffix(filename, ".puz"):
+		puzzle, err = LoadPuzFile(in)
+
 	default:
 		assert.Failf(t, "unrecognized filename prefix", "filename: %s", filename)
 	}
@@ -87,7 +106,11 @@ func ForcePuzzleToBeLoaded(t *testing.T, filename string) {
 	t.Helper()
 
 	testPuzzle = LoadTestPuzzle(t, filename)
-	t.Cleanup(func() { testPuzzle = nil })
+	ClearPuzzleCache()
+	t.Cleanup(func() {
+		testPuzzle = nil
+		ClearPuzzleCache()
+	})
 }
 
 // ForceErrorDuringLoad sets up an error to be returned when an attempt is made
@@ -96,7 +119,65 @@ func ForceErrorDuringPuzzleLoad(t *testing.T, err error) {
 	t.Helper()
 
 	testPuzzleLoadError = err
-	t.Cleanup(func() { testPuzzleLoadError = nil })
+	ClearPuzzleCache()
+	t.Cleanup(func() {
+		testPuzzleLoadError = nil
+		ClearPuzzleCache()
+	})
+}
+
+// ForcePuzzleLoader sets up a loader to use instead of the default one when
+// loading puzzles from a source through the puzzle cache.  The cache is
+// emptied before the loader is installed and again after the test finishes.
+func ForcePuzzleLoader(t *testing.T, source string, loader func(string) (*Puzzle, error)) {
+	t.Helper()
+
+	original := PuzzleLoaders[source]
+	PuzzleLoaders[source] = loader
+	ClearPuzzleCache()
+	t.Cleanup(func() {
+		PuzzleLoaders[source] = original
+		ClearPuzzleCache()
+	})
+}
+
+// ForceSourceLoader sets up a function to use instead of the default one when
+// loading puzzles from one of the sources that a puzzle can be selected from.
+func ForceSourceLoader(t *testing.T, name string, loader func(string) (*Puzzle, error)) {
+	t.Helper()
+
+	for i := range Sources {
+		if Sources[i].Name != name {
+			continue
+		}
+
+		original := Sources[i].Load
+		Sources[i].Load = loader
+		t.Cleanup(func() { Sources[i].Load = original })
+		return
+	}
+
+	require.Failf(t, "unrecognized source", "source: %s", name)
+}
+
+// ForceArchiveResolver sets up a resolver to use instead of the default one
+// when resolving community archive ids.
+func ForceArchiveResolver(t *testing.T, resolver func(string) (string, error)) {
+	t.Helper()
+
+	original := ArchiveResolver
+	ArchiveResolver = resolver
+	t.Cleanup(func() { ArchiveResolver = original })
+}
+
+// ForceAvailableCrypticDates sets up the dates that New York Times cryptics are
+// available for instead of fetching them.
+func ForceAvailableCrypticDates(t *testing.T, dates []time.Time) {
+	t.Helper()
+
+	previous := testAvailableCrypticDates
+	testAvailableCrypticDates = dates
+	t.Cleanup(func() { testAvailableCrypticDates = previous })
 }
 
 // ForceErrorDuringSettingsLoad sets up an error to be returned when an attempt
@@ -117,6 +198,30 @@ func ForceErrorDuringSettingsSave(t *testing.T, err error) {
 	t.Cleanup(func() { testSettingsSaveError = nil })
 }
 
+// ForceAdminToken configures the token required by administrative endpoints
+// for the duration of a test.
+func ForceAdminToken(t *testing.T, token string) {
+	t.Helper()
+
+	previous := admin.Token
+	admin.Token = token
+	t.Cleanup(func() { admin.Token = previous })
+}
+
+// AllowInternalWebhookAddresses allows completion webhooks to connect to
+// internal addresses for the duration of a test, so that they can call test
+// servers listening on the loopback address.
+func AllowInternalWebhookAddresses(t *testing.T) {
+	t.Helper()
+
+	previous := WebhookHTTPClient
+	WebhookHTTPClient = &http.Client{
+		Timeout:       previous.Timeout,
+		CheckRedirect: previous.CheckRedirect,
+	}
+	t.Cleanup(func() { WebhookHTTPClient = previous })
+}
+
 // ForceErrorDuringStateLoad sets up an error to be returned when an attempt
 // is made to load state.
 func ForceErrorDuringStateLoad(t *testing.T, err error) {
@@ -155,6 +260,21 @@ func NewTestRouter(t *testing.T) (chi.Router, *redis.Pool, *pubsub.Registry) {
 	// Create the pubsub registry.
 	registry := new(pubsub.Registry)
 
+	// Don't make streams wait long when retrying their initial events.
+	retryDelay := pubsub.InitialEventsRetryDelay
+	recoveryInterval := pubsub.InitialEventsRecoveryInterval
+	pubsub.InitialEventsRetryDelay = time.Millisecond
+	pubsub.InitialEventsRecoveryInterval = 10 * time.Millisecond
+	t.Cleanup(func() {
+		pubsub.InitialEventsRetryDelay = retryDelay
+		pubsub.InitialEventsRecoveryInterval = recoveryInterval
+	})
+
+	// Never fetch the dates that cryptics are available for.
+	if testAvailableCrypticDates == nil {
+		ForceAvailableCrypticDates(t, []time.Time{})
+	}
+
 	// Setup the chi router and wire it up to the redis pool and pubsub registry.
 	router := chi.NewRouter()
 	RegisterRoutes(router, pool, registry)
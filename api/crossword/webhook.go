@@ -0,0 +1,208 @@
+package crossword
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/db"
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"github.com/gomodule/redigo/redis"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// CompletionPayload is the JSON body that's sent to a channel's completion
+// webhook when its crossword is completed.
+type CompletionPayload struct {
+	// The name of the channel that completed the crossword.
+	Channel string `json:"channel"`
+
+	// The title of the crossword that was completed.
+	Title string `json:"title"`
+
+	// How long it took to solve the crossword.
+	Duration model.Duration `json:"duration"`
+}
+
+// ErrInternalWebhookAddress is returned when a completion webhook refers to an
+// address that's internal to the server's network, such as a loopback or
+// private address.  Webhooks are provided by channels, so they must not be
+// able to make the server send requests to its own network.
+var ErrInternalWebhookAddress = errors.New("webhook address is internal")
+
+// The HTTP client to use when calling a completion webhook.  The timeout keeps
+// a slow webhook from tying up resources.  Connections to internal addresses
+// are refused, even when a public host name resolves to one, and redirects are
+// never followed since they could point anywhere.
+var WebhookHTTPClient = &http.Client{
+	Timeout: 3 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 3 * time.Second,
+			Control: refuseInternalAddress,
+		}).DialContext,
+	},
+	CheckRedirect: refuseRedirect,
+}
+
+// refuseInternalAddress is a dialer control function that refuses to connect to
+// an address that's internal to the server's network.
+func refuseInternalAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || isInternalIP(ip) {
+		return fmt.Errorf("%w: %s", ErrInternalWebhookAddress, address)
+	}
+
+	return nil
+}
+
+// refuseRedirect keeps an HTTP client from following redirects, the redirect
+// response is returned instead.
+func refuseRedirect(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+// internalNetworks are the address ranges, beyond loopback and link-local
+// addresses, that are internal to a network.
+var internalNetworks = []*net.IPNet{
+	mustParseCIDR("10.0.0.0/8"),
+	mustParseCIDR("172.16.0.0/12"),
+	mustParseCIDR("192.168.0.0/16"),
+	mustParseCIDR("100.64.0.0/10"),
+	mustParseCIDR("fc00::/7"),
+}
+
+func mustParseCIDR(s string) *net.IPNet {
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// isInternalIP returns whether or not an IP address is a loopback, private,
+// link-local or unspecified address.
+func isInternalIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return true
+	}
+
+	for _, network := range internalNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebhookAttempts is the number of times a completion webhook is called before
+// giving up on it.
+var WebhookAttempts = 3
+
+// WebhookRetryDelay is the amount of time to wait between attempts to call a
+// completion webhook.
+var WebhookRetryDelay = time.Second
+
+// ValidateWebhookURL ensures that a webhook URL is an absolute HTTP or HTTPS
+// URL whose host isn't internal to the server's network.  Host names that
+// resolve to internal addresses are refused when the webhook is called.
+func ValidateWebhookURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported webhook scheme: %s", u.Scheme)
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("missing webhook host: %s", s)
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrInternalWebhookAddress, host)
+	}
+	if ip := net.ParseIP(host); ip != nil && isInternalIP(ip) {
+		return fmt.Errorf("%w: %s", ErrInternalWebhookAddress, host)
+	}
+
+	return nil
+}
+
+// CompletionWebhookKey returns the key that should be used in redis to store a
+// particular channel's completion webhook.  The webhook is kept apart from the
+// rest of the channel's settings because settings are sent to every viewer
+// while the webhook is private to the channel.
+func CompletionWebhookKey(name string) string {
+	return fmt.Sprintf("%s:crossword:completion_webhook", name)
+}
+
+// GetCompletionWebhook loads the completion webhook URL of a channel from
+// redis.  If the channel doesn't have a webhook then the empty string is
+// returned.
+func GetCompletionWebhook(conn db.Connection, channel string) (string, error) {
+	webhook, err := redis.String(conn.Do("GET", CompletionWebhookKey(channel)))
+	if err == redis.ErrNil {
+		return "", nil
+	}
+
+	return webhook, err
+}
+
+// SetCompletionWebhook writes the completion webhook URL of a channel to redis.
+// An empty URL removes the channel's webhook.
+func SetCompletionWebhook(conn db.Connection, channel string, webhook string) error {
+	if webhook == "" {
+		_, err := conn.Do("DEL", CompletionWebhookKey(channel))
+		return err
+	}
+
+	_, err := conn.Do("SET", CompletionWebhookKey(channel), webhook)
+	return err
+}
+
+// SendCompletionWebhook POSTs the payload to the webhook URL.  Calling the
+// webhook is best effort, when it fails it's retried up to WebhookAttempts
+// times before the last error is returned.
+func SendCompletionWebhook(url string, payload CompletionPayload) error {
+	bs, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = postWebhook(url, bs)
+		if err == nil || attempt >= WebhookAttempts {
+			return err
+		}
+
+		time.Sleep(WebhookRetryDelay)
+	}
+}
+
+func postWebhook(url string, bs []byte) error {
+	response, err := WebhookHTTPClient.Post(url, "application/json", bytes.NewReader(bs))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("received %d response from webhook", response.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package crossword
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSendCompletionWebhook(t *testing.T) {
+	AllowInternalWebhookAddresses(t)
+
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+	}))
+	defer server.Close()
+
+	payload := CompletionPayload{
+		Channel:  "channel",
+		Title:    "title",
+		Duration: model.Duration{Duration: 90 * time.Second},
+	}
+	require.NoError(t, SendCompletionWebhook(server.URL, payload))
+
+	expected := map[string]interface{}{
+		"channel":  "channel",
+		"title":    "title",
+		"duration": "1m30s",
+	}
+	assert.Equal(t, expected, body)
+}
+
+func TestSendCompletionWebhook_Retry(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int32 // the number of requests that fail before succeeding
+		attempts  int32 // the number of requests expected
+		succeeded bool
+	}{
+		{
+			name:      "first attempt succeeds",
+			failures:  0,
+			attempts:  1,
+			succeeded: true,
+		},
+		{
+			name:      "retry succeeds",
+			failures:  2,
+			attempts:  3,
+			succeeded: true,
+		},
+		{
+			name:      "all attempts fail",
+			failures:  5,
+			attempts:  3,
+			succeeded: false,
+		},
+	}
+
+	defer func(delay time.Duration) { WebhookRetryDelay = delay }(WebhookRetryDelay)
+	WebhookRetryDelay = time.Millisecond
+	AllowInternalWebhookAddresses(t)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&attempts, 1) <= test.failures {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}))
+			defer server.Close()
+
+			err := SendCompletionWebhook(server.URL, CompletionPayload{})
+			assert.Equal(t, test.succeeded, err == nil)
+			assert.Equal(t, test.attempts, atomic.LoadInt32(&attempts))
+		})
+	}
+}
+
+func TestSendCompletionWebhook_DoesNotFollowRedirects(t *testing.T) {
+	AllowInternalWebhookAddresses(t)
+
+	defer func(attempts int) { WebhookAttempts = attempts }(WebhookAttempts)
+	WebhookAttempts = 1
+
+	var redirected int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&redirected, 1)
+	}))
+	defer target.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	assert.Error(t, SendCompletionWebhook(server.URL, CompletionPayload{}))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&redirected))
+}
+
+func TestSendCompletionWebhook_RefusesInternalAddresses(t *testing.T) {
+	defer func(attempts int) { WebhookAttempts = attempts }(WebhookAttempts)
+	WebhookAttempts = 1
+
+	var called int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&called, 1)
+	}))
+	defer server.Close()
+
+	err := SendCompletionWebhook(server.URL, CompletionPayload{})
+	assert.True(t, errors.Is(err, ErrInternalWebhookAddress))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&called))
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	assert.NoError(t, ValidateWebhookURL("https://example.com/hook?scene=done"))
+	assert.NoError(t, ValidateWebhookURL("http://8.8.8.8:8080/solved"))
+	assert.Error(t, ValidateWebhookURL("ftp://example.com/hook"))
+	assert.Error(t, ValidateWebhookURL("/hook"))
+	assert.Error(t, ValidateWebhookURL("http:///hook"))
+
+	for _, internal := range []string{
+		"http://localhost:8080/solved",
+		"http://LOCALHOST./solved",
+		"http://api.localhost/solved",
+		"http://127.0.0.1/solved",
+		"http://0.0.0.0/solved",
+		"http://10.1.2.3/solved",
+		"http://172.16.0.1/solved",
+		"http://192.168.1.1/solved",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/solved",
+		"http://[fe80::1]/solved",
+		"http://[fd00::1]/solved",
+	} {
+		err := ValidateWebhookURL(internal)
+		assert.True(t, errors.Is(err, ErrInternalWebhookAddress), internal)
+	}
+}
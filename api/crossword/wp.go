@@ -0,0 +1,60 @@
+package crossword
+
+import (
+	"fmt"
+	"time"
+)
+
+// LoadFromWashingtonPost loads the crossword puzzle from the Washington Post
+// for a particular date.
+//
+// Like the Wall Street Journal this method downloads a .puz file from the
+// herbach.dnsalias.com site and loads it into a Puzzle object.
+//
+// If the puzzle cannot be loaded or parsed then an error is returned.
+func LoadFromWashingtonPost(date string) (*Puzzle, error) {
+	published, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		err = fmt.Errorf("unable to parse date %s: %+v", date, err)
+		return nil, err
+	}
+
+	// Download the .puz file from the herbach.dnsalias.com site.
+	puzzle, err := LoadFromPuzFileURLWithHeaders(WashingtonPostURL(published), HerbachHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	puzzle.Description = fmt.Sprintf("Washington Post puzzle from %s", published.Format("2006-01-02"))
+
+	// Normally .puz files don't have puzzle dates recorded in them, but we
+	// happen to know the date for this puzzle, so fill it in.
+	puzzle.PublishedDate = published
+	puzzle.Publisher = "The Washington Post"
+
+	return puzzle, nil
+}
+
+// WashingtonPostURL returns the URL of the .puz file for the Washington Post
+// crossword published on a particular date.
+func WashingtonPostURL(published time.Time) string {
+	return fmt.Sprintf("http://herbach.dnsalias.com/WaPo/wp%02d%02d%02d.puz", published.Year()%100, published.Month(), published.Day())
+}
+
+// WPFirstPuzzleDate is the date of the first Washington Post crossword that's
+// available as a .puz file.
+var WPFirstPuzzleDate = time.Date(2005, time.January, 2, 0, 0, 0, 0, time.UTC)
+
+// LoadAvailableWPDates calculates the set of available dates for crossword
+// puzzles from The Washington Post.  A puzzle is published every day of the
+// week.
+func LoadAvailableWPDates() []time.Time {
+	now := time.Now().UTC()
+
+	var dates []time.Time
+	for date := WPFirstPuzzleDate; date.Before(now) || date.Equal(now); date = date.AddDate(0, 0, 1) {
+		dates = append(dates, date)
+	}
+
+	return dates
+}
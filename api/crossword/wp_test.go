@@ -0,0 +1,70 @@
+package crossword
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestLoadFromWashingtonPost(t *testing.T) {
+	ForcePuzzleToBeLoaded(t, "puzzle-wp-20051206.json")
+
+	puzzle, err := LoadFromWashingtonPost("2005-12-06")
+	require.NoError(t, err)
+	assert.Equal(t, "Washington Post puzzle from 2005-12-06", puzzle.Description)
+	assert.Equal(t, "The Washington Post", puzzle.Publisher)
+	assert.Equal(t, time.Date(2005, time.December, 6, 0, 0, 0, 0, time.UTC), puzzle.PublishedDate)
+	assert.Equal(t, "Raymond Hamel", puzzle.Author)
+	assert.Equal(t, 15, puzzle.Rows)
+	assert.Equal(t, 15, puzzle.Cols)
+}
+
+func TestLoadFromWashingtonPost_Error(t *testing.T) {
+	_, err := LoadFromWashingtonPost("not a date")
+	assert.Error(t, err)
+}
+
+func TestLoadAvailableWPDates(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected time.Time
+	}{
+		{
+			name:     "first puzzle date",
+			expected: WPFirstPuzzleDate,
+		},
+		{
+			name:     "2005-12-06",
+			expected: time.Date(2005, time.December, 6, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "2019-01-06",
+			expected: time.Date(2019, time.January, 6, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "2020-01-05",
+			expected: time.Date(2020, time.January, 5, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	// A puzzle is available for every day.
+	dates := LoadAvailableWPDates()
+	for i := 1; i < len(dates); i++ {
+		require.Equal(t, dates[i-1].AddDate(0, 0, 1), dates[i])
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.True(t, sort.SliceIsSorted(dates, func(i, j int) bool {
+				return dates[i].Before(dates[j])
+			}))
+
+			index := sort.Search(len(dates), func(i int) bool {
+				return dates[i].Equal(test.expected) || dates[i].After(test.expected)
+			})
+			assert.Equal(t, test.expected, dates[index])
+		})
+	}
+}
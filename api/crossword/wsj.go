@@ -5,6 +5,12 @@ import (
 	"time"
 )
 
+// HerbachHeaders are the headers sent when downloading puzzles from the
+// herbach.dnsalias.com site.
+var HerbachHeaders = map[string]string{
+	"Referer": "http://herbach.dnsalias.com/",
+}
+
 // LoadFromWallStreetJournal loads a crossword puzzle from the Wall Street
 // Journal for a particular date.
 //
@@ -22,8 +28,7 @@ func LoadFromWallStreetJournal(date string) (*Puzzle, error) {
 	}
 
 	// Download the .puz file from the herbach.dnsalias.com site.
-	url := fmt.Sprintf("http://herbach.dnsalias.com/wsj/wsj%02d%02d%02d.puz", published.Year()-2000, published.Month(), published.Day())
-	puzzle, err := LoadFromPuzFileURL(url)
+	puzzle, err := LoadFromPuzFileURLWithHeaders(WallStreetJournalURL(published), HerbachHeaders)
 	if err != nil {
 		return nil, err
 	}
@@ -38,6 +43,12 @@ func LoadFromWallStreetJournal(date string) (*Puzzle, error) {
 	return puzzle, nil
 }
 
+// WallStreetJournalURL returns the URL of the .puz file for the Wall Street
+// Journal crossword published on a particular date.
+func WallStreetJournalURL(published time.Time) string {
+	return fmt.Sprintf("http://herbach.dnsalias.com/wsj/wsj%02d%02d%02d.puz", published.Year()-2000, published.Month(), published.Day())
+}
+
 // LoadAvailableWSJDates calculates the set of available dates for crossword
 // puzzles from The Wall Street Journal.
 func LoadAvailableWSJDates() []time.Time {
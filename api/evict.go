@@ -0,0 +1,132 @@
+package main
+
+import (
+	"github.com/bbeck/puzzles-with-chat/api/acrostic"
+	"github.com/bbeck/puzzles-with-chat/api/crossword"
+	"github.com/bbeck/puzzles-with-chat/api/db"
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"github.com/bbeck/puzzles-with-chat/api/pubsub"
+	"github.com/bbeck/puzzles-with-chat/api/spellingbee"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/gomodule/redigo/redis"
+	"log"
+	"net/http"
+)
+
+// PuzzleTypes are the types of puzzles that a channel can have data for.
+var PuzzleTypes = []string{"acrostic", "crossword", "spellingbee"}
+
+// GetChannelKeys returns the database keys that hold any data for a channel
+// across all puzzle types.
+func GetChannelKeys(pool *redis.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+		if !ChannelNameRegexp.MatchString(channel) {
+			log.Printf("invalid channel name: %s", channel)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		keys, err := FindChannelKeys(conn, channel)
+		if err != nil {
+			log.Printf("unable to load keys for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		render.JSON(w, r, keys)
+	}
+}
+
+// EvictChannel removes all of the data for a channel across all puzzle types.
+// This includes the channel's settings, solves, stats and streaks, its entries
+// in the sets of recently active channels and recent completions, as well as
+// the events that were recently published to it.  The keys that were removed
+// are returned.
+func EvictChannel(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+		if !ChannelNameRegexp.MatchString(channel) {
+			log.Printf("invalid channel name: %s", channel)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		keys, err := DeleteChannelData(conn, channel)
+		if err != nil {
+			log.Printf("unable to evict channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if len(keys) == 0 {
+			log.Printf("unable to evict channel %s: %+v", channel, ErrNoChannelData)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		for _, id := range ChannelIDs(channel) {
+			registry.ClearHistory(id)
+		}
+
+		log.Printf("evicted channel %s, keys: %v", channel, keys)
+		render.JSON(w, r, keys)
+	}
+}
+
+// FindChannelKeys returns the database keys that hold data for a channel.
+// Every key belonging to a channel is prefixed with the channel's name, so the
+// channel's name must not contain any wildcard characters.
+func FindChannelKeys(conn db.Connection, channel string) ([]string, error) {
+	keys, err := db.ScanKeys(conn, channel+":*")
+	if keys == nil {
+		keys = []string{}
+	}
+
+	return keys, err
+}
+
+// DeleteChannelData removes all of the database keys that hold data for a
+// channel along with the channel's entries in the sets of recently active
+// channels and recent completions.  The keys that were removed are returned.
+func DeleteChannelData(conn db.Connection, channel string) ([]string, error) {
+	keys, err := FindChannelKeys(conn, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if _, err := conn.Do("DEL", key); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, kind := range PuzzleTypes {
+		if err := model.RemoveActivity(conn, kind, channel); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := model.RemoveCompletions(conn, channel); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// ChannelIDs returns the pubsub channels that events are published to for a
+// channel across all puzzle types.
+func ChannelIDs(channel string) []pubsub.Channel {
+	return []pubsub.Channel{
+		acrostic.ChannelID(channel),
+		crossword.ChannelID(channel),
+		spellingbee.ChannelID(channel),
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/bbeck/puzzles-with-chat/api/acrostic"
+	"github.com/bbeck/puzzles-with-chat/api/admin"
+	"github.com/bbeck/puzzles-with-chat/api/crossword"
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"github.com/bbeck/puzzles-with-chat/api/pubsub"
+	"github.com/bbeck/puzzles-with-chat/api/spellingbee"
+	"github.com/go-chi/chi"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRoute_EvictChannel(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	ForceAdminToken(t, "secret")
+
+	// Seed every kind of data that a channel can have, and some for a second
+	// channel that shouldn't be touched.
+	now := time.Now()
+	for _, channel := range []string{"channel", "other"} {
+		state := crossword.NewState(t, "xwordinfo-nyt-20181231.json")
+		require.NoError(t, crossword.SetState(conn, channel, state))
+		require.NoError(t, crossword.SetSettings(conn, channel, crossword.Settings{OnlyAllowCorrectAnswers: true}))
+		require.NoError(t, crossword.RecordSolve(conn, channel, crossword.NewSolveRecord(state, now)))
+		require.NoError(t, crossword.SetStreak(conn, channel, crossword.Streak{}.Advance(now)))
+		require.NoError(t, crossword.RecordAuditEntry(conn, channel, crossword.AuditEntry{}))
+		require.NoError(t, acrostic.SetSettings(conn, channel, acrostic.Settings{}))
+		require.NoError(t, spellingbee.SetState(conn, channel, spellingbee.NewState(t, "nytbee-20180729.json")))
+		require.NoError(t, model.RecordCompletion(conn, model.Completion{Type: "crossword", Channel: channel, Time: now}))
+		registry.Publish(crossword.ChannelID(channel), pubsub.Event{Kind: "state"})
+	}
+
+	expected := []string{
+		acrostic.SettingsKey("channel"),
+		crossword.AuditLogKey("channel"),
+		crossword.SettingsKey("channel"),
+		crossword.StateKey("channel"),
+		crossword.StatsKey("channel"),
+		crossword.StreakKey("channel"),
+		spellingbee.StateKey("channel"),
+	}
+
+	// Listing the keys finds all of them.
+	response := AdminRequest(http.MethodGet, "/admin/channel/channel/keys", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.ElementsMatch(t, expected, ParseKeys(t, response))
+
+	// Evicting the channel removes all of them.
+	response = AdminRequest(http.MethodDelete, "/admin/channel/channel", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.ElementsMatch(t, expected, ParseKeys(t, response))
+
+	for _, key := range expected {
+		exists, err := redis.Bool(conn.Do("EXISTS", key))
+		require.NoError(t, err)
+		assert.False(t, exists, key)
+	}
+
+	// The channel is no longer considered active, but the other channel is.
+	activities, err := model.GetRecentActivity(conn)
+	require.NoError(t, err)
+	for _, activity := range activities {
+		assert.Equal(t, "other", activity.Name)
+	}
+	assert.NotEmpty(t, activities)
+
+	// Its completions and recently published events are gone too, but the other
+	// channel's remain.
+	completions, err := model.GetRecentCompletions(conn, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(completions))
+	assert.Equal(t, "other", completions[0].Channel)
+
+	events, _ := registry.EventsSince(crossword.ChannelID("channel"), 0)
+	assert.Empty(t, events)
+	events, _ = registry.EventsSince(crossword.ChannelID("other"), 0)
+	assert.Equal(t, 1, len(events))
+
+	// The other channel still has all of its data.
+	response = AdminRequest(http.MethodGet, "/admin/channel/other/keys", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, len(expected), len(ParseKeys(t, response)))
+
+	// Once evicted there's nothing left to list or evict.
+	response = AdminRequest(http.MethodGet, "/admin/channel/channel/keys", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Empty(t, ParseKeys(t, response))
+
+	response = AdminRequest(http.MethodDelete, "/admin/channel/channel", router)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestRoute_EvictChannel_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		method   string
+		url      string
+		expected int
+	}{
+		{
+			name:     "list without token",
+			method:   http.MethodGet,
+			url:      "/admin/channel/channel/keys",
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "evict without token",
+			method:   http.MethodDelete,
+			url:      "/admin/channel/channel",
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "list invalid channel name",
+			token:    "secret",
+			method:   http.MethodGet,
+			url:      "/admin/channel/a*/keys",
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "evict invalid channel name",
+			token:    "secret",
+			method:   http.MethodDelete,
+			url:      "/admin/channel/a*",
+			expected: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, _, _ := NewTestRouter(t)
+			ForceAdminToken(t, "secret")
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(test.method, test.url, nil)
+			if test.token != "" {
+				request.Header.Set("Authorization", "Bearer "+test.token)
+			}
+			router.ServeHTTP(recorder, request)
+			assert.Equal(t, test.expected, recorder.Code)
+		})
+	}
+}
+
+// ForceAdminToken configures the token required by administrative endpoints
+// for the duration of a test.
+func ForceAdminToken(t *testing.T, token string) {
+	t.Helper()
+
+	previous := admin.Token
+	admin.Token = token
+	t.Cleanup(func() { admin.Token = previous })
+}
+
+// AdminRequest performs a request to the router that includes the admin token.
+func AdminRequest(method, url string, router chi.Router) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, url, nil)
+	request.Header.Set("Authorization", "Bearer "+admin.Token)
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func ParseKeys(t *testing.T, response *httptest.ResponseRecorder) []string {
+	t.Helper()
+
+	var keys []string
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&keys))
+	return keys
+}
@@ -1,16 +1,22 @@
 package main
 
 import (
+	"context"
 	"github.com/bbeck/puzzles-with-chat/api/acrostic"
+	"github.com/bbeck/puzzles-with-chat/api/admin"
 	"github.com/bbeck/puzzles-with-chat/api/crossword"
+	"github.com/bbeck/puzzles-with-chat/api/model"
 	"github.com/bbeck/puzzles-with-chat/api/pubsub"
 	"github.com/bbeck/puzzles-with-chat/api/spellingbee"
+	"github.com/bbeck/puzzles-with-chat/api/web"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	"github.com/gomodule/redigo/redis"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -20,6 +26,160 @@ func main() {
 
 	registry := new(pubsub.Registry)
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Discard the event histories of channels that nobody is polling anymore.
+	registry.StartHistoryPruner(ctx)
+
+	// Record and replay events when configured to do so.
+	StartEventRecording(ctx, registry)
+
+	// Pre-load upcoming crossword puzzles from the configured sources (comma
+	// separated) so that selecting them is instant.
+	if sources := os.Getenv("CROSSWORD_CACHE_WARM_SOURCES"); sources != "" {
+		var names []string
+		for _, name := range strings.Split(sources, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+		crossword.StartPuzzleCacheWarmer(ctx, names)
+	}
+
+	// Reveal the quotes of acrostics whose delayed reveal was missed, for example
+	// because the server restarted before it happened.
+	acrostic.StartCompletionRevealer(ctx, pool, registry)
+
+	// Advance channels to their next crossword puzzle when the advance was
+	// missed, for example because the server restarted before it happened.
+	crossword.StartPuzzleAdvancer(ctx, pool, registry)
+
+	// Abandon crossword puzzles that were selected but never started once
+	// they've been idle for too long when configured to do so (e.g. "2h").
+	if idle := os.Getenv("CROSSWORD_IDLE_ABANDON_AFTER"); idle != "" {
+		duration, err := time.ParseDuration(idle)
+		if err != nil || duration <= 0 {
+			log.Fatalf("invalid CROSSWORD_IDLE_ABANDON_AFTER %s: %+v", idle, err)
+		}
+		crossword.StartIdlePuzzleAbandoner(ctx, pool, registry, duration)
+	}
+
+	// Close event streams that have been idle for too long when configured to
+	// do so (e.g. "2h").
+	if timeout := os.Getenv("SSE_IDLE_TIMEOUT"); timeout != "" {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil {
+			log.Fatalf("invalid SSE_IDLE_TIMEOUT %s: %+v", timeout, err)
+		}
+		pubsub.IdleTimeout = duration
+	}
+
+	// Limit the number of clients that can stream the events of a single channel
+	// when configured to do so.
+	if max := os.Getenv("SSE_MAX_SUBSCRIBERS_PER_CHANNEL"); max != "" {
+		n, err := strconv.Atoi(max)
+		if err != nil || n < 0 {
+			log.Fatalf("invalid SSE_MAX_SUBSCRIBERS_PER_CHANNEL %s: %+v", max, err)
+		}
+		registry.MaxSubscribersPerChannel = n
+	}
+
+	// Identify ourselves differently when downloading puzzles when configured to
+	// do so.
+	if agent := os.Getenv("DOWNLOAD_USER_AGENT"); agent != "" {
+		web.UserAgent = agent
+	}
+
+	// Limit the number of puzzles that can be downloaded at once when configured
+	// to do so.
+	if max := os.Getenv("DOWNLOAD_MAX_CONCURRENCY"); max != "" {
+		n, err := strconv.Atoi(max)
+		if err != nil || n < 0 {
+			log.Fatalf("invalid DOWNLOAD_MAX_CONCURRENCY %s: %+v", max, err)
+		}
+		web.SetDownloadLimit(n)
+	}
+
+	// Space out successive downloads from the same host when configured to do
+	// so (e.g. "500ms").
+	if delay := os.Getenv("DOWNLOAD_HOST_DELAY"); delay != "" {
+		duration, err := time.ParseDuration(delay)
+		if err != nil || duration < 0 {
+			log.Fatalf("invalid DOWNLOAD_HOST_DELAY %s: %+v", delay, err)
+		}
+		web.DownloadHostDelay = duration
+	}
+
+	// Give up on downloads that have waited this long for another download to
+	// finish when configured to do so (e.g. "1m").
+	if timeout := os.Getenv("DOWNLOAD_WAIT_TIMEOUT"); timeout != "" {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil || duration <= 0 {
+			log.Fatalf("invalid DOWNLOAD_WAIT_TIMEOUT %s: %+v", timeout, err)
+		}
+		web.DownloadWaitTimeout = duration
+	}
+
+	// Limit how far back New York Times puzzles can be selected from when
+	// configured to do so (e.g. "2010-01-01").
+	if min := os.Getenv("NYT_MIN_DATE"); min != "" {
+		date, err := time.Parse("2006-01-02", min)
+		if err != nil {
+			log.Fatalf("invalid NYT_MIN_DATE %s: %+v", min, err)
+		}
+		crossword.NYTMinDate = date
+	}
+
+	// Limit how many of each channel's crossword solve records and audit log
+	// entries are retained when configured to do so.
+	if max := os.Getenv("CROSSWORD_STATS_MAX_ENTRIES"); max != "" {
+		n, err := strconv.Atoi(max)
+		if err != nil || n <= 0 {
+			log.Fatalf("invalid CROSSWORD_STATS_MAX_ENTRIES %s: %+v", max, err)
+		}
+		crossword.StatsMaxEntries = n
+	}
+
+	if max := os.Getenv("CROSSWORD_AUDIT_MAX_ENTRIES"); max != "" {
+		n, err := strconv.Atoi(max)
+		if err != nil || n <= 0 {
+			log.Fatalf("invalid CROSSWORD_AUDIT_MAX_ENTRIES %s: %+v", max, err)
+		}
+		crossword.AuditLogMaxEntries = n
+	}
+
+	// Discard solve records and audit log entries once they're too old when
+	// configured to do so (e.g. "8760h").
+	if age := os.Getenv("CROSSWORD_STATS_MAX_AGE"); age != "" {
+		duration, err := time.ParseDuration(age)
+		if err != nil || duration < 0 {
+			log.Fatalf("invalid CROSSWORD_STATS_MAX_AGE %s: %+v", age, err)
+		}
+		crossword.StatsMaxAge = duration
+	}
+
+	if age := os.Getenv("CROSSWORD_AUDIT_MAX_AGE"); age != "" {
+		duration, err := time.ParseDuration(age)
+		if err != nil || duration < 0 {
+			log.Fatalf("invalid CROSSWORD_AUDIT_MAX_AGE %s: %+v", age, err)
+		}
+		crossword.AuditLogMaxAge = duration
+	}
+
+	// Resume the timers of solves that were in progress when the server last
+	// stopped instead of pausing them when configured to do so (e.g. "false").
+	if pause := os.Getenv("PAUSE_STALE_SOLVES"); pause != "" {
+		b, err := strconv.ParseBool(pause)
+		if err != nil {
+			log.Fatalf("invalid PAUSE_STALE_SOLVES %s: %+v", pause, err)
+		}
+		model.PauseStaleSolves = b
+	}
+
+	// Administrative endpoints are only available when a token is configured.
+	admin.Token = os.Getenv("ADMIN_TOKEN")
+
 	r := chi.NewRouter()
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Logger)
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/db"
+	"github.com/gomodule/redigo/redis"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ActivityKey is the key of the sorted set in redis that tracks when each
+// channel last changed the state of one of its puzzles.  Members of the set are
+// of the form <puzzle type>:<channel> and are scored by the time of the change
+// in milliseconds since the epoch.
+const ActivityKey = "activity"
+
+// ActivityRetention is how long a channel remains in the activity set after its
+// last change.  This matches how long puzzle states are retained for.
+var ActivityRetention = 4 * time.Hour
+
+// Activity is a representation of the most recent change a channel made to the
+// state of a puzzle.  It can be marshalled to/from JSON.
+type Activity struct {
+	Type         string    `json:"type"`
+	Name         string    `json:"name"`
+	LastActivity time.Time `json:"last_activity"`
+}
+
+// RecordActivity notes that the provided channel changed the state of a puzzle
+// of the provided type at the provided time.  Entries that are older than
+// ActivityRetention are removed at the same time.
+func RecordActivity(conn db.Connection, kind, channel string, when time.Time) error {
+	member := fmt.Sprintf("%s:%s", kind, channel)
+	if _, err := conn.Do("ZADD", ActivityKey, toMillis(when), member); err != nil {
+		return err
+	}
+
+	cutoff := toMillis(when.Add(-ActivityRetention))
+	_, err := conn.Do("ZREMRANGEBYSCORE", ActivityKey, "-inf", fmt.Sprintf("(%d", cutoff))
+	return err
+}
+
+// RemoveActivity removes the provided channel's entry for a puzzle type from
+// the activity set.  This is used when a channel is no longer working on its
+// puzzle and shouldn't be considered active.
+func RemoveActivity(conn db.Connection, kind, channel string) error {
+	member := fmt.Sprintf("%s:%s", kind, channel)
+	_, err := conn.Do("ZREM", ActivityKey, member)
+	return err
+}
+
+// MoveActivity moves the provided channel's entry for a puzzle type in the
+// activity set to another channel, keeping the time of its last change.  This is
+// used when a channel changes its name.
+func MoveActivity(conn db.Connection, kind, from, to string) error {
+	score, err := redis.Int64(conn.Do("ZSCORE", ActivityKey, fmt.Sprintf("%s:%s", kind, from)))
+	if err == redis.ErrNil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := conn.Do("ZADD", ActivityKey, score, fmt.Sprintf("%s:%s", kind, to)); err != nil {
+		return err
+	}
+
+	return RemoveActivity(conn, kind, from)
+}
+
+// GetRecentActivity returns the channels that have recently changed the state
+// of a puzzle ordered from the most recent change to the least recent.
+func GetRecentActivity(conn db.Connection) ([]Activity, error) {
+	values, err := redis.Strings(conn.Do("ZREVRANGE", ActivityKey, 0, -1, "WITHSCORES"))
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := toMillis(time.Now().Add(-ActivityRetention))
+
+	activities := make([]Activity, 0, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		parts := strings.SplitN(values[i], ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("malformed activity entry: %s", values[i])
+		}
+
+		score, err := strconv.ParseFloat(values[i+1], 64)
+		if err != nil {
+			return nil, err
+		}
+		millis := int64(score)
+
+		// Entries are only pruned when activity is recorded, so skip any that have
+		// aged out since then.
+		if millis < cutoff {
+			continue
+		}
+
+		activities = append(activities, Activity{
+			Type:         parts[0],
+			Name:         parts[1],
+			LastActivity: time.Unix(0, millis*int64(time.Millisecond)).UTC(),
+		})
+	}
+
+	return activities, nil
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
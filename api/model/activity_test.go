@@ -0,0 +1,114 @@
+package model
+
+import (
+	"github.com/alicebob/miniredis"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRecordActivity(t *testing.T) {
+	conn := NewRedisConnection(t)
+	now := time.Now()
+
+	require.NoError(t, RecordActivity(conn, "crossword", "a", now.Add(-2*time.Minute)))
+	require.NoError(t, RecordActivity(conn, "spellingbee", "b", now.Add(-1*time.Minute)))
+	require.NoError(t, RecordActivity(conn, "acrostic", "c", now.Add(-3*time.Minute)))
+
+	activities, err := GetRecentActivity(conn)
+	require.NoError(t, err)
+	require.Len(t, activities, 3)
+	assert.Equal(t, Activity{Type: "spellingbee", Name: "b", LastActivity: truncate(now.Add(-1 * time.Minute))}, activities[0])
+	assert.Equal(t, Activity{Type: "crossword", Name: "a", LastActivity: truncate(now.Add(-2 * time.Minute))}, activities[1])
+	assert.Equal(t, Activity{Type: "acrostic", Name: "c", LastActivity: truncate(now.Add(-3 * time.Minute))}, activities[2])
+
+	// Recording activity for a channel again replaces its previous entry.
+	require.NoError(t, RecordActivity(conn, "acrostic", "c", now))
+
+	activities, err = GetRecentActivity(conn)
+	require.NoError(t, err)
+	require.Len(t, activities, 3)
+	assert.Equal(t, "c", activities[0].Name)
+}
+
+func TestRemoveActivity(t *testing.T) {
+	conn := NewRedisConnection(t)
+	now := time.Now()
+
+	require.NoError(t, RecordActivity(conn, "crossword", "a", now))
+	require.NoError(t, RecordActivity(conn, "spellingbee", "a", now))
+
+	require.NoError(t, RemoveActivity(conn, "crossword", "a"))
+
+	activities, err := GetRecentActivity(conn)
+	require.NoError(t, err)
+	require.Len(t, activities, 1)
+	assert.Equal(t, "spellingbee", activities[0].Type)
+
+	// Removing a channel that isn't present isn't an error.
+	require.NoError(t, RemoveActivity(conn, "crossword", "missing"))
+}
+
+func TestMoveActivity(t *testing.T) {
+	conn := NewRedisConnection(t)
+	now := time.Now().Add(-time.Minute)
+
+	require.NoError(t, RecordActivity(conn, "crossword", "a", now))
+	require.NoError(t, RecordActivity(conn, "spellingbee", "a", now))
+
+	require.NoError(t, MoveActivity(conn, "crossword", "a", "b"))
+
+	activities, err := GetRecentActivity(conn)
+	require.NoError(t, err)
+	require.Len(t, activities, 2)
+	for _, activity := range activities {
+		if activity.Type == "crossword" {
+			assert.Equal(t, "b", activity.Name)
+			assert.Equal(t, now.UnixNano()/int64(time.Millisecond), activity.LastActivity.UnixNano()/int64(time.Millisecond))
+		} else {
+			assert.Equal(t, "a", activity.Name)
+		}
+	}
+
+	// Moving a channel that isn't present isn't an error.
+	require.NoError(t, MoveActivity(conn, "crossword", "missing", "c"))
+}
+
+func TestRecordActivity_Retention(t *testing.T) {
+	conn := NewRedisConnection(t)
+	now := time.Now()
+
+	require.NoError(t, RecordActivity(conn, "crossword", "old", now.Add(-ActivityRetention-time.Minute)))
+
+	// The old entry hasn't been pruned yet, but shouldn't be returned.
+	activities, err := GetRecentActivity(conn)
+	require.NoError(t, err)
+	assert.Empty(t, activities)
+
+	// Recording new activity prunes the old entry.
+	require.NoError(t, RecordActivity(conn, "crossword", "new", now))
+
+	count, err := redis.Int(conn.Do("ZCARD", ActivityKey))
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func NewRedisConnection(t *testing.T) redis.Conn {
+	t.Helper()
+
+	server, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	conn, err := redis.Dial("tcp", server.Addr())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func truncate(t time.Time) time.Time {
+	return t.Truncate(time.Millisecond).UTC()
+}
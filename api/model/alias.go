@@ -0,0 +1,104 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultAnswerAliases contains the alternate ways of writing part of an answer
+// that are always accepted, mapped to the canonical way of writing them.
+var DefaultAnswerAliases = map[string]string{
+	"0":  "ZERO",
+	"1":  "ONE",
+	"2":  "TWO",
+	"3":  "THREE",
+	"4":  "FOUR",
+	"5":  "FIVE",
+	"6":  "SIX",
+	"7":  "SEVEN",
+	"8":  "EIGHT",
+	"9":  "NINE",
+	"10": "TEN",
+	"&":  "AND",
+}
+
+// AnswerAliases combines the default answer aliases with additional ones.  The
+// additional aliases take precedence over the default ones.  Both the aliases
+// and the values they stand for are normalized to uppercase.
+func AnswerAliases(additional map[string]string) map[string]string {
+	aliases := make(map[string]string)
+	for alias, canonical := range DefaultAnswerAliases {
+		aliases[alias] = canonical
+	}
+	for alias, canonical := range additional {
+		aliases[strings.ToUpper(alias)] = strings.ToUpper(canonical)
+	}
+
+	return aliases
+}
+
+// NormalizeAnswerAliases validates aliases that were provided by a user and
+// normalizes them to uppercase.  An error is returned if an alias or the value
+// it stands for is empty.
+func NormalizeAnswerAliases(aliases map[string]string) (map[string]string, error) {
+	normalized := make(map[string]string)
+	for alias, canonical := range aliases {
+		alias = strings.ToUpper(strings.TrimSpace(alias))
+		canonical = strings.ToUpper(strings.TrimSpace(canonical))
+		if alias == "" || canonical == "" {
+			return nil, fmt.Errorf("invalid empty answer alias: %q -> %q", alias, canonical)
+		}
+
+		normalized[alias] = canonical
+	}
+
+	return normalized, nil
+}
+
+// CanonicalAnswer returns the canonical way of writing a value using the
+// provided aliases.  Values without an alias are returned as-is.
+func CanonicalAnswer(value string, aliases map[string]string) string {
+	value = strings.ToUpper(value)
+	if canonical, ok := aliases[value]; ok {
+		return canonical
+	}
+
+	return value
+}
+
+// EquivalentAnswers determines if two values are the same once their aliases
+// have been taken into account.  For example with the default aliases "1" and
+// "ONE" are equivalent, as are "&" and "AND".
+func EquivalentAnswers(a, b string, aliases map[string]string) bool {
+	return CanonicalAnswer(a, aliases) == CanonicalAnswer(b, aliases)
+}
+
+// ExpandAnswerAliases replaces every non-alphabetic alias within an answer with
+// the value it stands for.  This is useful for puzzles where every cell holds a
+// single letter, so "R&B" would become "RANDB".
+func ExpandAnswerAliases(answer string, aliases map[string]string) string {
+	var keys []string
+	for alias := range aliases {
+		if strings.IndexFunc(alias, unicode.IsLetter) == -1 {
+			keys = append(keys, alias)
+		}
+	}
+
+	// The replacer prefers the pairs that it's given first, so make sure longer
+	// aliases come first in order for "10" to be matched before "1".
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	var replacements []string
+	for _, alias := range keys {
+		replacements = append(replacements, alias, aliases[alias])
+	}
+
+	return strings.NewReplacer(replacements...).Replace(strings.ToUpper(answer))
+}
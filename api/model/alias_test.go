@@ -0,0 +1,73 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestAnswerAliases(t *testing.T) {
+	aliases := AnswerAliases(map[string]string{"+": "plus", "&": "n"})
+	assert.Equal(t, "PLUS", aliases["+"])
+	assert.Equal(t, "N", aliases["&"])
+	assert.Equal(t, "ONE", aliases["1"])
+
+	// The defaults aren't modified by additional aliases.
+	assert.Equal(t, "AND", DefaultAnswerAliases["&"])
+	_, ok := DefaultAnswerAliases["+"]
+	assert.False(t, ok)
+}
+
+func TestEquivalentAnswers(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{a: "ONE", b: "1", expected: true},
+		{a: "1", b: "one", expected: true},
+		{a: "TEN", b: "10", expected: true},
+		{a: "AND", b: "&", expected: true},
+		{a: "ONE", b: "ONE", expected: true},
+		{a: "ONE", b: "2", expected: false},
+		{a: "AND", b: "+", expected: false},
+	}
+
+	aliases := AnswerAliases(nil)
+	for _, test := range tests {
+		t.Run(test.a+"="+test.b, func(t *testing.T) {
+			assert.Equal(t, test.expected, EquivalentAnswers(test.a, test.b, aliases))
+		})
+	}
+}
+
+func TestExpandAnswerAliases(t *testing.T) {
+	tests := []struct {
+		answer   string
+		expected string
+	}{
+		{answer: "R&B", expected: "RANDB"},
+		{answer: "4SQUARE", expected: "FOURSQUARE"},
+		{answer: "10K", expected: "TENK"},
+		{answer: "WH..S", expected: "WH..S"},
+		{answer: "one", expected: "ONE"},
+	}
+
+	aliases := AnswerAliases(nil)
+	for _, test := range tests {
+		t.Run(test.answer, func(t *testing.T) {
+			assert.Equal(t, test.expected, ExpandAnswerAliases(test.answer, aliases))
+		})
+	}
+}
+
+func TestNormalizeAnswerAliases(t *testing.T) {
+	aliases, err := NormalizeAnswerAliases(map[string]string{"+": " plus "})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"+": "PLUS"}, aliases)
+
+	_, err = NormalizeAnswerAliases(map[string]string{"": "PLUS"})
+	assert.Error(t, err)
+
+	_, err = NormalizeAnswerAliases(map[string]string{"+": ""})
+	assert.Error(t, err)
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// MaxUnescapeDepth is the most times that clue text will be unescaped.  Some
+// sources escape their clues more than once (e.g. &amp;quot;) so unescaping
+// is repeated until the text stops changing or this depth is reached.
+const MaxUnescapeDepth = 3
+
+// SafeMarkupRegexp matches the HTML tags that are allowed to remain in clue
+// text.  These are the formatting tags that publishers use within clues, for
+// example to italicize the title of a work.  Any other angle brackets in a clue
+// are escaped so that they're displayed as-is instead of being interpreted as
+// markup.
+var SafeMarkupRegexp = regexp.MustCompile(
+	`(?i)</?(?:b|em|i|s|strong|sub|sup|u)>|<br\s*/?>`,
+)
+
+// UnescapeClue decodes all of the HTML entities (named as well as numeric) that
+// are present in the text of a clue.  Formatting tags that are considered safe
+// are preserved, while any other angle brackets, including those that were
+// produced by decoding an entity, are escaped.
+func UnescapeClue(s string) string {
+	for i := 0; i < MaxUnescapeDepth; i++ {
+		unescaped := html.UnescapeString(s)
+		if unescaped == s {
+			break
+		}
+		s = unescaped
+	}
+
+	escape := strings.NewReplacer("<", "&lt;", ">", "&gt;")
+
+	var sb strings.Builder
+	var last int
+	for _, loc := range SafeMarkupRegexp.FindAllStringIndex(s, -1) {
+		sb.WriteString(escape.Replace(s[last:loc[0]]))
+		sb.WriteString(s[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	sb.WriteString(escape.Replace(s[last:]))
+
+	return strings.TrimSpace(sb.String())
+}
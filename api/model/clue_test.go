@@ -0,0 +1,86 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUnescapeClue(t *testing.T) {
+	tests := []struct {
+		name     string
+		clue     string
+		expected string
+	}{
+		{
+			name:     "no entities",
+			clue:     "Room just under the roof",
+			expected: "Room just under the roof",
+		},
+		{
+			name:     "quote and apostrophe",
+			clue:     "&quot;Look out!&quot; and &#39;Hey!&#39;",
+			expected: `"Look out!" and 'Hey!'`,
+		},
+		{
+			name:     "ampersand",
+			clue:     "Q&amp;A part",
+			expected: "Q&A part",
+		},
+		{
+			name:     "named entities",
+			clue:     "Caf&eacute; drink &mdash; hot or iced&hellip;",
+			expected: "Café drink — hot or iced…",
+		},
+		{
+			name:     "decimal numeric entity",
+			clue:     "Ni&#241;o&#8217;s opposite",
+			expected: "Niño’s opposite",
+		},
+		{
+			name:     "hexadecimal numeric entity",
+			clue:     "&#x201C;Ta-da!&#x201D;",
+			expected: "“Ta-da!”",
+		},
+		{
+			name:     "double escaped",
+			clue:     "&amp;quot;Quiet!&amp;quot;",
+			expected: `"Quiet!"`,
+		},
+		{
+			name:     "safe markup is preserved",
+			clue:     "Author of <i>Hamlet</i>, <b>briefly</b>",
+			expected: "Author of <i>Hamlet</i>, <b>briefly</b>",
+		},
+		{
+			name:     "safe markup from entities is preserved",
+			clue:     "H&lt;sub&gt;2&lt;/sub&gt;O",
+			expected: "H<sub>2</sub>O",
+		},
+		{
+			name:     "unsafe markup is escaped",
+			clue:     `<img src="x" onerror="alert(1)">`,
+			expected: `&lt;img src="x" onerror="alert(1)"&gt;`,
+		},
+		{
+			name:     "unsafe markup from entities is escaped",
+			clue:     "&lt;script&gt;alert(1)&lt;/script&gt;",
+			expected: "&lt;script&gt;alert(1)&lt;/script&gt;",
+		},
+		{
+			name:     "comparison operators are escaped",
+			clue:     "1 &lt; 2, 3 &gt; 2",
+			expected: "1 &lt; 2, 3 &gt; 2",
+		},
+		{
+			name:     "surrounding whitespace",
+			clue:     "  &nbsp;Padded&nbsp; ",
+			expected: "Padded",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, UnescapeClue(test.clue))
+		})
+	}
+}
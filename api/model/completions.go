@@ -0,0 +1,122 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/db"
+	"github.com/bbeck/puzzles-with-chat/api/pubsub"
+	"github.com/gomodule/redigo/redis"
+	"time"
+)
+
+// CompletionsKey is the key of the sorted set in redis that tracks the puzzles
+// that have most recently been completed across all channels.  Members of the
+// set are JSON encoded Completion instances and are scored by the time of the
+// completion in milliseconds since the epoch.
+const CompletionsKey = "completions"
+
+// CompletionsMaxEntries is the maximum number of completions that are retained
+// in the completions set.  Older completions are removed as new ones are
+// recorded.
+var CompletionsMaxEntries = 100
+
+// Completion is a representation of a puzzle that a channel has completed.  It
+// can be marshalled to/from JSON.
+type Completion struct {
+	Type      string    `json:"type"`
+	Channel   string    `json:"channel"`
+	Title     string    `json:"title"`
+	Publisher string    `json:"publisher"`
+	Duration  Duration  `json:"duration"`
+	Time      time.Time `json:"time"`
+}
+
+// CompletionEventKind is the kind of the event that's published when a channel
+// completes a puzzle.  Unlike each puzzle type's complete event it's the same
+// for every puzzle type, so that completions can be watched for across all
+// channels.
+const CompletionEventKind = "completion"
+
+// CompletionsChannel is the pubsub channel that completion events are published
+// to.  It's kept separate from each channel's own pubsub channel so that the
+// clients of a channel aren't sent completion events they don't know about.
+const CompletionsChannel pubsub.Channel = "completions"
+
+// CompletionEvent returns the event that announces a channel's completion of a
+// puzzle.
+func CompletionEvent(completion Completion) pubsub.Event {
+	return pubsub.Event{
+		Kind:    CompletionEventKind,
+		Payload: completion,
+	}
+}
+
+// RecordCompletion adds a completion to the completions set.  If the set has
+// grown beyond CompletionsMaxEntries then the oldest completions are removed at
+// the same time.
+func RecordCompletion(conn db.Connection, completion Completion) error {
+	completion.Time = completion.Time.Truncate(time.Millisecond).UTC()
+
+	bs, err := json.Marshal(completion)
+	if err != nil {
+		return err
+	}
+
+	if _, err := conn.Do("ZADD", CompletionsKey, toMillis(completion.Time), bs); err != nil {
+		return err
+	}
+
+	_, err = conn.Do("ZREMRANGEBYRANK", CompletionsKey, 0, -(CompletionsMaxEntries + 1))
+	return err
+}
+
+// GetRecentCompletions returns up to limit of the most recent completions
+// ordered from the most recent completion to the least recent.
+func GetRecentCompletions(conn db.Connection, limit int) ([]Completion, error) {
+	if limit <= 0 {
+		return []Completion{}, nil
+	}
+
+	values, err := redis.ByteSlices(conn.Do("ZREVRANGE", CompletionsKey, 0, limit-1))
+	if err != nil {
+		return nil, err
+	}
+
+	completions := make([]Completion, 0, len(values))
+	for _, value := range values {
+		var completion Completion
+		if err := json.Unmarshal(value, &completion); err != nil {
+			return nil, fmt.Errorf("malformed completion entry: %v", err)
+		}
+
+		completions = append(completions, completion)
+	}
+
+	return completions, nil
+}
+
+// RemoveCompletions removes all of a channel's completions from the completions
+// set.
+func RemoveCompletions(conn db.Connection, channel string) error {
+	values, err := redis.ByteSlices(conn.Do("ZRANGE", CompletionsKey, 0, -1))
+	if err != nil {
+		return err
+	}
+
+	for _, value := range values {
+		var completion Completion
+		if err := json.Unmarshal(value, &completion); err != nil {
+			return fmt.Errorf("malformed completion entry: %v", err)
+		}
+
+		if completion.Channel != channel {
+			continue
+		}
+
+		if _, err := conn.Do("ZREM", CompletionsKey, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRecordCompletion(t *testing.T) {
+	conn := NewRedisConnection(t)
+	now := time.Now()
+
+	first := Completion{
+		Type:      "crossword",
+		Channel:   "a",
+		Title:     "First",
+		Publisher: "The New York Times",
+		Duration:  Duration{10 * time.Minute},
+		Time:      now.Add(-2 * time.Minute),
+	}
+	second := Completion{
+		Type:      "acrostic",
+		Channel:   "b",
+		Title:     "Second",
+		Publisher: "The New York Times",
+		Duration:  Duration{20 * time.Minute},
+		Time:      now.Add(-1 * time.Minute),
+	}
+	require.NoError(t, RecordCompletion(conn, first))
+	require.NoError(t, RecordCompletion(conn, second))
+
+	completions, err := GetRecentCompletions(conn, 10)
+	require.NoError(t, err)
+	require.Len(t, completions, 2)
+
+	second.Time = truncate(second.Time)
+	first.Time = truncate(first.Time)
+	assert.Equal(t, []Completion{second, first}, completions)
+
+	// The limit restricts the number of completions returned.
+	completions, err = GetRecentCompletions(conn, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []Completion{second}, completions)
+
+	completions, err = GetRecentCompletions(conn, 0)
+	require.NoError(t, err)
+	assert.Empty(t, completions)
+}
+
+func TestRecordCompletion_MaxEntries(t *testing.T) {
+	conn := NewRedisConnection(t)
+	now := time.Now()
+
+	max := CompletionsMaxEntries
+	CompletionsMaxEntries = 3
+	t.Cleanup(func() { CompletionsMaxEntries = max })
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, RecordCompletion(conn, Completion{
+			Type:    "crossword",
+			Channel: string(rune('a' + i)),
+			Time:    now.Add(time.Duration(i) * time.Second),
+		}))
+	}
+
+	count, err := redis.Int(conn.Do("ZCARD", CompletionsKey))
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	// Only the newest completions are retained.
+	completions, err := GetRecentCompletions(conn, 10)
+	require.NoError(t, err)
+	require.Len(t, completions, 3)
+	assert.Equal(t, "e", completions[0].Channel)
+	assert.Equal(t, "d", completions[1].Channel)
+	assert.Equal(t, "c", completions[2].Channel)
+}
+
+func TestRemoveCompletions(t *testing.T) {
+	conn := NewRedisConnection(t)
+	now := time.Now()
+
+	for i, channel := range []string{"a", "b", "a"} {
+		require.NoError(t, RecordCompletion(conn, Completion{
+			Type:    "crossword",
+			Channel: channel,
+			Time:    now.Add(time.Duration(i) * time.Second),
+		}))
+	}
+
+	require.NoError(t, RemoveCompletions(conn, "a"))
+
+	completions, err := GetRecentCompletions(conn, 10)
+	require.NoError(t, err)
+	require.Len(t, completions, 1)
+	assert.Equal(t, "b", completions[0].Channel)
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// NormalizeEmoteLetters validates a mapping of emote names to the letters they
+// stand for that was provided by a user and normalizes the letters to
+// uppercase.  Emote names are case sensitive so they're left as-is.  An error
+// is returned if an emote name contains whitespace or if the value it stands
+// for isn't made up entirely of letters.
+func NormalizeEmoteLetters(emotes map[string]string) (map[string]string, error) {
+	normalized := make(map[string]string)
+	for emote, letters := range emotes {
+		emote = strings.TrimSpace(emote)
+		letters = strings.ToUpper(strings.TrimSpace(letters))
+		if emote == "" || strings.IndexFunc(emote, unicode.IsSpace) != -1 {
+			return nil, fmt.Errorf("invalid emote name: %q", emote)
+		}
+		if letters == "" || strings.IndexFunc(letters, func(r rune) bool { return !unicode.IsLetter(r) }) != -1 {
+			return nil, fmt.Errorf("invalid letters for emote %s: %q", emote, letters)
+		}
+
+		normalized[emote] = letters
+	}
+
+	return normalized, nil
+}
+
+// TranslateEmoteLetters replaces an answer that's written entirely as emotes
+// with the letters that the emotes stand for, so "myChannelA myChannelB" would
+// become "AB".  The answer is only translated when every whitespace separated
+// token in it is a known emote, otherwise it's returned unchanged.
+func TranslateEmoteLetters(answer string, emotes map[string]string) string {
+	tokens := strings.Fields(answer)
+	if len(tokens) == 0 || len(emotes) == 0 {
+		return answer
+	}
+
+	var sb strings.Builder
+	for _, token := range tokens {
+		letters, ok := emotes[token]
+		if !ok {
+			return answer
+		}
+		sb.WriteString(letters)
+	}
+
+	return sb.String()
+}
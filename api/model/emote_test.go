@@ -0,0 +1,115 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNormalizeEmoteLetters(t *testing.T) {
+	emotes, err := NormalizeEmoteLetters(map[string]string{
+		"myChannelA":  "a",
+		" myChannelB": "B ",
+		"myChannelQu": "qu",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"myChannelA":  "A",
+		"myChannelB":  "B",
+		"myChannelQu": "QU",
+	}, emotes)
+}
+
+func TestNormalizeEmoteLetters_Error(t *testing.T) {
+	tests := []struct {
+		name   string
+		emotes map[string]string
+	}{
+		{
+			name:   "empty emote",
+			emotes: map[string]string{" ": "A"},
+		},
+		{
+			name:   "emote with whitespace",
+			emotes: map[string]string{"my emote": "A"},
+		},
+		{
+			name:   "empty letters",
+			emotes: map[string]string{"myChannelA": ""},
+		},
+		{
+			name:   "non-letter",
+			emotes: map[string]string{"myChannelA": "1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NormalizeEmoteLetters(test.emotes)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTranslateEmoteLetters(t *testing.T) {
+	emotes := map[string]string{
+		"myChannelA":  "A",
+		"myChannelB":  "B",
+		"myChannelQu": "QU",
+	}
+
+	tests := []struct {
+		name     string
+		answer   string
+		emotes   map[string]string
+		expected string
+	}{
+		{
+			name:     "full emote answer",
+			answer:   "myChannelA myChannelB",
+			emotes:   emotes,
+			expected: "AB",
+		},
+		{
+			name:     "multiple letter emote",
+			answer:   "  myChannelQu   myChannelA ",
+			emotes:   emotes,
+			expected: "QUA",
+		},
+		{
+			name:     "normal text",
+			answer:   "hello world",
+			emotes:   emotes,
+			expected: "hello world",
+		},
+		{
+			name:     "partially emotes",
+			answer:   "myChannelA b",
+			emotes:   emotes,
+			expected: "myChannelA b",
+		},
+		{
+			name:     "emote names are case sensitive",
+			answer:   "mychannela",
+			emotes:   emotes,
+			expected: "mychannela",
+		},
+		{
+			name:     "no emotes configured",
+			answer:   "myChannelA",
+			expected: "myChannelA",
+		},
+		{
+			name:     "empty answer",
+			answer:   "",
+			emotes:   emotes,
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, TranslateEmoteLetters(test.answer, test.emotes))
+		})
+	}
+}
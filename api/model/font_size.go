@@ -16,6 +16,9 @@ const (
 	FontSizeXLarge
 )
 
+// FontSizes contains every supported font size in the order they're defined.
+var FontSizes = []FontSize{FontSizeNormal, FontSizeLarge, FontSizeXLarge}
+
 func (s FontSize) String() string {
 	switch s {
 	case FontSizeNormal:
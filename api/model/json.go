@@ -0,0 +1,65 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// SnippetRadius is the number of bytes on either side of the location of a
+// parse failure that are included in the error.  This is enough to recognize
+// where in the input the failure happened without including the entire input.
+const SnippetRadius = 30
+
+// DecodeJSON parses JSON from a reader into a value.  When the JSON can't be
+// parsed the error describes where the problem is, naming the field that has an
+// unexpected value or, when the JSON is malformed, including a short snippet of
+// the input around the failure.
+func DecodeJSON(in io.Reader, v interface{}) error {
+	bs, err := ioutil.ReadAll(in)
+	if err != nil {
+		return err
+	}
+
+	return UnmarshalJSON(bs, v)
+}
+
+// UnmarshalJSON parses JSON into a value.  When the JSON can't be parsed the
+// error describes where the problem is in the same way as DecodeJSON.
+func UnmarshalJSON(bs []byte, v interface{}) error {
+	err := json.Unmarshal(bs, v)
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return fmt.Errorf("malformed JSON at offset %d near %q: %w", syntaxErr.Offset, Snippet(bs, syntaxErr.Offset), err)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return fmt.Errorf("unexpected %s for field %s near %q: %w", typeErr.Value, typeErr.Field, Snippet(bs, typeErr.Offset), err)
+	}
+
+	return err
+}
+
+// Snippet returns the portion of the input that surrounds an offset, extending
+// at most SnippetRadius bytes in each direction.
+func Snippet(bs []byte, offset int64) string {
+	start := offset - SnippetRadius
+	if start < 0 {
+		start = 0
+	}
+
+	end := offset + SnippetRadius
+	if end > int64(len(bs)) {
+		end = int64(len(bs))
+	}
+
+	if start > end {
+		start = end
+	}
+
+	return string(bs[start:end])
+}
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	var value struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	err := DecodeJSON(strings.NewReader(`{"name": "abc", "count": 3}`), &value)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", value.Name)
+	assert.Equal(t, 3, value.Count)
+}
+
+func TestDecodeJSON_Error(t *testing.T) {
+	type Inner struct {
+		Rows int `json:"rows"`
+	}
+
+	var value struct {
+		Name string `json:"name"`
+		Size Inner  `json:"size"`
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "unexpected type names the field",
+			input:    `{"name": "abc", "size": {"rows": "fifteen"}}`,
+			expected: []string{"field size.rows", "fifteen"},
+		},
+		{
+			name:     "malformed json includes a snippet",
+			input:    `{"name": "abc" "size": {"rows": 15}}`,
+			expected: []string{"offset 16", `"abc\" \"size\"`},
+		},
+		{
+			name:     "truncated json",
+			input:    `{"name": "ab`,
+			expected: []string{"unexpected end of JSON input"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := DecodeJSON(strings.NewReader(test.input), &value)
+			require.Error(t, err)
+			for _, expected := range test.expected {
+				assert.Contains(t, err.Error(), expected)
+			}
+		})
+	}
+
+	// The original error is still available.
+	err := DecodeJSON(strings.NewReader(`{"name": 1}`), &value)
+	var typeErr *json.UnmarshalTypeError
+	assert.True(t, errors.As(err, &typeErr))
+}
+
+func TestSnippet(t *testing.T) {
+	long := strings.Repeat("a", SnippetRadius) + "X" + strings.Repeat("b", SnippetRadius+10)
+
+	tests := []struct {
+		name     string
+		input    string
+		offset   int64
+		expected string
+	}{
+		{
+			name:     "short input",
+			input:    "abc",
+			offset:   1,
+			expected: "abc",
+		},
+		{
+			name:     "long input",
+			input:    long,
+			offset:   SnippetRadius,
+			expected: strings.Repeat("a", SnippetRadius) + "X" + strings.Repeat("b", SnippetRadius-1),
+		},
+		{
+			name:     "offset past the end",
+			input:    "abc",
+			offset:   100,
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, Snippet([]byte(test.input), test.offset))
+		})
+	}
+}
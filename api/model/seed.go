@@ -0,0 +1,21 @@
+package model
+
+import (
+	"math/rand"
+	"time"
+)
+
+// NewSeed returns a new random seed for a solve.  It's called once when a
+// puzzle is selected and the seed is then used for all randomization during
+// the solve.  Tests may replace it in order to choose the seed that's used.
+var NewSeed = func() int64 {
+	return rand.New(rand.NewSource(time.Now().UnixNano())).Int63()
+}
+
+// SeededRand returns a source of randomness for a solve with the provided seed.
+// The n parameter is the number of times randomness has previously been needed
+// during the solve so that repeated uses produce different, but still
+// reproducible, results.
+func SeededRand(seed int64, n int) *rand.Rand {
+	return rand.New(rand.NewSource(seed + int64(n)))
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSeededRand(t *testing.T) {
+	values := func(seed int64, n int) []int {
+		r := SeededRand(seed, n)
+		return []int{r.Int(), r.Int(), r.Int()}
+	}
+
+	assert.Equal(t, values(1, 0), values(1, 0))
+	assert.NotEqual(t, values(1, 0), values(2, 0))
+	assert.NotEqual(t, values(1, 0), values(1, 1))
+}
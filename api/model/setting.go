@@ -0,0 +1,20 @@
+package model
+
+import (
+	"fmt"
+	"github.com/go-chi/render"
+	"net/http"
+	"strings"
+)
+
+// InvalidSettingValue responds to a request that attempted to change a setting
+// to an unsupported value.  The response has a status of 400 and a body that
+// describes the values that the setting accepts.
+func InvalidSettingValue(w http.ResponseWriter, r *http.Request, setting string, allowed []string) {
+	render.Status(r, http.StatusBadRequest)
+	render.JSON(w, r, map[string]interface{}{
+		"error":   fmt.Sprintf("invalid value for setting %s, must be one of: %s", setting, strings.Join(allowed, ", ")),
+		"setting": setting,
+		"allowed": allowed,
+	})
+}
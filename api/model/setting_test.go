@@ -0,0 +1,28 @@
+package model
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestInvalidSettingValue(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPut, "/setting/clue_font_size", nil)
+	response := httptest.NewRecorder()
+
+	InvalidSettingValue(response, request, "clue_font_size", []string{"normal", "large"})
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	var body struct {
+		Error   string   `json:"error"`
+		Setting string   `json:"setting"`
+		Allowed []string `json:"allowed"`
+	}
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&body))
+	assert.Equal(t, "invalid value for setting clue_font_size, must be one of: normal, large", body.Error)
+	assert.Equal(t, "clue_font_size", body.Setting)
+	assert.Equal(t, []string{"normal", "large"}, body.Allowed)
+}
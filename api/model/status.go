@@ -25,6 +25,13 @@ const (
 
 	// The puzzle that was being solved is complete.
 	StatusComplete
+
+	// The puzzle was selected with all of its answers already filled in and is
+	// being reviewed instead of solved.
+	StatusReview
+
+	// The puzzle that was being solved was given up on before it was complete.
+	StatusAbandoned
 )
 
 func (s Status) String() string {
@@ -39,6 +46,10 @@ func (s Status) String() string {
 		return "solving"
 	case StatusComplete:
 		return "complete"
+	case StatusReview:
+		return "review"
+	case StatusAbandoned:
+		return "abandoned"
 	default:
 		return "unknown"
 	}
@@ -51,6 +62,8 @@ func (s Status) MarshalJSON() ([]byte, error) {
 	case StatusPaused:
 	case StatusSolving:
 	case StatusComplete:
+	case StatusReview:
+	case StatusAbandoned:
 	default:
 		return nil, fmt.Errorf("unrecognized status: %v", s)
 	}
@@ -75,6 +88,10 @@ func (s *Status) UnmarshalJSON(bs []byte) error {
 		*s = StatusSolving
 	case "complete":
 		*s = StatusComplete
+	case "review":
+		*s = StatusReview
+	case "abandoned":
+		*s = StatusAbandoned
 	default:
 		return fmt.Errorf("unrecognized status string: %s", str)
 	}
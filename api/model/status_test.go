@@ -38,6 +38,16 @@ func TestStatus_String(t *testing.T) {
 			state:    StatusComplete,
 			expected: "complete",
 		},
+		{
+			name:     "review",
+			state:    StatusReview,
+			expected: "review",
+		},
+		{
+			name:     "abandoned",
+			state:    StatusAbandoned,
+			expected: "abandoned",
+		},
 		{
 			name:     "invalid",
 			state:    Status(17),
@@ -84,6 +94,16 @@ func TestStatus_MarshalJSON(t *testing.T) {
 			state:    StatusComplete,
 			expected: []byte(`"complete"`),
 		},
+		{
+			name:     "review",
+			state:    StatusReview,
+			expected: []byte(`"review"`),
+		},
+		{
+			name:     "abandoned",
+			state:    StatusAbandoned,
+			expected: []byte(`"abandoned"`),
+		},
 	}
 
 	for _, test := range tests {
@@ -145,6 +165,16 @@ func TestStatus_UnmarshalJSON(t *testing.T) {
 			bs:       []byte(`"complete"`),
 			expected: StatusComplete,
 		},
+		{
+			name:     "review",
+			bs:       []byte(`"review"`),
+			expected: StatusReview,
+		},
+		{
+			name:     "abandoned",
+			bs:       []byte(`"abandoned"`),
+			expected: StatusAbandoned,
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+)
+
+// ServerStartTime is the time that this instance of the server started.  Any
+// solve that was started before this time was in progress when the previous
+// instance of the server stopped.
+var ServerStartTime = time.Now()
+
+// PauseStaleSolves determines whether solves that were in progress when the
+// server stopped are paused when they're next loaded.  When disabled their
+// timers instead resume from when the server started.
+var PauseStaleSolves = true
+
+// ReconcileSolveTimer corrects the timer of a solve that was in progress when
+// the server last stopped.  Without this the time that the server was down
+// would be counted as solve time.  Since we can't know exactly when the server
+// stopped, the solve is only credited with the time up until its state was
+// last saved.
+//
+// The returned status, last start time and total solve duration should replace
+// the ones that were provided.  Solves that aren't stale are returned as-is.
+func ReconcileSolveTimer(status Status, lastStart, lastSave *time.Time, total Duration) (Status, *time.Time, Duration) {
+	if status != StatusSolving || lastStart == nil || !lastStart.Before(ServerStartTime) {
+		return status, lastStart, total
+	}
+
+	if lastSave != nil && lastSave.After(*lastStart) {
+		total = Duration{total.Duration + lastSave.Sub(*lastStart)}
+	}
+
+	if PauseStaleSolves {
+		return StatusPaused, nil, total
+	}
+
+	start := ServerStartTime
+	return status, &start, total
+}
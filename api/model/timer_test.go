@@ -0,0 +1,82 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestReconcileSolveTimer(t *testing.T) {
+	start := ServerStartTime.Add(-3 * time.Hour)
+	save := ServerStartTime.Add(-2 * time.Hour)
+	fresh := ServerStartTime.Add(time.Minute)
+	total := Duration{10 * time.Minute}
+
+	tests := []struct {
+		name           string
+		status         Status
+		lastStart      *time.Time
+		lastSave       *time.Time
+		pause          bool
+		expectedStatus Status
+		expectedStart  *time.Time
+		expectedTotal  Duration
+	}{
+		{
+			name:           "stale solve is paused",
+			status:         StatusSolving,
+			lastStart:      &start,
+			lastSave:       &save,
+			pause:          true,
+			expectedStatus: StatusPaused,
+			expectedTotal:  Duration{70 * time.Minute},
+		},
+		{
+			name:           "stale solve without a save time adds no time",
+			status:         StatusSolving,
+			lastStart:      &start,
+			pause:          true,
+			expectedStatus: StatusPaused,
+			expectedTotal:  total,
+		},
+		{
+			name:           "stale solve resumes from server start",
+			status:         StatusSolving,
+			lastStart:      &start,
+			lastSave:       &save,
+			expectedStatus: StatusSolving,
+			expectedStart:  &ServerStartTime,
+			expectedTotal:  Duration{70 * time.Minute},
+		},
+		{
+			name:           "solve started after server start",
+			status:         StatusSolving,
+			lastStart:      &fresh,
+			lastSave:       &fresh,
+			pause:          true,
+			expectedStatus: StatusSolving,
+			expectedStart:  &fresh,
+			expectedTotal:  total,
+		},
+		{
+			name:           "paused solve",
+			status:         StatusPaused,
+			lastSave:       &save,
+			pause:          true,
+			expectedStatus: StatusPaused,
+			expectedTotal:  total,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			PauseStaleSolves = test.pause
+			defer func() { PauseStaleSolves = true }()
+
+			status, lastStart, duration := ReconcileSolveTimer(test.status, test.lastStart, test.lastSave, total)
+			assert.Equal(t, test.expectedStatus, status)
+			assert.Equal(t, test.expectedStart, lastStart)
+			assert.Equal(t, test.expectedTotal, duration)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxWildcardCount is the largest count that can follow a wildcard.  No puzzle
+// has an answer anywhere near this long.
+const MaxWildcardCount = 1000
+
+// ExpandWildcards rewrites the unknown cells of an answer into the canonical
+// "." form.  Unknown cells may be written as either "." or "?", and a run of
+// unknown cells may be abbreviated by following a single wildcard with a
+// count.  For example "WH?3S" expands to "WH...S".
+//
+// Characters within parenthesized groups (rebus cells) are kept as-is.  An
+// error is returned when a count is ambiguous, which happens when it follows
+// more than one consecutive wildcard (is "??3" three unknowns or four?), has a
+// leading zero, or is zero or too large.
+func ExpandWildcards(answer string) (string, error) {
+	runes := []rune(answer)
+
+	var sb strings.Builder
+	var inside bool
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+
+		switch {
+		case c == '(':
+			inside = true
+			sb.WriteRune(c)
+
+		case c == ')':
+			inside = false
+			sb.WriteRune(c)
+
+		case inside || (c != '.' && c != '?'):
+			sb.WriteRune(c)
+
+		default:
+			// Find the count that follows the wildcard, if there is one.
+			j := i + 1
+			for j < len(runes) && runes[j] >= '0' && runes[j] <= '9' {
+				j++
+			}
+
+			if j == i+1 {
+				sb.WriteRune('.')
+				continue
+			}
+
+			digits := string(runes[i+1 : j])
+			if i > 0 && (runes[i-1] == '.' || runes[i-1] == '?') {
+				return "", fmt.Errorf("ambiguous wildcard count %s follows another wildcard: %s", digits, answer)
+			}
+			if digits[0] == '0' {
+				return "", fmt.Errorf("invalid wildcard count %s: %s", digits, answer)
+			}
+
+			count, err := strconv.Atoi(digits)
+			if err != nil || count > MaxWildcardCount {
+				return "", fmt.Errorf("invalid wildcard count %s: %s", digits, answer)
+			}
+
+			sb.WriteString(strings.Repeat(".", count))
+			i = j - 1
+		}
+	}
+
+	return sb.String(), nil
+}
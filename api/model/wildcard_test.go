@@ -0,0 +1,89 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestExpandWildcards(t *testing.T) {
+	tests := []struct {
+		answer   string
+		expected string
+	}{
+		{
+			answer:   "WHALES",
+			expected: "WHALES",
+		},
+		{
+			answer:   "WH...S",
+			expected: "WH...S",
+		},
+		{
+			answer:   "WH???S",
+			expected: "WH...S",
+		},
+		{
+			answer:   "WH?3S",
+			expected: "WH...S",
+		},
+		{
+			answer:   "WH.3S",
+			expected: "WH...S",
+		},
+		{
+			answer:   "?5S",
+			expected: ".....S",
+		},
+		{
+			answer:   "W?4",
+			expected: "W....",
+		},
+		{
+			answer:   "?2L?2",
+			expected: "..L..",
+		},
+		{
+			answer:   "?12",
+			expected: "............",
+		},
+		{
+			answer:   "(R.D)?2",
+			expected: "(R.D)..",
+		},
+		{
+			answer:   "(?3)X",
+			expected: "(?3)X",
+		},
+		{
+			answer:   "R2D2",
+			expected: "R2D2",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.answer, func(t *testing.T) {
+			actual, err := ExpandWildcards(test.answer)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestExpandWildcards_Error(t *testing.T) {
+	tests := []string{
+		"WH??3S",
+		"WH.?3S",
+		"WH?0S",
+		"WH?03S",
+		"WH?1001S",
+		"?99999999999999999999",
+	}
+
+	for _, answer := range tests {
+		t.Run(answer, func(t *testing.T) {
+			_, err := ExpandWildcards(answer)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"io"
+	"sort"
+	"time"
+)
+
+// ParseXWordInfoAvailableDates converts an HTML response from one of the pages
+// on xwordinfo.com that list the puzzles of a kind (e.g. the select acrostic
+// page) into a sorted list of available dates.  Each puzzle is linked from the
+// page with its date in a data attribute whose name is made of the provided
+// prefix (e.g. data-acrdate for a prefix of acr).
+func ParseXWordInfoAvailableDates(in io.Reader, prefix string) ([]time.Time, error) {
+	doc, err := goquery.NewDocumentFromReader(in)
+	if err != nil {
+		return nil, err
+	}
+
+	attr := fmt.Sprintf("data-%sdate", prefix)
+
+	var dates []time.Time
+	doc.Find(fmt.Sprintf("a[%s]", attr)).Each(func(i int, s *goquery.Selection) {
+		if err != nil {
+			return
+		}
+
+		d, ok := s.Attr(attr)
+		if !ok {
+			err = fmt.Errorf("unable to determine %s for selection: %v", attr, s)
+			return
+		}
+
+		var date time.Time
+		if date, err = time.Parse("1/2/2006", d); err == nil {
+			dates = append(dates, date)
+		}
+	})
+
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+
+	if len(dates) == 0 && err == nil {
+		err = errors.New("no dates found")
+	}
+
+	return dates, err
+}
@@ -12,6 +12,20 @@ import (
 
 var PingEvent = Event{Kind: "ping"}
 
+// IdleEvent is the final event sent to a client before its stream is closed
+// because it has been idle for too long.
+var IdleEvent = Event{Kind: "idle"}
+
+// PingInterval is how long a stream can go without any events before a ping
+// event is sent to keep the connection with the client alive.
+var PingInterval = 30 * time.Second
+
+// IdleTimeout is how long a stream can go without any events other than pings
+// before it's closed.  Streams that stay idle this long likely belong to
+// abandoned browser tabs.  When 0 (the default) streams are never closed for
+// being idle.
+var IdleTimeout time.Duration
+
 // EmitEvents will loop and send events to the provided HTTP response.  The
 // events will be formatted according to the W3C working draft for Server-Sent
 // Events found at: https://www.w3.org/TR/2009/WD-eventsource-20090421.  This
@@ -21,16 +35,24 @@ var PingEvent = Event{Kind: "ping"}
 // EmitEvents will block until either the events channel is closed, the
 // provided context is done, or an error occurs while emitting an event.
 //
-// If no events are available on the events channel for 30 seconds then a ping
-// event will be synthesized and emitted automatically in order to keep the
-// connection with the client alive.
+// If no events are available on the events channel for PingInterval then a
+// ping event will be synthesized and emitted automatically in order to keep the
+// connection with the client alive.  If IdleTimeout is set and no events other
+// than pings are emitted for that long then an idle event is emitted and the
+// stream is closed.
 func EmitEvents(ctx context.Context, w http.ResponseWriter, events <-chan Event) {
 	w.Header().Set("Cache-Control", "no-transform")
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
 
+	lastActivity := time.Now()
 	for {
+		var idle <-chan time.Time
+		if IdleTimeout > 0 {
+			idle = time.After(time.Until(lastActivity.Add(IdleTimeout)))
+		}
+
 		select {
 		case <-ctx.Done():
 			return
@@ -43,8 +65,13 @@ func EmitEvents(ctx context.Context, w http.ResponseWriter, events <-chan Event)
 			if err := EmitEvent(w, msg); err != nil {
 				return
 			}
+			lastActivity = time.Now()
+
+		case <-idle:
+			_ = EmitEvent(w, IdleEvent)
+			return
 
-		case <-time.After(30 * time.Second):
+		case <-time.After(PingInterval):
 			if err := EmitEvent(w, PingEvent); err != nil {
 				return
 			}
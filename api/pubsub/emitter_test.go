@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"io"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -148,3 +149,63 @@ func TestEmitEvents_ErrorEmittingEvent(t *testing.T) {
 	assert.True(t, latch.Wait(100*time.Millisecond))
 	assert.Empty(t, w.Body.Bytes())
 }
+
+func TestEmitEvents_IdleTimeout(t *testing.T) {
+	ForceEmitterTiming(t, 10*time.Millisecond, 50*time.Millisecond)
+
+	// A connection that only receives pings is closed with an idle event.
+	w := httptest.NewRecorder()
+
+	latch := NewCountDownLatch(1)
+	go func() {
+		EmitEvents(context.Background(), w, make(chan Event))
+		latch.CountDown()
+	}()
+
+	require.True(t, latch.Wait(500*time.Millisecond))
+	assert.True(t, strings.Contains(w.Body.String(), `data:{"kind":"ping"}`))
+	assert.True(t, strings.HasSuffix(w.Body.String(), `data:{"kind":"idle"}`+nl+nl))
+}
+
+func TestEmitEvents_IdleTimeout_Activity(t *testing.T) {
+	ForceEmitterTiming(t, 10*time.Millisecond, 50*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A connection that keeps receiving events stays open well past the idle
+	// timeout.
+	w := httptest.NewRecorder()
+	events := make(chan Event)
+
+	latch := NewCountDownLatch(1)
+	go func() {
+		EmitEvents(ctx, w, events)
+		latch.CountDown()
+	}()
+
+	for i := 0; i < 10; i++ {
+		time.Sleep(20 * time.Millisecond)
+		events <- Event{Kind: "state"}
+	}
+	assert.False(t, latch.Wait(0))
+
+	cancel()
+	require.True(t, latch.Wait(100*time.Millisecond))
+	assert.False(t, strings.Contains(w.Body.String(), `"idle"`))
+}
+
+// ForceEmitterTiming changes how frequently pings are sent and how long a
+// stream can be idle for the duration of a test.
+func ForceEmitterTiming(t *testing.T, ping, idle time.Duration) {
+	t.Helper()
+
+	pingInterval := PingInterval
+	idleTimeout := IdleTimeout
+	PingInterval = ping
+	IdleTimeout = idle
+	t.Cleanup(func() {
+		PingInterval = pingInterval
+		IdleTimeout = idleTimeout
+	})
+}
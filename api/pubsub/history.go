@@ -0,0 +1,191 @@
+package pubsub
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultHistorySize is the number of recently published events that a
+// registry retains for each channel when HistorySize isn't set.
+var DefaultHistorySize = 100
+
+// HistoryIdleTimeout is how long a channel's history is retained after an event
+// was last published to or read from it.  Channels that still have subscribers
+// keep their history regardless.
+var HistoryIdleTimeout = time.Hour
+
+// HistoryPruneInterval is how frequently idle histories are looked for and
+// discarded once StartHistoryPruner has been called.
+var HistoryPruneInterval = time.Minute
+
+// MaxHistories is the largest number of channels that a registry retains a
+// history for at once.  When a history is needed for another channel the least
+// recently accessed history is discarded to make room for it.  A limit of zero
+// removes the limit.
+var MaxHistories = 10000
+
+// PollTimeout is how long a poll waits for a new event to be published when
+// there aren't any events newer than the client's cursor.
+var PollTimeout = 20 * time.Second
+
+// history is a ring buffer of the most recent events published to a channel.
+// Each event is assigned a cursor, the cursors of a channel's events are
+// sequential starting from 1 so that a client can ask for the events newer than
+// the last one it saw.
+type history struct {
+	events []Event
+	start  int
+	last   uint64
+
+	// The last time that an event was added to or read from the history.
+	accessed time.Time
+}
+
+// add appends an event to the history, discarding the oldest event if the
+// history already contains size events.
+func (h *history) add(event Event, size int) {
+	h.last++
+	h.accessed = time.Now()
+
+	if len(h.events) < size {
+		h.events = append(h.events, event)
+		return
+	}
+
+	h.events[h.start] = event
+	h.start = (h.start + 1) % len(h.events)
+}
+
+// since returns the events in the history that are newer than the provided
+// cursor along with the cursor of the newest event.  If the cursor is newer
+// than any event in the history, which happens when the registry has been
+// restarted, then every event in the history is returned.
+func (h *history) since(cursor uint64) ([]Event, uint64) {
+	if cursor > h.last {
+		cursor = 0
+	}
+
+	n := uint64(len(h.events))
+	first := h.last - n + 1
+	if cursor+1 > first {
+		first = cursor + 1
+	}
+
+	var events []Event
+	for c := first; c <= h.last; c++ {
+		offset := n - (h.last - c) - 1
+		events = append(events, h.events[(uint64(h.start)+offset)%n])
+	}
+
+	return events, h.last
+}
+
+// EventsSince returns the events published to a channel that are newer than
+// the provided cursor along with the cursor of the newest event.  Only the most
+// recent events of each channel are retained, so a client that falls too far
+// behind will miss events.
+func (r *Registry) EventsSince(channel Channel, cursor uint64) ([]Event, uint64) {
+	r.Lock()
+	defer r.Unlock()
+
+	h := r.histories[channel]
+	if h == nil {
+		return nil, 0
+	}
+	h.accessed = time.Now()
+
+	return h.since(cursor)
+}
+
+// ClearHistory discards the events that have been published to a channel.
+func (r *Registry) ClearHistory(channel Channel) {
+	r.Lock()
+	defer r.Unlock()
+
+	delete(r.histories, channel)
+}
+
+// StartHistoryPruner periodically discards the histories of channels that
+// don't have any subscribers and haven't been accessed within
+// HistoryIdleTimeout until the provided context is cancelled.
+func (r *Registry) StartHistoryPruner(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(HistoryPruneInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			r.Lock()
+			r.pruneHistories(time.Now())
+			r.Unlock()
+		}
+	}()
+}
+
+// pruneHistories discards the histories of channels that don't have any
+// subscribers and haven't been accessed within HistoryIdleTimeout.  The
+// registry's lock must be held when this is called.
+func (r *Registry) pruneHistories(now time.Time) {
+	for channel, h := range r.histories {
+		if r.counts[channel] == 0 && now.Sub(h.accessed) >= HistoryIdleTimeout {
+			delete(r.histories, channel)
+		}
+	}
+}
+
+// evictHistory discards the history that was accessed least recently, making
+// room for the history of another channel.  The registry's lock must be held
+// when this is called.
+func (r *Registry) evictHistory() {
+	var oldest Channel
+	var accessed time.Time
+	for channel, h := range r.histories {
+		if accessed.IsZero() || h.accessed.Before(accessed) {
+			oldest, accessed = channel, h.accessed
+		}
+	}
+
+	delete(r.histories, oldest)
+}
+
+// Poll returns the events published to a channel that are newer than the
+// provided cursor along with the cursor of the newest event.  If there aren't
+// any newer events then Poll blocks until one is published, the timeout
+// elapses or the context is done.
+func (r *Registry) Poll(ctx context.Context, channel Channel, cursor uint64, timeout time.Duration) ([]Event, uint64) {
+	events, last := r.EventsSince(channel, cursor)
+	if len(events) > 0 || timeout <= 0 {
+		return events, last
+	}
+
+	// Use a matching subscription so that polling clients don't count towards
+	// the channel's subscriber limit.
+	stream := make(chan Event, 1)
+	id, err := r.SubscribeMatching(func(c Channel, e Event) bool { return c == channel }, stream)
+	if err != nil {
+		return events, last
+	}
+	defer r.Unsubscribe(id)
+
+	// An event may have been published before the subscription was established.
+	events, last = r.EventsSince(channel, cursor)
+	if len(events) > 0 {
+		return events, last
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-stream:
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	return r.EventsSince(channel, cursor)
+}
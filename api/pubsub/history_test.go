@@ -0,0 +1,164 @@
+package pubsub
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRegistry_EventsSince(t *testing.T) {
+	registry := &Registry{HistorySize: 3}
+
+	// Nothing has been published yet.
+	events, cursor := registry.EventsSince("channel", 0)
+	assert.Empty(t, events)
+	assert.Equal(t, uint64(0), cursor)
+
+	registry.Publish("channel", Event{Kind: "1"})
+	registry.Publish("channel", Event{Kind: "2"})
+	registry.Publish("other", Event{Kind: "other"})
+
+	events, cursor = registry.EventsSince("channel", 0)
+	assert.Equal(t, []Event{{Kind: "1"}, {Kind: "2"}}, events)
+	assert.Equal(t, uint64(2), cursor)
+
+	events, cursor = registry.EventsSince("channel", 1)
+	assert.Equal(t, []Event{{Kind: "2"}}, events)
+	assert.Equal(t, uint64(2), cursor)
+
+	events, cursor = registry.EventsSince("channel", 2)
+	assert.Empty(t, events)
+	assert.Equal(t, uint64(2), cursor)
+
+	// Once the history is full the oldest events are discarded.
+	registry.Publish("channel", Event{Kind: "3"})
+	registry.Publish("channel", Event{Kind: "4"})
+	registry.Publish("channel", Event{Kind: "5"})
+
+	events, cursor = registry.EventsSince("channel", 0)
+	assert.Equal(t, []Event{{Kind: "3"}, {Kind: "4"}, {Kind: "5"}}, events)
+	assert.Equal(t, uint64(5), cursor)
+
+	events, cursor = registry.EventsSince("channel", 3)
+	assert.Equal(t, []Event{{Kind: "4"}, {Kind: "5"}}, events)
+	assert.Equal(t, uint64(5), cursor)
+
+	// A cursor from the future returns the entire history.
+	events, cursor = registry.EventsSince("channel", 100)
+	assert.Equal(t, []Event{{Kind: "3"}, {Kind: "4"}, {Kind: "5"}}, events)
+	assert.Equal(t, uint64(5), cursor)
+
+	// Other channels have their own history.
+	events, cursor = registry.EventsSince("other", 0)
+	assert.Equal(t, []Event{{Kind: "other"}}, events)
+	assert.Equal(t, uint64(1), cursor)
+}
+
+func TestRegistry_Poll(t *testing.T) {
+	registry := new(Registry)
+	registry.Publish("channel", Event{Kind: "1"})
+
+	// Events that are already available are returned immediately.
+	events, cursor := registry.Poll(context.Background(), "channel", 0, time.Hour)
+	assert.Equal(t, []Event{{Kind: "1"}}, events)
+	assert.Equal(t, uint64(1), cursor)
+
+	// Otherwise the poll waits for the next event to be published.
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		registry.Publish("channel", Event{Kind: "2"})
+	}()
+
+	events, cursor = registry.Poll(context.Background(), "channel", 1, time.Hour)
+	assert.Equal(t, []Event{{Kind: "2"}}, events)
+	assert.Equal(t, uint64(2), cursor)
+
+	// Or until the timeout elapses.
+	events, cursor = registry.Poll(context.Background(), "channel", 2, 10*time.Millisecond)
+	assert.Empty(t, events)
+	assert.Equal(t, uint64(2), cursor)
+
+	// Or the context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	events, cursor = registry.Poll(ctx, "channel", 2, time.Hour)
+	assert.Empty(t, events)
+	assert.Equal(t, uint64(2), cursor)
+
+	// The poll doesn't leave a subscription behind.
+	assert.Empty(t, registry.functions)
+}
+
+func TestRegistry_PruneHistories(t *testing.T) {
+	timeout, interval := HistoryIdleTimeout, HistoryPruneInterval
+	HistoryIdleTimeout = 20 * time.Millisecond
+	HistoryPruneInterval = 5 * time.Millisecond
+	t.Cleanup(func() { HistoryIdleTimeout, HistoryPruneInterval = timeout, interval })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	registry := new(Registry)
+	registry.StartHistoryPruner(ctx)
+	registry.Publish("idle", Event{Kind: "1"})
+	registry.Publish("subscribed", Event{Kind: "1"})
+
+	stream := make(chan Event, 10)
+	_, err := registry.Subscribe("subscribed", stream)
+	assert.NoError(t, err)
+
+	// Once idle for long enough, channels without subscribers lose their
+	// history.
+	time.Sleep(4 * HistoryIdleTimeout)
+	registry.Publish("other", Event{Kind: "1"})
+
+	events, _ := registry.EventsSince("idle", 0)
+	assert.Empty(t, events)
+
+	events, _ = registry.EventsSince("subscribed", 0)
+	assert.Equal(t, []Event{{Kind: "1"}}, events)
+
+	events, _ = registry.EventsSince("other", 0)
+	assert.Equal(t, []Event{{Kind: "1"}}, events)
+}
+
+func TestRegistry_MaxHistories(t *testing.T) {
+	max := MaxHistories
+	MaxHistories = 2
+	t.Cleanup(func() { MaxHistories = max })
+
+	registry := new(Registry)
+	registry.Publish("first", Event{Kind: "1"})
+	time.Sleep(time.Millisecond)
+	registry.Publish("second", Event{Kind: "1"})
+	time.Sleep(time.Millisecond)
+
+	// Reading the first channel's history makes the second channel's the least
+	// recently accessed, so it's the one discarded to make room for another.
+	registry.EventsSince("first", 0)
+	registry.Publish("third", Event{Kind: "1"})
+
+	events, _ := registry.EventsSince("second", 0)
+	assert.Empty(t, events)
+
+	for _, channel := range []Channel{"first", "third"} {
+		events, _ = registry.EventsSince(channel, 0)
+		assert.Equal(t, []Event{{Kind: "1"}}, events)
+	}
+}
+
+func TestRegistry_ClearHistory(t *testing.T) {
+	registry := new(Registry)
+	registry.Publish("channel", Event{Kind: "1"})
+	registry.Publish("other", Event{Kind: "1"})
+
+	registry.ClearHistory("channel")
+
+	events, cursor := registry.EventsSince("channel", 0)
+	assert.Empty(t, events)
+	assert.Equal(t, uint64(0), cursor)
+
+	events, _ = registry.EventsSince("other", 0)
+	assert.Equal(t, []Event{{Kind: "1"}}, events)
+}
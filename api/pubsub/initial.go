@@ -0,0 +1,94 @@
+package pubsub
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// InitialEventsAttempts is the number of times that the initial events of a
+// stream are attempted to be loaded before the client is told about the
+// failure.
+var InitialEventsAttempts = 3
+
+// InitialEventsRetryDelay is the amount of time to wait between attempts to
+// load the initial events of a stream.
+var InitialEventsRetryDelay = 100 * time.Millisecond
+
+// InitialEventsRecoveryInterval is the amount of time to wait between attempts
+// to load the initial events of a stream after the client has been told about
+// the failure.
+var InitialEventsRecoveryInterval = 5 * time.Second
+
+// ErrorEvent constructs an event that tells a client that something went wrong
+// while preparing its stream.  The stream remains open after an error event is
+// sent.
+func ErrorEvent(message string) Event {
+	return Event{
+		Kind:    "error",
+		Payload: map[string]string{"message": message},
+	}
+}
+
+// SendInitialEvents loads the events that a stream should start with and sends
+// them to the stream.  Loading is retried a few times in case of a transient
+// failure (such as a blip in the availability of the database).  If the events
+// still can't be loaded then an error event is sent to the stream instead and
+// loading continues in the background until it either succeeds or the context
+// is done.  This allows the client to keep its connection open and recover
+// once the underlying problem has been fixed.
+//
+// The returned channel is closed once no more events will be sent to the
+// stream.  Callers must wait for it to be closed before closing the stream.
+func SendInitialEvents(ctx context.Context, stream chan<- Event, load func() ([]Event, error)) <-chan struct{} {
+	done := make(chan struct{})
+
+	var events []Event
+	var err error
+	for attempt := 0; attempt < InitialEventsAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(InitialEventsRetryDelay)
+		}
+
+		if events, err = load(); err == nil {
+			for _, event := range events {
+				stream <- event
+			}
+
+			close(done)
+			return done
+		}
+	}
+
+	log.Printf("unable to load initial events: %+v", err)
+	stream <- ErrorEvent("unable to load the current state, retrying")
+
+	go func() {
+		defer close(done)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(InitialEventsRecoveryInterval):
+			}
+
+			events, err := load()
+			if err != nil {
+				log.Printf("unable to load initial events: %+v", err)
+				continue
+			}
+
+			for _, event := range events {
+				select {
+				case <-ctx.Done():
+					return
+				case stream <- event:
+				}
+			}
+			return
+		}
+	}()
+
+	return done
+}
@@ -0,0 +1,108 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestSendInitialEvents(t *testing.T) {
+	stream := make(chan Event, 10)
+	load := func() ([]Event, error) {
+		return []Event{{Kind: "settings"}, {Kind: "state"}}, nil
+	}
+
+	done := SendInitialEvents(context.Background(), stream, load)
+	<-done
+
+	require.Equal(t, 2, len(stream))
+	assert.Equal(t, "settings", (<-stream).Kind)
+	assert.Equal(t, "state", (<-stream).Kind)
+}
+
+func TestSendInitialEvents_TransientError(t *testing.T) {
+	ForceInitialEventsTiming(t)
+
+	// The first attempt fails, but a retry succeeds so the client never sees the
+	// error.
+	var calls int
+	stream := make(chan Event, 10)
+	load := func() ([]Event, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("forced error")
+		}
+		return []Event{{Kind: "settings"}}, nil
+	}
+
+	done := SendInitialEvents(context.Background(), stream, load)
+	<-done
+
+	assert.Equal(t, 2, calls)
+	require.Equal(t, 1, len(stream))
+	assert.Equal(t, "settings", (<-stream).Kind)
+}
+
+func TestSendInitialEvents_Recovery(t *testing.T) {
+	ForceInitialEventsTiming(t)
+
+	// Loading fails until enough attempts have been made that the client is
+	// told about the error, then starts succeeding.
+	failures := InitialEventsAttempts + 2
+	var calls int
+	stream := make(chan Event, 10)
+	load := func() ([]Event, error) {
+		calls++
+		if calls <= failures {
+			return nil, errors.New("forced error")
+		}
+		return []Event{{Kind: "settings"}}, nil
+	}
+
+	done := SendInitialEvents(context.Background(), stream, load)
+	assert.Equal(t, "error", (<-stream).Kind)
+
+	<-done
+	assert.Equal(t, failures+1, calls)
+	require.Equal(t, 1, len(stream))
+	assert.Equal(t, "settings", (<-stream).Kind)
+}
+
+func TestSendInitialEvents_ContextDone(t *testing.T) {
+	ForceInitialEventsTiming(t)
+
+	stream := make(chan Event, 10)
+	load := func() ([]Event, error) {
+		return nil, errors.New("forced error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := SendInitialEvents(ctx, stream, load)
+	assert.Equal(t, "error", (<-stream).Kind)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		assert.Fail(t, "background loading didn't stop")
+	}
+	assert.Equal(t, 0, len(stream))
+}
+
+// ForceInitialEventsTiming shortens the amount of time spent waiting between
+// attempts to load initial events.
+func ForceInitialEventsTiming(t *testing.T) {
+	t.Helper()
+
+	retryDelay := InitialEventsRetryDelay
+	recoveryInterval := InitialEventsRecoveryInterval
+	InitialEventsRetryDelay = time.Millisecond
+	InitialEventsRecoveryInterval = time.Millisecond
+	t.Cleanup(func() {
+		InitialEventsRetryDelay = retryDelay
+		InitialEventsRecoveryInterval = recoveryInterval
+	})
+}
@@ -0,0 +1,112 @@
+package pubsub
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// RecordedEvent is an event that was published to a channel of a registry
+// along with the time that it was published.  Recorded events are serialized
+// one per line as JSON.
+type RecordedEvent struct {
+	Time    time.Time       `json:"time"`
+	Channel Channel         `json:"channel"`
+	Kind    string          `json:"kind"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Record subscribes to a channel of the registry and writes every event that's
+// published to it to the provided io.Writer as a RecordedEvent.  The recording
+// doesn't count towards the channel's limit of subscribers so it never takes a
+// slot away from a client.  Record will block until either the provided
+// context is done or an error occurs while writing an event.
+func Record(ctx context.Context, registry *Registry, channel Channel, w io.Writer) error {
+	if channel == "" {
+		return errors.New("empty channel")
+	}
+
+	stream := make(chan Event, 100)
+
+	id, err := registry.SubscribeMatching(func(c Channel, e Event) bool {
+		return c == channel
+	}, stream)
+	defer registry.Unsubscribe(id)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event := <-stream:
+			var payload json.RawMessage
+			if event.Payload != nil {
+				bs, err := json.Marshal(event.Payload)
+				if err != nil {
+					return fmt.Errorf("unable to marshal payload of %s event: %w", event.Kind, err)
+				}
+				payload = bs
+			}
+
+			recorded := RecordedEvent{
+				Time:    time.Now(),
+				Channel: channel,
+				Kind:    event.Kind,
+				Payload: payload,
+			}
+			if err := encoder.Encode(recorded); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Replay reads events that were previously written by Record and publishes
+// them to the registry again.  The events are published with the same amount
+// of time between them as when they were originally recorded.  Replay will
+// block until either all of the events have been published, the provided
+// context is done, or an error occurs while reading an event.
+func Replay(ctx context.Context, registry *Registry, r io.Reader) error {
+	var last time.Time
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var recorded RecordedEvent
+		if err := json.Unmarshal(scanner.Bytes(), &recorded); err != nil {
+			return fmt.Errorf("unable to parse recorded event: %w", err)
+		}
+
+		// Wait the same amount of time that passed between this event and the
+		// previous one when they were recorded.
+		if !last.IsZero() && recorded.Time.After(last) {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(recorded.Time.Sub(last)):
+			}
+		}
+		last = recorded.Time
+
+		event := Event{Kind: recorded.Kind}
+		if recorded.Payload != nil {
+			event.Payload = recorded.Payload
+		}
+
+		registry.Publish(recorded.Channel, event)
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,108 @@
+package pubsub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	// Record a few events published to a channel.
+	registry := new(Registry)
+
+	var buffer bytes.Buffer
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- Record(ctx, registry, "channel", &buffer)
+	}()
+
+	// Wait for the recorder to subscribe to the registry.
+	require.Eventually(t, func() bool {
+		registry.Lock()
+		defer registry.Unlock()
+		return len(registry.streams) == 1
+	}, time.Second, time.Millisecond)
+
+	registry.Publish("channel", Event{Kind: "state", Payload: map[string]int{"a": 1}})
+	registry.Publish("other", Event{Kind: "state", Payload: "ignored"})
+	time.Sleep(100 * time.Millisecond)
+	registry.Publish("channel", Event{Kind: "complete"})
+
+	// Give the recorder a chance to write the events before stopping it.
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	require.Equal(t, 2, len(lines))
+
+	// Replay the recorded events into a different registry and make sure they
+	// arrive with the same content and timing.
+	registry = new(Registry)
+	stream := make(chan Event, 10)
+	_, err := registry.Subscribe("channel", stream)
+	require.NoError(t, err)
+
+	start := time.Now()
+	require.NoError(t, Replay(context.Background(), registry, &buffer))
+	elapsed := time.Since(start)
+
+	require.Equal(t, 2, len(stream))
+
+	first := <-stream
+	assert.Equal(t, "state", first.Kind)
+	bs, err := json.Marshal(first.Payload)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a":1}`, string(bs))
+
+	second := <-stream
+	assert.Equal(t, "complete", second.Kind)
+	assert.Nil(t, second.Payload)
+
+	// The second event was recorded 100ms after the first.
+	assert.True(t, elapsed >= 90*time.Millisecond, "elapsed: %s", elapsed)
+}
+
+func TestRecord_DoesNotCountAsSubscriber(t *testing.T) {
+	registry := &Registry{MaxSubscribersPerChannel: 1}
+
+	var buffer bytes.Buffer
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- Record(ctx, registry, "channel", &buffer)
+	}()
+
+	// Wait for the recorder to subscribe to the registry.
+	require.Eventually(t, func() bool {
+		registry.Lock()
+		defer registry.Unlock()
+		return len(registry.streams) == 1
+	}, time.Second, time.Millisecond)
+
+	// A client can still subscribe to the channel while it's being recorded.
+	id, err := registry.Subscribe("channel", make(chan Event, 10))
+	require.NoError(t, err)
+	registry.Unsubscribe(id)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestRecord_EmptyChannel(t *testing.T) {
+	var buffer bytes.Buffer
+	assert.Error(t, Record(context.Background(), new(Registry), "", &buffer))
+}
+
+func TestReplay_Error(t *testing.T) {
+	registry := new(Registry)
+
+	err := Replay(context.Background(), registry, strings.NewReader("{"))
+	assert.Error(t, err)
+}
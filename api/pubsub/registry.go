@@ -6,6 +6,15 @@ import (
 	"sync"
 )
 
+// ErrTooManySubscribers is returned when attempting to subscribe to a channel
+// that already has the maximum number of subscribers.
+var ErrTooManySubscribers = errors.New("too many subscribers")
+
+// SubscriberRetryAfter is the number of seconds that a client that was turned
+// away because a channel had too many subscribers should wait before trying
+// again.
+var SubscriberRetryAfter = "30"
+
 // Event encapsulates an event that can be sent to all subscribed clients of a
 // registry.
 type Event struct {
@@ -26,10 +35,28 @@ type ClientID string
 // a channel using an identifier.  This identifier can be used by the client to
 // unsubscribe to stop receiving any future events.  The registry is safe to
 // access from multiple goroutines.
+//
+// The number of clients that can subscribe to a single channel can be limited
+// by setting MaxSubscribersPerChannel.  When 0 (the default) the number of
+// subscribers is unlimited.
+//
+// The most recent events published to each channel are retained so that
+// clients which can't hold a stream open can poll for them instead.  The number
+// of events retained per channel can be set with HistorySize, when 0 (the
+// default) DefaultHistorySize events are retained.  At most MaxHistories
+// channels have a history at once, and once StartHistoryPruner has been called
+// the histories of channels without any subscribers are discarded once they've
+// been idle for HistoryIdleTimeout.
 type Registry struct {
 	sync.Mutex
+	MaxSubscribersPerChannel int
+	HistorySize              int
+
 	functions map[ClientID]func(Channel, Event) bool
 	streams   map[ClientID]chan<- Event
+	channels  map[ClientID]Channel
+	counts    map[Channel]int
+	histories map[Channel]*history
 }
 
 // Subscribe adds a new client stream for a particular channel.  The provided
@@ -42,6 +69,9 @@ type Registry struct {
 // already in it so that a set of initialization events can be sent to the
 // client before any published events.
 //
+// If the channel already has MaxSubscribersPerChannel subscribers then
+// ErrTooManySubscribers is returned and the client isn't subscribed.
+//
 // NOTE: The passed in stream should not be closed prior to the client being
 // unsubscribed from the registry.
 func (r *Registry) Subscribe(channel Channel, stream chan<- Event) (ClientID, error) {
@@ -53,7 +83,7 @@ func (r *Registry) Subscribe(channel Channel, stream chan<- Event) (ClientID, er
 		return c == channel
 	}
 
-	return r.SubscribeMatching(fn, stream)
+	return r.subscribe(channel, fn, stream)
 }
 
 // SubscribeMatching adds a new client stream for all events published that are
@@ -71,6 +101,13 @@ func (r *Registry) Subscribe(channel Channel, stream chan<- Event) (ClientID, er
 // NOTE: The passed in stream should not be closed prior to the client being
 // unsubscribed from the registry.
 func (r *Registry) SubscribeMatching(fn func(Channel, Event) bool, stream chan<- Event) (ClientID, error) {
+	return r.subscribe("", fn, stream)
+}
+
+// subscribe adds a new client stream for all events that match the provided
+// function.  If a channel is provided then the subscription counts towards
+// the limit of subscribers for that channel.
+func (r *Registry) subscribe(channel Channel, fn func(Channel, Event) bool, stream chan<- Event) (ClientID, error) {
 	if fn == nil {
 		return "", errors.New("empty channel function")
 	}
@@ -90,6 +127,22 @@ func (r *Registry) SubscribeMatching(fn func(Channel, Event) bool, stream chan<-
 	r.Lock()
 	defer r.Unlock()
 
+	if channel != "" {
+		if r.MaxSubscribersPerChannel > 0 && r.counts[channel] >= r.MaxSubscribersPerChannel {
+			return "", ErrTooManySubscribers
+		}
+
+		if r.channels == nil {
+			r.channels = make(map[ClientID]Channel)
+		}
+		r.channels[id] = channel
+
+		if r.counts == nil {
+			r.counts = make(map[Channel]int)
+		}
+		r.counts[channel]++
+	}
+
 	if r.functions == nil {
 		r.functions = make(map[ClientID]func(Channel, Event) bool)
 	}
@@ -111,14 +164,49 @@ func (r *Registry) Unsubscribe(id ClientID) {
 
 	delete(r.functions, id)
 	delete(r.streams, id)
+
+	if channel, ok := r.channels[id]; ok {
+		delete(r.channels, id)
+
+		r.counts[channel]--
+		if r.counts[channel] <= 0 {
+			delete(r.counts, channel)
+		}
+	}
+}
+
+// NumSubscribers returns the number of clients currently subscribed to a
+// particular channel.
+func (r *Registry) NumSubscribers(channel Channel) int {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.counts[channel]
 }
 
 // Publish sends an event to all subscribed clients of a given channel.  If a
-// client's stream is full the event will be skipped.
+// client's stream is full the event will be skipped.  The event is also added
+// to the channel's history.
 func (r *Registry) Publish(channel Channel, event Event) {
 	r.Lock()
 	defer r.Unlock()
 
+	size := r.HistorySize
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+
+	if r.histories == nil {
+		r.histories = make(map[Channel]*history)
+	}
+	if r.histories[channel] == nil {
+		if MaxHistories > 0 && len(r.histories) >= MaxHistories {
+			r.evictHistory()
+		}
+		r.histories[channel] = new(history)
+	}
+	r.histories[channel].add(event, size)
+
 	for id, fn := range r.functions {
 		if fn(channel, event) {
 			stream := r.streams[id]
@@ -62,6 +62,47 @@ func TestRegistry_SubscribeMatching_Error(t *testing.T) {
 	}
 }
 
+func TestRegistry_Subscribe_MaxSubscribersPerChannel(t *testing.T) {
+	registry := &Registry{MaxSubscribersPerChannel: 2}
+
+	id1, err := registry.Subscribe("channel", make(chan Event, 1))
+	require.NoError(t, err)
+	_, err = registry.Subscribe("channel", make(chan Event, 1))
+	require.NoError(t, err)
+	assert.Equal(t, 2, registry.NumSubscribers("channel"))
+
+	// The channel is full so the next subscriber is rejected.
+	_, err = registry.Subscribe("channel", make(chan Event, 1))
+	assert.Equal(t, ErrTooManySubscribers, err)
+	assert.Equal(t, 2, registry.NumSubscribers("channel"))
+
+	// Other channels and subscriptions to all channels aren't affected.
+	_, err = registry.Subscribe("other", make(chan Event, 1))
+	assert.NoError(t, err)
+	_, err = registry.SubscribeMatching(func(Channel, Event) bool { return true }, make(chan Event, 1))
+	assert.NoError(t, err)
+
+	// Unsubscribing frees up a slot, even when done more than once.
+	registry.Unsubscribe(id1)
+	registry.Unsubscribe(id1)
+	assert.Equal(t, 1, registry.NumSubscribers("channel"))
+
+	_, err = registry.Subscribe("channel", make(chan Event, 1))
+	assert.NoError(t, err)
+	_, err = registry.Subscribe("channel", make(chan Event, 1))
+	assert.Equal(t, ErrTooManySubscribers, err)
+}
+
+func TestRegistry_Subscribe_UnlimitedSubscribersPerChannel(t *testing.T) {
+	registry := new(Registry)
+
+	for i := 0; i < 100; i++ {
+		_, err := registry.Subscribe("channel", make(chan Event, 1))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 100, registry.NumSubscribers("channel"))
+}
+
 func TestRegistry_Unsubscribe_ClientStopsReceivingEvents(t *testing.T) {
 	registry := new(Registry)
 
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/acrostic"
+	"github.com/bbeck/puzzles-with-chat/api/crossword"
+	"github.com/bbeck/puzzles-with-chat/api/pubsub"
+	"github.com/bbeck/puzzles-with-chat/api/spellingbee"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// StartEventRecording starts recording the events of every channel listed in
+// the RECORD_EVENTS_CHANNELS environment variable (comma separated).  The
+// events for each puzzle type of a channel are appended to their own file in
+// the directory named by the RECORD_EVENTS_DIR environment variable, or the
+// current directory if it isn't set.
+//
+// Additionally if the REPLAY_EVENTS_FILE environment variable is set then the
+// events in that file are replayed into the registry.
+func StartEventRecording(ctx context.Context, registry *pubsub.Registry) {
+	dir := os.Getenv("RECORD_EVENTS_DIR")
+	if dir == "" {
+		dir = "."
+	}
+
+	for _, name := range strings.Split(os.Getenv("RECORD_EVENTS_CHANNELS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		channels := []pubsub.Channel{
+			acrostic.ChannelID(name),
+			crossword.ChannelID(name),
+			spellingbee.ChannelID(name),
+		}
+		for _, channel := range channels {
+			filename := filepath.Join(dir, fmt.Sprintf("%s.events", strings.ReplaceAll(string(channel), ":", "-")))
+			f, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				log.Printf("unable to open event recording file %s: %+v", filename, err)
+				continue
+			}
+
+			go func(channel pubsub.Channel, f *os.File) {
+				defer func() { _ = f.Close() }()
+
+				log.Printf("recording events for channel %s to %s", channel, f.Name())
+				if err := pubsub.Record(ctx, registry, channel, f); err != nil {
+					log.Printf("error while recording events for channel %s: %+v", channel, err)
+				}
+			}(channel, f)
+		}
+	}
+
+	if filename := os.Getenv("REPLAY_EVENTS_FILE"); filename != "" {
+		f, err := os.Open(filename)
+		if err != nil {
+			log.Printf("unable to open event replay file %s: %+v", filename, err)
+			return
+		}
+
+		go func() {
+			defer func() { _ = f.Close() }()
+
+			log.Printf("replaying events from %s", filename)
+			if err := pubsub.Replay(ctx, registry, f); err != nil {
+				log.Printf("error while replaying events from %s: %+v", filename, err)
+			}
+		}()
+	}
+}
@@ -2,20 +2,30 @@ package main
 
 import (
 	"github.com/bbeck/puzzles-with-chat/api/acrostic"
+	"github.com/bbeck/puzzles-with-chat/api/admin"
 	"github.com/bbeck/puzzles-with-chat/api/crossword"
 	"github.com/bbeck/puzzles-with-chat/api/model"
 	"github.com/bbeck/puzzles-with-chat/api/pubsub"
 	"github.com/bbeck/puzzles-with-chat/api/spellingbee"
 	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
 	"github.com/gomodule/redigo/redis"
 	"log"
 	"net/http"
+	"strconv"
 	"testing"
 	"time"
 )
 
 func RegisterRoutes(r chi.Router, pool *redis.Pool, registry *pubsub.Registry) {
+	r.Get("/active", GetActiveActivity(pool))
 	r.Get("/channels", GetChannels(pool, registry))
+	r.Get("/recent-completions", GetRecentCompletions(pool))
+	r.Get("/completions/stream", GetCompletionsStream(registry))
+
+	r.With(admin.Required).Post("/transfer", TransferChannel(pool, registry))
+	r.With(admin.Required).Get("/admin/channel/{channel}/keys", GetChannelKeys(pool))
+	r.With(admin.Required).Delete("/admin/channel/{channel}", EvictChannel(pool, registry))
 }
 
 // GetChannels establishes a SSE based stream with a client that contains the
@@ -111,6 +121,128 @@ func GetChannels(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
 	}
 }
 
+// GetCompletionsStream establishes a SSE based stream with a client that
+// contains an event for each puzzle that's completed across all channels and
+// puzzle types.  No other events are sent to the stream.
+func GetCompletionsStream(registry *pubsub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Construct the stream that all events for this particular client will be
+		// placed into.
+		stream := make(chan pubsub.Event, 10)
+		defer close(stream)
+
+		id, err := registry.SubscribeMatching(func(channel pubsub.Channel, event pubsub.Event) bool {
+			return event.Kind == model.CompletionEventKind
+		}, stream)
+		defer registry.Unsubscribe(id)
+		if err != nil {
+			log.Printf("unable to subscribe client to completions: %+v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		pubsub.EmitEvents(r.Context(), w, stream)
+	}
+}
+
+// GetActiveActivity returns the channels that have recently changed the state
+// of a puzzle across all puzzle types along with when they last did so.  The
+// channels are ordered from the most recent change to the least recent.
+func GetActiveActivity(pool *redis.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		activities, err := GetRecentActivity(conn)
+		if err != nil {
+			log.Printf("unable to load recent channel activity: %+v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		render.JSON(w, r, activities)
+	}
+}
+
+// GetRecentActivity loads from the database the channels that have recently
+// changed the state of a puzzle.  If the activity can't be loaded then an error
+// is returned.
+func GetRecentActivity(conn redis.Conn) ([]model.Activity, error) {
+	if testRecentActivityLoadError != nil {
+		return nil, testRecentActivityLoadError
+	}
+
+	return model.GetRecentActivity(conn)
+}
+
+var testRecentActivityLoadError error
+
+// ForceErrorDuringRecentActivityLoad sets up an error to be returned when an
+// attempt is made to load recent channel activity.
+func ForceErrorDuringRecentActivityLoad(t *testing.T, err error) {
+	t.Helper()
+
+	testRecentActivityLoadError = err
+	t.Cleanup(func() { testRecentActivityLoadError = nil })
+}
+
+// DefaultRecentCompletionsLimit is the number of completions returned by
+// GetRecentCompletions when the request doesn't specify a limit.
+const DefaultRecentCompletionsLimit = 10
+
+// GetRecentCompletions returns the puzzles that have most recently been
+// completed across all channels and puzzle types.  The completions are ordered
+// from the most recent to the least recent.  The number of completions returned
+// can be controlled with the limit query parameter.
+func GetRecentCompletions(pool *redis.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := DefaultRecentCompletionsLimit
+		if s := r.URL.Query().Get("limit"); s != "" {
+			var err error
+			limit, err = strconv.Atoi(s)
+			if err != nil || limit <= 0 {
+				log.Printf("malformed limit (%s): %+v", s, err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		completions, err := LoadRecentCompletions(conn, limit)
+		if err != nil {
+			log.Printf("unable to load recent completions: %+v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		render.JSON(w, r, completions)
+	}
+}
+
+// LoadRecentCompletions loads from the database up to limit of the puzzles that
+// have most recently been completed.  If the completions can't be loaded then
+// an error is returned.
+func LoadRecentCompletions(conn redis.Conn, limit int) ([]model.Completion, error) {
+	if testRecentCompletionsLoadError != nil {
+		return nil, testRecentCompletionsLoadError
+	}
+
+	return model.GetRecentCompletions(conn, limit)
+}
+
+var testRecentCompletionsLoadError error
+
+// ForceErrorDuringRecentCompletionsLoad sets up an error to be returned when an
+// attempt is made to load the recent completions.
+func ForceErrorDuringRecentCompletionsLoad(t *testing.T, err error) {
+	t.Helper()
+
+	testRecentCompletionsLoadError = err
+	t.Cleanup(func() { testRecentCompletionsLoadError = nil })
+}
+
 // Changed compares two sets of active channels and determines if anything has
 // changed or not.
 func Changed(before, after map[string][]model.Channel) bool {
@@ -19,6 +19,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -263,6 +264,227 @@ func TestRoute_GetChannels_Error(t *testing.T) {
 	}
 }
 
+func TestRoute_GetActiveActivity(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+
+	// With no solves there shouldn't be any activity.
+	response := GET("/active", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Empty(t, ParseActivity(t, response))
+
+	// Start a crossword and then a spelling bee.  The sleeps ensure that each
+	// change has a distinct timestamp.
+	state1 := crossword.NewState(t, "xwordinfo-nyt-20181231.json")
+	state1.Status = model.StatusSolving
+	require.NoError(t, crossword.SetState(conn, "channel1", state1))
+	time.Sleep(5 * time.Millisecond)
+
+	state2 := spellingbee.NewState(t, "nytbee-20180729.json")
+	state2.Status = model.StatusSolving
+	require.NoError(t, spellingbee.SetState(conn, "channel2", state2))
+
+	response = GET("/active", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	activities := ParseActivity(t, response)
+	require.Len(t, activities, 2)
+	assert.Equal(t, "spellingbee", activities[0].Type)
+	assert.Equal(t, "channel2", activities[0].Name)
+	assert.Equal(t, "crossword", activities[1].Type)
+	assert.Equal(t, "channel1", activities[1].Name)
+	assert.False(t, activities[0].LastActivity.Before(activities[1].LastActivity))
+
+	// Changing the crossword should bump its channel to the top.
+	time.Sleep(5 * time.Millisecond)
+	state1.Cells[0][0] = "Q"
+	require.NoError(t, crossword.SetState(conn, "channel1", state1))
+
+	response = GET("/active", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	activities = ParseActivity(t, response)
+	require.Len(t, activities, 2)
+	assert.Equal(t, "crossword", activities[0].Type)
+	assert.Equal(t, "channel1", activities[0].Name)
+	assert.Equal(t, "spellingbee", activities[1].Type)
+	assert.Equal(t, "channel2", activities[1].Name)
+	assert.True(t, activities[0].LastActivity.After(activities[1].LastActivity))
+}
+
+func TestRoute_GetActiveActivity_Error(t *testing.T) {
+	router, _, _ := NewTestRouter(t)
+	ForceErrorDuringRecentActivityLoad(t, errors.New("forced error"))
+
+	response := GET("/active", router)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
+func TestRoute_GetRecentCompletions(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	crossword.RegisterRoutes(router, pool, registry)
+	spellingbee.RegisterRoutes(router, pool, registry)
+	conn := NewRedisConnection(t, pool)
+
+	// With no completions the list should be empty.
+	response := GET("/recent-completions", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Empty(t, ParseCompletions(t, response))
+
+	// Solve the crossword in each channel by filling in every cell except the
+	// first one and then answering the first across clue.  The sleep ensures
+	// that each completion has a distinct timestamp.
+	complete := func(channel string) {
+		state := crossword.NewState(t, "xwordinfo-nyt-20181231.json")
+		state.Status = model.StatusSolving
+		for y := range state.Cells {
+			copy(state.Cells[y], state.Puzzle.Cells[y])
+		}
+		state.Cells[0][0] = ""
+		require.NoError(t, crossword.SetState(conn, channel, state))
+
+		answer, err := state.Puzzle.Answer(1, "a")
+		require.NoError(t, err)
+
+		response := PUT("/crossword/"+channel+"/answer/1a", `"`+answer+`"`, router)
+		require.Equal(t, http.StatusOK, response.Code)
+	}
+
+	complete("channel1")
+	time.Sleep(5 * time.Millisecond)
+	complete("channel2")
+
+	response = GET("/recent-completions", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	completions := ParseCompletions(t, response)
+	require.Len(t, completions, 2)
+	assert.Equal(t, "channel2", completions[0].Channel)
+	assert.Equal(t, "channel1", completions[1].Channel)
+	assert.True(t, completions[0].Time.After(completions[1].Time))
+
+	for _, completion := range completions {
+		assert.Equal(t, "crossword", completion.Type)
+		assert.Equal(t, "The New York Times", completion.Publisher)
+		assert.NotEmpty(t, completion.Title)
+		assert.True(t, completion.Duration.Duration > 0)
+	}
+
+	// The limit restricts the number of completions returned.
+	response = GET("/recent-completions?limit=1", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	completions = ParseCompletions(t, response)
+	require.Len(t, completions, 1)
+	assert.Equal(t, "channel2", completions[0].Channel)
+
+	// Solve the spelling bee in another channel by applying every answer except
+	// for the last one and then adding the last one through the route.  The
+	// sleep ensures that a non-zero amount of time has passed in the solve.
+	state := spellingbee.NewState(t, "nytbee-20200408.html")
+	state.Status = model.StatusSolving
+	answers := state.Puzzle.OfficialAnswers
+	for _, answer := range answers[:len(answers)-1] {
+		require.NoError(t, state.ApplyAnswer(answer, false, nil, spellingbee.ScoringStandard))
+	}
+	require.NoError(t, spellingbee.SetState(conn, "channel3", state))
+
+	time.Sleep(5 * time.Millisecond)
+	response = POST("/spellingbee/channel3/answer", `"`+answers[len(answers)-1]+`"`, router)
+	require.Equal(t, http.StatusCreated, response.Code)
+
+	response = GET("/recent-completions", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	completions = ParseCompletions(t, response)
+	require.Len(t, completions, 3)
+	assert.Equal(t, "spellingbee", completions[0].Type)
+	assert.Equal(t, "channel3", completions[0].Channel)
+	assert.Equal(t, "The New York Times", completions[0].Publisher)
+	assert.True(t, completions[0].Duration.Duration > 0)
+}
+
+func TestRoute_GetRecentCompletions_Error(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		loadError error
+		expected  int
+	}{
+		{
+			name:     "malformed limit",
+			url:      "/recent-completions?limit=abc",
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "non-positive limit",
+			url:      "/recent-completions?limit=0",
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:      "error loading completions",
+			url:       "/recent-completions",
+			loadError: errors.New("forced error"),
+			expected:  http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, _, _ := NewTestRouter(t)
+			ForceErrorDuringRecentCompletionsLoad(t, test.loadError)
+
+			response := GET(test.url, router)
+			assert.Equal(t, test.expected, response.Code)
+		})
+	}
+}
+
+func TestRoute_GetCompletionsStream(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	crossword.RegisterRoutes(router, pool, registry)
+	conn := NewRedisConnection(t, pool)
+
+	// Connect to the stream, nothing has been completed yet so there shouldn't
+	// be any events.
+	flush, stop := SSE("/completions/stream", router)
+	assert.Empty(t, flush())
+
+	// Start a crossword with every cell except the first one filled in.
+	state := crossword.NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	for y := range state.Cells {
+		copy(state.Cells[y], state.Puzzle.Cells[y])
+	}
+	state.Cells[0][0] = ""
+	require.NoError(t, crossword.SetState(conn, "channel", state))
+
+	// Answer a clue that doesn't complete the puzzle, the state event that's
+	// published shouldn't be sent to the stream.
+	answer, err := state.Puzzle.Answer(2, "d")
+	require.NoError(t, err)
+
+	response := PUT("/crossword/channel/answer/2d", `"`+answer+`"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Empty(t, flush())
+
+	// Now complete the puzzle, we should receive just the completion.
+	answer, err = state.Puzzle.Answer(1, "a")
+	require.NoError(t, err)
+
+	response = PUT("/crossword/channel/answer/1a", `"`+answer+`"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	events := stop()
+	require.Len(t, events, 1)
+	assert.Equal(t, model.CompletionEventKind, events[0].Kind)
+
+	bs, err := json.Marshal(events[0].Payload)
+	require.NoError(t, err)
+
+	var completion model.Completion
+	require.NoError(t, json.Unmarshal(bs, &completion))
+	assert.Equal(t, "crossword", completion.Type)
+	assert.Equal(t, "channel", completion.Channel)
+	assert.Equal(t, state.Puzzle.Title, completion.Title)
+	assert.True(t, completion.Duration.Duration > 0)
+}
+
 func TestChanged(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -389,6 +611,13 @@ func GET(url string, router chi.Router) *httptest.ResponseRecorder {
 	return recorder
 }
 
+func PUT(url, body string, router chi.Router) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPut, url, strings.NewReader(body))
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
 // SSE performs a streaming request to the provided router.  Because the router
 // won't immediately return, this request is done in a background goroutine.
 // When the main thread wishes to read events that have been received thus far
@@ -515,3 +744,19 @@ func ParsePayload(t *testing.T, v interface{}) map[string][]model.Channel {
 
 	return payload
 }
+
+func ParseCompletions(t *testing.T, response *httptest.ResponseRecorder) []model.Completion {
+	t.Helper()
+
+	var completions []model.Completion
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&completions))
+	return completions
+}
+
+func ParseActivity(t *testing.T, response *httptest.ResponseRecorder) []model.Activity {
+	t.Helper()
+
+	var activities []model.Activity
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&activities))
+	return activities
+}
@@ -101,10 +101,7 @@ func ParseNYTBeeResponse(in io.Reader) (*Puzzle, error) {
 	}
 
 	// Add calculated values to the created puzzle object.
-	puzzle.MaximumOfficialScore = puzzle.ComputeScore(puzzle.OfficialAnswers)
-	puzzle.MaximumUnofficialScore = puzzle.MaximumOfficialScore + puzzle.ComputeScore(puzzle.UnofficialAnswers)
-	puzzle.NumOfficialAnswers = len(puzzle.OfficialAnswers)
-	puzzle.NumUnofficialAnswers = len(puzzle.UnofficialAnswers)
+	puzzle.SetMaximumScores(ScoringStandard, nil)
 
 	return puzzle, nil
 }
@@ -1,6 +1,9 @@
 package spellingbee
 
-import "time"
+import (
+	"sort"
+	"time"
+)
 
 // Puzzle represents a spelling bee puzzle.  The puzzle is comprised of a
 // circular grid of 6 letters around a single letter.  The goal is to use the
@@ -60,10 +63,38 @@ func (p *Puzzle) WithoutAnswers() *Puzzle {
 	return &puzzle
 }
 
-// ComputeScore calculates the score for the provided words taken together. No
-// checking is done to make sure the words are valid answers, they're all
-// assumed to be correct.
-func (p *Puzzle) ComputeScore(words []string) int {
+// Answers returns the sorted list of answers that are accepted for the puzzle.
+// The unofficial answers are only included when allowUnofficial is set, and
+// any of the blocked words are never included.
+func (p *Puzzle) Answers(allowUnofficial bool, blocked []string) []string {
+	var answers []string
+	answers = append(answers, without(p.OfficialAnswers, blocked)...)
+	if allowUnofficial {
+		answers = append(answers, without(p.UnofficialAnswers, blocked)...)
+	}
+	sort.Strings(answers)
+
+	return answers
+}
+
+// SetMaximumScores calculates the total number of points and answers possible
+// in the puzzle, both with and without the unofficial answers, using the
+// provided scoring.  Blocked words can never be given as answers so they don't
+// contribute to either.
+func (p *Puzzle) SetMaximumScores(scoring Scoring, blocked []string) {
+	official := without(p.OfficialAnswers, blocked)
+	unofficial := without(p.UnofficialAnswers, blocked)
+
+	p.MaximumOfficialScore = p.ComputeScore(official, scoring)
+	p.MaximumUnofficialScore = p.MaximumOfficialScore + p.ComputeScore(unofficial, scoring)
+	p.NumOfficialAnswers = len(official)
+	p.NumUnofficialAnswers = len(unofficial)
+}
+
+// ComputeScore calculates the score for the provided words taken together using
+// the provided scoring.  No checking is done to make sure the words are valid
+// answers, they're all assumed to be correct.
+func (p *Puzzle) ComputeScore(words []string, scoring Scoring) int {
 	isPangram := func(word string) bool {
 		letters := map[string]struct{}{
 			p.CenterLetter: {},
@@ -81,18 +112,35 @@ func (p *Puzzle) ComputeScore(words []string) int {
 
 	var score int
 	for _, word := range words {
-		if len(word) == 4 {
-			score += 1
-			continue
+		score += scoring.Score(word, isPangram(word))
+	}
+
+	return score
+}
+
+// without returns the words that aren't in the list of excluded words.
+func without(words []string, excluded []string) []string {
+	if len(excluded) == 0 {
+		return words
+	}
+
+	var remaining []string
+	for _, word := range words {
+		if !contains(excluded, word) {
+			remaining = append(remaining, word)
 		}
+	}
 
-		score += len(word)
+	return remaining
+}
 
-		// pangrams get a 7 point bonus
-		if isPangram(word) {
-			score += 7
+// contains determines if a word is present in an unsorted list of words.
+func contains(words []string, word string) bool {
+	for _, w := range words {
+		if w == word {
+			return true
 		}
 	}
 
-	return score
+	return false
 }
@@ -270,7 +270,65 @@ func TestPuzzle_ComputeScore(t *testing.T) {
 				Letters:      test.letters,
 			}
 
-			assert.Equal(t, test.expected, puzzle.ComputeScore(test.words))
+			assert.Equal(t, test.expected, puzzle.ComputeScore(test.words, ScoringStandard))
 		})
 	}
 }
+
+func TestPuzzle_ComputeScore_Scoring(t *testing.T) {
+	puzzle := &Puzzle{
+		CenterLetter: "T",
+		Letters:      []string{"C", "N", "O", "R", "U", "Y"},
+	}
+	words := []string{"RUNT", "COUNT", "COUNTRY"}
+
+	tests := []struct {
+		scoring  Scoring
+		expected int
+	}{
+		{scoring: ScoringStandard, expected: 1 + 5 + (7 + 7)},
+		{scoring: ScoringLengthWeighted, expected: 1 + 4 + (16 + 7)},
+		{scoring: ScoringFlatPangram, expected: 1 + 1 + (1 + FlatPangramBonus)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scoring.String(), func(t *testing.T) {
+			assert.Equal(t, test.expected, puzzle.ComputeScore(words, test.scoring))
+		})
+	}
+}
+
+func TestPuzzle_SetMaximumScores(t *testing.T) {
+	puzzle := &Puzzle{
+		CenterLetter:      "T",
+		Letters:           []string{"C", "N", "O", "R", "U", "Y"},
+		OfficialAnswers:   []string{"COUNT", "COUNTRY"},
+		UnofficialAnswers: []string{"RUNT"},
+	}
+
+	puzzle.SetMaximumScores(ScoringStandard, nil)
+	assert.Equal(t, 19, puzzle.MaximumOfficialScore)
+	assert.Equal(t, 20, puzzle.MaximumUnofficialScore)
+
+	puzzle.SetMaximumScores(ScoringLengthWeighted, nil)
+	assert.Equal(t, 27, puzzle.MaximumOfficialScore)
+	assert.Equal(t, 28, puzzle.MaximumUnofficialScore)
+
+	// Blocked words can't be given so they don't count towards the maximums.
+	puzzle.SetMaximumScores(ScoringStandard, []string{"COUNTRY", "RUNT"})
+	assert.Equal(t, 5, puzzle.MaximumOfficialScore)
+	assert.Equal(t, 5, puzzle.MaximumUnofficialScore)
+	assert.Equal(t, 1, puzzle.NumOfficialAnswers)
+	assert.Equal(t, 0, puzzle.NumUnofficialAnswers)
+}
+
+func TestPuzzle_Answers(t *testing.T) {
+	puzzle := &Puzzle{
+		OfficialAnswers:   []string{"COUNTRY", "COUNT"},
+		UnofficialAnswers: []string{"RUNT"},
+	}
+
+	assert.Equal(t, []string{"COUNT", "COUNTRY"}, puzzle.Answers(false, nil))
+	assert.Equal(t, []string{"COUNT", "COUNTRY", "RUNT"}, puzzle.Answers(true, nil))
+	assert.Equal(t, []string{"COUNT", "RUNT"}, puzzle.Answers(true, []string{"COUNTRY"}))
+}
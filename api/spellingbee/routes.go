@@ -2,7 +2,10 @@ package spellingbee
 
 import (
 	"compress/flate"
+	"context"
+	"errors"
 	"fmt"
+	"github.com/bbeck/puzzles-with-chat/api/db"
 	"github.com/bbeck/puzzles-with-chat/api/model"
 	"github.com/bbeck/puzzles-with-chat/api/pubsub"
 	"github.com/go-chi/chi"
@@ -11,7 +14,6 @@ import (
 	"github.com/gomodule/redigo/redis"
 	"log"
 	"math"
-	"math/rand"
 	"net/http"
 	"time"
 )
@@ -19,6 +21,7 @@ import (
 func RegisterRoutes(r chi.Router, pool *redis.Pool, registry *pubsub.Registry) {
 	r.Route("/spellingbee/{channel}", func(r chi.Router) {
 		r.Put("/", UpdatePuzzle(pool, registry))
+		r.Get("/settings", ReadSettings(pool))
 		r.Put("/setting/{setting}", UpdateSetting(pool, registry))
 		r.Get("/shuffle", ShuffleLetters(pool, registry))
 		r.Put("/status", ToggleStatus(pool, registry))
@@ -68,13 +71,19 @@ func UpdatePuzzle(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 		conn := pool.Get()
 		defer func() { _ = conn.Close() }()
 
-		// Save the puzzle to this channel's state
-		state := State{
-			Status:  model.StatusSelected,
-			Puzzle:  puzzle,
-			Letters: puzzle.Letters,
-			Words:   make(map[string]int),
+		// The maximum scores of the puzzle depend on how the channel awards points
+		// for answers.
+		settings, err := GetSettings(conn, channel)
+		if err != nil {
+			log.Printf("unable to load settings for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
 		}
+		puzzle.SetMaximumScores(settings.Scoring, settings.BlockedWords)
+
+		// Save the puzzle to this channel's state
+		var state State
+		state.resetEphemeralState(puzzle)
 		if err := SetState(conn, channel, state); err != nil {
 			log.Printf("unable to save state for channel %s: %+v", channel, err)
 			w.WriteHeader(http.StatusInternalServerError)
@@ -142,6 +151,32 @@ func UpdateSetting(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 			}
 			settings.ShowAnswerPlaceholders = value
 
+		case "scoring":
+			var value Scoring
+			if err := render.DecodeJSON(r.Body, &value); err != nil {
+				log.Printf("unable to parse spelling bee scoring setting json %s: %+v", value, err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			settings.Scoring = value
+			shouldRebuildWordMap = true
+
+		case "blocked_words":
+			var value []string
+			if err := render.DecodeJSON(r.Body, &value); err != nil {
+				log.Printf("unable to parse spelling bee blocked words setting json %v: %+v", value, err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			words, err := NormalizeBlockedWords(value)
+			if err != nil {
+				log.Printf("invalid spelling bee blocked words setting: %+v", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			settings.BlockedWords = words
+			shouldRebuildWordMap = true
+
 		default:
 			log.Printf("unrecognized spelling bee setting name %s", setting)
 			w.WriteHeader(http.StatusBadRequest)
@@ -168,10 +203,13 @@ func UpdateSetting(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 			}
 
 			// There's no need to update cells if the puzzle hasn't been selected or
-			// started or is already complete.
+			// started or is already complete.  A puzzle that has only been selected
+			// still needs its maximum scores updated when the scoring or blocked
+			// words change.
 			status := state.Status
-			if status != model.StatusCreated && status != model.StatusSelected && status != model.StatusComplete {
-				state.RebuildWordMap(settings.AllowUnofficialAnswers)
+			rebuild := status != model.StatusCreated && status != model.StatusSelected && status != model.StatusComplete
+			if rebuild || (status == model.StatusSelected && setting != "allow_unofficial_answers") {
+				state.RebuildWordMap(settings.AllowUnofficialAnswers, settings.BlockedWords, settings.Scoring)
 
 				// We may have just solved the puzzle -- if so then we should stop the
 				// timer before saving the state.
@@ -206,6 +244,7 @@ func UpdateSetting(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc
 			// If we did then we should also send a complete message.
 			if updatedState.Status == model.StatusComplete {
 				registry.Publish(ChannelID(channel), CompleteEvent())
+				announceCompletion(conn, registry, channel, *updatedState)
 			}
 		}
 
@@ -233,10 +272,7 @@ func ShuffleLetters(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFun
 			return
 		}
 
-		// Shuffle the letters.
-		rand.Shuffle(len(state.Letters), func(i, j int) {
-			state.Letters[i], state.Letters[j] = state.Letters[j], state.Letters[i]
-		})
+		state.ShuffleLetters()
 
 		// Save the updated state.
 		if err := SetState(conn, channel, state); err != nil {
@@ -257,6 +293,26 @@ func ShuffleLetters(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFun
 	}
 }
 
+// ReadSettings returns the spelling bee settings of a channel.  Channels that have
+// never changed a setting receive the default settings.
+func ReadSettings(pool *redis.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channel := chi.URLParam(r, "channel")
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		settings, err := GetSettings(conn, channel)
+		if err != nil {
+			log.Printf("unable to load settings for channel %s: %+v", channel, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		render.JSON(w, r, settings)
+	}
+}
+
 // ToggleStatus changes the status of the current puzzle solve to a new status.
 // This effectively toggles between the solving and paused statuses as long as
 // the solve is in a state that can be paused or resumed.
@@ -368,9 +424,9 @@ func AddAnswer(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
 		// threshold or not.
 		previous := state.Score
 
-		if err := state.ApplyAnswer(answer, settings.AllowUnofficialAnswers); err != nil {
+		if err := state.ApplyAnswer(answer, settings.AllowUnofficialAnswers, settings.BlockedWords, settings.Scoring); err != nil {
 			log.Printf("unable to apply answer %s for channel %s: %+v", answer, channel, err)
-			w.WriteHeader(http.StatusBadRequest)
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
@@ -411,12 +467,33 @@ func AddAnswer(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
 		// If we've just finished the solve then send a complete event as well.
 		if state.Status == model.StatusComplete {
 			registry.Publish(ChannelID(channel), CompleteEvent())
+			announceCompletion(conn, registry, channel, state)
 		}
 
 		w.WriteHeader(http.StatusCreated)
 	}
 }
 
+// announceCompletion records that the channel has just completed its spelling
+// bee and lets anyone watching for completions across all channels know.
+func announceCompletion(conn db.Connection, registry *pubsub.Registry, channel string, state State) {
+	completion := model.Completion{
+		Type:      "spellingbee",
+		Channel:   channel,
+		Publisher: "The New York Times",
+		Duration:  state.TotalSolveDuration,
+		Time:      time.Now(),
+	}
+	if state.Puzzle != nil {
+		completion.Title = state.Puzzle.Description
+	}
+
+	if err := model.RecordCompletion(conn, completion); err != nil {
+		log.Printf("unable to record completion for channel %s: %+v", channel, err)
+	}
+	registry.Publish(model.CompletionsChannel, model.CompletionEvent(completion))
+}
+
 // GetEvents establishes an event stream with a client.  An event stream is
 // server side event stream (SSE) with a client's browser that allows one way
 // communication from the server to the client.  Clients that call into this
@@ -432,45 +509,61 @@ func GetEvents(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
 		stream := make(chan pubsub.Event, 10)
 		defer close(stream)
 
-		// Setup a connection to redis so that we can read settings and the current
-		// state of the solve.
-		conn := pool.Get()
-		defer func() { _ = conn.Close() }()
+		// Load the settings and the current state of the solve (if there is one,
+		// but make sure to mask the solution to the puzzle).  These are always the
+		// first events sent to the client.
+		load := func() ([]pubsub.Event, error) {
+			conn := pool.Get()
+			defer func() { _ = conn.Close() }()
 
-		// Always send the settings if there are any.
-		settings, err := GetSettings(conn, channel)
-		if err != nil {
-			log.Printf("unable to read settings for channel %s: %+v", channel, err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-		stream <- SettingsEvent(settings)
+			settings, err := GetSettings(conn, channel)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read settings for channel %s: %w", channel, err)
+			}
+			events := []pubsub.Event{SettingsEvent(settings)}
 
-		// Send the current state of the solve if there is one, but make sure to
-		// mask the solution to the puzzle.
-		state, err := GetState(conn, channel)
-		if err != nil {
-			log.Printf("unable to read state for channel %s: %+v", channel, err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-		if state.Puzzle != nil {
-			state.Puzzle = state.Puzzle.WithoutAnswers()
+			state, err := GetState(conn, channel)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read state for channel %s: %w", channel, err)
+			}
+			if state.Puzzle != nil {
+				state.Puzzle = state.Puzzle.WithoutAnswers()
 
-			stream <- StateEvent(state)
+				events = append(events, StateEvent(state))
+			}
+
+			return events, nil
 		}
 
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		// If the initial events can't be loaded the client is sent an error event
+		// instead, but the stream stays open while loading is retried in the
+		// background.
+		done := pubsub.SendInitialEvents(ctx, stream, load)
+		defer func() {
+			cancel()
+			<-done
+		}()
+
 		// Now that we've seeded the stream with the initialization events,
 		// subscribe it to receive all future events for the channel.
 		id, err := registry.Subscribe(ChannelID(channel), stream)
 		defer registry.Unsubscribe(id)
+		if errors.Is(err, pubsub.ErrTooManySubscribers) {
+			log.Printf("too many clients subscribed to channel %s", channel)
+			w.Header().Set("Retry-After", pubsub.SubscriberRetryAfter)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
 		if err != nil {
 			log.Printf("unable to subscribe client to channel %s: %+v", channel, err)
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
-		pubsub.EmitEvents(r.Context(), w, stream)
+		pubsub.EmitEvents(ctx, w, stream)
 	}
 }
 
@@ -510,6 +603,9 @@ func SettingsEvent(settings Settings) pubsub.Event {
 }
 
 func StateEvent(state State) pubsub.Event {
+	// The seed stays on the server so that clients can't predict shuffles.
+	state.Seed = 0
+
 	return pubsub.Event{
 		Kind:    "state",
 		Payload: state,
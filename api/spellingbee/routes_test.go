@@ -3,6 +3,7 @@ package spellingbee
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -78,16 +79,22 @@ func TestRoute_UpdatePuzzle_JSONError(t *testing.T) {
 
 func TestRoute_UpdatePuzzle_LoadSaveError(t *testing.T) {
 	tests := []struct {
-		name                  string
-		forcedPuzzleLoadError error
-		forcedStateSaveError  error
-		expected              int
+		name                    string
+		forcedPuzzleLoadError   error
+		forcedSettingsLoadError error
+		forcedStateSaveError    error
+		expected                int
 	}{
 		{
 			name:                  "nytbee error loading puzzle",
 			forcedPuzzleLoadError: errors.New("forced error"),
 			expected:              http.StatusInternalServerError,
 		},
+		{
+			name:                    "error loading settings",
+			forcedSettingsLoadError: errors.New("forced error"),
+			expected:                http.StatusInternalServerError,
+		},
 		{
 			name:                  "error saving state",
 			forcedPuzzleLoadError: nil,
@@ -106,6 +113,7 @@ func TestRoute_UpdatePuzzle_LoadSaveError(t *testing.T) {
 				ForcePuzzleToBeLoaded(t, "nytbee-20200408.html")
 			}
 
+			ForceErrorDuringSettingsLoad(t, test.forcedSettingsLoadError)
 			ForceErrorDuringStateSave(t, test.forcedStateSaveError)
 
 			response := Channel.PUT("/", `{"new_york_times_date": "ignored"}`, router)
@@ -114,6 +122,39 @@ func TestRoute_UpdatePuzzle_LoadSaveError(t *testing.T) {
 	}
 }
 
+func TestRoute_ReadSettings(t *testing.T) {
+	router, _, _ := NewTestRouter(t)
+
+	// A channel that has never changed a setting gets the defaults.
+	response := Channel.GET("/settings", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	var settings Settings
+	require.NoError(t, render.DecodeJSON(response.Result().Body, &settings))
+	assert.False(t, settings.AllowUnofficialAnswers)
+	assert.False(t, settings.ShowAnswerPlaceholders)
+	assert.Equal(t, model.FontSizeNormal, settings.FontSize)
+
+	// Once a setting is changed its new value is returned.
+	response = Channel.PUT("/setting/font_size", `"xlarge"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	response = Channel.GET("/settings", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	settings = Settings{}
+	require.NoError(t, render.DecodeJSON(response.Result().Body, &settings))
+	assert.Equal(t, model.FontSizeXLarge, settings.FontSize)
+}
+
+func TestRoute_ReadSettings_LoadError(t *testing.T) {
+	router, _, _ := NewTestRouter(t)
+	ForceErrorDuringSettingsLoad(t, errors.New("forced error"))
+
+	response := Channel.GET("/settings", router)
+	assert.Equal(t, http.StatusInternalServerError, response.Code)
+}
+
 func TestRoute_UpdateSetting(t *testing.T) {
 	// This acts as a small integration test updating each setting in turn and
 	// making sure the proper value is written to the database and that clients
@@ -139,6 +180,86 @@ func TestRoute_UpdateSetting(t *testing.T) {
 	VerifySettings(t, pool, events, func(s Settings) {
 		assert.True(t, s.ShowAnswerPlaceholders)
 	})
+
+	response = Channel.PUT("/setting/scoring", `"length_weighted"`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifySettings(t, pool, events, func(s Settings) {
+		assert.Equal(t, ScoringLengthWeighted, s.Scoring)
+	})
+
+	response = Channel.PUT("/setting/blocked_words", `["tutu", " Count ", "TUTU"]`, router)
+	assert.Equal(t, http.StatusOK, response.Code)
+	VerifySettings(t, pool, events, func(s Settings) {
+		assert.Equal(t, []string{"COUNT", "TUTU"}, s.BlockedWords)
+	})
+}
+
+func TestRoute_UpdateSetting_BlockedWords(t *testing.T) {
+	// This acts as a small integration test blocking a word and ensuring that it
+	// is no longer accepted as an answer and no longer counts towards the
+	// maximum score.
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	state := NewState(t, "nytbee-20200408.html")
+	state.Status = model.StatusSolving
+	require.NoError(t, state.ApplyAnswer("COUNT", false, nil, ScoringStandard))
+	require.NoError(t, state.ApplyAnswer("COUNTRY", false, nil, ScoringStandard))
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	index := state.Words["COUNT"]
+	max := state.Puzzle.MaximumOfficialScore
+	num := state.Puzzle.NumOfficialAnswers
+
+	// Blocking a word that was already given removes it from the solve.
+	response := Channel.PUT("/setting/blocked_words", `["country"]`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, map[string]int{"COUNT": index}, state.Words)
+		assert.Equal(t, 5, state.Score)
+		assert.Equal(t, max-14, state.Puzzle.MaximumOfficialScore)
+		assert.Equal(t, num-1, state.Puzzle.NumOfficialAnswers)
+	})
+
+	// The blocked word is rejected even though it's a valid answer.
+	response = Channel.POST("/answer", `"COUNTRY"`, router)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, ErrAnswerBlocked.Error(), strings.TrimSpace(response.Body.String()))
+	assert.Empty(t, Events(events, "state"))
+}
+
+func TestRoute_UpdateSetting_Scoring_RescoresSolve(t *testing.T) {
+	// This acts as a small integration test changing the scoring setting and
+	// ensuring that both the score and the maximum scores are recalculated.
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	state := NewState(t, "nytbee-20200408.html")
+	state.Status = model.StatusSolving
+	require.NoError(t, state.ApplyAnswer("COUNT", false, nil, ScoringStandard))
+	require.NoError(t, state.ApplyAnswer("COUNTRY", false, nil, ScoringStandard))
+	require.Equal(t, 19, state.Score)
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	official := state.Puzzle.OfficialAnswers
+	unofficial := state.Puzzle.UnofficialAnswers
+
+	response := Channel.PUT("/setting/scoring", `"flat_pangram"`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, 1+1+FlatPangramBonus, state.Score)
+		assert.Equal(t, state.Puzzle.ComputeScore(official, ScoringFlatPangram), state.Puzzle.MaximumOfficialScore)
+		assert.Equal(t, state.Puzzle.MaximumOfficialScore+state.Puzzle.ComputeScore(unofficial, ScoringFlatPangram), state.Puzzle.MaximumUnofficialScore)
+	})
+
+	// Answers given after the change are scored the new way as well.
+	response = Channel.POST("/answer", `"COUNTY"`, router)
+	require.Equal(t, http.StatusCreated, response.Code)
+	VerifyState(t, pool, events, func(state State) {
+		assert.Equal(t, 1+1+1+FlatPangramBonus, state.Score)
+	})
 }
 
 func TestRoute_UpdateSetting_AllowUnofficialAnswers_ClearsAnswers(t *testing.T) {
@@ -315,6 +436,21 @@ func TestRoute_UpdateSetting_JSONError(t *testing.T) {
 			setting: "show_answer_placeholders",
 			json:    `{`,
 		},
+		{
+			name:    "scoring",
+			setting: "scoring",
+			json:    `"most_points"`,
+		},
+		{
+			name:    "blocked_words",
+			setting: "blocked_words",
+			json:    `"TUTU"`,
+		},
+		{
+			name:    "blocked_words non-letter",
+			setting: "blocked_words",
+			json:    `["TUTU", "C0UNT"]`,
+		},
 		{
 			name:    "invalid setting name",
 			setting: "foo_bar_baz",
@@ -405,6 +541,36 @@ func TestRoute_ShuffleLetters(t *testing.T) {
 	})
 }
 
+func TestRoute_ShuffleLetters_Seed(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	events := NewEventSubscription(t, registry, Channel.name)
+
+	state := NewState(t, "nytbee-20200408.html")
+	state.Status = model.StatusSolving
+	state.Seed = 7
+	require.NoError(t, SetState(conn, Channel.name, state))
+
+	// The shuffle should be the one determined by the solve's seed.
+	expected := state
+	expected.ShuffleLetters()
+
+	response := Channel.GET("/shuffle", router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	// The seed is kept in the database, but never sent to clients.
+	found := Events(events, "state")
+	require.Equal(t, 1, len(found))
+	assert.Equal(t, int64(0), found[0].Payload.(State).Seed)
+	assert.Equal(t, expected.Letters, found[0].Payload.(State).Letters)
+
+	state, err := GetState(conn, Channel.name)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), state.Seed)
+	assert.Equal(t, 1, state.Shuffles)
+	assert.Equal(t, expected.Letters, state.Letters)
+}
+
 func TestRoute_ShuffleLetters_Error(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -638,53 +804,53 @@ func TestRoute_AddAnswer_SolvedPuzzleStopsTimer(t *testing.T) {
 	// Set the state to have all of the words except for one.
 	state := NewState(t, "nytbee-20200408.html")
 	state.Status = model.StatusSolving
-	state.ApplyAnswer("CONCOCT", false)
-	state.ApplyAnswer("CONTORT", false)
-	state.ApplyAnswer("CONTOUR", false)
-	state.ApplyAnswer("COOT", false)
-	state.ApplyAnswer("COTTON", false)
-	state.ApplyAnswer("COTTONY", false)
-	state.ApplyAnswer("COUNT", false)
-	state.ApplyAnswer("COUNTRY", false)
-	state.ApplyAnswer("COUNTY", false)
-	state.ApplyAnswer("COURT", false)
-	state.ApplyAnswer("CROUTON", false)
-	state.ApplyAnswer("CURT", false)
-	state.ApplyAnswer("CUTOUT", false)
-	state.ApplyAnswer("NUTTY", false)
-	state.ApplyAnswer("ONTO", false)
-	state.ApplyAnswer("OUTCRY", false)
-	state.ApplyAnswer("OUTRO", false)
-	state.ApplyAnswer("OUTRUN", false)
-	state.ApplyAnswer("ROOT", false)
-	state.ApplyAnswer("ROTO", false)
-	state.ApplyAnswer("ROTOR", false)
-	state.ApplyAnswer("ROUT", false)
-	state.ApplyAnswer("RUNOUT", false)
-	state.ApplyAnswer("RUNT", false)
-	state.ApplyAnswer("RUNTY", false)
-	state.ApplyAnswer("RUTTY", false)
-	state.ApplyAnswer("TONY", false)
-	state.ApplyAnswer("TOON", false)
-	state.ApplyAnswer("TOOT", false)
-	state.ApplyAnswer("TORN", false)
-	state.ApplyAnswer("TORO", false)
-	state.ApplyAnswer("TORT", false)
-	state.ApplyAnswer("TOUR", false)
-	state.ApplyAnswer("TOUT", false)
-	state.ApplyAnswer("TROT", false)
-	state.ApplyAnswer("TROUT", false)
-	state.ApplyAnswer("TROY", false)
-	state.ApplyAnswer("TRYOUT", false)
-	state.ApplyAnswer("TURN", false)
-	state.ApplyAnswer("TURNOUT", false)
-	state.ApplyAnswer("TUTOR", false)
-	state.ApplyAnswer("TUTU", false)
-	state.ApplyAnswer("TYCOON", false)
-	state.ApplyAnswer("TYRO", false)
-	state.ApplyAnswer("UNCUT", false)
-	state.ApplyAnswer("UNTO", false)
-	state.ApplyAnswer("YURT", false)
+	state.ApplyAnswer("CONCOCT", false, nil, ScoringStandard)
+	state.ApplyAnswer("CONTORT", false, nil, ScoringStandard)
+	state.ApplyAnswer("CONTOUR", false, nil, ScoringStandard)
+	state.ApplyAnswer("COOT", false, nil, ScoringStandard)
+	state.ApplyAnswer("COTTON", false, nil, ScoringStandard)
+	state.ApplyAnswer("COTTONY", false, nil, ScoringStandard)
+	state.ApplyAnswer("COUNT", false, nil, ScoringStandard)
+	state.ApplyAnswer("COUNTRY", false, nil, ScoringStandard)
+	state.ApplyAnswer("COUNTY", false, nil, ScoringStandard)
+	state.ApplyAnswer("COURT", false, nil, ScoringStandard)
+	state.ApplyAnswer("CROUTON", false, nil, ScoringStandard)
+	state.ApplyAnswer("CURT", false, nil, ScoringStandard)
+	state.ApplyAnswer("CUTOUT", false, nil, ScoringStandard)
+	state.ApplyAnswer("NUTTY", false, nil, ScoringStandard)
+	state.ApplyAnswer("ONTO", false, nil, ScoringStandard)
+	state.ApplyAnswer("OUTCRY", false, nil, ScoringStandard)
+	state.ApplyAnswer("OUTRO", false, nil, ScoringStandard)
+	state.ApplyAnswer("OUTRUN", false, nil, ScoringStandard)
+	state.ApplyAnswer("ROOT", false, nil, ScoringStandard)
+	state.ApplyAnswer("ROTO", false, nil, ScoringStandard)
+	state.ApplyAnswer("ROTOR", false, nil, ScoringStandard)
+	state.ApplyAnswer("ROUT", false, nil, ScoringStandard)
+	state.ApplyAnswer("RUNOUT", false, nil, ScoringStandard)
+	state.ApplyAnswer("RUNT", false, nil, ScoringStandard)
+	state.ApplyAnswer("RUNTY", false, nil, ScoringStandard)
+	state.ApplyAnswer("RUTTY", false, nil, ScoringStandard)
+	state.ApplyAnswer("TONY", false, nil, ScoringStandard)
+	state.ApplyAnswer("TOON", false, nil, ScoringStandard)
+	state.ApplyAnswer("TOOT", false, nil, ScoringStandard)
+	state.ApplyAnswer("TORN", false, nil, ScoringStandard)
+	state.ApplyAnswer("TORO", false, nil, ScoringStandard)
+	state.ApplyAnswer("TORT", false, nil, ScoringStandard)
+	state.ApplyAnswer("TOUR", false, nil, ScoringStandard)
+	state.ApplyAnswer("TOUT", false, nil, ScoringStandard)
+	state.ApplyAnswer("TROT", false, nil, ScoringStandard)
+	state.ApplyAnswer("TROUT", false, nil, ScoringStandard)
+	state.ApplyAnswer("TROY", false, nil, ScoringStandard)
+	state.ApplyAnswer("TRYOUT", false, nil, ScoringStandard)
+	state.ApplyAnswer("TURN", false, nil, ScoringStandard)
+	state.ApplyAnswer("TURNOUT", false, nil, ScoringStandard)
+	state.ApplyAnswer("TUTOR", false, nil, ScoringStandard)
+	state.ApplyAnswer("TUTU", false, nil, ScoringStandard)
+	state.ApplyAnswer("TYCOON", false, nil, ScoringStandard)
+	state.ApplyAnswer("TYRO", false, nil, ScoringStandard)
+	state.ApplyAnswer("UNCUT", false, nil, ScoringStandard)
+	state.ApplyAnswer("UNTO", false, nil, ScoringStandard)
+	state.ApplyAnswer("YURT", false, nil, ScoringStandard)
 	require.NoError(t, SetState(conn, Channel.name, state))
 	require.Equal(t, model.StatusSolving, state.Status)
 
@@ -711,32 +877,32 @@ func TestRoute_AddAnswer_GeniusEvent(t *testing.T) {
 	// Set the state to have all of the words except for one.
 	state := NewState(t, "nytbee-20200408.html")
 	state.Status = model.StatusSolving
-	state.ApplyAnswer("CONCOCT", false)
-	state.ApplyAnswer("CONTORT", false)
-	state.ApplyAnswer("CONTOUR", false)
-	state.ApplyAnswer("COOT", false)
-	state.ApplyAnswer("COTTON", false)
-	state.ApplyAnswer("COTTONY", false)
-	state.ApplyAnswer("COUNT", false)
-	state.ApplyAnswer("COUNTRY", false)
-	state.ApplyAnswer("COUNTY", false)
-	state.ApplyAnswer("COURT", false)
-	state.ApplyAnswer("CROUTON", false)
-	state.ApplyAnswer("CURT", false)
-	state.ApplyAnswer("CUTOUT", false)
-	state.ApplyAnswer("NUTTY", false)
-	state.ApplyAnswer("ONTO", false)
-	state.ApplyAnswer("OUTCRY", false)
-	state.ApplyAnswer("OUTRO", false)
-	state.ApplyAnswer("OUTRUN", false)
-	state.ApplyAnswer("ROOT", false)
-	state.ApplyAnswer("ROTO", false)
-	state.ApplyAnswer("ROTOR", false)
-	state.ApplyAnswer("ROUT", false)
-	state.ApplyAnswer("RUNOUT", false)
-	state.ApplyAnswer("RUNT", false)
-	state.ApplyAnswer("RUNTY", false)
-	state.ApplyAnswer("RUTTY", false)
+	state.ApplyAnswer("CONCOCT", false, nil, ScoringStandard)
+	state.ApplyAnswer("CONTORT", false, nil, ScoringStandard)
+	state.ApplyAnswer("CONTOUR", false, nil, ScoringStandard)
+	state.ApplyAnswer("COOT", false, nil, ScoringStandard)
+	state.ApplyAnswer("COTTON", false, nil, ScoringStandard)
+	state.ApplyAnswer("COTTONY", false, nil, ScoringStandard)
+	state.ApplyAnswer("COUNT", false, nil, ScoringStandard)
+	state.ApplyAnswer("COUNTRY", false, nil, ScoringStandard)
+	state.ApplyAnswer("COUNTY", false, nil, ScoringStandard)
+	state.ApplyAnswer("COURT", false, nil, ScoringStandard)
+	state.ApplyAnswer("CROUTON", false, nil, ScoringStandard)
+	state.ApplyAnswer("CURT", false, nil, ScoringStandard)
+	state.ApplyAnswer("CUTOUT", false, nil, ScoringStandard)
+	state.ApplyAnswer("NUTTY", false, nil, ScoringStandard)
+	state.ApplyAnswer("ONTO", false, nil, ScoringStandard)
+	state.ApplyAnswer("OUTCRY", false, nil, ScoringStandard)
+	state.ApplyAnswer("OUTRO", false, nil, ScoringStandard)
+	state.ApplyAnswer("OUTRUN", false, nil, ScoringStandard)
+	state.ApplyAnswer("ROOT", false, nil, ScoringStandard)
+	state.ApplyAnswer("ROTO", false, nil, ScoringStandard)
+	state.ApplyAnswer("ROTOR", false, nil, ScoringStandard)
+	state.ApplyAnswer("ROUT", false, nil, ScoringStandard)
+	state.ApplyAnswer("RUNOUT", false, nil, ScoringStandard)
+	state.ApplyAnswer("RUNT", false, nil, ScoringStandard)
+	state.ApplyAnswer("RUNTY", false, nil, ScoringStandard)
+	state.ApplyAnswer("RUTTY", false, nil, ScoringStandard)
 	require.NoError(t, SetState(conn, Channel.name, state))
 	require.Equal(t, model.StatusSolving, state.Status)
 
@@ -784,6 +950,52 @@ func TestRoute_AddAnswer_Error(t *testing.T) {
 	}
 }
 
+func TestRoute_AddAnswer_RejectionReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   string
+		expected error
+	}{
+		{
+			name:     "too short",
+			answer:   "TOT",
+			expected: ErrAnswerTooShort,
+		},
+		{
+			name:     "missing center letter",
+			answer:   "CORN",
+			expected: ErrAnswerMissingCenterLetter,
+		},
+		{
+			name:     "foreign letter",
+			answer:   "TOWN",
+			expected: ErrAnswerInvalidLetter,
+		},
+		{
+			name:     "not in word list",
+			answer:   "TTTT",
+			expected: ErrAnswerNotInWordList,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, registry := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+			events := NewEventSubscription(t, registry, Channel.name)
+
+			state := NewState(t, "nytbee-20200408.html")
+			state.Status = model.StatusSolving
+			require.NoError(t, SetState(conn, Channel.name, state))
+
+			response := Channel.POST("/answer", `"`+test.answer+`"`, router)
+			assert.Equal(t, http.StatusBadRequest, response.Code)
+			assert.Equal(t, test.expected.Error(), strings.TrimSpace(response.Body.String()))
+			assert.Empty(t, Events(events, "state"))
+		})
+	}
+}
+
 func TestRoute_AddAnswer_LoadSaveError(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -905,10 +1117,12 @@ func TestRoute_GetEvents_LoadSaveError(t *testing.T) {
 			ForceErrorDuringSettingsLoad(t, test.forceSettingsLoadError)
 			ForceErrorDuringStateLoad(t, test.forceStateLoadError)
 
-			// This won't start a background goroutine to send events because the
-			// request will fail before reaching that part of the code.
-			response := Channel.GET("/events", router)
-			assert.NotEqual(t, http.StatusOK, response.Code)
+			// The stream stays open, but the client is told that the initial
+			// events couldn't be loaded.
+			_, stop := Channel.SSE("/events", router)
+			events := stop()
+			require.Equal(t, 1, len(events))
+			assert.Equal(t, "error", events[0].Kind)
 		})
 	}
 }
@@ -1115,16 +1329,28 @@ func (c ChannelClient) SSE(url string, router chi.Router) (flush func() []pubsub
 		return events
 	}
 
+	// The request is cancelled when the stream is stopped, just like when a
+	// client disconnects.
+	ctx, cancel := context.WithCancel(context.Background())
+	finished := make(chan struct{})
+
 	stop = func() []pubsub.Event {
 		// Give the router a chance to write everything it needs to.
 		time.Sleep(10 * time.Millisecond)
 
 		recorder.Close()
-		return flush()
+		events := flush()
+
+		cancel()
+		<-finished
+		return events
 	}
 
-	request := httptest.NewRequest(http.MethodGet, url, nil)
-	go router.ServeHTTP(recorder, request)
+	request := httptest.NewRequest(http.MethodGet, url, nil).WithContext(ctx)
+	go func() {
+		defer close(finished)
+		router.ServeHTTP(recorder, request)
+	}()
 
 	return flush, stop
 }
@@ -0,0 +1,106 @@
+package spellingbee
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Scoring is an enumeration representing the ways that points can be awarded
+// for the answers of a spelling bee.  It can be marshalled to/from JSON as well
+// as implements the fmt.Stringer interface for human readability.
+type Scoring int
+
+const (
+	// ScoringStandard is the scoring used by The New York Times.  Four letter
+	// answers are worth a single point, longer answers are worth a point per
+	// letter and pangrams earn a 7 point bonus.
+	ScoringStandard Scoring = iota
+
+	// ScoringLengthWeighted rewards long answers more heavily.  Each answer is
+	// worth the square of the number of letters it has beyond the third, and
+	// pangrams earn a 7 point bonus.
+	ScoringLengthWeighted
+
+	// ScoringFlatPangram treats every answer the same regardless of its length.
+	// Each answer is worth a single point and pangrams earn a flat bonus of
+	// FlatPangramBonus points.
+	ScoringFlatPangram
+)
+
+// FlatPangramBonus is the number of bonus points a pangram earns when using
+// ScoringFlatPangram.
+var FlatPangramBonus = 10
+
+func (s Scoring) String() string {
+	switch s {
+	case ScoringStandard:
+		return "standard"
+	case ScoringLengthWeighted:
+		return "length_weighted"
+	case ScoringFlatPangram:
+		return "flat_pangram"
+	default:
+		return "unknown"
+	}
+}
+
+func (s Scoring) MarshalJSON() ([]byte, error) {
+	switch s {
+	case ScoringStandard:
+	case ScoringLengthWeighted:
+	case ScoringFlatPangram:
+	default:
+		return nil, fmt.Errorf("unrecognized scoring: %v", s)
+	}
+
+	return json.Marshal(s.String())
+}
+
+func (s *Scoring) UnmarshalJSON(bs []byte) error {
+	var str string
+	if err := json.Unmarshal(bs, &str); err != nil {
+		return err
+	}
+
+	switch str {
+	case "standard":
+		*s = ScoringStandard
+	case "length_weighted":
+		*s = ScoringLengthWeighted
+	case "flat_pangram":
+		*s = ScoringFlatPangram
+	default:
+		return fmt.Errorf("unrecognized scoring string: %s", str)
+	}
+
+	return nil
+}
+
+// Score returns the number of points that a single answer is worth.
+func (s Scoring) Score(word string, pangram bool) int {
+	switch s {
+	case ScoringLengthWeighted:
+		score := (len(word) - 3) * (len(word) - 3)
+		if pangram {
+			score += 7
+		}
+		return score
+
+	case ScoringFlatPangram:
+		if pangram {
+			return 1 + FlatPangramBonus
+		}
+		return 1
+
+	default:
+		if len(word) == 4 {
+			return 1
+		}
+
+		score := len(word)
+		if pangram {
+			score += 7
+		}
+		return score
+	}
+}
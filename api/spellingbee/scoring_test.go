@@ -0,0 +1,32 @@
+package spellingbee
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoring_JSON(t *testing.T) {
+	for _, scoring := range []Scoring{ScoringStandard, ScoringLengthWeighted, ScoringFlatPangram} {
+		t.Run(scoring.String(), func(t *testing.T) {
+			bs, err := json.Marshal(scoring)
+			require.NoError(t, err)
+			assert.Equal(t, `"`+scoring.String()+`"`, string(bs))
+
+			var actual Scoring
+			require.NoError(t, json.Unmarshal(bs, &actual))
+			assert.Equal(t, scoring, actual)
+		})
+	}
+}
+
+func TestScoring_JSON_Error(t *testing.T) {
+	_, err := json.Marshal(Scoring(17))
+	assert.Error(t, err)
+
+	var scoring Scoring
+	assert.Error(t, json.Unmarshal([]byte(`"unknown"`), &scoring))
+	assert.Error(t, json.Unmarshal([]byte(`17`), &scoring))
+}
@@ -5,6 +5,9 @@ import (
 	"github.com/bbeck/puzzles-with-chat/api/db"
 	"github.com/bbeck/puzzles-with-chat/api/model"
 	"github.com/gomodule/redigo/redis"
+	"sort"
+	"strings"
+	"unicode"
 )
 
 // Settings represents the optional behaviors that can be enabled or disabled
@@ -18,6 +21,37 @@ type Settings struct {
 
 	// What font size words should be rendered with.
 	FontSize model.FontSize `json:"font_size"`
+
+	// How points are awarded for answers.
+	Scoring Scoring `json:"scoring"`
+
+	// Words that are never accepted as answers even when they're otherwise
+	// valid, for example to keep a stream family friendly.  The words are
+	// uppercase and sorted.
+	BlockedWords []string `json:"blocked_words,omitempty"`
+}
+
+// NormalizeBlockedWords validates a list of blocked words that was provided by
+// a user and normalizes it so that each word is uppercase and appears only
+// once, in sorted order.  An error is returned if a word isn't made up entirely
+// of letters.
+func NormalizeBlockedWords(words []string) ([]string, error) {
+	seen := make(map[string]bool)
+	normalized := make([]string, 0, len(words))
+	for _, word := range words {
+		word = strings.ToUpper(strings.TrimSpace(word))
+		if word == "" || strings.IndexFunc(word, func(r rune) bool { return !unicode.IsLetter(r) }) != -1 {
+			return nil, fmt.Errorf("invalid blocked word: %q", word)
+		}
+
+		if !seen[word] {
+			seen[word] = true
+			normalized = append(normalized, word)
+		}
+	}
+	sort.Strings(normalized)
+
+	return normalized, nil
 }
 
 // SettingsKey returns the key that should be used in redis to store a
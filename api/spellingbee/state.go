@@ -37,36 +37,113 @@ type State struct {
 
 	// The total time spent on solving the puzzle up to the last start time.
 	TotalSolveDuration model.Duration `json:"total_solve_duration"`
+
+	// The time that the state was last saved.  This is used to avoid counting
+	// time that the server was down as solve time.
+	LastSaveTime *time.Time `json:"last_save_time,omitempty"`
+
+	// The seed chosen when the puzzle was selected.  All randomization during the
+	// solve is derived from it so that it's reproducible.  The seed is never
+	// sent to clients.
+	Seed int64 `json:"seed,omitempty"`
+
+	// The number of times the letters have been shuffled during the solve.
+	Shuffles int `json:"shuffles,omitempty"`
+}
+
+// resetEphemeralState clears everything about the state that belongs to a
+// single solve and initializes it for solving the provided puzzle.  Anything
+// that should not survive the selection of a new puzzle must be reset here so
+// that it isn't accidentally carried over into the next solve.
+func (s *State) resetEphemeralState(puzzle *Puzzle) {
+	s.Status = model.StatusSelected
+	s.Puzzle = puzzle
+	s.Letters = puzzle.Letters
+	s.Words = make(map[string]int)
+	s.Score = 0
+	s.LastStartTime = nil
+	s.TotalSolveDuration = model.Duration{}
+	s.Seed = model.NewSeed()
+	s.Shuffles = 0
+}
+
+// ShuffleLetters changes the order of the letters of the puzzle.  The new order
+// is determined by the solve's seed and how many times the letters have
+// already been shuffled.
+func (s *State) ShuffleLetters() {
+	r := model.SeededRand(s.Seed, s.Shuffles)
+	s.Shuffles++
+
+	// Shuffle a copy since the letters may be shared with the puzzle.
+	letters := append([]string(nil), s.Letters...)
+	r.Shuffle(len(letters), func(i, j int) {
+		letters[i], letters[j] = letters[j], letters[i]
+	})
+	s.Letters = letters
 }
 
-// ApplyAnswer applies an answer to the state.  If the answer cannot be applied
-// or is incorrect then an error is returned.
-func (s *State) ApplyAnswer(answer string, allowUnofficial bool) error {
+// The reasons that an answer can be rejected by ApplyAnswer.  Each describes a
+// different problem with the answer so that solvers can be told why their
+// answer wasn't accepted.
+var (
+	ErrAnswerAlreadyGiven        = errors.New("answer already given")
+	ErrAnswerTooShort            = errors.New("answer is too short")
+	ErrAnswerMissingCenterLetter = errors.New("answer doesn't use the center letter")
+	ErrAnswerInvalidLetter       = errors.New("answer uses a letter that isn't in the puzzle")
+	ErrAnswerNotInWordList       = errors.New("answer not in the list of allowed answers")
+	ErrAnswerBlocked             = errors.New("answer isn't accepted on this channel")
+)
+
+// MinimumAnswerLength is the fewest letters that an answer may have.
+const MinimumAnswerLength = 4
+
+// ApplyAnswer applies an answer to the state and updates the score using the
+// provided scoring.  If the answer cannot be applied, is incorrect or is one of
+// the blocked words then an error describing why is returned, it will be one
+// of the ErrAnswer errors.
+func (s *State) ApplyAnswer(answer string, allowUnofficial bool, blocked []string, scoring Scoring) error {
 	answer = strings.ToUpper(answer)
 
 	// First, make sure the answer wasn't previously given.
 	if _, found := s.Words[answer]; found {
-		return errors.New("answer already given")
+		return ErrAnswerAlreadyGiven
 	}
 
-	// Next, ensure the answer is in the list of allowed answers.
-	var answers []string
-	answers = append(answers, s.Puzzle.OfficialAnswers...)
-	if allowUnofficial {
-		answers = append(answers, s.Puzzle.UnofficialAnswers...)
+	// Next, make sure the answer could possibly be formed from the puzzle's
+	// letters.
+	if len(answer) < MinimumAnswerLength {
+		return ErrAnswerTooShort
 	}
-	sort.Strings(answers)
+
+	if !strings.Contains(answer, s.Puzzle.CenterLetter) {
+		return ErrAnswerMissingCenterLetter
+	}
+
+	letters := s.Puzzle.CenterLetter + strings.Join(s.Puzzle.Letters, "")
+	for _, c := range answer {
+		if !strings.ContainsRune(letters, c) {
+			return ErrAnswerInvalidLetter
+		}
+	}
+
+	// Blocked words are never accepted, even when they're otherwise valid.
+	if contains(blocked, answer) {
+		return ErrAnswerBlocked
+	}
+
+	// Lastly, ensure the answer is in the list of allowed answers.
+	answers := s.Puzzle.Answers(allowUnofficial, blocked)
 
 	index, found := find(answers, answer)
 	if !found {
-		return errors.New("answer not in the list of allowed answers")
+		return ErrAnswerNotInWordList
 	}
 
 	// Save the answer to the state along with it's index.
 	s.Words[answer] = index
 
 	// Update the score for this answer.
-	s.Score = s.Puzzle.ComputeScore(keys(s.Words))
+	s.Score = s.Puzzle.ComputeScore(keys(s.Words), scoring)
 
 	// Lastly determine if we've found all of the answers and the puzzle is now
 	// complete.
@@ -78,15 +155,12 @@ func (s *State) ApplyAnswer(answer string, allowUnofficial bool) error {
 }
 
 // RebuildWordMap rebuilds the words map using the set of answers specified by
-// the allowUnofficial parameter.  Words that are present that are no longer
-// permitted are removed, and indices are adjusted appropriately.
-func (s *State) RebuildWordMap(allowUnofficial bool) {
-	var answers []string
-	answers = append(answers, s.Puzzle.OfficialAnswers...)
-	if allowUnofficial {
-		answers = append(answers, s.Puzzle.UnofficialAnswers...)
-	}
-	sort.Strings(answers)
+// the allowUnofficial and blocked parameters.  Words that are present that are
+// no longer permitted are removed, and indices are adjusted appropriately.  The
+// score and the puzzle's maximum scores are recalculated using the provided
+// scoring.
+func (s *State) RebuildWordMap(allowUnofficial bool, blocked []string, scoring Scoring) {
+	answers := s.Puzzle.Answers(allowUnofficial, blocked)
 
 	words := make(map[string]int)
 	for word := range s.Words {
@@ -97,8 +171,9 @@ func (s *State) RebuildWordMap(allowUnofficial bool) {
 
 	s.Words = words
 
-	// The words may have changed, update the score accordingly.
-	s.Score = s.Puzzle.ComputeScore(keys(s.Words))
+	// The words or scoring may have changed, update the scores accordingly.
+	s.Puzzle.SetMaximumScores(scoring, blocked)
+	s.Score = s.Puzzle.ComputeScore(keys(s.Words), scoring)
 
 	// Lastly determine if the puzzle is now solved.
 	if len(s.Words) == len(answers) {
@@ -128,7 +203,16 @@ func GetState(conn db.Connection, channel string) (State, error) {
 	}
 
 	err := db.Get(conn, StateKey(channel), &state)
-	return state, err
+	if err != nil {
+		return state, err
+	}
+
+	// If the server restarted while the channel was solving then make sure the
+	// downtime isn't counted towards the solve.
+	state.Status, state.LastStartTime, state.TotalSolveDuration =
+		model.ReconcileSolveTimer(state.Status, state.LastStartTime, state.LastSaveTime, state.TotalSolveDuration)
+
+	return state, nil
 }
 
 // SetState writes the state for a channel's spelling bee solve to redis.  If
@@ -138,7 +222,14 @@ func SetState(conn db.Connection, channel string, state State) error {
 		return testStateSaveError
 	}
 
-	return db.SetWithTTL(conn, StateKey(channel), state, StateTTL)
+	now := time.Now()
+	state.LastSaveTime = &now
+
+	if err := db.SetWithTTL(conn, StateKey(channel), state, StateTTL); err != nil {
+		return err
+	}
+
+	return model.RecordActivity(conn, "spellingbee", channel, now)
 }
 
 // GetAllChannels returns a slice of model.Channel instances for each spelling
@@ -62,7 +62,7 @@ func TestState_ApplyAnswer_Words(t *testing.T) {
 			state := NewState(t, test.filename)
 			state.Words = test.initialWords
 
-			err := state.ApplyAnswer(test.answer, test.allowUnofficial)
+			err := state.ApplyAnswer(test.answer, test.allowUnofficial, nil, ScoringStandard)
 			require.NoError(t, err)
 			assert.Equal(t, test.expectedWords, state.Words)
 		})
@@ -297,7 +297,7 @@ func TestState_ApplyAnswer_Status(t *testing.T) {
 			state.Status = model.StatusSolving
 
 			for _, answer := range test.answers {
-				require.NoError(t, state.ApplyAnswer(answer, test.allowUnofficial))
+				require.NoError(t, state.ApplyAnswer(answer, test.allowUnofficial, nil, ScoringStandard))
 			}
 
 			assert.Equal(t, test.expectedStatus, state.Status)
@@ -489,7 +489,7 @@ func TestState_ApplyAnswer_Score(t *testing.T) {
 			state.Status = model.StatusSolving
 
 			for _, answer := range test.answers {
-				require.NoError(t, state.ApplyAnswer(answer, test.allowUnofficial))
+				require.NoError(t, state.ApplyAnswer(answer, test.allowUnofficial, nil, ScoringStandard))
 			}
 
 			assert.Equal(t, test.expectedScore, state.Score)
@@ -504,33 +504,72 @@ func TestState_ApplyAnswer_Error(t *testing.T) {
 		initialWords    map[string]int
 		answer          string
 		allowUnofficial bool
+		blocked         []string
+		expected        error
 	}{
 		{
 			name:     "not allowed letter",
 			filename: "nytbee-20200408.html",
 			answer:   "WXYZ",
+			expected: ErrAnswerMissingCenterLetter,
 		},
 		{
 			name:         "already given answer",
 			filename:     "nytbee-20200408.html",
 			initialWords: map[string]int{"COCONUT": 0},
 			answer:       "COCONUT",
+			expected:     ErrAnswerAlreadyGiven,
 		},
 		{
 			name:     "answer not in official list",
 			filename: "nytbee-20200408.html",
 			answer:   "CONCOCTOR",
+			expected: ErrAnswerNotInWordList,
 		},
 		{
 			name:     "answer from unofficial list, not allowed",
 			filename: "nytbee-20200408.html",
 			answer:   "CONCOCTOR",
+			expected: ErrAnswerNotInWordList,
 		},
 		{
 			name:            "answer not in either list",
 			filename:        "nytbee-20200408.html",
 			answer:          "CCCC",
 			allowUnofficial: true,
+			expected:        ErrAnswerMissingCenterLetter,
+		},
+		{
+			name:     "too short",
+			filename: "nytbee-20200408.html",
+			answer:   "TOT",
+			expected: ErrAnswerTooShort,
+		},
+		{
+			name:     "missing center letter",
+			filename: "nytbee-20200408.html",
+			answer:   "CORN",
+			expected: ErrAnswerMissingCenterLetter,
+		},
+		{
+			name:     "foreign letter",
+			filename: "nytbee-20200408.html",
+			answer:   "TOWN",
+			expected: ErrAnswerInvalidLetter,
+		},
+		{
+			name:            "not in word list",
+			filename:        "nytbee-20200408.html",
+			answer:          "TTTT",
+			allowUnofficial: true,
+			expected:        ErrAnswerNotInWordList,
+		},
+		{
+			name:     "blocked word",
+			filename: "nytbee-20200408.html",
+			answer:   "COUNTRY",
+			blocked:  []string{"COUNTRY"},
+			expected: ErrAnswerBlocked,
 		},
 	}
 
@@ -539,8 +578,8 @@ func TestState_ApplyAnswer_Error(t *testing.T) {
 			state := NewState(t, test.filename)
 			state.Words = test.initialWords
 
-			err := state.ApplyAnswer(test.answer, test.allowUnofficial)
-			assert.Error(t, err)
+			err := state.ApplyAnswer(test.answer, test.allowUnofficial, test.blocked, ScoringStandard)
+			assert.Equal(t, test.expected, err)
 		})
 	}
 }
@@ -631,7 +670,7 @@ func TestState_RebuildWordMap(t *testing.T) {
 				state.Words[word] = i
 			}
 
-			state.RebuildWordMap(test.allowUnofficial)
+			state.RebuildWordMap(test.allowUnofficial, nil, ScoringStandard)
 			assert.Equal(t, test.expected, state.Words)
 		})
 	}
@@ -708,7 +747,7 @@ func TestState_RebuildWordMap_Score(t *testing.T) {
 				state.Words[word] = i
 			}
 
-			state.RebuildWordMap(test.allowUnofficial)
+			state.RebuildWordMap(test.allowUnofficial, nil, ScoringStandard)
 			assert.Equal(t, test.expectedScore, state.Score)
 		})
 	}
@@ -865,12 +904,67 @@ func TestState_RebuildWordMap_Status(t *testing.T) {
 				state.Words[word] = i
 			}
 
-			state.RebuildWordMap(test.allowUnofficial)
+			state.RebuildWordMap(test.allowUnofficial, nil, ScoringStandard)
 			assert.Equal(t, test.expectedStatus, state.Status)
 		})
 	}
 }
 
+func TestState_ResetEphemeralState(t *testing.T) {
+	state := NewState(t, "nytbee-20200408.html")
+	state.Status = model.StatusSolving
+	require.NoError(t, state.ApplyAnswer("COCONUT", false, nil, ScoringStandard))
+	state.Letters = []string{"U", "T", "O", "N", "I", "C"}
+	state.TotalSolveDuration = model.Duration{Duration: 10 * time.Minute}
+
+	state.Shuffles = 3
+
+	ForceSeed(t, 42)
+	puzzle := LoadTestPuzzle(t, "nytbee-20180729.html")
+	state.resetEphemeralState(puzzle)
+
+	assert.Equal(t, model.StatusSelected, state.Status)
+	assert.Equal(t, puzzle, state.Puzzle)
+	assert.Equal(t, puzzle.Letters, state.Letters)
+	assert.Empty(t, state.Words)
+	assert.Equal(t, 0, state.Score)
+	assert.Nil(t, state.LastStartTime)
+	assert.Equal(t, time.Duration(0), state.TotalSolveDuration.Duration)
+	assert.Equal(t, int64(42), state.Seed)
+	assert.Equal(t, 0, state.Shuffles)
+}
+
+func TestState_ShuffleLetters(t *testing.T) {
+	shuffle := func(seed int64) [][]string {
+		state := NewState(t, "nytbee-20200408.html")
+		state.Seed = seed
+
+		var orders [][]string
+		for i := 0; i < 3; i++ {
+			state.ShuffleLetters()
+			orders = append(orders, state.Letters)
+		}
+		assert.Equal(t, 3, state.Shuffles)
+
+		return orders
+	}
+
+	// Shuffles with the same seed match.
+	assert.Equal(t, shuffle(1), shuffle(1))
+
+	// Shuffles with different seeds differ.
+	assert.NotEqual(t, shuffle(1), shuffle(2))
+}
+
+func TestState_ShuffleLetters_DoesNotChangePuzzle(t *testing.T) {
+	state := NewState(t, "nytbee-20200408.html")
+	letters := append([]string(nil), state.Puzzle.Letters...)
+
+	state.ShuffleLetters()
+	assert.Equal(t, letters, state.Puzzle.Letters)
+	assert.ElementsMatch(t, letters, state.Letters)
+}
+
 func TestGetAllChannels(t *testing.T) {
 	type ChannelToCreate struct {
 		name     string
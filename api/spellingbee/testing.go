@@ -90,6 +90,15 @@ func ForcePuzzleToBeLoaded(t *testing.T, filename string) {
 	t.Cleanup(func() { testPuzzle = nil })
 }
 
+// ForceSeed sets up the seed that's chosen when a puzzle is selected.
+func ForceSeed(t *testing.T, seed int64) {
+	t.Helper()
+
+	original := model.NewSeed
+	model.NewSeed = func() int64 { return seed }
+	t.Cleanup(func() { model.NewSeed = original })
+}
+
 // ForceErrorDuringLoad sets up an error to be returned when an attempt is made
 // to load a puzzle.
 func ForceErrorDuringPuzzleLoad(t *testing.T, err error) {
@@ -155,6 +164,16 @@ func NewTestRouter(t *testing.T) (chi.Router, *redis.Pool, *pubsub.Registry) {
 	// Create the pubsub registry.
 	registry := new(pubsub.Registry)
 
+	// Don't make streams wait long when retrying their initial events.
+	retryDelay := pubsub.InitialEventsRetryDelay
+	recoveryInterval := pubsub.InitialEventsRecoveryInterval
+	pubsub.InitialEventsRetryDelay = time.Millisecond
+	pubsub.InitialEventsRecoveryInterval = 10 * time.Millisecond
+	t.Cleanup(func() {
+		pubsub.InitialEventsRetryDelay = retryDelay
+		pubsub.InitialEventsRecoveryInterval = recoveryInterval
+	})
+
 	// Setup the chi router and wire it up to the redis pool and pubsub registry.
 	router := chi.NewRouter()
 	RegisterRoutes(router, pool, registry)
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"errors"
+	"github.com/bbeck/puzzles-with-chat/api/acrostic"
+	"github.com/bbeck/puzzles-with-chat/api/crossword"
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"github.com/bbeck/puzzles-with-chat/api/pubsub"
+	"github.com/bbeck/puzzles-with-chat/api/spellingbee"
+	"github.com/go-chi/render"
+	"github.com/gomodule/redigo/redis"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// ChannelNameRegexp matches the names that Twitch allows for a channel.
+var ChannelNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_]{1,25}$`)
+
+// ErrNoChannelData is returned when attempting to transfer a channel that
+// doesn't have any data.
+var ErrNoChannelData = errors.New("channel has no data")
+
+// ErrChannelHasData is returned when attempting to transfer a channel to a name
+// that already has data of its own.
+var ErrChannelHasData = errors.New("channel already has data")
+
+// ErrTransferConflict is returned when a channel's data changed while it was
+// being transferred.
+var ErrTransferConflict = errors.New("channel data changed during transfer")
+
+// TransferChannel changes the name of the channel that owns a set of settings
+// and solves.  All of the channel's data is moved, and clients of both channels
+// are sent the channels' new states.
+func TransferChannel(pool *redis.Pool, registry *pubsub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			From  string `json:"from"`
+			To    string `json:"to"`
+			Force bool   `json:"force"`
+		}
+		if err := render.DecodeJSON(r.Body, &payload); err != nil {
+			log.Printf("unable to read request body: %+v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if !ChannelNameRegexp.MatchString(payload.From) || !ChannelNameRegexp.MatchString(payload.To) || payload.From == payload.To {
+			log.Printf("invalid channel names for transfer, from: %s, to: %s", payload.From, payload.To)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		conn := pool.Get()
+		defer func() { _ = conn.Close() }()
+
+		err := MoveChannelData(conn, payload.From, payload.To, payload.Force)
+		if errors.Is(err, ErrNoChannelData) {
+			log.Printf("unable to transfer channel %s: %+v", payload.From, err)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, ErrChannelHasData) || errors.Is(err, ErrTransferConflict) {
+			log.Printf("unable to transfer channel %s to %s: %+v", payload.From, payload.To, err)
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if err != nil {
+			log.Printf("unable to transfer channel %s to %s: %+v", payload.From, payload.To, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		for _, channel := range []string{payload.From, payload.To} {
+			if err := PublishChannelStates(conn, registry, channel); err != nil {
+				log.Printf("unable to publish states for channel %s: %+v", channel, err)
+			}
+		}
+
+		// The old channel's events belong to the new channel now, so they
+		// shouldn't be replayed to clients that connect to the old channel.
+		for _, id := range ChannelIDs(payload.From) {
+			registry.ClearHistory(id)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// PublishChannelStates sends the current state of each puzzle type to the
+// clients of a channel, making sure to not include any answers.  A channel
+// without a solve of a puzzle type is sent an empty state for it.
+func PublishChannelStates(conn redis.Conn, registry *pubsub.Registry, channel string) error {
+	cs, err := crossword.GetState(conn, channel)
+	if err != nil {
+		return err
+	}
+	if cs.Puzzle != nil {
+		cs.Puzzle = cs.Puzzle.WithoutSolution()
+	}
+	registry.Publish(crossword.ChannelID(channel), crossword.StateEvent(cs))
+
+	as, err := acrostic.GetState(conn, channel)
+	if err != nil {
+		return err
+	}
+	if as.Puzzle != nil {
+		as.Puzzle = as.Puzzle.WithoutSolution()
+	}
+	registry.Publish(acrostic.ChannelID(channel), acrostic.StateEvent(as))
+
+	ss, err := spellingbee.GetState(conn, channel)
+	if err != nil {
+		return err
+	}
+	if ss.Puzzle != nil {
+		ss.Puzzle = ss.Puzzle.WithoutAnswers()
+	}
+	registry.Publish(spellingbee.ChannelID(channel), spellingbee.StateEvent(ss))
+
+	return nil
+}
+
+// MoveChannelData atomically moves all of the data of one channel to another
+// channel.  This includes the settings and solves of every puzzle type along
+// with anything else stored under the channel's name such as its stats,
+// streaks and audit log.  Once moved, the channel's entries in the sets shared
+// between channels are updated for its new name.  If the destination channel already has data of its
+// own then ErrChannelHasData is returned unless force is true, in which case
+// the destination's data is replaced.  If the source channel doesn't have any
+// data then ErrNoChannelData is returned.
+func MoveChannelData(conn redis.Conn, from, to string, force bool) error {
+	fromKeys, err := FindChannelKeys(conn, from)
+	if err != nil {
+		return err
+	}
+	if len(fromKeys) == 0 {
+		return ErrNoChannelData
+	}
+
+	existingKeys, err := FindChannelKeys(conn, to)
+	if err != nil {
+		return err
+	}
+	if len(existingKeys) > 0 && !force {
+		return ErrChannelHasData
+	}
+
+	// Every key of a channel is prefixed with the channel's name, and channel
+	// names can't contain a colon, so replacing the prefix names the key for the
+	// destination channel.
+	toKeys := make([]string, len(fromKeys))
+	for i, key := range fromKeys {
+		toKeys[i] = to + strings.TrimPrefix(key, from)
+	}
+
+	// Watch every key involved so that the transfer is aborted if anything
+	// changes between finding the keys and moving them.
+	var args []interface{}
+	for _, key := range append(append(fromKeys, existingKeys...), toKeys...) {
+		args = append(args, key)
+	}
+	if _, err := conn.Do("WATCH", args...); err != nil {
+		return err
+	}
+	defer func() { _, _ = conn.Do("UNWATCH") }()
+
+	if err := conn.Send("MULTI"); err != nil {
+		return err
+	}
+	for _, key := range existingKeys {
+		if err := conn.Send("DEL", key); err != nil {
+			return err
+		}
+	}
+	for i := range fromKeys {
+		// Renaming a key keeps its TTL and removes the original.
+		if err := conn.Send("RENAME", fromKeys[i], toKeys[i]); err != nil {
+			return err
+		}
+	}
+
+	// An aborted transaction has no replies.
+	replies, err := redis.Values(conn.Do("EXEC"))
+	if err == redis.ErrNil || (err == nil && len(replies) == 0) {
+		return ErrTransferConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	return moveChannelEntries(conn, from, to, len(existingKeys) > 0)
+}
+
+// moveChannelEntries moves a channel's entries in the sets that are shared
+// between all channels once its keys have been moved.  The channel's activity
+// and pending advances and reveals follow it to its new name, while its
+// completions are removed since they were announced under the old name.  If
+// the destination channel's data was replaced then its own entries are removed
+// first.
+func moveChannelEntries(conn redis.Conn, from, to string, replaced bool) error {
+	if replaced {
+		for _, kind := range PuzzleTypes {
+			if err := model.RemoveActivity(conn, kind, to); err != nil {
+				return err
+			}
+		}
+
+		if err := model.RemoveCompletions(conn, to); err != nil {
+			return err
+		}
+	}
+
+	for _, kind := range PuzzleTypes {
+		if err := model.MoveActivity(conn, kind, from, to); err != nil {
+			return err
+		}
+	}
+
+	if err := model.RemoveCompletions(conn, from); err != nil {
+		return err
+	}
+
+	for _, key := range []string{crossword.PendingAdvancesKey, acrostic.PendingRevealsKey} {
+		if _, err := conn.Do("ZREM", key, to); err != nil {
+			return err
+		}
+
+		score, err := redis.Int64(conn.Do("ZSCORE", key, from))
+		if err == redis.ErrNil {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := conn.Do("ZADD", key, score, to); err != nil {
+			return err
+		}
+		if _, err := conn.Do("ZREM", key, from); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
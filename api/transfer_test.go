@@ -0,0 +1,215 @@
+package main
+
+import (
+	"github.com/bbeck/puzzles-with-chat/api/admin"
+	"github.com/bbeck/puzzles-with-chat/api/crossword"
+	"github.com/bbeck/puzzles-with-chat/api/model"
+	"github.com/bbeck/puzzles-with-chat/api/spellingbee"
+	"github.com/go-chi/chi"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoute_TransferChannel(t *testing.T) {
+	router, pool, registry := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	ForceAdminToken(t, "secret")
+
+	oldEvents := crossword.NewEventSubscription(t, registry, "oldname")
+	newEvents := crossword.NewEventSubscription(t, registry, "newname")
+
+	// Start a crossword solve with custom settings on the old channel.
+	state := crossword.NewState(t, "xwordinfo-nyt-20181231.json")
+	state.Status = model.StatusSolving
+	require.NoError(t, crossword.SetState(conn, "oldname", state))
+
+	settings := crossword.Settings{OnlyAllowCorrectAnswers: true}
+	require.NoError(t, crossword.SetSettings(conn, "oldname", settings))
+
+	// Along with the channel's past solves and streak.
+	now := time.Now()
+	require.NoError(t, crossword.RecordSolve(conn, "oldname", crossword.NewSolveRecord(state, now)))
+	require.NoError(t, crossword.SetStreak(conn, "oldname", crossword.Streak{}.Advance(now)))
+
+	// And a completion that was announced and an advance that's still pending.
+	require.NoError(t, model.RecordCompletion(conn, model.Completion{Type: "crossword", Channel: "oldname", Time: now}))
+	require.NoError(t, crossword.AddPendingAdvance(conn, "oldname", now.Add(time.Hour)))
+
+	response := POST("/transfer", `{"from": "oldname", "to": "newname"}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	// The solve should now be readable under the new name.
+	transferred, err := crossword.GetState(conn, "newname")
+	require.NoError(t, err)
+	require.NotNil(t, transferred.Puzzle)
+	assert.Equal(t, model.StatusSolving, transferred.Status)
+	assert.Equal(t, state.Puzzle.Description, transferred.Puzzle.Description)
+
+	transferredSettings, err := crossword.GetSettings(conn, "newname")
+	require.NoError(t, err)
+	assert.True(t, transferredSettings.OnlyAllowCorrectAnswers)
+
+	// The state should keep its expiration.
+	ttl, err := redis.Int(conn.Do("TTL", crossword.StateKey("newname")))
+	require.NoError(t, err)
+	assert.True(t, ttl > 0)
+
+	// The stats and streak should have moved too.
+	records, err := crossword.GetSolveRecords(conn, "newname", 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(records))
+
+	streak, err := crossword.GetStreak(conn, "newname")
+	require.NoError(t, err)
+	assert.Equal(t, 1, streak.Count)
+
+	// And nothing should remain under the old name.
+	keys, err := FindChannelKeys(conn, "oldname")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	// The channel is only active under its new name.
+	response = GET("/active", router)
+	require.Equal(t, http.StatusOK, response.Code)
+	activities := ParseActivity(t, response)
+	require.Len(t, activities, 1)
+	assert.Equal(t, "crossword", activities[0].Type)
+	assert.Equal(t, "newname", activities[0].Name)
+
+	// Its completions under the old name are gone and its pending advance
+	// follows it to the new name.
+	completions, err := model.GetRecentCompletions(conn, 10)
+	require.NoError(t, err)
+	assert.Empty(t, completions)
+
+	pending, err := redis.Strings(conn.Do("ZRANGE", crossword.PendingAdvancesKey, 0, -1))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"newname"}, pending)
+
+	// Clients of both channels are sent the new states.
+	require.Equal(t, 1, len(oldEvents))
+	assert.Nil(t, (<-oldEvents).Payload.(crossword.State).Puzzle)
+
+	require.Equal(t, 1, len(newEvents))
+	published := (<-newEvents).Payload.(crossword.State)
+	require.NotNil(t, published.Puzzle)
+	assert.Nil(t, published.Puzzle.Cells)
+
+	// The old channel's events aren't replayed to clients that connect to it.
+	events, _ := registry.EventsSince(crossword.ChannelID("oldname"), 0)
+	assert.Empty(t, events)
+}
+
+func TestRoute_TransferChannel_Force(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	ForceAdminToken(t, "secret")
+
+	old := crossword.NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, crossword.SetState(conn, "oldname", old))
+
+	// The new channel already has a solve of a different puzzle type.
+	existing := spellingbee.NewState(t, "nytbee-20180729.json")
+	require.NoError(t, spellingbee.SetState(conn, "newname", existing))
+
+	// Without forcing the existing solve can't be clobbered.
+	response := POST("/transfer", `{"from": "oldname", "to": "newname"}`, router)
+	require.Equal(t, http.StatusConflict, response.Code)
+
+	state, err := crossword.GetState(conn, "oldname")
+	require.NoError(t, err)
+	assert.NotNil(t, state.Puzzle)
+
+	// When forced the new channel's data is replaced.
+	response = POST("/transfer", `{"from": "oldname", "to": "newname", "force": true}`, router)
+	require.Equal(t, http.StatusOK, response.Code)
+
+	state, err = crossword.GetState(conn, "newname")
+	require.NoError(t, err)
+	assert.NotNil(t, state.Puzzle)
+
+	bee, err := spellingbee.GetState(conn, "newname")
+	require.NoError(t, err)
+	assert.Nil(t, bee.Puzzle)
+}
+
+func TestRoute_TransferChannel_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected int
+	}{
+		{
+			name:     "malformed body",
+			json:     `{`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "missing new name",
+			json:     `{"from": "oldname"}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid new name",
+			json:     `{"from": "oldname", "to": "new:name"}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "same name",
+			json:     `{"from": "oldname", "to": "oldname"}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "no data for old name",
+			json:     `{"from": "unknown", "to": "newname"}`,
+			expected: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router, pool, _ := NewTestRouter(t)
+			conn := NewRedisConnection(t, pool)
+			ForceAdminToken(t, "secret")
+
+			state := crossword.NewState(t, "xwordinfo-nyt-20181231.json")
+			require.NoError(t, crossword.SetState(conn, "oldname", state))
+
+			response := POST("/transfer", test.json, router)
+			assert.Equal(t, test.expected, response.Code)
+		})
+	}
+}
+
+func TestRoute_TransferChannel_RequiresAdmin(t *testing.T) {
+	router, pool, _ := NewTestRouter(t)
+	conn := NewRedisConnection(t, pool)
+	ForceAdminToken(t, "secret")
+
+	state := crossword.NewState(t, "xwordinfo-nyt-20181231.json")
+	require.NoError(t, crossword.SetState(conn, "oldname", state))
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/transfer", strings.NewReader(`{"from": "oldname", "to": "newname"}`))
+	router.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	// Nothing was moved.
+	keys, err := FindChannelKeys(conn, "newname")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func POST(url, body string, router chi.Router) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer "+admin.Token)
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
@@ -74,6 +74,9 @@ func (l *ChannelLocator) Run(ctx context.Context, update UpdateFunc, fail FailFu
 			case "ping":
 				// do nothing
 
+			case "idle":
+				// The api closes idle streams, they're reopened automatically.
+
 			default:
 				err := fmt.Errorf("unrecognized event kind: %s", event.Kind)
 				fail(err)
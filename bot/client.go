@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"github.com/gempir/go-twitch-irc/v2"
 	"io"
+	"log"
 	"net"
 	"os"
 	"regexp"
@@ -26,10 +27,13 @@ type Client interface {
 
 	// Depart from a channel and stop processing messages from it.
 	Depart(channel string)
+
+	// Say sends a message to a channel's chat.
+	Say(channel, message string)
 }
 
 type ClientMessageHandler interface {
-	HandleChannelMessage(channel, userid, username, message string)
+	HandleChannelMessage(channel, userid, username, message string, mod bool, tags map[string]string)
 }
 
 // NewClient constructs a new client instance that's wired to the provided
@@ -70,13 +74,21 @@ func NewClient(handler ClientMessageHandler) (Client, error) {
 		channel := message.Channel
 		uid := message.User.ID
 		user := message.User.DisplayName
+		mod := IsModerator(message.User.Badges)
 
-		handler.HandleChannelMessage(channel, uid, user, message.Message)
+		handler.HandleChannelMessage(channel, uid, user, message.Message, mod, message.Tags)
 	})
 
 	return client, nil
 }
 
+// IsModerator determines if a user with the provided Twitch badges is allowed
+// to moderate a channel.  The broadcaster is always considered a moderator of
+// their own channel.
+func IsModerator(badges map[string]int) bool {
+	return badges["broadcaster"] > 0 || badges["moderator"] > 0
+}
+
 // LocalClient listens on a local network socket and returns messages based on
 // the commands it receives.
 type LocalClient struct {
@@ -87,6 +99,12 @@ type LocalClient struct {
 func (c *LocalClient) Join(...string) {}
 func (c *LocalClient) Depart(string)  {}
 
+// Say logs a message instead of sending it since there's no chat to send it to
+// when running locally.
+func (c *LocalClient) Say(channel, message string) {
+	log.Printf("[%s] %s", channel, message)
+}
+
 // Connect implements a small REPL on a network socket that allows a user to
 // use the connection as a means for providing input into the bot.
 func (c *LocalClient) Connect() error {
@@ -206,7 +224,12 @@ func (c *LocalClient) REPL(r io.Reader, w io.Writer) bool {
 			return true
 		}
 
-		c.handler.HandleChannelMessage(channel, id(user), user, input)
+		// There aren't any badges when running locally, so the owner of the
+		// channel is the only moderator.
+		mod := strings.EqualFold(user, channel)
+		tags := map[string]string{"user-id": id(user)}
+
+		c.handler.HandleChannelMessage(channel, id(user), user, input, mod, tags)
 	}
 }
 
@@ -187,6 +187,28 @@ func TestLocalClient_REPL(t *testing.T) {
 				assert.NotEqual(t, messages[0].userid, messages[1].userid)
 			},
 		},
+		{
+			name: "channel owner is a moderator",
+			inputs: []string{
+				"/channel foo",
+				"test",
+				"/user foo",
+				"test",
+			},
+			expectedNumMessages: 2,
+			verify: func(t *testing.T, messages []SeenMessage) {
+				assert.False(t, messages[0].mod)
+				assert.True(t, messages[1].mod)
+			},
+		},
+		{
+			name:                "user id included in tags",
+			inputs:              []string{"/user foo", "test"},
+			expectedNumMessages: 1,
+			verify: func(t *testing.T, messages []SeenMessage) {
+				assert.Equal(t, messages[0].userid, messages[0].tags["user-id"])
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -211,6 +233,13 @@ func TestLocalClient_REPL(t *testing.T) {
 	}
 }
 
+func TestIsModerator(t *testing.T) {
+	assert.True(t, IsModerator(map[string]int{"broadcaster": 1}))
+	assert.True(t, IsModerator(map[string]int{"moderator": 1, "subscriber": 12}))
+	assert.False(t, IsModerator(map[string]int{"subscriber": 12}))
+	assert.False(t, IsModerator(nil))
+}
+
 func GetFreePort(t *testing.T) int {
 	addr, err := net.ResolveTCPAddr("tcp", ":0")
 	require.NoError(t, err)
@@ -227,6 +256,8 @@ type SeenMessage struct {
 	userid   string
 	username string
 	message  string
+	mod      bool
+	tags     map[string]string
 }
 
 type RecordingMessageHandler struct {
@@ -243,12 +274,14 @@ func (i *RecordingMessageHandler) GetActiveChannelNames() ([]string, error) {
 	return nil, nil
 }
 
-func (i *RecordingMessageHandler) HandleChannelMessage(channel, userid, username, message string) {
+func (i *RecordingMessageHandler) HandleChannelMessage(channel, userid, username, message string, mod bool, tags map[string]string) {
 	i.seen = append(i.seen, SeenMessage{
 		channel:  channel,
 		userid:   userid,
 		username: username,
 		message:  message,
+		mod:      mod,
+		tags:     tags,
 	})
 	i.latch.CountDown()
 }
@@ -3,11 +3,18 @@ package crossword
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/bbeck/puzzles-with-chat/bot/web"
+	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
 	"regexp"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -23,44 +30,152 @@ var AnswerRegexp = regexp.MustCompile(
 	`^!(?i:answer\s+)?([0-9]+[aAdD])\s+(.*)\s*$`,
 )
 
+// A regular expression that matches a message that's providing an answer using
+// the single token clue=answer shorthand (e.g. 14a=THIRD).  Capture group 1 is
+// the clue and capture group 2 is the answer, neither has been validated.  A
+// message without the ! prefix is only treated as an answer when both are
+// valid.
+var ShorthandAnswerRegexp = regexp.MustCompile(
+	`^!?([0-9]+[a-zA-Z]+)=(\S*)\s*$`,
+)
+
+// A regular expression that matches a valid clue.
+var ClueRegexp = regexp.MustCompile(
+	`^[0-9]+[aAdD]$`,
+)
+
+// ErrNotShorthand is returned when parsing a message that isn't using the
+// clue=answer shorthand.
+var ErrNotShorthand = errors.New("message isn't a shorthand answer")
+
 // A regular expression that matches a message that's asking for a clue to be
-// made visible.  Capture group 1 is the clue.
+// made visible.  Capture group 1 is the clue and capture group 2 is the
+// optional duration (e.g. 30s or 2m) after which the clue is hidden again.
 var ShowClueRegexp = regexp.MustCompile(
-	`^!(?i:show)\s+(?P<clue>[0-9]+[aAdD])\s*$`,
+	`^!(?i:show)\s+(?P<clue>[0-9]+[aAdD])(?:\s+([0-9]+[sSmM]))?\s*$`,
+)
+
+// A regular expression that matches a message that's asking for the cells of a
+// clue to be locked or unlocked.  Capture group 1 is the command (lock or
+// unlock) and capture group 2 is the clue.
+var LockRegexp = regexp.MustCompile(
+	`^!((?i:lock|unlock))\s+([0-9]+[aAdD])\s*$`,
+)
+
+// A regular expression that matches a message that's asking for the progress
+// of the solve to be reported in chat.
+var ProgressRegexp = regexp.MustCompile(
+	`^!(?i:progress)\s*$`,
+)
+
+// A regular expression that matches a message that's asking for the users that
+// have solved the most clues to be reported in chat.
+var LeaderboardRegexp = regexp.MustCompile(
+	`^!(?i:leaderboard)\s*$`,
+)
+
+// A regular expression that matches a message that's asking how long a clue has
+// been open.  Capture group 1 is the clue.
+var AgeRegexp = regexp.MustCompile(
+	`^!(?i:age)\s+([0-9]+[aAdD])\s*$`,
 )
 
+// The HTTP client to use when asking the api service to load a new puzzle.
+// Loading a puzzle may require downloading it from its publisher, so this has a
+// longer timeout than the client used for the other commands.
+var DefaultPuzzleHTTPClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+// A regular expression that matches a message that's asking for a puzzle to be
+// selected.  Capture group 1 is the source and capture group 2 is the date.
+var PuzzleRegexp = regexp.MustCompile(
+	`^!(?i:puzzle)\s+(\S+)\s+(\S+)\s*$`,
+)
+
+// PuzzleSources maps the names of the puzzle sources that can be used in chat
+// to the key that the api service uses to load a puzzle from that source.
+var PuzzleSources = map[string]string{
+	"atlantic": "atlantic_date",
+	"jonesin":  "jonesin_date",
+	"nyt":      "new_york_times_date",
+	"mini":     "new_york_times_mini_date",
+	"cryptic":  "new_york_times_cryptic_date",
+	"wsj":      "wall_street_journal_date",
+	"wapo":     "washington_post_date",
+}
+
+// The minimum amount of time between progress reports in a channel.  Requests
+// for progress that arrive sooner than this after the previous report are
+// ignored in order to keep the command from spamming chat.
+var ProgressThrottle = 30 * time.Second
+
+// The minimum amount of time between leaderboard reports in a channel.
+var LeaderboardThrottle = 30 * time.Second
+
+// The minimum amount of time between reports of how long a clue has been open
+// in a channel.
+var AgeThrottle = 10 * time.Second
+
+// The number of ranks from the top of the leaderboard that are reported in
+// chat.  Users that are tied share a rank, so more users than this may be
+// reported.
+var LeaderboardSize = 3
+
 type MessageHandler struct {
 	baseURL string
+
+	// Say sends a message to a channel's chat.  If it isn't set then messages
+	// are logged instead.
+	Say func(channel, message string)
+
+	// The last time that progress, the leaderboard and the age of a clue were
+	// reported in each channel.
+	sync.Mutex
+	lastProgress    map[string]time.Time
+	lastLeaderboard map[string]time.Time
+	lastAge         map[string]time.Time
 }
 
 func NewMessageHandler(host string) *MessageHandler {
 	url := fmt.Sprintf("http://%s/api/crossword", host)
-	return &MessageHandler{baseURL: url}
+	return &MessageHandler{
+		baseURL:         url,
+		lastProgress:    make(map[string]time.Time),
+		lastLeaderboard: make(map[string]time.Time),
+		lastAge:         make(map[string]time.Time),
+	}
 }
 
 // HandleChannelMessage parses a message and if it matches a crossword command
 // sends it to the appropriate API endpoint.
-func (h *MessageHandler) HandleChannelMessage(channel, status, message string) {
+func (h *MessageHandler) HandleChannelMessage(channel, status, user, message string, mod bool, tags map[string]string) {
 	if match := AnswerRegexp.FindStringSubmatch(message); len(match) != 0 {
 		if status != "solving" {
 			return
 		}
 
-		clue := match[1]
-		answer := match[2]
+		h.submitAnswer(channel, user, match[1], match[2], tags)
+		return
+	}
 
-		bs, err := json.Marshal(answer)
-		if err != nil {
-			log.Printf("unable to marshal answer (%s) to json: %v", answer, err)
+	if ShorthandAnswerRegexp.MatchString(message) {
+		if status != "solving" {
 			return
 		}
 
-		url := fmt.Sprintf("%s/%s/answer/%s", h.baseURL, channel, clue)
-		response, err := web.PutWithClient(DefaultCrosswordHTTPClient, url, bytes.NewReader(bs))
-		defer func() { _ = response.Body.Close() }()
+		// Without the command prefix the message may just be ordinary chat that
+		// happens to contain an = (e.g. 10pm=bedtime), so problems are only
+		// reported when the message was clearly meant for the bot.
+		clue, answer, err := ParseShorthandAnswer(message)
 		if err != nil {
-			log.Printf("error applying answer, url: %s, answer: %s\n", url, answer)
+			if strings.HasPrefix(message, "!") {
+				h.say(channel, err.Error())
+			}
+			return
 		}
+
+		h.submitAnswer(channel, user, clue, answer, tags)
 		return
 	}
 
@@ -68,6 +183,11 @@ func (h *MessageHandler) HandleChannelMessage(channel, status, message string) {
 		clue := match[1]
 
 		url := fmt.Sprintf("%s/%s/show/%s", h.baseURL, channel, clue)
+
+		// Only moderators can have a clue hidden again automatically.
+		if duration := match[2]; duration != "" && mod {
+			url = fmt.Sprintf("%s?duration=%s", url, strings.ToLower(duration))
+		}
 		response, err := web.GetWithClient(DefaultCrosswordHTTPClient, url, nil)
 		defer func() { _ = response.Body.Close() }()
 		if err != nil {
@@ -75,4 +195,345 @@ func (h *MessageHandler) HandleChannelMessage(channel, status, message string) {
 		}
 		return
 	}
+
+	if match := LockRegexp.FindStringSubmatch(message); len(match) != 0 {
+		if !mod {
+			return
+		}
+
+		command := strings.ToLower(match[1])
+		clue := match[2]
+
+		bs, err := json.Marshal(map[string]string{"clue": clue})
+		if err != nil {
+			log.Printf("unable to marshal clue (%s) to json: %v", clue, err)
+			return
+		}
+
+		url := fmt.Sprintf("%s/%s/%s", h.baseURL, channel, command)
+		response, err := web.PutWithClient(DefaultCrosswordHTTPClient, url, bytes.NewReader(bs))
+		if response != nil {
+			defer func() { _ = response.Body.Close() }()
+		}
+		if err != nil {
+			log.Printf("error updating locked cells, url: %s, clue: %s: %v", url, clue, err)
+		}
+		return
+	}
+
+	if match := ProgressRegexp.FindStringSubmatch(message); len(match) != 0 {
+		if !h.allow(h.lastProgress, ProgressThrottle, channel) {
+			return
+		}
+
+		url := fmt.Sprintf("%s/%s/progress", h.baseURL, channel)
+		response, err := web.GetWithClient(DefaultCrosswordHTTPClient, url, nil)
+		if response != nil {
+			defer func() { _ = response.Body.Close() }()
+		}
+		if err != nil {
+			log.Printf("error loading progress, url: %s", url)
+			return
+		}
+
+		var progress Progress
+		if err := json.NewDecoder(response.Body).Decode(&progress); err != nil {
+			log.Printf("unable to parse progress json, url: %s: %v", url, err)
+			return
+		}
+
+		h.say(channel, FormatProgress(progress))
+		h.reported(h.lastProgress, channel)
+		return
+	}
+
+	if match := LeaderboardRegexp.FindStringSubmatch(message); len(match) != 0 {
+		if !h.allow(h.lastLeaderboard, LeaderboardThrottle, channel) {
+			return
+		}
+
+		url := fmt.Sprintf("%s/%s/leaderboard", h.baseURL, channel)
+		response, err := web.GetWithClient(DefaultCrosswordHTTPClient, url, nil)
+		if response != nil {
+			defer func() { _ = response.Body.Close() }()
+		}
+		if err != nil {
+			log.Printf("error loading leaderboard, url: %s", url)
+			return
+		}
+
+		var leaderboard []LeaderboardEntry
+		if err := json.NewDecoder(response.Body).Decode(&leaderboard); err != nil {
+			log.Printf("unable to parse leaderboard json, url: %s: %v", url, err)
+			return
+		}
+
+		h.say(channel, FormatLeaderboard(leaderboard))
+		h.reported(h.lastLeaderboard, channel)
+		return
+	}
+
+	if match := AgeRegexp.FindStringSubmatch(message); len(match) != 0 {
+		if status != "solving" && status != "paused" {
+			return
+		}
+
+		if !h.allow(h.lastAge, AgeThrottle, channel) {
+			return
+		}
+
+		clue := strings.ToLower(match[1])
+
+		url := fmt.Sprintf("%s/%s/clue/%s/age", h.baseURL, channel, clue)
+		response, err := web.GetWithClient(DefaultCrosswordHTTPClient, url, nil)
+		if response != nil {
+			defer func() { _ = response.Body.Close() }()
+		}
+		if err != nil {
+			log.Printf("error loading clue age, url: %s", url)
+			return
+		}
+
+		var age ClueAge
+		if err := json.NewDecoder(response.Body).Decode(&age); err != nil {
+			log.Printf("unable to parse clue age json, url: %s: %v", url, err)
+			return
+		}
+
+		h.say(channel, FormatClueAge(age))
+		h.reported(h.lastAge, channel)
+		return
+	}
+
+	if match := PuzzleRegexp.FindStringSubmatch(message); len(match) != 0 {
+		if !mod {
+			return
+		}
+
+		source := strings.ToLower(match[1])
+		date := match[2]
+
+		key, ok := PuzzleSources[source]
+		if !ok {
+			h.say(channel, fmt.Sprintf("Unknown puzzle source %s, try one of: %s", match[1], PuzzleSourceNames()))
+			return
+		}
+
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			h.say(channel, fmt.Sprintf("Invalid date %s, dates look like 2023-05-01", date))
+			return
+		}
+
+		bs, err := json.Marshal(map[string]string{key: date})
+		if err != nil {
+			log.Printf("unable to marshal puzzle selection (%s %s) to json: %v", key, date, err)
+			return
+		}
+
+		endpoint := fmt.Sprintf("%s/%s", h.baseURL, channel)
+		response, err := web.PutWithClient(DefaultPuzzleHTTPClient, endpoint, bytes.NewReader(bs))
+		if response != nil {
+			defer func() { _ = response.Body.Close() }()
+		}
+		if err != nil {
+			log.Printf("error selecting puzzle, url: %s, source: %s, date: %s: %v", endpoint, source, date, err)
+			h.say(channel, fmt.Sprintf("Unable to load the %s puzzle from %s", source, date))
+			return
+		}
+
+		h.say(channel, fmt.Sprintf("Loaded the %s puzzle from %s", source, date))
+		return
+	}
+}
+
+// submitAnswer sends an answer for a clue to the API on behalf of a user.
+func (h *MessageHandler) submitAnswer(channel, user, clue, answer string, tags map[string]string) {
+	bs, err := json.Marshal(answer)
+	if err != nil {
+		log.Printf("unable to marshal answer (%s) to json: %v", answer, err)
+		return
+	}
+
+	// Include who submitted the answer so that they can be credited with
+	// solving the clue, along with whether they cheered when doing so.
+	values := url.Values{"user": []string{user}}
+	if id := tags["user-id"]; id != "" {
+		values.Set("user_id", id)
+	}
+	if bits := tags["bits"]; bits != "" {
+		values.Set("bits", bits)
+	}
+	query := values.Encode()
+
+	endpoint := fmt.Sprintf("%s/%s/answer/%s?%s", h.baseURL, channel, clue, query)
+	response, err := web.PutWithClient(DefaultCrosswordHTTPClient, endpoint, bytes.NewReader(bs))
+	if response != nil {
+		defer func() { _ = response.Body.Close() }()
+	}
+	if err != nil {
+		log.Printf("error applying answer, url: %s, answer: %s\n", endpoint, answer)
+	}
+
+	// When the answer was rejected with an explanation (e.g. because it doesn't
+	// fit the clue) let the user know why.
+	if response != nil && response.StatusCode == http.StatusBadRequest {
+		reason, err := ioutil.ReadAll(io.LimitReader(response.Body, 256))
+		if err != nil {
+			log.Printf("unable to read answer rejection, url: %s: %v", endpoint, err)
+			return
+		}
+
+		if reason := strings.TrimSpace(string(reason)); reason != "" {
+			h.say(channel, fmt.Sprintf("@%s %s: %s", user, clue, reason))
+		}
+	}
+}
+
+// ParseShorthandAnswer parses a message that uses the clue=answer shorthand
+// (e.g. 14a=THIRD) into its clue and answer.  If the message isn't using the
+// shorthand then ErrNotShorthand is returned.  When the clue or answer is
+// malformed the error describes the problem in a way that's suitable for
+// sending to chat.
+func ParseShorthandAnswer(message string) (string, string, error) {
+	match := ShorthandAnswerRegexp.FindStringSubmatch(message)
+	if len(match) == 0 {
+		return "", "", ErrNotShorthand
+	}
+
+	clue, answer := match[1], match[2]
+	if !ClueRegexp.MatchString(clue) {
+		return "", "", fmt.Errorf("Invalid clue %s, clues look like 14a or 3d", clue)
+	}
+
+	if answer == "" {
+		return "", "", fmt.Errorf("Missing answer for clue %s, answers look like %s=THIRD", clue, clue)
+	}
+
+	if strings.Contains(answer, "=") {
+		return "", "", fmt.Errorf("Invalid answer %s for clue %s, answers can't contain =", answer, clue)
+	}
+
+	return clue, answer, nil
+}
+
+// PuzzleSourceNames returns the names of the puzzle sources that can be used
+// in chat in sorted order separated by commas.
+func PuzzleSourceNames() string {
+	var names []string
+	for name := range PuzzleSources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+// allow determines if a throttled report is allowed to be sent to the channel's
+// chat right now given the times of the previous reports.
+func (h *MessageHandler) allow(last map[string]time.Time, throttle time.Duration, channel string) bool {
+	h.Lock()
+	defer h.Unlock()
+
+	t, ok := last[channel]
+	return !ok || time.Since(t) >= throttle
+}
+
+// reported records that a throttled report was sent to the channel's chat.
+// This only happens once the report has actually been sent so that a failed
+// request to the api service doesn't keep the channel from trying again.
+func (h *MessageHandler) reported(last map[string]time.Time, channel string) {
+	h.Lock()
+	defer h.Unlock()
+
+	last[channel] = time.Now()
+}
+
+// say sends a message to a channel's chat.
+func (h *MessageHandler) say(channel, message string) {
+	if h.Say == nil {
+		log.Printf("[%s] %s", channel, message)
+		return
+	}
+
+	h.Say(channel, message)
+}
+
+// Progress describes how far along a crossword solve is.
+type Progress struct {
+	CellsFilled int `json:"cells_filled"`
+	CellsTotal  int `json:"cells_total"`
+	CluesFilled int `json:"clues_filled"`
+	CluesTotal  int `json:"clues_total"`
+	Percent     int `json:"percent"`
+}
+
+// FormatProgress formats the progress of a solve as a message suitable for
+// sending to chat.
+func FormatProgress(progress Progress) string {
+	return fmt.Sprintf("We're %d%% done (%d/%d clues)", progress.Percent, progress.CluesFilled, progress.CluesTotal)
+}
+
+// ClueAge describes how long a clue has been open, measured in solve time.
+type ClueAge struct {
+	Clue   string `json:"clue"`
+	Filled bool   `json:"filled"`
+	Age    string `json:"age"`
+}
+
+// FormatClueAge formats how long a clue has been open as a message suitable for
+// sending to chat.  The age is shown as minutes and seconds (or hours, minutes
+// and seconds for clues that have been open a very long time).
+func FormatClueAge(age ClueAge) string {
+	if age.Filled {
+		return fmt.Sprintf("%s is already filled in", age.Clue)
+	}
+
+	duration, err := time.ParseDuration(age.Age)
+	if err != nil {
+		duration = 0
+	}
+
+	seconds := int(duration / time.Second)
+	hours, minutes := seconds/3600, seconds/60%60
+	seconds = seconds % 60
+
+	if hours > 0 {
+		return fmt.Sprintf("%s has been open %d:%02d:%02d", age.Clue, hours, minutes, seconds)
+	}
+	return fmt.Sprintf("%s has been open %d:%02d", age.Clue, minutes, seconds)
+}
+
+// LeaderboardEntry describes how many clues a single user has solved.
+type LeaderboardEntry struct {
+	User  string `json:"user"`
+	Clues int    `json:"clues"`
+	Rank  int    `json:"rank"`
+}
+
+// FormatLeaderboard formats the top of a leaderboard as a message suitable for
+// sending to chat.  Users that are tied are reported together under their
+// shared rank.
+func FormatLeaderboard(leaderboard []LeaderboardEntry) string {
+	if len(leaderboard) == 0 {
+		return "Nobody has solved a clue yet"
+	}
+
+	var parts []string
+	for i := 0; i < len(leaderboard) && leaderboard[i].Rank <= LeaderboardSize; {
+		entry := leaderboard[i]
+
+		var users []string
+		for ; i < len(leaderboard) && leaderboard[i].Rank == entry.Rank; i++ {
+			users = append(users, leaderboard[i].User)
+		}
+
+		noun := "clues"
+		if entry.Clues == 1 {
+			noun = "clue"
+		}
+
+		parts = append(parts, fmt.Sprintf("%d. %s (%d %s)", entry.Rank, strings.Join(users, ", "), entry.Clues, noun))
+	}
+
+	return "Top solvers: " + strings.Join(parts, " | ")
 }
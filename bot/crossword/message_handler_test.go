@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 )
 
 func TestMessageHandler_HandleChannelMessage(t *testing.T) {
@@ -76,6 +77,60 @@ func TestMessageHandler_HandleChannelMessage(t *testing.T) {
 				"complete": {},
 			},
 		},
+		{
+			name:    "shorthand answer",
+			message: "14a=THIRD",
+			expected: Expected{
+				"solving":  {"/api/crossword/channel/answer/14a", `"THIRD"`},
+				"paused":   {},
+				"complete": {},
+			},
+		},
+		{
+			name:    "shorthand answer with command prefix",
+			message: "!14D=third",
+			expected: Expected{
+				"solving":  {"/api/crossword/channel/answer/14D", `"third"`},
+				"paused":   {},
+				"complete": {},
+			},
+		},
+		{
+			name:    "malformed shorthand answer",
+			message: "14x=THIRD",
+			expected: Expected{
+				"solving":  {},
+				"paused":   {},
+				"complete": {},
+			},
+		},
+		{
+			name:    "chat that looks like shorthand, time",
+			message: "10pm=bedtime",
+			expected: Expected{
+				"solving":  {},
+				"paused":   {},
+				"complete": {},
+			},
+		},
+		{
+			name:    "chat that looks like shorthand, math",
+			message: "2x=4",
+			expected: Expected{
+				"solving":  {},
+				"paused":   {},
+				"complete": {},
+			},
+		},
+		{
+			name:    "chat that looks like shorthand, missing answer",
+			message: "1a=",
+			expected: Expected{
+				"solving":  {},
+				"paused":   {},
+				"complete": {},
+			},
+		},
 		{
 			name:    "show command",
 			message: "!show 1A",
@@ -118,6 +173,9 @@ func TestMessageHandler_HandleChannelMessage(t *testing.T) {
 
 					path = r.URL.Path
 					body = string(bs)
+					if r.Method == http.MethodPut {
+						assert.Equal(t, "user", r.URL.Query().Get("user"))
+					}
 				}))
 				defer server.Close()
 
@@ -125,7 +183,7 @@ func TestMessageHandler_HandleChannelMessage(t *testing.T) {
 				require.NoError(t, err)
 
 				handler := NewMessageHandler(parsed.Host)
-				handler.HandleChannelMessage("channel", status, test.message)
+				handler.HandleChannelMessage("channel", status, "user", test.message, false, nil)
 
 				assert.Equal(t, expected.path, path)
 				assert.Equal(t, expected.body, body)
@@ -133,3 +191,750 @@ func TestMessageHandler_HandleChannelMessage(t *testing.T) {
 		}
 	}
 }
+
+func TestMessageHandler_ShorthandAnswerError(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	var said []string
+	handler := NewMessageHandler(parsed.Host)
+	handler.Say = func(channel, message string) {
+		assert.Equal(t, "channel", channel)
+		said = append(said, message)
+	}
+
+	handler.HandleChannelMessage("channel", "solving", "user", "!14a=", false, nil)
+	assert.Equal(t, 0, requests)
+	assert.Equal(t, []string{"Missing answer for clue 14a, answers look like 14a=THIRD"}, said)
+
+	// Errors aren't reported when the puzzle isn't being solved.
+	handler.HandleChannelMessage("channel", "paused", "user", "!14a=", false, nil)
+	assert.Equal(t, 1, len(said))
+
+	// Ordinary chat without the command prefix is ignored.
+	for _, message := range []string{"10pm=bedtime", "2x=4", "1a=", "1a=TH=IRD"} {
+		handler.HandleChannelMessage("channel", "solving", "user", message, false, nil)
+	}
+	assert.Equal(t, 0, requests)
+	assert.Equal(t, 1, len(said))
+}
+
+func TestMessageHandler_AnswerRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected []string
+	}{
+		{
+			name:     "answer doesn't fit",
+			status:   http.StatusBadRequest,
+			body:     "expected 5 letters, got 4\n",
+			expected: []string{"@user 1a: expected 5 letters, got 4"},
+		},
+		{
+			name:   "rejected without a reason",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "accepted",
+			status: http.StatusOK,
+			body:   "ok",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			parsed, err := url.Parse(server.URL)
+			require.NoError(t, err)
+
+			var said []string
+			handler := NewMessageHandler(parsed.Host)
+			handler.Say = func(channel, message string) {
+				assert.Equal(t, "channel", channel)
+				said = append(said, message)
+			}
+
+			handler.HandleChannelMessage("channel", "solving", "user", "!1a qand", false, nil)
+			assert.Equal(t, test.expected, said)
+		})
+	}
+}
+
+func TestParseShorthandAnswer(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		clue    string
+		answer  string
+		err     string // empty if no error is expected
+	}{
+		{
+			name:    "across clue",
+			message: "14a=THIRD",
+			clue:    "14a",
+			answer:  "THIRD",
+		},
+		{
+			name:    "down clue",
+			message: "3D=qtip",
+			clue:    "3D",
+			answer:  "qtip",
+		},
+		{
+			name:    "command prefix",
+			message: "!14a=THIRD",
+			clue:    "14a",
+			answer:  "THIRD",
+		},
+		{
+			name:    "trailing whitespace",
+			message: "14a=THIRD  ",
+			clue:    "14a",
+			answer:  "THIRD",
+		},
+		{
+			name:    "not shorthand",
+			message: "hello there",
+			err:     ErrNotShorthand.Error(),
+		},
+		{
+			name:    "multiple tokens",
+			message: "14a=THIRD and more",
+			err:     ErrNotShorthand.Error(),
+		},
+		{
+			name:    "missing direction",
+			message: "14=THIRD",
+			err:     ErrNotShorthand.Error(),
+		},
+		{
+			name:    "invalid direction",
+			message: "14x=THIRD",
+			err:     "Invalid clue 14x, clues look like 14a or 3d",
+		},
+		{
+			name:    "missing answer",
+			message: "14a=",
+			err:     "Missing answer for clue 14a, answers look like 14a=THIRD",
+		},
+		{
+			name:    "multiple separators",
+			message: "14a=TH=IRD",
+			err:     "Invalid answer TH=IRD for clue 14a, answers can't contain =",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clue, answer, err := ParseShorthandAnswer(test.message)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.clue, clue)
+			assert.Equal(t, test.answer, answer)
+		})
+	}
+}
+
+func TestMessageHandler_Progress(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/api/crossword/channel/progress", r.URL.Path)
+		_, _ = w.Write([]byte(`{"cells_filled":116,"cells_total":187,"clues_filled":41,"clues_total":66,"percent":62}`))
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	var said []string
+	handler := NewMessageHandler(parsed.Host)
+	handler.Say = func(channel, message string) {
+		assert.Equal(t, "channel", channel)
+		said = append(said, message)
+	}
+
+	// The first request should be reported.
+	handler.HandleChannelMessage("channel", "solving", "user", "!progress", false, nil)
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, []string{"We're 62% done (41/66 clues)"}, said)
+
+	// A second request right afterwards should be throttled.
+	handler.HandleChannelMessage("channel", "solving", "user", "!PROGRESS", false, nil)
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, 1, len(said))
+
+	// A request in a different channel isn't affected by the throttle.
+	handler.Say = func(channel, message string) {
+		assert.Equal(t, "other", channel)
+		said = append(said, message)
+	}
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"clues_filled":0,"clues_total":66,"percent":0}`))
+	})
+	handler.HandleChannelMessage("other", "paused", "user", "!progress", false, nil)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, "We're 0% done (0/66 clues)", said[1])
+
+	// Once the throttle duration has passed progress is reported again.
+	ProgressThrottle = 0
+	defer func() { ProgressThrottle = 30 * time.Second }()
+
+	handler.HandleChannelMessage("other", "paused", "user", "!progress", false, nil)
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, 3, len(said))
+}
+
+func TestMessageHandler_Progress_FailureNotThrottled(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	var said []string
+	handler := NewMessageHandler(parsed.Host)
+	handler.Say = func(channel, message string) {
+		said = append(said, message)
+	}
+
+	// A failed request doesn't report anything.
+	handler.HandleChannelMessage("channel", "solving", "user", "!progress", false, nil)
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, 0, len(said))
+
+	// Since nothing was reported the next request isn't throttled.
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"clues_filled":41,"clues_total":66,"percent":62}`))
+	})
+	handler.HandleChannelMessage("channel", "solving", "user", "!progress", false, nil)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, []string{"We're 62% done (41/66 clues)"}, said)
+
+	// Now that progress was reported the throttle applies.
+	handler.HandleChannelMessage("channel", "solving", "user", "!progress", false, nil)
+	assert.Equal(t, 2, requests)
+}
+
+func TestMessageHandler_Age(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/api/crossword/channel/clue/14a/age", r.URL.Path)
+		_, _ = w.Write([]byte(`{"clue":"14a","filled":false,"age":"4m12.5s"}`))
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	var said []string
+	handler := NewMessageHandler(parsed.Host)
+	handler.Say = func(channel, message string) {
+		assert.Equal(t, "channel", channel)
+		said = append(said, message)
+	}
+
+	// The age isn't reported when there isn't a solve in progress.
+	handler.HandleChannelMessage("channel", "selected", "user", "!age 14a", false, nil)
+	assert.Equal(t, 0, requests)
+
+	// The first request should be reported.
+	handler.HandleChannelMessage("channel", "solving", "user", "!AGE 14A", false, nil)
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, []string{"14a has been open 4:12"}, said)
+
+	// A second request right afterwards should be throttled.
+	handler.HandleChannelMessage("channel", "paused", "user", "!age 14a", false, nil)
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, 1, len(said))
+
+	// Once the throttle duration has passed the age is reported again.
+	AgeThrottle = 0
+	defer func() { AgeThrottle = 10 * time.Second }()
+
+	handler.HandleChannelMessage("channel", "paused", "user", "!age 14a", false, nil)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 2, len(said))
+
+	// Nothing is said when the clue can't be found.
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	handler.HandleChannelMessage("channel", "solving", "user", "!age 999a", false, nil)
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, 2, len(said))
+}
+
+func TestMessageHandler_Leaderboard(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/api/crossword/channel/leaderboard", r.URL.Path)
+		_, _ = w.Write([]byte(`[{"user":"alice","clues":4,"rank":1},{"user":"bob","clues":2,"rank":2}]`))
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	var said []string
+	handler := NewMessageHandler(parsed.Host)
+	handler.Say = func(channel, message string) {
+		assert.Equal(t, "channel", channel)
+		said = append(said, message)
+	}
+
+	// The first request should be reported.
+	handler.HandleChannelMessage("channel", "solving", "user", "!leaderboard", false, nil)
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, []string{"Top solvers: 1. alice (4 clues) | 2. bob (2 clues)"}, said)
+
+	// A second request right afterwards should be throttled.
+	handler.HandleChannelMessage("channel", "solving", "user", "!LEADERBOARD", false, nil)
+	assert.Equal(t, 1, requests)
+	assert.Equal(t, 1, len(said))
+
+	// The leaderboard throttle is independent of the progress throttle.
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"clues_filled":0,"clues_total":66,"percent":0}`))
+	})
+	handler.HandleChannelMessage("channel", "solving", "user", "!progress", false, nil)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 2, len(said))
+
+	// Once the throttle duration has passed the leaderboard is reported again.
+	LeaderboardThrottle = 0
+	defer func() { LeaderboardThrottle = 30 * time.Second }()
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`[]`))
+	})
+	handler.HandleChannelMessage("channel", "solving", "user", "!leaderboard", false, nil)
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, "Nobody has solved a clue yet", said[2])
+}
+
+func TestMessageHandler_Puzzle(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		mod      bool
+		status   int    // the status code the api responds with
+		path     string // the path the api should receive, empty if no request
+		body     string // the body the api should receive
+		expected string // the message said in chat, empty if nothing is said
+	}{
+		{
+			name:     "new york times",
+			message:  "!puzzle nyt 2023-05-01",
+			mod:      true,
+			status:   http.StatusOK,
+			path:     "/api/crossword/channel",
+			body:     `{"new_york_times_date":"2023-05-01"}`,
+			expected: "Loaded the nyt puzzle from 2023-05-01",
+		},
+		{
+			name:     "mixed case command and source",
+			message:  "!PuZZle WSJ 2023-05-01",
+			mod:      true,
+			status:   http.StatusOK,
+			path:     "/api/crossword/channel",
+			body:     `{"wall_street_journal_date":"2023-05-01"}`,
+			expected: "Loaded the wsj puzzle from 2023-05-01",
+		},
+		{
+			name:    "not a moderator",
+			message: "!puzzle nyt 2023-05-01",
+		},
+		{
+			name:     "unknown source",
+			message:  "!puzzle lat 2023-05-01",
+			mod:      true,
+			expected: "Unknown puzzle source lat, try one of: atlantic, cryptic, jonesin, mini, nyt, wapo, wsj",
+		},
+		{
+			name:     "invalid date",
+			message:  "!puzzle nyt 05/01/2023",
+			mod:      true,
+			expected: "Invalid date 05/01/2023, dates look like 2023-05-01",
+		},
+		{
+			name:     "api error",
+			message:  "!puzzle nyt 2023-05-01",
+			mod:      true,
+			status:   http.StatusInternalServerError,
+			path:     "/api/crossword/channel",
+			body:     `{"new_york_times_date":"2023-05-01"}`,
+			expected: "Unable to load the nyt puzzle from 2023-05-01",
+		},
+		{
+			name:    "missing date",
+			message: "!puzzle nyt",
+			mod:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var path, body string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer r.Body.Close()
+				assert.Equal(t, http.MethodPut, r.Method)
+
+				bs, err := ioutil.ReadAll(r.Body)
+				require.NoError(t, err)
+
+				path = r.URL.Path
+				body = string(bs)
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			parsed, err := url.Parse(server.URL)
+			require.NoError(t, err)
+
+			var said string
+			handler := NewMessageHandler(parsed.Host)
+			handler.Say = func(channel, message string) {
+				assert.Equal(t, "channel", channel)
+				said = message
+			}
+
+			handler.HandleChannelMessage("channel", "solving", "user", test.message, test.mod, nil)
+			assert.Equal(t, test.path, path)
+			assert.Equal(t, test.body, body)
+			assert.Equal(t, test.expected, said)
+		})
+	}
+}
+
+func TestMessageHandler_ShowWithDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		mod     bool
+		path    string // the path the api should receive, empty if no request
+		query   string // the query the api should receive
+	}{
+		{
+			name:    "seconds",
+			message: "!show 1a 30s",
+			mod:     true,
+			path:    "/api/crossword/channel/show/1a",
+			query:   "duration=30s",
+		},
+		{
+			name:    "minutes",
+			message: "!show 10D 2M",
+			mod:     true,
+			path:    "/api/crossword/channel/show/10D",
+			query:   "duration=2m",
+		},
+		{
+			name:    "not a moderator",
+			message: "!show 1a 30s",
+			path:    "/api/crossword/channel/show/1a",
+		},
+		{
+			name:    "missing unit",
+			message: "!show 1a 30",
+			mod:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var path, query string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				query = r.URL.RawQuery
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			parsed, err := url.Parse(server.URL)
+			require.NoError(t, err)
+
+			handler := NewMessageHandler(parsed.Host)
+			handler.HandleChannelMessage("channel", "solving", "user", test.message, test.mod, nil)
+
+			assert.Equal(t, test.path, path)
+			assert.Equal(t, test.query, query)
+		})
+	}
+}
+
+func TestMessageHandler_AnswerTags(t *testing.T) {
+	tests := []struct {
+		name  string
+		tags  map[string]string
+		query string // the query the api should receive
+	}{
+		{
+			name:  "no tags",
+			query: "user=user",
+		},
+		{
+			name:  "user id",
+			tags:  map[string]string{"user-id": "12345"},
+			query: "user=user&user_id=12345",
+		},
+		{
+			name:  "user id and bits",
+			tags:  map[string]string{"user-id": "12345", "bits": "100"},
+			query: "bits=100&user=user&user_id=12345",
+		},
+		{
+			name:  "unrelated tags",
+			tags:  map[string]string{"color": "#FF0000"},
+			query: "user=user",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var path, query string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				query = r.URL.RawQuery
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			parsed, err := url.Parse(server.URL)
+			require.NoError(t, err)
+
+			handler := NewMessageHandler(parsed.Host)
+			handler.HandleChannelMessage("channel", "solving", "user", "!1a qanda", false, test.tags)
+
+			assert.Equal(t, "/api/crossword/channel/answer/1a", path)
+			assert.Equal(t, test.query, query)
+		})
+	}
+}
+
+func TestMessageHandler_Lock(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		mod     bool
+		path    string // the path the api should receive, empty if no request
+		body    string // the body the api should receive
+	}{
+		{
+			name:    "lock",
+			message: "!lock 1a",
+			mod:     true,
+			path:    "/api/crossword/channel/lock",
+			body:    `{"clue":"1a"}`,
+		},
+		{
+			name:    "unlock",
+			message: "!unlock 10D",
+			mod:     true,
+			path:    "/api/crossword/channel/unlock",
+			body:    `{"clue":"10D"}`,
+		},
+		{
+			name:    "mixed case command",
+			message: "!LoCk 1a",
+			mod:     true,
+			path:    "/api/crossword/channel/lock",
+			body:    `{"clue":"1a"}`,
+		},
+		{
+			name:    "not a moderator",
+			message: "!lock 1a",
+		},
+		{
+			name:    "missing clue",
+			message: "!lock",
+			mod:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var path, body string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer r.Body.Close()
+				assert.Equal(t, http.MethodPut, r.Method)
+
+				bs, err := ioutil.ReadAll(r.Body)
+				require.NoError(t, err)
+
+				path = r.URL.Path
+				body = string(bs)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			parsed, err := url.Parse(server.URL)
+			require.NoError(t, err)
+
+			handler := NewMessageHandler(parsed.Host)
+			handler.HandleChannelMessage("channel", "solving", "user", test.message, test.mod, nil)
+			assert.Equal(t, test.path, path)
+			assert.Equal(t, test.body, body)
+		})
+	}
+}
+
+func TestPuzzleSources(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{source: "nyt", expected: "new_york_times_date"},
+		{source: "mini", expected: "new_york_times_mini_date"},
+		{source: "cryptic", expected: "new_york_times_cryptic_date"},
+		{source: "wsj", expected: "wall_street_journal_date"},
+		{source: "wapo", expected: "washington_post_date"},
+		{source: "atlantic", expected: "atlantic_date"},
+		{source: "jonesin", expected: "jonesin_date"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.source, func(t *testing.T) {
+			assert.Equal(t, test.expected, PuzzleSources[test.source])
+		})
+	}
+}
+
+func TestFormatLeaderboard(t *testing.T) {
+	tests := []struct {
+		name        string
+		leaderboard []LeaderboardEntry
+		expected    string
+	}{
+		{
+			name:     "empty",
+			expected: "Nobody has solved a clue yet",
+		},
+		{
+			name: "single user",
+			leaderboard: []LeaderboardEntry{
+				{User: "alice", Clues: 1, Rank: 1},
+			},
+			expected: "Top solvers: 1. alice (1 clue)",
+		},
+		{
+			name: "ties share a rank",
+			leaderboard: []LeaderboardEntry{
+				{User: "alice", Clues: 5, Rank: 1},
+				{User: "bob", Clues: 5, Rank: 1},
+				{User: "carol", Clues: 3, Rank: 3},
+			},
+			expected: "Top solvers: 1. alice, bob (5 clues) | 3. carol (3 clues)",
+		},
+		{
+			name: "only the top ranks are reported",
+			leaderboard: []LeaderboardEntry{
+				{User: "alice", Clues: 9, Rank: 1},
+				{User: "bob", Clues: 7, Rank: 2},
+				{User: "carol", Clues: 4, Rank: 3},
+				{User: "dave", Clues: 4, Rank: 3},
+				{User: "erin", Clues: 2, Rank: 5},
+			},
+			expected: "Top solvers: 1. alice (9 clues) | 2. bob (7 clues) | 3. carol, dave (4 clues)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, FormatLeaderboard(test.leaderboard))
+		})
+	}
+}
+
+func TestFormatProgress(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress Progress
+		expected string
+	}{
+		{
+			name:     "not started",
+			progress: Progress{CluesFilled: 0, CluesTotal: 78, Percent: 0},
+			expected: "We're 0% done (0/78 clues)",
+		},
+		{
+			name:     "partially complete",
+			progress: Progress{CluesFilled: 41, CluesTotal: 66, Percent: 62},
+			expected: "We're 62% done (41/66 clues)",
+		},
+		{
+			name:     "complete",
+			progress: Progress{CluesFilled: 66, CluesTotal: 66, Percent: 100},
+			expected: "We're 100% done (66/66 clues)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, FormatProgress(test.progress))
+		})
+	}
+}
+
+func TestFormatClueAge(t *testing.T) {
+	tests := []struct {
+		name     string
+		age      ClueAge
+		expected string
+	}{
+		{
+			name:     "seconds",
+			age:      ClueAge{Clue: "1a", Age: "7.25s"},
+			expected: "1a has been open 0:07",
+		},
+		{
+			name:     "minutes",
+			age:      ClueAge{Clue: "14a", Age: "4m12s"},
+			expected: "14a has been open 4:12",
+		},
+		{
+			name:     "hours",
+			age:      ClueAge{Clue: "3d", Age: "1h2m3s"},
+			expected: "3d has been open 1:02:03",
+		},
+		{
+			name:     "filled",
+			age:      ClueAge{Clue: "5d", Filled: true, Age: "0s"},
+			expected: "5d is already filled in",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, FormatClueAge(test.age))
+		})
+	}
+}
@@ -15,9 +15,11 @@ import (
 type ID string
 
 // A MessageHandler represents an implementation of a bot that processes chat
-// messages from a client in order to play a game in a channel.
+// messages from a client in order to play a game in a channel.  The mod flag
+// indicates whether the user that sent the message moderates the channel and
+// the tags are the raw IRC tags of the message (e.g. user-id or bits).
 type MessageHandler interface {
-	HandleChannelMessage(channel, status, message string)
+	HandleChannelMessage(channel, status, user, message string, mod bool, tags map[string]string)
 }
 
 func main() {
@@ -26,9 +28,11 @@ func main() {
 		log.Fatal("missing API_HOST environment variable")
 	}
 
+	crosswordHandler := crossword.NewMessageHandler(host)
+
 	handlers := map[ID]MessageHandler{
 		"acrostic":    acrostic.NewMessageHandler(host),
-		"crossword":   crossword.NewMessageHandler(host),
+		"crossword":   crosswordHandler,
 		"spellingbee": spellingbee.NewMessageHandler(host),
 	}
 
@@ -46,6 +50,9 @@ func main() {
 		log.Fatalf("unable to create client: %v", err)
 	}
 
+	// Now that there's a client, allow the handlers to send messages to chat.
+	crosswordHandler.Say = client.Say
+
 	// The channel monitor that will be used to keep track of which channels the
 	// client should be monitoring and router should be sending messages to.
 	monitor := ChannelMonitor{
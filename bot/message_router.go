@@ -54,15 +54,21 @@ func (r *MessageRouter) UpdateIntegrationStatus(app ID, channel string, status s
 
 // HandleChannelMessage takes a message that was sent to a channel and passes
 // it onto the handlers for the integrations that are active for the channel.
-func (r *MessageRouter) HandleChannelMessage(channel, _, _, message string) {
+// Handlers may make slow requests to the api service, so they're called without
+// holding the lock in order to not block updates to the integration statuses.
+func (r *MessageRouter) HandleChannelMessage(channel, _, user, message string, mod bool, tags map[string]string) {
 	r.Lock()
-	defer r.Unlock()
-
 	r.ensure(channel)
+	statuses := make(map[ID]string, len(r.statuses[channel]))
 	for app, status := range r.statuses[channel] {
+		statuses[app] = status
+	}
+	r.Unlock()
+
+	for app, status := range statuses {
 		handler := r.handlers[app]
 		if handler != nil {
-			handler.HandleChannelMessage(channel, status, message)
+			handler.HandleChannelMessage(channel, status, user, message, mod, tags)
 		}
 	}
 }
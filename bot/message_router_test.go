@@ -2,7 +2,9 @@ package main
 
 import (
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestNewMessageRouter(t *testing.T) {
@@ -249,17 +251,47 @@ func TestMessageRouter_HandleChannelMessage(t *testing.T) {
 				handlers: handlers,
 				statuses: test.initial,
 			}
-			router.HandleChannelMessage(test.channel, "userid", "username", "message")
+			router.HandleChannelMessage(test.channel, "userid", "username", "message", false, nil)
 			assert.ElementsMatch(t, test.expected, called)
 		})
 	}
 }
 
+func TestMessageRouter_HandleChannelMessage_DoesNotBlockUpdates(t *testing.T) {
+	release := make(chan struct{})
+	handled := NewCountDownLatch(1)
+
+	router := &MessageRouter{
+		handlers: map[ID]MessageHandler{
+			"crossword": TestMessageHandler{"crossword", func() {
+				handled.CountDown()
+				<-release
+			}},
+		},
+		statuses: map[string]map[ID]string{
+			"channel": {"crossword": "solving"},
+		},
+	}
+
+	go router.HandleChannelMessage("channel", "userid", "username", "message", false, nil)
+	require.True(t, handled.Wait(time.Second))
+
+	// The handler is still running, updating a status shouldn't wait for it.
+	updated := NewCountDownLatch(1)
+	go func() {
+		router.UpdateIntegrationStatus("crossword", "channel", "paused")
+		updated.CountDown()
+	}()
+	assert.True(t, updated.Wait(time.Second))
+
+	close(release)
+}
+
 type TestMessageHandler struct {
 	id ID
 	fn func()
 }
 
-func (h TestMessageHandler) HandleChannelMessage(_, _, _ string) {
+func (h TestMessageHandler) HandleChannelMessage(_, _, _, _ string, _ bool, _ map[string]string) {
 	h.fn()
 }
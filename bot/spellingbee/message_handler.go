@@ -40,7 +40,7 @@ func NewMessageHandler(host string) *MessageHandler {
 
 // HandleChannelMessage parses a message and if it matches a spelling bee
 // command sends it to the appropriate API endpoint.
-func (h *MessageHandler) HandleChannelMessage(channel, status, message string) {
+func (h *MessageHandler) HandleChannelMessage(channel, status, user, message string, _ bool, _ map[string]string) {
 	if status != "solving" {
 		return
 	}
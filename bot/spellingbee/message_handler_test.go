@@ -98,7 +98,7 @@ func TestMessageHandler_HandleChannelMessage(t *testing.T) {
 				require.NoError(t, err)
 
 				handler := NewMessageHandler(parsed.Host)
-				handler.HandleChannelMessage("channel", status, test.message)
+				handler.HandleChannelMessage("channel", status, "user", test.message, false, nil)
 
 				assert.Equal(t, expected.path, path)
 				assert.Equal(t, expected.body, body)
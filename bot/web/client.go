@@ -1,18 +1,21 @@
 package web
 
 //
-// NOTE: This file is hard linked in both api/web/client.go and
-// bot/web/client.go changes made in one file will automatically be reflected in
-// the other.
+// NOTE: Separate copies of this file live in api/web/client.go,
+// bot/web/client.go and controller/web/client.go so that each docker container
+// can see it.  Changes made to one copy aren't reflected in the others, so
+// they must be made to every copy that needs them.
 //
-// A hardlink was used to allow different docker containers to see the file
-// properly.
+// The api and bot copies are kept identical.  The controller doesn't download
+// puzzles, so its copy leaves out the download limits.
 //
 
 import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"sync"
 	"time"
 )
 
@@ -21,6 +24,12 @@ var DefaultHTTPClient = &http.Client{
 	Timeout: 3 * time.Second,
 }
 
+// UserAgent is the User-Agent header sent when fetching a URL.  Some sites
+// refuse requests that don't identify themselves, so by default the
+// application is described.  A request can still override it by supplying its
+// own User-Agent header.
+var UserAgent = "puzzles-with-chat (+https://github.com/bbeck/puzzles-with-chat)"
+
 // Get performs a HTTP GET of a URL using the default HTTP client.
 func Get(url string) (*http.Response, error) {
 	return GetWithHeaders(url, nil)
@@ -28,8 +37,112 @@ func Get(url string) (*http.Response, error) {
 
 // GetWithHeaders performs a HTTP GET of a URL using the default HTTP client
 // and passing in the supplied headers.
+//
+// These are the downloads of puzzles from their sources, so they're subject to
+// the download limit as well as the delay between downloads from the same host.
 func GetWithHeaders(url string, headers map[string]string) (*http.Response, error) {
-	return GetWithClient(DefaultHTTPClient, url, headers)
+	release, err := acquireDownload(url)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := GetWithClient(DefaultHTTPClient, url, headers)
+	if response == nil {
+		release()
+		return response, err
+	}
+
+	// The download isn't finished until the caller is done reading the body.
+	response.Body = &releasingReadCloser{ReadCloser: response.Body, release: release}
+	return response, err
+}
+
+// DownloadWaitTimeout is the longest that a download waits for one of the
+// downloads in progress to finish before it gives up.
+var DownloadWaitTimeout = 30 * time.Second
+
+// DownloadHostDelay is the minimum amount of time between the start of
+// successive downloads from the same host.  This keeps a burst of downloads
+// from overwhelming a source.  A delay of zero disables it.
+var DownloadHostDelay time.Duration
+
+// downloads tracks the downloads that are in progress as well as when the next
+// download from each host can start.
+var downloads = struct {
+	sync.Mutex
+	slots chan struct{}
+	next  map[string]time.Time
+}{
+	next: make(map[string]time.Time),
+}
+
+// SetDownloadLimit limits the number of downloads that can be in progress at
+// once.  Downloads beyond the limit wait for an earlier one to finish before
+// they start.  A limit of zero removes the limit.
+func SetDownloadLimit(limit int) {
+	downloads.Lock()
+	defer downloads.Unlock()
+
+	downloads.slots = nil
+	if limit > 0 {
+		downloads.slots = make(chan struct{}, limit)
+	}
+}
+
+// acquireDownload waits until a download of the URL is allowed to start and
+// returns a function that must be called once the download has finished.  An
+// error is returned if the download can't start within DownloadWaitTimeout.
+func acquireDownload(u string) (func(), error) {
+	downloads.Lock()
+	slots := downloads.slots
+
+	var wait time.Duration
+	if parsed, err := url.Parse(u); err == nil && DownloadHostDelay > 0 {
+		now := time.Now()
+		next := downloads.next[parsed.Host]
+		if next.Before(now) {
+			next = now
+		}
+
+		wait = next.Sub(now)
+		downloads.next[parsed.Host] = next.Add(DownloadHostDelay)
+	}
+	downloads.Unlock()
+
+	// Waiting for the host comes first since waiting for a download to finish
+	// only ever spaces the downloads out further.
+	time.Sleep(wait)
+	if slots != nil {
+		timer := time.NewTimer(DownloadWaitTimeout)
+		defer timer.Stop()
+
+		select {
+		case slots <- struct{}{}:
+		case <-timer.C:
+			return nil, fmt.Errorf("timed out waiting to download url %s", u)
+		}
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if slots != nil {
+				<-slots
+			}
+		})
+	}, nil
+}
+
+// releasingReadCloser is an io.ReadCloser that calls a function the first time
+// that it's closed.
+type releasingReadCloser struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releasingReadCloser) Close() error {
+	defer r.release()
+	return r.ReadCloser.Close()
 }
 
 // GetWithClient performs a HTTP GET of a URL with custom headers using the
@@ -40,8 +153,13 @@ func GetWithClient(client *http.Client, url string, headers map[string]string) (
 		return nil, fmt.Errorf("unable to create http request for url %s: %v", url, err)
 	}
 
+	if UserAgent != "" {
+		request.Header.Set("User-Agent", UserAgent)
+	}
+
+	// Set instead of add the headers so that they replace the defaults.
 	for key, value := range headers {
-		request.Header.Add(key, value)
+		request.Header.Set(key, value)
 	}
 
 	response, err := client.Do(request)
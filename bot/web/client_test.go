@@ -15,6 +15,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -94,6 +95,25 @@ func TestGetWithHeaders(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGetWithHeaders_UserAgent(t *testing.T) {
+	var agent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agent = r.Header.Get("User-Agent")
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	// By default the application's user agent is sent.
+	_, err := Get(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, UserAgent, agent)
+
+	// But it can be overridden by the request's headers.
+	_, err = GetWithHeaders(server.URL, map[string]string{"user-agent": "custom"})
+	require.NoError(t, err)
+	assert.Equal(t, "custom", agent)
+}
+
 func TestPost(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(200)
@@ -219,3 +239,132 @@ func TestPut_Error(t *testing.T) {
 		})
 	}
 }
+
+func TestGet_DownloadLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    int
+		expected int // the most downloads that should run at once
+	}{
+		{
+			name:     "limit of 1",
+			limit:    1,
+			expected: 1,
+		},
+		{
+			name:     "limit of 2",
+			limit:    2,
+			expected: 2,
+		},
+		{
+			name:     "no limit",
+			limit:    0,
+			expected: 3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			SetDownloadLimit(test.limit)
+			defer SetDownloadLimit(0)
+
+			var lock sync.Mutex
+			var current, most int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				current++
+				if current > most {
+					most = current
+				}
+				lock.Unlock()
+
+				time.Sleep(50 * time.Millisecond)
+
+				lock.Lock()
+				current--
+				lock.Unlock()
+			}))
+			defer server.Close()
+
+			var wg sync.WaitGroup
+			for i := 0; i < 3; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					response, err := Get(server.URL)
+					assert.NoError(t, err)
+					_ = response.Body.Close()
+				}()
+			}
+			wg.Wait()
+
+			assert.Equal(t, test.expected, most)
+		})
+	}
+}
+
+func TestGet_DownloadLimit_ReleasedOnError(t *testing.T) {
+	SetDownloadLimit(1)
+	defer SetDownloadLimit(0)
+
+	// A download that fails before a response is received doesn't hold on to
+	// its slot.
+	_, err := Get(":")
+	assert.Error(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	response, err := Get(server.URL)
+	require.NoError(t, err)
+	_ = response.Body.Close()
+}
+
+func TestGet_DownloadLimit_Timeout(t *testing.T) {
+	SetDownloadLimit(1)
+	defer SetDownloadLimit(0)
+
+	DownloadWaitTimeout = 10 * time.Millisecond
+	defer func() { DownloadWaitTimeout = 30 * time.Second }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// Hold on to the only slot so that the next download has to wait for it.
+	response, err := Get(server.URL)
+	require.NoError(t, err)
+
+	_, err = Get(server.URL)
+	assert.Error(t, err)
+
+	// Once the slot is released downloads can start again.
+	_ = response.Body.Close()
+
+	response, err = Get(server.URL)
+	require.NoError(t, err)
+	_ = response.Body.Close()
+}
+
+func TestGet_DownloadHostDelay(t *testing.T) {
+	DownloadHostDelay = 50 * time.Millisecond
+	defer func() { DownloadHostDelay = 0 }()
+
+	var lock sync.Mutex
+	var starts []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		starts = append(starts, time.Now())
+	}))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		response, err := Get(server.URL)
+		require.NoError(t, err)
+		_ = response.Body.Close()
+	}
+
+	require.Equal(t, 2, len(starts))
+	assert.True(t, starts[1].Sub(starts[0]) >= 40*time.Millisecond)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bbeck/puzzles-with-chat/controller/web"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ChannelNameRegexp matches the names that Twitch allows for a channel.
+var ChannelNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_]{1,25}$`)
+
+// Channels is the set of channels that the controller manages.  Each channel is
+// mapped to whether or not the controller should currently act on it.  The set
+// can be replaced while the controller is running.
+type Channels struct {
+	sync.RWMutex
+	enabled map[string]bool
+}
+
+// IsEnabled returns whether or not the controller should act on a channel.
+func (c *Channels) IsEnabled(name string) bool {
+	c.RLock()
+	defer c.RUnlock()
+	return c.enabled[name]
+}
+
+// Replace swaps the set of managed channels for a new one.
+func (c *Channels) Replace(enabled map[string]bool) {
+	c.Lock()
+	defer c.Unlock()
+	c.enabled = enabled
+}
+
+// String returns the names of the enabled channels, sorted alphabetically.
+func (c *Channels) String() string {
+	c.RLock()
+	defer c.RUnlock()
+
+	var names []string
+	for name, enabled := range c.enabled {
+		if enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return "[" + strings.Join(names, " ") + "]"
+}
+
+// LoadChannels reads the set of managed channels from a location that is either
+// a path to a file or a http(s) URL.  The channels are a JSON object mapping
+// each channel name to whether or not the controller should act on it.
+func LoadChannels(location string) (map[string]bool, error) {
+	var in io.ReadCloser
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		response, err := web.Get(location)
+		if response != nil && response.Body != nil {
+			defer func() { _ = response.Body.Close() }()
+		}
+		if err != nil {
+			return nil, err
+		}
+		in = response.Body
+	} else {
+		file, err := os.Open(location)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open channels file %s: %v", location, err)
+		}
+		defer func() { _ = file.Close() }()
+		in = file
+	}
+
+	return ParseChannels(in)
+}
+
+// ParseChannels parses a JSON object mapping each channel name to whether or not
+// the controller should act on it.  An error is returned if any of the channel
+// names aren't valid Twitch channel names.
+func ParseChannels(in io.Reader) (map[string]bool, error) {
+	var channels map[string]bool
+	if err := json.NewDecoder(in).Decode(&channels); err != nil {
+		return nil, fmt.Errorf("unable to parse channels: %v", err)
+	}
+
+	for name := range channels {
+		if !ChannelNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("invalid channel name: %q", name)
+		}
+	}
+
+	return channels, nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadChannels(t *testing.T) {
+	file, err := ioutil.TempFile("", "channels-*.json")
+	require.NoError(t, err)
+	defer func() { _ = os.Remove(file.Name()) }()
+
+	_, err = file.WriteString(`{"bbeck": true, "aidanwould": false}`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"bbeck": true, "aidanwould": false}`))
+	}))
+	defer server.Close()
+
+	for _, location := range []string{file.Name(), server.URL} {
+		channels, err := LoadChannels(location)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]bool{"bbeck": true, "aidanwould": false}, channels)
+	}
+}
+
+func TestLoadChannels_Error(t *testing.T) {
+	tests := []struct {
+		name    string
+		respond func(http.ResponseWriter)
+	}{
+		{
+			name: "non-200 response",
+			respond: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+		},
+		{
+			name: "malformed json",
+			respond: func(w http.ResponseWriter) {
+				_, _ = w.Write([]byte(`{`))
+			},
+		},
+		{
+			name: "invalid channel name",
+			respond: func(w http.ResponseWriter) {
+				_, _ = w.Write([]byte(`{"bbeck": true, "not a channel": true}`))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				test.respond(w)
+			}))
+			defer server.Close()
+
+			_, err := LoadChannels(server.URL)
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadChannels("does-not-exist.json")
+		assert.Error(t, err)
+	})
+}
+
+func TestHandlePayload_ReloadChannels(t *testing.T) {
+	var channels Channels
+	channels.Replace(map[string]bool{"bbeck": true})
+
+	payload := func(name string) Payload {
+		var payload Payload
+		require.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(`{"crossword": [{
+			"name": "%s",
+			"status": "complete",
+			"puzzle": {"publisher": "The New York Times", "published": "2024-01-07T00:00:00Z"}
+		}]}`, name)), &payload))
+		return payload
+	}
+
+	actions := make(chan SwitchPuzzle, 10)
+	require.NoError(t, HandlePayload(payload("bbeck"), &channels, actions))
+	require.NoError(t, HandlePayload(payload("mistaeksweremade"), &channels, actions))
+	require.Equal(t, 1, len(actions))
+
+	// Reloading swaps the channels that are acted on.
+	channels.Replace(map[string]bool{"mistaeksweremade": true})
+	assert.False(t, channels.IsEnabled("bbeck"))
+	assert.True(t, channels.IsEnabled("mistaeksweremade"))
+
+	require.NoError(t, HandlePayload(payload("bbeck"), &channels, actions))
+	require.NoError(t, HandlePayload(payload("mistaeksweremade"), &channels, actions))
+	require.Equal(t, 2, len(actions))
+
+	// The action that was queued before the reload is still carried out.
+	assert.Equal(t, SwitchPuzzle{
+		Channel:   "bbeck",
+		Publisher: "The New York Times",
+		Date:      time.Date(2024, time.January, 6, 0, 0, 0, 0, time.UTC),
+	}, <-actions)
+	assert.Equal(t, "mistaeksweremade", (<-actions).Channel)
+}
@@ -14,19 +14,27 @@ import (
 	"time"
 )
 
-var channels = map[string]bool{
-	"agenderwitchery":  true,
-	"aidanwould":       false,
-	"bbeck":            true,
-	"mistaeksweremade": true,
-}
-
 func main() {
 	host, ok := os.LookupEnv("API_HOST")
 	if !ok {
 		log.Fatal("missing API_HOST environment variable")
 	}
 
+	// The managed channels are read from a file or URL and can be reloaded by
+	// sending the process a SIGHUP.
+	location, ok := os.LookupEnv("CHANNELS")
+	if !ok {
+		location = "channels.json"
+	}
+
+	enabled, err := LoadChannels(location)
+	if err != nil {
+		log.Fatalf("unable to load channels from %s: %+v", location, err)
+	}
+
+	var channels Channels
+	channels.Replace(enabled)
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -37,17 +45,31 @@ func main() {
 		cancel()
 	}()
 
+	reloads := make(chan os.Signal, 1)
+	signal.Notify(reloads, syscall.SIGHUP)
+	defer signal.Stop(reloads)
+
 	events := sse.Open(ctx, fmt.Sprintf("http://%s/api/channels", host))
 	actions := make(chan SwitchPuzzle, 10)
 
-	log.Printf("controlling channels: %v\n", channels)
+	log.Printf("controlling channels: %v\n", &channels)
 
 	for {
 		select {
 		case e := <-events:
-			if err := HandleEvent(e, actions); err != nil {
+			if err := HandleEvent(e, &channels, actions); err != nil {
 				log.Printf("received error %v while processing event %v\n", err, e)
 			}
+		case <-reloads:
+			// A channel set that can't be loaded leaves the current one in place.
+			enabled, err := LoadChannels(location)
+			if err != nil {
+				log.Printf("unable to reload channels from %s: %+v\n", location, err)
+				break
+			}
+
+			channels.Replace(enabled)
+			log.Printf("reloaded channels: %v\n", &channels)
 		case a := <-actions:
 			body, err := json.Marshal(map[string]string{
 				"new_york_times_date": a.Date.Format("2006-01-02"),
@@ -75,7 +97,7 @@ func main() {
 	}
 }
 
-func HandleEvent(e sse.Event, actions chan<- SwitchPuzzle) error {
+func HandleEvent(e sse.Event, channels *Channels, actions chan<- SwitchPuzzle) error {
 	var event Event
 	if err := json.Unmarshal(e.Data, &event); err != nil {
 		err = fmt.Errorf("unable to parse json '%s': %+v", e.Data, err)
@@ -89,24 +111,28 @@ func HandleEvent(e sse.Event, actions chan<- SwitchPuzzle) error {
 			err = fmt.Errorf("unable to parse payload '%s': %+v", event.Payload, err)
 			return err
 		}
-		return HandlePayload(payload, actions)
+		return HandlePayload(payload, channels, actions)
 
 	case "ping":
 		return nil
 
+	case "idle":
+		// The api closes idle streams, they're reopened automatically.
+		return nil
+
 	default:
 		err := fmt.Errorf("unrecognized event kind: %s", event.Kind)
 		return err
 	}
 }
 
-func HandlePayload(payload Payload, actions chan<- SwitchPuzzle) error {
+func HandlePayload(payload Payload, channels *Channels, actions chan<- SwitchPuzzle) error {
 	for _, channel := range payload["crossword"] {
 		if channel.Puzzle.Publisher != "The New York Times" {
 			continue
 		}
 
-		if !channels[channel.Name] {
+		if !channels.IsEnabled(channel.Name) {
 			continue
 		}
 
@@ -1,12 +1,13 @@
 package web
 
 //
-// NOTE: This file is hard linked in both api/web/client.go and
-// bot/web/client.go changes made in one file will automatically be reflected in
-// the other.
+// NOTE: Separate copies of this file live in api/web/client.go,
+// bot/web/client.go and controller/web/client.go so that each docker container
+// can see it.  Changes made to one copy aren't reflected in the others, so
+// they must be made to every copy that needs them.
 //
-// A hardlink was used to allow different docker containers to see the file
-// properly.
+// The api and bot copies are kept identical.  The controller doesn't download
+// puzzles, so its copy leaves out the download limits.
 //
 
 import (
@@ -21,6 +22,12 @@ var DefaultHTTPClient = &http.Client{
 	Timeout: 3 * time.Second,
 }
 
+// UserAgent is the User-Agent header sent when fetching a URL.  Some sites
+// refuse requests that don't identify themselves, so by default the
+// application is described.  A request can still override it by supplying its
+// own User-Agent header.
+var UserAgent = "puzzles-with-chat (+https://github.com/bbeck/puzzles-with-chat)"
+
 // Get performs a HTTP GET of a URL using the default HTTP client.
 func Get(url string) (*http.Response, error) {
 	return GetWithHeaders(url, nil)
@@ -40,8 +47,13 @@ func GetWithClient(client *http.Client, url string, headers map[string]string) (
 		return nil, fmt.Errorf("unable to create http request for url %s: %v", url, err)
 	}
 
+	if UserAgent != "" {
+		request.Header.Set("User-Agent", UserAgent)
+	}
+
+	// Set instead of add the headers so that they replace the defaults.
 	for key, value := range headers {
-		request.Header.Add(key, value)
+		request.Header.Set(key, value)
 	}
 
 	response, err := client.Do(request)
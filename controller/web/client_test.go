@@ -94,6 +94,25 @@ func TestGetWithHeaders(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGetWithHeaders_UserAgent(t *testing.T) {
+	var agent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agent = r.Header.Get("User-Agent")
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	// By default the application's user agent is sent.
+	_, err := Get(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, UserAgent, agent)
+
+	// But it can be overridden by the request's headers.
+	_, err = GetWithHeaders(server.URL, map[string]string{"user-agent": "custom"})
+	require.NoError(t, err)
+	assert.Equal(t, "custom", agent)
+}
+
 func TestPost(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(200)
@@ -32,6 +32,14 @@ export class EventStream {
     let received = false;
     source.onmessage = (message) => {
       received = true;
+
+      // The server closes streams that have been idle for a long time since
+      // they likely belong to an abandoned tab, don't reconnect to them.
+      if (JSON.parse(message.data).kind === "idle") {
+        stop();
+        return;
+      }
+
       this.handler(message);
     };
     this.source = source;
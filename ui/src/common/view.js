@@ -14,7 +14,20 @@ export function TwitchChat({channel}) {
   );
 }
 
-export function Timer({total_solve_duration, last_start_time}) {
+export function Timer({total_solve_duration, last_start_time, elapsed}) {
+  // When the server includes how long the solve has been progressing for we
+  // count up from that value starting from when we received it.  This keeps
+  // the time consistent across reconnects regardless of any difference between
+  // our clock and the server's.
+  const received = React.useMemo(
+    () => new Date().getTime() / 1000,
+    [elapsed, last_start_time]
+  );
+  if (elapsed !== undefined) {
+    const started = last_start_time ? received : NaN;
+    return (<Duration prior={elapsed} started={started}/>);
+  }
+
   // Parse the duration into the total number of seconds that the solve has
   // accumulated prior to this most recent start.
   const prior = parseDuration(total_solve_duration);
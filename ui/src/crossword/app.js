@@ -12,11 +12,19 @@ export function CrosswordApp(props) {
     clue_font_size: "normal",
     only_allow_correct_answers: false,
     show_notes: false,
+    auto_advance_on_complete: false,
+    paused_answer_behavior: "reject",
   });
 
   // The current state of the crossword app for the current channel.
   const [state, setState] = React.useState({});
 
+  // The cells that are currently being peeked at, indexed by "row,col".
+  const [peeks, setPeeks] = React.useState({});
+
+  // The message pinned to the overlay by the streamer, if there is one.
+  const [banner, setBanner] = React.useState(null);
+
   // Whether or not we're currently showing fireworks.
   const [showFireworks, setShowFireworks] = React.useState(false);
 
@@ -87,6 +95,44 @@ export function CrosswordApp(props) {
           }
           break;
 
+        case "hide_clue":
+          // A clue that was shown for a limited time is no longer visible.
+          const hidden = document.getElementById(event.payload);
+          if (hidden !== null) {
+            hidden.classList.remove("shown");
+          }
+          break;
+
+        case "focus":
+          // The focused clue is part of the state so that it survives other
+          // state updates, but we also bring it into view like show_clue does.
+          setState(state => ({...state, focused_clue: event.payload}));
+
+          const focused = document.getElementById(event.payload);
+          if (focused !== null) {
+            focused.scrollIntoView();
+          }
+          break;
+
+        case "peek":
+          setPeeks(peeks => ({
+            ...peeks,
+            [`${event.payload.row},${event.payload.col}`]: event.payload.value,
+          }));
+          break;
+
+        case "peek_clear":
+          setPeeks(peeks => {
+            const {[`${event.payload.row},${event.payload.col}`]: _, ...rest} = peeks;
+            return rest;
+          });
+          break;
+
+        case "banner":
+          // A banner without a payload means that it has been cleared.
+          setBanner(event.payload || null);
+          break;
+
         case "complete":
           setShowFireworks(true);
           setTimeout(() => setShowFireworks(false), 20000);
@@ -99,7 +145,7 @@ export function CrosswordApp(props) {
           console.log("unhandled event:", event);
       }
     });
-  }, [setSettings, stream, setState, setShowFireworks]);
+  }, [setSettings, stream, setState, setPeeks, setBanner, setShowFireworks]);
 
   // Toggle the status.
   const toggleStatus = () => {
@@ -121,7 +167,9 @@ export function CrosswordApp(props) {
         view={props.view}
         state={state}
         settings={settings}
+        peeks={peeks}
       />
+      {banner && <div className="crossword-banner">{banner.message}</div>}
       {showFireworks && <Fireworks/>}
     </>
   );
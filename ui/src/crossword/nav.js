@@ -157,6 +157,34 @@ export function SettingsDropdown(props) {
             </div>
             <Switch checked={settings.show_notes} onClick={update("show_notes", !settings.show_notes)}/>
           </div>
+          <div className="dropdown-divider"/>
+          <div className="dropdown-item">
+            <div className="lead">Automatically advance</div>
+            <div>
+              <small className="text-muted">
+                This setting enables automatically switching to the puzzle
+                published before the current one shortly after the current
+                puzzle is completed.  It only applies to puzzles that were
+                selected by their date.
+              </small>
+            </div>
+            <Switch checked={settings.auto_advance_on_complete} onClick={update("auto_advance_on_complete", !settings.auto_advance_on_complete)}/>
+          </div>
+          <div className="dropdown-divider"/>
+          <div className="dropdown-item">
+            <div className="lead">Answers while paused</div>
+            <div>
+              <small className="text-muted">
+                This setting controls whether answers submitted while the puzzle
+                is paused are rejected or queued up and applied once the puzzle
+                is resumed.
+              </small>
+            </div>
+            <div className="btn-group" role="group">
+              <button type="button" className={settings.paused_answer_behavior === "reject" ? "btn btn-success" : "btn btn-dark"} onClick={update("paused_answer_behavior", "reject")}>Reject</button>
+              <button type="button" className={settings.paused_answer_behavior === "queue" ? "btn btn-success" : "btn btn-dark"} onClick={update("paused_answer_behavior", "queue")}>Queue</button>
+            </div>
+          </div>
         </form>
       </div>
     </li>
@@ -225,15 +253,25 @@ export function PuzzleDropdown({channel, setErrorMessage}) {
     return setPuzzle({"puz_file_url": url});
   };
 
-  // Select a .puz puzzle based on the uploaded .puz file.
+  // Select a puzzle based on the uploaded .puz or .ipuz file.
   const onPuzFileSelected = (file) => {
     if (!file) {
       return;
     }
 
+    const key = file.name.toLowerCase().endsWith(".ipuz") ? "ipuz_file_bytes" : "puz_file_bytes";
     return read(file)
       .then(btoa)
-      .then(bs => setPuzzle({"puz_file_bytes": bs}));
+      .then(bs => setPuzzle({[key]: bs}));
+  };
+
+  // Select a puzzle based on the pasted contents of an Across Lite text file.
+  const onTextPuzzleSelected = (text) => {
+    if (!text) {
+      return;
+    }
+
+    return setPuzzle({"text_puzzle": text});
   };
 
   return (
@@ -277,6 +315,23 @@ export function PuzzleDropdown({channel, setErrorMessage}) {
             </div>
           </div>
           <div className="dropdown-divider"/>
+          <div className="dropdown-item">
+            <div className="lead">Washington Post</div>
+            <div>
+              <small className="text-muted">
+                Select a date to solve that day's puzzle from the archives of
+                the Washington Post.
+              </small>
+            </div>
+            <div className="input-group">
+              <DateChooser
+                onClick={date => onDateSelected("washington_post", date)}
+                filterDate={date => isPuzzleAvailableForDate("washington_post", date)}
+                minDate={minDates["washington_post"]}
+              />
+            </div>
+          </div>
+          <div className="dropdown-divider"/>
           <div className="dropdown-item">
             <div className="lead">Download a .puz file</div>
             <div>
@@ -294,19 +349,37 @@ export function PuzzleDropdown({channel, setErrorMessage}) {
           </div>
           <div className="dropdown-divider"/>
           <div className="dropdown-item">
-            <div className="lead">Upload a .puz file</div>
+            <div className="lead">Upload a .puz or .ipuz file</div>
             <div>
               <small className="text-muted">
-                Upload your own puzzle file in .puz format. More info about
-                the .puz file format can be found&nbsp;
-                <a href="http://fileformats.archiveteam.org/wiki/PUZ_(crossword_puzzles)">here</a>.
+                Upload your own puzzle file in .puz or .ipuz format. More info
+                about the .puz file format can be found&nbsp;
+                <a href="http://fileformats.archiveteam.org/wiki/PUZ_(crossword_puzzles)">here</a>
+                and about the .ipuz file format&nbsp;
+                <a href="http://www.ipuz.org">here</a>.
               </small>
             </div>
             <div className="input-group">
-              <input id="puz-file-input" type="file" accept=".puz" className="d-none" onChange={e => onPuzFileSelected(e.target.files[0])}/>
+              <input id="puz-file-input" type="file" accept=".puz,.ipuz" className="d-none" onChange={e => onPuzFileSelected(e.target.files[0])}/>
               <label htmlFor="puz-file-input" className="btn btn-dark" onClick={e => {e.target.control.value = null}}>Choose file</label>
             </div>
           </div>
+          <div className="dropdown-divider"/>
+          <div className="dropdown-item">
+            <div className="lead">Paste an Across Lite text puzzle</div>
+            <div>
+              <small className="text-muted">
+                Paste the contents of a puzzle in the Across Lite text format,
+                starting with its &lt;ACROSS PUZZLE&gt; line.
+              </small>
+            </div>
+            <div className="input-group">
+              <textarea id="text-puzzle-input" className="form-control" rows="3"/>
+              <div className="input-group-append">
+                <label htmlFor="text-puzzle-input" className="btn btn-dark" onClick={e => onTextPuzzleSelected(e.target.control.value)}>Load</label>
+              </div>
+            </div>
+          </div>
         </form>
       </div>
     </li>
@@ -68,8 +68,9 @@ export function CrosswordView(props) {
           date={puzzle.published}
           last_start_time={last_start_time}
           total_solve_duration={total_solve_duration}
+          elapsed={state.elapsed}
         />
-        <Grid puzzle={puzzle} cells={state.cells} view={view}/>
+        <Grid puzzle={puzzle} cells={state.cells} pencil_cells={state.pencil_cells} locked_cells={state.locked_cells} peeks={props.peeks || {}} view={view}/>
         <Footer/>
       </div>
       <Clues
@@ -77,6 +78,7 @@ export function CrosswordView(props) {
         across_clues_filled={state.across_clues_filled}
         down_clues={puzzle.clues_down}
         down_clues_filled={state.down_clues_filled}
+        focused_clue={state.focused_clue}
         notes={puzzle.notes}
         clue_font_size={settings.clue_font_size}
         clues_to_show={settings.clues_to_show}
@@ -111,6 +113,7 @@ function Header(props) {
       <Timer
         last_start_time={props.last_start_time}
         total_solve_duration={props.total_solve_duration}
+        elapsed={props.elapsed}
       />
     </div>
   );
@@ -120,9 +123,11 @@ function Footer() {
   return (
     <div className="footer">
       <div>Answer a clue: <code>!12a red velvet cake</code></div>
+      <div>Answer a clue in one word: <code>14a=third</code></div>
       <div>Partially answer a clue: <code>!12a gr.y goose</code></div>
       <div>Answer with a rebus: <code>!12a (gray)goose</code></div>
       <div>Make a clue visible: <code>!show 10d</code></div>
+      <div>See how long a clue has been open: <code>!age 10d</code></div>
     </div>
   );
 }
@@ -130,6 +135,9 @@ function Footer() {
 function Grid(props) {
   const puzzle = props.puzzle;
   const contents = props.cells;
+  const pencils = props.pencil_cells;
+  const peeks = props.peeks;
+  const lockeds = props.locked_cells;
   const view = props.view;
 
   // Because we're rendering as a SVG we'll make the size of each cell fixed
@@ -163,12 +171,16 @@ function Grid(props) {
   for (let cy = 0; cy < puzzle.rows; cy++) {
     for (let cx = 0; cx < puzzle.cols; cx++) {
       const number = puzzle.cell_clue_numbers[cy][cx] || "";
-      const content = contents[cy][cx] || "";
+      const peek = contents[cy][cx] ? undefined : peeks[`${cy},${cx}`];
+      const pencil = contents[cy][cx] || peek || !pencils ? undefined : pencils[cy][cx];
+      const content = contents[cy][cx] || peek || pencil || "";
+      const locked = lockeds && lockeds[cy] && lockeds[cy][cx];
       const isBlock = puzzle.cell_blocks[cy][cx];
+      const isVoid = puzzle.cell_voids && puzzle.cell_voids[cy][cx];
       const isCircle = puzzle.cell_circles[cy][cx];
       const isShaded = puzzle.cell_shades[cy][cx];
-      const isFilled = view === "progress" && content !== "";
-      const className = isBlock ? "cell block" : isFilled ? "cell filled" : isShaded ? "cell shaded" : "cell";
+      const isFilled = view === "progress" && content !== "" && !pencil;
+      const className = isVoid ? "cell void" : isBlock ? "cell block" : isFilled ? "cell filled" : isShaded ? "cell shaded" : "cell";
       const x = cx * s;
       const y = cy * s;
 
@@ -187,7 +199,7 @@ function Grid(props) {
           <rect x={x} y={y} width={s} height={s} className={className}/>
           {circle}
           <text x={x} y={y} className="number">{number}</text>
-          <text x={x} y={y} className="content" data-length={content.length}>
+          <text x={x} y={y} className={peek ? "content peek" : pencil ? "content pencil" : locked ? "content locked" : "content"} data-length={content.length}>
             {view !== "progress" ? content : ""}
           </text>
         </g>
@@ -231,6 +243,7 @@ function Clues(props) {
   const across_clues_filled = props.across_clues_filled;
   const down_clues = props.down_clues;
   const down_clues_filled = props.down_clues_filled;
+  const focused_clue = props.focused_clue;
   const clue_notes = props.notes || "";
   const clues_to_show = props.clues_to_show;
   const clue_font_size = props.clue_font_size;
@@ -241,7 +254,7 @@ function Clues(props) {
     across = <div className="across">
       <div className="clue-title">Across</div>
       <div id="across-clues" className="clue-list">
-        <ClueList clues={across_clues} filled={across_clues_filled} side="a"/>
+        <ClueList clues={across_clues} filled={across_clues_filled} focused={focused_clue} side="a"/>
       </div>
     </div>;
   }
@@ -251,7 +264,7 @@ function Clues(props) {
     down = <div className="down">
       <div className="clue-title">Down</div>
       <div id="down-clues" className="clue-list">
-        <ClueList clues={down_clues} filled={down_clues_filled} side="d"/>
+        <ClueList clues={down_clues} filled={down_clues_filled} focused={focused_clue} side="d"/>
       </div>
     </div>;
   }
@@ -305,6 +318,7 @@ function ClueList(props) {
 
   const side = props.side;
   const filled = props.filled || {};
+  const focused = props.focused;
 
   // Make sure to always list the clues in sorted order.
   const numbers = Object.keys(clues);
@@ -316,8 +330,16 @@ function ClueList(props) {
 
   const items = [];
   for (const number of numbers) {
+    const classes = [];
+    if (filled[number]) {
+      classes.push("filled");
+    }
+    if (focused === number + side) {
+      classes.push("focused");
+    }
+
     items.push(
-      <li id={number + side} className={filled[number] ? "filled" : ""} key={number}>
+      <li id={number + side} className={classes.join(" ")} key={number}>
         <span className="number">{number}</span>
         <span className="clue" dangerouslySetInnerHTML={{__html: clues[number]}}/>
       </li>
@@ -10,7 +10,8 @@ export function SpellingBeeApp(props) {
   const [settings, setSettings] = React.useState({
     allow_unofficial_answers: false,
     show_answer_placeholders: false,
-    font_size: "normal"
+    font_size: "normal",
+    scoring: "standard"
   });
 
   // The current state of the spelling bee app for the current channel.
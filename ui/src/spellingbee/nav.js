@@ -142,6 +142,23 @@ export function SettingsDropdown(props) {
               <button type="button" className={settings.font_size === "xlarge" ? "btn btn-success" : "btn btn-dark"} onClick={update("font_size", "xlarge")}>Extra Large</button>
             </div>
           </div>
+          <div className="dropdown-divider"/>
+          <div className="dropdown-item">
+            <div className="lead">Scoring</div>
+            <div>
+              <small className="text-muted">
+                This setting changes how many points each answer is worth.
+                Length weighted scoring rewards long answers more heavily, and
+                flat pangram scoring makes every answer worth a single point
+                with a fixed bonus for pangrams.
+              </small>
+            </div>
+            <div className="btn-group" role="group">
+              <button type="button" className={settings.scoring === "standard" ? "btn btn-success" : "btn btn-dark"} onClick={update("scoring", "standard")}>Standard</button>
+              <button type="button" className={settings.scoring === "length_weighted" ? "btn btn-success" : "btn btn-dark"} onClick={update("scoring", "length_weighted")}>Length Weighted</button>
+              <button type="button" className={settings.scoring === "flat_pangram" ? "btn btn-success" : "btn btn-dark"} onClick={update("scoring", "flat_pangram")}>Flat Pangram</button>
+            </div>
+          </div>
         </form>
       </div>
     </li>